@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+	"url-shortener/internal/app/handlers"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/repositories/url"
+	"url-shortener/internal/app/repositories/workspace"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/config"
+	"url-shortener/internal/infrastructure/database"
+)
+
+// runImport runs the import subcommand against the configured database and returns the exit code.
+func runImport(args []string) int {
+	db, err := database.ConnectToDB(config.NewDBConnector(), "mysql")
+	if err != nil {
+		fmt.Println("[IMPORT] Error connecting to database:", err)
+		return 1
+	}
+	defer db.Close()
+
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspace_repository.NewDBWorkspaceRepository(db))
+	safetyPolicy, err := handlers.InitializeSafetyPolicy(db)
+	if err != nil {
+		// Importing without the configured blocklist would let blocked destinations in
+		fmt.Println("[IMPORT] Error loading URL safety policy:", err)
+		return 1
+	}
+	urlService.Safety = safetyPolicy
+
+	if err := importCommand(args, urlService, os.Stdout); err != nil {
+		fmt.Println("[IMPORT] Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// importCommand imports the links of an export file as personal links of a user:
+//
+//	app import -user <id> [-format csv|json] [-dry-run] <file>
+//
+// It prints a summary followed by the rows that were skipped, failed or changed short code.
+func importCommand(args []string, urlService *url_service.Service, out io.Writer) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	flags.SetOutput(out)
+	userID := flags.Uint("user", 0, "ID of the user owning the imported links")
+	format := flags.String("format", "", "export format, csv or json; detected from the content when empty")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without creating anything")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userID == 0 || flags.NArg() != 1 {
+		return errors.New("usage: import -user <id> [-format csv|json] [-dry-run] <file>")
+	}
+
+	file, err := os.Open(flags.Arg(0)) // #nosec G304 -- path comes from the command line
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := url_service.ParseImport(file, *format, math.MaxInt)
+	if err != nil {
+		return err
+	}
+
+	report, err := urlService.ImportURLs(*userID, records, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	printImportReport(out, report)
+	return nil
+}
+
+func printImportReport(out io.Writer, report *url_model.ImportReport) {
+	if report.DryRun {
+		fmt.Fprintln(out, "Dry run, nothing was imported.")
+	}
+	fmt.Fprintf(out, "%d links: %d created, %d skipped, %d failed, %d short code conflicts\n",
+		report.Total, report.Created, report.Skipped, report.Failed, report.Conflicts)
+
+	writer := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, result := range report.Results {
+		switch {
+		case result.Status == url_model.ImportFailed:
+			fmt.Fprintf(writer, "row %d\t%s\t%s\t%s\n", result.Row, result.Status, result.URL, result.Error)
+		case result.Status == url_model.ImportSkipped:
+			fmt.Fprintf(writer, "row %d\t%s\t%s\talready imported as %s\n", result.Row, result.Status, result.URL, result.ShortenedURL)
+		case result.Conflict != "":
+			fmt.Fprintf(writer, "row %d\tconflict\t%s\t%s: %s, using %s\n", result.Row, result.URL, result.SourceCode, result.Conflict, result.ShortenedURL)
+		}
+	}
+	_ = writer.Flush()
+}
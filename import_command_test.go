@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportCommand(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := url_service.NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	export := "keyword,url,clicks\ndocs,https://docs.example.com,42\nsuccess,https://www.example.com,1\nbad,ftp://files.example.com,0\n"
+	assert.NoError(t, os.WriteFile(path, []byte(export), 0o600))
+
+	t.Run("Should report a dry run", func(t *testing.T) {
+		var out bytes.Buffer
+
+		err := importCommand([]string{"-user", "1", "-dry-run", path}, urlService, &out)
+
+		assert.NoError(t, err)
+		assert.Contains(t, out.String(), "Dry run")
+		assert.Contains(t, out.String(), "3 links: 2 created, 0 skipped, 1 failed, 1 short code conflicts")
+		assert.Contains(t, out.String(), "success: short code already exists, using ")
+		assert.Empty(t, repository.Urls)
+	})
+
+	t.Run("Should import once", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, importCommand([]string{"-user", "1", path}, urlService, &out))
+		assert.Len(t, repository.Urls, 2)
+
+		out.Reset()
+		assert.NoError(t, importCommand([]string{"-user", "1", "-format", "csv", path}, urlService, &out))
+		assert.Contains(t, out.String(), "0 created, 2 skipped")
+		assert.Contains(t, out.String(), "already imported as docs")
+		assert.Len(t, repository.Urls, 2)
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.ErrorContains(t, importCommand([]string{path}, urlService, &out), "usage")
+		assert.Error(t, importCommand([]string{"-unknown"}, urlService, &out))
+		assert.Error(t, importCommand([]string{"-user", "1", filepath.Join(t.TempDir(), "missing.csv")}, urlService, &out))
+		assert.Error(t, importCommand([]string{"-user", "1", "-format", "xml", path}, urlService, &out))
+	})
+}
@@ -3,15 +3,42 @@ package main
 import (
 	"database/sql"
 	"url-shortener/internal/app/handlers"
-	"url-shortener/internal/app/handlers/auth"
-	"url-shortener/internal/app/handlers/clicks"
-	"url-shortener/internal/app/handlers/url"
+	"url-shortener/internal/infrastructure/http"
 )
 
-func initializeHandlers(db *sql.DB) (*auth_handler.Handler, *url_handler.Handler, *clicks_handler.Handler) {
-	userHandler := handlers.InitializeUserHandlers(db)
-	urlHandler := handlers.InitializeURLHandlers(db)
-	clicksHandler := handlers.InitializeClickHandlers(db)
+func initializeHandlers(db *sql.DB) (http.Handlers, error) {
+	// The safety policy is shared by the handlers so its blocklist is watched once
+	safetyPolicy, err := handlers.InitializeSafetyPolicy(db)
+	if err != nil {
+		return http.Handlers{}, err
+	}
 
-	return userHandler, urlHandler, clicksHandler
+	// Link and click events are published through the service delivering them
+	webhookHandler := handlers.InitializeWebhookHandlers(db, safetyPolicy)
+	urlHandler := handlers.InitializeURLHandlers(db, safetyPolicy)
+	urlHandler.Webhooks = webhookHandler.Service
+	clickHandler := handlers.InitializeClickHandlers(db)
+	clickHandler.Webhooks = webhookHandler.Service
+	adminHandler := handlers.InitializeAdminHandlers(db)
+	adminHandler.Webhooks = webhookHandler.Service
+	tagHandler := handlers.InitializeTagHandlers(db)
+	tagHandler.Webhooks = webhookHandler.Service
+	folderHandler := handlers.InitializeFolderHandlers(db)
+	folderHandler.Webhooks = webhookHandler.Service
+
+	return http.Handlers{
+		User:        handlers.InitializeUserHandlers(db),
+		URL:         urlHandler,
+		Clicks:      clickHandler,
+		OIDC:        handlers.InitializeOIDCHandlers(db),
+		Admin:       adminHandler,
+		Workspace:   handlers.InitializeWorkspaceHandlers(db),
+		QR:          handlers.InitializeQRHandlers(db),
+		Export:      handlers.InitializeExportHandlers(db),
+		Tag:         tagHandler,
+		Folder:      folderHandler,
+		Domain:      handlers.InitializeDomainHandlers(db, safetyPolicy),
+		Webhook:     webhookHandler,
+		RateLimiter: handlers.InitializeRateLimiter(),
+	}, nil
 }
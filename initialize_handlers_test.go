@@ -12,7 +12,7 @@ func TestInitializeHandlers(t *testing.T) {
 		if err != nil {
 			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 		}
-		_, _, _ = initializeHandlers(db)
+		_, err = initializeHandlers(db)
 
 		if err != nil {
 			t.Errorf("Error: %s", err)
@@ -0,0 +1,190 @@
+package admin_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"url-shortener/internal/app/middleware/role"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/app/services/admin"
+	"url-shortener/internal/app/services/token"
+	"url-shortener/internal/app/services/webhook"
+)
+
+// Handler handles HTTP requests for the admin endpoints.
+type Handler struct {
+	// Service is the admin service instance.
+	Service        *admin_service.Service
+	TokenService   token_service.TokenRepository
+	UserRepository auth_repository.Repository
+	// Webhooks notifies the webhooks of link owners of disabled and re-enabled links, nil when disabled.
+	Webhooks *webhook_service.Service
+}
+
+// NewAdminHandler creates a new instance of AdminHandler with the given admin service.
+func NewAdminHandler(service *admin_service.Service, tokenService token_service.TokenRepository, userRepository auth_repository.Repository) *Handler {
+	return &Handler{Service: service, TokenService: tokenService, UserRepository: userRepository}
+}
+
+// RequireAdmin returns the middleware protecting the admin routes.
+func (h *Handler) RequireAdmin() echo.MiddlewareFunc {
+	return role_middleware.RequireRole(h.TokenService, h.UserRepository, user_model.RoleAdmin)
+}
+
+// ListUsersHandler handles HTTP requests to list and search users.
+func (h *Handler) ListUsersHandler(c echo.Context) error {
+	limit, err := queryInt(c, "limit")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid limit"})
+	}
+	offset, err := queryInt(c, "offset")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid offset"})
+	}
+
+	users, err := h.Service.ListUsers(actor(c), c.QueryParam("q"), limit, offset)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, users)
+}
+
+// GetUserHandler handles HTTP requests to view a user.
+func (h *Handler) GetUserHandler(c echo.Context) error {
+	userID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	user, err := h.Service.GetUser(actor(c), userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// GetUserURLsHandler handles HTTP requests to list the URLs of a user.
+func (h *Handler) GetUserURLsHandler(c echo.Context) error {
+	userID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	urls, err := h.Service.GetUserURLs(actor(c), userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, urls)
+}
+
+// SetRoleHandler handles HTTP requests to change the role of a user.
+func (h *Handler) SetRoleHandler(c echo.Context) error {
+	userID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	var req user_model.RoleChange
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	user, err := h.Service.SetRole(actor(c), userID, req.Role)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// DisableUserHandler handles HTTP requests to disable an account.
+func (h *Handler) DisableUserHandler(c echo.Context) error {
+	return h.setUserDisabled(c, true)
+}
+
+// EnableUserHandler handles HTTP requests to re-enable an account.
+func (h *Handler) EnableUserHandler(c echo.Context) error {
+	return h.setUserDisabled(c, false)
+}
+
+// GetURLHandler handles HTTP requests to view any URL, including disabled ones.
+func (h *Handler) GetURLHandler(c echo.Context) error {
+	u, err := h.Service.GetURL(actor(c), c.Param("code"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, u)
+}
+
+// DisableURLHandler handles HTTP requests to disable any URL.
+func (h *Handler) DisableURLHandler(c echo.Context) error {
+	return h.setURLDisabled(c, true)
+}
+
+// EnableURLHandler handles HTTP requests to re-enable any URL.
+func (h *Handler) EnableURLHandler(c echo.Context) error {
+	return h.setURLDisabled(c, false)
+}
+
+func (h *Handler) setUserDisabled(c echo.Context, disabled bool) error {
+	userID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	user, err := h.Service.SetUserDisabled(actor(c), userID, disabled)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *Handler) setURLDisabled(c echo.Context, disabled bool) error {
+	u, err := h.Service.SetURLDisabled(actor(c), c.Param("code"), disabled)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	h.Webhooks.Publish(webhook_model.EventLinkUpdated, u, u)
+
+	return c.JSON(http.StatusOK, u)
+}
+
+// actor returns the admin authenticated by the role middleware.
+func actor(c echo.Context) admin_service.Actor {
+	return admin_service.Actor{UserID: role_middleware.UserID(c), IPAddress: c.RealIP()}
+}
+
+func paramID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	return uint(id), err
+}
+
+func queryInt(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, user_model.ErrUserNotFound), errors.Is(err, url_model.ErrURLNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, user_model.ErrInvalidRole):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, user_model.ErrSelfModeration):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,192 @@
+package admin_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/audit"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/admin"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler through the admin middleware with an optional name and value path parameter.
+func serve(h *Handler, handler echo.HandlerFunc, method, target, body, token string, params ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if len(params) == 2 {
+		c.SetParamNames(params[0])
+		c.SetParamValues(params[1])
+	}
+
+	_ = h.RequireAdmin()(handler)(c)
+	return rec
+}
+
+func TestRequireAdmin(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := admin_service.NewAdminService(userRepository, mocks.NewMockUrlRepository(), auditRepository)
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+
+	rec := serve(h, h.ListUsersHandler, http.MethodGet, "/admin/users/", "", "mockToken")
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, auditRepository.Entries)
+}
+
+func TestListUsersHandler(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := admin_service.NewAdminService(userRepository, mocks.NewMockUrlRepository(), auditRepository)
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+
+	t.Run("Should search users", func(t *testing.T) {
+		rec := serve(h, h.ListUsersHandler, http.MethodGet, "/admin/users/?q=ali&limit=10", "", "admin")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"username":"alice"`)
+		assert.NotContains(t, rec.Body.String(), "hash")
+		assert.Equal(t, audit_model.ActionAdminListUsers, auditRepository.Entries[0].Action)
+	})
+
+	t.Run("Should reject invalid paging", func(t *testing.T) {
+		rec := serve(h, h.ListUsersHandler, http.MethodGet, "/admin/users/?limit=ten", "", "admin")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = serve(h, h.ListUsersHandler, http.MethodGet, "/admin/users/?offset=-", "", "admin")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should return repository error", func(t *testing.T) {
+		rec := serve(h, h.ListUsersHandler, http.MethodGet, "/admin/users/?q=error", "", "admin")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestGetUserHandler(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+
+	adminService := admin_service.NewAdminService(userRepository, mocks.NewMockUrlRepository(), mocks.NewMockAuditRepository())
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+
+	rec := serve(h, h.GetUserHandler, http.MethodGet, "/admin/users/2/", "", "admin", "id", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"username":"alice"`)
+
+	rec = serve(h, h.GetUserHandler, http.MethodGet, "/admin/users/99/", "", "admin", "id", "99")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(h, h.GetUserHandler, http.MethodGet, "/admin/users/x/", "", "admin", "id", "x")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, h.GetUserURLsHandler, http.MethodGet, "/admin/users/2/urls/", "", "admin", "id", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+}
+
+func TestSetRoleHandler(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+
+	adminService := admin_service.NewAdminService(userRepository, mocks.NewMockUrlRepository(), mocks.NewMockAuditRepository())
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+
+	t.Run("Should change role", func(t *testing.T) {
+		rec := serve(h, h.SetRoleHandler, http.MethodPut, "/admin/users/2/role/", `{"role":"moderator"}`, "admin", "id", "2")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "moderator", userRepository.Users[2].Role)
+	})
+
+	t.Run("Should reject invalid role", func(t *testing.T) {
+		rec := serve(h, h.SetRoleHandler, http.MethodPut, "/admin/users/2/role/", `{"role":""}`, "admin", "id", "2")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should not demote self", func(t *testing.T) {
+		rec := serve(h, h.SetRoleHandler, http.MethodPut, "/admin/users/1/role/", `{"role":"user"}`, "admin", "id", "1")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+}
+
+func TestDisableUserHandler(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+
+	adminService := admin_service.NewAdminService(userRepository, mocks.NewMockUrlRepository(), mocks.NewMockAuditRepository())
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+
+	rec := serve(h, h.DisableUserHandler, http.MethodPost, "/admin/users/2/disable/", "", "admin", "id", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, userRepository.Users[2].Disabled)
+
+	rec = serve(h, h.EnableUserHandler, http.MethodPost, "/admin/users/2/enable/", "", "admin", "id", "2")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, userRepository.Users[2].Disabled)
+
+	rec = serve(h, h.DisableUserHandler, http.MethodPost, "/admin/users/1/disable/", "", "admin", "id", "1")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestURLHandlers(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash"})
+	urlRepository := mocks.NewMockUrlRepository()
+	owner := uint(2)
+	urlRepository.CreateURL("https://example.com", "abc123", &owner)
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := admin_service.NewAdminService(userRepository, urlRepository, auditRepository)
+	h := NewAdminHandler(adminService, mocks.NewMockTokenService(), userRepository)
+	webhookRepository := mocks.NewMockWebhookRepository()
+	h.Webhooks = webhook_service.NewWebhookService(webhookRepository, url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository()))
+	_, _ = webhookRepository.Create(&webhook_model.Webhook{URL: "https://hooks.example.com", UserID: 2, Events: []string{webhook_model.EventLinkUpdated}, Active: true})
+
+	rec := serve(h, h.GetURLHandler, http.MethodGet, "/admin/urls/abc123/", "", "admin", "code", "abc123")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "https://example.com")
+
+	rec = serve(h, h.DisableURLHandler, http.MethodPost, "/admin/urls/abc123/disable/", "", "admin", "code", "abc123")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, urlRepository.Urls[1].Disabled)
+
+	rec = serve(h, h.EnableURLHandler, http.MethodPost, "/admin/urls/abc123/enable/", "", "admin", "code", "abc123")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, urlRepository.Urls[1].Disabled)
+
+	// The owner of the link is notified of both changes
+	deliveries, _ := webhookRepository.ListDeliveries(1, "", 10)
+	if assert.Len(t, deliveries, 2) {
+		assert.Contains(t, deliveries[0].Payload+deliveries[1].Payload, `"disabled":true`)
+	}
+
+	rec = serve(h, h.DisableURLHandler, http.MethodPost, "/admin/urls/missing/disable/", "", "admin", "code", "missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serve(h, h.GetURLHandler, http.MethodGet, "/admin/urls/error/", "", "admin", "code", "error")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	assert.Len(t, auditRepository.Entries, 3)
+}
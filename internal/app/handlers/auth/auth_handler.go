@@ -1,11 +1,17 @@
 package auth_handler
 
 import (
+	"errors"
 	"github.com/labstack/echo/v4"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 	"url-shortener/internal/app/models/user"
 	"url-shortener/internal/app/services/auth"
+	"url-shortener/internal/app/services/email"
+	"url-shortener/internal/app/services/lockout"
 	"url-shortener/internal/app/services/token"
 )
 
@@ -14,11 +20,15 @@ type Handler struct {
 	// Service is the auth service instance.
 	Service         *auth_service.Service
 	TokenRepository token_service.TokenRepository
+	// LockoutService throttles repeated failed logins.
+	LockoutService *lockout_service.Service
+	// EmailService handles email addresses and their verification.
+	EmailService *email_service.Service
 }
 
 // NewAuthHandler creates a new instance of UserHandler with the given auth service.
-func NewAuthHandler(service *auth_service.Service, tokenRepository token_service.TokenRepository) *Handler {
-	return &Handler{Service: service, TokenRepository: tokenRepository}
+func NewAuthHandler(service *auth_service.Service, tokenRepository token_service.TokenRepository, lockoutService *lockout_service.Service, emailService *email_service.Service) *Handler {
+	return &Handler{Service: service, TokenRepository: tokenRepository, LockoutService: lockoutService, EmailService: emailService}
 }
 
 // CreateUserHandler handles HTTP requests to create a new auth.
@@ -36,9 +46,22 @@ func (h *Handler) CreateUserHandler(c echo.Context) error {
 	// Call the auth service to create the auth
 	userVal, err := h.Service.CreateUser(user)
 	if err != nil {
+		if errors.Is(err, user_model.ErrPasswordPolicy) || errors.Is(err, user_model.ErrInvalidEmail) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, user_model.ErrEmailAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	// Send the verification link; the account works without it so a delivery failure is not fatal
+	if userVal.Email != "" {
+		if err := h.EmailService.SendVerification(userVal); err != nil {
+			c.Logger().Error("[AUTH] Error sending verification email: ", err)
+		}
+	}
+
 	// Generate a token for the created auth
 	token, err := h.TokenRepository.GenerateToken(userVal)
 	if err != nil {
@@ -60,11 +83,25 @@ func (h *Handler) LoginUserHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Username and password are required"})
 	}
 
+	// Reject the attempt while the username or IP address is backing off or locked
+	ipAddress := c.RealIP()
+	if wait, err := h.LockoutService.Check(user.Username, ipAddress); err != nil {
+		return tooManyAttempts(c, wait, err)
+	}
+
 	// Call the auth service to log in the auth
 	userVal, err := h.Service.LoginUser(user)
 	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidCredentials) {
+			h.LockoutService.RegisterFailure(user.Username, ipAddress)
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, user_model.ErrAccountDisabled) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
+	h.LockoutService.Reset(user.Username)
 
 	// Generate a token for the authenticated auth
 	token, err := h.TokenRepository.GenerateToken(userVal)
@@ -94,16 +131,222 @@ func (h *Handler) RefreshTokenHandler(c echo.Context) error {
 	token = parts[1]
 	// Call the authentication service to validate the token and get the user ID
 	id, err := h.TokenRepository.ValidateToken(token)
+	if errors.Is(err, user_model.ErrAccountDisabled) {
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	}
 	if err != nil {
 		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
 	}
 	userID = &id
 
+	// Reload the auth so the new token carries its current role
+	userVal, err := h.Service.GetActiveUser(*userID)
+	if err != nil {
+		if errors.Is(err, user_model.ErrAccountDisabled) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, user_model.ErrUserNotFound) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
 	// Generate a new token for the authenticated auth
-	token, err = h.TokenRepository.GenerateToken(&user_model.User{ID: *userID})
+	token, err = h.TokenRepository.GenerateToken(userVal)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{"token": token})
 }
+
+// ChangePasswordHandler handles HTTP requests to change the password of the authenticated auth.
+func (h *Handler) ChangePasswordHandler(c echo.Context) error {
+	userID, message := h.authenticate(c)
+	if message != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+	}
+
+	// Parse request body to extract the passwords
+	var change user_model.PasswordChange
+	if err := c.Bind(&change); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if change.CurrentPassword == "" || change.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Current and new password are required"})
+	}
+
+	userVal, err := h.Service.GetActiveUser(userID)
+	if err != nil {
+		if errors.Is(err, user_model.ErrAccountDisabled) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Guesses of the current password count towards the lockout of the account like failed logins
+	ipAddress := c.RealIP()
+	if wait, err := h.LockoutService.Check(userVal.Username, ipAddress); err != nil {
+		return tooManyAttempts(c, wait, err)
+	}
+
+	// Call the auth service to change the password
+	err = h.Service.ChangePassword(userID, change)
+	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidCredentials) {
+			h.LockoutService.RegisterFailure(userVal.Username, ipAddress)
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, user_model.ErrPasswordPolicy) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	h.LockoutService.Reset(userVal.Username)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password changed"})
+}
+
+// ForgotPasswordHandler handles HTTP requests to send a password reset token.
+// The response is the same whether or not the account exists.
+func (h *Handler) ForgotPasswordHandler(c echo.Context) error {
+	// Parse request body to extract the username
+	var request user_model.PasswordResetRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if request.Username == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Username is required"})
+	}
+
+	// Call the auth service to issue and send the reset token
+	if err := h.Service.RequestPasswordReset(request.Username); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "If the account exists, a password reset token has been sent"})
+}
+
+// ResetPasswordHandler handles HTTP requests to set a new password with a reset token.
+func (h *Handler) ResetPasswordHandler(c echo.Context) error {
+	// Parse request body to extract the token and new password
+	var reset user_model.PasswordReset
+	if err := c.Bind(&reset); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if reset.Token == "" || reset.NewPassword == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Token and new password are required"})
+	}
+
+	// Call the auth service to reset the password
+	err := h.Service.ResetPassword(reset)
+	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidResetToken) || errors.Is(err, user_model.ErrPasswordPolicy) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Password reset"})
+}
+
+// VerifyEmailHandler handles HTTP requests from email verification links.
+func (h *Handler) VerifyEmailHandler(c echo.Context) error {
+	token := c.QueryParam("token")
+	if token == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Token is required"})
+	}
+
+	// Call the email service to verify the address
+	err := h.EmailService.Verify(token)
+	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidVerificationToken) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "Email verified"})
+}
+
+// ChangeEmailHandler handles HTTP requests to change the email address of the authenticated auth.
+func (h *Handler) ChangeEmailHandler(c echo.Context) error {
+	userID, message := h.authenticate(c)
+	if message != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+	}
+
+	// Parse request body to extract the email address
+	var change user_model.EmailChange
+	if err := c.Bind(&change); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if change.Email == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Email is required"})
+	}
+
+	// Call the email service to change the address and send a verification link
+	err := h.EmailService.ChangeEmail(userID, change.Email)
+	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidEmail) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, user_model.ErrEmailAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
+// ResendVerificationHandler handles HTTP requests to resend the verification link of the authenticated auth.
+func (h *Handler) ResendVerificationHandler(c echo.Context) error {
+	userID, message := h.authenticate(c)
+	if message != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": message})
+	}
+
+	err := h.EmailService.ResendVerification(userID)
+	if err != nil {
+		if errors.Is(err, user_model.ErrInvalidEmail) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "No email address on the account"})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
+// authenticate validates the bearer token of the request and returns its user ID.
+// On failure it returns the message to send with a 401 response.
+func (h *Handler) authenticate(c echo.Context) (uint, string) {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return 0, "Token is required"
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return 0, "Invalid token"
+	}
+
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenRepository.ValidateToken(parts[1])
+	if err != nil {
+		return 0, "Invalid token"
+	}
+
+	return userID, ""
+}
+
+// tooManyAttempts answers an attempt rejected by the lockout service, telling the client when to retry.
+func tooManyAttempts(c echo.Context, wait time.Duration, err error) error {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return c.JSON(http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+}
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	"url-shortener/internal/app/models/user"
 	"url-shortener/internal/app/services/auth"
+	"url-shortener/internal/app/services/email"
+	"url-shortener/internal/app/services/lockout"
 	"url-shortener/internal/mocks"
 
 	"github.com/labstack/echo/v4"
@@ -21,13 +25,18 @@ var (
 	loginEndpoint    = userEndpoint + "login/"
 )
 
+// lockoutConfig disables backoff so that failed logins in one test do not throttle the next.
+var lockoutConfig = lockout_service.Config{MaxUserAttempts: 3, MaxIPAttempts: 100, LockoutDuration: time.Minute, Window: time.Hour}
+
 // TestCreateUserHandler tests the CreateUserHandler method of the user handler.
 func TestCreateUserHandler(t *testing.T) {
 	// Create mock user repository and service
 	userRepository := mocks.NewMockUserRepository()
-	userService := auth_service.NewAuthService(userRepository)
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
 	tokenService := mocks.NewMockTokenService()
-	userHandler := NewAuthHandler(userService, tokenService)
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
 
 	// Define test user data
 	userData := user_model.User{
@@ -122,15 +131,36 @@ func TestCreateUserHandler(t *testing.T) {
 		assert.NoError(t, err)
 
 	})
+
+	t.Run("Should return error for weak password", func(t *testing.T) {
+		// Prepare a mock echo.Context with a password shorter than the policy allows
+		userData.Username = "weakuser"
+		userData.Password = "short"
+		jsonData, _ := json.Marshal(userData)
+		req := httptest.NewRequest(http.MethodPost, registerEndpoint, bytes.NewReader(jsonData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		// Call CreateUserHandler with valid request body
+		err := userHandler.CreateUserHandler(c)
+
+		// Check the response
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrPasswordPolicy.Error())
+		assert.NoError(t, err)
+	})
 }
 
 // TestLoginUserHandler tests the login functionality of the user handler.
 func TestLoginUserHandler(t *testing.T) {
 	// Create mock user repository and service
 	userRepository := mocks.NewMockUserRepository()
-	userService := auth_service.NewAuthService(userRepository)
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
 	tokenService := mocks.NewMockTokenService()
-	userHandler := NewAuthHandler(userService, tokenService)
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
 
 	// Define test user data
 	userData := user_model.User{
@@ -160,6 +190,23 @@ func TestLoginUserHandler(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Should return forbidden for disabled auth", func(t *testing.T) {
+		disabled, _ := userService.CreateUser(user_model.User{Username: "disabled", Password: "password123"})
+		_ = userRepository.SetDisabled(disabled.ID, true)
+
+		jsonData, _ := json.Marshal(user_model.User{Username: "disabled", Password: "password123"})
+		req := httptest.NewRequest(http.MethodPost, loginEndpoint, bytes.NewReader(jsonData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		err := userHandler.LoginUserHandler(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrAccountDisabled.Error())
+		assert.NoError(t, err)
+	})
+
 	t.Run("Should return error for invalid body", func(t *testing.T) {
 		// Prepare a mock echo.Context with invalid request body
 		req := httptest.NewRequest(http.MethodPost, loginEndpoint, bytes.NewReader([]byte("invalid")))
@@ -189,8 +236,8 @@ func TestLoginUserHandler(t *testing.T) {
 		err := userHandler.LoginUserHandler(c)
 
 		// Check the response
-		assert.Equal(t, http.StatusInternalServerError, rec.Code)
-		assert.Contains(t, rec.Body.String(), user_model.ErrUserNotFound.Error())
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrInvalidCredentials.Error())
 		assert.NoError(t, err)
 	})
 
@@ -207,8 +254,8 @@ func TestLoginUserHandler(t *testing.T) {
 		err := userHandler.LoginUserHandler(c)
 
 		// Check the response
-		assert.Equal(t, http.StatusInternalServerError, rec.Code)
-		assert.Contains(t, rec.Body.String(), user_model.ErrUserNotFound.Error())
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrInvalidCredentials.Error())
 		assert.NoError(t, err)
 	})
 
@@ -262,12 +309,61 @@ func TestLoginUserHandler(t *testing.T) {
 	})
 }
 
+func TestLoginUserHandlerLockout(t *testing.T) {
+	// Create mock user repository and service
+	userRepository := mocks.NewMockUserRepository()
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
+	tokenService := mocks.NewMockTokenService()
+	auditRepository := mocks.NewMockAuditRepository()
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, auditRepository)
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
+
+	userData := user_model.User{
+		Username: "testuser",
+		Password: "password123",
+	}
+	_, err := userService.CreateUser(userData)
+	assert.NoError(t, err)
+
+	login := func(password string) *httptest.ResponseRecorder {
+		jsonData, _ := json.Marshal(user_model.User{Username: userData.Username, Password: password})
+		req := httptest.NewRequest(http.MethodPost, loginEndpoint, bytes.NewReader(jsonData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, userHandler.LoginUserHandler(c))
+		return rec
+	}
+
+	t.Run("Should reset failures after successful login", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, login("wrongpassword").Code)
+		assert.Equal(t, http.StatusOK, login(userData.Password).Code)
+		assert.Equal(t, http.StatusUnauthorized, login("wrongpassword").Code)
+		assert.Equal(t, http.StatusUnauthorized, login("wrongpassword").Code)
+		assert.Empty(t, auditRepository.Entries)
+	})
+
+	t.Run("Should lock account after repeated failures", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, login("wrongpassword").Code)
+
+		rec := login(userData.Password)
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Contains(t, rec.Body.String(), lockout_service.ErrTooManyAttempts.Error())
+		assert.Len(t, auditRepository.Entries, 1)
+	})
+}
+
 func TestRefreshTokenHandler(t *testing.T) {
 	// Create mock user repository and service
 	userRepository := mocks.NewMockUserRepository()
-	userService := auth_service.NewAuthService(userRepository)
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
 	tokenService := mocks.NewMockTokenService()
-	userHandler := NewAuthHandler(userService, tokenService)
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
 
 	// Define test user data
 	userData := user_model.User{
@@ -283,7 +379,7 @@ func TestRefreshTokenHandler(t *testing.T) {
 
 		// Prepare a mock echo.Context with valid request body
 		req := httptest.NewRequest(http.MethodPost, userEndpoint+"refresh/", nil)
-		req.Header.Set(echo.HeaderAuthorization, "Bearer valid_token")
+		req.Header.Set(echo.HeaderAuthorization, "Bearer mockToken")
 		rec := httptest.NewRecorder()
 		c := echo.New().NewContext(req, rec)
 
@@ -340,8 +436,36 @@ func TestRefreshTokenHandler(t *testing.T) {
 		err := userHandler.RefreshTokenHandler(c)
 
 		// Check the response
-		assert.Equal(t, http.StatusInternalServerError, rec.Code)
-		assert.Contains(t, rec.Body.String(), `{"error":"invalid token"}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `{"error":"Invalid token"}`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error for disabled user", func(t *testing.T) {
+		_ = userRepository.SetDisabled(1, true)
+		defer func() { _ = userRepository.SetDisabled(1, false) }()
+
+		req := httptest.NewRequest(http.MethodPost, userEndpoint+"refresh/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer mockToken")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		err := userHandler.RefreshTokenHandler(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return error for token of disabled account", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, userEndpoint+"refresh/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer disabled")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		err := userHandler.RefreshTokenHandler(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrAccountDisabled.Error())
 		assert.NoError(t, err)
 	})
 
@@ -362,3 +486,237 @@ func TestRefreshTokenHandler(t *testing.T) {
 	})
 
 }
+
+func TestChangePasswordHandler(t *testing.T) {
+	// Create mock user repository and service
+	userRepository := mocks.NewMockUserRepository()
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
+	tokenService := mocks.NewMockTokenService()
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
+
+	// The mock token service resolves "mockToken" to user ID 1
+	_, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123"})
+	assert.NoError(t, err)
+
+	changePassword := func(authorization string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, userEndpoint+"password/change/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, userHandler.ChangePasswordHandler(c))
+		return rec
+	}
+
+	t.Run("Should change password", func(t *testing.T) {
+		rec := changePassword("Bearer mockToken", `{"current_password":"password123","new_password":"newpassword123"}`)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should return error for wrong current password", func(t *testing.T) {
+		rec := changePassword("Bearer mockToken", `{"current_password":"password123","new_password":"newpassword123"}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrInvalidCredentials.Error())
+	})
+
+	t.Run("Should return error for weak password", func(t *testing.T) {
+		rec := changePassword("Bearer mockToken", `{"current_password":"newpassword123","new_password":"short"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should return error for missing fields", func(t *testing.T) {
+		rec := changePassword("Bearer mockToken", `{"current_password":"newpassword123"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Current and new password are required")
+	})
+
+	t.Run("Should return error for invalid body", func(t *testing.T) {
+		rec := changePassword("Bearer mockToken", "invalid")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should return error for missing token", func(t *testing.T) {
+		rec := changePassword("", `{}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should return error for invalid token", func(t *testing.T) {
+		rec := changePassword("Bearer invalid", `{}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should return error for unknown user", func(t *testing.T) {
+		rec := changePassword("Bearer other", `{"current_password":"newpassword123","new_password":"newpassword456"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+
+	t.Run("Should lock out repeated wrong current passwords", func(t *testing.T) {
+		// One wrong guess was made above; the lockout locks the username after three
+		for i := 0; i < 2; i++ {
+			rec := changePassword("Bearer mockToken", `{"current_password":"guess","new_password":"newpassword456"}`)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+
+		rec := changePassword("Bearer mockToken", `{"current_password":"newpassword123","new_password":"newpassword456"}`)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+		_, err := lockoutService.Check("testuser", "10.0.0.1")
+		assert.ErrorIs(t, err, lockout_service.ErrTooManyAttempts)
+	})
+}
+
+func TestPasswordResetHandlers(t *testing.T) {
+	// Create mock user repository and service
+	userRepository := mocks.NewMockUserRepository()
+	mailer := mocks.NewMockMailer()
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mailer)
+	tokenService := mocks.NewMockTokenService()
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
+
+	user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123", Email: "user@example.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, userRepository.SetEmailVerified(user.ID, "user@example.com"))
+
+	call := func(handler echo.HandlerFunc, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+	forgotEndpoint := userEndpoint + "password/forgot/"
+	resetEndpoint := userEndpoint + "password/reset/"
+
+	t.Run("Should accept unknown username without sending mail", func(t *testing.T) {
+		rec := call(userHandler.ForgotPasswordHandler, forgotEndpoint, `{"username":"unknown"}`)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, mailer.Messages)
+	})
+
+	t.Run("Should reset password with mailed token", func(t *testing.T) {
+		rec := call(userHandler.ForgotPasswordHandler, forgotEndpoint, `{"username":"testuser"}`)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+
+		message, ok := mailer.Last()
+		assert.True(t, ok)
+		body := strings.TrimSpace(message.Body)
+		token := body[strings.LastIndex(body, "\n")+1:]
+
+		rec = call(userHandler.ResetPasswordHandler, resetEndpoint, `{"token":"`+token+`","new_password":"resetpassword123"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = call(userHandler.ResetPasswordHandler, resetEndpoint, `{"token":"`+token+`","new_password":"resetpassword123"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrInvalidResetToken.Error())
+	})
+
+	t.Run("Should return error for missing fields", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ForgotPasswordHandler, forgotEndpoint, `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ForgotPasswordHandler, forgotEndpoint, "invalid").Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ResetPasswordHandler, resetEndpoint, `{"token":"abc"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ResetPasswordHandler, resetEndpoint, "invalid").Code)
+	})
+}
+
+func TestEmailHandlers(t *testing.T) {
+	// Create mock user repository and service
+	userRepository := mocks.NewMockUserRepository()
+	mailer := mocks.NewMockMailer()
+	userService := auth_service.NewAuthService(userRepository, auth_service.DefaultPasswordPolicy(), mailer)
+	tokenService := mocks.NewMockTokenService()
+	lockoutService := lockout_service.NewLockoutService(lockoutConfig, mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(userRepository, mailer, "secret", "http://localhost")
+	userHandler := NewAuthHandler(userService, tokenService, lockoutService, emailService)
+
+	call := func(handler echo.HandlerFunc, method, target, authorization, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set(echo.HeaderAuthorization, authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+	lastLink := func() string {
+		message, ok := mailer.Last()
+		assert.True(t, ok)
+		body := strings.TrimSpace(message.Body)
+		return body[strings.LastIndex(body, "\n")+1:]
+	}
+
+	t.Run("Should register with email and send verification", func(t *testing.T) {
+		rec := call(userHandler.CreateUserHandler, http.MethodPost, registerEndpoint, "", `{"username":"testuser","password":"password123","email":"user@example.com"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		message, _ := mailer.Last()
+		assert.Equal(t, "user@example.com", message.To)
+	})
+
+	t.Run("Should return error for duplicate email", func(t *testing.T) {
+		rec := call(userHandler.CreateUserHandler, http.MethodPost, registerEndpoint, "", `{"username":"other","password":"password123","email":"user@example.com"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Should return error for invalid email", func(t *testing.T) {
+		rec := call(userHandler.CreateUserHandler, http.MethodPost, registerEndpoint, "", `{"username":"other","password":"password123","email":"invalid"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should verify email from link", func(t *testing.T) {
+		link := strings.TrimPrefix(lastLink(), "http://localhost")
+
+		rec := call(userHandler.VerifyEmailHandler, http.MethodGet, link, "", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, userRepository.Users[1].EmailVerified)
+	})
+
+	t.Run("Should return error for invalid verification token", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.VerifyEmailHandler, http.MethodGet, userEndpoint+"email/verify/?token=invalid", "", "").Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.VerifyEmailHandler, http.MethodGet, userEndpoint+"email/verify/", "", "").Code)
+	})
+
+	t.Run("Should change email and require re-verification", func(t *testing.T) {
+		rec := call(userHandler.ChangeEmailHandler, http.MethodPost, userEndpoint+"email/", "Bearer mockToken", `{"email":"new@example.com"}`)
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Equal(t, "new@example.com", userRepository.Users[1].Email)
+		assert.False(t, userRepository.Users[1].EmailVerified)
+	})
+
+	t.Run("Should resend verification", func(t *testing.T) {
+		count := len(mailer.Messages)
+
+		rec := call(userHandler.ResendVerificationHandler, http.MethodPost, userEndpoint+"email/resend/", "Bearer mockToken", "")
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Len(t, mailer.Messages, count+1)
+	})
+
+	t.Run("Should return error for invalid change requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, call(userHandler.ChangeEmailHandler, http.MethodPost, userEndpoint+"email/", "", `{}`).Code)
+		assert.Equal(t, http.StatusUnauthorized, call(userHandler.ResendVerificationHandler, http.MethodPost, userEndpoint+"email/resend/", "Bearer invalid", "").Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ChangeEmailHandler, http.MethodPost, userEndpoint+"email/", "Bearer mockToken", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ChangeEmailHandler, http.MethodPost, userEndpoint+"email/", "Bearer mockToken", `{"email":"invalid"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, call(userHandler.ChangeEmailHandler, http.MethodPost, userEndpoint+"email/", "Bearer mockToken", "invalid").Code)
+	})
+}
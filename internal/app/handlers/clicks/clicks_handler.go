@@ -1,44 +1,405 @@
 package clicks_handler
 
 import (
+	"errors"
 	"github.com/labstack/echo/v4"
+	"html/template"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
 	"url-shortener/internal/app/services/clicks"
+	"url-shortener/internal/app/services/domain"
 	token_service "url-shortener/internal/app/services/token"
 	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
 )
 
+// accessCookiePrefix prefixes the name of the cookie holding the access token of a password-protected URL.
+const accessCookiePrefix = "link_access_"
+
+// variantCookiePrefix prefixes the name of the cookie holding the split variant served to a visitor.
+const variantCookiePrefix = "link_variant_"
+
+// variantCookieTTL is how long visitors keep the variant of a split they were first served.
+const variantCookieTTL = 30 * 24 * time.Hour
+
+// passwordForm is the page asking for the password of a protected URL.
+var passwordForm = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Password required</title></head>
+<body>
+<form method="post" action="{{.Action}}">
+<p>This link is password protected.</p>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<input type="password" name="password" aria-label="Password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// appPage is the page opening the app of a deep link, then its fallback when the app did not open.
+// Browsers leaving for the app hide the page, which cancels the fallback.
+var appPage = template.Must(template.New("app").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Opening the app</title></head>
+<body>
+<p>Opening the app&hellip;</p>
+<p><a href="{{.URI}}">Open the app</a> or <a href="{{.Fallback}}">continue in the browser</a>.</p>
+<script>
+var fallback = setTimeout(function () { window.location.replace({{.Fallback}}); }, {{.Delay}});
+document.addEventListener("visibilitychange", function () { if (document.hidden) { clearTimeout(fallback); } });
+window.location.href = {{.URI}};
+</script>
+</body>
+</html>
+`))
+
+// previewPage is the page giving link crawlers the OpenGraph and Twitter card tags of a URL. Browsers
+// mistaken for crawlers follow the refresh to the destination.
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:title" content="{{.Title}}">
+{{if .Description}}<meta property="og:description" content="{{.Description}}">
+<meta name="description" content="{{.Description}}">
+{{end}}{{if .Image}}<meta property="og:image" content="{{.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{.Image}}">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<meta name="twitter:title" content="{{.Title}}">
+{{if .Description}}<meta name="twitter:description" content="{{.Description}}">
+{{end}}<meta http-equiv="refresh" content="0; url={{.Destination}}">
+</head>
+<body><a href="{{.Destination}}">{{.Title}}</a></body>
+</html>
+`))
+
+// interstitialPage tells visitors the host they are leaving to, redirecting them after the delay of the
+// URL or when they continue.
+var interstitialPage = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>You are leaving to {{.Host}}</title>
+{{if .Delay}}<meta http-equiv="refresh" content="{{.Delay}}; url={{.Destination}}">{{end}}
+</head>
+<body>
+<p>You are leaving to <strong>{{.Host}}</strong>.</p>
+{{if .Title}}<p>{{.Title}}</p>{{end}}
+{{if .Delay}}<p>You will be redirected in {{.Delay}} seconds.</p>{{end}}
+<p><a href="{{.Destination}}" rel="noreferrer">Continue</a></p>
+</body>
+</html>
+`))
+
+// notActivePage is the page shown before a link opens.
+var notActivePage = template.Must(template.New("not-active").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>Coming soon</title></head>
+<body>
+<p>This link is not active yet.</p>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{if .ActiveFrom}}<p>It opens on <time datetime="{{.ActiveFrom.UTC.Format "2006-01-02T15:04:05Z07:00"}}">{{.ActiveFrom.UTC.Format "2 January 2006 at 15:04 UTC"}}</time>.</p>{{end}}
+</body>
+</html>
+`))
+
+// appFallbackDelay is how long the app page waits for the app to open before going to the fallback, in milliseconds.
+const appFallbackDelay = 1500
+
 // Handler handles HTTP requests related to clicks.
 type Handler struct {
 	// Service is the click service instance.
 	Service      *clicks_service.Service
 	UrlService   *url_service.Service
 	TokenService token_service.TokenRepository
+	// CountryHeader is the request header holding the country of the visitor for redirect rules,
+	// set by the CDN or proxy in front of the server.
+	CountryHeader string
+	// AppleAppSiteAssociation and AssetLinks are served from /.well-known for iOS and Android to open short
+	// links in apps; nil when not configured.
+	AppleAppSiteAssociation []byte
+	AssetLinks              []byte
+	// Domains resolves the codes of links requested on custom domains, nil when they are not served.
+	Domains *domain_service.Service
+	// Webhooks notifies the webhooks of link owners of clicks, nil when disabled.
+	Webhooks *webhook_service.Service
 }
 
 // NewClickHandler creates a new instance of ClickHandler with the given click service.
+// It reads countries from url_service.DefaultCountryHeader until another header is set.
 func NewClickHandler(service *clicks_service.Service, urlService *url_service.Service, tokenService token_service.TokenRepository) *Handler {
-	return &Handler{Service: service, UrlService: urlService, TokenService: tokenService}
+	return &Handler{Service: service, UrlService: urlService, TokenService: tokenService, CountryHeader: url_service.DefaultCountryHeader}
 }
 
 // CreateClickHandler handles HTTP requests to create a new click.
 func (h *Handler) CreateClickHandler(c echo.Context) error {
 	// Get the shortened URL from the request
-	shortURL := c.Param("id")
+	shortURL, err := h.shortURL(c)
+	if err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
 
 	// Call the URL service to get the original URL
 	originalURL, err := h.UrlService.GetOriginalURL(shortURL)
+	if err != nil {
+		if errors.Is(err, url_model.ErrURLDisabled) || errors.Is(err, url_model.ErrURLExpired) {
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrURLNotActive) {
+			return h.notActive(c, shortURL)
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	// Password-protected URLs need the access cookie set by UnlockHandler
+	var token string
+	if cookie, err := c.Cookie(accessCookiePrefix + shortURL); err == nil {
+		token = cookie.Value
+	}
+	allowed, err := h.UrlService.CheckAccess(shortURL, token)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if !allowed {
+		return renderPasswordForm(c, http.StatusOK, "")
+	}
+
+	// Crawlers unfurling the link get its preview instead of a click
+	request := c.Request()
+	preview, err := h.UrlService.LinkPreview(shortURL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if preview != nil && url_service.IsCrawler(request.UserAgent()) {
+		return renderPreview(c, preview, originalURL)
+	}
+
+	// Redirect rules and splits may send the visitor somewhere else than the original URL
+	visitor := url_service.NewVisitor(request.UserAgent(), request.Header.Get("Accept-Language"), request.Header.Get(h.CountryHeader), time.Now())
+	visitor.IPAddress = c.RealIP()
+	var assigned string
+	if cookie, err := c.Cookie(variantCookiePrefix + shortURL); err == nil {
+		assigned = cookie.Value
+	}
+	match, err := h.UrlService.Resolve(shortURL, originalURL, visitor, assigned)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	if match.Cookie && match.Variant != assigned {
+		c.SetCookie(&http.Cookie{
+			Name:     variantCookiePrefix + shortURL,
+			Value:    match.Variant,
+			Path:     request.URL.Path,
+			Expires:  time.Now().Add(variantCookieTTL),
+			HttpOnly: true,
+			Secure:   c.IsTLS(),
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+
+	// UTM parameters and the query of the visit are added to whichever destination was chosen
+	destination, err := h.UrlService.Track(shortURL, match.Destination, c.QueryParams())
+	if err != nil {
+		if !errors.Is(err, url_model.ErrInvalidFinalURL) {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		c.Logger().Warnf("[REDIRECT] Redirecting %s without its tracking parameters: %v", shortURL, err)
+	}
+
+	// Phones try the app of deep links before the destination
+	app, err := h.UrlService.AppTarget(shortURL, visitor.Device, destination)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
 	// Call the click service to create the click
-	err = h.Service.CreateClick(shortURL, c.RealIP())
+	err = h.Service.CreateClick(shortURL, c.RealIP(), match.Variant, match.Revision)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
-	return c.JSON(http.StatusMovedPermanently, map[string]string{"Location": originalURL})
+	h.Webhooks.PublishClick(webhook_model.Click{
+		ShortenedURL: shortURL,
+		Destination:  destination,
+		Variant:      match.Variant,
+		Country:      visitor.Country,
+		Device:       visitor.Device,
+		Referrer:     request.Referer(),
+		ClickedAt:    visitor.Time.UTC().Truncate(time.Second),
+	})
+
+	if app != nil {
+		return renderAppPage(c, app)
+	}
+	if preview != nil && preview.Interstitial {
+		return renderInterstitial(c, preview, destination)
+	}
+	return c.Redirect(match.RedirectType, destination)
+}
+
+// shortURL returns the short code of the link requested. On custom domains the code of the path is
+// scoped to the domain of the Host header.
+func (h *Handler) shortURL(c echo.Context) (string, error) {
+	if h.Domains == nil {
+		return c.Param("id"), nil
+	}
+	return h.Domains.Resolve(c.Request().Host, c.Param("id"))
+}
+
+// notActive answers visitors of a link that is not active yet as the link is set to.
+func (h *Handler) notActive(c echo.Context, shortURL string) error {
+	activation, err := h.UrlService.NotActive(shortURL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	switch activation.NotActive.Mode {
+	case url_model.NotActiveRedirect:
+		return c.Redirect(http.StatusFound, activation.NotActive.URL)
+	case url_model.NotActivePage:
+		var page strings.Builder
+		data := map[string]interface{}{"Message": activation.NotActive.Message, "ActiveFrom": activation.ActiveFrom}
+		if err := notActivePage.Execute(&page, data); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		c.Response().Header().Set("Cache-Control", "no-store")
+		return c.HTML(http.StatusOK, page.String())
+	default:
+		// Unannounced links must not be told apart from missing ones
+		return c.JSON(http.StatusNotFound, map[string]string{"error": url_model.ErrURLNotFound.Error()})
+	}
+}
+
+// renderPreview renders the OpenGraph and Twitter card tags of the URL for link crawlers.
+func renderPreview(c echo.Context, preview *url_model.Preview, destination string) error {
+	request := c.Request()
+	title := preview.Title
+	if title == "" {
+		title = destination
+	}
+
+	var page strings.Builder
+	err := previewPage.Execute(&page, map[string]string{
+		"URL":         c.Scheme() + "://" + request.Host + request.URL.Path,
+		"Title":       title,
+		"Description": preview.Description,
+		"Image":       preview.Image,
+		"Destination": destination,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// renderInterstitial renders the page telling the visitor where they are leaving to.
+func renderInterstitial(c echo.Context, preview *url_model.Preview, destination string) error {
+	host := destination
+	if u, err := url.Parse(destination); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+
+	var page strings.Builder
+	err := interstitialPage.Execute(&page, map[string]interface{}{
+		"Host":        host,
+		"Title":       preview.Title,
+		"Delay":       preview.Delay,
+		"Destination": destination,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// renderAppPage renders the page opening the app, then its fallback.
+func renderAppPage(c echo.Context, app *url_model.AppTarget) error {
+	var page strings.Builder
+	// App URIs are checked when saved; the template would replace schemes it does not know otherwise
+	err := appPage.Execute(&page, map[string]interface{}{
+		"URI":      template.URL(app.URI), // #nosec G203 -- unsafe schemes are rejected by url_service.SetDeepLink
+		"Fallback": app.Fallback,
+		"Delay":    appFallbackDelay,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.HTML(http.StatusOK, page.String())
+}
+
+// AppleAppSiteAssociationHandler serves the apple-app-site-association file, when configured.
+func (h *Handler) AppleAppSiteAssociationHandler(c echo.Context) error {
+	return serveAppLinkFile(c, h.AppleAppSiteAssociation)
+}
+
+// AssetLinksHandler serves the assetlinks.json file, when configured.
+func (h *Handler) AssetLinksHandler(c echo.Context) error {
+	return serveAppLinkFile(c, h.AssetLinks)
+}
+
+func serveAppLinkFile(c echo.Context, file []byte) error {
+	if file == nil {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Not configured"})
+	}
+	return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, file)
+}
+
+// UnlockHandler handles the password form of a protected URL.
+// A correct password sets a short-lived access cookie and redirects back to the URL.
+func (h *Handler) UnlockHandler(c echo.Context) error {
+	shortURL, err := h.shortURL(c)
+	if err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	token, err := h.UrlService.Unlock(shortURL, c.FormValue("password"))
+	if err != nil {
+		if errors.Is(err, url_model.ErrIncorrectLinkPassword) {
+			return renderPasswordForm(c, http.StatusUnauthorized, err.Error())
+		}
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.SetCookie(&http.Cookie{
+		Name:     accessCookiePrefix + shortURL,
+		Value:    token,
+		Path:     c.Request().URL.Path,
+		Expires:  time.Now().Add(h.UrlService.AccessTTL),
+		HttpOnly: true,
+		Secure:   c.IsTLS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusSeeOther, c.Request().URL.Path)
+}
+
+// renderPasswordForm renders the password form posting back to the current path.
+func renderPasswordForm(c echo.Context, status int, message string) error {
+	var page strings.Builder
+	err := passwordForm.Execute(&page, map[string]string{"Action": c.Request().URL.Path, "Error": message})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	// The form must not be framed by other sites or cached by proxies
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("X-Frame-Options", "DENY")
+	return c.HTML(status, page.String())
 }
 
 // GetUserClickDetailsHandler handles HTTP requests to get click details for a user.
@@ -67,8 +428,15 @@ func (h *Handler) GetUserClickDetailsHandler(c echo.Context) error {
 	}
 	userID = id
 
+	// Creators of personal links and all members of the link's workspace may see its analytics
 	err = h.UrlService.GetUserWithShortURL(userID, shortURL)
 	if err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
@@ -79,3 +447,72 @@ func (h *Handler) GetUserClickDetailsHandler(c echo.Context) error {
 	}
 	return c.JSON(http.StatusOK, clickDetails)
 }
+
+// GetVariantStatsHandler handles HTTP requests to get the clicks of each split variant of a URL.
+func (h *Handler) GetVariantStatsHandler(c echo.Context) error {
+	shortURL := c.Param("id")
+
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	// Like the click details, variant stats are visible to everyone who may see the link's analytics
+	split, err := h.UrlService.GetSplit(userID, shortURL)
+	if err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	stats, err := h.Service.GetVariantStats(shortURL, split)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetRevisionStatsHandler handles HTTP requests to get the clicks of each revision of a URL.
+func (h *Handler) GetRevisionStatsHandler(c echo.Context) error {
+	shortURL := c.Param("id")
+
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	history, err := h.UrlService.GetHistory(userID, shortURL)
+	if err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	stats, err := h.Service.GetRevisionStats(shortURL, history)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	return c.JSON(http.StatusOK, stats)
+}
@@ -5,9 +5,18 @@ import (
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+	clicks_model "url-shortener/internal/app/models/clicks"
+	domain_model "url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/models/workspace"
 	"url-shortener/internal/app/services/clicks"
+	domain_service "url-shortener/internal/app/services/domain"
 	url_service "url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
 	"url-shortener/internal/mocks"
 )
 
@@ -17,7 +26,7 @@ func TestCreateClick(t *testing.T) {
 	clickRepository := mocks.NewMockClicksRepository()
 	clickService := clicks_service.NewClicksService(clickRepository)
 	mockRepository := mocks.NewMockUrlRepository()
-	mockService := url_service.NewURLService(mockRepository)
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
 	tokenService := mocks.NewMockTokenService()
 
 	clickHandler := NewClickHandler(clickService, mockService, tokenService)
@@ -41,7 +50,7 @@ func TestCreateClick(t *testing.T) {
 		// Assertions
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
-		assert.JSONEq(t, `{"Location":"https://www.google.com"}`, rec.Body.String())
+		assert.Equal(t, "https://www.google.com", rec.Header().Get(echo.HeaderLocation))
 	})
 
 	t.Run("Should return error for error URL", func(t *testing.T) {
@@ -86,6 +95,70 @@ func TestCreateClick(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Should return gone for disabled URL", func(t *testing.T) {
+		_, _ = mockRepository.CreateURL("https://www.example.com", "disabled", nil)
+		_ = mockRepository.SetDisabled("disabled", true)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/:id", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/:id")
+
+		c.SetParamNames("id")
+		c.SetParamValues("disabled")
+
+		err := clickHandler.CreateClickHandler(c)
+
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should return gone for expired URL", func(t *testing.T) {
+		expiredAt := time.Now().Add(-time.Minute)
+		_ = mockRepository.CreateURLs([]url_model.NewURL{{OriginalURL: "https://www.example.com", ShortCode: "expired", ExpiresAt: &expiredAt}})
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/:id", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/:id")
+
+		c.SetParamNames("id")
+		c.SetParamValues("expired")
+
+		err := clickHandler.CreateClickHandler(c)
+
+		assert.Equal(t, http.StatusGone, rec.Code)
+		assert.Contains(t, rec.Body.String(), url_model.ErrURLExpired.Error())
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should redirect by matching rule", func(t *testing.T) {
+		_, _ = mockRepository.CreateURL("https://www.example.com", "localized", nil)
+		mockRepository.Rules["localized"] = []url_model.Rule{
+			{Conditions: url_model.RuleConditions{Countries: []string{"TR"}}, Destination: "https://www.example.com/tr"},
+		}
+
+		for country, location := range map[string]string{"TR": "https://www.example.com/tr", "US": "https://www.example.com"} {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/:id", nil)
+			req.Header.Set("CF-IPCountry", country)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetPath("/:id")
+
+			c.SetParamNames("id")
+			c.SetParamValues("localized")
+
+			err := clickHandler.CreateClickHandler(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+			assert.Equal(t, location, rec.Header().Get(echo.HeaderLocation))
+		}
+	})
+
 }
 
 func TestGetUserClickDetails(t *testing.T) {
@@ -93,11 +166,17 @@ func TestGetUserClickDetails(t *testing.T) {
 	clickRepository := mocks.NewMockClicksRepository()
 	clickService := clicks_service.NewClicksService(clickRepository)
 	mockRepository := mocks.NewMockUrlRepository()
-	mockService := url_service.NewURLService(mockRepository)
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	mockService := url_service.NewURLService(mockRepository, workspaceRepository)
 	tokenService := mocks.NewMockTokenService()
 
 	clickHandler := NewClickHandler(clickService, mockService, tokenService)
 
+	// The mock token service resolves "Bearer valid" to user 123
+	userID := uint(123)
+	_, _ = mockRepository.CreateURL("https://www.google.com", "success", &userID)
+	_, _ = mockRepository.CreateURL("https://www.google.com", "not_valid", &userID)
+
 	t.Run("Success", func(t *testing.T) {
 		// Create a new Echo instance
 		e := echo.New()
@@ -202,7 +281,7 @@ func TestGetUserClickDetails(t *testing.T) {
 		err := clickHandler.GetUserClickDetailsHandler(c)
 
 		// Assertions
-		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
 		assert.Contains(t, rec.Body.String(), "error")
 		assert.NoError(t, err)
 	})
@@ -228,4 +307,507 @@ func TestGetUserClickDetails(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), "error")
 		assert.NoError(t, err)
 	})
+
+	t.Run("Should return forbidden for another user's URL", func(t *testing.T) {
+		otherID := uint(1)
+		_, _ = mockRepository.CreateURL("https://www.google.com", "other", &otherID)
+
+		req := httptest.NewRequest(http.MethodGet, "/other/details/", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("other")
+
+		err := clickHandler.GetUserClickDetailsHandler(c)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should allow workspace members", func(t *testing.T) {
+		workspace, _ := workspaceRepository.Create(&workspace_model.Workspace{Name: "Team", CreatedBy: 1})
+		_ = workspaceRepository.AddMember(workspace.ID, userID, workspace_model.RoleViewer)
+		_, _ = mockRepository.CreateWorkspaceURL("https://www.google.com", "team", 1, workspace.ID)
+
+		req := httptest.NewRequest(http.MethodGet, "/team/details/", nil)
+		req.Header.Set("Authorization", "Bearer valid")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("team")
+
+		err := clickHandler.GetUserClickDetailsHandler(c)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NoError(t, err)
+	})
+}
+
+func TestPasswordProtectedClick(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(mocks.NewMockClicksRepository()), mockService, mocks.NewMockTokenService())
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://docs.example.com", "docs", &userID)
+	assert.NoError(t, mockService.SetPassword(userID, "docs", "secret"))
+
+	// serve runs the handler for /clicks/docs with the given form and cookies
+	serve := func(handler echo.HandlerFunc, method, form string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/clicks/docs", strings.NewReader(form))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("docs")
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should render the password form", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, http.MethodGet, "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+		assert.Contains(t, rec.Body.String(), `<form method="post" action="/clicks/docs">`)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Should reject incorrect password", func(t *testing.T) {
+		rec := serve(clickHandler.UnlockHandler, http.MethodPost, "password=guess")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "incorrect link password")
+		assert.Empty(t, rec.Result().Cookies())
+	})
+
+	t.Run("Should redirect with an access cookie", func(t *testing.T) {
+		rec := serve(clickHandler.UnlockHandler, http.MethodPost, "password=secret")
+
+		assert.Equal(t, http.StatusSeeOther, rec.Code)
+		assert.Equal(t, "/clicks/docs", rec.Header().Get(echo.HeaderLocation))
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, "/clicks/docs", cookies[0].Path)
+
+		rec = serve(clickHandler.CreateClickHandler, http.MethodGet, "", cookies[0])
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://docs.example.com", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should ignore forged cookies", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, http.MethodGet, "", &http.Cookie{Name: "link_access_docs", Value: "9999999999.forged"})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "password protected")
+	})
+}
+
+func TestSplitClick(t *testing.T) {
+	clickRepository := mocks.NewMockClicksRepository()
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(clickRepository), mockService, mocks.NewMockTokenService())
+
+	// The mock token service resolves "Bearer valid" to user 123
+	userID := uint(123)
+	_, _ = mockRepository.CreateURL("https://www.example.com", "landing", &userID)
+	mockRepository.Splits["landing"] = &url_model.Split{Sticky: url_model.StickyCookie, Variants: []url_model.Variant{
+		{Name: "a", Destination: "https://www.example.com/a", Weight: 1},
+		{Name: "b", Destination: "https://www.example.com/b", Weight: 1},
+	}}
+
+	serve := func(handler echo.HandlerFunc, cookie *http.Cookie, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/clicks/landing", nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("landing")
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should serve a variant and remember it", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, nil, "")
+
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		cookies := rec.Result().Cookies()
+		if assert.Len(t, cookies, 1) {
+			assert.Equal(t, "link_variant_landing", cookies[0].Name)
+			assert.True(t, cookies[0].HttpOnly)
+			assert.Equal(t, "https://www.example.com/"+cookies[0].Value, rec.Header().Get(echo.HeaderLocation))
+		}
+	})
+
+	t.Run("Should keep the variant of the cookie", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			rec := serve(clickHandler.CreateClickHandler, &http.Cookie{Name: "link_variant_landing", Value: "b"}, "")
+
+			assert.Equal(t, "https://www.example.com/b", rec.Header().Get(echo.HeaderLocation))
+			assert.Empty(t, rec.Result().Cookies())
+		}
+	})
+
+	t.Run("Should return clicks by variant", func(t *testing.T) {
+		clickRepository.Clicks = []clicks_model.Clicks{{ID: 1, UrlID: "landing", Variant: "b"}}
+
+		rec := serve(clickHandler.GetVariantStatsHandler, nil, "Bearer valid")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"variant":"a","destination":"https://www.example.com/a","weight":1,"clicks":0},
+			{"variant":"b","destination":"https://www.example.com/b","weight":1,"clicks":1}]`, rec.Body.String())
+	})
+
+	t.Run("Should return variant stats errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(clickHandler.GetVariantStatsHandler, nil, "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(clickHandler.GetVariantStatsHandler, nil, "Bearer invalid").Code)
+		assert.Equal(t, http.StatusForbidden, serve(clickHandler.GetVariantStatsHandler, nil, "Bearer mockToken").Code)
+	})
+}
+
+func TestTrackedClick(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(mocks.NewMockClicksRepository()), mockService, mocks.NewMockTokenService())
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/sale?ref=site", "sale", &userID)
+	mockRepository.Tracking["sale"] = &url_model.Tracking{UTM: url_model.UTM{Source: "newsletter"}, PassQuery: true, QueryConflict: url_model.QueryKeep}
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("sale")
+		assert.NoError(t, clickHandler.CreateClickHandler(c))
+		return rec
+	}
+
+	t.Run("Should add UTM parameters and the query of the visit", func(t *testing.T) {
+		rec := serve("/clicks/sale?ref=ad&gclid=1")
+
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.example.com/sale?ref=site&utm_source=newsletter&gclid=1", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should redirect without the query when the result is invalid", func(t *testing.T) {
+		rec := serve("/clicks/sale?q=" + strings.Repeat("a", 9000))
+
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.example.com/sale?ref=site", rec.Header().Get(echo.HeaderLocation))
+	})
+}
+
+func TestWebhookClick(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(mocks.NewMockClicksRepository()), mockService, mocks.NewMockTokenService())
+	webhookRepository := mocks.NewMockWebhookRepository()
+	clickHandler.Webhooks = webhook_service.NewWebhookService(webhookRepository, mockService)
+	_, _ = webhookRepository.Create(&webhook_model.Webhook{URL: "https://hooks.example.com", UserID: 1, Events: []string{webhook_model.EventLinkClicked}, Active: true})
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/sale", "sale", &userID)
+	mockRepository.Tracking["sale"] = &url_model.Tracking{UTM: url_model.UTM{Source: "newsletter"}}
+
+	req := httptest.NewRequest(http.MethodGet, "/clicks/sale", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")
+	req.Header.Set("Referer", "https://news.example.com/")
+	req.Header.Set(url_service.DefaultCountryHeader, "NL")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("sale")
+	assert.NoError(t, clickHandler.CreateClickHandler(c))
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+
+	// The click is published in the background, after the redirect
+	stop := make(chan struct{})
+	defer close(stop)
+	go clickHandler.Webhooks.RunClicks(stop)
+	var deliveries []webhook_model.Delivery
+	assert.Eventually(t, func() bool {
+		deliveries, _ = webhookRepository.ListDeliveries(1, "", 10)
+		return len(deliveries) == 1
+	}, time.Second, 10*time.Millisecond)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, webhook_model.EventLinkClicked, deliveries[0].EventType)
+		payload := deliveries[0].Payload
+		assert.Contains(t, payload, `"shortened_url":"sale","destination":"https://www.example.com/sale?utm_source=newsletter"`)
+		assert.Contains(t, payload, `"country":"NL","device":"ios","referrer":"https://news.example.com/"`)
+	}
+}
+
+func TestDeepLinkClick(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(mocks.NewMockClicksRepository()), mockService, mocks.NewMockTokenService())
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/product/1", "product", &userID)
+	mockRepository.DeepLinks["product"] = &url_model.DeepLink{IOS: url_model.AppTarget{URI: "shop://product/1", Fallback: "https://apps.apple.com/app/id1"}}
+
+	serve := func(handler echo.HandlerFunc, target, userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("User-Agent", userAgent)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("product")
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should try the app on its platform", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, "/clicks/product", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		assert.Contains(t, rec.Body.String(), `<a href="shop://product/1">`)
+		assert.Contains(t, rec.Body.String(), `window.location.replace("https://apps.apple.com/app/id1")`)
+	})
+
+	t.Run("Should redirect other platforms", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, "/clicks/product", "Mozilla/5.0 (Linux; Android 14; Pixel 8)")
+
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.example.com/product/1", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should serve configured association files", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(clickHandler.AssetLinksHandler, "/.well-known/assetlinks.json", "").Code)
+
+		clickHandler.AppleAppSiteAssociation = []byte(`{"applinks":{"details":[]}}`)
+		rec := serve(clickHandler.AppleAppSiteAssociationHandler, "/.well-known/apple-app-site-association", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, echo.MIMEApplicationJSON, rec.Header().Get(echo.HeaderContentType))
+		assert.JSONEq(t, `{"applinks":{"details":[]}}`, rec.Body.String())
+	})
+}
+
+func TestPreviewClick(t *testing.T) {
+	clickRepository := mocks.NewMockClicksRepository()
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(clickRepository), mockService, mocks.NewMockTokenService())
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/launch?a=1&b=2", "launch", &userID)
+	mockRepository.Previews["launch"] = &url_model.Preview{Title: "Launch <day>", Image: "https://www.example.com/card.png", Interstitial: true, Delay: 5}
+
+	serve := func(userAgent string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://sho.rt/clicks/launch", nil)
+		req.Header.Set("User-Agent", userAgent)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("launch")
+		assert.NoError(t, clickHandler.CreateClickHandler(c))
+		return rec
+	}
+
+	t.Run("Should serve OpenGraph tags to crawlers", func(t *testing.T) {
+		rec := serve("Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `<meta property="og:title" content="Launch &lt;day&gt;">`)
+		assert.Contains(t, body, `<meta property="og:url" content="http://sho.rt/clicks/launch">`)
+		assert.Contains(t, body, `<meta property="og:image" content="https://www.example.com/card.png">`)
+		assert.Contains(t, body, `<meta name="twitter:card" content="summary_large_image">`)
+	})
+
+	t.Run("Should show the interstitial to visitors", func(t *testing.T) {
+		rec := serve("Mozilla/5.0 (X11; Linux x86_64)")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		body := rec.Body.String()
+		assert.Contains(t, body, `You are leaving to <strong>www.example.com</strong>`)
+		assert.Contains(t, body, `<meta http-equiv="refresh" content="5; url=https://www.example.com/launch?a=1&amp;b=2">`)
+		assert.Contains(t, body, `<a href="https://www.example.com/launch?a=1&amp;b=2" rel="noreferrer">Continue</a>`)
+	})
+
+	t.Run("Should redirect without an interstitial", func(t *testing.T) {
+		mockRepository.Previews["launch"].Interstitial = false
+
+		rec := serve("Mozilla/5.0 (X11; Linux x86_64)")
+
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.example.com/launch?a=1&b=2", rec.Header().Get(echo.HeaderLocation))
+	})
+}
+
+func TestNotActiveClick(t *testing.T) {
+	clickRepository := mocks.NewMockClicksRepository()
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(clickRepository), mockService, mocks.NewMockTokenService())
+
+	userID := uint(1)
+	activeFrom := time.Date(2030, 1, 1, 9, 30, 0, 0, time.UTC)
+	_, _ = mockRepository.CreateURL("https://www.example.com/launch", "launch", &userID)
+	_ = mockRepository.SetActivation("launch", &activeFrom, nil)
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/clicks/launch", nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("launch")
+		assert.NoError(t, clickHandler.CreateClickHandler(c))
+		return rec
+	}
+
+	t.Run("Should answer as missing by default", func(t *testing.T) {
+		rec := serve()
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"error":"URL not found"}`, rec.Body.String())
+	})
+
+	t.Run("Should redirect to the fallback URL", func(t *testing.T) {
+		_ = mockRepository.SetNotActiveResponse("launch", &url_model.NotActiveResponse{Mode: url_model.NotActiveRedirect, URL: "https://www.example.com/soon"})
+
+		rec := serve()
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://www.example.com/soon", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should show the page with the opening time", func(t *testing.T) {
+		_ = mockRepository.SetNotActiveResponse("launch", &url_model.NotActiveResponse{Mode: url_model.NotActivePage, Message: "Tickets <soon>"})
+
+		rec := serve()
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+		body := rec.Body.String()
+		assert.Contains(t, body, `<p>Tickets &lt;soon&gt;</p>`)
+		assert.Contains(t, body, `1 January 2030 at 09:30 UTC`)
+	})
+
+	t.Run("Should not record clicks", func(t *testing.T) {
+		clicks, _ := clickRepository.GetClicks("launch")
+		assert.Empty(t, clicks)
+	})
+}
+
+func TestRevisionClick(t *testing.T) {
+	clickRepository := mocks.NewMockClicksRepository()
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(clickRepository), mockService, mocks.NewMockTokenService())
+
+	// The mock token service resolves "Bearer valid" to user 123
+	userID := uint(123)
+	_, _ = mockRepository.CreateURL("https://www.example.com/v1", "docs", &userID)
+	_, _ = mockService.ChangeDestination(userID, "docs", "https://www.example.com/v2", 307)
+
+	serve := func(handler echo.HandlerFunc, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/clicks/docs", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("docs")
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should redirect with the redirect type of the link", func(t *testing.T) {
+		rec := serve(clickHandler.CreateClickHandler, "")
+
+		assert.Equal(t, http.StatusTemporaryRedirect, rec.Code)
+		assert.Equal(t, "https://www.example.com/v2", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should return clicks by revision", func(t *testing.T) {
+		clickRepository.Clicks = []clicks_model.Clicks{{ID: 1, UrlID: "docs"}, {ID: 2, UrlID: "docs", Revision: 2}}
+
+		rec := serve(clickHandler.GetRevisionStatsHandler, "Bearer valid")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `[{"revision":2,"destination":"https://www.example.com/v2","clicks":1},
+			{"revision":1,"destination":"https://www.example.com/v1","clicks":1}]`, rec.Body.String())
+	})
+
+	t.Run("Should return revision stats errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(clickHandler.GetRevisionStatsHandler, "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(clickHandler.GetRevisionStatsHandler, "Bearer invalid").Code)
+		assert.Equal(t, http.StatusForbidden, serve(clickHandler.GetRevisionStatsHandler, "Bearer mockToken").Code)
+	})
+}
+
+func TestCustomDomainClick(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	clickHandler := NewClickHandler(clicks_service.NewClicksService(mocks.NewMockClicksRepository()), mockService, mocks.NewMockTokenService())
+	domainRepository := mocks.NewMockDomainRepository()
+	clickHandler.Domains = domain_service.NewDomainService(domainRepository, mockService)
+
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/global", "launch", &userID)
+	for _, hostname := range []string{"go.example.com", "links.example.org"} {
+		domain, _ := domainRepository.Create(&domain_model.Domain{Hostname: hostname, UserID: userID})
+		_ = domainRepository.SetVerified(domain.ID, time.Now())
+		_, err := clickHandler.Domains.ShortenURL(userID, hostname, "https://www."+hostname+"/launch", "launch")
+		assert.NoError(t, err)
+	}
+	_, _ = domainRepository.Create(&domain_model.Domain{Hostname: "pending.example.com", UserID: userID})
+
+	// serve runs the redirect handler for /launch on the given host
+	serve := func(host string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/launch", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("launch")
+		assert.NoError(t, clickHandler.CreateClickHandler(c))
+		return rec
+	}
+
+	t.Run("Should route the same code by host", func(t *testing.T) {
+		rec := serve("go.example.com")
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.go.example.com/launch", rec.Header().Get(echo.HeaderLocation))
+
+		rec = serve("Links.Example.org:443")
+		assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+		assert.Equal(t, "https://www.links.example.org/launch", rec.Header().Get(echo.HeaderLocation))
+	})
+
+	t.Run("Should serve global codes on other hosts", func(t *testing.T) {
+		for _, host := range []string{"sho.rt", "pending.example.com"} {
+			rec := serve(host)
+			assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+			assert.Equal(t, "https://www.example.com/global", rec.Header().Get(echo.HeaderLocation))
+		}
+	})
+
+	t.Run("Should return not found for codes missing on the domain", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+		req.Host = "go.example.com"
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("missing")
+		assert.NoError(t, clickHandler.CreateClickHandler(c))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
 }
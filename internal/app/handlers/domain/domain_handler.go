@@ -0,0 +1,183 @@
+package domain_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/domain"
+	"url-shortener/internal/app/services/token"
+)
+
+// Handler handles HTTP requests related to custom domains.
+type Handler struct {
+	// Service is the domain service instance.
+	Service      *domain_service.Service
+	TokenService token_service.TokenRepository
+}
+
+// NewDomainHandler creates a new instance of DomainHandler with the given domain service.
+func NewDomainHandler(service *domain_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, TokenService: tokenService}
+}
+
+// ListDomainsHandler handles HTTP requests to list the domains of the caller and their workspaces.
+func (h *Handler) ListDomainsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	domains, err := h.Service.ListDomains(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, domains)
+}
+
+// CreateDomainHandler handles HTTP requests to register a domain, answering with how to verify it.
+func (h *Handler) CreateDomainHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	domain, err := h.Service.CreateDomain(userID, req.Hostname, req.WorkspaceID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, domain)
+}
+
+// GetDomainHandler handles HTTP requests to get a domain.
+func (h *Handler) GetDomainHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	domainID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid domain ID"})
+	}
+
+	domain, err := h.Service.GetDomain(userID, domainID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, domain)
+}
+
+// VerifyDomainHandler handles HTTP requests to verify the ownership of a domain.
+func (h *Handler) VerifyDomainHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	domainID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid domain ID"})
+	}
+
+	var req domain_model.VerifyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	domain, err := h.Service.VerifyDomain(userID, domainID, req.Method)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, domain)
+}
+
+// DeleteDomainHandler handles HTTP requests to delete a domain without links.
+func (h *Handler) DeleteDomainHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	domainID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid domain ID"})
+	}
+
+	if err := h.Service.DeleteDomain(userID, domainID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListLinksHandler handles HTTP requests to list the links of a domain.
+func (h *Handler) ListLinksHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	domainID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid domain ID"})
+	}
+
+	links, err := h.Service.ListLinks(userID, domainID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, links)
+}
+
+// authenticate validates the bearer token and returns the user ID.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func paramID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	return uint(id), err
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, domain_model.ErrInvalidHostname), errors.Is(err, domain_model.ErrInvalidMethod):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain_model.ErrDomainNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain_model.ErrDomainAlreadyExists), errors.Is(err, domain_model.ErrDomainInUse):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, domain_model.ErrVerificationFailed):
+		return c.JSON(http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
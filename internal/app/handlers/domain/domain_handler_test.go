@@ -0,0 +1,102 @@
+package domain_handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/services/domain"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// txtRecords serves the TXT records of its names.
+type txtRecords map[string][]string
+
+func (r txtRecords) LookupTXT(_ context.Context, name string) ([]string, error) {
+	return r[name], nil
+}
+
+// serve calls a handler with the given token, body and domain path parameter.
+func serve(handler echo.HandlerFunc, method, body, token, domainID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/domains/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(domainID)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestDomainHandlers(t *testing.T) {
+	// "mockToken" is user 1 and any other token is user 123
+	urlService := url_service.NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+	service := domain_service.NewDomainService(mocks.NewMockDomainRepository(), urlService)
+	service.Resolver = txtRecords{}
+	h := NewDomainHandler(service, mocks.NewMockTokenService())
+
+	t.Run("Should register and list domains", func(t *testing.T) {
+		rec := serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"go.example.com"}`, "mockToken", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"verified":false`)
+		assert.Contains(t, rec.Body.String(), `"dns_name":"_url-shortener.go.example.com"`)
+
+		rec = serve(h.ListDomainsHandler, http.MethodGet, "", "mockToken", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"hostname":"go.example.com"`)
+	})
+
+	t.Run("Should reject invalid, taken and unauthenticated registrations", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"localhost"}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusConflict, serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"go.example.com"}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusForbidden, serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"team.example.com","workspace_id":7}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"team.example.com"}`, "", "").Code)
+	})
+
+	t.Run("Should verify domains", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(h.VerifyDomainHandler, http.MethodPost, `{"method":"email"}`, "mockToken", "1").Code)
+		assert.Equal(t, http.StatusUnprocessableEntity, serve(h.VerifyDomainHandler, http.MethodPost, `{"method":"dns"}`, "mockToken", "1").Code)
+		assert.Equal(t, http.StatusForbidden, serve(h.VerifyDomainHandler, http.MethodPost, `{"method":"dns"}`, "other", "1").Code)
+
+		domain, _ := h.Service.GetDomain(1, 1)
+		h.Service.Resolver = txtRecords{"_url-shortener.go.example.com": {domain.Verification.DNSValue}}
+		rec := serve(h.VerifyDomainHandler, http.MethodPost, `{"method":"dns"}`, "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"verified":true`)
+	})
+
+	t.Run("Should get domains and their links", func(t *testing.T) {
+		link, err := h.Service.ShortenURL(1, "go.example.com", "https://www.example.com", "launch")
+		assert.NoError(t, err)
+
+		rec := serve(h.ListLinksHandler, http.MethodGet, "", "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"short_link":"https://go.example.com/launch"`)
+		assert.Contains(t, rec.Body.String(), `"shortened_url":"`+link.ShortenedURL+`"`)
+
+		assert.Equal(t, http.StatusOK, serve(h.GetDomainHandler, http.MethodGet, "", "mockToken", "1").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.GetDomainHandler, http.MethodGet, "", "mockToken", "9").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.GetDomainHandler, http.MethodGet, "", "mockToken", "x").Code)
+	})
+
+	t.Run("Should delete domains without links", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, serve(h.DeleteDomainHandler, http.MethodDelete, "", "mockToken", "1").Code)
+
+		rec := serve(h.CreateDomainHandler, http.MethodPost, `{"hostname":"spare.example.com"}`, "mockToken", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		domains, _ := h.Service.ListDomains(1)
+		id := strconv.Itoa(int(domains[1].ID))
+		assert.Equal(t, http.StatusNoContent, serve(h.DeleteDomainHandler, http.MethodDelete, "", "mockToken", id).Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.DeleteDomainHandler, http.MethodDelete, "", "mockToken", id).Code)
+	})
+}
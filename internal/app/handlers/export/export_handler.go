@@ -0,0 +1,227 @@
+package export_handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/clicks"
+	"url-shortener/internal/app/models/export"
+	"url-shortener/internal/app/models/job"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/export"
+	"url-shortener/internal/app/services/job"
+	"url-shortener/internal/app/services/token"
+)
+
+// Handler handles HTTP requests exporting the links and clicks of users.
+type Handler struct {
+	// Service is the export service instance.
+	Service      *export_service.Service
+	TokenService token_service.TokenRepository
+	// JobService runs the exports too large to wait for.
+	JobService *job_service.Service
+}
+
+// NewExportHandler creates a new instance of ExportHandler with the given export service.
+func NewExportHandler(service *export_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{
+		Service:      service,
+		TokenService: tokenService,
+		JobService:   job_service.NewJobService(),
+	}
+}
+
+// ExportURLsHandler handles HTTP requests to export the personal links of the user.
+// The format query parameter is csv, ndjson or parquet, csv when unset, and async=true
+// writes the export in the background instead of streaming it.
+func (h *Handler) ExportURLsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	format, async, ok := exportOptions(c)
+	if !ok {
+		return nil
+	}
+
+	export := func(w io.Writer) error {
+		return h.Service.ExportURLs(userID, format, w)
+	}
+	if async {
+		return h.startJob(c, userID, "urls", format, export)
+	}
+	return stream(c, "urls", format, export)
+}
+
+// ExportClicksHandler handles HTTP requests to export clicks, of the link given by the code query
+// parameter or else of all personal links of the user, within the optional from and to bounds.
+// Exports of all links must be bounded by a range short enough to wait for, unless async=true.
+func (h *Handler) ExportClicksHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	format, async, ok := exportOptions(c)
+	if !ok {
+		return nil
+	}
+
+	from, to, err := export_service.ParseRange(c.QueryParam("from"), c.QueryParam("to"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	filter := clicks_model.Filter{ShortURL: c.QueryParam("code"), From: from, To: to}
+	if filter.ShortURL == "" {
+		filter.UserID = userID
+	}
+	if err := h.Service.AuthorizeClicks(userID, filter); err != nil {
+		return errorResponse(c, err)
+	}
+
+	name := "clicks"
+	if filter.ShortURL != "" {
+		name += "-" + filter.ShortURL
+	}
+	export := func(w io.Writer) error {
+		return h.Service.ExportClicks(filter, format, w)
+	}
+	if async {
+		return h.startJob(c, userID, name, format, export)
+	}
+	if err := h.Service.CheckSyncRange(filter); err != nil {
+		return errorResponse(c, err)
+	}
+	return stream(c, name, format, export)
+}
+
+// GetExportJobHandler handles HTTP requests to get the status of an export job of the user.
+// Completed jobs hold the download URL of the export.
+func (h *Handler) GetExportJobHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	job, err := h.JobService.Get(userID, c.Param("job"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	if file, ok := job.Result.(*export_model.File); ok {
+		// The file is shared with the job, so the download URL goes on a copy
+		result := *file
+		result.DownloadURL = "/export/jobs/" + job.ID + "/download/"
+		job.Result = &result
+	}
+	return c.JSON(http.StatusOK, job)
+}
+
+// DownloadExportHandler handles HTTP requests to download the file written by an export job of the user.
+func (h *Handler) DownloadExportHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	job, err := h.JobService.Get(userID, c.Param("job"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	file, ok := job.Result.(*export_model.File)
+	if job.Status != job_model.StatusCompleted || !ok {
+		return errorResponse(c, export_model.ErrExportNotReady)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, export_model.ContentTypes[file.Format])
+	return c.Attachment(file.Path, file.Filename)
+}
+
+// startJob runs the export into a file in the background and answers with the status URL of the job.
+func (h *Handler) startJob(c echo.Context, userID uint, name, format string, export func(w io.Writer) error) error {
+	// Files are kept as long as the jobs pointing to them
+	h.Service.PruneFiles(h.JobService.Retention)
+
+	job, err := h.JobService.Start(userID, "export", 0, func(func(int)) (interface{}, error) {
+		return h.Service.ExportToFile(name, format, export)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	statusURL := "/export/jobs/" + job.ID + "/"
+	c.Response().Header().Set(echo.HeaderLocation, statusURL)
+	return c.JSON(http.StatusAccepted, map[string]string{"job_id": job.ID, "status": job.Status, "status_url": statusURL})
+}
+
+// stream writes the export as the body of an attachment. Once the body has started an error
+// can no longer change the status, so it only cuts the download short.
+func stream(c echo.Context, name, format string, export func(w io.Writer) error) error {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, export_model.ContentTypes[format])
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", name+"."+format))
+	c.Response().WriteHeader(http.StatusOK)
+	return export(c.Response())
+}
+
+// exportOptions returns the format query parameter, csv when unset, and the async one.
+// When they are invalid the error response has already been written.
+func exportOptions(c echo.Context) (string, bool, bool) {
+	format := strings.ToLower(c.QueryParam("format"))
+	if format == "" {
+		format = export_model.FormatCSV
+	}
+	if _, ok := export_model.ContentTypes[format]; !ok {
+		_ = c.JSON(http.StatusBadRequest, map[string]string{"error": export_model.ErrInvalidFormat.Error()})
+		return "", false, false
+	}
+
+	var async bool
+	if value := c.QueryParam("async"); value != "" {
+		var err error
+		if async, err = strconv.ParseBool(value); err != nil {
+			_ = c.JSON(http.StatusBadRequest, map[string]string{"error": "async must be true or false"})
+			return "", false, false
+		}
+	}
+	return format, async, true
+}
+
+// authenticate validates the bearer token and returns the user ID.
+// When it fails the error response has already been written.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, export_model.ErrInvalidRange), errors.Is(err, export_model.ErrRangeTooLarge):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrURLNotFound), errors.Is(err, job_model.ErrJobNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, export_model.ErrExportNotReady):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,155 @@
+package export_handler
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/clicks"
+	"url-shortener/internal/app/models/export"
+	"url-shortener/internal/app/models/job"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/export"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+)
+
+func request(t *testing.T, handler echo.HandlerFunc, authorization, target, job string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if job != "" {
+		c.SetParamNames("job")
+		c.SetParamValues(job)
+	}
+	assert.NoError(t, handler(c))
+	return rec
+}
+
+func TestExportURLsHandler(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	// The mock token service resolves "mockToken" to user ID 1
+	now := time.Now().UTC()
+	urlRepository.Urls[1] = &url_model.URL{ShortenedURL: "mine", OriginalURL: "https://www.example.com", UserID: 1, CreatedAt: now}
+	urlRepository.Urls[2] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://www.example.org", UserID: 2, CreatedAt: now}
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := export_service.NewExportService(urlRepository, mocks.MockClicksRepository{Urls: urlRepository.Urls}, urlService, t.TempDir())
+	handler := NewExportHandler(service, mocks.NewMockTokenService())
+
+	t.Run("Should stream personal links", func(t *testing.T) {
+		rec := request(t, handler.ExportURLsHandler, "Bearer mockToken", "/export/urls/?format=ndjson", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, export_model.ContentTypes[export_model.FormatNDJSON], rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="urls.ndjson"`, rec.Header().Get(echo.HeaderContentDisposition))
+		assert.Equal(t, 1, strings.Count(rec.Body.String(), "\n"))
+		assert.Contains(t, rec.Body.String(), `"short_code":"mine"`)
+	})
+
+	t.Run("Should default to CSV", func(t *testing.T) {
+		rec := request(t, handler.ExportURLsHandler, "Bearer mockToken", "/export/urls/", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "short_code,original_url,"))
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request(t, handler.ExportURLsHandler, "", "/export/urls/", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, request(t, handler.ExportURLsHandler, "Bearer invalid", "/export/urls/", "").Code)
+		assert.Equal(t, http.StatusBadRequest, request(t, handler.ExportURLsHandler, "Bearer mockToken", "/export/urls/?format=xlsx", "").Code)
+		assert.Equal(t, http.StatusBadRequest, request(t, handler.ExportURLsHandler, "Bearer mockToken", "/export/urls/?async=maybe", "").Code)
+	})
+}
+
+func TestExportClicksHandler(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	// The mock token service resolves "mockToken" to user ID 1
+	now := time.Now().UTC()
+	urlRepository.Urls[1] = &url_model.URL{ShortenedURL: "mine", OriginalURL: "https://www.example.com", UserID: 1, CreatedAt: now}
+	urlRepository.Urls[2] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://www.example.org", UserID: 2, CreatedAt: now}
+	clicksRepository := mocks.MockClicksRepository{Urls: urlRepository.Urls, Clicks: []clicks_model.Clicks{
+		{ID: 1, UrlID: "mine", IPAddress: "127.0.0.1", CreatedAt: now.AddDate(0, 0, -60)},
+		{ID: 2, UrlID: "mine", IPAddress: "127.0.0.2", CreatedAt: now},
+		{ID: 3, UrlID: "other", IPAddress: "127.0.0.3", CreatedAt: now},
+	}}
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := export_service.NewExportService(urlRepository, clicksRepository, urlService, t.TempDir())
+	handler := NewExportHandler(service, mocks.NewMockTokenService())
+	today := time.Now().UTC().Format(time.DateOnly)
+	lastWeek := time.Now().UTC().AddDate(0, 0, -7).Format(time.DateOnly)
+
+	t.Run("Should stream clicks of link", func(t *testing.T) {
+		rec := request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?code=mine", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `attachment; filename="clicks-mine.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+		assert.Equal(t, 3, strings.Count(rec.Body.String(), "\n"))
+	})
+
+	t.Run("Should stream clicks of user within range", func(t *testing.T) {
+		rec := request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?from="+lastWeek+"&to="+today, "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "id,short_code,ip_address,created_at,variant\n2,mine,127.0.0.2,"))
+		assert.Equal(t, 2, strings.Count(rec.Body.String(), "\n"))
+	})
+
+	t.Run("Should export large ranges as a job", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/", "").Code)
+
+		rec := request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?format=parquet&async=true", "")
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		var accepted map[string]string
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &accepted))
+		assert.Equal(t, "/export/jobs/"+accepted["job_id"]+"/", rec.Header().Get(echo.HeaderLocation))
+
+		var job struct {
+			job_model.Job
+			Result export_model.File `json:"result"`
+		}
+		assert.Eventually(t, func() bool {
+			rec := request(t, handler.GetExportJobHandler, "Bearer mockToken", "/", accepted["job_id"])
+			_ = json.Unmarshal(rec.Body.Bytes(), &job)
+			return job.Status == job_model.StatusCompleted
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, "clicks.parquet", job.Result.Filename)
+		assert.Equal(t, "/export/jobs/"+accepted["job_id"]+"/download/", job.Result.DownloadURL)
+
+		rec = request(t, handler.DownloadExportHandler, "Bearer mockToken", job.Result.DownloadURL, accepted["job_id"])
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, export_model.ContentTypes[export_model.FormatParquet], rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "clicks.parquet")
+		assert.Equal(t, job.Result.Size, int64(rec.Body.Len()))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "PAR1"))
+
+		assert.Equal(t, http.StatusNotFound, request(t, handler.GetExportJobHandler, "Bearer other", "/", accepted["job_id"]).Code)
+		assert.Equal(t, http.StatusNotFound, request(t, handler.DownloadExportHandler, "Bearer other", "/", accepted["job_id"]).Code)
+	})
+
+	t.Run("Should not download unfinished export", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		job, _ := handler.JobService.Start(1, "export", 0, func(func(int)) (interface{}, error) {
+			<-release
+			return nil, nil
+		})
+
+		assert.Equal(t, http.StatusConflict, request(t, handler.DownloadExportHandler, "Bearer mockToken", "/", job.ID).Code)
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, request(t, handler.ExportClicksHandler, "", "/export/clicks/?code=mine", "").Code)
+		assert.Equal(t, http.StatusForbidden, request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?code=other", "").Code)
+		assert.Equal(t, http.StatusNotFound, request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?code=missing", "").Code)
+		assert.Equal(t, http.StatusBadRequest, request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?code=mine&from=soon", "").Code)
+		assert.Equal(t, http.StatusBadRequest, request(t, handler.ExportClicksHandler, "Bearer mockToken", "/export/clicks/?code=mine&from="+today+"&to="+lastWeek, "").Code)
+	})
+}
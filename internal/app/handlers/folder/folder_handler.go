@@ -0,0 +1,167 @@
+package folder_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/folder"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/folder"
+	"url-shortener/internal/app/services/token"
+	"url-shortener/internal/app/services/webhook"
+)
+
+// Handler handles HTTP requests related to folders.
+type Handler struct {
+	// Service is the folder service instance.
+	Service      *folder_service.Service
+	TokenService token_service.TokenRepository
+	// Webhooks notifies the webhooks of link owners of moved links, nil when disabled.
+	Webhooks *webhook_service.Service
+}
+
+// NewFolderHandler creates a new instance of FolderHandler with the given folder service.
+func NewFolderHandler(service *folder_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, TokenService: tokenService}
+}
+
+// ListFoldersHandler handles HTTP requests to list the folders of the caller.
+func (h *Handler) ListFoldersHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	folders, err := h.Service.ListFolders(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, folders)
+}
+
+// CreateFolderHandler handles HTTP requests to create a folder.
+func (h *Handler) CreateFolderHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req folder_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	folder, err := h.Service.CreateFolder(userID, req.Name)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, folder)
+}
+
+// RenameFolderHandler handles HTTP requests to rename a folder.
+func (h *Handler) RenameFolderHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	folderID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid folder ID"})
+	}
+
+	var req folder_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	folder, err := h.Service.RenameFolder(userID, folderID, req.Name)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, folder)
+}
+
+// DeleteFolderHandler handles HTTP requests to delete a folder, keeping its links.
+func (h *Handler) DeleteFolderHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	folderID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid folder ID"})
+	}
+
+	if err := h.Service.DeleteFolder(userID, folderID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MoveURLHandler handles HTTP requests to move a link into a folder, or out of its folder.
+func (h *Handler) MoveURLHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req folder_model.MoveRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.Service.MoveURL(userID, c.Param("code"), req.FolderID); err != nil {
+		return errorResponse(c, err)
+	}
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// authenticate validates the bearer token and returns the user ID.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func paramID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	return uint(id), err
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, folder_model.ErrInvalidName):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, folder_model.ErrFolderNotFound), errors.Is(err, url_model.ErrURLNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, folder_model.ErrFolderAlreadyExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
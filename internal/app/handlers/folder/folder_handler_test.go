@@ -0,0 +1,113 @@
+package folder_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/folder"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler with the given token, body and folder and short code path parameters.
+func serve(handler echo.HandlerFunc, method, body, token, folderID, code string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/folders/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id", "code")
+	c.SetParamValues(folderID, code)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestFolderHandlers(t *testing.T) {
+	// "mockToken" is user 1, who owns the link "first", and any other token is user 123
+	urlRepository := mocks.NewMockUrlRepository()
+	folderRepository := mocks.NewMockFolderRepository()
+	folderRepository.Urls = urlRepository.Urls
+	user := uint(1)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &user)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := folder_service.NewFolderService(folderRepository, urlService)
+	h := NewFolderHandler(service, mocks.NewMockTokenService())
+
+	t.Run("Should create and list folders", func(t *testing.T) {
+		rec := serve(h.CreateFolderHandler, http.MethodPost, `{"name":"Campaigns"}`, "mockToken", "", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Campaigns"`)
+
+		rec = serve(h.ListFoldersHandler, http.MethodGet, "", "mockToken", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"url_count":0`)
+	})
+
+	t.Run("Should reject duplicate and invalid names", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, serve(h.CreateFolderHandler, http.MethodPost, `{"name":"Campaigns"}`, "mockToken", "", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.CreateFolderHandler, http.MethodPost, `{"name":" "}`, "mockToken", "", "").Code)
+	})
+
+	t.Run("Should rename and delete folders", func(t *testing.T) {
+		rec := serve(h.RenameFolderHandler, http.MethodPut, `{"name":"Launch"}`, "mockToken", "1", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Launch"`)
+
+		assert.Equal(t, http.StatusNotFound, serve(h.RenameFolderHandler, http.MethodPut, `{"name":"Mine"}`, "other", "1", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.DeleteFolderHandler, http.MethodDelete, "", "mockToken", "abc", "").Code)
+		assert.Equal(t, http.StatusNoContent, serve(h.DeleteFolderHandler, http.MethodDelete, "", "mockToken", "1", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.DeleteFolderHandler, http.MethodDelete, "", "mockToken", "1", "").Code)
+	})
+
+	t.Run("Should require token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(h.ListFoldersHandler, http.MethodGet, "", "", "", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(h.MoveURLHandler, http.MethodPut, `{}`, "invalid", "", "first").Code)
+	})
+}
+
+func TestMoveURLHandler(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	folderRepository := mocks.NewMockFolderRepository()
+	folderRepository.Urls = urlRepository.Urls
+	user := uint(1)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &user)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := folder_service.NewFolderService(folderRepository, urlService)
+	h := NewFolderHandler(service, mocks.NewMockTokenService())
+	webhookRepository := mocks.NewMockWebhookRepository()
+	h.Webhooks = webhook_service.NewWebhookService(webhookRepository, urlService)
+	_, _ = webhookRepository.Create(&webhook_model.Webhook{URL: "https://hooks.example.com", UserID: 1, Events: []string{webhook_model.EventLinkUpdated}, Active: true})
+	serve(h.CreateFolderHandler, http.MethodPost, `{"name":"Campaigns"}`, "mockToken", "", "")
+
+	t.Run("Should move a link into a folder and out of it", func(t *testing.T) {
+		rec := serve(h.MoveURLHandler, http.MethodPut, `{"folder_id":1}`, "mockToken", "", "first")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		u, _ := urlRepository.GetURL("first")
+		assert.Equal(t, uint(1), *u.FolderID)
+
+		rec = serve(h.MoveURLHandler, http.MethodPut, `{"folder_id":null}`, "mockToken", "", "first")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		u, _ = urlRepository.GetURL("first")
+		assert.Nil(t, u.FolderID)
+
+		deliveries, _ := webhookRepository.ListDeliveries(1, "", 10)
+		assert.Len(t, deliveries, 2)
+	})
+
+	t.Run("Should reject links and folders of other users", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(h.MoveURLHandler, http.MethodPut, `{"folder_id":1}`, "other", "", "first").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.MoveURLHandler, http.MethodPut, `{"folder_id":9}`, "mockToken", "", "first").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.MoveURLHandler, http.MethodPut, `{"folder_id":1}`, "mockToken", "", "missing").Code)
+	})
+}
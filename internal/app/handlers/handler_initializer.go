@@ -2,34 +2,87 @@ package handlers
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
+	admin_handler "url-shortener/internal/app/handlers/admin"
 	"url-shortener/internal/app/handlers/auth"
 	clicks_handler "url-shortener/internal/app/handlers/clicks"
+	domain_handler "url-shortener/internal/app/handlers/domain"
+	export_handler "url-shortener/internal/app/handlers/export"
+	folder_handler "url-shortener/internal/app/handlers/folder"
+	oidc_handler "url-shortener/internal/app/handlers/oidc"
+	qr_handler "url-shortener/internal/app/handlers/qr"
+	tag_handler "url-shortener/internal/app/handlers/tag"
 	url_handler "url-shortener/internal/app/handlers/url"
+	webhook_handler "url-shortener/internal/app/handlers/webhook"
+	workspace_handler "url-shortener/internal/app/handlers/workspace"
+	ratelimit_middleware "url-shortener/internal/app/middleware/ratelimit"
+	audit_repository "url-shortener/internal/app/repositories/audit"
 	"url-shortener/internal/app/repositories/auth"
 	clicks_repository "url-shortener/internal/app/repositories/clicks"
+	domain_repository "url-shortener/internal/app/repositories/domain"
+	folder_repository "url-shortener/internal/app/repositories/folder"
+	tag_repository "url-shortener/internal/app/repositories/tag"
 	url_repository "url-shortener/internal/app/repositories/url"
+	webhook_repository "url-shortener/internal/app/repositories/webhook"
+	workspace_repository "url-shortener/internal/app/repositories/workspace"
+	admin_service "url-shortener/internal/app/services/admin"
 	"url-shortener/internal/app/services/auth"
 	clicks_service "url-shortener/internal/app/services/clicks"
+	domain_service "url-shortener/internal/app/services/domain"
+	email_service "url-shortener/internal/app/services/email"
+	export_service "url-shortener/internal/app/services/export"
+	folder_service "url-shortener/internal/app/services/folder"
+	lockout_service "url-shortener/internal/app/services/lockout"
+	oidc_service "url-shortener/internal/app/services/oidc"
+	tag_service "url-shortener/internal/app/services/tag"
 	"url-shortener/internal/app/services/token"
 	url_service "url-shortener/internal/app/services/url"
+	workspace_service "url-shortener/internal/app/services/workspace"
+	"url-shortener/internal/config"
 )
 
 // InitializeUserHandlers initializes all the auth handlers.
 func InitializeUserHandlers(db *sql.DB) *auth_handler.Handler {
 	userRepository := auth_repository.NewDBAuthRepository(db)
-	userService := auth_service.NewAuthService(userRepository)
-	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
-	userHandler := auth_handler.NewAuthHandler(userService, tokenService)
+	passwordPolicy, err := config.NewPasswordPolicy()
+	if err != nil {
+		fmt.Println("[HANDLERS] Error loading password policy:", err)
+	}
+	mailer := config.NewMailer()
+	userService := auth_service.NewAuthService(userRepository, passwordPolicy, mailer)
+	tokenService := newTokenService(db)
+	auditRepository := audit_repository.NewDBAuditRepository(db)
+	lockoutService := lockout_service.NewLockoutService(config.NewLockoutConfig(), auditRepository)
+	emailService := email_service.NewEmailService(userRepository, mailer, os.Getenv("JWT_SECRET_KEY"), os.Getenv("APP_BASE_URL"))
+	userHandler := auth_handler.NewAuthHandler(userService, tokenService, lockoutService, emailService)
 	return userHandler
 }
 
-// InitializeURLHandlers initializes all the URL handlers.
-func InitializeURLHandlers(db *sql.DB) *url_handler.Handler {
+// InitializeSafetyPolicy loads the destination URL policy shared by the handlers. Verified custom domains
+// serve short links too, so links to them are rejected as redirect loops.
+func InitializeSafetyPolicy(db *sql.DB) (url_service.SafetyPolicy, error) {
+	safetyPolicy, err := config.NewSafetyPolicy()
+	if err != nil {
+		return safetyPolicy, err
+	}
+	safetyPolicy.ServesHost = domain_service.NewDomainService(domain_repository.NewDBDomainRepository(db), nil).ServesHost
+	return safetyPolicy, nil
+}
+
+// InitializeURLHandlers initializes all the URL handlers, checking destination URLs with the safety policy.
+func InitializeURLHandlers(db *sql.DB, safetyPolicy url_service.SafetyPolicy) *url_handler.Handler {
 	urlRepository := url_repository.NewDBURLRepository(db)
-	urlService := url_service.NewURLService(urlRepository)
-	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
-	urlHandler := url_handler.NewURLHandler(urlService, tokenService)
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	urlService.Safety = safetyPolicy
+	tokenService := newTokenService(db)
+	userRepository := auth_repository.NewDBAuthRepository(db)
+	emailService := email_service.NewEmailService(userRepository, config.NewMailer(), os.Getenv("JWT_SECRET_KEY"), os.Getenv("APP_BASE_URL"))
+	urlHandler := url_handler.NewURLHandler(urlService, tokenService, emailService)
+	urlHandler.BulkLimits = config.NewBulkLimits()
+	urlHandler.Metadata = config.NewMetadataService(urlRepository)
+	urlHandler.Domains = domain_service.NewDomainService(domain_repository.NewDBDomainRepository(db), urlService)
 	return urlHandler
 }
 
@@ -38,10 +91,136 @@ func InitializeClickHandlers(db *sql.DB) *clicks_handler.Handler {
 	clickRepository := clicks_repository.NewDBClicksRepository(db)
 
 	urlRepository := url_repository.NewDBURLRepository(db)
-	urlService := url_service.NewURLService(urlRepository)
-	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	// Access tokens of protected links must stay valid across instances and restarts
+	if secret := os.Getenv("JWT_SECRET_KEY"); secret != "" {
+		urlService.AccessKey = []byte("link-access:" + secret)
+	}
+	tokenService := newTokenService(db)
 
 	clickService := clicks_service.NewClicksService(clickRepository)
 	clickHandler := clicks_handler.NewClickHandler(clickService, urlService, tokenService)
+	clickHandler.CountryHeader = config.NewCountryHeader()
+	clickHandler.Domains = domain_service.NewDomainService(domain_repository.NewDBDomainRepository(db), urlService)
+	var err error
+	if clickHandler.AppleAppSiteAssociation, err = config.NewAppleAppSiteAssociation(); err != nil {
+		fmt.Println("[HANDLERS] Error loading apple-app-site-association:", err)
+	}
+	if clickHandler.AssetLinks, err = config.NewAssetLinks(); err != nil {
+		fmt.Println("[HANDLERS] Error loading assetlinks.json:", err)
+	}
 	return clickHandler
 }
+
+// InitializeOIDCHandlers initializes the single sign-on handlers.
+func InitializeOIDCHandlers(db *sql.DB) *oidc_handler.Handler {
+	userRepository := auth_repository.NewDBAuthRepository(db)
+	oidcService := oidc_service.NewOIDCService(config.NewOIDCProviders(), userRepository)
+	tokenService := newTokenService(db)
+	oidcHandler := oidc_handler.NewOIDCHandler(oidcService, tokenService)
+	return oidcHandler
+}
+
+// InitializeAdminHandlers initializes the admin handlers.
+func InitializeAdminHandlers(db *sql.DB) *admin_handler.Handler {
+	userRepository := auth_repository.NewDBAuthRepository(db)
+	urlRepository := url_repository.NewDBURLRepository(db)
+	auditRepository := audit_repository.NewDBAuditRepository(db)
+	adminService := admin_service.NewAdminService(userRepository, urlRepository, auditRepository)
+	tokenService := newTokenService(db)
+	adminHandler := admin_handler.NewAdminHandler(adminService, tokenService, userRepository)
+	return adminHandler
+}
+
+// InitializeWorkspaceHandlers initializes the workspace handlers.
+func InitializeWorkspaceHandlers(db *sql.DB) *workspace_handler.Handler {
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	userRepository := auth_repository.NewDBAuthRepository(db)
+	workspaceService := workspace_service.NewWorkspaceService(workspaceRepository, userRepository)
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspaceRepository)
+	tokenService := newTokenService(db)
+	workspaceHandler := workspace_handler.NewWorkspaceHandler(workspaceService, urlService, tokenService)
+	return workspaceHandler
+}
+
+// InitializeQRHandlers initializes the QR code handlers.
+func InitializeQRHandlers(db *sql.DB) *qr_handler.Handler {
+	urlRepository := url_repository.NewDBURLRepository(db)
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	qrService, err := config.NewQRService()
+	if err != nil {
+		fmt.Println("[HANDLERS] Error loading QR logo:", err)
+	}
+	qrHandler := qr_handler.NewQRHandler(qrService, urlService, os.Getenv("APP_BASE_URL"))
+	qrHandler.Domains = domain_service.NewDomainService(domain_repository.NewDBDomainRepository(db), urlService)
+	return qrHandler
+}
+
+// InitializeExportHandlers initializes the handlers exporting links and clicks.
+func InitializeExportHandlers(db *sql.DB) *export_handler.Handler {
+	urlRepository := url_repository.NewDBURLRepository(db)
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	clickRepository := clicks_repository.NewDBClicksRepository(db)
+	exportService := export_service.NewExportService(urlRepository, clickRepository, urlService, config.NewExportDir())
+	exportService.MaxSyncRange = config.NewExportMaxSyncRange()
+	tokenService := newTokenService(db)
+	return export_handler.NewExportHandler(exportService, tokenService)
+}
+
+// InitializeTagHandlers initializes the tag handlers.
+func InitializeTagHandlers(db *sql.DB) *tag_handler.Handler {
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspaceRepository)
+	tagService := tag_service.NewTagService(tag_repository.NewDBTagRepository(db), urlService)
+	tokenService := newTokenService(db)
+	return tag_handler.NewTagHandler(tagService, tokenService)
+}
+
+// InitializeFolderHandlers initializes the folder handlers.
+func InitializeFolderHandlers(db *sql.DB) *folder_handler.Handler {
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspaceRepository)
+	folderService := folder_service.NewFolderService(folder_repository.NewDBFolderRepository(db), urlService)
+	tokenService := newTokenService(db)
+	return folder_handler.NewFolderHandler(folderService, tokenService)
+}
+
+// InitializeDomainHandlers initializes the custom domain handlers.
+func InitializeDomainHandlers(db *sql.DB, safetyPolicy url_service.SafetyPolicy) *domain_handler.Handler {
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspaceRepository)
+	// The policy rejects the hosts of the shortener and domains pointing at private addresses
+	urlService.Safety = safetyPolicy
+	domainService := domain_service.NewDomainService(domain_repository.NewDBDomainRepository(db), urlService)
+	tokenService := newTokenService(db)
+	return domain_handler.NewDomainHandler(domainService, tokenService)
+}
+
+// InitializeWebhookHandlers initializes the webhook handlers and starts the delivery worker. The URL, click,
+// admin, tag and folder handlers publish their events through the service of the returned handler.
+func InitializeWebhookHandlers(db *sql.DB, safetyPolicy url_service.SafetyPolicy) *webhook_handler.Handler {
+	workspaceRepository := workspace_repository.NewDBWorkspaceRepository(db)
+	urlService := url_service.NewURLService(url_repository.NewDBURLRepository(db), workspaceRepository)
+	// The policy rejects webhooks on the hosts of the shortener and on private addresses
+	urlService.Safety = safetyPolicy
+	webhookService := config.NewWebhookService(webhook_repository.NewDBWebhookRepository(db), urlService)
+	tokenService := newTokenService(db)
+	return webhook_handler.NewWebhookHandler(webhookService, tokenService)
+}
+
+// InitializeRateLimiter initializes the rate limiter of the shortening and redirect routes.
+func InitializeRateLimiter() *ratelimit_middleware.Limiter {
+	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
+	keyFunc := ratelimit_middleware.NewKeyFunc(tokenService)
+	return ratelimit_middleware.NewLimiter(ratelimit_middleware.NewMemoryStore(), keyFunc, config.NewRateLimitPolicies())
+}
+
+// newTokenService creates the token service of the handlers, which rejects the tokens of disabled accounts.
+func newTokenService(db *sql.DB) *token_service.Service {
+	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
+	tokenService.Users = auth_repository.NewDBAuthRepository(db)
+	return tokenService
+}
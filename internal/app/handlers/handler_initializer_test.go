@@ -3,6 +3,7 @@ package handlers
 import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"testing"
+	"url-shortener/internal/app/services/url"
 )
 
 func TestInitializeUserHandlers(t *testing.T) {
@@ -27,6 +28,28 @@ func TestInitializeUserHandlers(t *testing.T) {
 	mock.ExpectClose()
 }
 
+func TestInitializeSafetyPolicy(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	safetyPolicy, err := InitializeSafetyPolicy(db)
+
+	if err != nil {
+		t.Errorf("Error initializing the URL safety policy: %s", err)
+	}
+
+	if safetyPolicy.ServesHost == nil {
+		t.Errorf("Safety policy does not check custom domains")
+	}
+
+	mock.ExpectClose()
+}
+
 func TestInitializeURLHandlers(t *testing.T) {
 	db, mock, err := sqlmock.New()
 
@@ -36,7 +59,7 @@ func TestInitializeURLHandlers(t *testing.T) {
 
 	defer db.Close()
 
-	urlHandler := InitializeURLHandlers(db)
+	urlHandler := InitializeURLHandlers(db, url_service.DefaultSafetyPolicy())
 
 	if err != nil {
 		t.Errorf("Error initializing URL handlers: %s", err)
@@ -70,3 +93,173 @@ func TestInitializeClickHandlers(t *testing.T) {
 
 	mock.ExpectClose()
 }
+
+func TestInitializeOIDCHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	oidcHandler := InitializeOIDCHandlers(db)
+
+	if oidcHandler == nil {
+		t.Errorf("OIDC handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeAdminHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	adminHandler := InitializeAdminHandlers(db)
+
+	if adminHandler == nil {
+		t.Errorf("Admin handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeWorkspaceHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	workspaceHandler := InitializeWorkspaceHandlers(db)
+
+	if workspaceHandler == nil {
+		t.Errorf("Workspace handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeQRHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	qrHandler := InitializeQRHandlers(db)
+
+	if qrHandler == nil {
+		t.Errorf("QR handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeRateLimiter(t *testing.T) {
+	limiter := InitializeRateLimiter()
+
+	if limiter == nil {
+		t.Errorf("Rate limiter is nil")
+	}
+}
+
+func TestInitializeExportHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	exportHandler := InitializeExportHandlers(db)
+
+	if exportHandler == nil {
+		t.Errorf("Export handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeTagHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	tagHandler := InitializeTagHandlers(db)
+
+	if tagHandler == nil {
+		t.Errorf("Tag handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeFolderHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	folderHandler := InitializeFolderHandlers(db)
+
+	if folderHandler == nil {
+		t.Errorf("Folder handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeDomainHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	domainHandler := InitializeDomainHandlers(db, url_service.DefaultSafetyPolicy())
+
+	if domainHandler == nil {
+		t.Errorf("Domain handler is nil")
+	}
+
+	mock.ExpectClose()
+}
+
+func TestInitializeWebhookHandlers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	defer db.Close()
+
+	webhookHandler := InitializeWebhookHandlers(db, url_service.DefaultSafetyPolicy())
+
+	if webhookHandler == nil {
+		t.Errorf("Webhook handler is nil")
+	}
+
+	mock.ExpectClose()
+}
@@ -0,0 +1,107 @@
+package oidc_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"url-shortener/internal/app/models/oidc"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/services/oidc"
+	"url-shortener/internal/app/services/token"
+)
+
+// bindingCookie is the cookie tying a started login to the browser that started it.
+const bindingCookie = "oidc_binding"
+
+// Handler handles HTTP requests for single sign-on through OpenID Connect providers.
+type Handler struct {
+	// Service is the OIDC service instance.
+	Service         *oidc_service.Service
+	TokenRepository token_service.TokenRepository
+}
+
+// NewOIDCHandler creates a new instance of OIDCHandler with the given OIDC service.
+func NewOIDCHandler(service *oidc_service.Service, tokenRepository token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, TokenRepository: tokenRepository}
+}
+
+// ProvidersHandler handles HTTP requests to list the configured identity providers.
+func (h *Handler) ProvidersHandler(c echo.Context) error {
+	return c.JSON(http.StatusOK, map[string][]string{"providers": h.Service.ProviderNames()})
+}
+
+// LoginHandler handles HTTP requests to start a login by redirecting to the identity provider.
+func (h *Handler) LoginHandler(c echo.Context) error {
+	authURL, binding, err := h.Service.AuthCodeURL(c.Param("provider"))
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	// Lax cookies are sent on the top-level redirect back from the provider
+	c.SetCookie(&http.Cookie{
+		Name:     bindingCookie,
+		Value:    binding,
+		Path:     callbackPath(c),
+		MaxAge:   int(h.Service.StateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   c.IsTLS(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// CallbackHandler handles the redirect back from the identity provider and issues a token.
+func (h *Handler) CallbackHandler(c echo.Context) error {
+	// The provider reports a denied or failed login through the error parameter
+	if providerError := c.QueryParam("error"); providerError != "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": providerError})
+	}
+
+	code, state := c.QueryParam("code"), c.QueryParam("state")
+	if code == "" || state == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Code and state are required"})
+	}
+
+	// The binding is single use like the state it signs
+	var binding string
+	if cookie, err := c.Cookie(bindingCookie); err == nil {
+		binding = cookie.Value
+	}
+	c.SetCookie(&http.Cookie{Name: bindingCookie, Path: callbackPath(c), MaxAge: -1, HttpOnly: true, Secure: c.IsTLS(), SameSite: http.SameSiteLaxMode})
+
+	// Call the OIDC service to verify the login and resolve the linked auth
+	user, err := h.Service.Login(c.Param("provider"), code, state, binding)
+	if err != nil {
+		return h.errorResponse(c, err)
+	}
+
+	// Generate a token for the authenticated auth
+	token, err := h.TokenRepository.GenerateToken(user)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"token": token})
+}
+
+// callbackPath returns the path of the callback of the requested provider, limiting the binding cookie to it.
+func callbackPath(c echo.Context) string {
+	return "/auth/oidc/" + c.Param("provider") + "/callback/"
+}
+
+func (h *Handler) errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, oidc_model.ErrUnknownProvider):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, oidc_model.ErrInvalidState):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": oidc_model.ErrInvalidState.Error()})
+	case errors.Is(err, oidc_model.ErrInvalidIDToken):
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": oidc_model.ErrInvalidIDToken.Error()})
+	case errors.Is(err, user_model.ErrAccountDisabled):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, oidc_model.ErrProviderUnavailable):
+		c.Logger().Error("[OIDC] Identity provider error: ", err)
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": oidc_model.ErrProviderUnavailable.Error()})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+}
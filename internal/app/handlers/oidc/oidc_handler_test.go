@@ -0,0 +1,165 @@
+package oidc_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"url-shortener/internal/app/services/oidc"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler with the provider path parameter set, sending the given cookies.
+func serve(handler echo.HandlerFunc, target, provider string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("provider")
+	c.SetParamValues(provider)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestProvidersHandler(t *testing.T) {
+	service := oidc_service.NewOIDCService([]oidc_service.Provider{{Name: "example"}, {Name: "offline"}}, mocks.NewMockUserRepository())
+	handler := NewOIDCHandler(service, mocks.NewMockTokenService())
+
+	rec := serve(handler.ProvidersHandler, "/auth/oidc/", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"providers":["example","offline"]}`, rec.Body.String())
+}
+
+func TestLoginHandler(t *testing.T) {
+	provider := mocks.NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	service := oidc_service.NewOIDCService([]oidc_service.Provider{
+		{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost:8080/auth/oidc/example/callback/"},
+		{Name: "offline", Issuer: "http://127.0.0.1:1", ClientID: "client"},
+	}, mocks.NewMockUserRepository())
+	handler := NewOIDCHandler(service, mocks.NewMockTokenService())
+
+	t.Run("Should redirect to provider", func(t *testing.T) {
+		rec := serve(handler.LoginHandler, "/auth/oidc/example/login/", "example")
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		assert.Contains(t, rec.Header().Get("Location"), provider.Issuer()+"/authorize?")
+
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, "oidc_binding", cookies[0].Name)
+		assert.Equal(t, "/auth/oidc/example/callback/", cookies[0].Path)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	})
+
+	t.Run("Should return not found for unknown provider", func(t *testing.T) {
+		rec := serve(handler.LoginHandler, "/auth/oidc/missing/login/", "missing")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should return bad gateway when provider is unavailable", func(t *testing.T) {
+		rec := serve(handler.LoginHandler, "/auth/oidc/offline/login/", "offline")
+
+		assert.Equal(t, http.StatusBadGateway, rec.Code)
+	})
+}
+
+func TestCallbackHandler(t *testing.T) {
+	provider := mocks.NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	repository := mocks.NewMockUserRepository()
+	service := oidc_service.NewOIDCService([]oidc_service.Provider{
+		{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost:8080/auth/oidc/example/callback/"},
+		{Name: "offline", Issuer: "http://127.0.0.1:1", ClientID: "client"},
+	}, repository)
+	handler := NewOIDCHandler(service, mocks.NewMockTokenService())
+
+	// authorize starts a login and follows it through the fake provider, returning the callback query and the browser's binding cookie
+	authorize := func() (url.Values, *http.Cookie) {
+		rec := serve(handler.LoginHandler, "/auth/oidc/example/login/", "example")
+		code, state, err := provider.Authorize(rec.Header().Get("Location"))
+		assert.NoError(t, err)
+		return url.Values{"code": {code}, "state": {state}}, rec.Result().Cookies()[0]
+	}
+
+	t.Run("Should issue token", func(t *testing.T) {
+		query, cookie := authorize()
+
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", cookie)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "token")
+		assert.Equal(t, -1, rec.Result().Cookies()[0].MaxAge)
+	})
+
+	t.Run("Should reject callback from another browser", func(t *testing.T) {
+		query, _ := authorize()
+		_, attacker := authorize()
+
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", attacker)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should reject replayed callback", func(t *testing.T) {
+		query, cookie := authorize()
+		serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", cookie)
+
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", cookie)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should reject forged ID token", func(t *testing.T) {
+		provider.Claims["aud"] = "other"
+		defer delete(provider.Claims, "aud")
+		query, cookie := authorize()
+
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", cookie)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should return forbidden for disabled user", func(t *testing.T) {
+		for _, user := range repository.Users {
+			user.Disabled = true
+		}
+		query, cookie := authorize()
+
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?"+query.Encode(), "example", cookie)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should return provider error", func(t *testing.T) {
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?error=access_denied", "example")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "access_denied")
+	})
+
+	t.Run("Should require code and state", func(t *testing.T) {
+		rec := serve(handler.CallbackHandler, "/auth/oidc/example/callback/?code=x", "example")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should return not found for unknown provider", func(t *testing.T) {
+		rec := serve(handler.CallbackHandler, "/auth/oidc/missing/callback/?code=x&state=y", "missing")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
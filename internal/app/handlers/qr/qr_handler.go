@@ -0,0 +1,86 @@
+package qr_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"url-shortener/internal/app/models/qr"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/domain"
+	"url-shortener/internal/app/services/qr"
+	"url-shortener/internal/app/services/url"
+)
+
+// Handler handles HTTP requests for QR codes of short links.
+type Handler struct {
+	Service    *qr_service.Service
+	URLService *url_service.Service
+	// BaseURL is the public address of the shortener; the request host is used when empty.
+	BaseURL string
+	// Domains gives the addresses of links on custom domains, which are encoded instead when set.
+	Domains *domain_service.Service
+}
+
+// NewQRHandler creates a new instance of QRHandler with the given services.
+func NewQRHandler(service *qr_service.Service, urlService *url_service.Service, baseURL string) *Handler {
+	return &Handler{Service: service, URLService: urlService, BaseURL: baseURL}
+}
+
+// GetQRCodeHandler handles HTTP requests to get the QR code of a short link.
+func (h *Handler) GetQRCodeHandler(c echo.Context) error {
+	code := c.Param("code")
+
+	// Only existing, enabled links get a code; scheduled links do, to be printed before they open
+	if _, err := h.URLService.GetOriginalURL(code); err != nil && !errors.Is(err, url_model.ErrURLNotActive) {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrURLDisabled) || errors.Is(err, url_model.ErrURLExpired) {
+			return c.JSON(http.StatusGone, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	var req qr_model.Request
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid query parameters"})
+	}
+
+	shortURL, err := h.shortURL(c, code)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	data, contentType, err := h.Service.Generate(shortURL, req)
+	if err != nil {
+		if errors.Is(err, qr_model.ErrInvalidFormat) || errors.Is(err, qr_model.ErrInvalidSize) ||
+			errors.Is(err, qr_model.ErrInvalidMargin) || errors.Is(err, qr_model.ErrInvalidLevel) ||
+			errors.Is(err, qr_model.ErrInvalidColor) || errors.Is(err, qr_model.ErrLogoUnavailable) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	c.Response().Header().Set("Cache-Control", "public, max-age=86400")
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+// shortURL returns the public redirect address of the short code, on its custom domain if it has one.
+func (h *Handler) shortURL(c echo.Context, code string) (string, error) {
+	if h.Domains != nil {
+		link, err := h.Domains.ShortLink(code)
+		if err == nil {
+			return link, nil
+		}
+		if !errors.Is(err, url_model.ErrURLNotFound) {
+			return "", err
+		}
+	}
+
+	base := h.BaseURL
+	if base == "" {
+		base = c.Scheme() + "://" + c.Request().Host
+	}
+	return strings.TrimSuffix(base, "/") + "/clicks/" + code, nil
+}
@@ -0,0 +1,89 @@
+package qr_handler
+
+import (
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/services/domain"
+	"url-shortener/internal/app/services/qr"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+)
+
+func TestGetQRCodeHandler(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	qrService := qr_service.NewQRService(nil, 10)
+	handler := NewQRHandler(qrService, urlService, "https://sho.rt/")
+
+	_, _ = urlRepository.CreateURL("https://www.example.com", "abc123", nil)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "gone", nil)
+	_ = urlRepository.SetDisabled("gone", true)
+
+	get := func(h *Handler, code, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/url/"+code+"/qr/?"+query, nil)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, h.GetQRCodeHandler(c))
+		return rec
+	}
+
+	t.Run("Should return PNG by default", func(t *testing.T) {
+		rec := get(handler, "abc123", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "public, max-age=86400", rec.Header().Get("Cache-Control"))
+		assert.True(t, strings.HasPrefix(rec.Body.String(), "\x89PNG"))
+	})
+
+	t.Run("Should return SVG with parameters", func(t *testing.T) {
+		rec := get(handler, "abc123", "format=svg&size=512&margin=2&level=H&fg=%23112233&bg=ffffff")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "image/svg+xml", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), `width="512"`)
+		assert.Contains(t, rec.Body.String(), `fill="#112233"`)
+	})
+
+	t.Run("Should encode the request host without base URL", func(t *testing.T) {
+		rec := get(NewQRHandler(qrService, urlService, ""), "abc123", "format=svg")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Should encode links on custom domains with their domain", func(t *testing.T) {
+		domainRepository := mocks.NewMockDomainRepository()
+		domainRepository.Domains[1] = &domain_model.Domain{ID: 1, Hostname: "go.example.com", UserID: 1, Verified: true}
+		_ = domainRepository.CreateLink(1, "launch", "abc123")
+		withDomains := NewQRHandler(qrService, urlService, "https://sho.rt/")
+		withDomains.Domains = domain_service.NewDomainService(domainRepository, urlService)
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+		shortURL, err := withDomains.shortURL(c, "abc123")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://go.example.com/launch", shortURL)
+
+		shortURL, err = withDomains.shortURL(c, "gone")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://sho.rt/clicks/gone", shortURL)
+		assert.Equal(t, http.StatusOK, get(withDomains, "abc123", "").Code)
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get(handler, "missing", "").Code)
+		assert.Equal(t, http.StatusGone, get(handler, "gone", "").Code)
+		assert.Equal(t, http.StatusBadRequest, get(handler, "abc123", "size=big").Code)
+		assert.Equal(t, http.StatusBadRequest, get(handler, "abc123", "format=gif").Code)
+
+		rec := get(handler, "abc123", "logo=true")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "no logo is configured")
+	})
+}
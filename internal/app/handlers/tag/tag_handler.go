@@ -0,0 +1,189 @@
+package tag_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/tag"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/tag"
+	"url-shortener/internal/app/services/token"
+	"url-shortener/internal/app/services/webhook"
+)
+
+// Handler handles HTTP requests related to tags.
+type Handler struct {
+	// Service is the tag service instance.
+	Service      *tag_service.Service
+	TokenService token_service.TokenRepository
+	// Webhooks notifies the webhooks of link owners of retagged links, nil when disabled.
+	Webhooks *webhook_service.Service
+}
+
+// NewTagHandler creates a new instance of TagHandler with the given tag service.
+func NewTagHandler(service *tag_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, TokenService: tokenService}
+}
+
+// ListTagsHandler handles HTTP requests to list the tags of the caller.
+func (h *Handler) ListTagsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	tags, err := h.Service.ListTags(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tags)
+}
+
+// CreateTagHandler handles HTTP requests to create a tag.
+func (h *Handler) CreateTagHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req tag_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	tag, err := h.Service.CreateTag(userID, req.Name)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, tag)
+}
+
+// RenameTagHandler handles HTTP requests to rename a tag.
+func (h *Handler) RenameTagHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	tagID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag ID"})
+	}
+
+	var req tag_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	tag, err := h.Service.RenameTag(userID, tagID, req.Name)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTagHandler handles HTTP requests to delete a tag and remove it from its links.
+func (h *Handler) DeleteTagHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	tagID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid tag ID"})
+	}
+
+	if err := h.Service.DeleteTag(userID, tagID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AssignTagsHandler handles HTTP requests to add and remove tags of many links at once.
+func (h *Handler) AssignTagsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req tag_model.AssignRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if err := h.Service.AssignTags(userID, req); err != nil {
+		return errorResponse(c, err)
+	}
+	published := make(map[string]bool, len(req.URLs))
+	for _, code := range req.URLs {
+		if !published[code] {
+			published[code] = true
+			h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, code)
+		}
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetStatsHandler handles HTTP requests to get the aggregate clicks of the links of each tag.
+func (h *Handler) GetStatsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	stats, err := h.Service.GetStats(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, stats)
+}
+
+// authenticate validates the bearer token and returns the user ID.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func paramID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	return uint(id), err
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, url_model.ErrInvalidTag), errors.Is(err, tag_model.ErrNoTagChanges),
+		errors.Is(err, tag_model.ErrNoURLs), errors.Is(err, url_model.ErrTooManyURLs):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, tag_model.ErrTagNotFound), errors.Is(err, url_model.ErrURLNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, tag_model.ErrTagAlreadyExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,117 @@
+package tag_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/tag"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler with the given token, body and tag path parameter.
+func serve(handler echo.HandlerFunc, method, body, token, tagID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/tags/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(tagID)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestTagHandlers(t *testing.T) {
+	// "mockToken" is user 1, who owns the link "first", and any other token is user 123
+	urlRepository := mocks.NewMockUrlRepository()
+	tagRepository := mocks.NewMockTagRepository()
+	tagRepository.URLTags = urlRepository.Tags
+	user := uint(1)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &user)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := tag_service.NewTagService(tagRepository, urlService)
+	h := NewTagHandler(service, mocks.NewMockTokenService())
+
+	t.Run("Should create and list tags", func(t *testing.T) {
+		rec := serve(h.CreateTagHandler, http.MethodPost, `{"name":"news"}`, "mockToken", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"news"`)
+
+		rec = serve(h.ListTagsHandler, http.MethodGet, "", "mockToken", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"url_count":0`)
+	})
+
+	t.Run("Should reject duplicate and invalid names", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, serve(h.CreateTagHandler, http.MethodPost, `{"name":"news"}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.CreateTagHandler, http.MethodPost, `{"name":""}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.CreateTagHandler, http.MethodPost, `{`, "mockToken", "").Code)
+	})
+
+	t.Run("Should rename and delete tags", func(t *testing.T) {
+		rec := serve(h.RenameTagHandler, http.MethodPut, `{"name":"press"}`, "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"press"`)
+
+		assert.Equal(t, http.StatusNotFound, serve(h.RenameTagHandler, http.MethodPut, `{"name":"mine"}`, "other", "1").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.DeleteTagHandler, http.MethodDelete, "", "mockToken", "abc").Code)
+		assert.Equal(t, http.StatusNoContent, serve(h.DeleteTagHandler, http.MethodDelete, "", "mockToken", "1").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.DeleteTagHandler, http.MethodDelete, "", "mockToken", "1").Code)
+	})
+
+	t.Run("Should require token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(h.ListTagsHandler, http.MethodGet, "", "", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(h.GetStatsHandler, http.MethodGet, "", "invalid", "").Code)
+	})
+}
+
+func TestAssignTagsHandler(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	tagRepository := mocks.NewMockTagRepository()
+	tagRepository.URLTags = urlRepository.Tags
+	user := uint(1)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &user)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := tag_service.NewTagService(tagRepository, urlService)
+	h := NewTagHandler(service, mocks.NewMockTokenService())
+	webhookRepository := mocks.NewMockWebhookRepository()
+	h.Webhooks = webhook_service.NewWebhookService(webhookRepository, urlService)
+	_, _ = webhookRepository.Create(&webhook_model.Webhook{URL: "https://hooks.example.com", UserID: 1, Events: []string{webhook_model.EventLinkUpdated}, Active: true})
+
+	t.Run("Should assign tags", func(t *testing.T) {
+		rec := serve(h.AssignTagsHandler, http.MethodPost, `{"urls":["first","first"],"add":["news","promo"]}`, "mockToken", "")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, []string{"news", "promo"}, tagRepository.URLTags["first"])
+		deliveries, _ := webhookRepository.ListDeliveries(1, "", 10)
+		assert.Len(t, deliveries, 1)
+
+		rec = serve(h.GetStatsHandler, http.MethodGet, "", "mockToken", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"news","urls":1`)
+	})
+
+	t.Run("Should reject links of other users", func(t *testing.T) {
+		rec := serve(h.AssignTagsHandler, http.MethodPost, `{"urls":["first"],"add":["news"]}`, "other", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = serve(h.AssignTagsHandler, http.MethodPost, `{"urls":["missing"],"add":["news"]}`, "mockToken", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should reject empty changes", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(h.AssignTagsHandler, http.MethodPost, `{"urls":["first"]}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.AssignTagsHandler, http.MethodPost, `{"add":["news"]}`, "mockToken", "").Code)
+	})
+}
@@ -1,13 +1,27 @@
 package url_handler
 
 import (
+	"errors"
 	"github.com/labstack/echo/v4"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/job"
 	"url-shortener/internal/app/models/url"
+	user_model "url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/services/domain"
+	email_service "url-shortener/internal/app/services/email"
+	"url-shortener/internal/app/services/job"
+	"url-shortener/internal/app/services/metadata"
 	"url-shortener/internal/app/services/token"
 	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
 )
 
 // Handler handles HTTP requests related to URLs.
@@ -15,11 +29,30 @@ type Handler struct {
 	// Service is the URL service instance.
 	Service      *url_service.Service
 	TokenService token_service.TokenRepository
+	// EmailService gates custom aliases on a verified email address.
+	EmailService *email_service.Service
+	// JobService runs the bulk creations too large to wait for.
+	JobService *job_service.Service
+	// BulkLimits bounds the number of URLs of a bulk creation.
+	BulkLimits url_service.BulkLimits
+	// Metadata fetches the page metadata of new links in the background, nil when fetching is disabled.
+	Metadata *metadata_service.Service
+	// Domains creates the links of custom domains, nil when they are not served.
+	Domains *domain_service.Service
+	// Webhooks notifies the webhooks of link owners of created, changed and deleted links, nil when disabled.
+	Webhooks *webhook_service.Service
 }
 
 // NewURLHandler creates a new instance of URLHandler with the given URL service.
-func NewURLHandler(service *url_service.Service, tokenService token_service.TokenRepository) *Handler {
-	return &Handler{Service: service, TokenService: tokenService}
+// It uses url_service.DefaultBulkLimits until others are set.
+func NewURLHandler(service *url_service.Service, tokenService token_service.TokenRepository, emailService *email_service.Service) *Handler {
+	return &Handler{
+		Service:      service,
+		TokenService: tokenService,
+		EmailService: emailService,
+		JobService:   job_service.NewJobService(),
+		BulkLimits:   url_service.DefaultBulkLimits(),
+	}
 }
 
 // ShortenURLHandler handles HTTP requests to shorten a URL.
@@ -45,7 +78,7 @@ func (h *Handler) ShortenURLHandler(c echo.Context) error {
 	}
 
 	// Parse request body to extract URL data
-	var urlData url_model.URL
+	var urlData url_model.ShortenRequest
 	if err := c.Bind(&urlData); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
 	}
@@ -60,16 +93,79 @@ func (h *Handler) ShortenURLHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid URL"})
 	}
 
-	// Call the URL service to shorten the URL with the user ID
-	shortenedURL, err := h.Service.ShortenURL(urlData.OriginalURL, userID)
+	// Custom aliases are reserved for accounts with a verified email address
+	if urlData.Alias != "" {
+		if userID == nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required for custom aliases"})
+		}
+		if err := h.EmailService.RequireVerified(*userID); err != nil {
+			if errors.Is(err, user_model.ErrEmailNotVerified) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+	}
+
+	// Call the URL service to shorten the URL with the user ID, on the custom domain or inside the workspace when one is given
+	var shortenedURL string
+	var link *domain_model.Link
+	switch {
+	case urlData.Domain != "":
+		if userID == nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required for custom domains"})
+		}
+		if h.Domains == nil {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": domain_model.ErrDomainNotFound.Error()})
+		}
+		link, err = h.Domains.ShortenURL(*userID, urlData.Domain, urlData.OriginalURL, urlData.Alias)
+		if err == nil {
+			shortenedURL = link.ShortenedURL
+		}
+	case urlData.WorkspaceID != nil:
+		if userID == nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required for workspace links"})
+		}
+		shortenedURL, err = h.Service.ShortenURLInWorkspace(urlData.OriginalURL, urlData.Alias, *userID, *urlData.WorkspaceID)
+	default:
+		shortenedURL, err = h.Service.ShortenURLWithAlias(urlData.OriginalURL, urlData.Alias, userID)
+	}
 	if err != nil {
+		if errors.Is(err, workspace_model.ErrNotMember) || errors.Is(err, workspace_model.ErrInsufficientRole) || errors.Is(err, url_model.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain_model.ErrDomainNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, domain_model.ErrDomainNotVerified) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		var rejection *url_model.Rejection
+		if errors.As(err, &rejection) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error":  url_model.ErrUnsafeURL.Error(),
+				"reason": rejection.Reason,
+				"detail": rejection.Detail,
+			})
+		}
+		if errors.Is(err, url_model.ErrInvalidAlias) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrShortCodeAlreadyExists) {
+			return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+		}
 		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
 	}
 
+	h.Metadata.Enqueue(shortenedURL, urlData.OriginalURL)
+	h.Webhooks.PublishLink(webhook_model.EventLinkCreated, shortenedURL)
+
+	if link != nil {
+		return c.JSON(http.StatusCreated, link)
+	}
 	return c.JSON(http.StatusCreated, map[string]string{"shortened_url": shortenedURL})
 }
 
-// GetUserUrlsHandler handles HTTP requests to get the URLs of a user.
+// GetUserUrlsHandler handles HTTP requests to get the URLs of a user, optionally filtered by tag or folder.
 func (h *Handler) GetUserUrlsHandler(c echo.Context) error {
 	// Extract token from request headers or cookies
 	token := c.Request().Header.Get("Authorization")
@@ -92,6 +188,24 @@ func (h *Handler) GetUserUrlsHandler(c echo.Context) error {
 	}
 	userID = id
 
+	// Filter by tag or folder when asked, listing the tags of each URL
+	filter := url_model.Filter{Tag: strings.TrimSpace(c.QueryParam("tag"))}
+	if folder := c.QueryParam("folder"); folder != "" {
+		id, err := strconv.ParseUint(folder, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid folder ID"})
+		}
+		folderID := uint(id)
+		filter.FolderID = &folderID
+	}
+	if filter.Tag != "" || filter.FolderID != nil {
+		urls, err := h.Service.FindUserURLs(userID, filter)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, urls)
+	}
+
 	// Call the URL service to get the URLs of the user
 	urls, err := h.Service.GetUserURLs(userID)
 	if err != nil {
@@ -100,3 +214,806 @@ func (h *Handler) GetUserUrlsHandler(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, urls)
 }
+
+// TransferURLHandler handles HTTP requests to move a URL between personal and workspace scope.
+func (h *Handler) TransferURLHandler(c echo.Context) error {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	var req url_model.TransferRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := h.Service.TransferURL(userID, c.Param("code"), req.WorkspaceID); err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrForbidden) || errors.Is(err, workspace_model.ErrNotMember) ||
+			errors.Is(err, workspace_model.ErrInsufficientRole) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"shortened_url": c.Param("code"), "workspace_id": req.WorkspaceID})
+}
+
+// SetPasswordHandler handles HTTP requests to set or remove the password of a URL.
+func (h *Handler) SetPasswordHandler(c echo.Context) error {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	var req url_model.PasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := h.Service.SetPassword(userID, c.Param("code"), req.Password); err != nil {
+		if errors.Is(err, url_model.ErrURLNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrForbidden) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		if errors.Is(err, url_model.ErrInvalidLinkPassword) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"shortened_url": c.Param("code"), "protected": req.Password != ""})
+}
+
+// SetDetailsHandler handles HTTP requests to set the title and notes of a URL.
+func (h *Handler) SetDetailsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.DetailsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	if err := h.Service.SetDetails(userID, c.Param("code"), req); err != nil {
+		return metadataErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, map[string]string{"shortened_url": c.Param("code"), "title": req.Title, "notes": req.Notes})
+}
+
+// GetMetadataHandler handles HTTP requests to get the page metadata fetched from the destination of a URL.
+func (h *Handler) GetMetadataHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	metadata, err := h.Service.GetMetadata(userID, c.Param("code"))
+	if err != nil {
+		return metadataErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, metadata)
+}
+
+// RefreshMetadataHandler handles HTTP requests to fetch the page metadata of a URL again while the client waits.
+func (h *Handler) RefreshMetadataHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.Service.Authorize(userID, c.Param("code"), workspace_model.RoleEditor); err != nil {
+		return metadataErrorResponse(c, err)
+	}
+	metadata, err := h.Metadata.Refresh(c.Request().Context(), c.Param("code"))
+	if err != nil {
+		return metadataErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, metadata)
+}
+
+// GetRulesHandler handles HTTP requests to get the redirect rules of a URL.
+func (h *Handler) GetRulesHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	rules, err := h.Service.GetRules(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"shortened_url": c.Param("code"), "rules": rules})
+}
+
+// SetRulesHandler handles HTTP requests to replace the redirect rules of a URL.
+func (h *Handler) SetRulesHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.RulesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	rules, err := h.Service.SetRules(userID, c.Param("code"), req.Rules)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, map[string]interface{}{"shortened_url": c.Param("code"), "rules": rules})
+}
+
+// GetSplitHandler handles HTTP requests to get the split of a URL across weighted destinations.
+func (h *Handler) GetSplitHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	split, err := h.Service.GetSplit(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+	if split == nil {
+		split = &url_model.Split{Variants: []url_model.Variant{}}
+	}
+
+	return c.JSON(http.StatusOK, split)
+}
+
+// SetSplitHandler handles HTTP requests to replace the split of a URL across weighted destinations.
+func (h *Handler) SetSplitHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.Split
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	split, err := h.Service.SetSplit(userID, c.Param("code"), req)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	if split == nil {
+		split = &url_model.Split{Variants: []url_model.Variant{}}
+	}
+
+	return c.JSON(http.StatusOK, split)
+}
+
+// GetTrackingHandler handles HTTP requests to get the UTM parameters and query passthrough of a URL.
+func (h *Handler) GetTrackingHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	tracking, err := h.Service.GetTracking(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, tracking)
+}
+
+// SetTrackingHandler handles HTTP requests to replace the UTM parameters and query passthrough of a URL.
+func (h *Handler) SetTrackingHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.Tracking
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	tracking, err := h.Service.SetTracking(userID, c.Param("code"), req)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, tracking)
+}
+
+// GetDeepLinkHandler handles HTTP requests to get the apps a URL opens on iOS and Android.
+func (h *Handler) GetDeepLinkHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	deepLink, err := h.Service.GetDeepLink(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, deepLink)
+}
+
+// SetDeepLinkHandler handles HTTP requests to replace the apps a URL opens on iOS and Android.
+func (h *Handler) SetDeepLinkHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.DeepLink
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	deepLink, err := h.Service.SetDeepLink(userID, c.Param("code"), req)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, deepLink)
+}
+
+// GetPreviewHandler handles HTTP requests to get the preview and interstitial settings of a URL.
+func (h *Handler) GetPreviewHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	preview, err := h.Service.GetPreview(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// SetPreviewHandler handles HTTP requests to replace the preview and interstitial settings of a URL.
+func (h *Handler) SetPreviewHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.Preview
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	preview, err := h.Service.SetPreview(userID, c.Param("code"), req)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// GetActivationHandler handles HTTP requests to get the window in which a URL resolves.
+func (h *Handler) GetActivationHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	activation, err := h.Service.GetActivation(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, activation)
+}
+
+// SetActivationHandler handles HTTP requests to replace the window in which a URL resolves and what
+// visitors get before it opens.
+func (h *Handler) SetActivationHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.Activation
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	activation, err := h.Service.SetActivation(userID, c.Param("code"), req)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, activation)
+}
+
+// SetDestinationHandler handles HTTP requests to change the destination and redirect type of a URL.
+func (h *Handler) SetDestinationHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.DestinationRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+
+	revision, err := h.Service.ChangeDestination(userID, c.Param("code"), req.URL, req.RedirectType)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	// The page metadata of the new destination is fetched again
+	h.Metadata.Enqueue(c.Param("code"), revision.Destination)
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, revision)
+}
+
+// GetHistoryHandler handles HTTP requests to get the revisions of a URL.
+func (h *Handler) GetHistoryHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	history, err := h.Service.GetHistory(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// RollbackHandler handles HTTP requests to restore a revision of a URL.
+func (h *Handler) RollbackHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	revisionID, err := strconv.ParseUint(c.Param("rev"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid revision ID"})
+	}
+
+	revision, err := h.Service.Rollback(userID, c.Param("code"), uint(revisionID))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Metadata.Enqueue(c.Param("code"), revision.Destination)
+	h.Webhooks.PublishLink(webhook_model.EventLinkUpdated, c.Param("code"))
+
+	return c.JSON(http.StatusOK, revision)
+}
+
+// DeleteURLHandler handles HTTP requests to delete a URL with its clicks, settings and history.
+func (h *Handler) DeleteURLHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	deleted, err := h.Service.DeleteURL(userID, c.Param("code"))
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	h.Webhooks.Publish(webhook_model.EventLinkDeleted, deleted, deleted)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DryRunRulesHandler handles HTTP requests to show where the redirect rules and split of a URL send a described visit.
+func (h *Handler) DryRunRulesHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req url_model.DryRunRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+	}
+	at := time.Now()
+	if req.Time != "" {
+		parsed, err := time.Parse(time.RFC3339, req.Time)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Time must be an RFC 3339 time"})
+		}
+		at = parsed
+	}
+	query, err := url.ParseQuery(strings.TrimPrefix(req.Query, "?"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Query must be a valid query string"})
+	}
+
+	visitor := url_service.NewVisitor(req.UserAgent, req.AcceptLanguage, req.Country, at)
+	visitor.IPAddress = req.IPAddress
+	match, err := h.Service.DryRun(userID, c.Param("code"), visitor, req.Variant, query)
+	if err != nil {
+		return redirectErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, match)
+}
+
+func redirectErrorResponse(c echo.Context, err error) error {
+	var ruleErr *url_model.RuleError
+	var rejection *url_model.Rejection
+	switch {
+	case errors.As(err, &ruleErr):
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":  url_model.ErrInvalidRule.Error(),
+			"rule":   ruleErr.Rule,
+			"field":  ruleErr.Field,
+			"detail": ruleErr.Detail,
+		})
+	case errors.Is(err, url_model.ErrTooManyRules), errors.Is(err, url_model.ErrInvalidSplit), errors.Is(err, url_model.ErrInvalidUTM),
+		errors.Is(err, url_model.ErrInvalidQueryConflict), errors.Is(err, url_model.ErrInvalidFinalURL), errors.Is(err, url_model.ErrInvalidDeepLink),
+		errors.Is(err, url_model.ErrInvalidPreview), errors.Is(err, url_model.ErrInvalidActivation), errors.Is(err, url_model.ErrInvalidRedirectType):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.As(err, &rejection):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":  url_model.ErrUnsafeURL.Error(),
+			"reason": rejection.Reason,
+			"detail": rejection.Detail,
+		})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrURLNotFound), errors.Is(err, url_model.ErrRevisionNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// authenticate validates the bearer token and returns the user ID.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func metadataErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, url_model.ErrInvalidTitle), errors.Is(err, url_model.ErrInvalidNotes):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrURLNotFound), errors.Is(err, url_model.ErrMetadataNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrMetadataFetch):
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
+	case errors.Is(err, url_model.ErrMetadataDisabled):
+		return c.JSON(http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// BulkShortenHandler handles HTTP requests to shorten many URLs at once, sent as a JSON array
+// or a CSV file. Requests with async set are processed as a background job.
+func (h *Handler) BulkShortenHandler(c echo.Context) error {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	req, err := h.bindBulkRequest(c)
+	if err != nil {
+		if errors.Is(err, url_model.ErrTooManyURLs) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if len(req.URLs) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "At least one URL is required"})
+	}
+	if req.Async && len(req.URLs) > h.BulkLimits.MaxAsyncURLs {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": url_model.ErrTooManyURLs.Error()})
+	}
+	if !req.Async && len(req.URLs) > h.BulkLimits.MaxURLs {
+		return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{
+			"error": url_model.ErrTooManyURLs.Error() + "; use async for up to " + strconv.Itoa(h.BulkLimits.MaxAsyncURLs),
+		})
+	}
+
+	// Custom aliases are reserved for accounts with a verified email address
+	for _, item := range req.URLs {
+		if item.Alias == "" {
+			continue
+		}
+		if err := h.EmailService.RequireVerified(userID); err != nil {
+			if errors.Is(err, user_model.ErrEmailNotVerified) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+			}
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		break
+	}
+
+	if req.Async {
+		job, err := h.JobService.Start(userID, "bulk", len(req.URLs), func(func(int)) (interface{}, error) {
+			report, err := h.Service.ShortenBulk(userID, req.WorkspaceID, req.URLs)
+			if err == nil {
+				h.publishBulk(report)
+			}
+			return report, err
+		})
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		statusURL := "/url/bulk/" + job.ID + "/"
+		c.Response().Header().Set(echo.HeaderLocation, statusURL)
+		return c.JSON(http.StatusAccepted, map[string]string{"job_id": job.ID, "status": job.Status, "status_url": statusURL})
+	}
+
+	report, err := h.Service.ShortenBulk(userID, req.WorkspaceID, req.URLs)
+	if err != nil {
+		if errors.Is(err, workspace_model.ErrNotMember) || errors.Is(err, workspace_model.ErrInsufficientRole) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	h.publishBulk(report)
+
+	// Failed rows are reported in the body; the status tells apart full, partial and no success
+	status := http.StatusCreated
+	if report.Created == 0 {
+		status = http.StatusUnprocessableEntity
+	} else if report.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	return c.JSON(status, report)
+}
+
+// publishBulk publishes link.created for the links created by a bulk creation.
+func (h *Handler) publishBulk(report *url_model.BulkReport) {
+	for _, result := range report.Results {
+		if result.ShortenedURL != "" {
+			h.Webhooks.PublishLink(webhook_model.EventLinkCreated, result.ShortenedURL)
+		}
+	}
+}
+
+// bindBulkRequest reads a bulk creation from a JSON body, a text/csv body or a multipart "file" upload.
+// CSV requests take workspace_id and async from the query string or form fields.
+func (h *Handler) bindBulkRequest(c echo.Context) (*url_model.BulkRequest, error) {
+	var req url_model.BulkRequest
+	max := h.BulkLimits.MaxURLs
+	if h.BulkLimits.MaxAsyncURLs > max {
+		max = h.BulkLimits.MaxAsyncURLs
+	}
+
+	var csv io.Reader
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	switch {
+	case strings.HasPrefix(contentType, echo.MIMEMultipartForm):
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, errors.New("CSV file is required")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		csv = file
+	case strings.HasPrefix(contentType, "text/csv"):
+		csv = c.Request().Body
+	default:
+		if err := c.Bind(&req); err != nil {
+			return nil, errors.New("Invalid request body")
+		}
+		if len(req.URLs) > max {
+			return nil, url_model.ErrTooManyURLs
+		}
+	}
+
+	if csv != nil {
+		items, err := url_service.ParseBulkCSV(csv, max)
+		if err != nil {
+			return nil, err
+		}
+		req.URLs = items
+
+		if value := c.FormValue("workspace_id"); value != "" {
+			id, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return nil, errors.New("Invalid workspace ID")
+			}
+			workspaceID := uint(id)
+			req.WorkspaceID = &workspaceID
+		}
+	}
+
+	if value := c.FormValue("async"); value != "" {
+		async, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("Invalid async flag")
+		}
+		req.Async = req.Async || async
+	}
+
+	return &req, nil
+}
+
+// GetBulkJobHandler handles HTTP requests to get the status of a bulk creation job of the user.
+func (h *Handler) GetBulkJobHandler(c echo.Context) error {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	job, err := h.JobService.Get(userID, c.Param("job"))
+	if err != nil {
+		if errors.Is(err, job_model.ErrJobNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// ImportHandler handles HTTP requests to import links exported from another shortener, sent as
+// the body or a multipart "file" field. The format query parameter is csv or json, detected when
+// unset, and dry_run=true reports what the import would do without creating anything.
+func (h *Handler) ImportHandler(c echo.Context) error {
+	// Extract token from request headers or cookies
+	token := c.Request().Header.Get("Authorization")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+	}
+
+	parts := strings.Fields(token)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+	// Call the authentication service to validate the token and get the user ID
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+	}
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid dry_run flag"})
+		}
+	}
+
+	// Imports keep the source short codes as aliases, which are reserved for verified email addresses
+	if err := h.EmailService.RequireVerified(userID); err != nil {
+		if errors.Is(err, user_model.ErrEmailNotVerified) {
+			return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+
+	var body io.Reader = c.Request().Body
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Export file is required"})
+		}
+		file, err := header.Open()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		}
+		defer file.Close()
+		body = file
+	}
+
+	records, err := url_service.ParseImport(body, c.QueryParam("format"), h.BulkLimits.MaxImportURLs)
+	if err != nil {
+		if errors.Is(err, url_model.ErrTooManyURLs) {
+			return c.JSON(http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	report, err := h.Service.ImportURLs(userID, records, dryRun)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+	for _, result := range report.Results {
+		if !report.DryRun && result.Status == url_model.ImportCreated {
+			h.Webhooks.PublishLink(webhook_model.EventLinkCreated, result.ShortenedURL)
+		}
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
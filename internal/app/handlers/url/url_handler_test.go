@@ -5,12 +5,22 @@ import (
 	"encoding/json"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+	domain_model "url-shortener/internal/app/models/domain"
+	job_model "url-shortener/internal/app/models/job"
 	"url-shortener/internal/app/models/url"
 	user_model "url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/models/workspace"
+	domain_service "url-shortener/internal/app/services/domain"
+	email_service "url-shortener/internal/app/services/email"
+	"url-shortener/internal/app/services/metadata"
 	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
 	"url-shortener/internal/mocks"
 )
 
@@ -21,9 +31,10 @@ var (
 
 func TestShortenUrlHandler(t *testing.T) {
 	mockRepository := mocks.NewMockUrlRepository()
-	mockService := url_service.NewURLService(mockRepository)
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
 	tokenService := mocks.NewMockTokenService()
-	mockHandler := NewURLHandler(mockService, tokenService)
+	emailService := email_service.NewEmailService(mocks.NewMockUserRepository(), mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, tokenService, emailService)
 
 	t.Run("Should shorten a URL", func(t *testing.T) {
 		urlData := url_model.URL{
@@ -165,11 +176,146 @@ func TestShortenUrlHandler(t *testing.T) {
 	})
 }
 
+func TestShortenUrlHandlerAlias(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	tokenService := mocks.NewMockTokenService()
+	userRepository := mocks.NewMockUserRepository()
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, tokenService, emailService)
+
+	// The mock token service resolves "mockToken" to user ID 1
+	user, _ := userRepository.Create(&user_model.User{Username: "testuser", Email: "user@example.com"})
+
+	shorten := func(authorization, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, shortenEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, mockHandler.ShortenURLHandler(c))
+		return rec
+	}
+
+	t.Run("Should require a token for aliases", func(t *testing.T) {
+		rec := shorten("", `{"original_url":"https://www.example.com","alias":"my-alias"}`)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should require a verified email for aliases", func(t *testing.T) {
+		rec := shorten("Bearer mockToken", `{"original_url":"https://www.example.com","alias":"my-alias"}`)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrEmailNotVerified.Error())
+	})
+
+	t.Run("Should shorten with alias", func(t *testing.T) {
+		assert.NoError(t, userRepository.SetEmailVerified(user.ID, user.Email))
+
+		rec := shorten("Bearer mockToken", `{"original_url":"https://www.example.com","alias":"my-alias"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"my-alias"}`, rec.Body.String())
+	})
+
+	t.Run("Should return error for taken alias", func(t *testing.T) {
+		rec := shorten("Bearer mockToken", `{"original_url":"https://www.example.com","alias":"success"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Should return error for invalid alias", func(t *testing.T) {
+		rec := shorten("Bearer mockToken", `{"original_url":"https://www.example.com","alias":"no"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should return error for unknown user", func(t *testing.T) {
+		rec := shorten("Bearer other", `{"original_url":"https://www.example.com","alias":"my-alias"}`)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+func TestShortenUrlHandlerDomain(t *testing.T) {
+	mockService := url_service.NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+	domainRepository := mocks.NewMockDomainRepository()
+	mockHandler.Domains = domain_service.NewDomainService(domainRepository, mockService)
+
+	// The mock token service resolves "mockToken" to user ID 1
+	verified, _ := domainRepository.Create(&domain_model.Domain{Hostname: "go.example.com", UserID: 1})
+	_ = domainRepository.SetVerified(verified.ID, time.Now())
+	_, _ = domainRepository.Create(&domain_model.Domain{Hostname: "pending.example.com", UserID: 1})
+
+	shorten := func(authorization, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, shortenEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, mockHandler.ShortenURLHandler(c))
+		return rec
+	}
+
+	t.Run("Should shorten on a verified domain", func(t *testing.T) {
+		rec := shorten("Bearer mockToken", `{"original_url":"https://www.example.com","domain":"go.example.com"}`)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var link domain_model.Link
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &link))
+		assert.Equal(t, "go.example.com", link.Domain)
+		assert.Equal(t, "https://go.example.com/"+link.Code, link.ShortLink)
+		assert.NotEmpty(t, link.ShortenedURL)
+	})
+
+	t.Run("Should reject domains the user cannot use", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, shorten("", `{"original_url":"https://www.example.com","domain":"go.example.com"}`).Code)
+		assert.Equal(t, http.StatusForbidden, shorten("Bearer other", `{"original_url":"https://www.example.com","domain":"go.example.com"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, shorten("Bearer mockToken", `{"original_url":"https://www.example.com","domain":"pending.example.com"}`).Code)
+		assert.Equal(t, http.StatusNotFound, shorten("Bearer mockToken", `{"original_url":"https://www.example.com","domain":"unknown.example.com"}`).Code)
+	})
+}
+
+func TestShortenUrlHandlerSafety(t *testing.T) {
+	mockService := url_service.NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+	mockService.Safety.OwnHosts = []string{"sho.rt"}
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+
+	shorten := func(originalURL string) *httptest.ResponseRecorder {
+		body := `{"original_url":"` + originalURL + `"}`
+		req := httptest.NewRequest(http.MethodPost, shortenEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, mockHandler.ShortenURLHandler(c))
+		return rec
+	}
+
+	t.Run("Should reject unsafe URLs with a reason", func(t *testing.T) {
+		rec := shorten("javascript:alert(1)")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reason":"scheme_not_allowed"`)
+	})
+
+	t.Run("Should reject redirect loops and private addresses", func(t *testing.T) {
+		assert.Contains(t, shorten("https://sho.rt/abc").Body.String(), `"reason":"redirect_loop"`)
+		assert.Contains(t, shorten("http://192.168.1.1/").Body.String(), `"reason":"private_address"`)
+	})
+}
+
 func TestUserUrlHandlers(t *testing.T) {
 	mockRepository := mocks.NewMockUrlRepository()
-	mockService := url_service.NewURLService(mockRepository)
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
 	tokenService := mocks.NewMockTokenService()
-	mockHandler := NewURLHandler(mockService, tokenService)
+	emailService := email_service.NewEmailService(mocks.NewMockUserRepository(), mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, tokenService, emailService)
 
 	t.Run("Should return error if token is not provided", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, urlEndpoint, nil)
@@ -204,6 +350,39 @@ func TestUserUrlHandlers(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Should filter user urls by tag and folder", func(t *testing.T) {
+		user, folder := uint(1), uint(3)
+		_, _ = mockRepository.CreateURL("https://www.example.org", "tagged", &user)
+		mockRepository.Tags["tagged"] = []string{"news"}
+		_ = mockRepository.SetFolder("tagged", &folder)
+
+		for _, query := range []string{"?tag=news", "?folder=3", "?tag=news&folder=3"} {
+			req := httptest.NewRequest(http.MethodGet, urlEndpoint+query, nil)
+			req.Header.Set("Authorization", "Bearer mockToken")
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := mockHandler.GetUserUrlsHandler(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"tags":["news"]`)
+			assert.NotContains(t, rec.Body.String(), "abc123")
+		}
+	})
+
+	t.Run("Should reject invalid folder filter", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, urlEndpoint+"?folder=abc", nil)
+		req.Header.Set("Authorization", "Bearer mockToken")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		err := mockHandler.GetUserUrlsHandler(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
 	t.Run("Should return error for user not having any urls", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, urlEndpoint, nil)
 		rec := httptest.NewRecorder()
@@ -248,3 +427,718 @@ func TestUserUrlHandlers(t *testing.T) {
 		assert.NoError(t, err)
 	})
 }
+
+func TestWorkspaceUrlHandlers(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	mockService := url_service.NewURLService(mockRepository, workspaceRepository)
+	emailService := email_service.NewEmailService(mocks.NewMockUserRepository(), mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), emailService)
+
+	// "mockToken" is user 1, the owner; any other token is user 123, a viewer
+	workspace, _ := workspaceRepository.Create(&workspace_model.Workspace{Name: "Team", CreatedBy: 1})
+	_ = workspaceRepository.AddMember(workspace.ID, 123, workspace_model.RoleViewer)
+
+	call := func(handler echo.HandlerFunc, authorization, body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should shorten in workspace", func(t *testing.T) {
+		rec := call(mockHandler.ShortenURLHandler, "Bearer mockToken", `{"original_url":"https://www.example.com","workspace_id":1}`, "")
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		urls, _ := mockRepository.GetWorkspaceURLs(workspace.ID)
+		assert.Len(t, urls, 1)
+	})
+
+	t.Run("Should reject workspace links without edit access", func(t *testing.T) {
+		rec := call(mockHandler.ShortenURLHandler, "Bearer other", `{"original_url":"https://www.example.com","workspace_id":1}`, "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = call(mockHandler.ShortenURLHandler, "", `{"original_url":"https://www.example.com","workspace_id":1}`, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should transfer URL", func(t *testing.T) {
+		userID := uint(1)
+		_, _ = mockRepository.CreateURL("https://www.example.com", "mine", &userID)
+
+		rec := call(mockHandler.TransferURLHandler, "Bearer mockToken", `{"workspace_id":1}`, "mine")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"mine","workspace_id":1}`, rec.Body.String())
+		assert.Equal(t, workspace.ID, *mockRepository.Urls[2].WorkspaceID)
+	})
+
+	t.Run("Should reject transfer by viewer", func(t *testing.T) {
+		rec := call(mockHandler.TransferURLHandler, "Bearer other", `{"workspace_id":null}`, "mine")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should return errors for transfer", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, call(mockHandler.TransferURLHandler, "Bearer mockToken", `{}`, "missing").Code)
+		assert.Equal(t, http.StatusUnauthorized, call(mockHandler.TransferURLHandler, "", `{}`, "mine").Code)
+		assert.Equal(t, http.StatusUnauthorized, call(mockHandler.TransferURLHandler, "Token x", `{}`, "mine").Code)
+		assert.Equal(t, http.StatusUnauthorized, call(mockHandler.TransferURLHandler, "Bearer invalid", `{}`, "mine").Code)
+		assert.Equal(t, http.StatusBadRequest, call(mockHandler.TransferURLHandler, "Bearer mockToken", `{"workspace_id":"x"}`, "mine").Code)
+	})
+}
+
+func TestSetPasswordHandler(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+
+	// "mockToken" is user 1, the creator of the link
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://docs.example.com", "docs", &userID)
+
+	setPassword := func(authorization, body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, mockHandler.SetPasswordHandler(c))
+		return rec
+	}
+
+	t.Run("Should set password", func(t *testing.T) {
+		rec := setPassword("Bearer mockToken", `{"password":"secret"}`, "docs")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"docs","protected":true}`, rec.Body.String())
+		assert.NotEmpty(t, mockRepository.PasswordHashes["docs"])
+	})
+
+	t.Run("Should remove password", func(t *testing.T) {
+		rec := setPassword("Bearer mockToken", `{"password":""}`, "docs")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"docs","protected":false}`, rec.Body.String())
+		assert.Empty(t, mockRepository.PasswordHashes["docs"])
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, setPassword("", `{"password":"secret"}`, "docs").Code)
+		assert.Equal(t, http.StatusUnauthorized, setPassword("Bearer invalid", `{"password":"secret"}`, "docs").Code)
+		assert.Equal(t, http.StatusBadRequest, setPassword("Bearer mockToken", `{"password":`, "docs").Code)
+		assert.Equal(t, http.StatusForbidden, setPassword("Bearer other", `{"password":"secret"}`, "docs").Code)
+		assert.Equal(t, http.StatusNotFound, setPassword("Bearer mockToken", `{"password":"secret"}`, "missing").Code)
+		assert.Equal(t, http.StatusInternalServerError, setPassword("Bearer mockToken", `{"password":"secret"}`, "error").Code)
+
+		long, _ := json.Marshal(map[string]string{"password": string(bytes.Repeat([]byte("a"), 73))})
+		assert.Equal(t, http.StatusBadRequest, setPassword("Bearer mockToken", string(long), "docs").Code)
+	})
+}
+
+func TestBulkShortenHandler(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	userRepository := mocks.NewMockUserRepository()
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), emailService)
+	mockHandler.BulkLimits = url_service.BulkLimits{MaxURLs: 2, MaxAsyncURLs: 3}
+
+	// The mock token service resolves "mockToken" to user ID 1
+	user, _ := userRepository.Create(&user_model.User{Username: "testuser", Email: "user@example.com"})
+
+	bulk := func(authorization, contentType, target string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, urlEndpoint+target, bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, mockHandler.BulkShortenHandler(c))
+		return rec
+	}
+
+	getJob := func(authorization, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, urlEndpoint, nil)
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("job")
+		c.SetParamValues(id)
+		assert.NoError(t, mockHandler.GetBulkJobHandler(c))
+		return rec
+	}
+
+	t.Run("Should create URLs from JSON", func(t *testing.T) {
+		rec := bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/",
+			[]byte(`{"urls":[{"url":"https://www.example.com","tags":["docs"]},{"url":"https://www.example.org"}]}`))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var report url_model.BulkReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 2, report.Created)
+		assert.Len(t, mockRepository.Urls, 2)
+	})
+
+	t.Run("Should report partial failures", func(t *testing.T) {
+		rec := bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/",
+			[]byte(`{"urls":[{"url":"https://www.example.com"},{"url":"ftp://files.example.com"}]}`))
+
+		assert.Equal(t, http.StatusMultiStatus, rec.Code)
+		assert.Contains(t, rec.Body.String(), url_model.ReasonScheme)
+
+		rec = bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", []byte(`{"urls":[{"url":""}]}`))
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("Should create URLs from CSV", func(t *testing.T) {
+		rec := bulk("Bearer mockToken", "text/csv", "bulk/", []byte("url,tags\nhttps://www.example.net,docs;team\n"))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", "links.csv")
+		_, _ = part.Write([]byte("url\nhttps://www.example.net\n"))
+		_ = writer.Close()
+		rec = bulk("Bearer mockToken", writer.FormDataContentType(), "bulk/", body.Bytes())
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("Should require a verified email for aliases", func(t *testing.T) {
+		rec := bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", []byte(`{"urls":[{"url":"https://www.example.com","alias":"bulk-alias"}]}`))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		assert.NoError(t, userRepository.SetEmailVerified(user.ID, user.Email))
+		rec = bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", []byte(`{"urls":[{"url":"https://www.example.com","alias":"bulk-alias"}]}`))
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("Should process async requests as a job", func(t *testing.T) {
+		rec := bulk("Bearer mockToken", "text/csv", "bulk/?async=true",
+			[]byte("url\nhttps://a.example.com\nhttps://b.example.com\nhttps://c.example.com\n"))
+
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		var accepted map[string]string
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &accepted))
+		assert.Equal(t, "/url/bulk/"+accepted["job_id"]+"/", rec.Header().Get(echo.HeaderLocation))
+
+		var job struct {
+			job_model.Job
+			Result url_model.BulkReport `json:"result"`
+		}
+		assert.Eventually(t, func() bool {
+			rec := getJob("Bearer mockToken", accepted["job_id"])
+			_ = json.Unmarshal(rec.Body.Bytes(), &job)
+			return job.Status == job_model.StatusCompleted
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, 3, job.Result.Created)
+
+		assert.Equal(t, http.StatusNotFound, getJob("Bearer other", accepted["job_id"]).Code)
+		assert.Equal(t, http.StatusUnauthorized, getJob("Bearer invalid", accepted["job_id"]).Code)
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		three := []byte(`{"urls":[{"url":"https://a.example.com"},{"url":"https://b.example.com"},{"url":"https://c.example.com"}]}`)
+		four := []byte(`{"urls":[{"url":"https://a.example.com"},{"url":"https://b.example.com"},{"url":"https://c.example.com"},{"url":"https://d.example.com"}],"async":true}`)
+
+		assert.Equal(t, http.StatusUnauthorized, bulk("", echo.MIMEApplicationJSON, "bulk/", three).Code)
+		assert.Equal(t, http.StatusUnauthorized, bulk("Bearer expired", echo.MIMEApplicationJSON, "bulk/", three).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", []byte(`{"urls":`)).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", []byte(`{"urls":[]}`)).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk("Bearer mockToken", "text/csv", "bulk/", []byte("alias\nx\n")).Code)
+		assert.Equal(t, http.StatusBadRequest, bulk("Bearer mockToken", "text/csv", "bulk/?async=maybe", []byte("url\nx\n")).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", three).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/", four).Code)
+		assert.Equal(t, http.StatusForbidden, bulk("Bearer mockToken", echo.MIMEApplicationJSON, "bulk/",
+			[]byte(`{"urls":[{"url":"https://a.example.com"}],"workspace_id":9}`)).Code)
+	})
+}
+
+func TestImportHandler(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	userRepository := mocks.NewMockUserRepository()
+	emailService := email_service.NewEmailService(userRepository, mocks.NewMockMailer(), "secret", "")
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), emailService)
+	mockHandler.BulkLimits.MaxImportURLs = 2
+
+	// The mock token service resolves "mockToken" to user ID 1
+	user, _ := userRepository.Create(&user_model.User{Username: "testuser", Email: "user@example.com"})
+
+	importLinks := func(authorization, contentType, query string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, urlEndpoint+"import/"+query, bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		assert.NoError(t, mockHandler.ImportHandler(c))
+		return rec
+	}
+	export := []byte("keyword,url,clicks\ndocs,https://docs.example.com,42\n")
+
+	t.Run("Should require a verified email", func(t *testing.T) {
+		rec := importLinks("Bearer mockToken", "text/csv", "", export)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should report a dry run", func(t *testing.T) {
+		assert.NoError(t, userRepository.SetEmailVerified(user.ID, user.Email))
+
+		rec := importLinks("Bearer mockToken", "text/csv", "?dry_run=true", export)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var report url_model.ImportReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Empty(t, mockRepository.Urls)
+	})
+
+	t.Run("Should import an uploaded file once", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, _ := writer.CreateFormFile("file", "export.csv")
+		_, _ = part.Write(export)
+		_ = writer.Close()
+
+		rec := importLinks("Bearer mockToken", writer.FormDataContentType(), "", body.Bytes())
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 42, mockRepository.Urls[1].ImportedClicks)
+
+		rec = importLinks("Bearer mockToken", echo.MIMEApplicationJSON, "?format=json", []byte(`[{"keyword":"docs","url":"https://docs.example.com"}]`))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"skipped":1`)
+		assert.Len(t, mockRepository.Urls, 1)
+	})
+
+	t.Run("Should return errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, importLinks("", "text/csv", "", export).Code)
+		assert.Equal(t, http.StatusUnauthorized, importLinks("Bearer invalid", "text/csv", "", export).Code)
+		assert.Equal(t, http.StatusBadRequest, importLinks("Bearer mockToken", "text/csv", "?dry_run=maybe", export).Code)
+		assert.Equal(t, http.StatusBadRequest, importLinks("Bearer mockToken", "text/csv", "?format=xml", export).Code)
+		assert.Equal(t, http.StatusBadRequest, importLinks("Bearer mockToken", echo.MIMEMultipartForm+"; boundary=x", "", nil).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, importLinks("Bearer mockToken", "text/csv", "", []byte("url\na\nb\nc\n")).Code)
+		assert.Equal(t, http.StatusInternalServerError, importLinks("Bearer other", "text/csv", "", export).Code)
+	})
+}
+
+func TestLinkDetailsHandlers(t *testing.T) {
+	page := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set(echo.HeaderContentType, echo.MIMETextHTML)
+		_, _ = w.Write([]byte(`<title>Documentation</title><meta name="description" content="All the docs">`))
+	}))
+	defer page.Close()
+
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+
+	// "mockToken" is user 1, the creator of the links
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL(page.URL+"/docs", "docs", &userID)
+	_, _ = mockRepository.CreateURL(page.URL+"/gone", "gone", &userID)
+
+	serve := func(handler echo.HandlerFunc, method, authorization, body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should set title and notes", func(t *testing.T) {
+		rec := serve(mockHandler.SetDetailsHandler, http.MethodPut, "Bearer mockToken", `{"title":"Docs","notes":"For the team"}`, "docs")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"docs","title":"Docs","notes":"For the team"}`, rec.Body.String())
+		assert.Equal(t, "For the team", mockRepository.Urls[1].Notes)
+	})
+
+	t.Run("Should return details errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(mockHandler.SetDetailsHandler, http.MethodPut, "", `{}`, "docs").Code)
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.SetDetailsHandler, http.MethodPut, "Bearer other", `{}`, "docs").Code)
+		assert.Equal(t, http.StatusNotFound, serve(mockHandler.SetDetailsHandler, http.MethodPut, "Bearer mockToken", `{}`, "missing").Code)
+		long := `{"title":"` + string(bytes.Repeat([]byte("a"), 256)) + `"}`
+		assert.Equal(t, http.StatusBadRequest, serve(mockHandler.SetDetailsHandler, http.MethodPut, "Bearer mockToken", long, "docs").Code)
+	})
+
+	t.Run("Should report metadata fetching as disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(mockHandler.GetMetadataHandler, http.MethodGet, "Bearer mockToken", "", "docs").Code)
+		assert.Equal(t, http.StatusServiceUnavailable, serve(mockHandler.RefreshMetadataHandler, http.MethodPost, "Bearer mockToken", "", "docs").Code)
+	})
+
+	mockHandler.Metadata = metadata_service.NewMetadataService(mockRepository, metadata_service.NewFetcher(page.Client()), 10)
+
+	t.Run("Should refresh and return metadata", func(t *testing.T) {
+		rec := serve(mockHandler.RefreshMetadataHandler, http.MethodPost, "Bearer mockToken", "", "docs")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"description":"All the docs"`)
+
+		rec = serve(mockHandler.GetMetadataHandler, http.MethodGet, "Bearer mockToken", "", "docs")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"title":"Documentation"`)
+		// The title set by the user is kept
+		assert.Equal(t, "Docs", mockRepository.Urls[1].Title)
+	})
+
+	t.Run("Should return refresh errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadGateway, serve(mockHandler.RefreshMetadataHandler, http.MethodPost, "Bearer mockToken", "", "gone").Code)
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.RefreshMetadataHandler, http.MethodPost, "Bearer other", "", "docs").Code)
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.GetMetadataHandler, http.MethodGet, "Bearer other", "", "docs").Code)
+	})
+
+	t.Run("Should queue new links", func(t *testing.T) {
+		// Nothing runs the queue, which holds a single link
+		mockHandler.Metadata = metadata_service.NewMetadataService(mockRepository, metadata_service.NewFetcher(page.Client()), 1)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, shortenEndpoint, bytes.NewReader([]byte(`{"original_url":"https://www.example.com"}`)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		assert.NoError(t, mockHandler.ShortenURLHandler(echo.New().NewContext(req, rec)))
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.False(t, mockHandler.Metadata.Enqueue("other", "https://www.example.org"))
+	})
+}
+
+func TestRedirectRulesHandlers(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+
+	// "mockToken" is user 1, the creator of the link
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com", "app", &userID)
+
+	serve := func(handler echo.HandlerFunc, method, authorization, body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should set and get rules", func(t *testing.T) {
+		body := `{"rules":[{"name":"iOS","if":{"devices":["iOS"]},"destination":"https://apps.apple.com/app/id1"}]}`
+		rec := serve(mockHandler.SetRulesHandler, http.MethodPut, "Bearer mockToken", body, "app")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"shortened_url":"app","rules":[{"name":"iOS","if":{"devices":["ios"]},"destination":"https://apps.apple.com/app/id1"}]}`, rec.Body.String())
+
+		rec = serve(mockHandler.GetRulesHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"destination":"https://apps.apple.com/app/id1"`)
+	})
+
+	t.Run("Should explain invalid rules", func(t *testing.T) {
+		body := `{"rules":[{"if":{"countries":["Turkey"]},"destination":"https://www.example.com/tr"}]}`
+		rec := serve(mockHandler.SetRulesHandler, http.MethodPut, "Bearer mockToken", body, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid redirect rule","rule":1,"field":"countries","detail":"\"Turkey\" is not valid"}`, rec.Body.String())
+	})
+
+	t.Run("Should dry run a visit", func(t *testing.T) {
+		body := `{"user_agent":"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)","country":"tr","time":"2026-10-19T12:00:00Z"}`
+		rec := serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", body, "app")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"rule":1,"name":"iOS","destination":"https://apps.apple.com/app/id1","redirect_type":301,"revision":2,
+			"visitor":{"device":"ios","country":"TR","language":"","time":"2026-10-19T12:00:00Z"}}`, rec.Body.String())
+
+		rec = serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{}`, "app")
+		assert.Contains(t, rec.Body.String(), `"rule":null,"destination":"https://www.example.com"`)
+	})
+
+	t.Run("Should set and get the split", func(t *testing.T) {
+		rec := serve(mockHandler.GetSplitHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, `{"sticky":"","variants":[]}`, rec.Body.String())
+
+		body := `{"sticky":"ip","variants":[{"name":"a","destination":"https://www.example.com/a","weight":1},{"name":"b","destination":"https://www.example.com/b","weight":1}]}`
+		rec = serve(mockHandler.SetSplitHandler, http.MethodPut, "Bearer mockToken", body, "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, body, rec.Body.String())
+
+		rec = serve(mockHandler.GetSplitHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, body, rec.Body.String())
+	})
+
+	t.Run("Should dry run the split when no rule matches", func(t *testing.T) {
+		rec := serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{"ip_address":"203.0.113.7"}`, "app")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"rule":null`)
+		assert.Contains(t, rec.Body.String(), `"variant":`)
+	})
+
+	t.Run("Should reject invalid splits", func(t *testing.T) {
+		body := `{"variants":[{"name":"a","destination":"https://www.example.com/a","weight":1}]}`
+		rec := serve(mockHandler.SetSplitHandler, http.MethodPut, "Bearer mockToken", body, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid split: a split needs 2 to 10 variants"}`, rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.GetSplitHandler, http.MethodGet, "Bearer other", "", "app").Code)
+	})
+
+	t.Run("Should remove the split", func(t *testing.T) {
+		rec := serve(mockHandler.SetSplitHandler, http.MethodPut, "Bearer mockToken", `{"variants":[]}`, "app")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"sticky":"","variants":[]}`, rec.Body.String())
+	})
+
+	t.Run("Should set and get tracking", func(t *testing.T) {
+		rec := serve(mockHandler.GetTrackingHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, `{"utm":{"source":"","medium":"","campaign":"","term":"","content":""},"pass_query":false,"query_conflict":"keep"}`, rec.Body.String())
+
+		body := `{"utm":{"source":"newsletter","medium":"email","campaign":"launch","term":"","content":""},"pass_query":true,"query_conflict":"replace"}`
+		rec = serve(mockHandler.SetTrackingHandler, http.MethodPut, "Bearer mockToken", body, "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, body, rec.Body.String())
+
+		rec = serve(mockHandler.GetTrackingHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, body, rec.Body.String())
+	})
+
+	t.Run("Should dry run a visit with its query", func(t *testing.T) {
+		rec := serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{"query":"?utm_medium=social&gclid=1"}`, "app")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"destination":"https://www.example.com?utm_source=newsletter\u0026utm_campaign=launch\u0026gclid=1\u0026utm_medium=social"`)
+
+		rec = serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{"query":"a=%zz"}`, "app")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should reject invalid tracking", func(t *testing.T) {
+		rec := serve(mockHandler.SetTrackingHandler, http.MethodPut, "Bearer mockToken", `{"pass_query":true,"query_conflict":"merge"}`, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"query conflict must be keep, replace or append"}`, rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.SetTrackingHandler, http.MethodPut, "Bearer other", `{}`, "app").Code)
+	})
+
+	t.Run("Should set and get the deep link", func(t *testing.T) {
+		rec := serve(mockHandler.GetDeepLinkHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, `{"ios":{"uri":"","fallback":""},"android":{"uri":"","fallback":""}}`, rec.Body.String())
+
+		body := `{"ios":{"uri":"shop://home","fallback":"https://apps.apple.com/app/id1"},"android":{"uri":"shop://home","fallback":""}}`
+		rec = serve(mockHandler.SetDeepLinkHandler, http.MethodPut, "Bearer mockToken", body, "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, body, rec.Body.String())
+
+		rec = serve(mockHandler.GetDeepLinkHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, body, rec.Body.String())
+	})
+
+	t.Run("Should reject invalid deep links", func(t *testing.T) {
+		rec := serve(mockHandler.SetDeepLinkHandler, http.MethodPut, "Bearer mockToken", `{"ios":{"uri":"javascript:alert(1)"}}`, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid deep link: ios: scheme \"javascript\" is not allowed"}`, rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.GetDeepLinkHandler, http.MethodGet, "Bearer other", "", "app").Code)
+	})
+
+	t.Run("Should set and get the preview", func(t *testing.T) {
+		rec := serve(mockHandler.GetPreviewHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, `{"title":"","description":"","image":"","interstitial":false,"delay":0}`, rec.Body.String())
+
+		body := `{"title":"Our app","description":"Get it now","image":"https://www.example.com/card.png","interstitial":true,"delay":3}`
+		rec = serve(mockHandler.SetPreviewHandler, http.MethodPut, "Bearer mockToken", body, "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, body, rec.Body.String())
+
+		rec = serve(mockHandler.GetPreviewHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, body, rec.Body.String())
+	})
+
+	t.Run("Should reject invalid previews", func(t *testing.T) {
+		rec := serve(mockHandler.SetPreviewHandler, http.MethodPut, "Bearer mockToken", `{"interstitial":true,"delay":60}`, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid preview: delay must be 0 to 30 seconds"}`, rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.SetPreviewHandler, http.MethodPut, "Bearer other", `{}`, "app").Code)
+	})
+
+	t.Run("Should set and get the activation window", func(t *testing.T) {
+		rec := serve(mockHandler.GetActivationHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, `{"active_from":null,"active_until":null,"not_active":{"mode":"not_found","url":"","message":""}}`, rec.Body.String())
+
+		body := `{"active_from":"2030-01-01T09:00:00Z","active_until":null,"not_active":{"mode":"page","url":"","message":"Opens soon"}}`
+		rec = serve(mockHandler.SetActivationHandler, http.MethodPut, "Bearer mockToken", body, "app")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, body, rec.Body.String())
+
+		rec = serve(mockHandler.GetActivationHandler, http.MethodGet, "Bearer mockToken", "", "app")
+		assert.JSONEq(t, body, rec.Body.String())
+
+		_ = serve(mockHandler.SetActivationHandler, http.MethodPut, "Bearer mockToken", `{}`, "app")
+	})
+
+	t.Run("Should reject invalid activation windows", func(t *testing.T) {
+		body := `{"active_from":"2030-01-02T00:00:00Z","active_until":"2030-01-01T00:00:00Z"}`
+		rec := serve(mockHandler.SetActivationHandler, http.MethodPut, "Bearer mockToken", body, "app")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"invalid activation window: active_until must be after active_from"}`, rec.Body.String())
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.SetActivationHandler, http.MethodPut, "Bearer other", `{}`, "app").Code)
+	})
+
+	t.Run("Should return rules errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(mockHandler.GetRulesHandler, http.MethodGet, "", "", "app").Code)
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.SetRulesHandler, http.MethodPut, "Bearer other", `{}`, "app").Code)
+		assert.Equal(t, http.StatusNotFound, serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{}`, "missing").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(mockHandler.DryRunRulesHandler, http.MethodPost, "Bearer mockToken", `{"time":"tomorrow"}`, "app").Code)
+	})
+}
+
+func TestHistoryHandlers(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+
+	// "mockToken" is user 1, the creator of the link
+	userID := uint(1)
+	_, _ = mockRepository.CreateURL("https://www.example.com/v1", "docs", &userID)
+
+	serve := func(handler echo.HandlerFunc, method, authorization, body, rev string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code", "rev")
+		c.SetParamValues("docs", rev)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should change the destination", func(t *testing.T) {
+		rec := serve(mockHandler.SetDestinationHandler, http.MethodPut, "Bearer mockToken", `{"url":"https://www.example.com/v2","redirect_type":302}`, "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"destination":"https://www.example.com/v2","previous_destination":"https://www.example.com/v1","redirect_type":302,"rules":[],"changed_by":1`)
+	})
+
+	t.Run("Should list the history newest first", func(t *testing.T) {
+		rec := serve(mockHandler.GetHistoryHandler, http.MethodGet, "Bearer mockToken", "", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var history []url_model.Revision
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+		if assert.Len(t, history, 2) {
+			assert.Equal(t, uint(2), history[0].ID)
+			assert.Equal(t, "https://www.example.com/v1", history[1].Destination)
+		}
+	})
+
+	t.Run("Should roll back to a revision", func(t *testing.T) {
+		rec := serve(mockHandler.RollbackHandler, http.MethodPost, "Bearer mockToken", "", "1")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"destination":"https://www.example.com/v1","previous_destination":"https://www.example.com/v2","redirect_type":301`)
+		assert.Contains(t, rec.Body.String(), `"restored_from":1`)
+	})
+
+	t.Run("Should return history errors", func(t *testing.T) {
+		rec := serve(mockHandler.SetDestinationHandler, http.MethodPut, "Bearer mockToken", `{"url":"https://www.example.com","redirect_type":200}`, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"redirect type must be 301, 302, 307 or 308"}`, rec.Body.String())
+
+		rec = serve(mockHandler.SetDestinationHandler, http.MethodPut, "Bearer mockToken", `{"url":"ftp://www.example.com"}`, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reason":"scheme_not_allowed"`)
+
+		assert.Equal(t, http.StatusNotFound, serve(mockHandler.RollbackHandler, http.MethodPost, "Bearer mockToken", "", "99").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(mockHandler.RollbackHandler, http.MethodPost, "Bearer mockToken", "", "latest").Code)
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.GetHistoryHandler, http.MethodGet, "Bearer other", "", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(mockHandler.SetDestinationHandler, http.MethodPut, "", `{}`, "").Code)
+	})
+}
+
+func TestDeleteURLHandler(t *testing.T) {
+	mockRepository := mocks.NewMockUrlRepository()
+	mockService := url_service.NewURLService(mockRepository, mocks.NewMockWorkspaceRepository())
+	mockHandler := NewURLHandler(mockService, mocks.NewMockTokenService(), nil)
+	webhookRepository := mocks.NewMockWebhookRepository()
+	mockHandler.Webhooks = webhook_service.NewWebhookService(webhookRepository, mockService)
+	_, _ = webhookRepository.Create(&webhook_model.Webhook{URL: "https://hooks.example.com", UserID: 1, Active: true})
+
+	serve := func(handler echo.HandlerFunc, method, authorization, body, code string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, urlEndpoint, bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.SetParamNames("code")
+		c.SetParamValues(code)
+		assert.NoError(t, handler(c))
+		return rec
+	}
+
+	t.Run("Should publish the events of a link", func(t *testing.T) {
+		rec := serve(mockHandler.ShortenURLHandler, http.MethodPost, "Bearer mockToken", `{"original_url":"https://www.example.com"}`, "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var created map[string]string
+		_ = json.Unmarshal(rec.Body.Bytes(), &created)
+		code := created["shortened_url"]
+
+		rec = serve(mockHandler.SetDetailsHandler, http.MethodPut, "Bearer mockToken", `{"title":"Docs"}`, code)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serve(mockHandler.DeleteURLHandler, http.MethodDelete, "Bearer mockToken", "", code)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		_, err := mockRepository.GetURL(code)
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+
+		deliveries, _ := webhookRepository.ListDeliveries(1, "", 10)
+		if assert.Len(t, deliveries, 3) {
+			assert.Equal(t, webhook_model.EventLinkDeleted, deliveries[0].EventType)
+			assert.Contains(t, deliveries[0].Payload, `"shortened_url":"`+code+`"`)
+			assert.Equal(t, webhook_model.EventLinkUpdated, deliveries[1].EventType)
+			assert.Equal(t, webhook_model.EventLinkCreated, deliveries[2].EventType)
+		}
+	})
+
+	t.Run("Should return delete errors", func(t *testing.T) {
+		userID := uint(1)
+		_, _ = mockRepository.CreateURL("https://www.example.com", "kept", &userID)
+
+		assert.Equal(t, http.StatusForbidden, serve(mockHandler.DeleteURLHandler, http.MethodDelete, "Bearer other", "", "kept").Code)
+		assert.Equal(t, http.StatusNotFound, serve(mockHandler.DeleteURLHandler, http.MethodDelete, "Bearer mockToken", "", "missing").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(mockHandler.DeleteURLHandler, http.MethodDelete, "", "", "kept").Code)
+	})
+}
@@ -0,0 +1,247 @@
+package webhook_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/token"
+	"url-shortener/internal/app/services/webhook"
+)
+
+// Handler handles HTTP requests related to webhooks.
+type Handler struct {
+	// Service is the webhook service instance.
+	Service      *webhook_service.Service
+	TokenService token_service.TokenRepository
+}
+
+// NewWebhookHandler creates a new instance of WebhookHandler with the given webhook service.
+func NewWebhookHandler(service *webhook_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, TokenService: tokenService}
+}
+
+// ListWebhooksHandler handles HTTP requests to list the webhooks of the caller and the workspaces they own.
+func (h *Handler) ListWebhooksHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	webhooks, err := h.Service.ListWebhooks(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// CreateWebhookHandler handles HTTP requests to register a webhook, answering with the secret signing its deliveries.
+func (h *Handler) CreateWebhookHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req webhook_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	webhook, err := h.Service.CreateWebhook(userID, req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhookHandler handles HTTP requests to get a webhook.
+func (h *Handler) GetWebhookHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	webhookID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+	}
+
+	webhook, err := h.Service.GetWebhook(userID, webhookID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhookHandler handles HTTP requests to change the URL, events or state of a webhook.
+func (h *Handler) UpdateWebhookHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	webhookID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+	}
+
+	var req webhook_model.Request
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	webhook, err := h.Service.UpdateWebhook(userID, webhookID, req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhookHandler handles HTTP requests to delete a webhook with its deliveries.
+func (h *Handler) DeleteWebhookHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	webhookID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+	}
+
+	if err := h.Service.DeleteWebhook(userID, webhookID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// TestWebhookHandler handles HTTP requests to send a test event to a webhook while the client waits.
+func (h *Handler) TestWebhookHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	webhookID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+	}
+
+	delivery, err := h.Service.SendTest(c.Request().Context(), userID, webhookID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	// The outcome of the delivery is in the body, failed or not
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// ListDeliveriesHandler handles HTTP requests to list the latest deliveries of a webhook, filtered by
+// the status query parameter; status=dead lists the dead letters.
+func (h *Handler) ListDeliveriesHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	webhookID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid webhook ID"})
+	}
+
+	deliveries, err := h.Service.ListDeliveries(userID, webhookID, c.QueryParam("status"))
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// GetDeliveryHandler handles HTTP requests to get a delivery with the log of its attempts.
+func (h *Handler) GetDeliveryHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	deliveryID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+
+	delivery, err := h.Service.GetDelivery(userID, deliveryID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, delivery)
+}
+
+// RetryDeliveryHandler handles HTTP requests to queue a dead or failed delivery again.
+func (h *Handler) RetryDeliveryHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	deliveryID, err := paramID(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid delivery ID"})
+	}
+
+	delivery, err := h.Service.RetryDelivery(userID, deliveryID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusAccepted, delivery)
+}
+
+// authenticate validates the bearer token and returns the user ID.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func paramID(c echo.Context) (uint, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	return uint(id), err
+}
+
+func errorResponse(c echo.Context, err error) error {
+	var rejection *url_model.Rejection
+	switch {
+	case errors.Is(err, webhook_model.ErrInvalidEvent), errors.Is(err, webhook_model.ErrInvalidStatus):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.As(err, &rejection):
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error":  url_model.ErrUnsafeURL.Error(),
+			"reason": rejection.Reason,
+			"detail": rejection.Detail,
+		})
+	case errors.Is(err, url_model.ErrForbidden):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, webhook_model.ErrWebhookNotFound), errors.Is(err, webhook_model.ErrDeliveryNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, webhook_model.ErrDeliveryNotFailed):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
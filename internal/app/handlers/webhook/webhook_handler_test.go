@@ -0,0 +1,142 @@
+package webhook_handler
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler with the given token, body and path parameter.
+func serve(handler echo.HandlerFunc, method, target, body, token, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestWebhookHandlers(t *testing.T) {
+	// "mockToken" is user 1, the creator of the link "docs", and any other token is user 123
+	urlRepository := mocks.NewMockUrlRepository()
+	userID := uint(1)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "docs", &userID)
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := webhook_service.NewWebhookService(mocks.NewMockWebhookRepository(), urlService)
+	h := NewWebhookHandler(service, mocks.NewMockTokenService())
+
+	// Deliveries go to a receiver answering every hostname with the status
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	service.Client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+
+	t.Run("Should register webhooks and show the secret once", func(t *testing.T) {
+		rec := serve(h.CreateWebhookHandler, http.MethodPost, "/webhooks/", `{"url":"http://hooks.example.com/","events":["link.created"]}`, "mockToken", "")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"secret":"whsec_`)
+		assert.Contains(t, rec.Body.String(), `"events":["link.created"]`)
+
+		rec = serve(h.ListWebhooksHandler, http.MethodGet, "/webhooks/", "", "mockToken", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"url":"http://hooks.example.com/"`)
+		assert.NotContains(t, rec.Body.String(), "secret")
+
+		rec = serve(h.GetWebhookHandler, http.MethodGet, "/webhooks/1/", "", "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "secret")
+	})
+
+	t.Run("Should reject invalid and unauthorized webhooks", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(h.CreateWebhookHandler, http.MethodPost, "/webhooks/", `{"url":"http://hooks.example.com/","events":["link.lost"]}`, "mockToken", "").Code)
+		rec := serve(h.CreateWebhookHandler, http.MethodPost, "/webhooks/", `{"url":"http://10.0.0.1/"}`, "mockToken", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"reason":"private_address"`)
+		assert.Equal(t, http.StatusForbidden, serve(h.CreateWebhookHandler, http.MethodPost, "/webhooks/", `{"url":"http://hooks.example.com/","workspace_id":7}`, "mockToken", "").Code)
+		assert.Equal(t, http.StatusForbidden, serve(h.GetWebhookHandler, http.MethodGet, "/webhooks/1/", "", "other", "1").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.GetWebhookHandler, http.MethodGet, "/webhooks/9/", "", "mockToken", "9").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.GetWebhookHandler, http.MethodGet, "/webhooks/x/", "", "mockToken", "x").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(h.ListWebhooksHandler, http.MethodGet, "/webhooks/", "", "", "").Code)
+	})
+
+	t.Run("Should send test events", func(t *testing.T) {
+		rec := serve(h.TestWebhookHandler, http.MethodPost, "/webhooks/1/test/", "", "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"event_type":"webhook.test","payload"`)
+		assert.Contains(t, rec.Body.String(), `"status":"delivered"`)
+
+		status = http.StatusServiceUnavailable
+		rec = serve(h.TestWebhookHandler, http.MethodPost, "/webhooks/1/test/", "", "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+		assert.Contains(t, rec.Body.String(), `"response_status":503`)
+	})
+
+	t.Run("Should list dead letters and retry them", func(t *testing.T) {
+		h.Service.MaxAttempts = 1
+		h.Service.PublishLink(webhook_model.EventLinkCreated, "docs")
+		_, err := h.Service.DeliverDue(context.Background())
+		assert.NoError(t, err)
+
+		rec := serve(h.ListDeliveriesHandler, http.MethodGet, "/webhooks/1/deliveries/?status=dead", "", "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var dead []webhook_model.Delivery
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dead))
+		if !assert.Len(t, dead, 1) {
+			return
+		}
+		assert.Equal(t, webhook_model.EventLinkCreated, dead[0].EventType)
+		id := strconv.Itoa(int(dead[0].ID))
+
+		rec = serve(h.ListDeliveriesHandler, http.MethodGet, "/webhooks/1/deliveries/", "", "mockToken", "1")
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &dead))
+		assert.Len(t, dead, 3)
+		assert.Equal(t, http.StatusBadRequest, serve(h.ListDeliveriesHandler, http.MethodGet, "/webhooks/1/deliveries/?status=lost", "", "mockToken", "1").Code)
+
+		rec = serve(h.GetDeliveryHandler, http.MethodGet, "/webhooks/deliveries/"+id+"/", "", "mockToken", id)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"log":[{"response_status":503,"error":"status 503"`)
+		assert.Equal(t, http.StatusForbidden, serve(h.GetDeliveryHandler, http.MethodGet, "/webhooks/deliveries/"+id+"/", "", "other", id).Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.GetDeliveryHandler, http.MethodGet, "/webhooks/deliveries/9/", "", "mockToken", "9").Code)
+
+		rec = serve(h.RetryDeliveryHandler, http.MethodPost, "/webhooks/deliveries/"+id+"/retry/", "", "mockToken", id)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"pending","attempts":0`)
+		assert.Equal(t, http.StatusConflict, serve(h.RetryDeliveryHandler, http.MethodPost, "/webhooks/deliveries/"+id+"/retry/", "", "mockToken", id).Code)
+	})
+
+	t.Run("Should update and delete webhooks", func(t *testing.T) {
+		rec := serve(h.UpdateWebhookHandler, http.MethodPut, "/webhooks/1/", `{"events":[],"active":false}`, "mockToken", "1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"events":[],"active":false`)
+		assert.Equal(t, http.StatusForbidden, serve(h.UpdateWebhookHandler, http.MethodPut, "/webhooks/1/", `{}`, "other", "1").Code)
+
+		assert.Equal(t, http.StatusForbidden, serve(h.DeleteWebhookHandler, http.MethodDelete, "/webhooks/1/", "", "other", "1").Code)
+		assert.Equal(t, http.StatusNoContent, serve(h.DeleteWebhookHandler, http.MethodDelete, "/webhooks/1/", "", "mockToken", "1").Code)
+		assert.Equal(t, http.StatusNotFound, serve(h.DeleteWebhookHandler, http.MethodDelete, "/webhooks/1/", "", "mockToken", "1").Code)
+	})
+}
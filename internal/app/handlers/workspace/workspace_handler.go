@@ -0,0 +1,216 @@
+package workspace_handler
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"strings"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/services/token"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/workspace"
+)
+
+// Handler handles HTTP requests related to workspaces.
+type Handler struct {
+	// Service is the workspace service instance.
+	Service      *workspace_service.Service
+	URLService   *url_service.Service
+	TokenService token_service.TokenRepository
+}
+
+// NewWorkspaceHandler creates a new instance of WorkspaceHandler with the given workspace service.
+func NewWorkspaceHandler(service *workspace_service.Service, urlService *url_service.Service, tokenService token_service.TokenRepository) *Handler {
+	return &Handler{Service: service, URLService: urlService, TokenService: tokenService}
+}
+
+// CreateWorkspaceHandler handles HTTP requests to create a workspace owned by the caller.
+func (h *Handler) CreateWorkspaceHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	var req workspace_model.CreateRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	workspace, err := h.Service.CreateWorkspace(userID, req.Name)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, workspace)
+}
+
+// ListWorkspacesHandler handles HTTP requests to list the workspaces of the caller.
+func (h *Handler) ListWorkspacesHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+
+	workspaces, err := h.Service.ListWorkspaces(userID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, workspaces)
+}
+
+// ListMembersHandler handles HTTP requests to list the members of a workspace.
+func (h *Handler) ListMembersHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	workspaceID, err := paramID(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid workspace ID"})
+	}
+
+	members, err := h.Service.GetMembers(userID, workspaceID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+// AddMemberHandler handles HTTP requests to add a user to a workspace.
+func (h *Handler) AddMemberHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	workspaceID, err := paramID(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid workspace ID"})
+	}
+
+	var req workspace_model.MemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	member, err := h.Service.AddMember(userID, workspaceID, req.Username, req.Role)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, member)
+}
+
+// UpdateMemberHandler handles HTTP requests to change the role of a workspace member.
+func (h *Handler) UpdateMemberHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	workspaceID, err := paramID(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid workspace ID"})
+	}
+	memberID, err := paramID(c, "user")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	var req workspace_model.MemberRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	member, err := h.Service.UpdateMemberRole(userID, workspaceID, memberID, req.Role)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, member)
+}
+
+// RemoveMemberHandler handles HTTP requests to remove a member from a workspace or to leave it.
+func (h *Handler) RemoveMemberHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	workspaceID, err := paramID(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid workspace ID"})
+	}
+	memberID, err := paramID(c, "user")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid user ID"})
+	}
+
+	if err := h.Service.RemoveMember(userID, workspaceID, memberID); err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWorkspaceURLsHandler handles HTTP requests to list the URLs of a workspace.
+func (h *Handler) GetWorkspaceURLsHandler(c echo.Context) error {
+	userID, ok := h.authenticate(c)
+	if !ok {
+		return nil
+	}
+	workspaceID, err := paramID(c, "id")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid workspace ID"})
+	}
+
+	urls, err := h.URLService.GetWorkspaceURLs(userID, workspaceID)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, urls)
+}
+
+// authenticate validates the bearer token and returns the user ID.
+// When it fails the error response has already been written.
+func (h *Handler) authenticate(c echo.Context) (uint, bool) {
+	// Extract token from request headers
+	parts := strings.Fields(c.Request().Header.Get("Authorization"))
+	if len(parts) == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+		return 0, false
+	}
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+
+	userID, err := h.TokenService.ValidateToken(parts[1])
+	if err != nil || userID == 0 {
+		_ = c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		return 0, false
+	}
+	return userID, true
+}
+
+func paramID(c echo.Context, name string) (uint, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	return uint(id), err
+}
+
+func errorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, workspace_model.ErrInvalidName), errors.Is(err, workspace_model.ErrInvalidRole):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, workspace_model.ErrNotMember), errors.Is(err, workspace_model.ErrInsufficientRole):
+		return c.JSON(http.StatusForbidden, map[string]string{"error": err.Error()})
+	case errors.Is(err, user_model.ErrUserNotFound), errors.Is(err, workspace_model.ErrWorkspaceNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, workspace_model.ErrMemberAlreadyExists), errors.Is(err, workspace_model.ErrLastOwner):
+		return c.JSON(http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
@@ -0,0 +1,151 @@
+package workspace_handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/workspace"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serve calls a handler with the given token, body and workspace and user path parameters.
+func serve(handler echo.HandlerFunc, method, body, token, workspaceID, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/workspaces/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	c.SetParamNames("id", "user")
+	c.SetParamValues(workspaceID, userID)
+
+	_ = handler(c)
+	return rec
+}
+
+func TestCreateWorkspaceHandler(t *testing.T) {
+	// "mockToken" is user 1 and any other token is user 123
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Users[1] = &user_model.User{ID: 1, Username: "owner"}
+	userRepository.Users[123] = &user_model.User{ID: 123, Username: "alice"}
+
+	workspaceService := workspace_service.NewWorkspaceService(repository, userRepository)
+	urlService := url_service.NewURLService(mocks.NewMockUrlRepository(), repository)
+	h := NewWorkspaceHandler(workspaceService, urlService, mocks.NewMockTokenService())
+
+	t.Run("Should create workspace", func(t *testing.T) {
+		rec := serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":"Team"}`, "mockToken", "", "")
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"role":"owner"`)
+		assert.Equal(t, workspace_model.RoleOwner, repository.Members[1][1])
+	})
+
+	t.Run("Should reject invalid name", func(t *testing.T) {
+		rec := serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":""}`, "mockToken", "", "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Should require token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":"Team"}`, "", "", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":"Team"}`, "invalid", "", "").Code)
+	})
+
+	t.Run("Should list workspaces", func(t *testing.T) {
+		rec := serve(h.ListWorkspacesHandler, http.MethodGet, "", "mockToken", "", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"name":"Team"`)
+	})
+}
+
+func TestMemberHandlers(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Users[1] = &user_model.User{ID: 1, Username: "owner"}
+	userRepository.Users[123] = &user_model.User{ID: 123, Username: "alice"}
+
+	workspaceService := workspace_service.NewWorkspaceService(repository, userRepository)
+	urlService := url_service.NewURLService(mocks.NewMockUrlRepository(), repository)
+	h := NewWorkspaceHandler(workspaceService, urlService, mocks.NewMockTokenService())
+	serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":"Team"}`, "mockToken", "", "")
+
+	t.Run("Should reject outsiders", func(t *testing.T) {
+		rec := serve(h.ListMembersHandler, http.MethodGet, "", "other", "1", "")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should add member", func(t *testing.T) {
+		rec := serve(h.AddMemberHandler, http.MethodPost, `{"username":"alice","role":"viewer"}`, "mockToken", "1", "")
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, workspace_model.RoleViewer, repository.Members[1][123])
+
+		rec = serve(h.AddMemberHandler, http.MethodPost, `{"username":"alice","role":"viewer"}`, "mockToken", "1", "")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		rec = serve(h.AddMemberHandler, http.MethodPost, `{"username":"missing","role":"viewer"}`, "mockToken", "1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("Should list members", func(t *testing.T) {
+		rec := serve(h.ListMembersHandler, http.MethodGet, "", "other", "1", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"user_id":123`)
+	})
+
+	t.Run("Should update member role", func(t *testing.T) {
+		rec := serve(h.UpdateMemberHandler, http.MethodPut, `{"role":"editor"}`, "mockToken", "1", "123")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, workspace_model.RoleEditor, repository.Members[1][123])
+
+		rec = serve(h.UpdateMemberHandler, http.MethodPut, `{"role":"owner"}`, "other", "1", "123")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+
+		rec = serve(h.UpdateMemberHandler, http.MethodPut, `{"role":"viewer"}`, "mockToken", "1", "1")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("Should reject invalid IDs", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(h.ListMembersHandler, http.MethodGet, "", "mockToken", "x", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(h.RemoveMemberHandler, http.MethodDelete, "", "mockToken", "1", "x").Code)
+	})
+
+	t.Run("Should remove member", func(t *testing.T) {
+		rec := serve(h.RemoveMemberHandler, http.MethodDelete, "", "other", "1", "123")
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.NotContains(t, repository.Members[1], uint(123))
+	})
+}
+
+func TestGetWorkspaceURLsHandler(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Users[1] = &user_model.User{ID: 1, Username: "owner"}
+	userRepository.Users[123] = &user_model.User{ID: 123, Username: "alice"}
+	urlRepository := mocks.NewMockUrlRepository()
+
+	workspaceService := workspace_service.NewWorkspaceService(repository, userRepository)
+	urlService := url_service.NewURLService(urlRepository, repository)
+	h := NewWorkspaceHandler(workspaceService, urlService, mocks.NewMockTokenService())
+	serve(h.CreateWorkspaceHandler, http.MethodPost, `{"name":"Team"}`, "mockToken", "", "")
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "team", 1, 1)
+
+	rec := serve(h.GetWorkspaceURLsHandler, http.MethodGet, "", "mockToken", "1", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"shortened_url":"team"`)
+
+	rec = serve(h.GetWorkspaceURLsHandler, http.MethodGet, "", "other", "1", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
@@ -0,0 +1,152 @@
+package ratelimit_middleware
+
+import (
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/app/services/token"
+)
+
+// Names of the rate limited routes, used as keys of the policies.
+const (
+	RouteShorten  = "shorten"
+	RouteBulk     = "bulk"
+	RouteImport   = "import"
+	RouteRedirect = "redirect"
+	RouteUnlock   = "unlock"
+	RouteQR       = "qr"
+)
+
+// Policy allows Limit requests per Period, with bursts of up to Burst requests.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+	// Burst is the size of the bucket, Limit when zero.
+	Burst int
+}
+
+// burst returns the size of the bucket of the policy.
+func (p Policy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// rate returns the number of tokens added to the bucket per second.
+func (p Policy) rate() float64 {
+	return float64(p.Limit) / p.Period.Seconds()
+}
+
+// RoutePolicy holds the quotas of a route. A nil policy leaves the matching clients unlimited.
+type RoutePolicy struct {
+	Anonymous     *Policy
+	Authenticated *Policy
+	// Param is the path parameter giving each of its values its own bucket, e.g. one per link.
+	Param string
+	// Shared counts all clients against the same bucket, e.g. to bound the guesses on a link.
+	Shared bool
+}
+
+// Identity is the client a request is counted against.
+type Identity struct {
+	Key           string
+	Authenticated bool
+}
+
+// KeyFunc returns the identity of the client of a request.
+type KeyFunc func(c echo.Context) Identity
+
+// NewKeyFunc returns a KeyFunc keying requests by user ID, then IP address. The IP address is the one
+// the IP extractor of the server gives, which only trusts X-Forwarded-For behind trusted proxies.
+func NewKeyFunc(tokenService token_service.TokenRepository) KeyFunc {
+	return func(c echo.Context) Identity {
+		parts := strings.Fields(c.Request().Header.Get("Authorization"))
+		if tokenService != nil && len(parts) == 2 && parts[0] == "Bearer" {
+			userID, err := tokenService.ValidateToken(parts[1])
+			if err == nil && userID != 0 {
+				return Identity{Key: fmt.Sprintf("user:%d", userID), Authenticated: true}
+			}
+		}
+
+		return Identity{Key: "ip:" + c.RealIP()}
+	}
+}
+
+// Limiter rate limits routes with token buckets kept in a Store.
+type Limiter struct {
+	Store    Store
+	Key      KeyFunc
+	Policies map[string]RoutePolicy
+}
+
+// NewLimiter creates a new instance of Limiter.
+func NewLimiter(store Store, key KeyFunc, policies map[string]RoutePolicy) *Limiter {
+	return &Limiter{
+		Store:    store,
+		Key:      key,
+		Policies: policies,
+	}
+}
+
+// Route returns the middleware enforcing the policy of the named route.
+// Routes without a policy, and a nil Limiter, let every request through.
+func (l *Limiter) Route(name string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if l == nil {
+				return next(c)
+			}
+			route, ok := l.Policies[name]
+			if !ok {
+				return next(c)
+			}
+
+			identity := l.Key(c)
+			policy := route.Anonymous
+			if identity.Authenticated {
+				policy = route.Authenticated
+			}
+			if policy == nil || policy.Limit <= 0 || policy.Period <= 0 {
+				return next(c)
+			}
+
+			key := name + ":" + identity.Key
+			if route.Shared {
+				key = name
+			}
+			if route.Param != "" {
+				key += ":" + c.Param(route.Param)
+			}
+
+			result, err := l.Store.Take(key, *policy)
+			if err != nil {
+				// A broken store must not take the service down with it
+				c.Logger().Error("[RATELIMIT] Error taking token: ", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(policy.burst()))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%s", policy.burst(), ceilSeconds(policy.Period)))
+
+			if !result.Allowed {
+				header.Set("Retry-After", ceilSeconds(result.RetryAfter))
+				return c.JSON(http.StatusTooManyRequests, map[string]string{"error": "Too many requests"})
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds formats the duration as whole seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,136 @@
+package ratelimit_middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore is a Store whose backend is down.
+type failingStore struct{}
+
+func (failingStore) Take(string, Policy) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestLimiter_Route(t *testing.T) {
+	next := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+	policies := map[string]RoutePolicy{
+		RouteShorten: {
+			Anonymous:     &Policy{Limit: 1, Period: time.Minute},
+			Authenticated: &Policy{Limit: 2, Period: time.Minute},
+		},
+		RouteRedirect: {
+			Anonymous: &Policy{Limit: 1, Period: time.Minute},
+			Param:     "id",
+		},
+		RouteUnlock: {
+			Anonymous:     &Policy{Limit: 1, Period: time.Minute},
+			Authenticated: &Policy{Limit: 1, Period: time.Minute},
+			Param:         "id",
+			Shared:        true,
+		},
+	}
+	keyFunc := NewKeyFunc(mocks.NewMockTokenService())
+
+	// serve runs the named route with the given headers and path parameter
+	serve := func(limiter *Limiter, route string, headers map[string]string, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		e := echo.New()
+		e.IPExtractor = echo.ExtractIPDirect()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+
+		assert.NoError(t, limiter.Route(route)(next)(c))
+		return rec
+	}
+
+	t.Run("Should limit anonymous clients by IP", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+
+		rec := serve(limiter, RouteShorten, nil, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "1;w=60", rec.Header().Get("RateLimit-Policy"))
+
+		rec = serve(limiter, RouteShorten, nil, "")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Should give authenticated users their own quota", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+		bearer := map[string]string{echo.HeaderAuthorization: "Bearer mockToken"}
+
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteShorten, nil, "").Code)
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteShorten, bearer, "").Code)
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteShorten, bearer, "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, RouteShorten, bearer, "").Code)
+	})
+
+	t.Run("Should treat invalid tokens as anonymous", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteShorten, map[string]string{echo.HeaderAuthorization: "Bearer invalid"}, "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, RouteShorten, map[string]string{"X-API-Key": "random"}, "").Code)
+	})
+
+	t.Run("Should not let anonymous clients pick their IP address", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteShorten, map[string]string{echo.HeaderXForwardedFor: "198.51.100.1"}, "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, RouteShorten, map[string]string{echo.HeaderXForwardedFor: "198.51.100.2"}, "").Code)
+	})
+
+	t.Run("Should limit each link separately", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteRedirect, nil, "abc").Code)
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteRedirect, nil, "def").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, RouteRedirect, nil, "abc").Code)
+	})
+
+	t.Run("Should share buckets across clients", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+		bearer := map[string]string{echo.HeaderAuthorization: "Bearer mockToken"}
+
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteUnlock, nil, "abc").Code)
+		assert.Equal(t, http.StatusTooManyRequests, serve(limiter, RouteUnlock, bearer, "abc").Code)
+		assert.Equal(t, http.StatusOK, serve(limiter, RouteUnlock, bearer, "def").Code)
+	})
+
+	t.Run("Should not limit routes without policy", func(t *testing.T) {
+		limiter := NewLimiter(NewMemoryStore(), keyFunc, policies)
+		bearer := map[string]string{echo.HeaderAuthorization: "Bearer mockToken"}
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusOK, serve(limiter, "other", nil, "").Code)
+			assert.Equal(t, http.StatusOK, serve(limiter, RouteRedirect, bearer, "abc").Code)
+		}
+		assert.Equal(t, http.StatusOK, serve(nil, RouteShorten, nil, "").Code)
+	})
+
+	t.Run("Should let requests through when the store fails", func(t *testing.T) {
+		limiter := NewLimiter(failingStore{}, keyFunc, policies)
+
+		rec := serve(limiter, RouteShorten, nil, "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+	})
+}
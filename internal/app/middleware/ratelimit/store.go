@@ -0,0 +1,106 @@
+package ratelimit_middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the state of a bucket after a request has been counted.
+type Result struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+	// Remaining is the number of requests left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when Allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets. Implementations must be safe for concurrent use;
+// a shared backend lets several instances of the service enforce the same quotas.
+type Store interface {
+	// Take removes one token from the bucket of the key, creating a full bucket when it does not exist.
+	Take(key string, policy Policy) (Result, error)
+}
+
+// sweepInterval is how often the MemoryStore drops the buckets of idle clients.
+const sweepInterval = time.Minute
+
+// bucket is a token bucket of the MemoryStore.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	// full is when the bucket will be refilled, after which dropping it loses nothing.
+	full time.Time
+}
+
+// MemoryStore is a Store keeping the buckets in the memory of the process.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewMemoryStore creates a new instance of MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take removes one token from the bucket of the key.
+func (s *MemoryStore) Take(key string, policy Policy) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(policy.burst())
+	rate := policy.rate()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+
+	// Refill for the time passed since the last request
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	result := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / rate)
+	b.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep drops the buckets that have refilled so idle clients do not use memory forever.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(value float64) time.Duration {
+	return time.Duration(value * float64(time.Second))
+}
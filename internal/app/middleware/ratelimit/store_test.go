@@ -0,0 +1,62 @@
+package ratelimit_middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	policy := Policy{Limit: 2, Period: time.Minute}
+
+	t.Run("Should allow until the bucket is empty", func(t *testing.T) {
+		result, err := store.Take("a", policy)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+
+		result, _ = store.Take("a", policy)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, time.Minute, result.Reset)
+
+		result, _ = store.Take("a", policy)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 30*time.Second, result.RetryAfter)
+	})
+
+	t.Run("Should keep keys apart", func(t *testing.T) {
+		result, _ := store.Take("b", policy)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Should refill over time", func(t *testing.T) {
+		now = now.Add(30 * time.Second)
+
+		result, _ := store.Take("a", policy)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("Should allow bursts above the limit", func(t *testing.T) {
+		burst := Policy{Limit: 1, Period: time.Minute, Burst: 3}
+		for i := 0; i < 3; i++ {
+			result, _ := store.Take("c", burst)
+			assert.True(t, result.Allowed)
+		}
+
+		result, _ := store.Take("c", burst)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Should drop refilled buckets", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		_, _ = store.Take("d", policy)
+		assert.Len(t, store.buckets, 1)
+	})
+}
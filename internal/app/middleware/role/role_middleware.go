@@ -0,0 +1,78 @@
+package role_middleware
+
+import (
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strings"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/app/services/token"
+)
+
+// Context keys set by RequireRole for the handlers behind it.
+const (
+	UserIDKey = "user_id"
+	RoleKey   = "role"
+)
+
+// RequireRole returns a middleware that only lets through bearer tokens carrying one of the given roles.
+// When a repository is given the stored account is checked too, so that demoted or disabled
+// users are rejected before their token expires.
+func RequireRole(tokenService token_service.TokenRepository, repository auth_repository.Repository, roles ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			// Extract token from request headers
+			parts := strings.Fields(c.Request().Header.Get("Authorization"))
+			if len(parts) == 0 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Token is required"})
+			}
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
+
+			claims, err := tokenService.ValidateClaims(parts[1])
+			if err != nil || claims.UserID == 0 {
+				return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+			}
+			if !hasRole(roles, claims.Role) {
+				return c.JSON(http.StatusForbidden, map[string]string{"error": "Insufficient role"})
+			}
+
+			if repository != nil {
+				user, err := repository.GetByID(claims.UserID)
+				if err != nil {
+					if errors.Is(err, user_model.ErrUserNotFound) {
+						return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+					}
+					return c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				}
+				if user.Disabled {
+					return c.JSON(http.StatusForbidden, map[string]string{"error": user_model.ErrAccountDisabled.Error()})
+				}
+				if !hasRole(roles, user.Role) {
+					return c.JSON(http.StatusForbidden, map[string]string{"error": "Insufficient role"})
+				}
+			}
+
+			c.Set(UserIDKey, claims.UserID)
+			c.Set(RoleKey, claims.Role)
+			return next(c)
+		}
+	}
+}
+
+// UserID returns the ID of the user authenticated by RequireRole.
+func UserID(c echo.Context) uint {
+	userID, _ := c.Get(UserIDKey).(uint)
+	return userID
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
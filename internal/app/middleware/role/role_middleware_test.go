@@ -0,0 +1,99 @@
+package role_middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/mocks"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	repository := mocks.NewMockUserRepository()
+	admin, _ := repository.Create(&user_model.User{Username: "admin", Role: user_model.RoleAdmin})
+
+	next := func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]uint{"user_id": UserID(c)})
+	}
+	middleware := RequireRole(mocks.NewMockTokenService(), repository, user_model.RoleAdmin)
+
+	// serve runs the middleware with the given Authorization header
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		assert.NoError(t, middleware(next)(c))
+		return rec
+	}
+
+	t.Run("Should allow admin", func(t *testing.T) {
+		rec := serve("Bearer admin")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"user_id":1}`, rec.Body.String())
+	})
+
+	t.Run("Should reject missing token", func(t *testing.T) {
+		rec := serve("")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Token is required")
+	})
+
+	t.Run("Should reject malformed and invalid tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("Token admin").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer invalid").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve("Bearer expired").Code)
+	})
+
+	t.Run("Should reject other roles", func(t *testing.T) {
+		rec := serve("Bearer mockToken")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should reject demoted admin", func(t *testing.T) {
+		admin.Role = user_model.RoleUser
+		defer func() { admin.Role = user_model.RoleAdmin }()
+
+		rec := serve("Bearer admin")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Should reject disabled admin", func(t *testing.T) {
+		admin.Disabled = true
+		defer func() { admin.Disabled = false }()
+
+		rec := serve("Bearer admin")
+
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), user_model.ErrAccountDisabled.Error())
+	})
+
+	t.Run("Should reject deleted admin", func(t *testing.T) {
+		delete(repository.Users, admin.ID)
+		defer func() { repository.Users[admin.ID] = admin }()
+
+		rec := serve("Bearer admin")
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Should trust claims without repository", func(t *testing.T) {
+		middleware := RequireRole(mocks.NewMockTokenService(), nil, user_model.RoleAdmin, "moderator")
+		req := httptest.NewRequest(http.MethodGet, "/admin/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer admin")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		assert.NoError(t, middleware(next)(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, user_model.RoleAdmin, c.Get(RoleKey))
+	})
+}
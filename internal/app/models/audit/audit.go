@@ -0,0 +1,29 @@
+package audit_model
+
+import (
+	"time"
+)
+
+// Audit actions recorded by the application.
+const (
+	ActionLoginLockout     = "login_lockout"
+	ActionAdminListUsers   = "admin_list_users"
+	ActionAdminViewUser    = "admin_view_user"
+	ActionAdminSetRole     = "admin_set_role"
+	ActionAdminDisableUser = "admin_disable_user"
+	ActionAdminEnableUser  = "admin_enable_user"
+	ActionAdminViewURL     = "admin_view_url"
+	ActionAdminDisableURL  = "admin_disable_url"
+	ActionAdminEnableURL   = "admin_enable_url"
+)
+
+// Entry represents an audit log entry in the application.
+type Entry struct {
+	ID        uint      `json:"id"`
+	Action    string    `json:"action"`
+	ActorID   *uint     `json:"actor_id"`
+	Target    string    `json:"target"`
+	IPAddress string    `json:"ip_address"`
+	Details   string    `json:"details"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -9,8 +9,39 @@ var ErrClicksNotFound = errors.New("clicks not found")
 
 // Clicks represents a Clicks entity in the application.
 type Clicks struct {
-	ID        uint      `json:"id"`
-	UrlID     string    `json:"url_id"`
-	IPAddress string    `json:"ip_address"`
+	ID        uint   `json:"id"`
+	UrlID     string `json:"url_id"`
+	IPAddress string `json:"ip_address"`
+	// Variant is the split variant the click was served, empty for links without a split.
+	Variant string `json:"variant,omitempty"`
+	// Revision is the revision of the link the click was served, 0 for clicks made before its first change.
+	Revision  uint      `json:"revision,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+// VariantStats are the clicks of a variant of a split. Variants removed from the split have no
+// destination and a weight of 0.
+type VariantStats struct {
+	Variant     string `json:"variant"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+	Clicks      int    `json:"clicks"`
+}
+
+// RevisionStats are the clicks served by a revision of a link.
+type RevisionStats struct {
+	Revision    uint   `json:"revision"`
+	Destination string `json:"destination"`
+	Clicks      int    `json:"clicks"`
+}
+
+// Filter selects the clicks to read: those of a link, or of all personal links of a user,
+// optionally within a time range.
+type Filter struct {
+	// ShortURL selects the clicks of a link; when empty UserID selects those of the user's links.
+	ShortURL string
+	UserID   uint
+	// From and To bound the click times, From included and To excluded; nil leaves them open.
+	From *time.Time
+	To   *time.Time
+}
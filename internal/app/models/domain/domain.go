@@ -0,0 +1,74 @@
+package domain_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrDomainNotFound = errors.New("domain not found")
+var ErrDomainAlreadyExists = errors.New("domain is already registered")
+var ErrInvalidHostname = errors.New("hostname must be a domain name such as go.example.com")
+var ErrInvalidMethod = errors.New("verification method must be dns or http")
+var ErrVerificationFailed = errors.New("domain ownership could not be verified")
+var ErrDomainNotVerified = errors.New("domain is not verified")
+var ErrDomainInUse = errors.New("domain still has links")
+
+// Verification methods proving the ownership of a domain.
+const (
+	// MethodDNS looks for a TXT record holding the verification value at DNSName.
+	MethodDNS = "dns"
+	// MethodHTTP fetches the verification file of the domain over HTTP.
+	MethodHTTP = "http"
+)
+
+// VerificationPrefix is prepended to the hostname for the name of the TXT record.
+const VerificationPrefix = "_url-shortener."
+
+// VerificationPath is the path of the verification file, served by the domain with the token as content.
+const VerificationPath = "/.well-known/url-shortener-verification.txt"
+
+// Domain is a branded hostname serving short links of a user or workspace. Short codes are scoped
+// to the domain, so the same code can exist on several domains.
+type Domain struct {
+	ID       uint   `json:"id"`
+	Hostname string `json:"hostname"`
+	// UserID is the user who registered the domain; it owns the domain unless WorkspaceID is set.
+	UserID      uint  `json:"user_id"`
+	WorkspaceID *uint `json:"workspace_id,omitempty"`
+	// Token is the secret the domain must publish to be verified.
+	Token      string     `json:"-"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// Verification explains how to verify the domain, only while it is not verified.
+	Verification *Verification `json:"verification,omitempty"`
+}
+
+// Verification lists the records proving the ownership of a domain; publishing either one is enough.
+type Verification struct {
+	DNSName  string `json:"dns_name"`
+	DNSValue string `json:"dns_value"`
+	HTTPURL  string `json:"http_url"`
+	HTTPBody string `json:"http_body"`
+}
+
+// Link is a short link of a custom domain. Its shortened URL is the internal short code of the link,
+// used by the statistics and settings endpoints.
+type Link struct {
+	Domain       string `json:"domain"`
+	Code         string `json:"code"`
+	ShortenedURL string `json:"shortened_url"`
+	// ShortLink is the full branded link, such as https://go.example.com/launch.
+	ShortLink string `json:"short_link"`
+}
+
+// Request represents a request to register a domain, inside a workspace when one is given.
+type Request struct {
+	Hostname    string `json:"hostname"`
+	WorkspaceID *uint  `json:"workspace_id"`
+}
+
+// VerifyRequest represents a request to verify a domain with one of the methods.
+type VerifyRequest struct {
+	Method string `json:"method"`
+}
@@ -0,0 +1,35 @@
+package export_model
+
+import (
+	"errors"
+)
+
+var ErrInvalidFormat = errors.New("export format must be csv, ndjson or parquet")
+var ErrInvalidRange = errors.New("from and to must be RFC 3339 times or YYYY-MM-DD dates, with from before to")
+var ErrRangeTooLarge = errors.New("time range is too large to export while waiting; use async")
+var ErrExportNotReady = errors.New("export is not ready")
+
+// Export formats.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// ContentTypes are the media types of the export formats.
+var ContentTypes = map[string]string{
+	FormatCSV:     "text/csv; charset=utf-8",
+	FormatNDJSON:  "application/x-ndjson",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// File is an export written by a background job.
+type File struct {
+	// Path is where the export is stored on the server.
+	Path     string `json:"-"`
+	Filename string `json:"filename"`
+	Format   string `json:"format"`
+	Size     int64  `json:"size"`
+	// DownloadURL serves the file to the user who started the job.
+	DownloadURL string `json:"download_url"`
+}
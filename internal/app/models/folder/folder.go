@@ -0,0 +1,29 @@
+package folder_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrFolderNotFound = errors.New("folder not found")
+var ErrFolderAlreadyExists = errors.New("folder already exists")
+var ErrInvalidName = errors.New("folder name must be 1 to 50 characters")
+
+// Folder groups personal links of a user; a link is in at most one folder.
+type Folder struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// URLCount is the number of links in the folder.
+	URLCount  int       `json:"url_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Request represents a request to create or rename a folder.
+type Request struct {
+	Name string `json:"name"`
+}
+
+// MoveRequest represents a request to move a link into a folder, or out of its folder when nil.
+type MoveRequest struct {
+	FolderID *uint `json:"folder_id"`
+}
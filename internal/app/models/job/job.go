@@ -0,0 +1,32 @@
+package job_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+// Statuses of a job.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Job represents a background task started by a user.
+type Job struct {
+	ID     string `json:"id"`
+	Kind   string `json:"kind"`
+	UserID uint   `json:"-"`
+	Status string `json:"status"`
+	// Total and Processed report the progress in items.
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	// Result is set once the job has completed.
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package oidc_model
+
+import "errors"
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+var ErrInvalidState = errors.New("invalid or expired login state")
+var ErrInvalidIDToken = errors.New("invalid ID token")
+var ErrProviderUnavailable = errors.New("identity provider unavailable")
+
+// Identity represents the verified claims of an ID token issued by an identity provider.
+type Identity struct {
+	Provider          string `json:"provider"`
+	Subject           string `json:"subject"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	PreferredUsername string `json:"preferred_username"`
+}
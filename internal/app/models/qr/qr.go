@@ -0,0 +1,33 @@
+package qr_model
+
+import "errors"
+
+var ErrInvalidFormat = errors.New("format must be png or svg")
+var ErrInvalidSize = errors.New("size must be between 64 and 2048 pixels and fit the code")
+var ErrInvalidMargin = errors.New("margin must be between 0 and 16 modules")
+var ErrInvalidLevel = errors.New("level must be L, M, Q or H")
+var ErrInvalidColor = errors.New("colors must be hex RRGGBB or RRGGBBAA and differ")
+var ErrLogoUnavailable = errors.New("no logo is configured")
+
+// Formats of the generated images.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Request represents the parameters of a QR code.
+type Request struct {
+	// Format is png or svg.
+	Format string `query:"format"`
+	// Size is the width and height of the image in pixels.
+	Size int `query:"size"`
+	// Margin is the quiet zone around the code, in modules.
+	Margin *int `query:"margin"`
+	// Level is the error correction level: L, M, Q or H.
+	Level string `query:"level"`
+	// Foreground and Background are hex colors such as 000000 or ffffff00.
+	Foreground string `query:"fg"`
+	Background string `query:"bg"`
+	// Logo overlays the configured logo at the center of the code.
+	Logo bool `query:"logo"`
+}
@@ -0,0 +1,45 @@
+package tag_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrTagNotFound = errors.New("tag not found")
+var ErrTagAlreadyExists = errors.New("tag already exists")
+var ErrNoTagChanges = errors.New("add or remove at least one tag")
+var ErrNoURLs = errors.New("urls must list at least one short code")
+
+// Tag labels personal links of a user; a link can have many tags.
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// URLCount is the number of links with the tag.
+	URLCount  int       `json:"url_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Stats aggregates the clicks of the links with a tag.
+type Stats struct {
+	TagID uint   `json:"tag_id"`
+	Name  string `json:"name"`
+	URLs  int    `json:"urls"`
+	// Clicks counts the recorded clicks and UniqueVisitors their distinct IP addresses.
+	Clicks         int `json:"clicks"`
+	UniqueVisitors int `json:"unique_visitors"`
+	// ImportedClicks is the click total carried over from other shorteners.
+	ImportedClicks int `json:"imported_clicks"`
+}
+
+// Request represents a request to create or rename a tag.
+type Request struct {
+	Name string `json:"name"`
+}
+
+// AssignRequest represents a request to add tags to and remove tags from many links at once.
+// Added tags are created when missing.
+type AssignRequest struct {
+	URLs   []string `json:"urls"`
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
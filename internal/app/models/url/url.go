@@ -2,6 +2,7 @@ package url_model
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -9,11 +10,483 @@ var ErrURLNotFound = errors.New("URL not found")
 var ErrShortCodeAlreadyExists = errors.New("short code already exists")
 var ErrInvalidToken = errors.New("invalid token")
 var ErrClickNotCreated = errors.New("click not created")
+var ErrURLDisabled = errors.New("URL has been disabled")
+var ErrURLExpired = errors.New("URL has expired")
+var ErrURLNotActive = errors.New("URL is not active yet")
+var ErrForbidden = errors.New("you do not have access to this URL")
+var ErrInvalidAlias = errors.New("alias must be 3 to 32 letters, digits, '-' or '_'")
+var ErrUnsafeURL = errors.New("destination URL is not allowed")
+var ErrInvalidLinkPassword = errors.New("link password must be 1 to 72 bytes")
+var ErrIncorrectLinkPassword = errors.New("incorrect link password")
+var ErrInvalidExpiry = errors.New("expiry must be a future RFC 3339 time or YYYY-MM-DD date")
+var ErrInvalidTag = errors.New("tags must be 1 to 50 characters without commas or semicolons")
+var ErrTooManyURLs = errors.New("too many URLs in one request")
+var ErrDuplicateAlias = errors.New("alias is used by another row")
+var ErrInvalidImportFormat = errors.New("import format must be csv or json")
+var ErrInvalidCreatedAt = errors.New("creation time must be an RFC 3339 time, a YYYY-MM-DD date or a Unix timestamp")
+var ErrInvalidClicks = errors.New("clicks must be a non-negative integer")
+var ErrInvalidTitle = errors.New("title must be at most 255 characters")
+var ErrInvalidNotes = errors.New("notes must be at most 2000 characters")
+var ErrMetadataNotFound = errors.New("no metadata has been fetched for this URL")
+var ErrMetadataDisabled = errors.New("metadata fetching is disabled")
+var ErrMetadataFetch = errors.New("failed to fetch the destination page")
+var ErrInvalidRule = errors.New("invalid redirect rule")
+var ErrTooManyRules = errors.New("a URL can have at most 20 redirect rules")
+var ErrInvalidSplit = errors.New("invalid split")
+var ErrInvalidUTM = errors.New("UTM values must be at most 100 characters without control characters")
+var ErrInvalidQueryConflict = errors.New("query conflict must be keep, replace or append")
+var ErrInvalidFinalURL = errors.New("destination with its query parameters is not a valid URL")
+var ErrInvalidDeepLink = errors.New("invalid deep link")
+var ErrInvalidPreview = errors.New("invalid preview")
+var ErrInvalidActivation = errors.New("invalid activation window")
+var ErrInvalidRedirectType = errors.New("redirect type must be 301, 302, 307 or 308")
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// Reasons a destination URL is rejected for.
+const (
+	ReasonInvalidURL     = "invalid_url"
+	ReasonScheme         = "scheme_not_allowed"
+	ReasonRedirectLoop   = "redirect_loop"
+	ReasonPrivateAddress = "private_address"
+	ReasonBlockedDomain  = "blocked_domain"
+	ReasonBlockedPattern = "blocked_pattern"
+)
+
+// Rejection explains why a destination URL was rejected. It wraps ErrUnsafeURL.
+type Rejection struct {
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+}
+
+func (r *Rejection) Error() string {
+	return ErrUnsafeURL.Error() + ": " + r.Detail
+}
+
+func (r *Rejection) Unwrap() error {
+	return ErrUnsafeURL
+}
 
 // URL represents a URL entity in the application.
 type URL struct {
-	OriginalURL  string    `json:"original_url"`
-	ShortenedURL string    `json:"shortened_url"`
-	UserID       uint      `json:"user_id"`
+	OriginalURL  string `json:"original_url"`
+	ShortenedURL string `json:"shortened_url"`
+	UserID       uint   `json:"user_id"`
+	// WorkspaceID is set for links shared within a workspace, nil for personal links.
+	WorkspaceID *uint `json:"workspace_id"`
+	Disabled    bool  `json:"disabled"`
+	// RedirectType is the HTTP status visitors are redirected with.
+	RedirectType int `json:"redirect_type"`
+	// ActiveFrom is when the link starts resolving, nil when it resolves from its creation.
+	ActiveFrom *time.Time `json:"active_from"`
+	// ExpiresAt is when the link stops resolving, nil when it never expires.
+	ExpiresAt *time.Time `json:"expires_at"`
+	// Status is StatusScheduled, StatusActive or StatusExpired, set when listing links.
+	Status string `json:"status,omitempty"`
+	// ImportedClicks is the click total carried over from another shortener.
+	ImportedClicks int `json:"imported_clicks"`
+	// FolderID is the folder of a personal link, nil when it is in none.
+	FolderID *uint `json:"folder_id"`
+	// Title and Notes describe the link; links without a title get the title of the destination page.
+	Title     string    `json:"title"`
+	Notes     string    `json:"notes"`
+	CreatedAt time.Time `json:"created_at"`
+	// Tags are the tags of the link, set when listing the links of a user.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Statuses of a link given its activation window.
+const (
+	StatusScheduled = "scheduled"
+	StatusActive    = "active"
+	StatusExpired   = "expired"
+)
+
+// StatusAt returns the status of the link at the given time.
+func (u *URL) StatusAt(t time.Time) string {
+	switch {
+	case u.ActiveFrom != nil && u.ActiveFrom.After(t):
+		return StatusScheduled
+	case u.ExpiresAt != nil && !u.ExpiresAt.After(t):
+		return StatusExpired
+	default:
+		return StatusActive
+	}
+}
+
+// What visitors of a link that is not active yet get.
+const (
+	// NotActiveNotFound answers as if the link did not exist.
+	NotActiveNotFound = "not_found"
+	// NotActiveRedirect sends visitors to a fallback URL.
+	NotActiveRedirect = "redirect"
+	// NotActivePage shows a page with a message and the time the link opens.
+	NotActivePage = "page"
+)
+
+// NotActiveResponse is what visitors of a link that is not active yet get.
+type NotActiveResponse struct {
+	// Mode is NotActiveNotFound, NotActiveRedirect or NotActivePage.
+	Mode string `json:"mode"`
+	// URL is the fallback of NotActiveRedirect.
+	URL string `json:"url"`
+	// Message is shown by NotActivePage.
+	Message string `json:"message"`
+}
+
+// Activation is the window in which a link resolves, and the response before it opens.
+type Activation struct {
+	ActiveFrom *time.Time `json:"active_from"`
+	// ActiveUntil is the expiry of the link.
+	ActiveUntil *time.Time        `json:"active_until"`
+	NotActive   NotActiveResponse `json:"not_active"`
+}
+
+// Filter selects personal links of a user by tag and folder; empty fields select all links.
+type Filter struct {
+	Tag      string
+	FolderID *uint
+}
+
+// ShortenRequest represents a request to shorten a URL.
+type ShortenRequest struct {
+	OriginalURL string `json:"original_url"`
+	// Alias is an optional custom short code.
+	Alias string `json:"alias"`
+	// WorkspaceID optionally creates the link inside a workspace.
+	WorkspaceID *uint `json:"workspace_id"`
+	// Domain optionally creates the link on a verified custom domain, using the alias as code on the domain.
+	// Links of workspace domains are created inside their workspace.
+	Domain string `json:"domain"`
+}
+
+// BulkItem represents one URL of a bulk creation request or CSV row.
+type BulkItem struct {
+	URL   string `json:"url"`
+	Alias string `json:"alias"`
+	// Tags label the link; CSV rows separate them with semicolons.
+	Tags []string `json:"tags"`
+	// Expiry is an RFC 3339 time or a YYYY-MM-DD date, empty when the link never expires.
+	Expiry string `json:"expiry"`
+}
+
+// BulkRequest represents a request to shorten many URLs at once.
+type BulkRequest struct {
+	URLs []BulkItem `json:"urls"`
+	// WorkspaceID optionally creates the links inside a workspace.
+	WorkspaceID *uint `json:"workspace_id"`
+	// Async processes the request as a background job.
+	Async bool `json:"async"`
+}
+
+// NewURL is a validated URL ready to be inserted by a bulk creation or an import.
+type NewURL struct {
+	OriginalURL string
+	ShortCode   string
+	UserID      uint
+	WorkspaceID *uint
+	ExpiresAt   *time.Time
+	Tags        []string
+	// CreatedAt keeps the creation time of imported links, nil for the current time.
+	CreatedAt *time.Time
+	// ImportedClicks is the click total of imported links.
+	ImportedClicks int
+	// ImportSource identifies an imported link in its source so imports can be re-run.
+	ImportSource string
+}
+
+// BulkResult is the outcome of one row of a bulk creation. Rows are numbered from 1.
+type BulkResult struct {
+	Row          int    `json:"row"`
+	URL          string `json:"url"`
+	ShortenedURL string `json:"shortened_url,omitempty"`
+	Error        string `json:"error,omitempty"`
+	// Reason is set for destinations rejected by the safety checks.
+	Reason string `json:"reason,omitempty"`
+}
+
+// BulkReport summarises a bulk creation.
+type BulkReport struct {
+	Total   int          `json:"total"`
+	Created int          `json:"created"`
+	Failed  int          `json:"failed"`
+	Results []BulkResult `json:"results"`
+}
+
+// Import statuses of a row.
+const (
+	ImportCreated = "created"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+// ImportRecord is a link read from the export of another shortener, with its fields as exported.
+type ImportRecord struct {
+	// ShortCode is the code of the link in the source, kept as alias when free.
+	ShortCode   string
+	OriginalURL string
+	// CreatedAt is an RFC 3339 time, a YYYY-MM-DD date with an optional time or a Unix timestamp.
+	CreatedAt string
+	Clicks    string
+	Tags      []string
+}
+
+// ImportResult is the outcome of one imported row. Rows are numbered from 1.
+type ImportResult struct {
+	Row          int    `json:"row"`
+	SourceCode   string `json:"source_code,omitempty"`
+	URL          string `json:"url"`
+	ShortenedURL string `json:"shortened_url,omitempty"`
+	Status       string `json:"status"`
+	// Conflict explains why the source code could not be kept.
+	Conflict string `json:"conflict,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// ImportReport summarises an import. A dry run reports what an import would do without creating anything.
+type ImportReport struct {
+	DryRun    bool           `json:"dry_run"`
+	Total     int            `json:"total"`
+	Created   int            `json:"created"`
+	Skipped   int            `json:"skipped"`
+	Failed    int            `json:"failed"`
+	Conflicts int            `json:"conflicts"`
+	Results   []ImportResult `json:"results"`
+}
+
+// Metadata is the page information fetched from the destination of a URL.
+type Metadata struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	FaviconURL  string    `json:"favicon_url"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
+
+// Preview is what chat tools and social networks show when a URL is shared, and the interstitial page
+// telling visitors where they are going.
+type Preview struct {
+	// Title, Description and Image are the OpenGraph and Twitter card tags served to link crawlers. The title
+	// of the URL and the description of its destination page are used when empty.
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	// Interstitial shows visitors the host they are leaving to before redirecting them.
+	Interstitial bool `json:"interstitial"`
+	// Delay is the number of seconds the interstitial waits before redirecting; 0 waits for the visitor.
+	Delay int `json:"delay"`
+}
+
+// DetailsRequest represents a request to set the title and notes of a URL; empty values clear them.
+type DetailsRequest struct {
+	Title string `json:"title"`
+	Notes string `json:"notes"`
+}
+
+// PasswordRequest represents a request to set the password of a URL. An empty password removes it.
+type PasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// TransferRequest represents a request to move a URL between personal and workspace scope.
+type TransferRequest struct {
+	// WorkspaceID is the target workspace, nil to make the link personal.
+	WorkspaceID *uint `json:"workspace_id"`
+}
+
+// Devices matched by redirect rules, detected from the User-Agent header.
+const (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceWindows = "windows"
+	DeviceMacOS   = "macos"
+	DeviceLinux   = "linux"
+	DeviceOther   = "other"
+)
+
+// Rule redirects the visitors matching all of its conditions to its destination. Conditions listing
+// several values match any of them, and unset conditions match every visitor.
+type Rule struct {
+	// Name describes the rule in dry runs.
+	Name        string         `json:"name,omitempty"`
+	Conditions  RuleConditions `json:"if"`
+	Destination string         `json:"destination"`
+}
+
+// RuleConditions are the conditions of a redirect rule.
+type RuleConditions struct {
+	Devices []string `json:"devices,omitempty"`
+	// Countries are ISO 3166-1 alpha-2 codes such as "TR".
+	Countries []string `json:"countries,omitempty"`
+	// Languages are language tags such as "de" or "pt-BR", matched against the preferred language
+	// of the visitor; "de" matches "de-AT" too.
+	Languages []string  `json:"languages,omitempty"`
+	Schedule  *Schedule `json:"schedule,omitempty"`
+}
+
+// Schedule matches visits between From and Until, as HH:MM times in Timezone, on the given days.
+// Windows ending before they start run past midnight.
+type Schedule struct {
+	// Days are "mon" to "sun"; empty matches every day.
+	Days  []string `json:"days,omitempty"`
+	From  string   `json:"from"`
+	Until string   `json:"until"`
+	// Timezone is an IANA time zone such as "Europe/Berlin", UTC when empty.
+	Timezone string `json:"timezone,omitempty"`
+	// Outside matches the visits outside the window instead, e.g. outside business hours.
+	Outside bool `json:"outside,omitempty"`
+}
+
+// RuleError explains why a redirect rule is invalid. It wraps ErrInvalidRule; rules are numbered from 1.
+type RuleError struct {
+	Rule   int    `json:"rule"`
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("%s: rule %d: %s: %s", ErrInvalidRule.Error(), e.Rule, e.Field, e.Detail)
+}
+
+func (e *RuleError) Unwrap() error {
+	return ErrInvalidRule
+}
+
+// RulesRequest represents a request to replace the redirect rules of a URL; no rules removes them.
+type RulesRequest struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Visitor is what redirect rules know about a visit.
+type Visitor struct {
+	Device   string    `json:"device"`
+	Country  string    `json:"country"`
+	Language string    `json:"language"`
+	Time     time.Time `json:"time"`
+	// IPAddress chooses the variant of splits sticking to IP addresses.
+	IPAddress string `json:"ip_address,omitempty"`
+}
+
+// DryRunRequest describes a visit to test the redirect rules of a URL with.
+type DryRunRequest struct {
+	UserAgent      string `json:"user_agent"`
+	AcceptLanguage string `json:"accept_language"`
+	Country        string `json:"country"`
+	// Time is an RFC 3339 time, the current time when empty.
+	Time      string `json:"time"`
+	IPAddress string `json:"ip_address"`
+	// Variant is the split variant remembered by the cookie of the visitor, if any.
+	Variant string `json:"variant"`
+	// Query is the query string of the short URL visited, passed through to links that pass their query.
+	Query string `json:"query"`
+}
+
+// How visitors keep the variant of a split they were first served.
+const (
+	// StickyCookie remembers the variant in a cookie, choosing new visitors at random by weight.
+	StickyCookie = "cookie"
+	// StickyIP chooses the variant from a hash of the IP address of the visitor.
+	StickyIP = "ip"
+)
+
+// Variant is one of the destinations of a split, served to a share of the visitors given by its weight.
+type Variant struct {
+	Name        string `json:"name"`
+	Destination string `json:"destination"`
+	Weight      int    `json:"weight"`
+}
+
+// Split distributes the visitors of a URL across weighted destinations, e.g. for A/B tests.
+type Split struct {
+	// Sticky is StickyCookie or StickyIP.
+	Sticky   string    `json:"sticky"`
+	Variants []Variant `json:"variants"`
+}
+
+// How the query parameters of visitors passed through to the destination treat parameters it already has.
+const (
+	// QueryKeep keeps the parameters of the destination and its UTM fields, adding the others.
+	QueryKeep = "keep"
+	// QueryReplace replaces the parameters of the destination and its UTM fields.
+	QueryReplace = "replace"
+	// QueryAppend adds the parameters next to those of the destination.
+	QueryAppend = "append"
+)
+
+// UTM are the campaign parameters added to the destination of a URL as utm_source, utm_medium and so on.
+// Empty fields are left out.
+type UTM struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium"`
+	Campaign string `json:"campaign"`
+	Term     string `json:"term"`
+	Content  string `json:"content"`
+}
+
+// Tracking sets the query parameters a URL adds to its destination when redirecting.
+type Tracking struct {
+	// UTM fields replace the UTM parameters the destination has.
+	UTM UTM `json:"utm"`
+	// PassQuery adds the query parameters of the short URL visited to the destination.
+	PassQuery bool `json:"pass_query"`
+	// QueryConflict is QueryKeep, QueryReplace or QueryAppend.
+	QueryConflict string `json:"query_conflict"`
+}
+
+// RuleMatch is the destination a visit is redirected to. Rule is the number of the matched rule,
+// counted from 1, and nil when no rule matches and the visit falls back to the split or the original URL.
+type RuleMatch struct {
+	Rule        *int   `json:"rule"`
+	Name        string `json:"name,omitempty"`
+	Destination string `json:"destination"`
+	// Variant is the variant of the split of the URL served when no rule matches.
+	Variant string `json:"variant,omitempty"`
+	// Cookie is set when the variant is remembered in a cookie.
+	Cookie  bool    `json:"-"`
+	Visitor Visitor `json:"visitor"`
+	// App is the app the visitor is sent to before the destination, nil when the URL has no deep link for its device.
+	App *AppTarget `json:"app,omitempty"`
+	// RedirectType is the HTTP status the visitor is redirected with.
+	RedirectType int `json:"redirect_type"`
+	// Revision is the latest revision of the URL, 0 when it was never changed.
+	Revision uint `json:"revision,omitempty"`
+}
+
+// AppTarget is where a deep link opens on one platform.
+type AppTarget struct {
+	// URI opens the app, such as "myapp://product/1" or an Android intent: URI.
+	URI string `json:"uri"`
+	// Fallback is opened when the app is not installed, usually its store page. The destination of the URL
+	// is used when empty.
+	Fallback string `json:"fallback"`
+}
+
+// DeepLink opens the iOS and Android apps of a URL when they are installed.
+type DeepLink struct {
+	IOS     AppTarget `json:"ios"`
+	Android AppTarget `json:"android"`
+}
+
+// DefaultRedirectType is the redirect type of new links.
+const DefaultRedirectType = 301
+
+// Revision is a version of the destination, redirect type and redirect rules of a URL, recorded each time
+// one of them changes. The first revision of a URL is how it was before its first recorded change.
+type Revision struct {
+	ID                  uint   `json:"id"`
+	Destination         string `json:"destination"`
+	PreviousDestination string `json:"previous_destination"`
+	RedirectType        int    `json:"redirect_type"`
+	Rules               []Rule `json:"rules"`
+	// ChangedBy is the user who made the change, nil for changes of anonymous links.
+	ChangedBy *uint `json:"changed_by"`
+	// RestoredFrom is the revision rolled back to, nil for other changes.
+	RestoredFrom *uint     `json:"restored_from,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 }
+
+// DestinationRequest represents a request to change the destination of a URL. A zero redirect type keeps
+// the current one.
+type DestinationRequest struct {
+	URL          string `json:"url"`
+	RedirectType int    `json:"redirect_type"`
+}
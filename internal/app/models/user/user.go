@@ -7,11 +7,58 @@ import (
 
 var ErrUserNotFound = errors.New("auth not found")
 var ErrUserAlreadyExists = errors.New("auth already exists")
+var ErrInvalidCredentials = errors.New("invalid username or password")
+var ErrPasswordPolicy = errors.New("password does not meet the policy")
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+var ErrEmailAlreadyExists = errors.New("email already exists")
+var ErrInvalidEmail = errors.New("invalid email address")
+var ErrEmailNotVerified = errors.New("email address is not verified")
+var ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+var ErrAccountDisabled = errors.New("account is disabled")
+var ErrInvalidRole = errors.New("role must be 1 to 50 lowercase letters, digits, '-' or '_'")
+var ErrSelfModeration = errors.New("admins cannot disable or demote themselves")
+
+// RoleUser is the role of every new account.
+const RoleUser = "user"
+
+// RoleAdmin grants access to the admin endpoints.
+const RoleAdmin = "admin"
 
 // User represents an auth entity in the application.
 type User struct {
-	ID        uint      `json:"id"`
-	Username  string    `json:"username"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Password      string    `json:"password"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"email_verified"`
+	Role          string    `json:"role"`
+	Disabled      bool      `json:"disabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// PasswordChange represents a request to change the password of the authenticated user.
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordResetRequest represents a request to send a password reset token.
+type PasswordResetRequest struct {
+	Username string `json:"username"`
+}
+
+// PasswordReset represents a request to set a new password with a reset token.
+type PasswordReset struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+// EmailChange represents a request to change the email address of the authenticated user.
+type EmailChange struct {
+	Email string `json:"email"`
+}
+
+// RoleChange represents a request to change the role of a user.
+type RoleChange struct {
+	Role string `json:"role"`
 }
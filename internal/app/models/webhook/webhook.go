@@ -0,0 +1,139 @@
+package webhook_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+var ErrInvalidEvent = errors.New("invalid webhook event type")
+var ErrDeliveryNotFound = errors.New("webhook delivery not found")
+var ErrDeliveryNotFailed = errors.New("only failed deliveries can be retried")
+var ErrInvalidStatus = errors.New("invalid delivery status")
+
+// Event types webhooks can subscribe to.
+const (
+	EventLinkCreated = "link.created"
+	EventLinkUpdated = "link.updated"
+	EventLinkDeleted = "link.deleted"
+	EventLinkClicked = "link.clicked"
+	// EventTest is only sent on demand, to the webhook under test.
+	EventTest = "webhook.test"
+)
+
+// Events lists the event types webhooks can subscribe to.
+var Events = []string{EventLinkCreated, EventLinkUpdated, EventLinkDeleted, EventLinkClicked}
+
+// Statuses of a delivery.
+const (
+	// StatusPending deliveries wait for their next attempt.
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	// StatusDead deliveries failed every attempt and are kept in the dead-letter list until retried.
+	StatusDead = "dead"
+	// StatusFailed test deliveries failed their single attempt.
+	StatusFailed = "failed"
+)
+
+// Statuses lists the statuses deliveries can be filtered by.
+var Statuses = []string{StatusPending, StatusDelivered, StatusDead, StatusFailed}
+
+// Headers of webhook requests.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature holds "sha256=" and the hex HMAC-SHA256 of the timestamp, a dot and the body,
+	// keyed with the secret of the webhook.
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Webhook is an endpoint notified of the events of the links of a user or workspace.
+type Webhook struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url"`
+	// UserID is the user who created the webhook; it receives the events of the personal links of the
+	// user unless WorkspaceID is set, in which case it receives those of the workspace links.
+	UserID      uint  `json:"user_id"`
+	WorkspaceID *uint `json:"workspace_id,omitempty"`
+	// Events are the event types sent to the webhook, every type when empty.
+	Events []string `json:"events"`
+	// Secret signs the deliveries of the webhook, only returned when the webhook is created.
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribes reports whether the webhook receives events of the type.
+func (w *Webhook) Subscribes(eventType string) bool {
+	if !w.Active {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, event := range w.Events {
+		if event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Request represents a request to create or update a webhook, inside a workspace when one is given.
+// Active defaults to true on creation and is left unchanged on updates when omitted.
+type Request struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	WorkspaceID *uint    `json:"workspace_id"`
+	Active      *bool    `json:"active"`
+}
+
+// Event is the body of webhook requests.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Click is the data of link.clicked events.
+type Click struct {
+	ShortenedURL string `json:"shortened_url"`
+	// Destination is where the visitor was sent, after redirect rules, splits and tracking parameters.
+	Destination string    `json:"destination"`
+	Variant     string    `json:"variant,omitempty"`
+	Country     string    `json:"country,omitempty"`
+	Device      string    `json:"device,omitempty"`
+	Referrer    string    `json:"referrer,omitempty"`
+	ClickedAt   time.Time `json:"clicked_at"`
+}
+
+// Delivery is an event queued for a webhook, with the outcome of its attempts.
+type Delivery struct {
+	ID        uint   `json:"id"`
+	WebhookID uint   `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	// Payload is the JSON event sent as body.
+	Payload  string `json:"payload"`
+	Status   string `json:"status"`
+	Attempts int    `json:"attempts"`
+	// NextAttemptAt is when a pending delivery is attempted again.
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// ResponseStatus and Error describe the last attempt.
+	ResponseStatus int       `json:"response_status,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Log lists the attempts of the delivery, set when getting a single delivery.
+	Log []Attempt `json:"log,omitempty"`
+}
+
+// Attempt records one request of a delivery.
+type Attempt struct {
+	ResponseStatus int    `json:"response_status,omitempty"`
+	Error          string `json:"error,omitempty"`
+	// Duration is how long the request took, in milliseconds.
+	Duration    int64     `json:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at"`
+}
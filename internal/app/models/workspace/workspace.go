@@ -0,0 +1,72 @@
+package workspace_model
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+var ErrNotMember = errors.New("not a member of the workspace")
+var ErrMemberAlreadyExists = errors.New("user is already a member of the workspace")
+var ErrInsufficientRole = errors.New("workspace role does not allow this action")
+var ErrInvalidRole = errors.New("workspace role must be owner, editor or viewer")
+var ErrInvalidName = errors.New("workspace name must be 1 to 100 characters")
+var ErrLastOwner = errors.New("a workspace must keep at least one owner")
+
+// Workspace member roles, from most to least privileged.
+const (
+	// RoleOwner manages members and everything an editor can do.
+	RoleOwner = "owner"
+	// RoleEditor creates, transfers and disables links of the workspace.
+	RoleEditor = "editor"
+	// RoleViewer sees the links of the workspace and their analytics.
+	RoleViewer = "viewer"
+)
+
+// roleRanks orders the roles so that a higher role includes the lower ones.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// ValidRole reports whether role is a workspace role.
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// HasRole reports whether role grants at least the required role.
+func HasRole(role, required string) bool {
+	return ValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
+
+// Workspace represents a workspace whose members share links.
+type Workspace struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	CreatedBy uint   `json:"created_by"`
+	// Role is the role of the requesting user, set when listing their workspaces.
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Member represents the membership of a user in a workspace.
+type Member struct {
+	WorkspaceID uint      `json:"workspace_id"`
+	UserID      uint      `json:"user_id"`
+	Username    string    `json:"username"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// CreateRequest represents a request to create a workspace.
+type CreateRequest struct {
+	Name string `json:"name"`
+}
+
+// MemberRequest represents a request to add a member or change their role.
+type MemberRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
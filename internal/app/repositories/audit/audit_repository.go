@@ -0,0 +1,51 @@
+package audit_repository
+
+import (
+	"database/sql"
+	"url-shortener/internal/app/models/audit"
+)
+
+// Repository defines methods to interact with the audit repository.
+type Repository interface {
+	Create(entry *audit_model.Entry) error
+}
+
+// DBAuditRepository is an implementation of AuditRepository for MySQL database.
+type DBAuditRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBAuditRepository creates a new instance of DBAuditRepository.
+func NewDBAuditRepository(db *sql.DB) *DBAuditRepository {
+	return &DBAuditRepository{DB: db}
+}
+
+// Create inserts a new audit record into the database.
+func (r *DBAuditRepository) Create(entry *audit_model.Entry) error {
+	// Prepare SQL statement
+	query := "INSERT INTO audit_logs (action, actor_id, target, ip_address, details) VALUES (?, ?, ?, ?, ?)"
+	stmt, err := r.DB.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing the prepared statement
+	defer stmt.Close()
+
+	// Execute SQL statement
+	result, err := stmt.Exec(entry.Action, entry.ActorID, entry.Target, entry.IPAddress, entry.Details)
+	if err != nil {
+		return err
+	}
+
+	// Retrieve the ID of the newly inserted entry
+	entryID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	entry.ID = uint(entryID)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package audit_repository
+
+import (
+	"errors"
+	"testing"
+	"url-shortener/internal/app/models/audit"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBAuditRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuditRepository(db)
+	entry := &audit_model.Entry{
+		Action:    audit_model.ActionLoginLockout,
+		Target:    "username:testuser",
+		IPAddress: "127.0.0.1",
+		Details:   "locked",
+	}
+
+	t.Run("Create Entry Successfully", func(t *testing.T) {
+		mock.ExpectPrepare("INSERT INTO audit_logs").
+			ExpectExec().
+			WithArgs(entry.Action, entry.ActorID, entry.Target, entry.IPAddress, entry.Details).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Create(entry)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), entry.ID)
+	})
+
+	t.Run("Failed to Prepare SQL Statement", func(t *testing.T) {
+		mock.ExpectPrepare("INSERT INTO audit_logs").
+			WillReturnError(errors.New("prepare error"))
+
+		err := repo.Create(entry)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectPrepare("INSERT INTO audit_logs").
+			ExpectExec().
+			WithArgs(entry.Action, entry.ActorID, entry.Target, entry.IPAddress, entry.Details).
+			WillReturnError(errors.New("execute error"))
+
+		err := repo.Create(entry)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Failed to Retrieve Last Insert ID", func(t *testing.T) {
+		mock.ExpectPrepare("INSERT INTO audit_logs").
+			ExpectExec().
+			WithArgs(entry.Action, entry.ActorID, entry.Target, entry.IPAddress, entry.Details).
+			WillReturnResult(sqlmock.NewErrorResult(errors.New("last insert id error")))
+
+		err := repo.Create(entry)
+
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,8 @@ package auth_repository
 import (
 	"database/sql"
 	"errors"
+	"strings"
+	"time"
 	"url-shortener/internal/app/models/user"
 )
 
@@ -10,6 +12,18 @@ import (
 type Repository interface {
 	Create(user *user_model.User) (*user_model.User, error)
 	GetByUsername(username string) (*user_model.User, error)
+	GetByID(id uint) (*user_model.User, error)
+	GetByEmail(email string) (*user_model.User, error)
+	UpdateEmail(id uint, email string) error
+	SetEmailVerified(id uint, email string) error
+	UpdatePassword(id uint, password string) error
+	CreateResetToken(userID uint, tokenHash string, expiresAt time.Time) error
+	ConsumeResetToken(tokenHash string) (uint, error)
+	GetByIdentity(provider, subject string) (*user_model.User, error)
+	CreateIdentity(userID uint, provider, subject string) error
+	ListUsers(search string, limit, offset int) ([]user_model.User, error)
+	UpdateRole(id uint, role string) error
+	SetDisabled(id uint, disabled bool) error
 }
 
 // DBAuthRepository is an implementation of UserRepository for MySQL database.
@@ -26,7 +40,7 @@ func NewDBAuthRepository(db *sql.DB) *DBAuthRepository {
 // Create inserts a new auth record into the database.
 func (r *DBAuthRepository) Create(user *user_model.User) (*user_model.User, error) {
 	// Prepare SQL statement
-	query := "INSERT INTO users (username, password) VALUES (?, ?)"
+	query := "INSERT INTO users (username, password, email, role) VALUES (?, ?, ?, ?)"
 	stmt, err := r.DB.Prepare(query)
 	if err != nil {
 		return nil, err
@@ -36,7 +50,10 @@ func (r *DBAuthRepository) Create(user *user_model.User) (*user_model.User, erro
 	defer stmt.Close()
 
 	// Execute SQL statement
-	result, err := stmt.Exec(user.Username, user.Password)
+	if user.Role == "" {
+		user.Role = user_model.RoleUser
+	}
+	result, err := stmt.Exec(user.Username, user.Password, nullString(user.Email), user.Role)
 	if err != nil {
 		return nil, err
 	}
@@ -56,21 +73,214 @@ func (r *DBAuthRepository) Create(user *user_model.User) (*user_model.User, erro
 // GetByUsername retrieves an auth record from the database by username.
 func (r *DBAuthRepository) GetByUsername(username string) (*user_model.User, error) {
 	// Prepare SQL statement
-	query := "SELECT id, username, password, created_at FROM users WHERE username = ?"
-	row := r.DB.QueryRow(query, username)
+	query := "SELECT " + userColumns + " FROM users WHERE username = ?"
+	return scanUser(r.DB.QueryRow(query, username))
+}
+
+// GetByID retrieves an auth record from the database by ID.
+func (r *DBAuthRepository) GetByID(id uint) (*user_model.User, error) {
+	// Prepare SQL statement
+	query := "SELECT " + userColumns + " FROM users WHERE id = ?"
+	return scanUser(r.DB.QueryRow(query, id))
+}
+
+// GetByEmail retrieves an auth record from the database by email address.
+func (r *DBAuthRepository) GetByEmail(email string) (*user_model.User, error) {
+	// Prepare SQL statement
+	query := "SELECT " + userColumns + " FROM users WHERE email = ?"
+	return scanUser(r.DB.QueryRow(query, email))
+}
+
+// UpdateEmail replaces the email address of the given auth and marks it unverified.
+func (r *DBAuthRepository) UpdateEmail(id uint, email string) error {
+	result, err := r.DB.Exec("UPDATE users SET email = ?, email_verified = FALSE WHERE id = ?", nullString(email), id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the auth exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return user_model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SetEmailVerified marks the email address of the given auth as verified.
+// The address must still match so that a link for a replaced address has no effect.
+func (r *DBAuthRepository) SetEmailVerified(id uint, email string) error {
+	result, err := r.DB.Exec("UPDATE users SET email_verified = TRUE WHERE id = ? AND email = ?", id, email)
+	if err != nil {
+		return err
+	}
+
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return user_model.ErrInvalidVerificationToken
+	}
+
+	return nil
+}
+
+// UpdatePassword replaces the hashed password of the given auth.
+func (r *DBAuthRepository) UpdatePassword(id uint, password string) error {
+	result, err := r.DB.Exec("UPDATE users SET password = ? WHERE id = ?", password, id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the auth exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return user_model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// CreateResetToken stores the hash of a password reset token for the given auth.
+func (r *DBAuthRepository) CreateResetToken(userID uint, tokenHash string, expiresAt time.Time) error {
+	_, err := r.DB.Exec("INSERT INTO password_reset_tokens (token_hash, user_id, expires_at) VALUES (?, ?, ?)", tokenHash, userID, expiresAt)
+	return err
+}
+
+// ConsumeResetToken marks an unused, unexpired reset token as used and returns its auth ID.
+// A token can only be consumed once.
+func (r *DBAuthRepository) ConsumeResetToken(tokenHash string) (uint, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// Lock the token row so concurrent resets cannot both consume it
+	var userID uint
+	query := "SELECT user_id FROM password_reset_tokens WHERE token_hash = ? AND used_at IS NULL AND expires_at > ? FOR UPDATE"
+	err = tx.QueryRow(query, tokenHash, time.Now()).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, user_model.ErrInvalidResetToken
+		}
+		return 0, err
+	}
+
+	if _, err := tx.Exec("UPDATE password_reset_tokens SET used_at = ? WHERE token_hash = ?", time.Now(), tokenHash); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// GetByIdentity retrieves the auth linked to the given identity provider subject.
+func (r *DBAuthRepository) GetByIdentity(provider, subject string) (*user_model.User, error) {
+	// Prepare SQL statement
+	query := "SELECT " + userColumns + " FROM users WHERE id = (SELECT user_id FROM user_identities WHERE provider = ? AND subject = ?)"
+	return scanUser(r.DB.QueryRow(query, provider, subject))
+}
+
+// CreateIdentity links an identity provider subject to the given auth.
+func (r *DBAuthRepository) CreateIdentity(userID uint, provider, subject string) error {
+	_, err := r.DB.Exec("INSERT INTO user_identities (provider, subject, user_id) VALUES (?, ?, ?)", provider, subject, userID)
+	return err
+}
+
+// ListUsers retrieves users whose username or email address contains the search term, ordered by ID.
+func (r *DBAuthRepository) ListUsers(search string, limit, offset int) ([]user_model.User, error) {
+	// Escape LIKE wildcards so the search term is matched literally
+	pattern := "%" + strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(search) + "%"
+
+	query := "SELECT " + userColumns + " FROM users WHERE username LIKE ? OR email LIKE ? ORDER BY id LIMIT ? OFFSET ?"
+	rows, err := r.DB.Query(query, pattern, pattern, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Initialize a slice to store the result
+	users := make([]user_model.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, rows.Err()
+}
 
+// UpdateRole replaces the role of the given auth.
+func (r *DBAuthRepository) UpdateRole(id uint, role string) error {
+	return r.updateUser("UPDATE users SET role = ? WHERE id = ?", role, id)
+}
+
+// SetDisabled disables or re-enables the given auth.
+func (r *DBAuthRepository) SetDisabled(id uint, disabled bool) error {
+	return r.updateUser("UPDATE users SET disabled = ? WHERE id = ?", disabled, id)
+}
+
+// updateUser executes an update of a single auth, returning ErrUserNotFound when no row matched.
+func (r *DBAuthRepository) updateUser(query string, args ...interface{}) error {
+	result, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the auth exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return user_model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// userColumns lists the columns scanned by scanUser.
+const userColumns = "id, username, password, email, email_verified, role, disabled, created_at"
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a single users row selected with userColumns.
+func scanUser(row rowScanner) (*user_model.User, error) {
 	// Initialize a new User object to store the result
 	user := &user_model.User{}
+	var email sql.NullString
 
 	// Scan the result into the User object
-	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.CreatedAt)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &email, &user.EmailVerified, &user.Role, &user.Disabled, &user.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			// Return a custom error if the auth with the specified username is not found
+			// Return a custom error if the auth is not found
 			return nil, user_model.ErrUserNotFound
 		}
 		return nil, err
 	}
+	user.Email = email.String
 
 	return user, nil
 }
+
+// nullString stores empty strings as NULL so that unique indexes ignore them.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
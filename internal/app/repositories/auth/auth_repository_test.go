@@ -27,7 +27,7 @@ func TestMySQLUserRepository_Create(t *testing.T) {
 	t.Run("Create User Successfully", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO users").
 			ExpectExec().
-			WithArgs(user.Username, user.Password).
+			WithArgs(user.Username, user.Password, sql.NullString{}, user_model.RoleUser).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		createdUser, err := repo.Create(user)
@@ -50,7 +50,7 @@ func TestMySQLUserRepository_Create(t *testing.T) {
 	t.Run("Failed on sql statement", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO users").
 			ExpectExec().
-			WithArgs(user.Username, user.Password).
+			WithArgs(user.Username, user.Password, sql.NullString{}, user_model.RoleUser).
 			WillReturnError(errors.New("execute error"))
 
 		createdUser, err := repo.Create(user)
@@ -63,7 +63,7 @@ func TestMySQLUserRepository_Create(t *testing.T) {
 	t.Run("Failed to close prepared statement", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO users").
 			ExpectExec().
-			WithArgs(user.Username, user.Password).
+			WithArgs(user.Username, user.Password, sql.NullString{}, user_model.RoleUser).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		createdUser, err := repo.Create(user)
@@ -77,7 +77,7 @@ func TestMySQLUserRepository_Create(t *testing.T) {
 	t.Run("Failed to Execute SQL Statement", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO users").
 			ExpectExec().
-			WithArgs(user.Username, user.Password).
+			WithArgs(user.Username, user.Password, sql.NullString{}, user_model.RoleUser).
 			WillReturnError(errors.New("execute error"))
 
 		createdUser, err := repo.Create(user)
@@ -89,7 +89,7 @@ func TestMySQLUserRepository_Create(t *testing.T) {
 	t.Run("Failed to Retrieve Last Inserted ID", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO users").
 			ExpectExec().
-			WithArgs(user.Username, user.Password).
+			WithArgs(user.Username, user.Password, sql.NullString{}, user_model.RoleUser).
 			WillReturnResult(sqlmock.NewErrorResult(errors.New("last insert ID error")))
 
 		createdUser, err := repo.Create(user)
@@ -116,13 +116,14 @@ func TestDBUserRepositoryGetByUsername(t *testing.T) {
 			ID:        1,
 			Username:  username,
 			Password:  "password123",
+			Role:      user_model.RoleUser,
 			CreatedAt: time.Now(),
 		}
 
-		mock.ExpectQuery("SELECT id, username, password, created_at FROM users").
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users").
 			WithArgs(username).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "created_at"}).
-				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Password, expectedUser.CreatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}).
+				AddRow(expectedUser.ID, expectedUser.Username, expectedUser.Password, nil, false, expectedUser.Role, false, expectedUser.CreatedAt))
 
 		user, err := repo.GetByUsername(username)
 
@@ -133,7 +134,7 @@ func TestDBUserRepositoryGetByUsername(t *testing.T) {
 	})
 
 	t.Run("Failed to Get User (No Rows Returned)", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, username, password, created_at FROM users").
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users").
 			WithArgs(username).
 			WillReturnError(sql.ErrNoRows)
 
@@ -146,10 +147,10 @@ func TestDBUserRepositoryGetByUsername(t *testing.T) {
 	})
 
 	t.Run("Failed to Get User (Scan Error)", func(t *testing.T) {
-		mock.ExpectQuery("SELECT id, username, password, created_at FROM users").
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users").
 			WithArgs(username).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "created_at"}).
-				AddRow(nil, nil, nil, nil))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}).
+				AddRow(nil, nil, nil, nil, nil, nil, nil, nil))
 
 		user, err := repo.GetByUsername(username)
 		assert.Error(t, err)
@@ -158,3 +159,422 @@ func TestDBUserRepositoryGetByUsername(t *testing.T) {
 	})
 
 }
+
+func TestDBAuthRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Get User Successfully", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE id = ?").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}).
+				AddRow(1, "testuser", "hash", "user@example.com", true, "user", false, now))
+
+		user, err := repo.GetByID(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &user_model.User{ID: 1, Username: "testuser", Password: "hash", Email: "user@example.com", EmailVerified: true, Role: user_model.RoleUser, CreatedAt: now}, user)
+	})
+
+	t.Run("Failed to Get User (No Rows Returned)", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE id = ?").
+			WithArgs(2).
+			WillReturnError(sql.ErrNoRows)
+
+		user, err := repo.GetByID(2)
+
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+		assert.Nil(t, user)
+	})
+
+	t.Run("Failed to Get User (Query Error)", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE id = ?").
+			WithArgs(3).
+			WillReturnError(errors.New("query error"))
+
+		user, err := repo.GetByID(3)
+
+		assert.Error(t, err)
+		assert.Nil(t, user)
+	})
+}
+
+func TestDBAuthRepository_UpdatePassword(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Update Password Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET password = ?").
+			WithArgs("hash", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdatePassword(1, "hash"))
+	})
+
+	t.Run("Failed to Update Missing User", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET password = ?").
+			WithArgs("hash", 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpdatePassword(2, "hash"), user_model.ErrUserNotFound)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET password = ?").
+			WithArgs("hash", 3).
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.UpdatePassword(3, "hash"))
+	})
+}
+
+func TestDBAuthRepository_CreateResetToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	t.Run("Create Reset Token Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO password_reset_tokens").
+			WithArgs("hash", 1, expiresAt).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		assert.NoError(t, repo.CreateResetToken(1, "hash", expiresAt))
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO password_reset_tokens").
+			WithArgs("hash", 1, expiresAt).
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.CreateResetToken(1, "hash", expiresAt))
+	})
+}
+
+func TestDBAuthRepository_ConsumeResetToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Consume Reset Token Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id FROM password_reset_tokens").
+			WithArgs("hash", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+		mock.ExpectExec("UPDATE password_reset_tokens SET used_at").
+			WithArgs(sqlmock.AnyArg(), "hash").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		userID, err := repo.ConsumeResetToken("hash")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), userID)
+	})
+
+	t.Run("Failed for Used or Expired Token", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id FROM password_reset_tokens").
+			WithArgs("hash", sqlmock.AnyArg()).
+			WillReturnError(sql.ErrNoRows)
+		mock.ExpectRollback()
+
+		_, err := repo.ConsumeResetToken("hash")
+
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+
+	t.Run("Failed to Mark Token Used", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT user_id FROM password_reset_tokens").
+			WithArgs("hash", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+		mock.ExpectExec("UPDATE password_reset_tokens SET used_at").
+			WithArgs(sqlmock.AnyArg(), "hash").
+			WillReturnError(errors.New("execute error"))
+		mock.ExpectRollback()
+
+		_, err := repo.ConsumeResetToken("hash")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Failed to Begin Transaction", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(errors.New("begin error"))
+
+		_, err := repo.ConsumeResetToken("hash")
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBAuthRepository_GetByEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Get User Successfully", func(t *testing.T) {
+		now := time.Now()
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE email = ?").
+			WithArgs("user@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}).
+				AddRow(1, "testuser", "hash", "user@example.com", false, "user", false, now))
+
+		user, err := repo.GetByEmail("user@example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user@example.com", user.Email)
+		assert.False(t, user.EmailVerified)
+	})
+
+	t.Run("Failed to Get User (No Rows Returned)", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE email = ?").
+			WithArgs("missing@example.com").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByEmail("missing@example.com")
+
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestDBAuthRepository_UpdateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Update Email Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified = FALSE").
+			WithArgs(sql.NullString{String: "user@example.com", Valid: true}, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdateEmail(1, "user@example.com"))
+	})
+
+	t.Run("Failed to Update Missing User", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified = FALSE").
+			WithArgs(sql.NullString{String: "user@example.com", Valid: true}, 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpdateEmail(2, "user@example.com"), user_model.ErrUserNotFound)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email = \\?, email_verified = FALSE").
+			WithArgs(sql.NullString{String: "user@example.com", Valid: true}, 3).
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.UpdateEmail(3, "user@example.com"))
+	})
+}
+
+func TestDBAuthRepository_SetEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Verify Email Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email_verified = TRUE").
+			WithArgs(1, "user@example.com").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetEmailVerified(1, "user@example.com"))
+	})
+
+	t.Run("Failed for Replaced Email", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email_verified = TRUE").
+			WithArgs(1, "old@example.com").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetEmailVerified(1, "old@example.com"), user_model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET email_verified = TRUE").
+			WithArgs(1, "user@example.com").
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.SetEmailVerified(1, "user@example.com"))
+	})
+}
+
+func TestDBAuthRepository_Identities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Get User by Identity", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}).
+			AddRow(1, "testuser", "password123", "user@example.com", true, "admin", false, time.Now())
+		mock.ExpectQuery("SELECT id, username, password, email, email_verified, role, disabled, created_at FROM users WHERE id = \\(SELECT user_id FROM user_identities").
+			WithArgs("example", "subject-1").
+			WillReturnRows(rows)
+
+		user, err := repo.GetByIdentity("example", "subject-1")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), user.ID)
+	})
+
+	t.Run("Failed to Get Unlinked Identity", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE id = \\(SELECT user_id FROM user_identities").
+			WithArgs("example", "missing").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetByIdentity("example", "missing")
+
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+
+	t.Run("Create Identity Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO user_identities").
+			WithArgs("example", "subject-1", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.CreateIdentity(1, "example", "subject-1"))
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO user_identities").
+			WithArgs("example", "subject-1", 1).
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.CreateIdentity(1, "example", "subject-1"))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBAuthRepository_ListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+	columns := []string{"id", "username", "password", "email", "email_verified", "role", "disabled", "created_at"}
+
+	t.Run("List Users Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE username LIKE \\? OR email LIKE \\? ORDER BY id LIMIT \\? OFFSET \\?").
+			WithArgs("%test\\_%", "%test\\_%", 10, 0).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "test_one", "hash", nil, false, "user", false, time.Now()).
+				AddRow(2, "test_two", "hash", "two@example.com", true, "admin", true, time.Now()))
+
+		users, err := repo.ListUsers("test_", 10, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, "admin", users[1].Role)
+		assert.True(t, users[1].Disabled)
+	})
+
+	t.Run("Return Empty List", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE username LIKE").
+			WithArgs("%%", "%%", 10, 10).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		users, err := repo.ListUsers("", 10, 10)
+
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE username LIKE").
+			WillReturnError(errors.New("query error"))
+
+		_, err := repo.ListUsers("", 10, 0)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Failed to Scan Row", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM users WHERE username LIKE").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(nil, nil, nil, nil, nil, nil, nil, nil))
+
+		_, err := repo.ListUsers("", 10, 0)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBAuthRepository_UpdateRoleAndDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBAuthRepository(db)
+
+	t.Run("Update Role Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET role = \\? WHERE id = \\?").
+			WithArgs("admin", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdateRole(1, "admin"))
+	})
+
+	t.Run("Failed to Update Missing User", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET role = \\? WHERE id = \\?").
+			WithArgs("admin", 2).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.UpdateRole(2, "admin"), user_model.ErrUserNotFound)
+	})
+
+	t.Run("Disable User Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET disabled = \\? WHERE id = \\?").
+			WithArgs(true, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetDisabled(1, true))
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE users SET disabled = \\? WHERE id = \\?").
+			WithArgs(false, 1).
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.SetDisabled(1, false))
+	})
+}
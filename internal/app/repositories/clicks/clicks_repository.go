@@ -2,13 +2,17 @@ package clicks_repository
 
 import (
 	"database/sql"
+	"strings"
 	"url-shortener/internal/app/models/clicks"
 )
 
 // Repository defines methods to interact with the URL repository.
 type Repository interface {
-	CreateClick(shortURL, ipAddress string) error
+	CreateClick(shortURL, ipAddress, variant string, revision uint) error
 	GetClicks(shortURL string) ([]clicks_model.Clicks, error)
+	CountVariantClicks(shortURL string) (map[string]int, error)
+	CountRevisionClicks(shortURL string) (map[uint]int, error)
+	IterateClicks(filter clicks_model.Filter, fn func(*clicks_model.Clicks) error) error
 }
 
 // DBClicksRepository is an implementation of ClicksRepository for MySQL database.
@@ -22,10 +26,11 @@ func NewDBClicksRepository(db *sql.DB) *DBClicksRepository {
 	return &DBClicksRepository{DB: db}
 }
 
-// CreateClick inserts a new click record into the database.
-func (r *DBClicksRepository) CreateClick(shortURL, ipAddress string) error {
+// CreateClick inserts a new click record into the database, with the split variant it was served and the
+// revision of the link, if any.
+func (r *DBClicksRepository) CreateClick(shortURL, ipAddress, variant string, revision uint) error {
 	// Prepare SQL statement
-	stmt, err := r.DB.Prepare("INSERT INTO clicks (url_id, ip_address) VALUES (?, ?)")
+	stmt, err := r.DB.Prepare("INSERT INTO clicks (url_id, ip_address, variant, revision_id) VALUES (?, ?, NULLIF(?, ''), NULLIF(?, 0))")
 	if err != nil {
 		return err
 	}
@@ -34,7 +39,7 @@ func (r *DBClicksRepository) CreateClick(shortURL, ipAddress string) error {
 	defer stmt.Close()
 
 	// Execute SQL statement
-	_, err = stmt.Exec(shortURL, ipAddress)
+	_, err = stmt.Exec(shortURL, ipAddress, variant, revision)
 	if err != nil {
 		return err
 	}
@@ -45,7 +50,7 @@ func (r *DBClicksRepository) CreateClick(shortURL, ipAddress string) error {
 // GetClicks retrieves the clicks for the given shortened URL.
 func (r *DBClicksRepository) GetClicks(shortURL string) ([]clicks_model.Clicks, error) {
 	// Prepare SQL statement
-	stmt, err := r.DB.Prepare("SELECT * FROM clicks WHERE url_id = ?")
+	stmt, err := r.DB.Prepare("SELECT id, url_id, ip_address, created_at, variant, revision_id FROM clicks WHERE url_id = ?")
 	if err != nil {
 		return nil, err
 	}
@@ -64,12 +69,108 @@ func (r *DBClicksRepository) GetClicks(shortURL string) ([]clicks_model.Clicks,
 	var clicks = make([]clicks_model.Clicks, 0)
 	for rows.Next() {
 		var clicks_m clicks_model.Clicks
-		err := rows.Scan(&clicks_m.ID, &clicks_m.UrlID, &clicks_m.IPAddress, &clicks_m.CreatedAt)
+		var variant sql.NullString
+		var revision sql.NullInt64
+		err := rows.Scan(&clicks_m.ID, &clicks_m.UrlID, &clicks_m.IPAddress, &clicks_m.CreatedAt, &variant, &revision)
 		if err != nil {
 			return nil, err
 		}
+		clicks_m.Variant = variant.String
+		clicks_m.Revision = uint(revision.Int64)
 		clicks = append(clicks, clicks_m)
 	}
 
 	return clicks, nil
 }
+
+// IterateClicks calls fn with each click matching the filter in insertion order, reading one row
+// at a time so that the clicks are never all held in memory. It stops at the first error returned by fn.
+func (r *DBClicksRepository) IterateClicks(filter clicks_model.Filter, fn func(*clicks_model.Clicks) error) error {
+	query := "SELECT c.id, c.url_id, c.ip_address, c.created_at, c.variant, c.revision_id FROM clicks c"
+	var conditions []string
+	var args []interface{}
+	if filter.ShortURL != "" {
+		conditions = append(conditions, "c.url_id = ?")
+		args = append(args, filter.ShortURL)
+	} else {
+		// Clicks of the personal links of the user
+		query += " JOIN urls u ON u.shortened_url = c.url_id"
+		conditions = append(conditions, "u.user_id = ?", "u.workspace_id IS NULL")
+		args = append(args, filter.UserID)
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "c.created_at >= ?")
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "c.created_at < ?")
+		args = append(args, *filter.To)
+	}
+	query += " WHERE " + strings.Join(conditions, " AND ") + " ORDER BY c.id"
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var click clicks_model.Clicks
+		var variant sql.NullString
+		var revision sql.NullInt64
+		if err := rows.Scan(&click.ID, &click.UrlID, &click.IPAddress, &click.CreatedAt, &variant, &revision); err != nil {
+			return err
+		}
+		click.Variant = variant.String
+		click.Revision = uint(revision.Int64)
+		if err := fn(&click); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// CountVariantClicks counts the clicks of the given shortened URL by the split variant they were served.
+// Clicks served without a variant are not counted.
+func (r *DBClicksRepository) CountVariantClicks(shortURL string) (map[string]int, error) {
+	rows, err := r.DB.Query("SELECT variant, COUNT(*) FROM clicks WHERE url_id = ? AND variant IS NOT NULL GROUP BY variant", shortURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var variant string
+		var count int
+		if err := rows.Scan(&variant, &count); err != nil {
+			return nil, err
+		}
+		counts[variant] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// CountRevisionClicks counts the clicks of the given shortened URL by the revision of the link they were
+// served. Clicks made before the link was first changed are counted under revision 0.
+func (r *DBClicksRepository) CountRevisionClicks(shortURL string) (map[uint]int, error) {
+	rows, err := r.DB.Query("SELECT COALESCE(revision_id, 0), COUNT(*) FROM clicks WHERE url_id = ? GROUP BY revision_id", shortURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[uint]int)
+	for rows.Next() {
+		var revision uint
+		var count int
+		if err := rows.Scan(&revision, &count); err != nil {
+			return nil, err
+		}
+		counts[revision] = count
+	}
+
+	return counts, rows.Err()
+}
@@ -24,10 +24,10 @@ func TestCreateClick(t *testing.T) {
 	t.Run("Create Click Successfully", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO clicks").
 			ExpectExec().
-			WithArgs(shortURL, ipAddress).
+			WithArgs(shortURL, ipAddress, "", 0).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
-		err := repo.CreateClick(shortURL, ipAddress)
+		err := repo.CreateClick(shortURL, ipAddress, "", 0)
 
 		assert.NoError(t, err)
 	})
@@ -36,7 +36,7 @@ func TestCreateClick(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO clicks").
 			WillReturnError(errors.New("prepare error"))
 
-		err := repo.CreateClick(shortURL, ipAddress)
+		err := repo.CreateClick(shortURL, ipAddress, "", 0)
 
 		assert.Error(t, err)
 	})
@@ -44,10 +44,10 @@ func TestCreateClick(t *testing.T) {
 	t.Run("Failed on SQL Execution", func(t *testing.T) {
 		mock.ExpectPrepare("INSERT INTO clicks").
 			ExpectExec().
-			WithArgs(shortURL, ipAddress).
+			WithArgs(shortURL, ipAddress, "", 0).
 			WillReturnError(errors.New("execute error"))
 
-		err := repo.CreateClick(shortURL, ipAddress)
+		err := repo.CreateClick(shortURL, ipAddress, "", 0)
 
 		assert.Error(t, err)
 	})
@@ -65,7 +65,7 @@ func TestGetClicks(t *testing.T) {
 	shortURL := "test-url"
 
 	//t.Run("Get Clicks Successfully", func(t *testing.T) {
-	//	rows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at"}).
+	//	rows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at", "variant", "revision_id"}).
 	//		AddRow(1, 1, "127.0.0.1", time.Now()).
 	//		AddRow(2, 1, "127.0.0.1", time.Now())
 	//
@@ -81,7 +81,7 @@ func TestGetClicks(t *testing.T) {
 	//})
 
 	t.Run("Failed to Prepare SQL Statement", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT * FROM clicks").
+		mock.ExpectPrepare("SELECT (.+) FROM clicks").
 			WillReturnError(errors.New("prepare error"))
 
 		clicks, err := repo.GetClicks(shortURL)
@@ -92,7 +92,7 @@ func TestGetClicks(t *testing.T) {
 	})
 
 	t.Run("Failed on SQL Execution", func(t *testing.T) {
-		mock.ExpectPrepare("SELECT * FROM clicks").
+		mock.ExpectPrepare("SELECT (.+) FROM clicks").
 			ExpectQuery().
 			WithArgs(shortURL).
 			WillReturnError(errors.New("execute error"))
@@ -104,11 +104,11 @@ func TestGetClicks(t *testing.T) {
 	})
 
 	t.Run("Failed to Scan Rows", func(t *testing.T) {
-		rows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at"}).
-			AddRow(1, 1, "abc", "invalid").
-			AddRow(2, 1, "acb", "invalid")
+		rows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at", "variant", "revision_id"}).
+			AddRow(1, 1, "abc", "invalid", nil, nil).
+			AddRow(2, 1, "acb", "invalid", nil, nil)
 
-		mock.ExpectPrepare("SELECT * FROM clicks").
+		mock.ExpectPrepare("SELECT (.+) FROM clicks").
 			ExpectQuery().
 			WithArgs(shortURL).
 			WillReturnRows(rows)
@@ -137,12 +137,12 @@ func TestGetClicksSuccessful(t *testing.T) {
 	now := time.Now()
 
 	// Define expected query and result
-	expectedRows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at"}).
-		AddRow(1, "url_id_1", "192.168.0.1", now).
-		AddRow(2, "url_id_2", "192.168.0.2", now)
+	expectedRows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at", "variant", "revision_id"}).
+		AddRow(1, "url_id_1", "192.168.0.1", now, nil, nil).
+		AddRow(2, "url_id_2", "192.168.0.2", now, "b", 3)
 
 	// Expect the query with the short URL
-	mock.ExpectPrepare("SELECT \\* FROM clicks WHERE url_id = \\?").
+	mock.ExpectPrepare("SELECT id, url_id, ip_address, created_at, variant, revision_id FROM clicks WHERE url_id = \\?").
 		ExpectQuery().
 		WithArgs(shortURL).
 		WillReturnRows(expectedRows)
@@ -156,7 +156,7 @@ func TestGetClicksSuccessful(t *testing.T) {
 	// Check if the returned clicks match the expected ones
 	expectedClicks := []clicks_model.Clicks{
 		{ID: 1, UrlID: "url_id_1", IPAddress: "192.168.0.1", CreatedAt: now},
-		{ID: 2, UrlID: "url_id_2", IPAddress: "192.168.0.2", CreatedAt: now},
+		{ID: 2, UrlID: "url_id_2", IPAddress: "192.168.0.2", Variant: "b", Revision: 3, CreatedAt: now},
 	}
 	if len(clicks) != len(expectedClicks) {
 		t.Errorf("expected %d clicks, got %d", len(expectedClicks), len(clicks))
@@ -188,7 +188,7 @@ func TestGetClicksQueryError(t *testing.T) {
 	shortURL := "your-shortened-url"
 
 	// Expect the query with the short URL
-	mock.ExpectPrepare("SELECT \\* FROM clicks WHERE url_id = \\?").
+	mock.ExpectPrepare("SELECT id, url_id, ip_address, created_at, variant, revision_id FROM clicks WHERE url_id = \\?").
 		ExpectQuery().
 		WithArgs(shortURL).
 		WillReturnError(errors.New("query error"))
@@ -223,12 +223,12 @@ func TestGetClicksScanError(t *testing.T) {
 	shortURL := "your-shortened-url"
 
 	// Define expected query and result
-	expectedRows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at"}).
-		AddRow(1, "url_id_1", "127.0.0.1", time.Now()).
-		AddRow(2, "url_id_2", "127.0.0.1", "invalid")
+	expectedRows := sqlmock.NewRows([]string{"id", "url_id", "ip_address", "created_at", "variant", "revision_id"}).
+		AddRow(1, "url_id_1", "127.0.0.1", time.Now(), nil, nil).
+		AddRow(2, "url_id_2", "127.0.0.1", "invalid", nil, nil)
 
 	// Expect the query with the short URL
-	mock.ExpectPrepare("SELECT \\* FROM clicks WHERE url_id = \\?").
+	mock.ExpectPrepare("SELECT id, url_id, ip_address, created_at, variant, revision_id FROM clicks WHERE url_id = \\?").
 		ExpectQuery().
 		WithArgs(shortURL).
 		WillReturnRows(expectedRows)
@@ -242,3 +242,135 @@ func TestGetClicksScanError(t *testing.T) {
 		t.Errorf("expected no clicks, got %+v", clicks)
 	}
 }
+
+func TestIterateClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBClicksRepository(db)
+	columns := []string{"id", "url_id", "ip_address", "created_at", "variant", "revision_id"}
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	t.Run("Iterate Clicks of Link", func(t *testing.T) {
+		mock.ExpectQuery("SELECT c.id, c.url_id, c.ip_address, c.created_at, c.variant, c.revision_id FROM clicks c WHERE c.url_id = \\? ORDER BY c.id").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "abc123", "127.0.0.1", from, nil, nil).
+				AddRow(2, "abc123", "127.0.0.2", to, "b", 3))
+
+		var ids []uint
+		err := repo.IterateClicks(clicks_model.Filter{ShortURL: "abc123"}, func(click *clicks_model.Clicks) error {
+			ids = append(ids, click.ID)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uint{1, 2}, ids)
+	})
+
+	t.Run("Iterate Clicks of User Within Range", func(t *testing.T) {
+		mock.ExpectQuery("FROM clicks c JOIN urls u ON u.shortened_url = c.url_id WHERE u.user_id = \\? AND u.workspace_id IS NULL AND c.created_at >= \\? AND c.created_at < \\? ORDER BY c.id").
+			WithArgs(uint(1), from, to).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "abc123", "127.0.0.1", from, nil, nil))
+
+		calls := 0
+		err := repo.IterateClicks(clicks_model.Filter{UserID: 1, From: &from, To: &to}, func(click *clicks_model.Clicks) error {
+			calls++
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Stop on Callback Error", func(t *testing.T) {
+		mock.ExpectQuery("FROM clicks c").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, "abc123", "127.0.0.1", from, nil, nil).
+				AddRow(2, "abc123", "127.0.0.2", to, "b", 3))
+
+		calls := 0
+		err := repo.IterateClicks(clicks_model.Filter{ShortURL: "abc123"}, func(click *clicks_model.Clicks) error {
+			calls++
+			return errors.New("write error")
+		})
+
+		assert.EqualError(t, err, "write error")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("FROM clicks c").
+			WillReturnError(errors.New("query error"))
+
+		err := repo.IterateClicks(clicks_model.Filter{ShortURL: "abc123"}, func(click *clicks_model.Clicks) error { return nil })
+
+		assert.Error(t, err)
+	})
+}
+
+func TestCountVariantClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBClicksRepository(db)
+
+	t.Run("Count Clicks by Variant", func(t *testing.T) {
+		mock.ExpectQuery("SELECT variant, COUNT\\(\\*\\) FROM clicks WHERE url_id = \\? AND variant IS NOT NULL GROUP BY variant").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"variant", "count"}).AddRow("a", 3).AddRow("b", 1))
+
+		counts, err := repo.CountVariantClicks("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"a": 3, "b": 1}, counts)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("FROM clicks").
+			WillReturnError(errors.New("query error"))
+
+		_, err := repo.CountVariantClicks("abc123")
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestCountRevisionClicks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBClicksRepository(db)
+
+	t.Run("Count Clicks by Revision", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COALESCE\\(revision_id, 0\\), COUNT\\(\\*\\) FROM clicks WHERE url_id = \\? GROUP BY revision_id").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"revision", "count"}).AddRow(0, 5).AddRow(2, 1))
+
+		counts, err := repo.CountRevisionClicks("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[uint]int{0: 5, 2: 1}, counts)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("FROM clicks").
+			WillReturnError(errors.New("query error"))
+
+		_, err := repo.CountRevisionClicks("abc123")
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,238 @@
+package domain_repository
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+)
+
+// errDuplicateEntry is the MySQL error number of inserting a value a unique key already holds.
+const errDuplicateEntry = 1062
+
+// Repository defines methods to interact with the domain repository.
+type Repository interface {
+	List(userID uint) ([]domain_model.Domain, error)
+	GetByID(id uint) (*domain_model.Domain, error)
+	GetByHostname(hostname string) (*domain_model.Domain, error)
+	ListByHostname(hostname string) ([]domain_model.Domain, error)
+	Create(domain *domain_model.Domain) (*domain_model.Domain, error)
+	SetVerified(id uint, verifiedAt time.Time) error
+	Delete(id uint) error
+	CreateLink(domainID uint, code, shortURL string) error
+	GetLink(domainID uint, code string) (string, error)
+	GetLinkByShortURL(shortURL string) (*domain_model.Link, error)
+	ListLinks(domainID uint) ([]domain_model.Link, error)
+}
+
+// DBDomainRepository is an implementation of DomainRepository for MySQL database.
+type DBDomainRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBDomainRepository creates a new instance of DBDomainRepository.
+func NewDBDomainRepository(db *sql.DB) *DBDomainRepository {
+	return &DBDomainRepository{DB: db}
+}
+
+// domainQuery selects domains with the columns read by scanDomain.
+const domainQuery = "SELECT id, hostname, user_id, workspace_id, verification_token, verified_at, created_at FROM domains"
+
+// List retrieves the personal domains of the user and the domains of their workspaces, by hostname.
+func (r *DBDomainRepository) List(userID uint) ([]domain_model.Domain, error) {
+	rows, err := r.DB.Query(domainQuery+" WHERE (user_id = ? AND workspace_id IS NULL) OR workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ?) ORDER BY hostname", userID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := make([]domain_model.Domain, 0)
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, *domain)
+	}
+
+	return domains, rows.Err()
+}
+
+// GetByID retrieves a domain by ID.
+func (r *DBDomainRepository) GetByID(id uint) (*domain_model.Domain, error) {
+	return r.get(domainQuery+" WHERE id = ?", id)
+}
+
+// GetByHostname retrieves the domain serving the hostname: its verified claim, or else its oldest pending claim.
+func (r *DBDomainRepository) GetByHostname(hostname string) (*domain_model.Domain, error) {
+	return r.get(domainQuery+" WHERE hostname = ? ORDER BY verified_at IS NULL, id LIMIT 1", hostname)
+}
+
+// ListByHostname retrieves the verified and pending claims of the hostname.
+func (r *DBDomainRepository) ListByHostname(hostname string) ([]domain_model.Domain, error) {
+	rows, err := r.DB.Query(domainQuery+" WHERE hostname = ? ORDER BY id", hostname)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	domains := make([]domain_model.Domain, 0)
+	for rows.Next() {
+		domain, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, *domain)
+	}
+
+	return domains, rows.Err()
+}
+
+func (r *DBDomainRepository) get(query string, args ...interface{}) (*domain_model.Domain, error) {
+	domain, err := scanDomain(r.DB.QueryRow(query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain_model.ErrDomainNotFound
+		}
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// scanDomain reads a domain selected with domainQuery.
+func scanDomain(row interface{ Scan(...interface{}) error }) (*domain_model.Domain, error) {
+	var domain domain_model.Domain
+	var workspaceID sql.NullInt64
+	var verifiedAt sql.NullTime
+	if err := row.Scan(&domain.ID, &domain.Hostname, &domain.UserID, &workspaceID, &domain.Token, &verifiedAt, &domain.CreatedAt); err != nil {
+		return nil, err
+	}
+	if workspaceID.Valid {
+		id := uint(workspaceID.Int64)
+		domain.WorkspaceID = &id
+	}
+	if verifiedAt.Valid {
+		domain.Verified = true
+		domain.VerifiedAt = &verifiedAt.Time
+	}
+	return &domain, nil
+}
+
+// Create inserts a new unverified domain.
+func (r *DBDomainRepository) Create(domain *domain_model.Domain) (*domain_model.Domain, error) {
+	result, err := r.DB.Exec("INSERT INTO domains (hostname, user_id, workspace_id, verification_token) VALUES (?, ?, ?, ?)",
+		domain.Hostname, domain.UserID, domain.WorkspaceID, domain.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted domain
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(uint(id))
+}
+
+// SetVerified records the time the ownership of the domain was verified, reserving its hostname.
+// It fails with domain_model.ErrDomainAlreadyExists when another claim of the hostname was verified first.
+func (r *DBDomainRepository) SetVerified(id uint, verifiedAt time.Time) error {
+	result, err := r.DB.Exec("UPDATE domains SET verified_at = ?, verified_hostname = hostname WHERE id = ?", verifiedAt, id)
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateEntry {
+		return domain_model.ErrDomainAlreadyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the domain exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return domain_model.ErrDomainNotFound
+	}
+
+	return nil
+}
+
+// Delete deletes a domain without links.
+func (r *DBDomainRepository) Delete(id uint) error {
+	result, err := r.DB.Exec("DELETE FROM domains WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the domain exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return domain_model.ErrDomainNotFound
+	}
+
+	return nil
+}
+
+// CreateLink maps the code of the domain to the internal short code of a link.
+func (r *DBDomainRepository) CreateLink(domainID uint, code, shortURL string) error {
+	_, err := r.DB.Exec("INSERT INTO domain_links (domain_id, code, url_id) VALUES (?, ?, ?)", domainID, code, shortURL)
+	return err
+}
+
+// GetLink retrieves the internal short code of the link with the code on the domain.
+func (r *DBDomainRepository) GetLink(domainID uint, code string) (string, error) {
+	var shortURL string
+	err := r.DB.QueryRow("SELECT url_id FROM domain_links WHERE domain_id = ? AND code = ?", domainID, code).Scan(&shortURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", url_model.ErrURLNotFound
+		}
+		return "", err
+	}
+
+	return shortURL, nil
+}
+
+// GetLinkByShortURL retrieves the domain link with the internal short code.
+func (r *DBDomainRepository) GetLinkByShortURL(shortURL string) (*domain_model.Link, error) {
+	var link domain_model.Link
+	err := r.DB.QueryRow("SELECT d.hostname, l.code, l.url_id FROM domain_links l JOIN domains d ON d.id = l.domain_id WHERE l.url_id = ?", shortURL).
+		Scan(&link.Domain, &link.Code, &link.ShortenedURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, url_model.ErrURLNotFound
+		}
+		return nil, err
+	}
+
+	return &link, nil
+}
+
+// ListLinks retrieves the links of the domain by code.
+func (r *DBDomainRepository) ListLinks(domainID uint) ([]domain_model.Link, error) {
+	rows, err := r.DB.Query("SELECT d.hostname, l.code, l.url_id FROM domain_links l JOIN domains d ON d.id = l.domain_id WHERE l.domain_id = ? ORDER BY l.code", domainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := make([]domain_model.Link, 0)
+	for rows.Next() {
+		var link domain_model.Link
+		if err := rows.Scan(&link.Domain, &link.Code, &link.ShortenedURL); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
@@ -0,0 +1,211 @@
+package domain_repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+)
+
+var columns = []string{"id", "hostname", "user_id", "workspace_id", "verification_token", "verified_at", "created_at"}
+
+func TestDBDomainRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBDomainRepository(db)
+	createdAt := time.Now()
+
+	t.Run("List Domains Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM domains WHERE \\(user_id = \\? AND workspace_id IS NULL\\) OR workspace_id IN \\(SELECT workspace_id FROM workspace_members WHERE user_id = \\?\\) ORDER BY hostname").
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, "go.example.com", 1, nil, "token", createdAt, createdAt).
+				AddRow(4, "links.example.org", 2, 5, "other", nil, createdAt))
+
+		domains, err := repo.List(1)
+
+		assert.NoError(t, err)
+		assert.Len(t, domains, 2)
+		assert.True(t, domains[0].Verified)
+		assert.Equal(t, "token", domains[0].Token)
+		assert.Nil(t, domains[0].WorkspaceID)
+		assert.False(t, domains[1].Verified)
+		assert.Equal(t, uint(5), *domains[1].WorkspaceID)
+	})
+
+	t.Run("Get Domain by Hostname", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM domains WHERE hostname = \\? ORDER BY verified_at IS NULL, id LIMIT 1").
+			WithArgs("go.example.com").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "go.example.com", 1, nil, "token", nil, createdAt))
+
+		domain, err := repo.GetByHostname("go.example.com")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), domain.ID)
+		assert.Nil(t, domain.VerifiedAt)
+	})
+
+	t.Run("List Claims of Hostname", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM domains WHERE hostname = \\? ORDER BY id").
+			WithArgs("go.example.com").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, "go.example.com", 1, nil, "token", nil, createdAt).
+				AddRow(6, "go.example.com", 2, nil, "other", nil, createdAt))
+
+		domains, err := repo.ListByHostname("go.example.com")
+
+		assert.NoError(t, err)
+		assert.Len(t, domains, 2)
+		assert.Equal(t, uint(2), domains[1].UserID)
+	})
+
+	t.Run("Failed to Get Missing Domain", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM domains WHERE id = \\?").
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetByID(9)
+
+		assert.ErrorIs(t, err, domain_model.ErrDomainNotFound)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.List(1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBDomainRepository_Write(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBDomainRepository(db)
+
+	t.Run("Create Domain Successfully", func(t *testing.T) {
+		workspaceID := uint(5)
+		mock.ExpectExec("INSERT INTO domains \\(hostname, user_id, workspace_id, verification_token\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
+			WithArgs("go.example.com", 1, 5, "token").
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectQuery("SELECT (.+) FROM domains WHERE id = \\?").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "go.example.com", 1, 5, "token", nil, time.Now()))
+
+		domain, err := repo.Create(&domain_model.Domain{Hostname: "go.example.com", UserID: 1, WorkspaceID: &workspaceID, Token: "token"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), domain.ID)
+	})
+
+	t.Run("Verify Domain Successfully", func(t *testing.T) {
+		verifiedAt := time.Now()
+		mock.ExpectExec("UPDATE domains SET verified_at = \\?, verified_hostname = hostname WHERE id = \\?").
+			WithArgs(verifiedAt, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetVerified(3, verifiedAt))
+	})
+
+	t.Run("Failed to Verify Hostname Verified Before", func(t *testing.T) {
+		verifiedAt := time.Now()
+		mock.ExpectExec("UPDATE domains SET verified_at = \\?, verified_hostname = hostname WHERE id = \\?").
+			WithArgs(verifiedAt, 6).
+			WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+
+		assert.ErrorIs(t, repo.SetVerified(6, verifiedAt), domain_model.ErrDomainAlreadyExists)
+	})
+
+	t.Run("Failed to Delete Missing Domain", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM domains WHERE id = \\?").
+			WithArgs(9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.Delete(9), domain_model.ErrDomainNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBDomainRepository_Links(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBDomainRepository(db)
+
+	t.Run("Create Link Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO domain_links \\(domain_id, code, url_id\\) VALUES \\(\\?, \\?, \\?\\)").
+			WithArgs(3, "launch", "abc12345").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.CreateLink(3, "launch", "abc12345"))
+	})
+
+	t.Run("Get Link Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT url_id FROM domain_links WHERE domain_id = \\? AND code = \\?").
+			WithArgs(3, "launch").
+			WillReturnRows(sqlmock.NewRows([]string{"url_id"}).AddRow("abc12345"))
+
+		shortURL, err := repo.GetLink(3, "launch")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc12345", shortURL)
+	})
+
+	t.Run("Failed to Get Missing Link", func(t *testing.T) {
+		mock.ExpectQuery("SELECT url_id FROM domain_links").
+			WithArgs(3, "missing").
+			WillReturnRows(sqlmock.NewRows([]string{"url_id"}))
+
+		_, err := repo.GetLink(3, "missing")
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Get Link by Short Code", func(t *testing.T) {
+		mock.ExpectQuery("SELECT d.hostname, l.code, l.url_id FROM domain_links l JOIN domains d ON d.id = l.domain_id WHERE l.url_id = \\?").
+			WithArgs("abc12345").
+			WillReturnRows(sqlmock.NewRows([]string{"hostname", "code", "url_id"}).AddRow("go.example.com", "launch", "abc12345"))
+
+		link, err := repo.GetLinkByShortURL("abc12345")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &domain_model.Link{Domain: "go.example.com", Code: "launch", ShortenedURL: "abc12345"}, link)
+
+		mock.ExpectQuery("SELECT d.hostname, l.code, l.url_id FROM domain_links").
+			WithArgs("other").
+			WillReturnRows(sqlmock.NewRows([]string{"hostname", "code", "url_id"}))
+
+		_, err = repo.GetLinkByShortURL("other")
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("List Links Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT d.hostname, l.code, l.url_id FROM domain_links l JOIN domains d ON d.id = l.domain_id WHERE l.domain_id = \\? ORDER BY l.code").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"hostname", "code", "url_id"}).AddRow("go.example.com", "launch", "abc12345"))
+
+		links, err := repo.ListLinks(3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []domain_model.Link{{Domain: "go.example.com", Code: "launch", ShortenedURL: "abc12345"}}, links)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,137 @@
+package folder_repository
+
+import (
+	"database/sql"
+	"errors"
+	"url-shortener/internal/app/models/folder"
+)
+
+// Repository defines methods to interact with the folder repository.
+type Repository interface {
+	List(userID uint) ([]folder_model.Folder, error)
+	GetByID(userID, id uint) (*folder_model.Folder, error)
+	GetByName(userID uint, name string) (*folder_model.Folder, error)
+	Create(userID uint, name string) (*folder_model.Folder, error)
+	Rename(id uint, name string) error
+	Delete(id uint) error
+}
+
+// DBFolderRepository is an implementation of FolderRepository for MySQL database.
+type DBFolderRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBFolderRepository creates a new instance of DBFolderRepository.
+func NewDBFolderRepository(db *sql.DB) *DBFolderRepository {
+	return &DBFolderRepository{DB: db}
+}
+
+// folderQuery selects folders with the number of personal links in them.
+const folderQuery = "SELECT f.id, f.name, f.created_at, COUNT(u.shortened_url) FROM folders f LEFT JOIN urls u ON u.folder_id = f.id AND u.workspace_id IS NULL"
+
+// List retrieves the folders of the user by name.
+func (r *DBFolderRepository) List(userID uint) ([]folder_model.Folder, error) {
+	rows, err := r.DB.Query(folderQuery+" WHERE f.user_id = ? GROUP BY f.id, f.name, f.created_at ORDER BY f.name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := make([]folder_model.Folder, 0)
+	for rows.Next() {
+		var folder folder_model.Folder
+		if err := rows.Scan(&folder.ID, &folder.Name, &folder.CreatedAt, &folder.URLCount); err != nil {
+			return nil, err
+		}
+		folders = append(folders, folder)
+	}
+
+	return folders, rows.Err()
+}
+
+// GetByID retrieves a folder of the user by ID.
+func (r *DBFolderRepository) GetByID(userID, id uint) (*folder_model.Folder, error) {
+	return r.get(folderQuery+" WHERE f.user_id = ? AND f.id = ? GROUP BY f.id, f.name, f.created_at", userID, id)
+}
+
+// GetByName retrieves a folder of the user by name.
+func (r *DBFolderRepository) GetByName(userID uint, name string) (*folder_model.Folder, error) {
+	return r.get(folderQuery+" WHERE f.user_id = ? AND f.name = ? GROUP BY f.id, f.name, f.created_at", userID, name)
+}
+
+func (r *DBFolderRepository) get(query string, args ...interface{}) (*folder_model.Folder, error) {
+	var folder folder_model.Folder
+	err := r.DB.QueryRow(query, args...).Scan(&folder.ID, &folder.Name, &folder.CreatedAt, &folder.URLCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, folder_model.ErrFolderNotFound
+		}
+		return nil, err
+	}
+
+	return &folder, nil
+}
+
+// Create inserts a new folder of the user.
+func (r *DBFolderRepository) Create(userID uint, name string) (*folder_model.Folder, error) {
+	result, err := r.DB.Exec("INSERT INTO folders (user_id, name) VALUES (?, ?)", userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted folder
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(userID, uint(id))
+}
+
+// Rename changes the name of a folder.
+func (r *DBFolderRepository) Rename(id uint, name string) error {
+	result, err := r.DB.Exec("UPDATE folders SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the folder exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return folder_model.ErrFolderNotFound
+	}
+
+	return nil
+}
+
+// Delete moves the links of a folder out of it and deletes it, in a single transaction.
+func (r *DBFolderRepository) Delete(id uint) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE urls SET folder_id = NULL WHERE folder_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM folders WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the folder exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return folder_model.ErrFolderNotFound
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,112 @@
+package folder_repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/folder"
+)
+
+var columns = []string{"id", "name", "created_at", "url_count"}
+
+func TestDBFolderRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBFolderRepository(db)
+	createdAt := time.Now()
+
+	t.Run("List Folders Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT f.id, f.name, f.created_at, COUNT\\(u.shortened_url\\) FROM folders f LEFT JOIN urls u ON u.folder_id = f.id AND u.workspace_id IS NULL WHERE f.user_id = \\? GROUP BY (.+) ORDER BY f.name").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "Campaigns", createdAt, 2))
+
+		folders, err := repo.List(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []folder_model.Folder{{ID: 3, Name: "Campaigns", URLCount: 2, CreatedAt: createdAt}}, folders)
+	})
+
+	t.Run("Get Folder by Name", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM folders f (.+) WHERE f.user_id = \\? AND f.name = \\?").
+			WithArgs(1, "Campaigns").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "Campaigns", createdAt, 2))
+
+		folder, err := repo.GetByName(1, "Campaigns")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), folder.ID)
+	})
+
+	t.Run("Failed to Get Missing Folder", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM folders f (.+) WHERE f.user_id = \\? AND f.id = \\?").
+			WithArgs(1, 9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetByID(1, 9)
+
+		assert.ErrorIs(t, err, folder_model.ErrFolderNotFound)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT f.id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.List(1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBFolderRepository_Write(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBFolderRepository(db)
+
+	t.Run("Create Folder Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO folders \\(user_id, name\\) VALUES \\(\\?, \\?\\)").
+			WithArgs(1, "Campaigns").
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectQuery("SELECT (.+) WHERE f.user_id = \\? AND f.id = \\?").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "Campaigns", time.Now(), 0))
+
+		folder, err := repo.Create(1, "Campaigns")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), folder.ID)
+	})
+
+	t.Run("Failed to Rename Missing Folder", func(t *testing.T) {
+		mock.ExpectExec("UPDATE folders SET name = \\? WHERE id = \\?").
+			WithArgs("Archive", 9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.Rename(9, "Archive"), folder_model.ErrFolderNotFound)
+	})
+
+	t.Run("Delete Folder Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE urls SET folder_id = NULL WHERE folder_id = \\?").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM folders WHERE id = \\?").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Delete(3))
+	})
+
+	t.Run("Roll Back on Failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE urls SET folder_id = NULL").WillReturnError(errors.New("execute error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.Delete(3))
+	})
+}
@@ -0,0 +1,207 @@
+package tag_repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"url-shortener/internal/app/models/tag"
+)
+
+// Repository defines methods to interact with the tag repository.
+type Repository interface {
+	List(userID uint) ([]tag_model.Tag, error)
+	GetByID(userID, id uint) (*tag_model.Tag, error)
+	GetByName(userID uint, name string) (*tag_model.Tag, error)
+	Create(userID uint, name string) (*tag_model.Tag, error)
+	Rename(id uint, name string) error
+	Delete(id uint) error
+	Assign(userID uint, shortCodes, add, remove []string) error
+	Stats(userID uint) ([]tag_model.Stats, error)
+}
+
+// DBTagRepository is an implementation of TagRepository for MySQL database.
+type DBTagRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBTagRepository creates a new instance of DBTagRepository.
+func NewDBTagRepository(db *sql.DB) *DBTagRepository {
+	return &DBTagRepository{DB: db}
+}
+
+// tagQuery selects tags with the number of links having them.
+const tagQuery = "SELECT t.id, t.name, t.created_at, COUNT(ut.url_id) FROM tags t LEFT JOIN url_tags ut ON ut.tag_id = t.id"
+
+// List retrieves the tags of the user by name.
+func (r *DBTagRepository) List(userID uint) ([]tag_model.Tag, error) {
+	rows, err := r.DB.Query(tagQuery+" WHERE t.user_id = ? GROUP BY t.id, t.name, t.created_at ORDER BY t.name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]tag_model.Tag, 0)
+	for rows.Next() {
+		var tag tag_model.Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.URLCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// GetByID retrieves a tag of the user by ID.
+func (r *DBTagRepository) GetByID(userID, id uint) (*tag_model.Tag, error) {
+	return r.get(tagQuery+" WHERE t.user_id = ? AND t.id = ? GROUP BY t.id, t.name, t.created_at", userID, id)
+}
+
+// GetByName retrieves a tag of the user by name.
+func (r *DBTagRepository) GetByName(userID uint, name string) (*tag_model.Tag, error) {
+	return r.get(tagQuery+" WHERE t.user_id = ? AND t.name = ? GROUP BY t.id, t.name, t.created_at", userID, name)
+}
+
+func (r *DBTagRepository) get(query string, args ...interface{}) (*tag_model.Tag, error) {
+	var tag tag_model.Tag
+	err := r.DB.QueryRow(query, args...).Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.URLCount)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, tag_model.ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// Create inserts a new tag of the user.
+func (r *DBTagRepository) Create(userID uint, name string) (*tag_model.Tag, error) {
+	result, err := r.DB.Exec("INSERT INTO tags (user_id, name) VALUES (?, ?)", userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted tag
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(userID, uint(id))
+}
+
+// Rename changes the name of a tag.
+func (r *DBTagRepository) Rename(id uint, name string) error {
+	result, err := r.DB.Exec("UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the tag exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return tag_model.ErrTagNotFound
+	}
+
+	return nil
+}
+
+// Delete removes a tag from its links and deletes it, in a single transaction.
+func (r *DBTagRepository) Delete(id uint) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM url_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the tag exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return tag_model.ErrTagNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Assign adds the tags of the user named in add to the links and removes those named in remove,
+// in a single transaction. Missing tags to add are created.
+func (r *DBTagRepository) Assign(userID uint, shortCodes, add, remove []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, name := range add {
+		// Reuse the tag of the user with the same name, making its ID the last insert ID
+		result, err := tx.Exec("INSERT INTO tags (user_id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)", userID, name)
+		if err != nil {
+			return fmt.Errorf("failed to insert tag %s: %w", name, err)
+		}
+		tagID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, shortCode := range shortCodes {
+			if _, err := tx.Exec("INSERT IGNORE INTO url_tags (url_id, tag_id) VALUES (?, ?)", shortCode, tagID); err != nil {
+				return fmt.Errorf("failed to tag %s: %w", shortCode, err)
+			}
+		}
+	}
+
+	for _, name := range remove {
+		for _, shortCode := range shortCodes {
+			_, err := tx.Exec("DELETE ut FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = ? AND t.name = ? AND ut.url_id = ?", userID, name, shortCode)
+			if err != nil {
+				return fmt.Errorf("failed to untag %s: %w", shortCode, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Stats aggregates the clicks of the links of each tag of the user, by tag name.
+func (r *DBTagRepository) Stats(userID uint) ([]tag_model.Stats, error) {
+	// Imported clicks are summed in a subquery since the join repeats links once per click
+	query := `SELECT t.id, t.name, COUNT(DISTINCT ut.url_id), COUNT(c.id), COUNT(DISTINCT c.ip_address),
+		(SELECT COALESCE(SUM(u.imported_clicks), 0) FROM url_tags iut JOIN urls u ON u.shortened_url = iut.url_id WHERE iut.tag_id = t.id)
+		FROM tags t
+		LEFT JOIN url_tags ut ON ut.tag_id = t.id
+		LEFT JOIN clicks c ON c.url_id = ut.url_id
+		WHERE t.user_id = ?
+		GROUP BY t.id, t.name
+		ORDER BY t.name`
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]tag_model.Stats, 0)
+	for rows.Next() {
+		var s tag_model.Stats
+		if err := rows.Scan(&s.TagID, &s.Name, &s.URLs, &s.Clicks, &s.UniqueVisitors, &s.ImportedClicks); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,178 @@
+package tag_repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/tag"
+)
+
+var columns = []string{"id", "name", "created_at", "url_count"}
+
+func TestDBTagRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBTagRepository(db)
+	createdAt := time.Now()
+
+	t.Run("List Tags Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT t.id, t.name, t.created_at, COUNT\\(ut.url_id\\) FROM tags t LEFT JOIN url_tags ut ON ut.tag_id = t.id WHERE t.user_id = \\? GROUP BY (.+) ORDER BY t.name").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "docs", createdAt, 3).AddRow(1, "team", createdAt, 0))
+
+		tags, err := repo.List(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []tag_model.Tag{{ID: 2, Name: "docs", URLCount: 3, CreatedAt: createdAt}, {ID: 1, Name: "team", CreatedAt: createdAt}}, tags)
+	})
+
+	t.Run("Get Tag by Name", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM tags t (.+) WHERE t.user_id = \\? AND t.name = \\?").
+			WithArgs(1, "docs").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(2, "docs", createdAt, 3))
+
+		tag, err := repo.GetByName(1, "docs")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), tag.ID)
+	})
+
+	t.Run("Failed to Get Missing Tag", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM tags t (.+) WHERE t.user_id = \\? AND t.id = \\?").
+			WithArgs(1, 9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetByID(1, 9)
+
+		assert.ErrorIs(t, err, tag_model.ErrTagNotFound)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT t.id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.List(1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBTagRepository_Write(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBTagRepository(db)
+
+	t.Run("Create Tag Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO tags \\(user_id, name\\) VALUES \\(\\?, \\?\\)").
+			WithArgs(1, "docs").
+			WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectQuery("SELECT (.+) WHERE t.user_id = \\? AND t.id = \\?").
+			WithArgs(1, 4).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "docs", time.Now(), 0))
+
+		tag, err := repo.Create(1, "docs")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(4), tag.ID)
+	})
+
+	t.Run("Rename Tag Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE tags SET name = \\? WHERE id = \\?").
+			WithArgs("guides", 4).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Rename(4, "guides"))
+	})
+
+	t.Run("Delete Tag Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM url_tags WHERE tag_id = \\?").WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM tags WHERE id = \\?").WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Delete(4))
+	})
+
+	t.Run("Failed to Delete Missing Tag", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM url_tags").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM tags").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Delete(9), tag_model.ErrTagNotFound)
+	})
+}
+
+func TestDBTagRepository_Assign(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBTagRepository(db)
+
+	t.Run("Assign Tags Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tags \\(user_id, name\\) VALUES \\(\\?, \\?\\) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID\\(id\\)").
+			WithArgs(1, "docs").
+			WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectExec("INSERT IGNORE INTO url_tags \\(url_id, tag_id\\) VALUES \\(\\?, \\?\\)").WithArgs("abc", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT IGNORE INTO url_tags").WithArgs("def", 4).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE ut FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = \\? AND t.name = \\? AND ut.url_id = \\?").
+			WithArgs(1, "old", "abc").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("DELETE ut FROM url_tags").WithArgs(1, "old", "def").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Assign(1, []string{"abc", "def"}, []string{"docs"}, []string{"old"}))
+	})
+
+	t.Run("Roll Back on Failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO tags").WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectExec("INSERT IGNORE INTO url_tags").WillReturnError(errors.New("foreign key error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.Assign(1, []string{"abc"}, []string{"docs"}, nil))
+	})
+}
+
+func TestDBTagRepository_Stats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBTagRepository(db)
+
+	t.Run("Get Stats Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT t.id, t.name, COUNT\\(DISTINCT ut.url_id\\), COUNT\\(c.id\\), COUNT\\(DISTINCT c.ip_address\\)").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "urls", "clicks", "unique_visitors", "imported_clicks"}).
+				AddRow(2, "docs", 3, 40, 12, 5))
+
+		stats, err := repo.Stats(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []tag_model.Stats{{TagID: 2, Name: "docs", URLs: 3, Clicks: 40, UniqueVisitors: 12, ImportedClicks: 5}}, stats)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT t.id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.Stats(1)
+
+		assert.Error(t, err)
+	})
+}
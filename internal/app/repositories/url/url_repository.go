@@ -2,8 +2,10 @@ package url_repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 	"url-shortener/internal/app/models/url"
 )
 
@@ -12,7 +14,82 @@ type Repository interface {
 	CreateURL(originalURL, shortCode string, userId *uint) (string, error)
 	GetOriginalURL(shortCode string) (string, error)
 	GetUserURLs(userID uint) ([]url_model.URL, error)
+	IterateUserURLs(userID uint, fn func(*url_model.URL) error) error
+	FindUserURLs(userID uint, filter url_model.Filter) ([]url_model.URL, error)
 	GetUserWithShortURL(userID uint, shortURL string) error
+	GetURL(shortCode string) (*url_model.URL, error)
+	SetDisabled(shortCode string, disabled bool) error
+	CreateWorkspaceURL(originalURL, shortCode string, userID, workspaceID uint) (string, error)
+	GetWorkspaceURLs(workspaceID uint) ([]url_model.URL, error)
+	SetOwner(shortCode string, userID uint, workspaceID *uint) error
+	SetFolder(shortCode string, folderID *uint) error
+	CreateURLs(urls []url_model.NewURL) error
+	GetImportSources(userID uint) (map[string]string, error)
+	GetPasswordHash(shortCode string) (string, error)
+	SetPasswordHash(shortCode string, hash *string) error
+	SetDetails(shortCode, title, notes string) error
+	GetMetadata(shortCode string) (*url_model.Metadata, error)
+	SaveMetadata(shortCode string, metadata *url_model.Metadata) error
+	GetRules(shortCode string) ([]url_model.Rule, error)
+	SetRules(shortCode string, rules []url_model.Rule) error
+	GetSplit(shortCode string) (*url_model.Split, error)
+	SetSplit(shortCode string, split *url_model.Split) error
+	GetTracking(shortCode string) (*url_model.Tracking, error)
+	SetTracking(shortCode string, tracking *url_model.Tracking) error
+	GetDeepLink(shortCode string) (*url_model.DeepLink, error)
+	SetDeepLink(shortCode string, deepLink *url_model.DeepLink) error
+	GetPreview(shortCode string) (*url_model.Preview, error)
+	SetPreview(shortCode string, preview *url_model.Preview) error
+	SetActivation(shortCode string, activeFrom, activeUntil *time.Time) error
+	GetNotActiveResponse(shortCode string) (*url_model.NotActiveResponse, error)
+	SetNotActiveResponse(shortCode string, response *url_model.NotActiveResponse) error
+	GetRedirectVersion(shortCode string) (int, uint, error)
+	GetRevisions(shortCode string) ([]url_model.Revision, error)
+	Revise(shortCode string, revisions []*url_model.Revision, withRules bool) error
+	DeleteURL(shortCode string) error
+}
+
+// urlColumns lists the columns read by scanURL, in order.
+const urlColumns = "original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type"
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// execer is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// scanURL reads a URL selected with urlColumns.
+func scanURL(row rowScanner) (*url_model.URL, error) {
+	// Anonymous URLs have no user and personal URLs have no workspace
+	var u url_model.URL
+	var userID, workspaceID, folderID sql.NullInt64
+	var expiresAt, activeFrom sql.NullTime
+	var title, notes sql.NullString
+	if err := row.Scan(&u.OriginalURL, &u.ShortenedURL, &userID, &workspaceID, &u.Disabled, &expiresAt, &u.ImportedClicks, &u.CreatedAt, &folderID, &title, &notes, &activeFrom, &u.RedirectType); err != nil {
+		return nil, err
+	}
+	u.Title, u.Notes = title.String, notes.String
+	if expiresAt.Valid {
+		u.ExpiresAt = &expiresAt.Time
+	}
+	if activeFrom.Valid {
+		u.ActiveFrom = &activeFrom.Time
+	}
+	u.UserID = uint(userID.Int64)
+	if workspaceID.Valid {
+		id := uint(workspaceID.Int64)
+		u.WorkspaceID = &id
+	}
+	if folderID.Valid {
+		id := uint(folderID.Int64)
+		u.FolderID = &id
+	}
+
+	return &u, nil
 }
 
 // DBURLRepository is an implementation of URLRepository for MySQL database.
@@ -70,14 +147,16 @@ func (r *DBURLRepository) CreateURL(originalURL, shortCode string, userID *uint)
 // GetOriginalURL retrieves the original URL from the database by short code.
 func (r *DBURLRepository) GetOriginalURL(shortCode string) (string, error) {
 	// Prepare SQL statement
-	query := "SELECT original_url FROM urls WHERE shortened_url = ?"
+	query := "SELECT original_url, disabled, expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP, " +
+		"active_from IS NOT NULL AND active_from > CURRENT_TIMESTAMP FROM urls WHERE shortened_url = ?"
 	row := r.DB.QueryRow(query, shortCode)
 
 	// Initialize a string to store the result
 	var originalURL string
+	var disabled, expired, pending bool
 
 	// Scan the result into the originalURL string
-	err := row.Scan(&originalURL)
+	err := row.Scan(&originalURL, &disabled, &expired, &pending)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Return a custom error if the URL with the specified short code is not found
@@ -86,32 +165,91 @@ func (r *DBURLRepository) GetOriginalURL(shortCode string) (string, error) {
 		return "", err
 	}
 
+	// Disabled links keep their short code but no longer resolve
+	if disabled {
+		return "", url_model.ErrURLDisabled
+	}
+	if expired {
+		return "", url_model.ErrURLExpired
+	}
+	if pending {
+		return "", url_model.ErrURLNotActive
+	}
+
 	return originalURL, nil
 }
 
-// GetUserURLs retrieves the URLs created by the given user.
+// GetUserURLs retrieves the personal URLs created by the given user.
 func (r *DBURLRepository) GetUserURLs(userID uint) ([]url_model.URL, error) {
 	// Prepare SQL statement
-	query := "SELECT * FROM urls WHERE user_id = ?"
-	rows, err := r.DB.Query(query, userID)
+	query := "SELECT " + urlColumns + " FROM urls WHERE user_id = ? AND workspace_id IS NULL"
+	return r.queryURLs(query, userID)
+}
+
+// IterateUserURLs calls fn with each personal URL of the user, reading one row at a time
+// so that the URLs are never all held in memory. It stops at the first error returned by fn.
+func (r *DBURLRepository) IterateUserURLs(userID uint, fn func(*url_model.URL) error) error {
+	rows, err := r.DB.Query("SELECT "+urlColumns+" FROM urls WHERE user_id = ? AND workspace_id IS NULL ORDER BY created_at", userID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	// Initialize a slice to store the result
-	var urls []url_model.URL
-
-	// Iterate through the rows and scan the result into URL objects
 	for rows.Next() {
-		var u url_model.URL
-		err := rows.Scan(&u.OriginalURL, &u.ShortenedURL, &u.UserID, &u.CreatedAt)
+		u, err := scanURL(rows)
 		if err != nil {
+			return err
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// FindUserURLs retrieves the personal URLs of the user matching the filter, oldest first, with their tags.
+func (r *DBURLRepository) FindUserURLs(userID uint, filter url_model.Filter) ([]url_model.URL, error) {
+	query := "SELECT " + urlColumns + " FROM urls WHERE user_id = ? AND workspace_id IS NULL"
+	args := []interface{}{userID}
+	if filter.FolderID != nil {
+		query += " AND folder_id = ?"
+		args = append(args, *filter.FolderID)
+	}
+	if filter.Tag != "" {
+		query += " AND shortened_url IN (SELECT ut.url_id FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = ? AND t.name = ?)"
+		args = append(args, userID, filter.Tag)
+	}
+	urls, err := r.queryURLs(query+" ORDER BY created_at", args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return []url_model.URL{}, nil
+	}
+
+	// Tags of all links of the user are read at once rather than once per link
+	rows, err := r.DB.Query("SELECT ut.url_id, t.name FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = ? ORDER BY t.name", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var shortCode, name string
+		if err := rows.Scan(&shortCode, &name); err != nil {
 			return nil, err
 		}
-		urls = append(urls, u)
+		tags[shortCode] = append(tags[shortCode], name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
+	for i := range urls {
+		urls[i].Tags = tags[urls[i].ShortenedURL]
+	}
 	return urls, nil
 }
 
@@ -137,3 +275,597 @@ func (r *DBURLRepository) GetUserWithShortURL(userID uint, shortURL string) erro
 
 	return nil
 }
+
+// GetURL retrieves a URL record from the database by short code, including disabled ones.
+func (r *DBURLRepository) GetURL(shortCode string) (*url_model.URL, error) {
+	query := "SELECT " + urlColumns + " FROM urls WHERE shortened_url = ?"
+
+	u, err := scanURL(r.DB.QueryRow(query, shortCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, url_model.ErrURLNotFound
+		}
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// SetDisabled disables or re-enables the URL with the given short code.
+func (r *DBURLRepository) SetDisabled(shortCode string, disabled bool) error {
+	result, err := r.DB.Exec("UPDATE urls SET disabled = ? WHERE shortened_url = ?", disabled, shortCode)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the URL exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return url_model.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// CreateWorkspaceURL inserts a new URL record owned by a workspace into the database.
+func (r *DBURLRepository) CreateWorkspaceURL(originalURL, shortCode string, userID, workspaceID uint) (string, error) {
+	query := "INSERT INTO urls (original_url, shortened_url, user_id, workspace_id) VALUES (?, ?, ?, ?)"
+	_, err := r.DB.Exec(query, originalURL, shortCode, userID, workspaceID)
+	if err != nil {
+		return "", err
+	}
+
+	return shortCode, nil
+}
+
+// GetWorkspaceURLs retrieves the URLs of the given workspace.
+func (r *DBURLRepository) GetWorkspaceURLs(workspaceID uint) ([]url_model.URL, error) {
+	query := "SELECT " + urlColumns + " FROM urls WHERE workspace_id = ?"
+	return r.queryURLs(query, workspaceID)
+}
+
+// SetOwner moves the URL with the given short code to a user and workspace; a nil workspace makes it personal.
+func (r *DBURLRepository) SetOwner(shortCode string, userID uint, workspaceID *uint) error {
+	// Folders are personal, so the link leaves its folder
+	result, err := r.DB.Exec("UPDATE urls SET user_id = ?, workspace_id = ?, folder_id = NULL WHERE shortened_url = ?", userID, workspaceID, shortCode)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the URL exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return url_model.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// SetFolder moves a URL into a folder, or out of its folder when folderID is nil.
+func (r *DBURLRepository) SetFolder(shortCode string, folderID *uint) error {
+	result, err := r.DB.Exec("UPDATE urls SET folder_id = ? WHERE shortened_url = ?", folderID, shortCode)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the URL exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return url_model.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// CreateURLs inserts the URLs, their tags and import sources in a single transaction; nothing is inserted when one fails.
+func (r *DBURLRepository) CreateURLs(urls []url_model.NewURL) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, u := range urls {
+		_, err := tx.Exec("INSERT INTO urls (original_url, shortened_url, user_id, workspace_id, expires_at, imported_clicks, created_at) VALUES (?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))",
+			u.OriginalURL, u.ShortCode, u.UserID, u.WorkspaceID, u.ExpiresAt, u.ImportedClicks, u.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to insert %s: %w", u.ShortCode, err)
+		}
+
+		if u.ImportSource != "" {
+			if _, err := tx.Exec("INSERT INTO url_imports (user_id, source, url_id) VALUES (?, ?, ?)", u.UserID, u.ImportSource, u.ShortCode); err != nil {
+				return fmt.Errorf("failed to record import of %s: %w", u.ShortCode, err)
+			}
+		}
+
+		for _, tag := range u.Tags {
+			// Reuse the tag of the user with the same name, making its ID the last insert ID
+			result, err := tx.Exec("INSERT INTO tags (user_id, name) VALUES (?, ?) ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id)", u.UserID, tag)
+			if err != nil {
+				return fmt.Errorf("failed to insert tag %s: %w", tag, err)
+			}
+			tagID, err := result.LastInsertId()
+			if err != nil {
+				return err
+			}
+			if _, err := tx.Exec("INSERT IGNORE INTO url_tags (url_id, tag_id) VALUES (?, ?)", u.ShortCode, tagID); err != nil {
+				return fmt.Errorf("failed to tag %s: %w", u.ShortCode, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetImportSources retrieves the short codes of the links imported by the user, by import source.
+func (r *DBURLRepository) GetImportSources(userID uint) (map[string]string, error) {
+	rows, err := r.DB.Query("SELECT source, url_id FROM url_imports WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sources := make(map[string]string)
+	for rows.Next() {
+		var source, shortCode string
+		if err := rows.Scan(&source, &shortCode); err != nil {
+			return nil, err
+		}
+		sources[source] = shortCode
+	}
+	return sources, rows.Err()
+}
+
+// GetPasswordHash retrieves the password hash of the URL with the given short code, empty when it has none.
+func (r *DBURLRepository) GetPasswordHash(shortCode string) (string, error) {
+	var hash sql.NullString
+	err := r.DB.QueryRow("SELECT password_hash FROM urls WHERE shortened_url = ?", shortCode).Scan(&hash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", url_model.ErrURLNotFound
+		}
+		return "", err
+	}
+
+	return hash.String, nil
+}
+
+// SetPasswordHash sets the password hash of the URL with the given short code; a nil hash removes the password.
+func (r *DBURLRepository) SetPasswordHash(shortCode string, hash *string) error {
+	result, err := r.DB.Exec("UPDATE urls SET password_hash = ? WHERE shortened_url = ?", hash, shortCode)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the URL exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return url_model.ErrURLNotFound
+	}
+
+	return nil
+}
+
+// SetDetails sets the title and notes of the URL with the given short code; empty values clear them.
+// The caller checks the URL exists, since MySQL does not count rows updated with their current values.
+func (r *DBURLRepository) SetDetails(shortCode, title, notes string) error {
+	_, err := r.DB.Exec("UPDATE urls SET title = NULLIF(?, ''), notes = NULLIF(?, '') WHERE shortened_url = ?", title, notes, shortCode)
+	return err
+}
+
+// GetMetadata retrieves the page metadata fetched for the URL with the given short code.
+func (r *DBURLRepository) GetMetadata(shortCode string) (*url_model.Metadata, error) {
+	var m url_model.Metadata
+	err := r.DB.QueryRow("SELECT title, description, favicon_url, fetched_at FROM url_metadata WHERE url_id = ?", shortCode).
+		Scan(&m.Title, &m.Description, &m.FaviconURL, &m.FetchedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, url_model.ErrMetadataNotFound
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// SaveMetadata stores the page metadata of the URL with the given short code, replacing the previous one.
+// URLs without a title get the page title.
+func (r *DBURLRepository) SaveMetadata(shortCode string, metadata *url_model.Metadata) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("INSERT INTO url_metadata (url_id, title, description, favicon_url, fetched_at) VALUES (?, ?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE title = VALUES(title), description = VALUES(description), favicon_url = VALUES(favicon_url), fetched_at = VALUES(fetched_at)",
+		shortCode, metadata.Title, metadata.Description, metadata.FaviconURL, metadata.FetchedAt)
+	if err != nil {
+		return err
+	}
+
+	if metadata.Title != "" {
+		if _, err := tx.Exec("UPDATE urls SET title = ? WHERE shortened_url = ? AND title IS NULL", metadata.Title, shortCode); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetRules retrieves the redirect rules of the URL with the given short code, in order; nil when it has none.
+func (r *DBURLRepository) GetRules(shortCode string) ([]url_model.Rule, error) {
+	var encoded []byte
+	err := r.DB.QueryRow("SELECT rules FROM url_rules WHERE url_id = ?", shortCode).Scan(&encoded)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var rules []url_model.Rule
+	if err := json.Unmarshal(encoded, &rules); err != nil {
+		return nil, fmt.Errorf("decoding rules of %s: %w", shortCode, err)
+	}
+	return rules, nil
+}
+
+// SetRules replaces the redirect rules of the URL with the given short code; no rules removes them.
+func (r *DBURLRepository) SetRules(shortCode string, rules []url_model.Rule) error {
+	return setRules(r.DB, shortCode, rules)
+}
+
+func setRules(db execer, shortCode string, rules []url_model.Rule) error {
+	if len(rules) == 0 {
+		_, err := db.Exec("DELETE FROM url_rules WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("INSERT INTO url_rules (url_id, rules) VALUES (?, ?) ON DUPLICATE KEY UPDATE rules = VALUES(rules)", shortCode, encoded)
+	return err
+}
+
+// GetSplit retrieves the split of the URL with the given short code; nil when it has none.
+func (r *DBURLRepository) GetSplit(shortCode string) (*url_model.Split, error) {
+	var split url_model.Split
+	var variants []byte
+	err := r.DB.QueryRow("SELECT sticky, variants FROM url_splits WHERE url_id = ?", shortCode).Scan(&split.Sticky, &variants)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal(variants, &split.Variants); err != nil {
+		return nil, fmt.Errorf("decoding variants of %s: %w", shortCode, err)
+	}
+	return &split, nil
+}
+
+// SetSplit replaces the split of the URL with the given short code; nil removes it.
+func (r *DBURLRepository) SetSplit(shortCode string, split *url_model.Split) error {
+	if split == nil {
+		_, err := r.DB.Exec("DELETE FROM url_splits WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	variants, err := json.Marshal(split.Variants)
+	if err != nil {
+		return err
+	}
+	_, err = r.DB.Exec("INSERT INTO url_splits (url_id, sticky, variants) VALUES (?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE sticky = VALUES(sticky), variants = VALUES(variants)", shortCode, split.Sticky, variants)
+	return err
+}
+
+// GetTracking retrieves the query parameters added to the destination of the URL with the given short code;
+// nil when it adds none.
+func (r *DBURLRepository) GetTracking(shortCode string) (*url_model.Tracking, error) {
+	var t url_model.Tracking
+	err := r.DB.QueryRow("SELECT utm_source, utm_medium, utm_campaign, utm_term, utm_content, pass_query, query_conflict FROM url_tracking WHERE url_id = ?", shortCode).
+		Scan(&t.UTM.Source, &t.UTM.Medium, &t.UTM.Campaign, &t.UTM.Term, &t.UTM.Content, &t.PassQuery, &t.QueryConflict)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// SetTracking replaces the query parameters added to the destination of the URL with the given short code;
+// nil removes them.
+func (r *DBURLRepository) SetTracking(shortCode string, tracking *url_model.Tracking) error {
+	if tracking == nil {
+		_, err := r.DB.Exec("DELETE FROM url_tracking WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	utm := tracking.UTM
+	_, err := r.DB.Exec("INSERT INTO url_tracking (url_id, utm_source, utm_medium, utm_campaign, utm_term, utm_content, pass_query, query_conflict) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE utm_source = VALUES(utm_source), utm_medium = VALUES(utm_medium), "+
+		"utm_campaign = VALUES(utm_campaign), utm_term = VALUES(utm_term), utm_content = VALUES(utm_content), "+
+		"pass_query = VALUES(pass_query), query_conflict = VALUES(query_conflict)",
+		shortCode, utm.Source, utm.Medium, utm.Campaign, utm.Term, utm.Content, tracking.PassQuery, tracking.QueryConflict)
+	return err
+}
+
+// GetDeepLink retrieves the apps opened by the URL with the given short code; nil when it opens none.
+func (r *DBURLRepository) GetDeepLink(shortCode string) (*url_model.DeepLink, error) {
+	var d url_model.DeepLink
+	err := r.DB.QueryRow("SELECT ios_uri, ios_fallback, android_uri, android_fallback FROM url_deep_links WHERE url_id = ?", shortCode).
+		Scan(&d.IOS.URI, &d.IOS.Fallback, &d.Android.URI, &d.Android.Fallback)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+// SetDeepLink replaces the apps opened by the URL with the given short code; nil removes them.
+func (r *DBURLRepository) SetDeepLink(shortCode string, deepLink *url_model.DeepLink) error {
+	if deepLink == nil {
+		_, err := r.DB.Exec("DELETE FROM url_deep_links WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	_, err := r.DB.Exec("INSERT INTO url_deep_links (url_id, ios_uri, ios_fallback, android_uri, android_fallback) VALUES (?, ?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE ios_uri = VALUES(ios_uri), ios_fallback = VALUES(ios_fallback), "+
+		"android_uri = VALUES(android_uri), android_fallback = VALUES(android_fallback)",
+		shortCode, deepLink.IOS.URI, deepLink.IOS.Fallback, deepLink.Android.URI, deepLink.Android.Fallback)
+	return err
+}
+
+// GetPreview retrieves the preview of the URL with the given short code; nil when it has none.
+func (r *DBURLRepository) GetPreview(shortCode string) (*url_model.Preview, error) {
+	var p url_model.Preview
+	err := r.DB.QueryRow("SELECT title, description, image_url, interstitial, delay_seconds FROM url_previews WHERE url_id = ?", shortCode).
+		Scan(&p.Title, &p.Description, &p.Image, &p.Interstitial, &p.Delay)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// SetPreview replaces the preview of the URL with the given short code; nil removes it.
+func (r *DBURLRepository) SetPreview(shortCode string, preview *url_model.Preview) error {
+	if preview == nil {
+		_, err := r.DB.Exec("DELETE FROM url_previews WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	_, err := r.DB.Exec("INSERT INTO url_previews (url_id, title, description, image_url, interstitial, delay_seconds) VALUES (?, ?, ?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE title = VALUES(title), description = VALUES(description), image_url = VALUES(image_url), "+
+		"interstitial = VALUES(interstitial), delay_seconds = VALUES(delay_seconds)",
+		shortCode, preview.Title, preview.Description, preview.Image, preview.Interstitial, preview.Delay)
+	return err
+}
+
+// SetActivation sets when the URL with the given short code starts and stops resolving; nil times leave it
+// open on that side.
+func (r *DBURLRepository) SetActivation(shortCode string, activeFrom, activeUntil *time.Time) error {
+	_, err := r.DB.Exec("UPDATE urls SET active_from = ?, expires_at = ? WHERE shortened_url = ?", activeFrom, activeUntil, shortCode)
+	return err
+}
+
+// GetNotActiveResponse retrieves what visitors of the URL with the given short code get before it is active;
+// nil when they get the default response.
+func (r *DBURLRepository) GetNotActiveResponse(shortCode string) (*url_model.NotActiveResponse, error) {
+	var n url_model.NotActiveResponse
+	err := r.DB.QueryRow("SELECT mode, fallback_url, message FROM url_not_active_responses WHERE url_id = ?", shortCode).
+		Scan(&n.Mode, &n.URL, &n.Message)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// SetNotActiveResponse replaces what visitors of the URL with the given short code get before it is active;
+// nil restores the default response.
+func (r *DBURLRepository) SetNotActiveResponse(shortCode string, response *url_model.NotActiveResponse) error {
+	if response == nil {
+		_, err := r.DB.Exec("DELETE FROM url_not_active_responses WHERE url_id = ?", shortCode)
+		return err
+	}
+
+	_, err := r.DB.Exec("INSERT INTO url_not_active_responses (url_id, mode, fallback_url, message) VALUES (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE mode = VALUES(mode), fallback_url = VALUES(fallback_url), message = VALUES(message)",
+		shortCode, response.Mode, response.URL, response.Message)
+	return err
+}
+
+// GetRedirectVersion retrieves the redirect type and the latest revision of the URL with the given short code;
+// the revision is 0 when the URL was never changed.
+func (r *DBURLRepository) GetRedirectVersion(shortCode string) (int, uint, error) {
+	var redirectType int
+	var revision sql.NullInt64
+	err := r.DB.QueryRow("SELECT u.redirect_type, (SELECT MAX(r.id) FROM link_revisions r WHERE r.url_id = u.shortened_url) "+
+		"FROM urls u WHERE u.shortened_url = ?", shortCode).Scan(&redirectType, &revision)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, 0, url_model.ErrURLNotFound
+		}
+		return 0, 0, err
+	}
+
+	return redirectType, uint(revision.Int64), nil
+}
+
+// GetRevisions retrieves the revisions of the URL with the given short code, newest first.
+func (r *DBURLRepository) GetRevisions(shortCode string) ([]url_model.Revision, error) {
+	rows, err := r.DB.Query("SELECT id, destination, previous_destination, redirect_type, rules, changed_by, restored_from, created_at "+
+		"FROM link_revisions WHERE url_id = ? ORDER BY id DESC", shortCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]url_model.Revision, 0)
+	for rows.Next() {
+		var revision url_model.Revision
+		var rules []byte
+		var changedBy, restoredFrom sql.NullInt64
+		if err := rows.Scan(&revision.ID, &revision.Destination, &revision.PreviousDestination, &revision.RedirectType, &rules,
+			&changedBy, &restoredFrom, &revision.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rules, &revision.Rules); err != nil {
+			return nil, fmt.Errorf("decoding rules of revision %d of %s: %w", revision.ID, shortCode, err)
+		}
+		if changedBy.Valid {
+			id := uint(changedBy.Int64)
+			revision.ChangedBy = &id
+		}
+		if restoredFrom.Valid {
+			id := uint(restoredFrom.Int64)
+			revision.RestoredFrom = &id
+		}
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+// Revise changes the original URL and the redirect type of the URL with the given short code, and its redirect
+// rules when withRules is set, to those of the last of the revisions, and records the revisions, setting their
+// IDs, in a single transaction.
+func (r *DBURLRepository) Revise(shortCode string, revisions []*url_model.Revision, withRules bool) error {
+	if len(revisions) == 0 {
+		return nil
+	}
+	latest := revisions[len(revisions)-1]
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE urls SET original_url = ?, redirect_type = ? WHERE shortened_url = ?", latest.Destination, latest.RedirectType, shortCode); err != nil {
+		return err
+	}
+	if withRules {
+		if err := setRules(tx, shortCode, latest.Rules); err != nil {
+			return err
+		}
+	}
+	for _, revision := range revisions {
+		if err := addRevision(tx, shortCode, revision); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// addRevision records a revision of the URL with the given short code, setting its ID.
+func addRevision(db execer, shortCode string, revision *url_model.Revision) error {
+	rules := revision.Rules
+	if rules == nil {
+		rules = []url_model.Rule{}
+	}
+	encoded, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	result, err := db.Exec("INSERT INTO link_revisions (url_id, destination, previous_destination, redirect_type, rules, changed_by, restored_from, created_at) "+
+		"VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		shortCode, revision.Destination, revision.PreviousDestination, revision.RedirectType, encoded, revision.ChangedBy, revision.RestoredFrom, revision.CreatedAt)
+	if err != nil {
+		return err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	revision.ID = uint(id)
+	return nil
+}
+
+// linkTables lists the tables holding data of a URL in their url_id column, deleted with it.
+var linkTables = []string{
+	"clicks", "url_tags", "url_imports", "url_metadata", "url_rules", "url_splits", "url_tracking",
+	"url_deep_links", "url_previews", "url_not_active_responses", "link_revisions", "domain_links",
+}
+
+// DeleteURL deletes the URL with the given short code with its clicks, settings and history in a single transaction.
+func (r *DBURLRepository) DeleteURL(shortCode string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range linkTables {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE url_id = ?", shortCode); err != nil {
+			return err
+		}
+	}
+	result, err := tx.Exec("DELETE FROM urls WHERE shortened_url = ?", shortCode)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return url_model.ErrURLNotFound
+	}
+
+	return tx.Commit()
+}
+
+// queryURLs runs a query selecting urlColumns and scans every row.
+func (r *DBURLRepository) queryURLs(query string, args ...interface{}) ([]url_model.URL, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Initialize a slice to store the result
+	var urls []url_model.URL
+
+	// Iterate through the rows and scan the result into URL objects
+	for rows.Next() {
+		u, err := scanURL(rows)
+		if err != nil {
+			return nil, err
+		}
+		urls = append(urls, *u)
+	}
+
+	return urls, rows.Err()
+}
@@ -115,10 +115,10 @@ func TestDBURLRepository_GetOriginalURL(t *testing.T) {
 		shortCode := "abc123"
 		originalURL := "https://www.example.com"
 
-		rows := sqlmock.NewRows([]string{"original_url"}).
-			AddRow(originalURL)
+		rows := sqlmock.NewRows([]string{"original_url", "disabled", "expired", "pending"}).
+			AddRow(originalURL, false, false, false)
 
-		mock.ExpectQuery("SELECT original_url FROM urls").
+		mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls").
 			WithArgs(shortCode).
 			WillReturnRows(rows)
 
@@ -143,7 +143,7 @@ func TestDBURLRepository_GetOriginalURL(t *testing.T) {
 	t.Run("URL Not Found", func(t *testing.T) {
 		shortCode := "abc123"
 
-		mock.ExpectQuery("SELECT original_url FROM urls").
+		mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls").
 			WithArgs(shortCode).
 			WillReturnError(errors.New("no rows found"))
 
@@ -156,7 +156,7 @@ func TestDBURLRepository_GetOriginalURL(t *testing.T) {
 	t.Run("Failed to Execute SQL Statement", func(t *testing.T) {
 		shortCode := "abc123"
 
-		mock.ExpectQuery("SELECT original_url FROM urls").
+		mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls").
 			WithArgs(shortCode).
 			WillReturnError(errors.New("execute error"))
 
@@ -169,9 +169,9 @@ func TestDBURLRepository_GetOriginalURL(t *testing.T) {
 	t.Run("No Rows Returned", func(t *testing.T) {
 		shortCode := "abc123"
 
-		mock.ExpectQuery("SELECT original_url FROM urls").
+		mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls").
 			WithArgs(shortCode).
-			WillReturnRows(sqlmock.NewRows([]string{"original_url"}))
+			WillReturnRows(sqlmock.NewRows([]string{"original_url", "disabled", "expired", "pending"}))
 
 		url, err := repo.GetOriginalURL(shortCode)
 
@@ -181,6 +181,63 @@ func TestDBURLRepository_GetOriginalURL(t *testing.T) {
 
 }
 
+func TestDBURLRepository_GetOriginalURL_Disabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls WHERE shortened_url = ?").
+		WithArgs("abc123").
+		WillReturnRows(sqlmock.NewRows([]string{"original_url", "disabled", "expired", "pending"}).AddRow("https://www.example.com", true, false, false))
+
+	url, err := repo.GetOriginalURL("abc123")
+
+	assert.ErrorIs(t, err, url_model.ErrURLDisabled)
+	assert.Empty(t, url)
+}
+
+func TestDBURLRepository_GetOriginalURL_Expired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls WHERE shortened_url = ?").
+		WithArgs("abc123").
+		WillReturnRows(sqlmock.NewRows([]string{"original_url", "disabled", "expired", "pending"}).AddRow("https://www.example.com", false, true, false))
+
+	url, err := repo.GetOriginalURL("abc123")
+
+	assert.ErrorIs(t, err, url_model.ErrURLExpired)
+	assert.Empty(t, url)
+}
+
+func TestDBURLRepository_GetOriginalURL_NotActive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	mock.ExpectQuery("SELECT original_url, disabled, expires_at .*, active_from .* FROM urls WHERE shortened_url = ?").
+		WithArgs("abc123").
+		WillReturnRows(sqlmock.NewRows([]string{"original_url", "disabled", "expired", "pending"}).AddRow("https://www.example.com", false, false, true))
+
+	url, err := repo.GetOriginalURL("abc123")
+
+	assert.ErrorIs(t, err, url_model.ErrURLNotActive)
+	assert.Empty(t, url)
+}
+
 func TestDBURLRepository_GetOriginalURL_ErrorNoRows(t *testing.T) {
 	// Create a new mock database connection
 	db, mock, err := sqlmock.New()
@@ -193,7 +250,7 @@ func TestDBURLRepository_GetOriginalURL_ErrorNoRows(t *testing.T) {
 	repo := NewDBURLRepository(db)
 
 	// Prepare the mock query and result
-	mock.ExpectQuery("SELECT original_url FROM urls WHERE shortened_url = ?").
+	mock.ExpectQuery("SELECT original_url, disabled, expires_at .* FROM urls WHERE shortened_url = ?").
 		WithArgs("nonexistent").
 		WillReturnError(sql.ErrNoRows)
 
@@ -316,7 +373,7 @@ func TestDBURLREPOSITORY_GetUserURLScanError(t *testing.T) {
 	repo := NewDBURLRepository(db)
 
 	// Prepare the mock query and result
-	mock.ExpectQuery("SELECT \\* FROM urls WHERE user_id = \\?").
+	mock.ExpectQuery("SELECT original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type FROM urls WHERE user_id = \\? AND workspace_id IS NULL").
 		WithArgs(uint(1)).
 		WillReturnRows(sqlmock.NewRows([]string{"original_url", "shortened_url"}).AddRow("http://example.com", "http://short.com"))
 
@@ -342,12 +399,12 @@ func TestReturnSuccessfulUrlReturn(t *testing.T) {
 
 		// Define the expected SQL query and results
 		expectedUserID := uint(1)
-		expectedRows := sqlmock.NewRows([]string{"original_url", "shortened_url", "user_id", "created_at"}).
-			AddRow("http://example.com", "http://short.com", expectedUserID, time.Now()).
-			AddRow("http://example2.com", "http://short2.com", expectedUserID, time.Now())
+		expectedRows := sqlmock.NewRows([]string{"original_url", "shortened_url", "user_id", "workspace_id", "disabled", "expires_at", "imported_clicks", "created_at", "folder_id", "title", "notes", "active_from", "redirect_type"}).
+			AddRow("http://example.com", "http://short.com", expectedUserID, nil, false, nil, 0, time.Now(), nil, nil, nil, nil, 301).
+			AddRow("http://example2.com", "http://short2.com", expectedUserID, nil, true, nil, 0, time.Now(), nil, nil, nil, nil, 301)
 
 		// Expect the query with the given user ID
-		mock.ExpectQuery("SELECT \\* FROM urls WHERE user_id = \\?").WithArgs(expectedUserID).WillReturnRows(expectedRows)
+		mock.ExpectQuery("SELECT original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type FROM urls WHERE user_id = \\? AND workspace_id IS NULL").WithArgs(expectedUserID).WillReturnRows(expectedRows)
 
 		// Call the function to be tested
 		urls, err := repo.GetUserURLs(expectedUserID)
@@ -386,7 +443,7 @@ func TestReturnSuccessfulUrlReturn(t *testing.T) {
 			AddRow(2, "http://example2.com", "http://short2.com").
 			RowError(0, fmt.Errorf("error scanning row"))
 
-		mock.ExpectQuery("SELECT \\* FROM urls WHERE user_id = \\?").WithArgs(expectedUserID).WillReturnRows(mockRows).WillReturnError(fmt.Errorf("error"))
+		mock.ExpectQuery("SELECT original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type FROM urls WHERE user_id = \\? AND workspace_id IS NULL").WithArgs(expectedUserID).WillReturnRows(mockRows).WillReturnError(fmt.Errorf("error"))
 
 		// Call the function to be tested
 		urls, err := repo.GetUserURLs(expectedUserID)
@@ -522,3 +579,1000 @@ func TestGetUserWithShortUrlSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet(), "there were unfulfilled expectations")
 }
+
+func TestDBURLRepository_GetURL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	columns := []string{"original_url", "shortened_url", "user_id", "workspace_id", "disabled", "expires_at", "imported_clicks", "created_at", "folder_id", "title", "notes", "active_from", "redirect_type"}
+
+	t.Run("Get URL Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type FROM urls WHERE shortened_url = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("https://www.example.com", "abc123", 1, nil, true, nil, 0, time.Now(), nil, nil, nil, nil, 301))
+
+		url, err := repo.GetURL("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), url.UserID)
+		assert.Nil(t, url.WorkspaceID)
+		assert.True(t, url.Disabled)
+	})
+
+	t.Run("Get Workspace URL", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE shortened_url = \\?").
+			WithArgs("team").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("https://www.example.com", "team", 1, 7, false, time.Now(), 0, time.Now(), 3, "Team docs", "Shared with support", nil, 301))
+
+		url, err := repo.GetURL("team")
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), *url.WorkspaceID)
+		assert.NotNil(t, url.ExpiresAt)
+		assert.Equal(t, uint(3), *url.FolderID)
+		assert.Equal(t, "Team docs", url.Title)
+		assert.Equal(t, "Shared with support", url.Notes)
+	})
+
+	t.Run("Get Anonymous URL", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE shortened_url = \\?").
+			WithArgs("anon").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("https://www.example.com", "anon", nil, nil, false, nil, 0, time.Now(), nil, nil, nil, nil, 301))
+
+		url, err := repo.GetURL("anon")
+
+		assert.NoError(t, err)
+		assert.Zero(t, url.UserID)
+	})
+
+	t.Run("URL Not Found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE shortened_url = \\?").
+			WithArgs("missing").
+			WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetURL("missing")
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+}
+
+func TestDBURLRepository_SetDisabled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	t.Run("Disable URL Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET disabled = \\? WHERE shortened_url = \\?").
+			WithArgs(true, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetDisabled("abc123", true))
+	})
+
+	t.Run("URL Not Found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET disabled").
+			WithArgs(true, "missing").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetDisabled("missing", true), url_model.ErrURLNotFound)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET disabled").
+			WithArgs(false, "abc123").
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.SetDisabled("abc123", false))
+	})
+}
+
+func TestDBURLRepository_Workspace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	workspaceID := uint(7)
+
+	t.Run("Create Workspace URL Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO urls \\(original_url, shortened_url, user_id, workspace_id\\) VALUES \\(\\?, \\?, \\?, \\?\\)").
+			WithArgs("https://www.example.com", "team", 1, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		shortCode, err := repo.CreateWorkspaceURL("https://www.example.com", "team", 1, 7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "team", shortCode)
+	})
+
+	t.Run("Failed to Create Workspace URL", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO urls").WillReturnError(errors.New("insert error"))
+
+		_, err := repo.CreateWorkspaceURL("https://www.example.com", "team", 1, 7)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Get Workspace URLs Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT original_url, shortened_url, user_id, workspace_id, disabled, expires_at, imported_clicks, created_at, folder_id, title, notes, active_from, redirect_type FROM urls WHERE workspace_id = \\?").
+			WithArgs(workspaceID).
+			WillReturnRows(sqlmock.NewRows([]string{"original_url", "shortened_url", "user_id", "workspace_id", "disabled", "expires_at", "imported_clicks", "created_at", "folder_id", "title", "notes", "active_from", "redirect_type"}).
+				AddRow("https://www.example.com", "team", 1, 7, false, nil, 0, time.Now(), nil, nil, nil, nil, 301))
+
+		urls, err := repo.GetWorkspaceURLs(workspaceID)
+
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+		assert.Equal(t, workspaceID, *urls[0].WorkspaceID)
+	})
+
+	t.Run("Set Owner Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET user_id = \\?, workspace_id = \\?, folder_id = NULL WHERE shortened_url = \\?").
+			WithArgs(2, &workspaceID, "team").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetOwner("team", 2, &workspaceID))
+	})
+
+	t.Run("Failed to Set Owner of Missing URL", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET user_id").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetOwner("missing", 2, nil), url_model.ErrURLNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBURLRepository_PasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	t.Run("Get Password Hash Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT password_hash FROM urls WHERE shortened_url = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow("hash"))
+
+		hash, err := repo.GetPasswordHash("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "hash", hash)
+	})
+
+	t.Run("Get Empty Hash For Unprotected URL", func(t *testing.T) {
+		mock.ExpectQuery("SELECT password_hash FROM urls").
+			WithArgs("open").
+			WillReturnRows(sqlmock.NewRows([]string{"password_hash"}).AddRow(nil))
+
+		hash, err := repo.GetPasswordHash("open")
+
+		assert.NoError(t, err)
+		assert.Empty(t, hash)
+	})
+
+	t.Run("URL Not Found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT password_hash FROM urls").
+			WithArgs("missing").
+			WillReturnRows(sqlmock.NewRows([]string{"password_hash"}))
+
+		_, err := repo.GetPasswordHash("missing")
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Set Password Hash Successfully", func(t *testing.T) {
+		hash := "hash"
+		mock.ExpectExec("UPDATE urls SET password_hash = \\? WHERE shortened_url = \\?").
+			WithArgs(&hash, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetPasswordHash("abc123", &hash))
+	})
+
+	t.Run("Set Password Hash Of Missing URL", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET password_hash").
+			WithArgs(nil, "missing").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetPasswordHash("missing", nil), url_model.ErrURLNotFound)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET password_hash").
+			WithArgs(nil, "abc123").
+			WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.SetPasswordHash("abc123", nil))
+	})
+}
+
+func TestDBURLRepository_CreateURLs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	expiresAt := time.Now().Add(time.Hour)
+	createdAt := time.Now().Add(-time.Hour)
+	urls := []url_model.NewURL{
+		{OriginalURL: "https://www.example.com", ShortCode: "first", UserID: 1, ExpiresAt: &expiresAt, Tags: []string{"docs"}},
+		{OriginalURL: "https://www.example.org", ShortCode: "second", UserID: 1, CreatedAt: &createdAt, ImportedClicks: 42, ImportSource: "second"},
+	}
+
+	t.Run("Create URLs Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO urls").
+			WithArgs("https://www.example.com", "first", uint(1), nil, &expiresAt, 0, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO tags").
+			WithArgs(uint(1), "docs").
+			WillReturnResult(sqlmock.NewResult(5, 1))
+		mock.ExpectExec("INSERT IGNORE INTO url_tags").
+			WithArgs("first", int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO urls").
+			WithArgs("https://www.example.org", "second", uint(1), nil, nil, 42, &createdAt).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectExec("INSERT INTO url_imports").
+			WithArgs(uint(1), "second", "second").
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.CreateURLs(urls))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Roll Back When One Insert Fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO urls").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("INSERT INTO tags").
+			WillReturnResult(sqlmock.NewResult(5, 1))
+		mock.ExpectExec("INSERT IGNORE INTO url_tags").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO urls").
+			WillReturnError(errors.New("duplicate entry"))
+		mock.ExpectRollback()
+
+		err := repo.CreateURLs(urls)
+
+		assert.ErrorContains(t, err, "second")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed to Begin Transaction", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(errors.New("begin error"))
+
+		assert.Error(t, repo.CreateURLs(urls))
+	})
+}
+
+func TestDBURLRepository_GetImportSources(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	t.Run("Get Import Sources Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT source, url_id FROM url_imports WHERE user_id = \\?").
+			WithArgs(uint(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"source", "url_id"}).AddRow("abc", "abc").AddRow("taken", "x1y2z3"))
+
+		sources, err := repo.GetImportSources(1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"abc": "abc", "taken": "x1y2z3"}, sources)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT source, url_id FROM url_imports").
+			WillReturnError(errors.New("query error"))
+
+		_, err := repo.GetImportSources(1)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBURLRepository_IterateUserURLs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	columns := []string{"original_url", "shortened_url", "user_id", "workspace_id", "disabled", "expires_at", "imported_clicks", "created_at", "folder_id", "title", "notes", "active_from", "redirect_type"}
+
+	t.Run("Iterate URLs Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE user_id = \\? AND workspace_id IS NULL ORDER BY created_at").
+			WithArgs(uint(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("https://www.example.com", "abc123", 1, nil, false, nil, 0, time.Now(), nil, nil, nil, nil, 301).
+				AddRow("https://www.example.org", "def456", 1, nil, true, nil, 3, time.Now(), nil, nil, nil, nil, 301))
+
+		var codes []string
+		err := repo.IterateUserURLs(1, func(u *url_model.URL) error {
+			codes = append(codes, u.ShortenedURL)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"abc123", "def456"}, codes)
+	})
+
+	t.Run("Stop on Callback Error", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls").
+			WithArgs(uint(1)).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("https://www.example.com", "abc123", 1, nil, false, nil, 0, time.Now(), nil, nil, nil, nil, 301).
+				AddRow("https://www.example.org", "def456", 1, nil, true, nil, 3, time.Now(), nil, nil, nil, nil, 301))
+
+		calls := 0
+		err := repo.IterateUserURLs(1, func(u *url_model.URL) error {
+			calls++
+			return errors.New("write error")
+		})
+
+		assert.EqualError(t, err, "write error")
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls").
+			WillReturnError(errors.New("query error"))
+
+		err := repo.IterateUserURLs(1, func(u *url_model.URL) error { return nil })
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBURLRepository_FindUserURLs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	columns := []string{"original_url", "shortened_url", "user_id", "workspace_id", "disabled", "expires_at", "imported_clicks", "created_at", "folder_id", "title", "notes", "active_from", "redirect_type"}
+	folderID := uint(3)
+
+	t.Run("Find URLs with Tags", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE user_id = \\? AND workspace_id IS NULL AND folder_id = \\? AND shortened_url IN \\(SELECT ut.url_id FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = \\? AND t.name = \\?\\) ORDER BY created_at").
+			WithArgs(uint(1), folderID, uint(1), "docs").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow("https://www.example.com", "abc123", 1, nil, false, nil, 0, time.Now(), 3, nil, nil, nil, 301).
+				AddRow("https://www.example.org", "def456", 1, nil, false, nil, 0, time.Now(), 3, nil, nil, nil, 301))
+		mock.ExpectQuery("SELECT ut.url_id, t.name FROM url_tags ut JOIN tags t ON t.id = ut.tag_id WHERE t.user_id = \\?").
+			WithArgs(uint(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"url_id", "name"}).AddRow("abc123", "docs").AddRow("abc123", "team").AddRow("def456", "docs"))
+
+		urls, err := repo.FindUserURLs(1, url_model.Filter{Tag: "docs", FolderID: &folderID})
+
+		assert.NoError(t, err)
+		assert.Len(t, urls, 2)
+		assert.Equal(t, []string{"docs", "team"}, urls[0].Tags)
+		assert.Equal(t, []string{"docs"}, urls[1].Tags)
+		assert.Equal(t, folderID, *urls[0].FolderID)
+	})
+
+	t.Run("Find No URLs", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls WHERE user_id = \\? AND workspace_id IS NULL ORDER BY created_at").
+			WithArgs(uint(1)).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		urls, err := repo.FindUserURLs(1, url_model.Filter{})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, urls)
+		assert.Empty(t, urls)
+	})
+
+	t.Run("Failed on Tags Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM urls").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("https://www.example.com", "abc123", 1, nil, false, nil, 0, time.Now(), nil, nil, nil, nil, 301))
+		mock.ExpectQuery("SELECT ut.url_id, t.name FROM url_tags").
+			WillReturnError(errors.New("query error"))
+
+		_, err := repo.FindUserURLs(1, url_model.Filter{})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBURLRepository_SetFolder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	folderID := uint(3)
+
+	t.Run("Set Folder Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET folder_id = \\? WHERE shortened_url = \\?").
+			WithArgs(&folderID, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetFolder("abc123", &folderID))
+	})
+
+	t.Run("Return Not Found", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET folder_id").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetFolder("missing", nil), url_model.ErrURLNotFound)
+	})
+}
+
+func TestDBURLRepository_SetDetails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	mock.ExpectExec("UPDATE urls SET title = NULLIF\\(\\?, ''\\), notes = NULLIF\\(\\?, ''\\) WHERE shortened_url = \\?").
+		WithArgs("Docs", "", "abc123").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.SetDetails("abc123", "Docs", ""))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBURLRepository_Metadata(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	fetchedAt := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	metadata := &url_model.Metadata{Title: "Example", Description: "An example page", FaviconURL: "https://www.example.com/favicon.ico", FetchedAt: fetchedAt}
+
+	t.Run("Get Metadata Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT title, description, favicon_url, fetched_at FROM url_metadata WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "favicon_url", "fetched_at"}).
+				AddRow("Example", "An example page", "https://www.example.com/favicon.ico", fetchedAt))
+
+		found, err := repo.GetMetadata("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, metadata, found)
+	})
+
+	t.Run("Return Not Found", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM url_metadata").
+			WillReturnRows(sqlmock.NewRows([]string{"title", "description", "favicon_url", "fetched_at"}))
+
+		_, err := repo.GetMetadata("missing")
+		assert.ErrorIs(t, err, url_model.ErrMetadataNotFound)
+	})
+
+	t.Run("Save Metadata and Default Title", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO url_metadata \\(url_id, title, description, favicon_url, fetched_at\\) VALUES (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "Example", "An example page", "https://www.example.com/favicon.ico", fetchedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE urls SET title = \\? WHERE shortened_url = \\? AND title IS NULL").
+			WithArgs("Example", "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.SaveMetadata("abc123", metadata))
+	})
+
+	t.Run("Save Metadata Without Title", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO url_metadata").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.SaveMetadata("abc123", &url_model.Metadata{FetchedAt: fetchedAt}))
+	})
+
+	t.Run("Roll Back on Error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO url_metadata").
+			WillReturnError(fmt.Errorf("insert error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.SaveMetadata("abc123", metadata))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Rules(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	rules := []url_model.Rule{{Name: "iOS", Conditions: url_model.RuleConditions{Devices: []string{"ios"}}, Destination: "https://apps.apple.com"}}
+	encoded := `[{"name":"iOS","if":{"devices":["ios"]},"destination":"https://apps.apple.com"}]`
+
+	t.Run("Get Rules Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT rules FROM url_rules WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"rules"}).AddRow([]byte(encoded)))
+
+		found, err := repo.GetRules("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, rules, found)
+	})
+
+	t.Run("Return No Rules", func(t *testing.T) {
+		mock.ExpectQuery("SELECT rules FROM url_rules").
+			WillReturnRows(sqlmock.NewRows([]string{"rules"}))
+
+		found, err := repo.GetRules("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Fail on Invalid JSON", func(t *testing.T) {
+		mock.ExpectQuery("SELECT rules FROM url_rules").
+			WillReturnRows(sqlmock.NewRows([]string{"rules"}).AddRow([]byte("{")))
+
+		_, err := repo.GetRules("abc123")
+		assert.Error(t, err)
+	})
+
+	t.Run("Set Rules Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_rules \\(url_id, rules\\) VALUES \\(\\?, \\?\\) ON DUPLICATE KEY UPDATE rules = VALUES\\(rules\\)").
+			WithArgs("abc123", []byte(encoded)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetRules("abc123", rules))
+	})
+
+	t.Run("Remove Rules", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_rules WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetRules("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Split(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	split := &url_model.Split{Sticky: url_model.StickyIP, Variants: []url_model.Variant{
+		{Name: "a", Destination: "https://www.example.com/a", Weight: 3},
+		{Name: "b", Destination: "https://www.example.com/b", Weight: 1},
+	}}
+	encoded := `[{"name":"a","destination":"https://www.example.com/a","weight":3},{"name":"b","destination":"https://www.example.com/b","weight":1}]`
+
+	t.Run("Get Split Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT sticky, variants FROM url_splits WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"sticky", "variants"}).AddRow("ip", []byte(encoded)))
+
+		found, err := repo.GetSplit("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, split, found)
+	})
+
+	t.Run("Return No Split", func(t *testing.T) {
+		mock.ExpectQuery("SELECT sticky, variants FROM url_splits").
+			WillReturnRows(sqlmock.NewRows([]string{"sticky", "variants"}))
+
+		found, err := repo.GetSplit("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Set Split Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_splits \\(url_id, sticky, variants\\) VALUES \\(\\?, \\?, \\?\\) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "ip", []byte(encoded)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetSplit("abc123", split))
+	})
+
+	t.Run("Remove Split", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_splits WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetSplit("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Tracking(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	tracking := &url_model.Tracking{UTM: url_model.UTM{Source: "newsletter", Campaign: "launch"}, PassQuery: true, QueryConflict: url_model.QueryKeep}
+	columns := []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "pass_query", "query_conflict"}
+
+	t.Run("Get Tracking Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT utm_source, utm_medium, utm_campaign, utm_term, utm_content, pass_query, query_conflict FROM url_tracking WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("newsletter", "", "launch", "", "", true, "keep"))
+
+		found, err := repo.GetTracking("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, tracking, found)
+	})
+
+	t.Run("Return No Tracking", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM url_tracking").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		found, err := repo.GetTracking("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Set Tracking Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_tracking (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "newsletter", "", "launch", "", "", true, "keep").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetTracking("abc123", tracking))
+	})
+
+	t.Run("Remove Tracking", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_tracking WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetTracking("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_DeepLink(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	deepLink := &url_model.DeepLink{IOS: url_model.AppTarget{URI: "shop://product/1", Fallback: "https://apps.apple.com/app/id1"}}
+	columns := []string{"ios_uri", "ios_fallback", "android_uri", "android_fallback"}
+
+	t.Run("Get Deep Link Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT ios_uri, ios_fallback, android_uri, android_fallback FROM url_deep_links WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("shop://product/1", "https://apps.apple.com/app/id1", "", ""))
+
+		found, err := repo.GetDeepLink("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, deepLink, found)
+	})
+
+	t.Run("Return No Deep Link", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM url_deep_links").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		found, err := repo.GetDeepLink("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Set Deep Link Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_deep_links (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "shop://product/1", "https://apps.apple.com/app/id1", "", "").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetDeepLink("abc123", deepLink))
+	})
+
+	t.Run("Remove Deep Link", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_deep_links WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetDeepLink("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Preview(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	preview := &url_model.Preview{Title: "Launch", Image: "https://www.example.com/card.png", Interstitial: true, Delay: 5}
+	columns := []string{"title", "description", "image_url", "interstitial", "delay_seconds"}
+
+	t.Run("Get Preview Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT title, description, image_url, interstitial, delay_seconds FROM url_previews WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("Launch", "", "https://www.example.com/card.png", true, 5))
+
+		found, err := repo.GetPreview("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, preview, found)
+	})
+
+	t.Run("Return No Preview", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM url_previews").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		found, err := repo.GetPreview("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Set Preview Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_previews (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "Launch", "", "https://www.example.com/card.png", true, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetPreview("abc123", preview))
+	})
+
+	t.Run("Remove Preview", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_previews WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetPreview("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Activation(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	activeFrom := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	response := &url_model.NotActiveResponse{Mode: url_model.NotActivePage, Message: "Coming soon"}
+
+	t.Run("Set Activation Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE urls SET active_from = \\?, expires_at = \\? WHERE shortened_url = \\?").
+			WithArgs(&activeFrom, nil, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetActivation("abc123", &activeFrom, nil))
+	})
+
+	t.Run("Get Not Active Response Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT mode, fallback_url, message FROM url_not_active_responses WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"mode", "fallback_url", "message"}).AddRow("page", "", "Coming soon"))
+
+		found, err := repo.GetNotActiveResponse("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, response, found)
+	})
+
+	t.Run("Return No Not Active Response", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM url_not_active_responses").
+			WillReturnRows(sqlmock.NewRows([]string{"mode", "fallback_url", "message"}))
+
+		found, err := repo.GetNotActiveResponse("abc123")
+
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Set Not Active Response Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO url_not_active_responses (.+) ON DUPLICATE KEY UPDATE").
+			WithArgs("abc123", "page", "", "Coming soon").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetNotActiveResponse("abc123", response))
+	})
+
+	t.Run("Remove Not Active Response", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM url_not_active_responses WHERE url_id = \\?").
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetNotActiveResponse("abc123", nil))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_Revisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+	changedBy := uint(1)
+	createdAt := time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)
+	revision := url_model.Revision{
+		Destination:         "https://www.example.com/v2",
+		PreviousDestination: "https://www.example.com/v1",
+		RedirectType:        302,
+		Rules:               []url_model.Rule{{Conditions: url_model.RuleConditions{Devices: []string{"ios"}}, Destination: "https://www.example.com/ios"}},
+		ChangedBy:           &changedBy,
+		CreatedAt:           createdAt,
+	}
+	columns := []string{"id", "destination", "previous_destination", "redirect_type", "rules", "changed_by", "restored_from", "created_at"}
+
+	t.Run("Get Redirect Version Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT u.redirect_type, \\(SELECT MAX\\(r.id\\) FROM link_revisions r (.+)\\) FROM urls u WHERE u.shortened_url = \\?").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"redirect_type", "revision"}).AddRow(302, nil))
+
+		redirectType, latest, err := repo.GetRedirectVersion("abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 302, redirectType)
+		assert.Equal(t, uint(0), latest)
+	})
+
+	t.Run("Return URL Not Found For The Redirect Version", func(t *testing.T) {
+		mock.ExpectQuery("SELECT u.redirect_type").
+			WithArgs("missing").
+			WillReturnError(sql.ErrNoRows)
+
+		_, _, err := repo.GetRedirectVersion("missing")
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Revise Successfully", func(t *testing.T) {
+		first := url_model.Revision{Destination: "https://www.example.com/v1", RedirectType: 301, Rules: []url_model.Rule{}, ChangedBy: &changedBy, CreatedAt: createdAt}
+		added := revision
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE urls SET original_url = \\?, redirect_type = \\? WHERE shortened_url = \\?").
+			WithArgs("https://www.example.com/v2", 302, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO url_rules").
+			WithArgs("abc123", []byte(`[{"if":{"devices":["ios"]},"destination":"https://www.example.com/ios"}]`)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO link_revisions").
+			WithArgs("abc123", first.Destination, "", 301, []byte("[]"), &changedBy, nil, createdAt).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectExec("INSERT INTO link_revisions").
+			WithArgs("abc123", revision.Destination, revision.PreviousDestination, 302,
+				[]byte(`[{"if":{"devices":["ios"]},"destination":"https://www.example.com/ios"}]`), &changedBy, nil, createdAt).
+			WillReturnResult(sqlmock.NewResult(4, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Revise("abc123", []*url_model.Revision{&first, &added}, true))
+		assert.Equal(t, uint(3), first.ID)
+		assert.Equal(t, uint(4), added.ID)
+	})
+
+	t.Run("Roll Back The Change When A Revision Fails", func(t *testing.T) {
+		added := revision
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE urls SET original_url").
+			WithArgs("https://www.example.com/v2", 302, "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO link_revisions").WillReturnError(errors.New("insert error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.Revise("abc123", []*url_model.Revision{&added}, false))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Get Revisions Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM link_revisions WHERE url_id = \\? ORDER BY id DESC").
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(5, "https://www.example.com/v1", "https://www.example.com/v2", 301, []byte("[]"), 1, 3, createdAt).
+				AddRow(4, revision.Destination, revision.PreviousDestination, 302, []byte(`[{"if":{"devices":["ios"]},"destination":"https://www.example.com/ios"}]`), 1, nil, createdAt))
+
+		revisions, err := repo.GetRevisions("abc123")
+
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, uint(5), revisions[0].ID)
+		assert.Equal(t, []url_model.Rule{}, revisions[0].Rules)
+		assert.Equal(t, uint(3), *revisions[0].RestoredFrom)
+		revision.ID = 4
+		assert.Equal(t, revision, revisions[1])
+	})
+
+	t.Run("Fail On Invalid Rules", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM link_revisions").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(4, "", "", 301, []byte("{"), nil, nil, createdAt))
+
+		_, err := repo.GetRevisions("abc123")
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestDBURLRepository_DeleteURL(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBURLRepository(db)
+
+	t.Run("Delete URL Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		for _, table := range linkTables {
+			mock.ExpectExec("DELETE FROM " + table + " WHERE url_id = \\?").WithArgs("abc123").WillReturnResult(sqlmock.NewResult(0, 1))
+		}
+		mock.ExpectExec("DELETE FROM urls WHERE shortened_url = \\?").WithArgs("abc123").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.DeleteURL("abc123"))
+	})
+
+	t.Run("Return URL Not Found", func(t *testing.T) {
+		mock.ExpectBegin()
+		for _, table := range linkTables {
+			mock.ExpectExec("DELETE FROM " + table).WithArgs("missing").WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		mock.ExpectExec("DELETE FROM urls").WithArgs("missing").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.DeleteURL("missing"), url_model.ErrURLNotFound)
+	})
+
+	t.Run("Roll Back On Error", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM clicks").WithArgs("abc123").WillReturnError(errors.New("delete error"))
+		mock.ExpectRollback()
+
+		assert.Error(t, repo.DeleteURL("abc123"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,341 @@
+package webhook_repository
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"url-shortener/internal/app/models/webhook"
+)
+
+// Repository defines methods to interact with the webhook repository.
+type Repository interface {
+	List(userID uint) ([]webhook_model.Webhook, error)
+	GetByID(id uint) (*webhook_model.Webhook, error)
+	Subscribers(userID uint, workspaceID *uint) ([]webhook_model.Webhook, error)
+	Create(webhook *webhook_model.Webhook) (*webhook_model.Webhook, error)
+	Update(webhook *webhook_model.Webhook) error
+	Delete(id uint) error
+	CreateDelivery(delivery *webhook_model.Delivery) (*webhook_model.Delivery, error)
+	GetDelivery(id uint) (*webhook_model.Delivery, error)
+	ListDeliveries(webhookID uint, status string, limit int) ([]webhook_model.Delivery, error)
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]webhook_model.Delivery, error)
+	RecordAttempt(delivery *webhook_model.Delivery, attempt webhook_model.Attempt) error
+	ListAttempts(deliveryID uint) ([]webhook_model.Attempt, error)
+	Requeue(id uint, now time.Time) error
+}
+
+// DBWebhookRepository is an implementation of WebhookRepository for MySQL database.
+type DBWebhookRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBWebhookRepository creates a new instance of DBWebhookRepository.
+func NewDBWebhookRepository(db *sql.DB) *DBWebhookRepository {
+	return &DBWebhookRepository{DB: db}
+}
+
+// webhookQuery selects webhooks with the columns read by scanWebhook.
+const webhookQuery = "SELECT id, url, user_id, workspace_id, events, secret, active, created_at FROM webhooks"
+
+// deliveryQuery selects deliveries with the columns read by scanDelivery.
+const deliveryQuery = "SELECT id, webhook_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_status, error, created_at FROM webhook_deliveries"
+
+// List retrieves the personal webhooks of the user and the webhooks of the workspaces they own, by ID.
+func (r *DBWebhookRepository) List(userID uint) ([]webhook_model.Webhook, error) {
+	return r.list(webhookQuery+" WHERE (user_id = ? AND workspace_id IS NULL) OR workspace_id IN (SELECT workspace_id FROM workspace_members WHERE user_id = ? AND role = 'owner') ORDER BY id", userID, userID)
+}
+
+// GetByID retrieves a webhook by ID.
+func (r *DBWebhookRepository) GetByID(id uint) (*webhook_model.Webhook, error) {
+	webhook, err := scanWebhook(r.DB.QueryRow(webhookQuery+" WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook_model.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// Subscribers retrieves the active webhooks receiving the events of the links of the workspace, or of
+// the personal links of the user when the workspace is nil.
+func (r *DBWebhookRepository) Subscribers(userID uint, workspaceID *uint) ([]webhook_model.Webhook, error) {
+	if workspaceID != nil {
+		return r.list(webhookQuery+" WHERE workspace_id = ? AND active = TRUE ORDER BY id", *workspaceID)
+	}
+	return r.list(webhookQuery+" WHERE user_id = ? AND workspace_id IS NULL AND active = TRUE ORDER BY id", userID)
+}
+
+func (r *DBWebhookRepository) list(query string, args ...interface{}) ([]webhook_model.Webhook, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]webhook_model.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// scanWebhook reads a webhook selected with webhookQuery.
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*webhook_model.Webhook, error) {
+	var webhook webhook_model.Webhook
+	var workspaceID sql.NullInt64
+	var events string
+	if err := row.Scan(&webhook.ID, &webhook.URL, &webhook.UserID, &workspaceID, &events, &webhook.Secret, &webhook.Active, &webhook.CreatedAt); err != nil {
+		return nil, err
+	}
+	if workspaceID.Valid {
+		id := uint(workspaceID.Int64)
+		webhook.WorkspaceID = &id
+	}
+	webhook.Events = make([]string, 0)
+	if events != "" {
+		webhook.Events = strings.Split(events, ",")
+	}
+	return &webhook, nil
+}
+
+// Create inserts a new webhook.
+func (r *DBWebhookRepository) Create(webhook *webhook_model.Webhook) (*webhook_model.Webhook, error) {
+	result, err := r.DB.Exec("INSERT INTO webhooks (url, user_id, workspace_id, events, secret, active) VALUES (?, ?, ?, ?, ?, ?)",
+		webhook.URL, webhook.UserID, webhook.WorkspaceID, strings.Join(webhook.Events, ","), webhook.Secret, webhook.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted webhook
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(uint(id))
+}
+
+// Update saves the URL, events and state of a webhook.
+func (r *DBWebhookRepository) Update(webhook *webhook_model.Webhook) error {
+	result, err := r.DB.Exec("UPDATE webhooks SET url = ?, events = ?, active = ? WHERE id = ?",
+		webhook.URL, strings.Join(webhook.Events, ","), webhook.Active, webhook.ID)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the webhook exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		// MySQL reports unchanged rows as not affected
+		_, err := r.GetByID(webhook.ID)
+		return err
+	}
+
+	return nil
+}
+
+// Delete deletes a webhook with its deliveries and their attempts.
+func (r *DBWebhookRepository) Delete(id uint) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM webhook_attempts WHERE delivery_id IN (SELECT id FROM webhook_deliveries WHERE webhook_id = ?)", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM webhook_deliveries WHERE webhook_id = ?", id); err != nil {
+		return err
+	}
+	result, err := tx.Exec("DELETE FROM webhooks WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the webhook exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return webhook_model.ErrWebhookNotFound
+	}
+
+	return tx.Commit()
+}
+
+// CreateDelivery queues a delivery.
+func (r *DBWebhookRepository) CreateDelivery(delivery *webhook_model.Delivery) (*webhook_model.Delivery, error) {
+	result, err := r.DB.Exec("INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, error) VALUES (?, ?, ?, ?, ?, ?, '')",
+		delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Payload, delivery.Status, delivery.NextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted delivery
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.GetDelivery(uint(id))
+}
+
+// GetDelivery retrieves a delivery by ID.
+func (r *DBWebhookRepository) GetDelivery(id uint) (*webhook_model.Delivery, error) {
+	delivery, err := scanDelivery(r.DB.QueryRow(deliveryQuery+" WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, webhook_model.ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// ListDeliveries retrieves the latest deliveries of a webhook, newest first, only those with the status
+// when one is given.
+func (r *DBWebhookRepository) ListDeliveries(webhookID uint, status string, limit int) ([]webhook_model.Delivery, error) {
+	query, args := deliveryQuery+" WHERE webhook_id = ?", []interface{}{webhookID}
+	if status != "" {
+		query, args = query+" AND status = ?", append(args, status)
+	}
+	rows, err := r.DB.Query(query+" ORDER BY id DESC LIMIT ?", append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	return collectDeliveries(rows)
+}
+
+// ClaimDue retrieves the pending deliveries due at the time, oldest first, and postpones them by the
+// lease so other instances skip them while they are attempted. Deliveries of an instance stopping
+// mid-attempt are claimed again once the lease ends.
+func (r *DBWebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]webhook_model.Delivery, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(deliveryQuery+" WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED",
+		webhook_model.StatusPending, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := collectDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, delivery := range deliveries {
+		if _, err := tx.Exec("UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?", now.Add(lease), delivery.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func collectDeliveries(rows *sql.Rows) ([]webhook_model.Delivery, error) {
+	defer rows.Close()
+
+	deliveries := make([]webhook_model.Delivery, 0)
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// scanDelivery reads a delivery selected with deliveryQuery.
+func scanDelivery(row interface{ Scan(...interface{}) error }) (*webhook_model.Delivery, error) {
+	var delivery webhook_model.Delivery
+	if err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status,
+		&delivery.Attempts, &delivery.NextAttemptAt, &delivery.ResponseStatus, &delivery.Error, &delivery.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// RecordAttempt saves the status, attempts and last outcome of the delivery, and logs the attempt.
+func (r *DBWebhookRepository) RecordAttempt(delivery *webhook_model.Delivery, attempt webhook_model.Attempt) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec("UPDATE webhook_deliveries SET status = ?, attempts = ?, next_attempt_at = ?, response_status = ?, error = ? WHERE id = ?",
+		delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.ResponseStatus, delivery.Error, delivery.ID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO webhook_attempts (delivery_id, response_status, error, duration_ms, attempted_at) VALUES (?, ?, ?, ?, ?)",
+		delivery.ID, attempt.ResponseStatus, attempt.Error, attempt.Duration, attempt.AttemptedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListAttempts retrieves the attempts of a delivery, oldest first.
+func (r *DBWebhookRepository) ListAttempts(deliveryID uint) ([]webhook_model.Attempt, error) {
+	rows, err := r.DB.Query("SELECT response_status, error, duration_ms, attempted_at FROM webhook_attempts WHERE delivery_id = ? ORDER BY id", deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := make([]webhook_model.Attempt, 0)
+	for rows.Next() {
+		var attempt webhook_model.Attempt
+		if err := rows.Scan(&attempt.ResponseStatus, &attempt.Error, &attempt.Duration, &attempt.AttemptedAt); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, attempt)
+	}
+
+	return attempts, rows.Err()
+}
+
+// Requeue makes a dead or failed delivery pending again with all its attempts, due at the time.
+func (r *DBWebhookRepository) Requeue(id uint, now time.Time) error {
+	result, err := r.DB.Exec("UPDATE webhook_deliveries SET status = ?, attempts = 0, next_attempt_at = ? WHERE id = ? AND status IN (?, ?)",
+		webhook_model.StatusPending, now, id, webhook_model.StatusDead, webhook_model.StatusFailed)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the delivery had failed
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return webhook_model.ErrDeliveryNotFailed
+	}
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package webhook_repository
+
+import (
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/webhook"
+)
+
+var columns = []string{"id", "url", "user_id", "workspace_id", "events", "secret", "active", "created_at"}
+
+var deliveryColumns = []string{"id", "webhook_id", "event_id", "event_type", "payload", "status", "attempts", "next_attempt_at", "response_status", "error", "created_at"}
+
+func TestDBWebhookRepository_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWebhookRepository(db)
+	createdAt := time.Now()
+
+	t.Run("List Webhooks Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE \\(user_id = \\? AND workspace_id IS NULL\\) OR workspace_id IN \\(SELECT workspace_id FROM workspace_members WHERE user_id = \\? AND role = 'owner'\\) ORDER BY id").
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, "https://hooks.example.com/a", 1, nil, "link.created,link.clicked", "secret", true, createdAt).
+				AddRow(4, "https://hooks.example.com/b", 2, 5, "", "other", false, createdAt))
+
+		webhooks, err := repo.List(1)
+
+		assert.NoError(t, err)
+		assert.Len(t, webhooks, 2)
+		assert.Equal(t, []string{webhook_model.EventLinkCreated, webhook_model.EventLinkClicked}, webhooks[0].Events)
+		assert.Nil(t, webhooks[0].WorkspaceID)
+		assert.Equal(t, []string{}, webhooks[1].Events)
+		assert.Equal(t, uint(5), *webhooks[1].WorkspaceID)
+		assert.False(t, webhooks[1].Active)
+	})
+
+	t.Run("List Subscribers of Personal and Workspace Links", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE user_id = \\? AND workspace_id IS NULL AND active = TRUE").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "https://hooks.example.com/a", 1, nil, "", "secret", true, createdAt))
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE workspace_id = \\? AND active = TRUE").
+			WithArgs(5).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		personal, err := repo.Subscribers(1, nil)
+		assert.NoError(t, err)
+		assert.Len(t, personal, 1)
+
+		workspaceID := uint(5)
+		shared, err := repo.Subscribers(1, &workspaceID)
+		assert.NoError(t, err)
+		assert.Empty(t, shared)
+	})
+
+	t.Run("Failed to Get Missing Webhook", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\?").
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetByID(9)
+
+		assert.ErrorIs(t, err, webhook_model.ErrWebhookNotFound)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.List(1)
+
+		assert.Error(t, err)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBWebhookRepository_Write(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWebhookRepository(db)
+	createdAt := time.Now()
+
+	t.Run("Create Webhook Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO webhooks").
+			WithArgs("https://hooks.example.com/a", 1, nil, "link.created,link.deleted", "secret", true).
+			WillReturnResult(sqlmock.NewResult(3, 1))
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\?").
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(3, "https://hooks.example.com/a", 1, nil, "link.created,link.deleted", "secret", true, createdAt))
+
+		webhook, err := repo.Create(&webhook_model.Webhook{URL: "https://hooks.example.com/a", UserID: 1, Events: []string{webhook_model.EventLinkCreated, webhook_model.EventLinkDeleted}, Secret: "secret", Active: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(3), webhook.ID)
+	})
+
+	t.Run("Update Webhook Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhooks SET url = \\?, events = \\?, active = \\? WHERE id = \\?").
+			WithArgs("https://hooks.example.com/b", "", false, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Update(&webhook_model.Webhook{ID: 3, URL: "https://hooks.example.com/b", Active: false}))
+	})
+
+	t.Run("Failed to Update Missing Webhook", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhooks").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT (.+) FROM webhooks WHERE id = \\?").
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		assert.ErrorIs(t, repo.Update(&webhook_model.Webhook{ID: 9}), webhook_model.ErrWebhookNotFound)
+	})
+
+	t.Run("Delete Webhook with its Deliveries", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM webhook_attempts WHERE delivery_id IN").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 4))
+		mock.ExpectExec("DELETE FROM webhook_deliveries WHERE webhook_id = \\?").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("DELETE FROM webhooks WHERE id = \\?").WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Delete(3))
+	})
+
+	t.Run("Failed to Delete Missing Webhook", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("DELETE FROM webhook_attempts").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM webhook_deliveries").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("DELETE FROM webhooks").WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Delete(9), webhook_model.ErrWebhookNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDBWebhookRepository_Deliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWebhookRepository(db)
+	now := time.Now()
+
+	t.Run("Create Delivery Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs(3, "evt_1", webhook_model.EventLinkCreated, `{"id":"evt_1"}`, webhook_model.StatusPending, now).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+		mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE id = \\?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns).AddRow(7, 3, "evt_1", webhook_model.EventLinkCreated, `{"id":"evt_1"}`, webhook_model.StatusPending, 0, now, 0, "", now))
+
+		delivery, err := repo.CreateDelivery(&webhook_model.Delivery{WebhookID: 3, EventID: "evt_1", EventType: webhook_model.EventLinkCreated, Payload: `{"id":"evt_1"}`, Status: webhook_model.StatusPending, NextAttemptAt: now})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), delivery.ID)
+	})
+
+	t.Run("List Dead Deliveries", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE webhook_id = \\? AND status = \\? ORDER BY id DESC LIMIT \\?").
+			WithArgs(3, webhook_model.StatusDead, 50).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns).AddRow(7, 3, "evt_1", webhook_model.EventLinkCreated, "{}", webhook_model.StatusDead, 8, now, 500, "", now))
+
+		deliveries, err := repo.ListDeliveries(3, webhook_model.StatusDead, 50)
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, 500, deliveries[0].ResponseStatus)
+	})
+
+	t.Run("Claim Due Deliveries", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE status = \\? AND next_attempt_at <= \\? ORDER BY next_attempt_at LIMIT \\? FOR UPDATE SKIP LOCKED").
+			WithArgs(webhook_model.StatusPending, now, 10).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns).AddRow(7, 3, "evt_1", webhook_model.EventLinkCreated, "{}", webhook_model.StatusPending, 1, now, 500, "", now))
+		mock.ExpectExec("UPDATE webhook_deliveries SET next_attempt_at = \\? WHERE id = \\?").
+			WithArgs(now.Add(time.Minute), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		deliveries, err := repo.ClaimDue(now, time.Minute, 10)
+
+		assert.NoError(t, err)
+		assert.Len(t, deliveries, 1)
+	})
+
+	t.Run("Record Attempt Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE webhook_deliveries SET status = \\?, attempts = \\?, next_attempt_at = \\?, response_status = \\?, error = \\? WHERE id = \\?").
+			WithArgs(webhook_model.StatusDelivered, 2, now, 204, "", 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("INSERT INTO webhook_attempts").
+			WithArgs(7, 204, "", 12, now).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		delivery := &webhook_model.Delivery{ID: 7, Status: webhook_model.StatusDelivered, Attempts: 2, NextAttemptAt: now, ResponseStatus: 204}
+		assert.NoError(t, repo.RecordAttempt(delivery, webhook_model.Attempt{ResponseStatus: 204, Duration: 12, AttemptedAt: now}))
+	})
+
+	t.Run("List Attempts Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT response_status, error, duration_ms, attempted_at FROM webhook_attempts WHERE delivery_id = \\? ORDER BY id").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"response_status", "error", "duration_ms", "attempted_at"}).
+				AddRow(500, "", 30, now).
+				AddRow(0, "connection refused", 2, now))
+
+		attempts, err := repo.ListAttempts(7)
+
+		assert.NoError(t, err)
+		assert.Len(t, attempts, 2)
+		assert.Equal(t, "connection refused", attempts[1].Error)
+	})
+
+	t.Run("Requeue Failed Deliveries Only", func(t *testing.T) {
+		mock.ExpectExec("UPDATE webhook_deliveries SET status = \\?, attempts = 0, next_attempt_at = \\? WHERE id = \\? AND status IN").
+			WithArgs(webhook_model.StatusPending, now, 7, webhook_model.StatusDead, webhook_model.StatusFailed).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE webhook_deliveries SET status = \\?, attempts = 0").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, repo.Requeue(7, now))
+		assert.ErrorIs(t, repo.Requeue(8, now), webhook_model.ErrDeliveryNotFailed)
+	})
+
+	t.Run("Failed to Get Missing Delivery", func(t *testing.T) {
+		mock.ExpectQuery("SELECT (.+) FROM webhook_deliveries WHERE id = \\?").
+			WithArgs(9).
+			WillReturnRows(sqlmock.NewRows(deliveryColumns))
+
+		_, err := repo.GetDelivery(9)
+
+		assert.ErrorIs(t, err, webhook_model.ErrDeliveryNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,185 @@
+package workspace_repository
+
+import (
+	"database/sql"
+	"errors"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// Repository defines methods to interact with the workspace repository.
+type Repository interface {
+	Create(workspace *workspace_model.Workspace) (*workspace_model.Workspace, error)
+	GetByID(id uint) (*workspace_model.Workspace, error)
+	ListByUser(userID uint) ([]workspace_model.Workspace, error)
+	GetMember(workspaceID, userID uint) (*workspace_model.Member, error)
+	ListMembers(workspaceID uint) ([]workspace_model.Member, error)
+	AddMember(workspaceID, userID uint, role string) error
+	UpdateMemberRole(workspaceID, userID uint, role string) error
+	RemoveMember(workspaceID, userID uint) error
+	CountOwners(workspaceID uint) (int, error)
+}
+
+// DBWorkspaceRepository is an implementation of WorkspaceRepository for MySQL database.
+type DBWorkspaceRepository struct {
+	// DB is the database connection
+	DB *sql.DB
+}
+
+// NewDBWorkspaceRepository creates a new instance of DBWorkspaceRepository.
+func NewDBWorkspaceRepository(db *sql.DB) *DBWorkspaceRepository {
+	return &DBWorkspaceRepository{DB: db}
+}
+
+// Create inserts a new workspace and makes its creator the first owner, in a single transaction.
+func (r *DBWorkspaceRepository) Create(workspace *workspace_model.Workspace) (*workspace_model.Workspace, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("INSERT INTO workspaces (name, created_by) VALUES (?, ?)", workspace.Name, workspace.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retrieve the ID of the newly inserted workspace
+	workspaceID, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec("INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (?, ?, ?)",
+		workspaceID, workspace.CreatedBy, workspace_model.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	workspace.ID = uint(workspaceID)
+	workspace.Role = workspace_model.RoleOwner
+	return workspace, nil
+}
+
+// GetByID retrieves a workspace from the database by ID.
+func (r *DBWorkspaceRepository) GetByID(id uint) (*workspace_model.Workspace, error) {
+	query := "SELECT id, name, created_by, created_at FROM workspaces WHERE id = ?"
+
+	var w workspace_model.Workspace
+	err := r.DB.QueryRow(query, id).Scan(&w.ID, &w.Name, &w.CreatedBy, &w.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, workspace_model.ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// ListByUser retrieves the workspaces the given user is a member of, with their role.
+func (r *DBWorkspaceRepository) ListByUser(userID uint) ([]workspace_model.Workspace, error) {
+	query := "SELECT w.id, w.name, w.created_by, m.role, w.created_at FROM workspaces w " +
+		"JOIN workspace_members m ON m.workspace_id = w.id WHERE m.user_id = ? ORDER BY w.id"
+	rows, err := r.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workspaces := make([]workspace_model.Workspace, 0)
+	for rows.Next() {
+		var w workspace_model.Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.CreatedBy, &w.Role, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	return workspaces, rows.Err()
+}
+
+// GetMember retrieves the membership of a user in a workspace.
+func (r *DBWorkspaceRepository) GetMember(workspaceID, userID uint) (*workspace_model.Member, error) {
+	query := "SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at FROM workspace_members m " +
+		"JOIN users u ON u.id = m.user_id WHERE m.workspace_id = ? AND m.user_id = ?"
+
+	var m workspace_model.Member
+	err := r.DB.QueryRow(query, workspaceID, userID).Scan(&m.WorkspaceID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, workspace_model.ErrNotMember
+		}
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// ListMembers retrieves the members of a workspace, ordered by user ID.
+func (r *DBWorkspaceRepository) ListMembers(workspaceID uint) ([]workspace_model.Member, error) {
+	query := "SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at FROM workspace_members m " +
+		"JOIN users u ON u.id = m.user_id WHERE m.workspace_id = ? ORDER BY m.user_id"
+	rows, err := r.DB.Query(query, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := make([]workspace_model.Member, 0)
+	for rows.Next() {
+		var m workspace_model.Member
+		if err := rows.Scan(&m.WorkspaceID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// AddMember inserts a membership of a user in a workspace.
+func (r *DBWorkspaceRepository) AddMember(workspaceID, userID uint, role string) error {
+	_, err := r.DB.Exec("INSERT INTO workspace_members (workspace_id, user_id, role) VALUES (?, ?, ?)", workspaceID, userID, role)
+	return err
+}
+
+// UpdateMemberRole replaces the role of a member of a workspace.
+func (r *DBWorkspaceRepository) UpdateMemberRole(workspaceID, userID uint, role string) error {
+	return r.updateMember("UPDATE workspace_members SET role = ? WHERE workspace_id = ? AND user_id = ?", role, workspaceID, userID)
+}
+
+// RemoveMember deletes the membership of a user in a workspace.
+func (r *DBWorkspaceRepository) RemoveMember(workspaceID, userID uint) error {
+	return r.updateMember("DELETE FROM workspace_members WHERE workspace_id = ? AND user_id = ?", workspaceID, userID)
+}
+
+// CountOwners returns the number of owners of a workspace.
+func (r *DBWorkspaceRepository) CountOwners(workspaceID uint) (int, error) {
+	var count int
+	err := r.DB.QueryRow("SELECT COUNT(*) FROM workspace_members WHERE workspace_id = ? AND role = ?",
+		workspaceID, workspace_model.RoleOwner).Scan(&count)
+	return count, err
+}
+
+// updateMember executes a statement on a single membership and reports ErrNotMember when none matched.
+func (r *DBWorkspaceRepository) updateMember(query string, args ...interface{}) error {
+	result, err := r.DB.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+
+	// Check the number of rows affected to ensure the membership exists
+	numRows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if numRows == 0 {
+		return workspace_model.ErrNotMember
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package workspace_repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/workspace"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBWorkspaceRepository_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWorkspaceRepository(db)
+
+	t.Run("Create Workspace Successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO workspaces \\(name, created_by\\) VALUES \\(\\?, \\?\\)").
+			WithArgs("Marketing", 1).
+			WillReturnResult(sqlmock.NewResult(7, 1))
+		mock.ExpectExec("INSERT INTO workspace_members \\(workspace_id, user_id, role\\) VALUES \\(\\?, \\?, \\?\\)").
+			WithArgs(7, 1, workspace_model.RoleOwner).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		workspace, err := repo.Create(&workspace_model.Workspace{Name: "Marketing", CreatedBy: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), workspace.ID)
+		assert.Equal(t, workspace_model.RoleOwner, workspace.Role)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Roll Back When Owner Cannot Be Added", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO workspaces").WillReturnResult(sqlmock.NewResult(8, 1))
+		mock.ExpectExec("INSERT INTO workspace_members").WillReturnError(errors.New("insert error"))
+		mock.ExpectRollback()
+
+		_, err := repo.Create(&workspace_model.Workspace{Name: "Sales", CreatedBy: 1})
+
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed to Begin Transaction", func(t *testing.T) {
+		mock.ExpectBegin().WillReturnError(errors.New("begin error"))
+
+		_, err := repo.Create(&workspace_model.Workspace{Name: "Sales", CreatedBy: 1})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBWorkspaceRepository_GetByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWorkspaceRepository(db)
+	createdAt := time.Now()
+
+	t.Run("Get Workspace Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name, created_by, created_at FROM workspaces WHERE id = \\?").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_by", "created_at"}).AddRow(7, "Marketing", 1, createdAt))
+
+		workspace, err := repo.GetByID(7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &workspace_model.Workspace{ID: 7, Name: "Marketing", CreatedBy: 1, CreatedAt: createdAt}, workspace)
+	})
+
+	t.Run("Failed to Get Missing Workspace", func(t *testing.T) {
+		mock.ExpectQuery("SELECT id, name, created_by, created_at FROM workspaces WHERE id = \\?").
+			WithArgs(8).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_by", "created_at"}))
+
+		_, err := repo.GetByID(8)
+
+		assert.ErrorIs(t, err, workspace_model.ErrWorkspaceNotFound)
+	})
+}
+
+func TestDBWorkspaceRepository_ListByUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWorkspaceRepository(db)
+	createdAt := time.Now()
+
+	t.Run("List Workspaces Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT w.id, w.name, w.created_by, m.role, w.created_at FROM workspaces w JOIN workspace_members m").
+			WithArgs(2).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_by", "role", "created_at"}).
+				AddRow(7, "Marketing", 1, "editor", createdAt))
+
+		workspaces, err := repo.ListByUser(2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []workspace_model.Workspace{{ID: 7, Name: "Marketing", CreatedBy: 1, Role: "editor", CreatedAt: createdAt}}, workspaces)
+	})
+
+	t.Run("Failed on SQL Query", func(t *testing.T) {
+		mock.ExpectQuery("SELECT w.id").WillReturnError(errors.New("query error"))
+
+		_, err := repo.ListByUser(2)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDBWorkspaceRepository_Members(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	repo := NewDBWorkspaceRepository(db)
+	createdAt := time.Now()
+	columns := []string{"workspace_id", "user_id", "username", "role", "created_at"}
+
+	t.Run("Get Member Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at FROM workspace_members m JOIN users u").
+			WithArgs(7, 2).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(7, 2, "alice", "viewer", createdAt))
+
+		member, err := repo.GetMember(7, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &workspace_model.Member{WorkspaceID: 7, UserID: 2, Username: "alice", Role: "viewer", CreatedAt: createdAt}, member)
+	})
+
+	t.Run("Failed to Get Missing Member", func(t *testing.T) {
+		mock.ExpectQuery("SELECT m.workspace_id").
+			WithArgs(7, 3).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := repo.GetMember(7, 3)
+
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+	})
+
+	t.Run("List Members Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT m.workspace_id, m.user_id, u.username, m.role, m.created_at FROM workspace_members m JOIN users u .* ORDER BY m.user_id").
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(7, 1, "bob", "owner", createdAt).
+				AddRow(7, 2, "alice", "viewer", createdAt))
+
+		members, err := repo.ListMembers(7)
+
+		assert.NoError(t, err)
+		assert.Len(t, members, 2)
+		assert.Equal(t, "owner", members[0].Role)
+	})
+
+	t.Run("Add Member Successfully", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO workspace_members \\(workspace_id, user_id, role\\) VALUES \\(\\?, \\?, \\?\\)").
+			WithArgs(7, 3, "editor").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.AddMember(7, 3, "editor"))
+	})
+
+	t.Run("Update Member Role Successfully", func(t *testing.T) {
+		mock.ExpectExec("UPDATE workspace_members SET role = \\? WHERE workspace_id = \\? AND user_id = \\?").
+			WithArgs("owner", 7, 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.UpdateMemberRole(7, 3, "owner"))
+	})
+
+	t.Run("Failed to Remove Missing Member", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM workspace_members WHERE workspace_id = \\? AND user_id = \\?").
+			WithArgs(7, 9).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.RemoveMember(7, 9), workspace_model.ErrNotMember)
+	})
+
+	t.Run("Failed on SQL Execution", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM workspace_members").WillReturnError(errors.New("execute error"))
+
+		assert.Error(t, repo.RemoveMember(7, 3))
+	})
+
+	t.Run("Count Owners Successfully", func(t *testing.T) {
+		mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM workspace_members WHERE workspace_id = \\? AND role = \\?").
+			WithArgs(7, workspace_model.RoleOwner).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+		count, err := repo.CountOwners(7)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,228 @@
+package admin_service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"url-shortener/internal/app/models/audit"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/repositories/audit"
+	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/app/repositories/url"
+)
+
+// rolePattern restricts roles, including custom ones, to short lowercase identifiers.
+var rolePattern = regexp.MustCompile(`^[a-z0-9_-]{1,50}$`)
+
+// DefaultLimit is the page size used when none is given to ListUsers.
+const DefaultLimit = 50
+
+// MaxLimit caps the page size of ListUsers.
+const MaxLimit = 200
+
+// Actor identifies the admin performing an action, for the audit log.
+type Actor struct {
+	UserID    uint
+	IPAddress string
+}
+
+// Service provides the moderation functionalities of the admin endpoints.
+// Every action is written to the audit log. Views are audited before they are served and fail when they
+// cannot be audited; changes are audited once they ran, with their outcome.
+type Service struct {
+	UserRepository  auth_repository.Repository
+	URLRepository   url_repository.Repository
+	AuditRepository audit_repository.Repository
+}
+
+// NewAdminService creates a new instance of AdminService with the given repositories.
+func NewAdminService(userRepository auth_repository.Repository, urlRepository url_repository.Repository, auditRepository audit_repository.Repository) *Service {
+	return &Service{UserRepository: userRepository, URLRepository: urlRepository, AuditRepository: auditRepository}
+}
+
+// ListUsers returns a page of users whose username or email contains search.
+func (s *Service) ListUsers(actor Actor, search string, limit, offset int) ([]user_model.User, error) {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if err := s.audit(actor, audit_model.ActionAdminListUsers, "users", fmt.Sprintf("search=%q limit=%d offset=%d", search, limit, offset)); err != nil {
+		return nil, err
+	}
+
+	users, err := s.UserRepository.ListUsers(search, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, nil
+}
+
+// GetUser returns the user with the given ID.
+func (s *Service) GetUser(actor Actor, userID uint) (*user_model.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(actor, audit_model.ActionAdminViewUser, userTarget(userID), ""); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserURLs returns the URLs created by the user with the given ID.
+func (s *Service) GetUserURLs(actor Actor, userID uint) ([]url_model.URL, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(actor, audit_model.ActionAdminViewUser, userTarget(userID), "urls"); err != nil {
+		return nil, err
+	}
+
+	urls, err := s.URLRepository.GetUserURLs(userID)
+	if errors.Is(err, url_model.ErrURLNotFound) {
+		return []url_model.URL{}, nil
+	}
+	return urls, err
+}
+
+// SetRole replaces the role of the user with the given ID.
+// Admins cannot demote themselves, so that there is always someone left to undo it.
+func (s *Service) SetRole(actor Actor, userID uint, role string) (*user_model.User, error) {
+	if !rolePattern.MatchString(role) {
+		return nil, user_model.ErrInvalidRole
+	}
+	if userID == actor.UserID && role != user_model.RoleAdmin {
+		return nil, user_model.ErrSelfModeration
+	}
+
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.UserRepository.UpdateRole(userID, role)
+	s.record(actor, audit_model.ActionAdminSetRole, userTarget(userID), fmt.Sprintf("%s -> %s", user.Role, role), err)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// SetUserDisabled disables or re-enables the account with the given ID.
+// Disabled accounts cannot log in, and their tokens are rejected until they are re-enabled.
+func (s *Service) SetUserDisabled(actor Actor, userID uint, disabled bool) (*user_model.User, error) {
+	if userID == actor.UserID && disabled {
+		return nil, user_model.ErrSelfModeration
+	}
+
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	action := audit_model.ActionAdminEnableUser
+	if disabled {
+		action = audit_model.ActionAdminDisableUser
+	}
+	err = s.UserRepository.SetDisabled(userID, disabled)
+	s.record(actor, action, userTarget(userID), "", err)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Disabled = disabled
+	return user, nil
+}
+
+// GetURL returns the URL with the given short code, including disabled ones.
+func (s *Service) GetURL(actor Actor, shortCode string) (*url_model.URL, error) {
+	u, err := s.URLRepository.GetURL(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.audit(actor, audit_model.ActionAdminViewURL, urlTarget(shortCode), ""); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// SetURLDisabled disables or re-enables the URL with the given short code.
+// Disabled URLs stop redirecting but keep their short code reserved.
+func (s *Service) SetURLDisabled(actor Actor, shortCode string, disabled bool) (*url_model.URL, error) {
+	u, err := s.URLRepository.GetURL(shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	action := audit_model.ActionAdminEnableURL
+	if disabled {
+		action = audit_model.ActionAdminDisableURL
+	}
+	err = s.URLRepository.SetDisabled(shortCode, disabled)
+	s.record(actor, action, urlTarget(shortCode), u.OriginalURL, err)
+	if err != nil {
+		return nil, err
+	}
+
+	result := *u
+	result.Disabled = disabled
+	return &result, nil
+}
+
+// getUser returns a copy of the user without its password hash.
+func (s *Service) getUser(userID uint) (*user_model.User, error) {
+	stored, err := s.UserRepository.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user := *stored
+	user.Password = ""
+	return &user, nil
+}
+
+// record writes the audit entry of a change after it ran, noting the error it failed with. The change
+// cannot be undone by then, so an entry that cannot be written is only logged.
+func (s *Service) record(actor Actor, action, target, details string, err error) {
+	if err != nil {
+		details = strings.TrimSpace(details + " failed: " + err.Error())
+	}
+	if err := s.audit(actor, action, target, details); err != nil {
+		fmt.Println("[ADMIN] Error writing audit entry:", err)
+	}
+}
+
+func (s *Service) audit(actor Actor, action, target, details string) error {
+	actorID := actor.UserID
+	return s.AuditRepository.Create(&audit_model.Entry{
+		Action:    action,
+		ActorID:   &actorID,
+		Target:    target,
+		IPAddress: actor.IPAddress,
+		Details:   details,
+	})
+}
+
+func userTarget(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+func urlTarget(shortCode string) string {
+	return "url:" + shortCode
+}
@@ -0,0 +1,249 @@
+package admin_service
+
+import (
+	"testing"
+	"url-shortener/internal/app/models/audit"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var admin = Actor{UserID: 1, IPAddress: "203.0.113.1"}
+
+func TestListUsers(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := NewAdminService(userRepository, mocks.NewMockUrlRepository(), auditRepository)
+
+	t.Run("Should list users without passwords", func(t *testing.T) {
+		users, err := adminService.ListUsers(admin, "ali", 0, -1)
+
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, "alice", users[0].Username)
+		assert.Empty(t, users[0].Password)
+		assert.Len(t, auditRepository.Entries, 1)
+		assert.Equal(t, audit_model.ActionAdminListUsers, auditRepository.Entries[0].Action)
+		assert.Equal(t, uint(1), *auditRepository.Entries[0].ActorID)
+		assert.Equal(t, "203.0.113.1", auditRepository.Entries[0].IPAddress)
+		assert.Contains(t, auditRepository.Entries[0].Details, "limit=50 offset=0")
+	})
+
+	t.Run("Should cap page size", func(t *testing.T) {
+		_, err := adminService.ListUsers(admin, "", 10000, 0)
+
+		assert.NoError(t, err)
+		assert.Contains(t, auditRepository.Entries[len(auditRepository.Entries)-1].Details, "limit=200")
+	})
+
+	t.Run("Should return repository error", func(t *testing.T) {
+		_, err := adminService.ListUsers(admin, "error", 10, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetUser(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+	urlRepository := mocks.NewMockUrlRepository()
+	userID := uint(2)
+	urlRepository.CreateURL("https://example.com", "alice1", &userID)
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := NewAdminService(userRepository, urlRepository, auditRepository)
+
+	t.Run("Should return user without password", func(t *testing.T) {
+		user, err := adminService.GetUser(admin, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "alice", user.Username)
+		assert.Empty(t, user.Password)
+		assert.Equal(t, "hash", userRepository.Users[2].Password)
+		assert.Equal(t, "user:2", auditRepository.Entries[len(auditRepository.Entries)-1].Target)
+	})
+
+	t.Run("Should return user URLs", func(t *testing.T) {
+		urls, err := adminService.GetUserURLs(admin, 2)
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+
+		urls, err = adminService.GetUserURLs(admin, 1)
+		assert.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+
+	t.Run("Should return error for unknown user", func(t *testing.T) {
+		_, err := adminService.GetUser(admin, 99)
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+
+		_, err = adminService.GetUserURLs(admin, 99)
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestSetRole(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := NewAdminService(userRepository, mocks.NewMockUrlRepository(), auditRepository)
+
+	t.Run("Should change role", func(t *testing.T) {
+		user, err := adminService.SetRole(admin, 2, "moderator")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "moderator", user.Role)
+		assert.Equal(t, "moderator", userRepository.Users[2].Role)
+		assert.Equal(t, audit_model.ActionAdminSetRole, auditRepository.Entries[0].Action)
+		assert.Equal(t, "user -> moderator", auditRepository.Entries[0].Details)
+	})
+
+	t.Run("Should reject invalid role", func(t *testing.T) {
+		_, err := adminService.SetRole(admin, 2, "Super Admin")
+
+		assert.ErrorIs(t, err, user_model.ErrInvalidRole)
+		assert.Len(t, auditRepository.Entries, 1)
+	})
+
+	t.Run("Should not demote self", func(t *testing.T) {
+		_, err := adminService.SetRole(admin, 1, user_model.RoleUser)
+
+		assert.ErrorIs(t, err, user_model.ErrSelfModeration)
+		assert.Equal(t, user_model.RoleAdmin, userRepository.Users[1].Role)
+	})
+
+	t.Run("Should return error for unknown user", func(t *testing.T) {
+		_, err := adminService.SetRole(admin, 99, "moderator")
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := NewAdminService(userRepository, mocks.NewMockUrlRepository(), auditRepository)
+
+	t.Run("Should disable and enable user", func(t *testing.T) {
+		user, err := adminService.SetUserDisabled(admin, 2, true)
+		assert.NoError(t, err)
+		assert.True(t, user.Disabled)
+		assert.True(t, userRepository.Users[2].Disabled)
+
+		user, err = adminService.SetUserDisabled(admin, 2, false)
+		assert.NoError(t, err)
+		assert.False(t, user.Disabled)
+
+		assert.Equal(t, audit_model.ActionAdminDisableUser, auditRepository.Entries[0].Action)
+		assert.Equal(t, audit_model.ActionAdminEnableUser, auditRepository.Entries[1].Action)
+	})
+
+	t.Run("Should not disable self", func(t *testing.T) {
+		_, err := adminService.SetUserDisabled(admin, 1, true)
+		assert.ErrorIs(t, err, user_model.ErrSelfModeration)
+	})
+}
+
+func TestSetURLDisabled(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	urlRepository.CreateURL("https://example.com", "abc123", nil)
+	auditRepository := mocks.NewMockAuditRepository()
+
+	adminService := NewAdminService(mocks.NewMockUserRepository(), urlRepository, auditRepository)
+
+	t.Run("Should view, disable and enable URL", func(t *testing.T) {
+		u, err := adminService.GetURL(admin, "abc123")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com", u.OriginalURL)
+
+		u, err = adminService.SetURLDisabled(admin, "abc123", true)
+		assert.NoError(t, err)
+		assert.True(t, u.Disabled)
+		_, err = urlRepository.GetOriginalURL("abc123")
+		assert.ErrorIs(t, err, url_model.ErrURLDisabled)
+
+		_, err = adminService.SetURLDisabled(admin, "abc123", false)
+		assert.NoError(t, err)
+		_, err = urlRepository.GetOriginalURL("abc123")
+		assert.NoError(t, err)
+
+		assert.Equal(t, audit_model.ActionAdminViewURL, auditRepository.Entries[0].Action)
+		assert.Equal(t, audit_model.ActionAdminDisableURL, auditRepository.Entries[1].Action)
+		assert.Equal(t, "url:abc123", auditRepository.Entries[1].Target)
+		assert.Equal(t, audit_model.ActionAdminEnableURL, auditRepository.Entries[2].Action)
+	})
+
+	t.Run("Should return error for unknown URL", func(t *testing.T) {
+		_, err := adminService.SetURLDisabled(admin, "missing", true)
+
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+		assert.Len(t, auditRepository.Entries, 3)
+	})
+}
+
+func TestAuditFailure(t *testing.T) {
+	userRepository := mocks.NewMockUserRepository()
+	userRepository.Create(&user_model.User{Username: "admin", Password: "hash", Role: user_model.RoleAdmin})
+	userRepository.Create(&user_model.User{Username: "alice", Password: "hash", Email: "alice@example.com"})
+
+	t.Run("Should refuse views that cannot be audited", func(t *testing.T) {
+		adminService := NewAdminService(userRepository, mocks.NewMockUrlRepository(), failingAudit{})
+
+		_, err := adminService.GetUser(admin, 2)
+		assert.Error(t, err)
+	})
+
+	t.Run("Should apply changes that cannot be audited", func(t *testing.T) {
+		adminService := NewAdminService(userRepository, mocks.NewMockUrlRepository(), failingAudit{})
+
+		_, err := adminService.SetUserDisabled(admin, 2, true)
+		assert.NoError(t, err)
+		assert.True(t, userRepository.Users[2].Disabled)
+	})
+
+	t.Run("Should audit failed changes as failed", func(t *testing.T) {
+		auditRepository := mocks.NewMockAuditRepository()
+		adminService := NewAdminService(failingUsers{userRepository}, mocks.NewMockUrlRepository(), auditRepository)
+
+		_, err := adminService.SetUserDisabled(admin, 2, false)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.True(t, userRepository.Users[2].Disabled)
+
+		_, err = adminService.SetRole(admin, 2, "moderator")
+		assert.ErrorIs(t, err, assert.AnError)
+
+		assert.Len(t, auditRepository.Entries, 2)
+		assert.Equal(t, audit_model.ActionAdminEnableUser, auditRepository.Entries[0].Action)
+		assert.Equal(t, "failed: "+assert.AnError.Error(), auditRepository.Entries[0].Details)
+		assert.Equal(t, "user -> moderator failed: "+assert.AnError.Error(), auditRepository.Entries[1].Details)
+	})
+}
+
+// failingAudit is an audit repository that cannot write.
+type failingAudit struct{}
+
+func (failingAudit) Create(*audit_model.Entry) error {
+	return assert.AnError
+}
+
+// failingUsers is a user repository whose changes fail.
+type failingUsers struct {
+	*mocks.MockUserRepository
+}
+
+func (failingUsers) UpdateRole(uint, string) error {
+	return assert.AnError
+}
+
+func (failingUsers) SetDisabled(uint, bool) error {
+	return assert.AnError
+}
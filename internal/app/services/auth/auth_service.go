@@ -1,25 +1,72 @@
 package auth_service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"sync"
+	"time"
 	"url-shortener/internal/app/models/user"
 	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/infrastructure/mail"
+	"url-shortener/internal/utils"
+)
+
+// DefaultResetTokenTTL is how long a password reset token stays valid.
+const DefaultResetTokenTTL = time.Hour
+
+// dummyHash is compared against when a username does not exist so that
+// unknown and known usernames take the same time to reject.
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
 )
 
 // Service handles operations related to users.
 type Service struct {
 	// UserRepository is an interface that defines methods to interact with the auth repository.
 	Repository auth_repository.Repository
+	// Policy is the password policy applied to new passwords.
+	Policy PasswordPolicy
+	// Mailer delivers password reset tokens.
+	Mailer mail.Mailer
+	// ResetTokenTTL is how long a password reset token stays valid.
+	ResetTokenTTL time.Duration
 }
 
-// NewAuthService creates a new instance of AuthService with the given auth repository.
-func NewAuthService(AuthRepository auth_repository.Repository) *Service {
-	return &Service{Repository: AuthRepository}
+// NewAuthService creates a new instance of AuthService with the given auth repository, password policy and mailer.
+func NewAuthService(AuthRepository auth_repository.Repository, policy PasswordPolicy, mailer mail.Mailer) *Service {
+	return &Service{Repository: AuthRepository, Policy: policy, Mailer: mailer, ResetTokenTTL: DefaultResetTokenTTL}
 }
 
 // CreateUser creates a new auth with the provided data.
 // It hashes the password before storing it in the database.
 func (s *Service) CreateUser(user user_model.User) (*user_model.User, error) {
+	// Check the password against the policy
+	if err := s.Policy.Validate(user.Password); err != nil {
+		return nil, err
+	}
+
+	// New accounts always start as enabled users with an unverified email address
+	user.Role = user_model.RoleUser
+	user.Disabled = false
+	user.EmailVerified = false
+	if user.Email != "" {
+		email, ok := utils.NormalizeEmail(user.Email)
+		if !ok {
+			return nil, user_model.ErrInvalidEmail
+		}
+		if _, err := s.Repository.GetByEmail(email); err == nil {
+			return nil, user_model.ErrEmailAlreadyExists
+		} else if !errors.Is(err, user_model.ErrUserNotFound) {
+			return nil, err
+		}
+		user.Email = email
+	}
+
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -38,19 +85,133 @@ func (s *Service) CreateUser(user user_model.User) (*user_model.User, error) {
 }
 
 // LoginUser authenticates the auth with the provided username and password.
-// It returns a token upon successful authentication.
+// Unknown usernames and wrong passwords both return ErrInvalidCredentials.
 func (s *Service) LoginUser(user user_model.User) (*user_model.User, error) {
 	// Retrieve auth from the database
 	userVal, err := s.Repository.GetByUsername(user.Username)
 	if err != nil {
+		if errors.Is(err, user_model.ErrUserNotFound) {
+			// Spend the same bcrypt work as for a real user before rejecting
+			_ = bcrypt.CompareHashAndPassword(getDummyHash(), []byte(user.Password))
+			return nil, user_model.ErrInvalidCredentials
+		}
 		return nil, err
 	}
 
 	// Compare the hashed password with the provided password
 	err = bcrypt.CompareHashAndPassword([]byte(userVal.Password), []byte(user.Password))
+	if err != nil {
+		return nil, user_model.ErrInvalidCredentials
+	}
+
+	// Only reveal that the account is disabled to someone who knows its password
+	if userVal.Disabled {
+		return nil, user_model.ErrAccountDisabled
+	}
+
+	return userVal, nil
+}
+
+// GetActiveUser retrieves the given auth, returning ErrAccountDisabled when it has been disabled.
+func (s *Service) GetActiveUser(userID uint) (*user_model.User, error) {
+	userVal, err := s.Repository.GetByID(userID)
 	if err != nil {
 		return nil, err
 	}
+	if userVal.Disabled {
+		return nil, user_model.ErrAccountDisabled
+	}
 
 	return userVal, nil
 }
+
+// ChangePassword replaces the password of the given auth after verifying the current one.
+func (s *Service) ChangePassword(userID uint, change user_model.PasswordChange) error {
+	userVal, err := s.Repository.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	// Compare the hashed password with the provided current password
+	err = bcrypt.CompareHashAndPassword([]byte(userVal.Password), []byte(change.CurrentPassword))
+	if err != nil {
+		return user_model.ErrInvalidCredentials
+	}
+
+	return s.setPassword(userID, change.NewPassword)
+}
+
+// RequestPasswordReset issues a single-use reset token for the given username and mails it to the verified
+// email address of the account. Unknown usernames and accounts without a verified email address are ignored
+// so that callers cannot tell whether an account exists.
+func (s *Service) RequestPasswordReset(username string) error {
+	userVal, err := s.Repository.GetByUsername(username)
+	if err != nil {
+		if errors.Is(err, user_model.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	// Only a verified email address is trusted with the token
+	if !userVal.EmailVerified {
+		return nil
+	}
+
+	// Generate a random token; only its hash is stored
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.Repository.CreateResetToken(userVal.ID, hashResetToken(token), time.Now().Add(s.ResetTokenTTL)); err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(mail.Message{
+		To:      userVal.Email,
+		Subject: "Password reset",
+		Body:    fmt.Sprintf("Use the following token to reset your password. It expires in %s and can only be used once.\n\n%s\n", s.ResetTokenTTL, token),
+	})
+}
+
+// ResetPassword sets a new password for the auth owning the given reset token and consumes the token.
+func (s *Service) ResetPassword(reset user_model.PasswordReset) error {
+	// Check the password before consuming the token so a rejected password does not burn it
+	if err := s.Policy.Validate(reset.NewPassword); err != nil {
+		return err
+	}
+
+	userID, err := s.Repository.ConsumeResetToken(hashResetToken(reset.Token))
+	if err != nil {
+		return err
+	}
+
+	return s.setPassword(userID, reset.NewPassword)
+}
+
+// setPassword validates, hashes and stores a new password for the given auth.
+func (s *Service) setPassword(userID uint, password string) error {
+	if err := s.Policy.Validate(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	return s.Repository.UpdatePassword(userID, string(hashedPassword))
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func getDummyHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
@@ -1,7 +1,9 @@
 package auth_service
 
 import (
+	"strings"
 	"testing"
+	"time"
 	"url-shortener/internal/app/models/user"
 	"url-shortener/internal/mocks"
 
@@ -12,7 +14,7 @@ func TestCreateUser(t *testing.T) {
 	mockUserRepository := mocks.NewMockUserRepository()
 
 	// Create a new instance of AuthService with the mock repository
-	userService := NewAuthService(mockUserRepository)
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
 
 	userData := user_model.User{
 		Username: "testuser",
@@ -62,7 +64,7 @@ func TestLoginUser(t *testing.T) {
 	mockUserRepository := mocks.NewMockUserRepository()
 
 	// Create a new instance of AuthService with the mock repository
-	userService := NewAuthService(mockUserRepository)
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
 
 	user := user_model.User{Username: "test", Password: "password123"}
 
@@ -84,7 +86,7 @@ func TestLoginUser(t *testing.T) {
 		// Should fail to log in
 		user.Password = "wrongpassword"
 		userVal, err := userService.LoginUser(user)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, user_model.ErrInvalidCredentials)
 
 		assert.Nil(t, userVal)
 	})
@@ -94,10 +96,217 @@ func TestLoginUser(t *testing.T) {
 		notFoundUser := user_model.User{Username: "unknown", Password: "password123"}
 		// Should fail to log in
 		userReturn, err := userService.LoginUser(notFoundUser)
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, user_model.ErrInvalidCredentials)
+
+		assert.Nil(t, userReturn)
+
+	})
+
+	t.Run("Disabled user", func(t *testing.T) {
+		disabled, _ := userService.CreateUser(user_model.User{Username: "disabled", Password: "password123"})
+		_ = mockUserRepository.SetDisabled(disabled.ID, true)
 
+		userReturn, err := userService.LoginUser(user_model.User{Username: "disabled", Password: "password123"})
+		assert.ErrorIs(t, err, user_model.ErrAccountDisabled)
 		assert.Nil(t, userReturn)
 
+		// A wrong password does not reveal the account state
+		_, err = userService.LoginUser(user_model.User{Username: "disabled", Password: "wrongpassword"})
+		assert.ErrorIs(t, err, user_model.ErrInvalidCredentials)
+	})
+
+}
+
+func TestCreateUserIgnoresPrivilegedFields(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
+
+	user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123", Role: user_model.RoleAdmin, Disabled: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, user_model.RoleUser, user.Role)
+	assert.False(t, user.Disabled)
+}
+
+func TestGetActiveUser(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
+	user, _ := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123"})
+
+	found, err := userService.GetActiveUser(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	_ = mockUserRepository.SetDisabled(user.ID, true)
+	_, err = userService.GetActiveUser(user.ID)
+	assert.ErrorIs(t, err, user_model.ErrAccountDisabled)
+
+	_, err = userService.GetActiveUser(99)
+	assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+}
+
+func TestCreateUserPasswordPolicy(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
+
+	t.Run("Should reject short password", func(t *testing.T) {
+		user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "short"})
+
+		assert.ErrorIs(t, err, user_model.ErrPasswordPolicy)
+		assert.Nil(t, user)
+		assert.Empty(t, mockUserRepository.Users)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
+
+	user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123"})
+	assert.NoError(t, err)
+
+	t.Run("Change Password Successfully", func(t *testing.T) {
+		err := userService.ChangePassword(user.ID, user_model.PasswordChange{CurrentPassword: "password123", NewPassword: "newpassword123"})
+		assert.NoError(t, err)
+
+		_, err = userService.LoginUser(user_model.User{Username: "testuser", Password: "newpassword123"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject wrong current password", func(t *testing.T) {
+		err := userService.ChangePassword(user.ID, user_model.PasswordChange{CurrentPassword: "password123", NewPassword: "anotherpassword"})
+
+		assert.ErrorIs(t, err, user_model.ErrInvalidCredentials)
+	})
+
+	t.Run("Should reject weak new password", func(t *testing.T) {
+		err := userService.ChangePassword(user.ID, user_model.PasswordChange{CurrentPassword: "newpassword123", NewPassword: "short"})
+
+		assert.ErrorIs(t, err, user_model.ErrPasswordPolicy)
+	})
+
+	t.Run("Should return error for unknown user", func(t *testing.T) {
+		err := userService.ChangePassword(99, user_model.PasswordChange{CurrentPassword: "password123", NewPassword: "newpassword123"})
+
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestPasswordReset(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+	mockMailer := mocks.NewMockMailer()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mockMailer)
+
+	user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123", Email: "user@example.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, mockUserRepository.SetEmailVerified(user.ID, "user@example.com"))
+
+	t.Run("Should not send mail for unknown user", func(t *testing.T) {
+		err := userService.RequestPasswordReset("unknown")
+
+		assert.NoError(t, err)
+		assert.Empty(t, mockMailer.Messages)
+	})
+
+	t.Run("Reset Password Successfully", func(t *testing.T) {
+		err := userService.RequestPasswordReset("testuser")
+		assert.NoError(t, err)
+
+		message, ok := mockMailer.Last()
+		assert.True(t, ok)
+		assert.Equal(t, "user@example.com", message.To)
+
+		token := strings.TrimSpace(message.Body[strings.LastIndex(strings.TrimSpace(message.Body), "\n"):])
+		assert.Len(t, token, 64)
+		assert.NotContains(t, mockUserRepository.ResetTokens, token)
+
+		err = userService.ResetPassword(user_model.PasswordReset{Token: token, NewPassword: "shortpw"})
+		assert.ErrorIs(t, err, user_model.ErrPasswordPolicy)
+
+		err = userService.ResetPassword(user_model.PasswordReset{Token: token, NewPassword: "resetpassword123"})
+		assert.NoError(t, err)
+
+		_, err = userService.LoginUser(user_model.User{Username: "testuser", Password: "resetpassword123"})
+		assert.NoError(t, err)
+
+		// Tokens are single-use
+		err = userService.ResetPassword(user_model.PasswordReset{Token: token, NewPassword: "resetpassword456"})
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+
+	t.Run("Should reject expired token", func(t *testing.T) {
+		userService.ResetTokenTTL = -time.Minute
+		defer func() { userService.ResetTokenTTL = DefaultResetTokenTTL }()
+
+		assert.NoError(t, userService.RequestPasswordReset("testuser"))
+		message, _ := mockMailer.Last()
+		token := strings.TrimSpace(message.Body[strings.LastIndex(strings.TrimSpace(message.Body), "\n"):])
+
+		err := userService.ResetPassword(user_model.PasswordReset{Token: token, NewPassword: "resetpassword456"})
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+}
+
+func TestCreateUserEmail(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mocks.NewMockMailer())
+
+	t.Run("Should store normalized unverified email", func(t *testing.T) {
+		user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123", Email: " User@Example.com", EmailVerified: true})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "user@example.com", user.Email)
+		assert.False(t, user.EmailVerified)
 	})
 
+	t.Run("Should reject duplicate email", func(t *testing.T) {
+		_, err := userService.CreateUser(user_model.User{Username: "other", Password: "password123", Email: "USER@example.com"})
+
+		assert.ErrorIs(t, err, user_model.ErrEmailAlreadyExists)
+	})
+
+	t.Run("Should reject invalid email", func(t *testing.T) {
+		_, err := userService.CreateUser(user_model.User{Username: "other", Password: "password123", Email: "invalid"})
+
+		assert.ErrorIs(t, err, user_model.ErrInvalidEmail)
+	})
+}
+
+func TestPasswordResetVerifiedEmail(t *testing.T) {
+	mockUserRepository := mocks.NewMockUserRepository()
+	mockMailer := mocks.NewMockMailer()
+
+	userService := NewAuthService(mockUserRepository, DefaultPasswordPolicy(), mockMailer)
+
+	user, err := userService.CreateUser(user_model.User{Username: "testuser", Password: "password123", Email: "user@example.com"})
+	assert.NoError(t, err)
+
+	t.Run("Should not send to unverified email", func(t *testing.T) {
+		assert.NoError(t, userService.RequestPasswordReset("testuser"))
+
+		assert.Empty(t, mockMailer.Messages)
+		assert.Empty(t, mockUserRepository.ResetTokens)
+	})
+
+	t.Run("Should not send without email", func(t *testing.T) {
+		_, err := userService.CreateUser(user_model.User{Username: "noemail", Password: "password123"})
+		assert.NoError(t, err)
+
+		assert.NoError(t, userService.RequestPasswordReset("noemail"))
+		assert.Empty(t, mockMailer.Messages)
+	})
+
+	t.Run("Should send to verified email", func(t *testing.T) {
+		assert.NoError(t, mockUserRepository.SetEmailVerified(user.ID, "user@example.com"))
+		assert.NoError(t, userService.RequestPasswordReset("testuser"))
+
+		message, _ := mockMailer.Last()
+		assert.Equal(t, "user@example.com", message.To)
+	})
 }
@@ -0,0 +1,73 @@
+package auth_service
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+	"url-shortener/internal/app/models/user"
+)
+
+// PasswordPolicy defines the rules a new password must satisfy.
+type PasswordPolicy struct {
+	// MinLength is the minimum number of characters.
+	MinLength int
+	// MaxLength is the maximum number of bytes; bcrypt ignores anything beyond 72.
+	MaxLength int
+	// BreachedHashes holds upper-case hex SHA-1 hashes of known breached passwords.
+	BreachedHashes map[string]struct{}
+}
+
+// DefaultPasswordPolicy returns the policy used when none is configured.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength: 8,
+		MaxLength: 72,
+	}
+}
+
+// Validate checks the password against the policy.
+func (p PasswordPolicy) Validate(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return fmt.Errorf("%w: password must be at least %d characters", user_model.ErrPasswordPolicy, p.MinLength)
+	}
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("%w: password must be at most %d bytes", user_model.ErrPasswordPolicy, p.MaxLength)
+	}
+	if len(p.BreachedHashes) > 0 {
+		sum := sha1.Sum([]byte(password)) // #nosec G401 -- matches the SHA-1 breach list format
+		if _, ok := p.BreachedHashes[strings.ToUpper(hex.EncodeToString(sum[:]))]; ok {
+			return fmt.Errorf("%w: password has appeared in a data breach", user_model.ErrPasswordPolicy)
+		}
+	}
+	return nil
+}
+
+// LoadBreachedHashes reads a breached password list with one SHA-1 hash per line.
+// Lines may carry a ":count" suffix as in the Have I Been Pwned downloads.
+func LoadBreachedHashes(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path) // #nosec G304 -- path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	hashes := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(line, ":")
+		hashes[strings.ToUpper(hash)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return hashes, nil
+}
@@ -0,0 +1,58 @@
+package auth_service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"url-shortener/internal/app/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.BreachedHashes = map[string]struct{}{
+		// SHA-1 of "password123"
+		"CBFDAC6008F9CAB4083784CBD1874F76618D2A97": {},
+	}
+
+	t.Run("Should accept valid password", func(t *testing.T) {
+		assert.NoError(t, policy.Validate("correct horse battery"))
+	})
+
+	t.Run("Should reject short password", func(t *testing.T) {
+		assert.ErrorIs(t, policy.Validate("short"), user_model.ErrPasswordPolicy)
+	})
+
+	t.Run("Should reject password longer than 72 bytes", func(t *testing.T) {
+		assert.ErrorIs(t, policy.Validate(string(make([]byte, 73))), user_model.ErrPasswordPolicy)
+	})
+
+	t.Run("Should reject breached password", func(t *testing.T) {
+		err := policy.Validate("password123")
+
+		assert.ErrorIs(t, err, user_model.ErrPasswordPolicy)
+		assert.Contains(t, err.Error(), "breach")
+	})
+}
+
+func TestLoadBreachedHashes(t *testing.T) {
+	t.Run("Load Successfully", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "breached.txt")
+		content := "# comment\ncbfdac6008f9cab4083784cbd1874f76618d2a97\n\n5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\n"
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+		hashes, err := LoadBreachedHashes(path)
+
+		assert.NoError(t, err)
+		assert.Len(t, hashes, 2)
+		assert.Contains(t, hashes, "CBFDAC6008F9CAB4083784CBD1874F76618D2A97")
+		assert.Contains(t, hashes, "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8")
+	})
+
+	t.Run("Failed to Open File", func(t *testing.T) {
+		_, err := LoadBreachedHashes(filepath.Join(t.TempDir(), "missing.txt"))
+
+		assert.Error(t, err)
+	})
+}
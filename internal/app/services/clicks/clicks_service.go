@@ -1,7 +1,9 @@
 package clicks_service
 
 import (
+	"sort"
 	clicks_model "url-shortener/internal/app/models/clicks"
+	url_model "url-shortener/internal/app/models/url"
 	"url-shortener/internal/app/repositories/clicks"
 )
 
@@ -15,10 +17,11 @@ func NewClicksService(repository clicks_repository.Repository) *Service {
 	return &Service{Repository: repository}
 }
 
-// CreateClick generates a shortened URL for the given original URL.
-func (s *Service) CreateClick(shortURL, ipAddress string) error {
+// CreateClick records a click of the given shortened URL, with the split variant it was served and the
+// revision of the link, if any.
+func (s *Service) CreateClick(shortURL, ipAddress, variant string, revision uint) error {
 	// Save the click in the repository
-	err := s.Repository.CreateClick(shortURL, ipAddress)
+	err := s.Repository.CreateClick(shortURL, ipAddress, variant, revision)
 	if err != nil {
 		return err
 	}
@@ -36,3 +39,57 @@ func (s *Service) GetClicks(shortURL string) ([]clicks_model.Clicks, error) {
 
 	return clicks, nil
 }
+
+// GetVariantStats counts the clicks of the given shortened URL by split variant. The variants of the
+// split come first, in order, followed by removed variants that were clicked, by name.
+func (s *Service) GetVariantStats(shortURL string, split *url_model.Split) ([]clicks_model.VariantStats, error) {
+	counts, err := s.Repository.CountVariantClicks(shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]clicks_model.VariantStats, 0, len(counts))
+	if split != nil {
+		for _, variant := range split.Variants {
+			stats = append(stats, clicks_model.VariantStats{
+				Variant:     variant.Name,
+				Destination: variant.Destination,
+				Weight:      variant.Weight,
+				Clicks:      counts[variant.Name],
+			})
+			delete(counts, variant.Name)
+		}
+	}
+
+	removed := make([]string, 0, len(counts))
+	for name := range counts {
+		removed = append(removed, name)
+	}
+	sort.Strings(removed)
+	for _, name := range removed {
+		stats = append(stats, clicks_model.VariantStats{Variant: name, Clicks: counts[name]})
+	}
+
+	return stats, nil
+}
+
+// GetRevisionStats counts the clicks of the given shortened URL by the revision of the link they were served,
+// in the order of its revisions, newest first. Clicks made before the first change of the link count for its
+// first revision, which records how the link was until then.
+func (s *Service) GetRevisionStats(shortURL string, revisions []url_model.Revision) ([]clicks_model.RevisionStats, error) {
+	counts, err := s.Repository.CountRevisionClicks(shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]clicks_model.RevisionStats, 0, len(revisions))
+	for i, revision := range revisions {
+		clicks := counts[revision.ID]
+		if i == len(revisions)-1 {
+			clicks += counts[0]
+		}
+		stats = append(stats, clicks_model.RevisionStats{Revision: revision.ID, Destination: revision.Destination, Clicks: clicks})
+	}
+
+	return stats, nil
+}
@@ -2,6 +2,8 @@ package clicks_service
 
 import (
 	"testing"
+	clicks_model "url-shortener/internal/app/models/clicks"
+	url_model "url-shortener/internal/app/models/url"
 	"url-shortener/internal/mocks"
 
 	"github.com/stretchr/testify/assert"
@@ -15,7 +17,7 @@ func TestCreateClick(t *testing.T) {
 
 	t.Run("Create Click Successfully", func(t *testing.T) {
 		// Call the CreateClick method
-		err := clickService.CreateClick("test-url", "127.0.0.1")
+		err := clickService.CreateClick("test-url", "127.0.0.1", "", 0)
 
 		// Assertions
 		assert.NoError(t, err)
@@ -25,7 +27,7 @@ func TestCreateClick(t *testing.T) {
 		// Set up repository to return an error
 
 		// Call the CreateClick method
-		err := clickService.CreateClick("invalid", "127.0.0.1")
+		err := clickService.CreateClick("invalid", "127.0.0.1", "", 0)
 
 		// Assertions
 		assert.Error(t, err)
@@ -59,3 +61,79 @@ func TestGetClicks(t *testing.T) {
 		assert.Nil(t, clicks)
 	})
 }
+
+func TestGetVariantStats(t *testing.T) {
+	mockRepository := mocks.NewMockClicksRepository()
+	mockRepository.Clicks = []clicks_model.Clicks{
+		{ID: 1, UrlID: "landing", Variant: "a"},
+		{ID: 2, UrlID: "landing", Variant: "old"},
+		{ID: 3, UrlID: "landing", Variant: "a"},
+	}
+	clickService := NewClicksService(mockRepository)
+	split := &url_model.Split{Variants: []url_model.Variant{
+		{Name: "a", Destination: "https://www.example.com/a", Weight: 1},
+		{Name: "b", Destination: "https://www.example.com/b", Weight: 1},
+	}}
+
+	t.Run("Should count clicks of current and removed variants", func(t *testing.T) {
+		stats, err := clickService.GetVariantStats("landing", split)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []clicks_model.VariantStats{
+			{Variant: "a", Destination: "https://www.example.com/a", Weight: 1, Clicks: 2},
+			{Variant: "b", Destination: "https://www.example.com/b", Weight: 1, Clicks: 0},
+			{Variant: "old", Clicks: 1},
+		}, stats)
+	})
+
+	t.Run("Should count clicks of removed splits", func(t *testing.T) {
+		stats, err := clickService.GetVariantStats("landing", nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, stats, 2)
+	})
+
+	t.Run("Failed to Count Clicks", func(t *testing.T) {
+		_, err := clickService.GetVariantStats("error", split)
+		assert.Error(t, err)
+	})
+}
+
+func TestGetRevisionStats(t *testing.T) {
+	mockRepository := mocks.NewMockClicksRepository()
+	mockRepository.Clicks = []clicks_model.Clicks{
+		{ID: 1, UrlID: "docs"},
+		{ID: 2, UrlID: "docs", Revision: 1},
+		{ID: 3, UrlID: "docs", Revision: 2},
+		{ID: 4, UrlID: "docs", Revision: 2},
+	}
+	clickService := NewClicksService(mockRepository)
+	revisions := []url_model.Revision{
+		{ID: 3, Destination: "https://www.example.com/v1"},
+		{ID: 2, Destination: "https://www.example.com/v2"},
+		{ID: 1, Destination: "https://www.example.com/v1"},
+	}
+
+	t.Run("Should count clicks before the first change for the first revision", func(t *testing.T) {
+		stats, err := clickService.GetRevisionStats("docs", revisions)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []clicks_model.RevisionStats{
+			{Revision: 3, Destination: "https://www.example.com/v1", Clicks: 0},
+			{Revision: 2, Destination: "https://www.example.com/v2", Clicks: 2},
+			{Revision: 1, Destination: "https://www.example.com/v1", Clicks: 2},
+		}, stats)
+	})
+
+	t.Run("Should have no stats for links never changed", func(t *testing.T) {
+		stats, err := clickService.GetRevisionStats("docs", nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, stats)
+	})
+
+	t.Run("Failed to Count Clicks", func(t *testing.T) {
+		_, err := clickService.GetRevisionStats("error", revisions)
+		assert.Error(t, err)
+	})
+}
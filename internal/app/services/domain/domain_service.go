@@ -0,0 +1,451 @@
+package domain_service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/repositories/domain"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/utils"
+)
+
+// verificationTimeout bounds the DNS lookup or HTTP request verifying a domain.
+const verificationTimeout = 10 * time.Second
+
+// maxVerificationBody is the largest verification file read, in bytes.
+const maxVerificationBody = 1024
+
+// labelPattern matches one label of a hostname, in lower case.
+var labelPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Resolver looks up the TXT records verifying domains; *net.Resolver satisfies it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// Service provides custom short domains for users and workspaces.
+type Service struct {
+	Repository domain_repository.Repository
+	// URLService creates the links of the domains and checks the workspace roles of their users.
+	URLService *url_service.Service
+	// Resolver looks up the TXT records of the DNS verification.
+	Resolver Resolver
+	// Client fetches the verification files of the HTTP verification.
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewDomainService creates a new instance of DomainService with the given domain repository and URL service.
+// It verifies domains with net.DefaultResolver and an HTTP client not following redirects until others are set.
+func NewDomainService(repository domain_repository.Repository, urlService *url_service.Service) *Service {
+	return &Service{
+		Repository: repository,
+		URLService: urlService,
+		Resolver:   net.DefaultResolver,
+		Client: &http.Client{
+			Timeout: verificationTimeout,
+			// The file must be served by the domain itself
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now: time.Now,
+	}
+}
+
+// ListDomains returns the personal domains of the user and the domains of their workspaces, by hostname.
+func (s *Service) ListDomains(userID uint) ([]domain_model.Domain, error) {
+	domains, err := s.Repository.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range domains {
+		withVerification(&domains[i])
+	}
+	return domains, nil
+}
+
+// GetDomain returns a domain the user can view.
+func (s *Service) GetDomain(userID, domainID uint) (*domain_model.Domain, error) {
+	domain, err := s.Repository.GetByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, domain, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+	return withVerification(domain), nil
+}
+
+// CreateDomain registers an unverified domain for the user, or for the workspace when one is given.
+// Workspace domains are registered by workspace owners. Several users may claim a hostname until one of
+// them verifies it.
+func (s *Service) CreateDomain(userID uint, hostname string, workspaceID *uint) (*domain_model.Domain, error) {
+	hostname, err := s.validHostname(hostname)
+	if err != nil {
+		return nil, err
+	}
+	domain := &domain_model.Domain{Hostname: hostname, UserID: userID, WorkspaceID: workspaceID}
+	if err := s.authorize(userID, domain, workspace_model.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	// Pending claims share the hostname so that nobody can hold it back from its owner; only verifying takes it
+	claims, err := s.Repository.ListByHostname(hostname)
+	if err != nil {
+		return nil, err
+	}
+	for _, claim := range claims {
+		if claim.Verified || sameOwner(&claim, domain) {
+			return nil, domain_model.ErrDomainAlreadyExists
+		}
+	}
+
+	if domain.Token, err = newToken(); err != nil {
+		return nil, err
+	}
+	created, err := s.Repository.Create(domain)
+	if err != nil {
+		return nil, err
+	}
+	return withVerification(created), nil
+}
+
+// VerifyDomain checks the domain publishes its verification token with the method, and marks it verified.
+// Failed checks return an error wrapping domain_model.ErrVerificationFailed, and hostnames another claim
+// was verified for domain_model.ErrDomainAlreadyExists. The user needs to manage the domain.
+func (s *Service) VerifyDomain(userID, domainID uint, method string) (*domain_model.Domain, error) {
+	if method != domain_model.MethodDNS && method != domain_model.MethodHTTP {
+		return nil, domain_model.ErrInvalidMethod
+	}
+	domain, err := s.Repository.GetByID(domainID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, domain, workspace_model.RoleOwner); err != nil {
+		return nil, err
+	}
+	if domain.Verified {
+		return domain, nil
+	}
+	if serving, err := s.Repository.GetByHostname(domain.Hostname); err == nil && serving.Verified {
+		return nil, domain_model.ErrDomainAlreadyExists
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), verificationTimeout)
+	defer cancel()
+	if method == domain_model.MethodDNS {
+		err = s.checkDNS(ctx, domain)
+	} else {
+		err = s.checkHTTP(ctx, domain)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedAt := s.now().UTC().Truncate(time.Second)
+	if err := s.Repository.SetVerified(domain.ID, verifiedAt); err != nil {
+		return nil, err
+	}
+	domain.Verified = true
+	domain.VerifiedAt = &verifiedAt
+	return domain, nil
+}
+
+// DeleteDomain deletes a domain without links. The user needs to manage the domain.
+func (s *Service) DeleteDomain(userID, domainID uint) error {
+	domain, err := s.Repository.GetByID(domainID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(userID, domain, workspace_model.RoleOwner); err != nil {
+		return err
+	}
+
+	links, err := s.Repository.ListLinks(domain.ID)
+	if err != nil {
+		return err
+	}
+	if len(links) > 0 {
+		return domain_model.ErrDomainInUse
+	}
+	return s.Repository.Delete(domain.ID)
+}
+
+// ListLinks returns the links of a domain the user can view, by code.
+func (s *Service) ListLinks(userID, domainID uint) ([]domain_model.Link, error) {
+	domain, err := s.GetDomain(userID, domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	links, err := s.Repository.ListLinks(domain.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		links[i].ShortLink = shortLink(domain.Hostname, links[i].Code)
+	}
+	return links, nil
+}
+
+// ShortenURL shortens the original URL on a verified domain, using the alias as code on the domain.
+// An empty alias generates a random code. The link belongs to the workspace of workspace domains, whose
+// editors may use them.
+func (s *Service) ShortenURL(userID uint, hostname, originalURL, alias string) (*domain_model.Link, error) {
+	domain, err := s.Repository.GetByHostname(normalizeHost(hostname))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, domain, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+	if !domain.Verified {
+		return nil, domain_model.ErrDomainNotVerified
+	}
+
+	code, err := s.code(domain.ID, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	// The link keeps a random short code of its own, which other endpoints know it by
+	var shortURL string
+	if domain.WorkspaceID != nil {
+		shortURL, err = s.URLService.ShortenURLInWorkspace(originalURL, "", userID, *domain.WorkspaceID)
+	} else {
+		shortURL, err = s.URLService.ShortenURLWithAlias(originalURL, "", &userID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repository.CreateLink(domain.ID, code, shortURL); err != nil {
+		return nil, err
+	}
+
+	return &domain_model.Link{Domain: domain.Hostname, Code: code, ShortenedURL: shortURL, ShortLink: shortLink(domain.Hostname, code)}, nil
+}
+
+// Resolve returns the short code of the link a request for the code on the host is for. Codes on
+// verified custom domains are looked up on the domain, failing with url_model.ErrURLNotFound when
+// missing; on any other host the code is the short code itself.
+func (s *Service) Resolve(host, code string) (string, error) {
+	domain, err := s.Repository.GetByHostname(normalizeHost(host))
+	if err != nil {
+		if errors.Is(err, domain_model.ErrDomainNotFound) {
+			return code, nil
+		}
+		return "", err
+	}
+	if !domain.Verified {
+		return code, nil
+	}
+	return s.Repository.GetLink(domain.ID, code)
+}
+
+// ShortLink returns the address of the link with the short code on its custom domain, failing with
+// url_model.ErrURLNotFound for links on no custom domain.
+func (s *Service) ShortLink(shortURL string) (string, error) {
+	link, err := s.Repository.GetLinkByShortURL(shortURL)
+	if err != nil {
+		return "", err
+	}
+	return shortLink(link.Domain, link.Code), nil
+}
+
+// AllowHost accepts the host when it is a verified custom domain, for the issuance of its certificate.
+func (s *Service) AllowHost(_ context.Context, host string) error {
+	domain, err := s.Repository.GetByHostname(normalizeHost(host))
+	if err != nil {
+		return err
+	}
+	if !domain.Verified {
+		return domain_model.ErrDomainNotVerified
+	}
+	return nil
+}
+
+// ServesHost reports whether the host is a verified custom domain, so the safety policy rejects links to it
+// as redirect loops.
+func (s *Service) ServesHost(host string) bool {
+	return s.AllowHost(context.Background(), host) == nil
+}
+
+// code returns the alias if it is valid and free on the domain, or a random code when it is empty.
+func (s *Service) code(domainID uint, alias string) (string, error) {
+	if alias == "" {
+		return utils.GenerateShortCode(8), nil
+	}
+	if !url_service.ValidAlias(alias) {
+		return "", url_model.ErrInvalidAlias
+	}
+
+	// Check the alias is free on the domain before inserting
+	_, err := s.Repository.GetLink(domainID, alias)
+	if err == nil {
+		return "", url_model.ErrShortCodeAlreadyExists
+	}
+	if !errors.Is(err, url_model.ErrURLNotFound) {
+		return "", err
+	}
+	return alias, nil
+}
+
+// checkDNS looks for the verification value among the TXT records of the domain.
+func (s *Service) checkDNS(ctx context.Context, domain *domain_model.Domain) error {
+	name := domain_model.VerificationPrefix + domain.Hostname
+	records, err := s.Resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%w: no TXT record found at %s", domain_model.ErrVerificationFailed, name)
+	}
+
+	value := dnsValue(domain.Token)
+	for _, record := range records {
+		if strings.TrimSpace(record) == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: no TXT record at %s holds %s", domain_model.ErrVerificationFailed, name, value)
+}
+
+// checkHTTP fetches the verification file of the domain and compares it to the token.
+func (s *Service) checkHTTP(ctx context.Context, domain *domain_model.Domain) error {
+	fileURL := verificationURL(domain.Hostname)
+	// Hostnames pointing at private addresses must not be fetched
+	if err := s.URLService.Safety.Check(fileURL); err != nil {
+		var rejection *url_model.Rejection
+		if errors.As(err, &rejection) {
+			return fmt.Errorf("%w: %s", domain_model.ErrVerificationFailed, rejection.Detail)
+		}
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %s could not be fetched", domain_model.ErrVerificationFailed, fileURL)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered with status %d", domain_model.ErrVerificationFailed, fileURL, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxVerificationBody))
+	if err != nil {
+		return fmt.Errorf("%w: %s could not be read", domain_model.ErrVerificationFailed, fileURL)
+	}
+	if strings.TrimSpace(string(body)) != domain.Token {
+		return fmt.Errorf("%w: %s does not hold the verification token", domain_model.ErrVerificationFailed, fileURL)
+	}
+	return nil
+}
+
+// authorize checks the user may act on the domain with the role: personal domains only by the user who
+// registered them, workspace domains by members with the role.
+func (s *Service) authorize(userID uint, domain *domain_model.Domain, role string) error {
+	if domain.WorkspaceID == nil {
+		if domain.UserID != userID {
+			return url_model.ErrForbidden
+		}
+		return nil
+	}
+
+	member, err := s.URLService.WorkspaceRepository.GetMember(*domain.WorkspaceID, userID)
+	if errors.Is(err, workspace_model.ErrNotMember) {
+		return url_model.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !workspace_model.HasRole(member.Role, role) {
+		return url_model.ErrForbidden
+	}
+	return nil
+}
+
+// validHostname normalizes the hostname and checks it is a public domain name not served by this shortener.
+func (s *Service) validHostname(hostname string) (string, error) {
+	hostname = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(hostname)), ".")
+	labels := strings.Split(hostname, ".")
+	if len(hostname) > 253 || len(labels) < 2 || net.ParseIP(hostname) != nil {
+		return "", domain_model.ErrInvalidHostname
+	}
+	for _, label := range labels {
+		if !labelPattern.MatchString(label) {
+			return "", domain_model.ErrInvalidHostname
+		}
+	}
+	// Top-level domains are never numeric, and local names cannot be verified from the outside
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" || tld == "localhost" || tld == "local" || tld == "internal" {
+		return "", domain_model.ErrInvalidHostname
+	}
+	for _, own := range s.URLService.Safety.OwnHosts {
+		if hostname == strings.ToLower(own) {
+			return "", domain_model.ErrInvalidHostname
+		}
+	}
+	return hostname, nil
+}
+
+// sameOwner reports whether both domains belong to the same user, or to the same workspace.
+func sameOwner(a, b *domain_model.Domain) bool {
+	if a.WorkspaceID != nil || b.WorkspaceID != nil {
+		return a.WorkspaceID != nil && b.WorkspaceID != nil && *a.WorkspaceID == *b.WorkspaceID
+	}
+	return a.UserID == b.UserID
+}
+
+// withVerification adds the verification instructions to an unverified domain.
+func withVerification(domain *domain_model.Domain) *domain_model.Domain {
+	if !domain.Verified {
+		domain.Verification = &domain_model.Verification{
+			DNSName:  domain_model.VerificationPrefix + domain.Hostname,
+			DNSValue: dnsValue(domain.Token),
+			HTTPURL:  verificationURL(domain.Hostname),
+			HTTPBody: domain.Token,
+		}
+	}
+	return domain
+}
+
+func dnsValue(token string) string {
+	return "url-shortener-verification=" + token
+}
+
+func verificationURL(hostname string) string {
+	return "http://" + hostname + domain_model.VerificationPath
+}
+
+func shortLink(hostname, code string) string {
+	return "https://" + hostname + "/" + code
+}
+
+// normalizeHost returns the hostname of a Host header, in lower case and without port or trailing dot.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// newToken returns a random verification token.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
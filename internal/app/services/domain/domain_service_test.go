@@ -0,0 +1,336 @@
+package domain_service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeResolver serves the TXT records of its names.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := r[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// serveDomain points the HTTP client of the service at a server answering for every hostname, keeping
+// its redirect policy.
+func serveDomain(t *testing.T, service *Service, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	service.Client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+}
+
+func TestCreateDomain(t *testing.T) {
+	// User 1 owns workspace 1, in which user 2 is an editor and user 3 a viewer
+	urlRepository := mocks.NewMockUrlRepository()
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	workspaceRepository.Workspaces[1] = &workspace_model.Workspace{ID: 1, Name: "Team"}
+	workspaceRepository.Members[1] = map[uint]string{1: workspace_model.RoleOwner, 2: workspace_model.RoleEditor, 3: workspace_model.RoleViewer}
+	domainRepository := mocks.NewMockDomainRepository()
+	domainRepository.Members = workspaceRepository.Members
+
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	urlService.Safety.OwnHosts = []string{"sho.rt"}
+	service := NewDomainService(domainRepository, urlService)
+	service.Resolver = fakeResolver{}
+
+	t.Run("Should register a normalized domain with its verification", func(t *testing.T) {
+		domain, err := service.CreateDomain(1, " Go.Example.com. ", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "go.example.com", domain.Hostname)
+		assert.False(t, domain.Verified)
+		assert.Len(t, domain.Token, 32)
+		assert.Equal(t, "_url-shortener.go.example.com", domain.Verification.DNSName)
+		assert.Equal(t, "url-shortener-verification="+domain.Token, domain.Verification.DNSValue)
+		assert.Equal(t, "http://go.example.com/.well-known/url-shortener-verification.txt", domain.Verification.HTTPURL)
+	})
+
+	t.Run("Should reject registered and invalid hostnames", func(t *testing.T) {
+		_, err := service.CreateDomain(1, "go.example.com", nil)
+		assert.ErrorIs(t, err, domain_model.ErrDomainAlreadyExists)
+
+		for _, hostname := range []string{"", "example", "127.0.0.1", "go.example.123", "-go.example.com", "my_links.example.com", "printer.local", "Sho.rt", "https://go.example.com"} {
+			_, err = service.CreateDomain(1, hostname, nil)
+			assert.ErrorIs(t, err, domain_model.ErrInvalidHostname, hostname)
+		}
+	})
+
+	t.Run("Should register workspace domains for owners only", func(t *testing.T) {
+		workspaceID := uint(1)
+		domain, err := service.CreateDomain(1, "links.example.org", &workspaceID)
+		assert.NoError(t, err)
+		assert.Equal(t, workspaceID, *domain.WorkspaceID)
+
+		_, err = service.CreateDomain(2, "team.example.org", &workspaceID)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = service.CreateDomain(4, "team.example.org", &workspaceID)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should list and get the domains the user can view", func(t *testing.T) {
+		domains, err := service.ListDomains(1)
+		assert.NoError(t, err)
+		assert.Len(t, domains, 2)
+
+		domains, err = service.ListDomains(3)
+		assert.NoError(t, err)
+		assert.Equal(t, "links.example.org", domains[0].Hostname)
+
+		_, err = service.GetDomain(3, domains[0].ID)
+		assert.NoError(t, err)
+		_, err = service.GetDomain(2, 1)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = service.GetDomain(1, 99)
+		assert.ErrorIs(t, err, domain_model.ErrDomainNotFound)
+	})
+}
+
+func TestVerifyDomain(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	workspaceRepository.Workspaces[1] = &workspace_model.Workspace{ID: 1, Name: "Team"}
+	workspaceRepository.Members[1] = map[uint]string{1: workspace_model.RoleOwner, 2: workspace_model.RoleEditor, 3: workspace_model.RoleViewer}
+	domainRepository := mocks.NewMockDomainRepository()
+	domainRepository.Members = workspaceRepository.Members
+
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	urlService.Safety.OwnHosts = []string{"sho.rt"}
+	service := NewDomainService(domainRepository, urlService)
+	service.Resolver = fakeResolver{}
+
+	t.Run("Should verify with a TXT record", func(t *testing.T) {
+		domain, _ := service.CreateDomain(1, "go.example.com", nil)
+
+		_, err := service.VerifyDomain(1, domain.ID, domain_model.MethodDNS)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+		assert.Contains(t, err.Error(), "no TXT record found at _url-shortener.go.example.com")
+
+		service.Resolver = fakeResolver{"_url-shortener.go.example.com": {"v=spf1 -all", "url-shortener-verification=wrong"}}
+		_, err = service.VerifyDomain(1, domain.ID, domain_model.MethodDNS)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+
+		service.Resolver = fakeResolver{"_url-shortener.go.example.com": {"v=spf1 -all", "url-shortener-verification=" + domain.Token}}
+		verified, err := service.VerifyDomain(1, domain.ID, domain_model.MethodDNS)
+		assert.NoError(t, err)
+		assert.True(t, verified.Verified)
+		assert.NotNil(t, verified.VerifiedAt)
+
+		found, _ := service.GetDomain(1, domain.ID)
+		assert.True(t, found.Verified)
+		assert.Nil(t, found.Verification)
+	})
+
+	t.Run("Should verify with the well-known file", func(t *testing.T) {
+		domain, _ := service.CreateDomain(1, "file.example.com", nil)
+
+		body := "wrong"
+		serveDomain(t, service, func(w http.ResponseWriter, r *http.Request) {
+			if r.Host != "file.example.com" || r.URL.Path != domain_model.VerificationPath {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body + "\n"))
+		})
+
+		_, err := service.VerifyDomain(1, domain.ID, domain_model.MethodHTTP)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+		assert.Contains(t, err.Error(), "does not hold the verification token")
+
+		body = domain.Token
+		verified, err := service.VerifyDomain(1, domain.ID, domain_model.MethodHTTP)
+		assert.NoError(t, err)
+		assert.True(t, verified.Verified)
+	})
+
+	t.Run("Should fail on errors and redirects of the domain", func(t *testing.T) {
+		domain, _ := service.CreateDomain(1, "redirect.example.com", nil)
+
+		serveDomain(t, service, func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "http://elsewhere.example.com/token.txt", http.StatusFound)
+		})
+		_, err := service.VerifyDomain(1, domain.ID, domain_model.MethodHTTP)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+		assert.Contains(t, err.Error(), "status 302")
+	})
+
+	t.Run("Should not fetch domains pointing at private addresses", func(t *testing.T) {
+		service.URLService.Safety.LookupIP = func(string) ([]net.IP, error) { return []net.IP{net.ParseIP("10.0.0.1")}, nil }
+		defer func() { service.URLService.Safety.LookupIP = nil }()
+		domain, _ := service.CreateDomain(1, "private.example.com", nil)
+
+		_, err := service.VerifyDomain(1, domain.ID, domain_model.MethodHTTP)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+	})
+
+	t.Run("Should let the owner verify a hostname squatted by another user", func(t *testing.T) {
+		squatted, err := service.CreateDomain(2, "brand.example.com", nil)
+		assert.NoError(t, err)
+		owned, err := service.CreateDomain(1, "brand.example.com", nil)
+		assert.NoError(t, err)
+
+		service.Resolver = fakeResolver{"_url-shortener.brand.example.com": {"url-shortener-verification=" + owned.Token}}
+		_, err = service.VerifyDomain(2, squatted.ID, domain_model.MethodDNS)
+		assert.ErrorIs(t, err, domain_model.ErrVerificationFailed)
+		verified, err := service.VerifyDomain(1, owned.ID, domain_model.MethodDNS)
+		assert.NoError(t, err)
+		assert.True(t, verified.Verified)
+
+		// Once verified, the hostname is taken
+		service.Resolver = fakeResolver{"_url-shortener.brand.example.com": {"url-shortener-verification=" + squatted.Token}}
+		_, err = service.VerifyDomain(2, squatted.ID, domain_model.MethodDNS)
+		assert.ErrorIs(t, err, domain_model.ErrDomainAlreadyExists)
+		_, err = service.CreateDomain(3, "brand.example.com", nil)
+		assert.ErrorIs(t, err, domain_model.ErrDomainAlreadyExists)
+		assert.NoError(t, service.AllowHost(context.Background(), "brand.example.com"))
+	})
+
+	t.Run("Should reject unknown methods and other users", func(t *testing.T) {
+		domain, _ := service.CreateDomain(1, "other.example.com", nil)
+
+		_, err := service.VerifyDomain(1, domain.ID, "email")
+		assert.ErrorIs(t, err, domain_model.ErrInvalidMethod)
+		_, err = service.VerifyDomain(2, domain.ID, domain_model.MethodDNS)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+}
+
+func TestShortenURL(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	workspaceRepository.Workspaces[1] = &workspace_model.Workspace{ID: 1, Name: "Team"}
+	workspaceRepository.Members[1] = map[uint]string{1: workspace_model.RoleOwner, 2: workspace_model.RoleEditor, 3: workspace_model.RoleViewer}
+	domainRepository := mocks.NewMockDomainRepository()
+	domainRepository.Members = workspaceRepository.Members
+
+	urlService := url_service.NewURLService(urlRepository, workspaceRepository)
+	urlService.Safety.OwnHosts = []string{"sho.rt"}
+	service := NewDomainService(domainRepository, urlService)
+	service.Resolver = fakeResolver{}
+	personal, _ := service.CreateDomain(1, "go.example.com", nil)
+	workspaceID := uint(1)
+	shared, _ := service.CreateDomain(1, "links.example.org", &workspaceID)
+
+	t.Run("Should reject unverified domains", func(t *testing.T) {
+		_, err := service.ShortenURL(1, "go.example.com", "https://www.example.com", "launch")
+		assert.ErrorIs(t, err, domain_model.ErrDomainNotVerified)
+	})
+
+	service.Resolver = fakeResolver{
+		"_url-shortener.go.example.com":    {"url-shortener-verification=" + personal.Token},
+		"_url-shortener.links.example.org": {"url-shortener-verification=" + shared.Token},
+	}
+	_, _ = service.VerifyDomain(1, personal.ID, domain_model.MethodDNS)
+	_, _ = service.VerifyDomain(1, shared.ID, domain_model.MethodDNS)
+
+	t.Run("Should scope codes to the domain", func(t *testing.T) {
+		link, err := service.ShortenURL(1, "Go.Example.com", "https://www.example.com", "launch")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://go.example.com/launch", link.ShortLink)
+		assert.NotEqual(t, "launch", link.ShortenedURL)
+		u, _ := urlRepository.GetURL(link.ShortenedURL)
+		assert.Equal(t, uint(1), u.UserID)
+		assert.Nil(t, u.WorkspaceID)
+
+		other, err := service.ShortenURL(2, "links.example.org", "https://www.example.net", "launch")
+		assert.NoError(t, err)
+		assert.NotEqual(t, link.ShortenedURL, other.ShortenedURL)
+		u, _ = urlRepository.GetURL(other.ShortenedURL)
+		assert.Equal(t, workspaceID, *u.WorkspaceID)
+
+		_, err = service.ShortenURL(1, "go.example.com", "https://www.example.org", "launch")
+		assert.ErrorIs(t, err, url_model.ErrShortCodeAlreadyExists)
+	})
+
+	t.Run("Should generate codes and check aliases and access", func(t *testing.T) {
+		link, err := service.ShortenURL(1, "go.example.com", "https://www.example.com", "")
+		assert.NoError(t, err)
+		assert.Len(t, link.Code, 8)
+
+		_, err = service.ShortenURL(1, "go.example.com", "https://www.example.com", "a/b")
+		assert.ErrorIs(t, err, url_model.ErrInvalidAlias)
+		_, err = service.ShortenURL(2, "go.example.com", "https://www.example.com", "")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = service.ShortenURL(3, "links.example.org", "https://www.example.com", "")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = service.ShortenURL(1, "unknown.example.com", "https://www.example.com", "")
+		assert.ErrorIs(t, err, domain_model.ErrDomainNotFound)
+
+		var rejection *url_model.Rejection
+		_, err = service.ShortenURL(1, "go.example.com", "ftp://www.example.com", "")
+		assert.True(t, errors.As(err, &rejection))
+	})
+
+	t.Run("Should resolve codes by host", func(t *testing.T) {
+		link, _ := domainRepository.GetLink(personal.ID, "launch")
+
+		shortURL, err := service.Resolve("go.example.com:8080", "launch")
+		assert.NoError(t, err)
+		assert.Equal(t, link, shortURL)
+
+		_, err = service.Resolve("GO.EXAMPLE.COM", "missing")
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+
+		shortURL, err = service.Resolve("sho.rt", "abc12345")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc12345", shortURL)
+	})
+
+	t.Run("Should give the address of links on their domain", func(t *testing.T) {
+		link, _ := domainRepository.GetLink(personal.ID, "launch")
+
+		shortLink, err := service.ShortLink(link)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://go.example.com/launch", shortLink)
+
+		_, err = service.ShortLink("abc12345")
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should allow certificates for verified domains", func(t *testing.T) {
+		assert.NoError(t, service.AllowHost(context.Background(), "go.example.com"))
+		assert.ErrorIs(t, service.AllowHost(context.Background(), "unknown.example.com"), domain_model.ErrDomainNotFound)
+
+		pending, _ := service.CreateDomain(1, "pending.example.com", nil)
+		assert.ErrorIs(t, service.AllowHost(context.Background(), pending.Hostname), domain_model.ErrDomainNotVerified)
+	})
+
+	t.Run("Should serve verified domains only", func(t *testing.T) {
+		assert.True(t, service.ServesHost("Go.Example.com."))
+		assert.False(t, service.ServesHost("unknown.example.com"))
+		assert.False(t, service.ServesHost("pending.example.com"))
+	})
+
+	t.Run("Should list links and keep domains with links", func(t *testing.T) {
+		links, err := service.ListLinks(1, personal.ID)
+		assert.NoError(t, err)
+		assert.Len(t, links, 2)
+		assert.Equal(t, "https://go.example.com/"+links[0].Code, links[0].ShortLink)
+
+		assert.ErrorIs(t, service.DeleteDomain(1, personal.ID), domain_model.ErrDomainInUse)
+		assert.ErrorIs(t, service.DeleteDomain(2, shared.ID), url_model.ErrForbidden)
+
+		unused, _ := service.CreateDomain(1, "spare.example.com", nil)
+		assert.NoError(t, service.DeleteDomain(1, unused.ID))
+		_, err = service.GetDomain(1, unused.ID)
+		assert.ErrorIs(t, err, domain_model.ErrDomainNotFound)
+	})
+}
@@ -0,0 +1,139 @@
+package email_service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenFromMessage extracts the token from the verification link in the last captured message.
+func tokenFromMessage(t *testing.T, mailer *mocks.MockMailer) string {
+	message, ok := mailer.Last()
+	assert.True(t, ok)
+
+	body := strings.TrimSpace(message.Body)
+	link, err := url.Parse(body[strings.LastIndex(body, "\n")+1:])
+	assert.NoError(t, err)
+	return link.Query().Get("token")
+}
+
+func TestSendVerificationAndVerify(t *testing.T) {
+	repository := mocks.NewMockUserRepository()
+	mailer := mocks.NewMockMailer()
+	service := NewEmailService(repository, mailer, "secret", "http://localhost:8080/")
+
+	user, _ := repository.Create(&user_model.User{Username: "testuser", Email: "user@example.com"})
+
+	t.Run("Verify Email Successfully", func(t *testing.T) {
+		assert.NoError(t, service.SendVerification(user))
+
+		message, _ := mailer.Last()
+		assert.Equal(t, "user@example.com", message.To)
+		assert.Contains(t, message.Body, "http://localhost:8080/auth/email/verify/?token=")
+
+		assert.NoError(t, service.Verify(tokenFromMessage(t, mailer)))
+		assert.True(t, user.EmailVerified)
+	})
+
+	t.Run("Should reject tampered token", func(t *testing.T) {
+		assert.NoError(t, service.SendVerification(user))
+		token := tokenFromMessage(t, mailer)
+
+		assert.ErrorIs(t, service.Verify(token+"x"), user_model.ErrInvalidVerificationToken)
+		assert.ErrorIs(t, service.Verify("x"+token), user_model.ErrInvalidVerificationToken)
+		assert.ErrorIs(t, service.Verify("invalid"), user_model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Should reject token signed with another key", func(t *testing.T) {
+		otherMailer := mocks.NewMockMailer()
+		other := NewEmailService(repository, otherMailer, "other", "")
+		assert.NoError(t, other.SendVerification(user))
+
+		assert.ErrorIs(t, service.Verify(tokenFromMessage(t, otherMailer)), user_model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Should reject expired token", func(t *testing.T) {
+		assert.NoError(t, service.SendVerification(user))
+		token := tokenFromMessage(t, mailer)
+
+		service.now = func() time.Time { return time.Now().Add(DefaultVerificationTTL + time.Minute) }
+		defer func() { service.now = time.Now }()
+
+		assert.ErrorIs(t, service.Verify(token), user_model.ErrInvalidVerificationToken)
+	})
+
+	t.Run("Should return error without email", func(t *testing.T) {
+		assert.ErrorIs(t, service.SendVerification(&user_model.User{ID: 1}), user_model.ErrInvalidEmail)
+	})
+}
+
+func TestChangeEmail(t *testing.T) {
+	repository := mocks.NewMockUserRepository()
+	mailer := mocks.NewMockMailer()
+	service := NewEmailService(repository, mailer, "secret", "")
+
+	user, _ := repository.Create(&user_model.User{Username: "testuser", Email: "user@example.com", EmailVerified: true})
+	_, _ = repository.Create(&user_model.User{Username: "other", Email: "taken@example.com"})
+
+	t.Run("Should reject invalid email", func(t *testing.T) {
+		assert.ErrorIs(t, service.ChangeEmail(user.ID, "invalid"), user_model.ErrInvalidEmail)
+	})
+
+	t.Run("Should reject email used by another account", func(t *testing.T) {
+		assert.ErrorIs(t, service.ChangeEmail(user.ID, "Taken@Example.com"), user_model.ErrEmailAlreadyExists)
+	})
+
+	t.Run("Change Email Successfully", func(t *testing.T) {
+		assert.NoError(t, service.ChangeEmail(user.ID, "New@Example.com"))
+
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.False(t, user.EmailVerified)
+
+		message, _ := mailer.Last()
+		assert.Equal(t, "new@example.com", message.To)
+	})
+
+	t.Run("Should not verify replaced email", func(t *testing.T) {
+		token := tokenFromMessage(t, mailer)
+		assert.NoError(t, service.ChangeEmail(user.ID, "newer@example.com"))
+
+		assert.ErrorIs(t, service.Verify(token), user_model.ErrInvalidVerificationToken)
+		assert.False(t, user.EmailVerified)
+	})
+}
+
+func TestResendVerification(t *testing.T) {
+	repository := mocks.NewMockUserRepository()
+	mailer := mocks.NewMockMailer()
+	service := NewEmailService(repository, mailer, "secret", "")
+
+	unverified, _ := repository.Create(&user_model.User{Username: "unverified", Email: "user@example.com"})
+	verified, _ := repository.Create(&user_model.User{Username: "verified", Email: "verified@example.com", EmailVerified: true})
+	noEmail, _ := repository.Create(&user_model.User{Username: "noemail"})
+
+	assert.NoError(t, service.ResendVerification(unverified.ID))
+	assert.Len(t, mailer.Messages, 1)
+
+	assert.NoError(t, service.ResendVerification(verified.ID))
+	assert.Len(t, mailer.Messages, 1)
+
+	assert.ErrorIs(t, service.ResendVerification(noEmail.ID), user_model.ErrInvalidEmail)
+	assert.ErrorIs(t, service.ResendVerification(99), user_model.ErrUserNotFound)
+}
+
+func TestRequireVerified(t *testing.T) {
+	repository := mocks.NewMockUserRepository()
+	service := NewEmailService(repository, mocks.NewMockMailer(), "secret", "")
+
+	unverified, _ := repository.Create(&user_model.User{Username: "unverified", Email: "user@example.com"})
+	verified, _ := repository.Create(&user_model.User{Username: "verified", Email: "verified@example.com", EmailVerified: true})
+
+	assert.ErrorIs(t, service.RequireVerified(unverified.ID), user_model.ErrEmailNotVerified)
+	assert.NoError(t, service.RequireVerified(verified.ID))
+	assert.ErrorIs(t, service.RequireVerified(99), user_model.ErrUserNotFound)
+}
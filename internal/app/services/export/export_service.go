@@ -0,0 +1,218 @@
+package export_service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"url-shortener/internal/app/models/clicks"
+	"url-shortener/internal/app/models/export"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/repositories/clicks"
+	"url-shortener/internal/app/repositories/url"
+	"url-shortener/internal/app/services/url"
+)
+
+// DefaultMaxSyncRange is the longest click time range exported while the client waits.
+const DefaultMaxSyncRange = 31 * 24 * time.Hour
+
+// URLColumns are the columns of link exports.
+var URLColumns = []Column{
+	{Name: "short_code", Kind: KindString},
+	{Name: "original_url", Kind: KindString},
+	{Name: "workspace_id", Kind: KindInt, Optional: true},
+	{Name: "disabled", Kind: KindBool},
+	{Name: "expires_at", Kind: KindTime, Optional: true},
+	{Name: "imported_clicks", Kind: KindInt},
+	{Name: "created_at", Kind: KindTime},
+}
+
+// ClickColumns are the columns of click exports.
+var ClickColumns = []Column{
+	{Name: "id", Kind: KindInt},
+	{Name: "short_code", Kind: KindString},
+	{Name: "ip_address", Kind: KindString},
+	{Name: "created_at", Kind: KindTime},
+	{Name: "variant", Kind: KindString, Optional: true},
+}
+
+// Service streams the links and clicks of users in export formats.
+type Service struct {
+	URLRepository    url_repository.Repository
+	ClicksRepository clicks_repository.Repository
+	// URLService checks access to the links whose clicks are exported.
+	URLService *url_service.Service
+	// Dir stores the files written by background exports.
+	Dir string
+	// MaxSyncRange is the longest click time range exported while the client waits.
+	MaxSyncRange time.Duration
+}
+
+// NewExportService creates a new instance of ExportService storing background exports in dir.
+func NewExportService(urlRepository url_repository.Repository, clicksRepository clicks_repository.Repository, urlService *url_service.Service, dir string) *Service {
+	return &Service{
+		URLRepository:    urlRepository,
+		ClicksRepository: clicksRepository,
+		URLService:       urlService,
+		Dir:              dir,
+		MaxSyncRange:     DefaultMaxSyncRange,
+	}
+}
+
+// ExportURLs writes the personal links of the user to w in the format.
+func (s *Service) ExportURLs(userID uint, format string, w io.Writer) error {
+	writer, err := NewWriter(format, w, URLColumns)
+	if err != nil {
+		return err
+	}
+
+	err = s.URLRepository.IterateUserURLs(userID, func(u *url_model.URL) error {
+		var workspaceID, expiresAt interface{}
+		if u.WorkspaceID != nil {
+			workspaceID = int64(*u.WorkspaceID)
+		}
+		if u.ExpiresAt != nil {
+			expiresAt = *u.ExpiresAt
+		}
+		return writer.Write([]interface{}{u.ShortenedURL, u.OriginalURL, workspaceID, u.Disabled, expiresAt, int64(u.ImportedClicks), u.CreatedAt})
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// ParseRange parses the bounds of a click time range, RFC 3339 times or YYYY-MM-DD dates in UTC.
+// Empty bounds are left open, and a date as the upper bound includes the whole day.
+func ParseRange(from, to string) (*time.Time, *time.Time, error) {
+	start, err := parseBound(from, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := parseBound(to, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, export_model.ErrInvalidRange
+	}
+	return start, end, nil
+}
+
+func parseBound(value string, upper bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, export_model.ErrInvalidRange
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// AuthorizeClicks checks that the user may export the clicks selected by the filter. Filters of a
+// link need access to its analytics; the others select the clicks of the user's own links.
+func (s *Service) AuthorizeClicks(userID uint, filter clicks_model.Filter) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return export_model.ErrInvalidRange
+	}
+	if filter.ShortURL == "" {
+		return nil
+	}
+	return s.URLService.GetUserWithShortURL(userID, filter.ShortURL)
+}
+
+// CheckSyncRange rejects the filters of the user's links spanning more than MaxSyncRange,
+// which are exported as background jobs instead.
+func (s *Service) CheckSyncRange(filter clicks_model.Filter) error {
+	if filter.ShortURL != "" {
+		return nil
+	}
+	if filter.From == nil || filter.To == nil || filter.To.Sub(*filter.From) > s.MaxSyncRange {
+		return export_model.ErrRangeTooLarge
+	}
+	return nil
+}
+
+// ExportClicks writes the clicks selected by the filter to w in the format.
+// The caller checks access with AuthorizeClicks.
+func (s *Service) ExportClicks(filter clicks_model.Filter, format string, w io.Writer) error {
+	writer, err := NewWriter(format, w, ClickColumns)
+	if err != nil {
+		return err
+	}
+
+	err = s.ClicksRepository.IterateClicks(filter, func(click *clicks_model.Clicks) error {
+		var variant interface{}
+		if click.Variant != "" {
+			variant = click.Variant
+		}
+		return writer.Write([]interface{}{int64(click.ID), click.UrlID, click.IPAddress, click.CreatedAt, variant})
+	})
+	if err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// ExportToFile runs the export into a new file of Dir, removing the file when it fails.
+func (s *Service) ExportToFile(name, format string, export func(w io.Writer) error) (*export_model.File, error) {
+	if err := os.MkdirAll(s.Dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return nil, err
+	}
+	filename := fmt.Sprintf("%s.%s", name, format)
+	path := filepath.Join(s.Dir, fmt.Sprintf("%s-%s.%s", name, hex.EncodeToString(suffix), format))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) // #nosec G304 -- path is built from a random name
+	if err != nil {
+		return nil, err
+	}
+	err = export(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &export_model.File{Path: path, Filename: filename, Format: format, Size: info.Size()}, nil
+}
+
+// PruneFiles removes the export files of Dir older than maxAge.
+func (s *Service) PruneFiles(maxAge time.Duration) {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || time.Since(info.ModTime()) < maxAge {
+			continue
+		}
+		for format := range export_model.ContentTypes {
+			if strings.HasSuffix(entry.Name(), "."+format) {
+				_ = os.Remove(filepath.Join(s.Dir, entry.Name()))
+			}
+		}
+	}
+}
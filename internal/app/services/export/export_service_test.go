@@ -0,0 +1,176 @@
+package export_service
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/clicks"
+	"url-shortener/internal/app/models/export"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportURLs(t *testing.T) {
+	urlRepo := mocks.NewMockUrlRepository()
+	workspaceID := uint(7)
+	urlRepo.Urls[1] = &url_model.URL{ShortenedURL: "abc", OriginalURL: "https://example.com/a", UserID: 1, ImportedClicks: 4, CreatedAt: testTime}
+	urlRepo.Urls[2] = &url_model.URL{ShortenedURL: "team", OriginalURL: "https://example.com/t", UserID: 1, WorkspaceID: &workspaceID}
+	urlRepo.Urls[3] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://example.com/o", UserID: 2}
+
+	urlService := url_service.NewURLService(urlRepo, mocks.NewMockWorkspaceRepository())
+	exportService := NewExportService(urlRepo, mocks.MockClicksRepository{Urls: urlRepo.Urls}, urlService, t.TempDir())
+
+	t.Run("Should export personal urls of user", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := exportService.ExportURLs(1, export_model.FormatCSV, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "short_code,original_url,workspace_id,disabled,expires_at,imported_clicks,created_at\n"+
+			"abc,https://example.com/a,,false,,4,2026-10-19T12:00:00Z\n", buf.String())
+	})
+
+	t.Run("Should reject invalid format", func(t *testing.T) {
+		err := exportService.ExportURLs(1, "xml", io.Discard)
+		assert.ErrorIs(t, err, export_model.ErrInvalidFormat)
+	})
+
+	t.Run("Should return repository error", func(t *testing.T) {
+		err := exportService.ExportURLs(0, export_model.FormatNDJSON, io.Discard)
+		assert.Error(t, err)
+	})
+}
+
+func TestExportClicks(t *testing.T) {
+	urlRepo := mocks.NewMockUrlRepository()
+	workspaceID := uint(7)
+	urlRepo.Urls[1] = &url_model.URL{ShortenedURL: "abc", OriginalURL: "https://example.com/a", UserID: 1, ImportedClicks: 4, CreatedAt: testTime}
+	urlRepo.Urls[2] = &url_model.URL{ShortenedURL: "team", OriginalURL: "https://example.com/t", UserID: 1, WorkspaceID: &workspaceID}
+	urlRepo.Urls[3] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://example.com/o", UserID: 2}
+	clicksRepo := mocks.MockClicksRepository{Urls: urlRepo.Urls, Clicks: []clicks_model.Clicks{
+		{ID: 1, UrlID: "abc", IPAddress: "1.1.1.1", CreatedAt: testTime},
+		{ID: 2, UrlID: "other", IPAddress: "2.2.2.2", CreatedAt: testTime},
+		{ID: 3, UrlID: "abc", IPAddress: "3.3.3.3", CreatedAt: testTime.Add(48 * time.Hour)},
+	}}
+
+	urlService := url_service.NewURLService(urlRepo, mocks.NewMockWorkspaceRepository())
+	exportService := NewExportService(urlRepo, clicksRepo, urlService, t.TempDir())
+	to := testTime.Add(24 * time.Hour)
+
+	t.Run("Should export clicks of user within range", func(t *testing.T) {
+		var buf bytes.Buffer
+		filter := clicks_model.Filter{UserID: 1, From: &testTime, To: &to}
+		err := exportService.ExportClicks(filter, export_model.FormatNDJSON, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"id":1,"short_code":"abc","ip_address":"1.1.1.1","created_at":"2026-10-19T12:00:00Z","variant":null}`+"\n", buf.String())
+	})
+
+	t.Run("Should export clicks of link", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := exportService.ExportClicks(clicks_model.Filter{ShortURL: "abc"}, export_model.FormatCSV, &buf)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, bytes.Count(buf.Bytes(), []byte("\n")))
+	})
+
+	t.Run("Should return repository error", func(t *testing.T) {
+		err := exportService.ExportClicks(clicks_model.Filter{ShortURL: "error"}, export_model.FormatCSV, io.Discard)
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthorizeClicks(t *testing.T) {
+	urlRepo := mocks.NewMockUrlRepository()
+	workspaceID := uint(7)
+	urlRepo.Urls[1] = &url_model.URL{ShortenedURL: "abc", OriginalURL: "https://example.com/a", UserID: 1, ImportedClicks: 4, CreatedAt: testTime}
+	urlRepo.Urls[2] = &url_model.URL{ShortenedURL: "team", OriginalURL: "https://example.com/t", UserID: 1, WorkspaceID: &workspaceID}
+	urlRepo.Urls[3] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://example.com/o", UserID: 2}
+
+	urlService := url_service.NewURLService(urlRepo, mocks.NewMockWorkspaceRepository())
+	exportService := NewExportService(urlRepo, mocks.MockClicksRepository{Urls: urlRepo.Urls}, urlService, t.TempDir())
+	to := testTime.Add(-time.Hour)
+
+	assert.NoError(t, exportService.AuthorizeClicks(1, clicks_model.Filter{ShortURL: "abc"}))
+	assert.NoError(t, exportService.AuthorizeClicks(1, clicks_model.Filter{UserID: 1}))
+	assert.ErrorIs(t, exportService.AuthorizeClicks(1, clicks_model.Filter{ShortURL: "other"}), url_model.ErrForbidden)
+	assert.ErrorIs(t, exportService.AuthorizeClicks(1, clicks_model.Filter{UserID: 1, From: &testTime, To: &to}), export_model.ErrInvalidRange)
+}
+
+func TestParseRange(t *testing.T) {
+	from, to, err := ParseRange("2026-10-01", "2026-10-31")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), *from)
+	assert.Equal(t, time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC), *to)
+
+	from, to, err = ParseRange("2026-10-19T12:00:00+02:00", "")
+	assert.NoError(t, err)
+	assert.True(t, from.Equal(time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)))
+	assert.Nil(t, to)
+
+	_, _, err = ParseRange("yesterday", "")
+	assert.ErrorIs(t, err, export_model.ErrInvalidRange)
+	_, _, err = ParseRange("2026-10-31", "2026-10-01")
+	assert.ErrorIs(t, err, export_model.ErrInvalidRange)
+}
+
+func TestCheckSyncRange(t *testing.T) {
+	urlRepo := mocks.NewMockUrlRepository()
+	urlService := url_service.NewURLService(urlRepo, mocks.NewMockWorkspaceRepository())
+	exportService := NewExportService(urlRepo, mocks.MockClicksRepository{}, urlService, t.TempDir())
+	week := testTime.Add(7 * 24 * time.Hour)
+	year := testTime.Add(365 * 24 * time.Hour)
+
+	assert.NoError(t, exportService.CheckSyncRange(clicks_model.Filter{ShortURL: "abc"}))
+	assert.NoError(t, exportService.CheckSyncRange(clicks_model.Filter{UserID: 1, From: &testTime, To: &week}))
+	assert.ErrorIs(t, exportService.CheckSyncRange(clicks_model.Filter{UserID: 1, From: &testTime, To: &year}), export_model.ErrRangeTooLarge)
+	assert.ErrorIs(t, exportService.CheckSyncRange(clicks_model.Filter{UserID: 1}), export_model.ErrRangeTooLarge)
+}
+
+func TestExportToFile(t *testing.T) {
+	urlRepo := mocks.NewMockUrlRepository()
+	workspaceID := uint(7)
+	urlRepo.Urls[1] = &url_model.URL{ShortenedURL: "abc", OriginalURL: "https://example.com/a", UserID: 1, ImportedClicks: 4, CreatedAt: testTime}
+	urlRepo.Urls[2] = &url_model.URL{ShortenedURL: "team", OriginalURL: "https://example.com/t", UserID: 1, WorkspaceID: &workspaceID}
+	urlRepo.Urls[3] = &url_model.URL{ShortenedURL: "other", OriginalURL: "https://example.com/o", UserID: 2}
+
+	urlService := url_service.NewURLService(urlRepo, mocks.NewMockWorkspaceRepository())
+	exportService := NewExportService(urlRepo, mocks.MockClicksRepository{Urls: urlRepo.Urls}, urlService, t.TempDir())
+
+	t.Run("Should write export to file", func(t *testing.T) {
+		file, err := exportService.ExportToFile("urls", export_model.FormatCSV, func(w io.Writer) error {
+			return exportService.ExportURLs(1, export_model.FormatCSV, w)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "urls.csv", file.Filename)
+		assert.Equal(t, filepath.Dir(file.Path), exportService.Dir)
+
+		data, err := os.ReadFile(file.Path)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(data)), file.Size)
+	})
+
+	t.Run("Should remove file of failed export", func(t *testing.T) {
+		_, err := exportService.ExportToFile("clicks", export_model.FormatCSV, func(w io.Writer) error {
+			return errors.New("export error")
+		})
+		assert.Error(t, err)
+
+		entries, _ := os.ReadDir(exportService.Dir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("Should prune old files", func(t *testing.T) {
+		exportService.PruneFiles(time.Hour)
+		entries, _ := os.ReadDir(exportService.Dir)
+		assert.Len(t, entries, 1)
+
+		exportService.PruneFiles(0)
+		entries, _ = os.ReadDir(exportService.Dir)
+		assert.Empty(t, entries)
+	})
+}
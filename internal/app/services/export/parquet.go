@@ -0,0 +1,375 @@
+package export_service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// parquetRowGroupSize is the number of rows buffered before they are written as a row group,
+// which bounds the memory used by a Parquet export.
+const parquetRowGroupSize = 8192
+
+// parquetMagic starts and ends Parquet files.
+const parquetMagic = "PAR1"
+
+// Values of the parquet.thrift enums used by the writer.
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	repetitionRequired = 0
+	repetitionOptional = 1
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	pageTypeData      = 0
+	codecUncompressed = 0
+)
+
+// parquetWriter writes flat Parquet files with one uncompressed, PLAIN encoded data page per
+// column chunk. Rows are buffered by column and written as a row group every parquetRowGroupSize rows.
+type parquetWriter struct {
+	w       *countingWriter
+	columns []Column
+	chunks  []columnBuffer
+	rows    int
+	total   int64
+	groups  []rowGroup
+}
+
+// columnBuffer holds the values of a column in the current row group.
+type columnBuffer struct {
+	values bytes.Buffer
+	// defined holds the definition level of each row of optional columns.
+	defined []bool
+	bools   []bool
+}
+
+// rowGroup records where the column chunks of a row group were written, for the footer.
+type rowGroup struct {
+	rows   int64
+	chunks []columnChunk
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// countingWriter tracks the offset of the written data, needed by the footer.
+type countingWriter struct {
+	w      io.Writer
+	offset int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.offset += int64(n)
+	return n, err
+}
+
+func newParquetWriter(w io.Writer, columns []Column) (*parquetWriter, error) {
+	p := &parquetWriter{
+		w:       &countingWriter{w: w},
+		columns: columns,
+		chunks:  make([]columnBuffer, len(columns)),
+	}
+	if _, err := io.WriteString(p.w, parquetMagic); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parquetWriter) Write(row []interface{}) error {
+	for i, value := range row {
+		column, chunk := p.columns[i], &p.chunks[i]
+		if column.Optional {
+			chunk.defined = append(chunk.defined, value != nil)
+		}
+		if value == nil {
+			if !column.Optional {
+				return fmt.Errorf("column %s is required", column.Name)
+			}
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, uint32(len(v)))
+			chunk.values.WriteString(v)
+		case int64:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, v)
+		case time.Time:
+			_ = binary.Write(&chunk.values, binary.LittleEndian, v.UnixMilli())
+		case bool:
+			chunk.bools = append(chunk.bools, v)
+		default:
+			return fmt.Errorf("unsupported value %T", value)
+		}
+	}
+
+	p.rows++
+	if p.rows == parquetRowGroupSize {
+		return p.flush()
+	}
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if p.rows > 0 {
+		if err := p.flush(); err != nil {
+			return err
+		}
+	}
+
+	footer := p.footer()
+	if _, err := p.w.Write(footer); err != nil {
+		return err
+	}
+	if err := binary.Write(p.w, binary.LittleEndian, uint32(len(footer))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(p.w, parquetMagic)
+	return err
+}
+
+// flush writes the buffered rows as a row group.
+func (p *parquetWriter) flush() error {
+	group := rowGroup{rows: int64(p.rows)}
+	for i := range p.chunks {
+		chunk := &p.chunks[i]
+
+		var page bytes.Buffer
+		if p.columns[i].Optional {
+			levels := encodeLevels(chunk.defined)
+			_ = binary.Write(&page, binary.LittleEndian, uint32(len(levels)))
+			page.Write(levels)
+		}
+		if p.columns[i].Kind == KindBool {
+			page.Write(packBools(chunk.bools))
+		} else {
+			page.Write(chunk.values.Bytes())
+		}
+
+		var header thriftWriter
+		header.structValue(func() {
+			header.i32(1, pageTypeData)
+			header.i32(2, int32(page.Len()))
+			header.i32(3, int32(page.Len()))
+			header.structField(5, func() {
+				header.i32(1, int32(p.rows))
+				header.i32(2, encodingPlain)
+				header.i32(3, encodingRLE)
+				header.i32(4, encodingRLE)
+			})
+		})
+
+		offset := p.w.offset
+		if _, err := p.w.Write(header.buf.Bytes()); err != nil {
+			return err
+		}
+		if _, err := p.w.Write(page.Bytes()); err != nil {
+			return err
+		}
+		group.chunks = append(group.chunks, columnChunk{offset: offset, size: p.w.offset - offset})
+
+		chunk.values.Reset()
+		chunk.defined = chunk.defined[:0]
+		chunk.bools = chunk.bools[:0]
+	}
+
+	p.groups = append(p.groups, group)
+	p.total += int64(p.rows)
+	p.rows = 0
+	return nil
+}
+
+// footer encodes the FileMetaData of the file.
+func (p *parquetWriter) footer() []byte {
+	var t thriftWriter
+	t.structValue(func() {
+		t.i32(1, 1)
+		t.listField(2, thriftStruct, len(p.columns)+1)
+		t.structValue(func() {
+			t.binary(4, "schema")
+			t.i32(5, int32(len(p.columns)))
+		})
+		for _, column := range p.columns {
+			column := column
+			t.structValue(func() {
+				physical, converted := parquetTypes(column.Kind)
+				t.i32(1, physical)
+				repetition := int32(repetitionRequired)
+				if column.Optional {
+					repetition = repetitionOptional
+				}
+				t.i32(3, repetition)
+				t.binary(4, column.Name)
+				if converted >= 0 {
+					t.i32(6, converted)
+				}
+			})
+		}
+		t.i64(3, p.total)
+		t.listField(4, thriftStruct, len(p.groups))
+		for _, group := range p.groups {
+			group := group
+			t.structValue(func() {
+				var size int64
+				t.listField(1, thriftStruct, len(group.chunks))
+				for i, chunk := range group.chunks {
+					column, chunk := p.columns[i], chunk
+					size += chunk.size
+					t.structValue(func() {
+						t.i64(2, chunk.offset)
+						t.structField(3, func() {
+							physical, _ := parquetTypes(column.Kind)
+							t.i32(1, physical)
+							t.listField(2, thriftI32, 2)
+							t.listI32(encodingPlain)
+							t.listI32(encodingRLE)
+							t.listField(3, thriftBinary, 1)
+							t.listBinary(column.Name)
+							t.i32(4, codecUncompressed)
+							t.i64(5, group.rows)
+							t.i64(6, chunk.size)
+							t.i64(7, chunk.size)
+							t.i64(9, chunk.offset)
+						})
+					})
+				}
+				t.i64(2, size)
+				t.i64(3, group.rows)
+			})
+		}
+		t.binary(6, "url-shortener")
+	})
+	return t.buf.Bytes()
+}
+
+// parquetTypes returns the physical and converted types of a kind, the latter -1 when unset.
+func parquetTypes(kind Kind) (int32, int32) {
+	switch kind {
+	case KindInt:
+		return parquetInt64, -1
+	case KindBool:
+		return parquetBoolean, -1
+	case KindTime:
+		return parquetInt64, convertedTimestampMillis
+	default:
+		return parquetByteArray, convertedUTF8
+	}
+}
+
+// encodeLevels encodes definition levels of bit width 1 with the RLE/bit-packing hybrid, as RLE runs.
+func encodeLevels(defined []bool) []byte {
+	var buf bytes.Buffer
+	for start := 0; start < len(defined); {
+		end := start
+		for end < len(defined) && defined[end] == defined[start] {
+			end++
+		}
+		buf.Write(binary.AppendUvarint(nil, uint64(end-start)<<1))
+		if defined[start] {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		start = end
+	}
+	return buf.Bytes()
+}
+
+// packBools bit-packs booleans, least significant bit first, as PLAIN encodes them.
+func packBools(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
+
+// Types of the Thrift compact protocol.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Parquet metadata structures with the Thrift compact protocol.
+type thriftWriter struct {
+	buf    bytes.Buffer
+	lastID int16
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.buf.WriteByte(typ)
+		t.buf.Write(binary.AppendVarint(nil, int64(id)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.buf.Write(binary.AppendVarint(nil, int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.buf.Write(binary.AppendVarint(nil, v))
+}
+
+func (t *thriftWriter) binary(id int16, v string) {
+	t.field(id, thriftBinary)
+	t.listBinary(v)
+}
+
+// structField writes a struct field whose fields are written by body.
+func (t *thriftWriter) structField(id int16, body func()) {
+	t.field(id, thriftStruct)
+	t.structValue(body)
+}
+
+// structValue writes a struct, as a top-level value, list element or field value.
+func (t *thriftWriter) structValue(body func()) {
+	saved := t.lastID
+	t.lastID = 0
+	body()
+	t.buf.WriteByte(0)
+	t.lastID = saved
+}
+
+// listField writes the header of a list field; its elements follow.
+func (t *thriftWriter) listField(id int16, elementType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | elementType)
+		return
+	}
+	t.buf.WriteByte(0xf0 | elementType)
+	t.buf.Write(binary.AppendUvarint(nil, uint64(size)))
+}
+
+func (t *thriftWriter) listI32(v int32) {
+	t.buf.Write(binary.AppendVarint(nil, int64(v)))
+}
+
+func (t *thriftWriter) listBinary(v string) {
+	t.buf.Write(binary.AppendUvarint(nil, uint64(len(v))))
+	t.buf.WriteString(v)
+}
@@ -0,0 +1,128 @@
+package export_service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"url-shortener/internal/app/models/export"
+)
+
+// Kind is the type of the values of a column.
+type Kind int
+
+// Kinds of column values. Rows hold them as string, int64, bool and time.Time.
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindTime
+)
+
+// Column describes a column of an export. Optional columns accept nil values.
+type Column struct {
+	Name     string
+	Kind     Kind
+	Optional bool
+}
+
+// Writer writes rows to an export file, one value per column in column order.
+type Writer interface {
+	Write(row []interface{}) error
+	// Close writes the buffered rows and the trailer of the format, without closing the underlying writer.
+	Close() error
+}
+
+// NewWriter returns the Writer of the export format writing to w.
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case export_model.FormatCSV:
+		return newCSVWriter(w, columns)
+	case export_model.FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case export_model.FormatParquet:
+		return newParquetWriter(w, columns)
+	default:
+		return nil, export_model.ErrInvalidFormat
+	}
+}
+
+// csvWriter writes a header row followed by one row per record.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.Name
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: writer}, nil
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			record[i] = v
+		case int64:
+			record[i] = strconv.FormatInt(v, 10)
+		case bool:
+			record[i] = strconv.FormatBool(v)
+		case time.Time:
+			record[i] = v.UTC().Format(time.RFC3339)
+		default:
+			return fmt.Errorf("unsupported value %T", value)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonWriter writes one JSON object per line, with the keys in column order.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []Column
+	line    bytes.Buffer
+}
+
+func (n *ndjsonWriter) Write(row []interface{}) error {
+	n.line.Reset()
+	n.line.WriteByte('{')
+	for i, value := range row {
+		if i > 0 {
+			n.line.WriteByte(',')
+		}
+		if t, ok := value.(time.Time); ok {
+			value = t.UTC().Format(time.RFC3339)
+		}
+		key, _ := json.Marshal(n.columns[i].Name)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		n.line.Write(key)
+		n.line.WriteByte(':')
+		n.line.Write(encoded)
+	}
+	n.line.WriteString("}\n")
+	_, err := n.w.Write(n.line.Bytes())
+	return err
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,292 @@
+package export_service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/export"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	parquet_reader "github.com/xitongsys/parquet-go/reader"
+)
+
+var testColumns = []Column{
+	{Name: "code", Kind: KindString},
+	{Name: "count", Kind: KindInt},
+	{Name: "enabled", Kind: KindBool},
+	{Name: "expires_at", Kind: KindTime, Optional: true},
+}
+
+var testTime = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+func writeRows(t *testing.T, format string, rows [][]interface{}) []byte {
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf, testColumns)
+	require.NoError(t, err)
+	for _, row := range rows {
+		require.NoError(t, writer.Write(row))
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestWriter(t *testing.T) {
+	rows := [][]interface{}{
+		{"abc", int64(3), true, testTime},
+		{"d,e", int64(-1), false, nil},
+	}
+
+	t.Run("Should write CSV", func(t *testing.T) {
+		data := writeRows(t, export_model.FormatCSV, rows)
+		assert.Equal(t, "code,count,enabled,expires_at\nabc,3,true,2026-10-19T12:00:00Z\n\"d,e\",-1,false,\n", string(data))
+	})
+
+	t.Run("Should write NDJSON", func(t *testing.T) {
+		data := writeRows(t, export_model.FormatNDJSON, rows)
+		assert.Equal(t, `{"code":"abc","count":3,"enabled":true,"expires_at":"2026-10-19T12:00:00Z"}`+"\n"+
+			`{"code":"d,e","count":-1,"enabled":false,"expires_at":null}`+"\n", string(data))
+	})
+
+	t.Run("Should reject unknown format", func(t *testing.T) {
+		_, err := NewWriter("xlsx", &bytes.Buffer{}, testColumns)
+		assert.ErrorIs(t, err, export_model.ErrInvalidFormat)
+	})
+}
+
+func TestParquetWriter(t *testing.T) {
+	t.Run("Should write readable file", func(t *testing.T) {
+		data := writeRows(t, export_model.FormatParquet, [][]interface{}{
+			{"abc", int64(3), true, testTime},
+			{"d,e", int64(-1), false, nil},
+			{"", int64(0), true, testTime.Add(time.Second)},
+		})
+
+		file := readParquet(t, data)
+		assert.Equal(t, int64(3), file.numRows)
+		assert.Equal(t, []string{"code", "count", "enabled", "expires_at"}, file.names)
+		assert.Equal(t, [][]interface{}{
+			{"abc", "d,e", ""},
+			{int64(3), int64(-1), int64(0)},
+			{true, false, true},
+			{testTime.UnixMilli(), nil, testTime.Add(time.Second).UnixMilli()},
+		}, file.columns)
+	})
+
+	t.Run("Should be readable by parquet-go", func(t *testing.T) {
+		rows := make([][]interface{}, parquetRowGroupSize+2)
+		for i := range rows {
+			rows[i] = []interface{}{fmt.Sprint("é", i), int64(-i), i%3 == 0, testTime.Add(time.Duration(i) * time.Second)}
+		}
+		rows[1][3] = nil
+		source, err := buffer.NewBufferFile(writeRows(t, export_model.FormatParquet, rows))
+		require.NoError(t, err)
+
+		reader, err := parquet_reader.NewParquetColumnReader(source, 1)
+		require.NoError(t, err)
+		defer reader.ReadStop()
+		assert.Equal(t, int64(len(rows)), reader.GetNumRows())
+		assert.Len(t, reader.Footer.RowGroups, 2)
+
+		for i, column := range testColumns {
+			values, _, _, err := reader.ReadColumnByIndex(int64(i), reader.GetNumRows())
+			require.NoError(t, err, column.Name)
+			require.Len(t, values, len(rows), column.Name)
+			for row, value := range values {
+				want := rows[row][i]
+				if at, ok := want.(time.Time); ok {
+					want = at.UnixMilli()
+				}
+				assert.Equal(t, want, value, "%s of row %d", column.Name, row)
+			}
+		}
+	})
+
+	t.Run("Should split rows into row groups", func(t *testing.T) {
+		rows := make([][]interface{}, parquetRowGroupSize+1)
+		for i := range rows {
+			rows[i] = []interface{}{fmt.Sprint(i), int64(i), i%2 == 0, nil}
+		}
+		file := readParquet(t, writeRows(t, export_model.FormatParquet, rows))
+		assert.Equal(t, int64(parquetRowGroupSize+1), file.numRows)
+		assert.Equal(t, 2, file.rowGroups)
+		assert.Len(t, file.columns[1], parquetRowGroupSize+1)
+		assert.Equal(t, int64(parquetRowGroupSize), file.columns[1][parquetRowGroupSize])
+		assert.Equal(t, true, file.columns[2][parquetRowGroupSize])
+	})
+
+	t.Run("Should write empty file", func(t *testing.T) {
+		file := readParquet(t, writeRows(t, export_model.FormatParquet, nil))
+		assert.Equal(t, int64(0), file.numRows)
+		assert.Equal(t, 0, file.rowGroups)
+	})
+
+	t.Run("Should reject missing required value", func(t *testing.T) {
+		writer, _ := NewWriter(export_model.FormatParquet, &bytes.Buffer{}, testColumns)
+		assert.Error(t, writer.Write([]interface{}{nil, int64(1), true, nil}))
+	})
+}
+
+// parquetFile is what the tests read back from a Parquet file.
+type parquetFile struct {
+	numRows   int64
+	rowGroups int
+	names     []string
+	// columns holds the values of each column, nil for nulls and times in milliseconds.
+	columns [][]interface{}
+}
+
+// readParquet decodes the files written by parquetWriter, following the metadata of the footer.
+func readParquet(t *testing.T, data []byte) parquetFile {
+	require.True(t, bytes.HasPrefix(data, []byte(parquetMagic)))
+	require.True(t, bytes.HasSuffix(data, []byte(parquetMagic)))
+	length := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footer := &thriftReader{data: data[len(data)-8-length : len(data)-8]}
+	metadata := footer.readStruct()
+
+	var file parquetFile
+	file.numRows = metadata[3].(int64)
+	schema := metadata[2].([]interface{})
+	assert.Equal(t, "schema", schema[0].(map[int16]interface{})[4])
+	var types []int32
+	var optional []bool
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]interface{})
+		file.names = append(file.names, fields[4].(string))
+		types = append(types, fields[1].(int32))
+		optional = append(optional, fields[3].(int32) == repetitionOptional)
+	}
+	file.columns = make([][]interface{}, len(file.names))
+
+	groups := metadata[4].([]interface{})
+	file.rowGroups = len(groups)
+	for _, group := range groups {
+		for i, chunk := range group.(map[int16]interface{})[1].([]interface{}) {
+			meta := chunk.(map[int16]interface{})[3].(map[int16]interface{})
+			assert.Equal(t, []interface{}{file.names[i]}, meta[3])
+			offset := meta[9].(int64)
+			size := meta[7].(int64)
+
+			page := &thriftReader{data: data[offset : offset+size]}
+			header := page.readStruct()
+			numValues := int(header[5].(map[int16]interface{})[1].(int32))
+			values := page.data[page.pos:]
+			require.Equal(t, int(header[2].(int32)), len(values))
+
+			file.columns[i] = append(file.columns[i], decodePage(t, values, types[i], optional[i], numValues)...)
+		}
+	}
+	return file
+}
+
+func decodePage(t *testing.T, page []byte, typ int32, optional bool, numValues int) []interface{} {
+	defined := make([]bool, numValues)
+	for i := range defined {
+		defined[i] = true
+	}
+	if optional {
+		length := binary.LittleEndian.Uint32(page)
+		levels := page[4 : 4+length]
+		page = page[4+length:]
+		defined = defined[:0]
+		for len(levels) > 0 {
+			header, n := binary.Uvarint(levels)
+			require.Zero(t, header&1, "only RLE runs are written")
+			for j := uint64(0); j < header>>1; j++ {
+				defined = append(defined, levels[n] == 1)
+			}
+			levels = levels[n+1:]
+		}
+		require.Len(t, defined, numValues)
+	}
+
+	values := make([]interface{}, 0, numValues)
+	bit := 0
+	for _, isDefined := range defined {
+		if !isDefined {
+			values = append(values, nil)
+			continue
+		}
+		switch typ {
+		case parquetByteArray:
+			length := binary.LittleEndian.Uint32(page)
+			values = append(values, string(page[4:4+length]))
+			page = page[4+length:]
+		case parquetInt64:
+			values = append(values, int64(binary.LittleEndian.Uint64(page)))
+			page = page[8:]
+		case parquetBoolean:
+			values = append(values, page[bit/8]&(1<<(bit%8)) != 0)
+			bit++
+		}
+	}
+	return values
+}
+
+// thriftReader decodes the Thrift compact protocol structures written by thriftWriter.
+type thriftReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) varint() int64 {
+	v, n := binary.Varint(r.data[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		header := r.data[r.pos]
+		r.pos++
+		if header == 0 {
+			return fields
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.readValue(header & 0x0f)
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) interface{} {
+	switch typ {
+	case thriftI32:
+		return int32(r.varint())
+	case thriftI64:
+		return r.varint()
+	case thriftBinary:
+		length := int(r.uvarint())
+		r.pos += length
+		return string(r.data[r.pos-length : r.pos])
+	case thriftList:
+		header := r.data[r.pos]
+		r.pos++
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0f)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	default:
+		panic(fmt.Sprintf("unsupported thrift type %d", typ))
+	}
+}
@@ -0,0 +1,100 @@
+package folder_service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"url-shortener/internal/app/models/folder"
+	"url-shortener/internal/app/repositories/folder"
+	"url-shortener/internal/app/services/url"
+)
+
+// Service provides folder functionalities for the personal links of a user.
+type Service struct {
+	Repository folder_repository.Repository
+	// URLService checks the ownership of the links being moved.
+	URLService *url_service.Service
+}
+
+// NewFolderService creates a new instance of FolderService with the given folder repository and URL service.
+func NewFolderService(repository folder_repository.Repository, urlService *url_service.Service) *Service {
+	return &Service{Repository: repository, URLService: urlService}
+}
+
+// ListFolders returns the folders of the user by name, with the number of links in each.
+func (s *Service) ListFolders(userID uint) ([]folder_model.Folder, error) {
+	return s.Repository.List(userID)
+}
+
+// CreateFolder creates a folder for the user.
+func (s *Service) CreateFolder(userID uint, name string) (*folder_model.Folder, error) {
+	name, err := s.freeName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Repository.Create(userID, name)
+}
+
+// RenameFolder renames a folder of the user.
+func (s *Service) RenameFolder(userID, folderID uint, name string) (*folder_model.Folder, error) {
+	folder, err := s.Repository.GetByID(userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+	if folder.Name == strings.TrimSpace(name) {
+		return folder, nil
+	}
+
+	name, err = s.freeName(userID, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repository.Rename(folderID, name); err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	return folder, nil
+}
+
+// DeleteFolder deletes a folder of the user. Its links are kept, outside of any folder.
+func (s *Service) DeleteFolder(userID, folderID uint) error {
+	if _, err := s.Repository.GetByID(userID, folderID); err != nil {
+		return err
+	}
+
+	return s.Repository.Delete(folderID)
+}
+
+// MoveURL moves a personal link of the user into one of their folders, or out of its folder when nil.
+func (s *Service) MoveURL(userID uint, shortURL string, folderID *uint) error {
+	if err := s.URLService.RequireOwner(userID, shortURL); err != nil {
+		return err
+	}
+	if folderID != nil {
+		if _, err := s.Repository.GetByID(userID, *folderID); err != nil {
+			return err
+		}
+	}
+
+	return s.URLService.Repository.SetFolder(shortURL, folderID)
+}
+
+// freeName trims and validates a folder name, and checks the user has no folder with it.
+func (s *Service) freeName(userID uint, name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 50 {
+		return "", folder_model.ErrInvalidName
+	}
+
+	// Check the name is free before inserting
+	_, err := s.Repository.GetByName(userID, name)
+	if err == nil {
+		return "", folder_model.ErrFolderAlreadyExists
+	}
+	if !errors.Is(err, folder_model.ErrFolderNotFound) {
+		return "", err
+	}
+	return name, nil
+}
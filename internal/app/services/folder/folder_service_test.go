@@ -0,0 +1,128 @@
+package folder_service
+
+import (
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/folder"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateFolder(t *testing.T) {
+	// User 1 owns the link "first", with a workspace link "shared" and a link "other" of user 2
+	urlRepository := mocks.NewMockUrlRepository()
+	folderRepository := mocks.NewMockFolderRepository()
+	folderRepository.Urls = urlRepository.Urls
+
+	owner, otherOwner := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &otherOwner)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewFolderService(folderRepository, urlService)
+
+	t.Run("Should create a trimmed folder", func(t *testing.T) {
+		folder, err := service.CreateFolder(1, " Campaigns ")
+		assert.NoError(t, err)
+		assert.Equal(t, "Campaigns", folder.Name)
+
+		folders, err := service.ListFolders(1)
+		assert.NoError(t, err)
+		assert.Len(t, folders, 1)
+	})
+
+	t.Run("Should reject duplicate and invalid names", func(t *testing.T) {
+		_, err := service.CreateFolder(1, "Campaigns")
+		assert.ErrorIs(t, err, folder_model.ErrFolderAlreadyExists)
+
+		_, err = service.CreateFolder(1, " ")
+		assert.ErrorIs(t, err, folder_model.ErrInvalidName)
+
+		_, err = service.CreateFolder(1, strings.Repeat("a", 51))
+		assert.ErrorIs(t, err, folder_model.ErrInvalidName)
+	})
+}
+
+func TestRenameAndDeleteFolder(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	folderRepository := mocks.NewMockFolderRepository()
+	folderRepository.Urls = urlRepository.Urls
+
+	owner, otherOwner := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &otherOwner)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewFolderService(folderRepository, urlService)
+	folder, _ := service.CreateFolder(1, "Campaigns")
+	_, _ = service.CreateFolder(1, "Archive")
+
+	t.Run("Should rename the folder", func(t *testing.T) {
+		renamed, err := service.RenameFolder(1, folder.ID, "Launch")
+		assert.NoError(t, err)
+		assert.Equal(t, "Launch", renamed.Name)
+
+		renamed, err = service.RenameFolder(1, folder.ID, "Launch")
+		assert.NoError(t, err)
+		assert.Equal(t, "Launch", renamed.Name)
+	})
+
+	t.Run("Should reject taken names and folders of other users", func(t *testing.T) {
+		_, err := service.RenameFolder(1, folder.ID, "Archive")
+		assert.ErrorIs(t, err, folder_model.ErrFolderAlreadyExists)
+
+		_, err = service.RenameFolder(2, folder.ID, "Mine")
+		assert.ErrorIs(t, err, folder_model.ErrFolderNotFound)
+
+		assert.ErrorIs(t, service.DeleteFolder(2, folder.ID), folder_model.ErrFolderNotFound)
+	})
+
+	t.Run("Should keep the links of a deleted folder", func(t *testing.T) {
+		assert.NoError(t, service.MoveURL(1, "first", &folder.ID))
+		assert.NoError(t, service.DeleteFolder(1, folder.ID))
+
+		u, err := urlRepository.GetURL("first")
+		assert.NoError(t, err)
+		assert.Nil(t, u.FolderID)
+	})
+}
+
+func TestMoveURL(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	folderRepository := mocks.NewMockFolderRepository()
+	folderRepository.Urls = urlRepository.Urls
+
+	owner, otherOwner := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &otherOwner)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewFolderService(folderRepository, urlService)
+	folder, _ := service.CreateFolder(1, "Campaigns")
+	other, _ := service.CreateFolder(2, "Campaigns")
+
+	t.Run("Should move a link in and out of a folder", func(t *testing.T) {
+		assert.NoError(t, service.MoveURL(1, "first", &folder.ID))
+
+		folders, err := service.ListFolders(1)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, folders[0].URLCount)
+
+		assert.NoError(t, service.MoveURL(1, "first", nil))
+		u, _ := urlRepository.GetURL("first")
+		assert.Nil(t, u.FolderID)
+	})
+
+	t.Run("Should only move personal links of the user into their folders", func(t *testing.T) {
+		assert.ErrorIs(t, service.MoveURL(1, "other", &folder.ID), url_model.ErrForbidden)
+		assert.ErrorIs(t, service.MoveURL(1, "shared", &folder.ID), url_model.ErrForbidden)
+		assert.ErrorIs(t, service.MoveURL(1, "first", &other.ID), folder_model.ErrFolderNotFound)
+		assert.ErrorIs(t, service.MoveURL(1, "missing", nil), url_model.ErrURLNotFound)
+	})
+}
@@ -0,0 +1,126 @@
+package job_service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+	"url-shortener/internal/app/models/job"
+)
+
+// DefaultRetention is how long finished jobs are kept for their status to be read.
+const DefaultRetention = 24 * time.Hour
+
+// Task is the work of a job. It reports the number of processed items through progress.
+type Task func(progress func(processed int)) (interface{}, error)
+
+// Service runs jobs in the background and keeps their status in memory.
+type Service struct {
+	// Retention is how long finished jobs are kept.
+	Retention time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*job_model.Job
+	now  func() time.Time
+}
+
+// NewJobService creates a new instance of JobService.
+func NewJobService() *Service {
+	return &Service{
+		Retention: DefaultRetention,
+		jobs:      make(map[string]*job_model.Job),
+		now:       time.Now,
+	}
+}
+
+// Start queues the task as a job of the user and runs it in the background.
+func (s *Service) Start(userID uint, kind string, total int, task Task) (*job_model.Job, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.prune()
+	job := &job_model.Job{
+		ID:        id,
+		Kind:      kind,
+		UserID:    userID,
+		Status:    job_model.StatusQueued,
+		Total:     total,
+		CreatedAt: s.now(),
+	}
+	s.jobs[id] = job
+	snapshot := *job
+	s.mu.Unlock()
+
+	go s.run(job, task)
+	return &snapshot, nil
+}
+
+// Get returns a copy of the job of the user with the given ID.
+func (s *Service) Get(userID uint, id string) (*job_model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	// Jobs of other users are reported as missing so their IDs cannot be probed
+	if !ok || job.UserID != userID {
+		return nil, job_model.ErrJobNotFound
+	}
+	snapshot := *job
+	return &snapshot, nil
+}
+
+func (s *Service) run(job *job_model.Job, task Task) {
+	s.update(job, func() { job.Status = job_model.StatusRunning })
+
+	result, err := func() (result interface{}, err error) {
+		// A panicking task fails its job instead of the process
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		return task(func(processed int) {
+			s.update(job, func() { job.Processed = processed })
+		})
+	}()
+
+	s.update(job, func() {
+		finishedAt := s.now()
+		job.FinishedAt = &finishedAt
+		if err != nil {
+			job.Status = job_model.StatusFailed
+			job.Error = err.Error()
+			return
+		}
+		job.Status = job_model.StatusCompleted
+		job.Processed = job.Total
+		job.Result = result
+	})
+}
+
+func (s *Service) update(job *job_model.Job, change func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	change()
+}
+
+// prune drops the jobs finished longer than the retention ago. The caller holds the lock.
+func (s *Service) prune() {
+	for id, job := range s.jobs {
+		if job.FinishedAt != nil && s.now().Sub(*job.FinishedAt) > s.Retention {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,84 @@
+package job_service
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/job"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// wait polls the job until it has finished.
+func wait(t *testing.T, s *Service, userID uint, id string) *job_model.Job {
+	var job *job_model.Job
+	assert.Eventually(t, func() bool {
+		job, _ = s.Get(userID, id)
+		return job.FinishedAt != nil
+	}, time.Second, time.Millisecond)
+	return job
+}
+
+func TestJobService(t *testing.T) {
+	jobService := NewJobService()
+
+	t.Run("Should run job to completion", func(t *testing.T) {
+		release := make(chan struct{})
+		job, err := jobService.Start(1, "bulk", 2, func(progress func(int)) (interface{}, error) {
+			progress(1)
+			<-release
+			return "done", nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, job_model.StatusQueued, job.Status)
+		assert.Len(t, job.ID, 32)
+
+		assert.Eventually(t, func() bool {
+			running, _ := jobService.Get(1, job.ID)
+			return running.Status == job_model.StatusRunning && running.Processed == 1
+		}, time.Second, time.Millisecond)
+		close(release)
+
+		finished := wait(t, jobService, 1, job.ID)
+		assert.Equal(t, job_model.StatusCompleted, finished.Status)
+		assert.Equal(t, 2, finished.Processed)
+		assert.Equal(t, "done", finished.Result)
+	})
+
+	t.Run("Should report failures and panics", func(t *testing.T) {
+		failed, _ := jobService.Start(1, "bulk", 1, func(func(int)) (interface{}, error) {
+			return nil, errors.New("task error")
+		})
+		panicked, _ := jobService.Start(1, "bulk", 1, func(func(int)) (interface{}, error) {
+			panic("boom")
+		})
+
+		job := wait(t, jobService, 1, failed.ID)
+		assert.Equal(t, job_model.StatusFailed, job.Status)
+		assert.Equal(t, "task error", job.Error)
+
+		job = wait(t, jobService, 1, panicked.ID)
+		assert.Equal(t, job_model.StatusFailed, job.Status)
+		assert.Contains(t, job.Error, "boom")
+	})
+
+	t.Run("Should hide jobs of other users", func(t *testing.T) {
+		job, _ := jobService.Start(1, "bulk", 0, func(func(int)) (interface{}, error) { return nil, nil })
+
+		_, err := jobService.Get(2, job.ID)
+		assert.ErrorIs(t, err, job_model.ErrJobNotFound)
+		_, err = jobService.Get(1, "missing")
+		assert.ErrorIs(t, err, job_model.ErrJobNotFound)
+	})
+
+	t.Run("Should prune finished jobs after the retention", func(t *testing.T) {
+		job, _ := jobService.Start(1, "bulk", 0, func(func(int)) (interface{}, error) { return nil, nil })
+		wait(t, jobService, 1, job.ID)
+
+		jobService.now = func() time.Time { return time.Now().Add(DefaultRetention + time.Minute) }
+		_, _ = jobService.Start(1, "bulk", 0, func(func(int)) (interface{}, error) { return nil, nil })
+
+		_, err := jobService.Get(1, job.ID)
+		assert.ErrorIs(t, err, job_model.ErrJobNotFound)
+	})
+}
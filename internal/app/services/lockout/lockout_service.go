@@ -0,0 +1,212 @@
+package lockout_service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"url-shortener/internal/app/models/audit"
+	"url-shortener/internal/app/repositories/audit"
+)
+
+// ErrTooManyAttempts is returned while a username or IP address is backing off or locked out.
+var ErrTooManyAttempts = errors.New("too many failed login attempts, try again later")
+
+// Config holds the thresholds used to throttle failed logins.
+type Config struct {
+	// MaxUserAttempts is the number of failures for a username before it is locked.
+	MaxUserAttempts int
+	// MaxIPAttempts is the number of failures from an IP address before it is locked.
+	MaxIPAttempts int
+	// BaseDelay is the backoff after the first failure; it doubles on every further failure.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff.
+	MaxDelay time.Duration
+	// LockoutDuration is how long a key stays locked once the attempt limit is reached.
+	LockoutDuration time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// DefaultConfig returns the thresholds used when none are configured.
+func DefaultConfig() Config {
+	return Config{
+		MaxUserAttempts: 5,
+		MaxIPAttempts:   20,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		Window:          time.Hour,
+	}
+}
+
+// sweepInterval is how often the failures of keys not seen again are forgotten.
+const sweepInterval = time.Minute
+
+// attempts tracks the failed logins recorded for a single key.
+type attempts struct {
+	failures     int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// Service tracks failed logins per username and per IP address.
+type Service struct {
+	Config          Config
+	AuditRepository audit_repository.Repository
+
+	mu        sync.Mutex
+	attempts  map[string]*attempts
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLockoutService creates a new instance of LockoutService with the given config and audit repository.
+func NewLockoutService(config Config, auditRepository audit_repository.Repository) *Service {
+	return &Service{
+		Config:          config,
+		AuditRepository: auditRepository,
+		attempts:        make(map[string]*attempts),
+		now:             time.Now,
+	}
+}
+
+// Check reports whether a login for the given username and IP address may proceed.
+// When it may not, the remaining wait is returned along with ErrTooManyAttempts.
+func (s *Service) Check(username, ipAddress string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+	var wait time.Duration
+	for _, key := range []string{userKey(username), ipKey(ipAddress)} {
+		a := s.current(key, now)
+		if a != nil && a.blockedUntil.After(now) && a.blockedUntil.Sub(now) > wait {
+			wait = a.blockedUntil.Sub(now)
+		}
+	}
+
+	if wait > 0 {
+		return wait, ErrTooManyAttempts
+	}
+	return 0, nil
+}
+
+// RegisterFailure records a failed login for the given username and IP address.
+// Every failure backs the key off exponentially; reaching the attempt limit locks it.
+func (s *Service) RegisterFailure(username, ipAddress string) {
+	s.mu.Lock()
+	now := s.now()
+	s.sweep(now)
+	userLocked := s.fail(userKey(username), s.Config.MaxUserAttempts, now)
+	ipLocked := s.fail(ipKey(ipAddress), s.Config.MaxIPAttempts, now)
+	s.mu.Unlock()
+
+	// Audit entries are written after releasing the lock so other logins do not wait for the database
+	if userLocked {
+		s.audit(userKey(username), ipAddress, s.Config.MaxUserAttempts)
+	}
+	if ipLocked {
+		s.audit(ipKey(ipAddress), ipAddress, s.Config.MaxIPAttempts)
+	}
+}
+
+// Reset clears the failures recorded for the given username after a successful login.
+// Failures from the IP address are kept so that one valid account cannot unlock a guessing client.
+func (s *Service) Reset(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, userKey(username))
+}
+
+// current returns the tracked attempts for the key, forgetting them once the window has passed.
+func (s *Service) current(key string, now time.Time) *attempts {
+	a, ok := s.attempts[key]
+	if !ok {
+		return nil
+	}
+	if s.expired(a, now) {
+		delete(s.attempts, key)
+		return nil
+	}
+	return a
+}
+
+// sweep forgets the expired failures of all keys, so usernames and IP addresses that are not seen
+// again do not use memory forever.
+func (s *Service) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, a := range s.attempts {
+		if s.expired(a, now) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// expired reports whether the attempts are no longer blocking and out of the window.
+func (s *Service) expired(a *attempts, now time.Time) bool {
+	return now.After(a.blockedUntil) && now.Sub(a.lastFailure) > s.Config.Window
+}
+
+// fail records a failure for the key and reports whether it locked the key.
+func (s *Service) fail(key string, maxAttempts int, now time.Time) bool {
+	a := s.current(key, now)
+	if a == nil {
+		a = &attempts{}
+		s.attempts[key] = a
+	}
+
+	a.failures++
+	a.lastFailure = now
+
+	if maxAttempts > 0 && a.failures >= maxAttempts {
+		a.blockedUntil = now.Add(s.Config.LockoutDuration)
+		a.failures = 0
+		return true
+	}
+
+	a.blockedUntil = now.Add(s.backoff(a.failures))
+	return false
+}
+
+// backoff returns the exponential delay for the given number of failures.
+func (s *Service) backoff(failures int) time.Duration {
+	delay := s.Config.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= s.Config.MaxDelay {
+			return s.Config.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (s *Service) audit(key, ipAddress string, maxAttempts int) {
+	if s.AuditRepository == nil {
+		return
+	}
+
+	entry := &audit_model.Entry{
+		Action:    audit_model.ActionLoginLockout,
+		Target:    key,
+		IPAddress: ipAddress,
+		Details:   fmt.Sprintf("locked for %s after %d failed attempts", s.Config.LockoutDuration, maxAttempts),
+	}
+	if err := s.AuditRepository.Create(entry); err != nil {
+		fmt.Println("[LOCKOUT] Error writing audit entry:", err)
+	}
+}
+
+func userKey(username string) string {
+	return "username:" + username
+}
+
+func ipKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
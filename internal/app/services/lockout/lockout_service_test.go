@@ -0,0 +1,204 @@
+package lockout_service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/audit"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testConfig = Config{
+	MaxUserAttempts: 3,
+	MaxIPAttempts:   5,
+	BaseDelay:       time.Second,
+	MaxDelay:        4 * time.Second,
+	LockoutDuration: 15 * time.Minute,
+	Window:          time.Hour,
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("Should allow unknown keys", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+		service.now = func() time.Time { return now }
+
+		wait, err := service.Check("testuser", "127.0.0.1")
+
+		assert.NoError(t, err)
+		assert.Zero(t, wait)
+	})
+
+	t.Run("Should back off exponentially after failures", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+		service.now = func() time.Time { return now }
+
+		service.RegisterFailure("testuser", "127.0.0.1")
+		wait, err := service.Check("testuser", "127.0.0.1")
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+		assert.Equal(t, time.Second, wait)
+
+		now = now.Add(time.Second)
+		_, err = service.Check("testuser", "127.0.0.1")
+		assert.NoError(t, err)
+
+		service.RegisterFailure("testuser", "127.0.0.1")
+		wait, err = service.Check("testuser", "127.0.0.1")
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+		assert.Equal(t, 2*time.Second, wait)
+	})
+
+	t.Run("Should apply IP backoff to other usernames", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+		service.now = func() time.Time { return now }
+
+		service.RegisterFailure("testuser", "127.0.0.1")
+		_, err := service.Check("otheruser", "127.0.0.1")
+
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+	})
+
+	t.Run("Should forget failures after the window", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+		service.now = func() time.Time { return now }
+
+		service.RegisterFailure("testuser", "127.0.0.1")
+		now = now.Add(2 * time.Hour)
+		service.RegisterFailure("testuser", "127.0.0.1")
+
+		wait, err := service.Check("testuser", "127.0.0.1")
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+		assert.Equal(t, time.Second, wait)
+	})
+}
+
+func TestRegisterFailure(t *testing.T) {
+	t.Run("Should lock username and write audit entry", func(t *testing.T) {
+		auditRepository := mocks.NewMockAuditRepository()
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, auditRepository)
+		service.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			service.RegisterFailure("testuser", "127.0.0.1")
+			now = now.Add(5 * time.Second)
+		}
+
+		wait, err := service.Check("testuser", "10.0.0.1")
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+		assert.Equal(t, 15*time.Minute-5*time.Second, wait)
+
+		assert.Len(t, auditRepository.Entries, 1)
+		assert.Equal(t, audit_model.ActionLoginLockout, auditRepository.Entries[0].Action)
+		assert.Equal(t, "username:testuser", auditRepository.Entries[0].Target)
+		assert.Equal(t, "127.0.0.1", auditRepository.Entries[0].IPAddress)
+	})
+
+	t.Run("Should lock IP address across usernames", func(t *testing.T) {
+		auditRepository := mocks.NewMockAuditRepository()
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, auditRepository)
+		service.now = func() time.Time { return now }
+
+		for i := 0; i < 5; i++ {
+			service.RegisterFailure("user"+string(rune('a'+i)), "127.0.0.1")
+			now = now.Add(5 * time.Second)
+		}
+
+		_, err := service.Check("fresh", "127.0.0.1")
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+
+		_, err = service.Check("fresh", "10.0.0.1")
+		assert.NoError(t, err)
+
+		assert.Equal(t, "ip:127.0.0.1", auditRepository.Entries[len(auditRepository.Entries)-1].Target)
+	})
+
+	t.Run("Should cap the backoff", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+		service.now = func() time.Time { return now }
+
+		assert.Equal(t, time.Second, service.backoff(1))
+		assert.Equal(t, 4*time.Second, service.backoff(3))
+		assert.Equal(t, 4*time.Second, service.backoff(10))
+	})
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+	service.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		service.RegisterFailure(fmt.Sprintf("user%d", i), fmt.Sprintf("10.0.0.%d", i))
+	}
+	assert.Len(t, service.attempts, 200)
+
+	// Keys still blocked or in the window are kept
+	now = now.Add(30 * time.Minute)
+	service.RegisterFailure("testuser", "127.0.0.1")
+	assert.Len(t, service.attempts, 202)
+
+	now = now.Add(time.Hour)
+	_, err := service.Check("otheruser", "127.0.0.2")
+	assert.NoError(t, err)
+	assert.Len(t, service.attempts, 2)
+}
+
+// lockingAuditRepository checks a login while writing audit entries.
+type lockingAuditRepository struct {
+	service *Service
+	entries int
+}
+
+func (r *lockingAuditRepository) Create(_ *audit_model.Entry) error {
+	_, _ = r.service.Check("testuser", "127.0.0.1")
+	r.entries++
+	return nil
+}
+
+func TestAuditWithoutLock(t *testing.T) {
+	repository := &lockingAuditRepository{}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := NewLockoutService(testConfig, nil)
+	service.now = func() time.Time { return now }
+	service.AuditRepository = repository
+	repository.service = service
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3; i++ {
+			service.RegisterFailure("testuser", "127.0.0.1")
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		assert.Equal(t, 1, repository.entries)
+	case <-time.After(5 * time.Second):
+		t.Fatal("audit entry written while holding the lock")
+	}
+}
+
+func TestReset(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	service := NewLockoutService(testConfig, mocks.NewMockAuditRepository())
+	service.now = func() time.Time { return now }
+
+	service.RegisterFailure("testuser", "127.0.0.1")
+	service.Reset("testuser")
+
+	_, err := service.Check("testuser", "10.0.0.1")
+	assert.NoError(t, err)
+
+	// IP failures survive a successful login
+	_, err = service.Check("testuser", "127.0.0.1")
+	assert.ErrorIs(t, err, ErrTooManyAttempts)
+}
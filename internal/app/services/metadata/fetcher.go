@@ -0,0 +1,247 @@
+package metadata_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/url"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// DefaultTimeout bounds a page fetch, from connecting to reading the body.
+const DefaultTimeout = 5 * time.Second
+
+// DefaultMaxBytes is the number of bytes of a page read for its metadata; the head of a page comes first.
+const DefaultMaxBytes = 512 << 10
+
+// maxRedirects is the number of redirects followed to reach a page.
+const maxRedirects = 5
+
+// Longest stored values, in characters, matching the url_metadata table.
+const (
+	maxTitleLength       = 255
+	maxDescriptionLength = 1000
+	maxFaviconLength     = 2048
+)
+
+// HTTPClient sends the requests of the fetcher; *http.Client implements it.
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Fetcher reads the title, description and favicon of HTML pages.
+type Fetcher struct {
+	Client HTTPClient
+	// Timeout bounds each fetch and MaxBytes the part of the page read.
+	Timeout   time.Duration
+	MaxBytes  int64
+	UserAgent string
+}
+
+// NewFetcher creates a new instance of Fetcher sending requests with the given client,
+// with DefaultTimeout and DefaultMaxBytes until others are set.
+func NewFetcher(client HTTPClient) *Fetcher {
+	return &Fetcher{
+		Client:    client,
+		Timeout:   DefaultTimeout,
+		MaxBytes:  DefaultMaxBytes,
+		UserAgent: "url-shortener-metadata/1.0",
+	}
+}
+
+// NewHTTPClient returns a client for fetching pages that gives up after the timeout, follows at most
+// maxRedirects redirects and refuses to connect to private addresses, checked once names are resolved.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: refuseInternal}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   timeout,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+}
+
+// refuseInternal stops connections to addresses that are not publicly routable, so links cannot
+// make the server read internal pages.
+func refuseInternal(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || url_service.InternalIP(ip) {
+		return fmt.Errorf("refusing to connect to private address %s", host)
+	}
+	return nil
+}
+
+// Fetch reads the metadata of the HTML page at the URL. Errors wrap url_model.ErrMetadataFetch.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*url_model.Metadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", url_model.ErrMetadataFetch, err)
+	}
+	req.Header.Set("User-Agent", f.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", url_model.ErrMetadataFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: status %d", url_model.ErrMetadataFetch, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%w: content type %q is not HTML", url_model.ErrMetadataFetch, mediaType)
+	}
+
+	// Relative favicons are resolved against the page reached after redirects
+	base := req.URL
+	if resp.Request != nil && resp.Request.URL != nil {
+		base = resp.Request.URL
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.MaxBytes), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", url_model.ErrMetadataFetch, err)
+	}
+	metadata, err := parseHead(body, base)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", url_model.ErrMetadataFetch, err)
+	}
+	return metadata, nil
+}
+
+// parseHead reads the title, description and favicon from the head of a page. OpenGraph values are used
+// when the page has no title or description, and favicons default to /favicon.ico.
+func parseHead(r io.Reader, base *url.URL) (*url_model.Metadata, error) {
+	var title, ogTitle, description, ogDescription, icon string
+	inTitle := false
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			// Pages cut at MaxBytes end early; what was read is kept
+			if err := z.Err(); !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			break loop
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			attrs := make(map[string]string)
+			for hasAttr {
+				var key, value []byte
+				key, value, hasAttr = z.TagAttr()
+				attrs[string(key)] = string(value)
+			}
+
+			switch string(name) {
+			case "title":
+				inTitle = true
+			case "body":
+				break loop
+			case "meta":
+				key := strings.ToLower(attrs["name"])
+				if key == "" {
+					key = strings.ToLower(attrs["property"])
+				}
+				switch key {
+				case "description":
+					description = attrs["content"]
+				case "og:description":
+					ogDescription = attrs["content"]
+				case "og:title":
+					ogTitle = attrs["content"]
+				}
+			case "link":
+				if icon == "" && isIcon(attrs["rel"]) && attrs["href"] != "" {
+					icon = attrs["href"]
+				}
+			}
+		}
+	}
+
+	metadata := &url_model.Metadata{
+		Title:       truncate(firstNonEmpty(title, ogTitle), maxTitleLength),
+		Description: truncate(firstNonEmpty(description, ogDescription), maxDescriptionLength),
+	}
+	if icon == "" {
+		icon = "/favicon.ico"
+	}
+	if ref, err := url.Parse(strings.TrimSpace(icon)); err == nil {
+		if favicon := base.ResolveReference(ref).String(); len(favicon) <= maxFaviconLength {
+			metadata.FaviconURL = favicon
+		}
+	}
+	return metadata, nil
+}
+
+// isIcon reports whether a link rel attribute names a favicon, as "icon" or "shortcut icon".
+func isIcon(rel string) bool {
+	for _, value := range strings.Fields(strings.ToLower(rel)) {
+		if value == "icon" {
+			return true
+		}
+	}
+	return false
+}
+
+// firstNonEmpty returns the first value that is not blank once whitespace is collapsed.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.Join(strings.Fields(value), " "); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// truncate cuts the value to at most max characters.
+func truncate(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max])
+}
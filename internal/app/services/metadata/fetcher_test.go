@@ -0,0 +1,146 @@
+package metadata_service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer serves the pages of the map, with their content type, and redirects /moved to /page.
+func newTestServer(t *testing.T, pages map[string]string, contentType string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(pages["/page"]))
+		default:
+			page, ok := pages[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", contentType)
+			_, _ = w.Write([]byte(page))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	pages := map[string]string{
+		"/page": `<!DOCTYPE html><html><head>
+			<title>  Example &amp; Co
+			</title>
+			<meta name="Description" content="An example page">
+			<link rel="shortcut icon" href="/static/icon.png">
+			</head><body><title>Not this one</title></body></html>`,
+		"/og":   `<html><head><meta property="og:title" content="Shared title"><meta property="og:description" content="Shared description"></head></html>`,
+		"/bare": `<p>No head at all</p>`,
+	}
+	server := newTestServer(t, pages, "text/html; charset=utf-8")
+	fetcher := NewFetcher(server.Client())
+
+	t.Run("Should read title, description and favicon", func(t *testing.T) {
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Example & Co", metadata.Title)
+		assert.Equal(t, "An example page", metadata.Description)
+		assert.Equal(t, server.URL+"/static/icon.png", metadata.FaviconURL)
+	})
+
+	t.Run("Should follow redirects", func(t *testing.T) {
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/moved")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Example & Co", metadata.Title)
+	})
+
+	t.Run("Should fall back to OpenGraph and the default favicon", func(t *testing.T) {
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/og")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Shared title", metadata.Title)
+		assert.Equal(t, "Shared description", metadata.Description)
+		assert.Equal(t, server.URL+"/favicon.ico", metadata.FaviconURL)
+
+		metadata, err = fetcher.Fetch(context.Background(), server.URL+"/bare")
+		assert.NoError(t, err)
+		assert.Empty(t, metadata.Title)
+	})
+
+	t.Run("Should reject missing pages", func(t *testing.T) {
+		_, err := fetcher.Fetch(context.Background(), server.URL+"/missing")
+		assert.ErrorIs(t, err, url_model.ErrMetadataFetch)
+		assert.Contains(t, err.Error(), "404")
+	})
+
+	t.Run("Should give up after the timeout", func(t *testing.T) {
+		slow := NewFetcher(server.Client())
+		slow.Timeout = 50 * time.Millisecond
+
+		_, err := slow.Fetch(context.Background(), server.URL+"/slow")
+		assert.ErrorIs(t, err, url_model.ErrMetadataFetch)
+	})
+}
+
+func TestFetchLimits(t *testing.T) {
+	t.Run("Should only read MaxBytes of the page", func(t *testing.T) {
+		page := `<html><head><title>Kept</title>` + strings.Repeat(" ", 1024) + `<meta name="description" content="Cut"></head></html>`
+		server := newTestServer(t, map[string]string{"/page": page}, "text/html")
+		fetcher := NewFetcher(server.Client())
+		fetcher.MaxBytes = 512
+
+		metadata, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Kept", metadata.Title)
+		assert.Empty(t, metadata.Description)
+	})
+
+	t.Run("Should truncate long titles", func(t *testing.T) {
+		page := `<title>` + strings.Repeat("é", 300) + `</title>`
+		server := newTestServer(t, map[string]string{"/page": page}, "text/html")
+
+		metadata, err := NewFetcher(server.Client()).Fetch(context.Background(), server.URL+"/page")
+
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("é", maxTitleLength), metadata.Title)
+	})
+
+	t.Run("Should decode the declared charset", func(t *testing.T) {
+		server := newTestServer(t, map[string]string{"/page": "<title>Caf\xe9</title>"}, "text/html; charset=iso-8859-1")
+
+		metadata, err := NewFetcher(server.Client()).Fetch(context.Background(), server.URL+"/page")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Café", metadata.Title)
+	})
+
+	t.Run("Should reject other content types", func(t *testing.T) {
+		server := newTestServer(t, map[string]string{"/page": "{}"}, "application/json")
+
+		_, err := NewFetcher(server.Client()).Fetch(context.Background(), server.URL+"/page")
+
+		assert.ErrorIs(t, err, url_model.ErrMetadataFetch)
+	})
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := newTestServer(t, map[string]string{"/page": "<title>Internal</title>"}, "text/html")
+
+	_, err := NewFetcher(NewHTTPClient(time.Second)).Fetch(context.Background(), server.URL+"/page")
+
+	assert.ErrorIs(t, err, url_model.ErrMetadataFetch)
+	assert.Contains(t, err.Error(), "private address")
+}
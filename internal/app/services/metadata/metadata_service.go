@@ -0,0 +1,93 @@
+package metadata_service
+
+import (
+	"context"
+	"fmt"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/repositories/url"
+)
+
+// DefaultQueueSize is the number of links waiting for their metadata before new ones are skipped.
+const DefaultQueueSize = 100
+
+// Service fetches the page metadata of links in the background. A nil Service is disabled:
+// nothing is queued and refreshes return url_model.ErrMetadataDisabled.
+type Service struct {
+	Repository url_repository.Repository
+	Fetcher    *Fetcher
+	queue      chan fetch
+	now        func() time.Time
+}
+
+// fetch is a queued metadata fetch.
+type fetch struct {
+	shortCode   string
+	originalURL string
+}
+
+// NewMetadataService creates a new instance of MetadataService storing the metadata read by the fetcher,
+// with room for queueSize links waiting to be fetched.
+func NewMetadataService(repository url_repository.Repository, fetcher *Fetcher, queueSize int) *Service {
+	return &Service{
+		Repository: repository,
+		Fetcher:    fetcher,
+		queue:      make(chan fetch, queueSize),
+		now:        time.Now,
+	}
+}
+
+// Enqueue queues a fetch of the metadata of a link for Run. It reports false when the service is
+// disabled or the queue is full, in which case the metadata can still be refreshed on demand.
+func (s *Service) Enqueue(shortCode, originalURL string) bool {
+	if s == nil {
+		return false
+	}
+
+	select {
+	case s.queue <- fetch{shortCode: shortCode, originalURL: originalURL}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run fetches the queued links one at a time until stop is closed.
+func (s *Service) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case f := <-s.queue:
+			if _, err := s.fetch(context.Background(), f.shortCode, f.originalURL); err != nil {
+				fmt.Printf("[METADATA] Error fetching metadata of %s: %v\n", f.shortCode, err)
+			}
+		}
+	}
+}
+
+// Refresh fetches and stores the metadata of the link with the given short code now.
+func (s *Service) Refresh(ctx context.Context, shortCode string) (*url_model.Metadata, error) {
+	if s == nil {
+		return nil, url_model.ErrMetadataDisabled
+	}
+
+	u, err := s.Repository.GetURL(shortCode)
+	if err != nil {
+		return nil, err
+	}
+	return s.fetch(ctx, shortCode, u.OriginalURL)
+}
+
+func (s *Service) fetch(ctx context.Context, shortCode, originalURL string) (*url_model.Metadata, error) {
+	metadata, err := s.Fetcher.Fetch(ctx, originalURL)
+	if err != nil {
+		return nil, err
+	}
+	metadata.FetchedAt = s.now().UTC().Truncate(time.Second)
+
+	if err := s.Repository.SaveMetadata(shortCode, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
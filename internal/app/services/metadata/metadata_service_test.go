@@ -0,0 +1,71 @@
+package metadata_service
+
+import (
+	"context"
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataService(t *testing.T) {
+	server := newTestServer(t, map[string]string{"/page": "<title>Example</title>"}, "text/html")
+	repository := mocks.NewMockUrlRepository()
+	_, _ = repository.CreateURL(server.URL+"/page", "abc123", nil)
+	_, _ = repository.CreateURL(server.URL+"/missing", "broken", nil)
+
+	service := NewMetadataService(repository, NewFetcher(server.Client()), 1)
+	service.now = func() time.Time { return time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC) }
+
+	t.Run("Should refresh metadata and title now", func(t *testing.T) {
+		metadata, err := service.Refresh(context.Background(), "abc123")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Example", metadata.Title)
+		assert.Equal(t, service.now(), metadata.FetchedAt)
+		assert.Equal(t, metadata, repository.Metadata["abc123"])
+
+		u, _ := repository.GetURL("abc123")
+		assert.Equal(t, "Example", u.Title)
+	})
+
+	t.Run("Should not store failed fetches", func(t *testing.T) {
+		_, err := service.Refresh(context.Background(), "broken")
+		assert.ErrorIs(t, err, url_model.ErrMetadataFetch)
+		assert.NotContains(t, repository.Metadata, "broken")
+
+		_, err = service.Refresh(context.Background(), "missing")
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should fetch queued links in the background", func(t *testing.T) {
+		delete(repository.Metadata, "abc123")
+		assert.True(t, service.Enqueue("abc123", server.URL+"/page"))
+		// The queue holds a single link
+		assert.False(t, service.Enqueue("abc123", server.URL+"/page"))
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			service.Run(stop)
+			close(done)
+		}()
+		// Run finishes the fetch it took from the queue before stopping
+		assert.Eventually(t, func() bool { return len(service.queue) == 0 }, time.Second, 10*time.Millisecond)
+		close(stop)
+		<-done
+
+		_, err := repository.GetMetadata("abc123")
+		assert.NoError(t, err)
+	})
+}
+
+func TestDisabledMetadataService(t *testing.T) {
+	var service *Service
+
+	assert.False(t, service.Enqueue("abc123", "https://www.example.com"))
+	_, err := service.Refresh(context.Background(), "abc123")
+	assert.ErrorIs(t, err, url_model.ErrMetadataDisabled)
+}
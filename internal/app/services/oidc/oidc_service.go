@@ -0,0 +1,359 @@
+package oidc_service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"url-shortener/internal/app/models/oidc"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/utils"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultStateTTL is how long a started login may take before its state expires.
+const DefaultStateTTL = 10 * time.Minute
+
+// clockSkew is the tolerance applied to the time based ID token claims.
+const clockSkew = 60
+
+// pendingLogin is the state kept between redirecting to the provider and its callback.
+type pendingLogin struct {
+	provider  string
+	verifier  string
+	nonce     string
+	expiresAt time.Time
+}
+
+// Service handles single sign-on through OpenID Connect providers.
+type Service struct {
+	Providers  map[string]Provider
+	Repository auth_repository.Repository
+	HTTPClient *http.Client
+	// StateTTL is how long a started login may take before its state expires.
+	StateTTL time.Duration
+	// BindingKey signs the states handed to browsers so that a callback is only accepted from the browser that started it.
+	BindingKey []byte
+
+	mu       sync.Mutex
+	states   map[string]*pendingLogin
+	metadata map[string]*metadata
+	jwks     map[string]map[string]*rsa.PublicKey
+	now      func() time.Time
+}
+
+// NewOIDCService creates a new instance of OIDCService for the given providers.
+func NewOIDCService(providers []Provider, repository auth_repository.Repository) *Service {
+	byName := make(map[string]Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name] = provider
+	}
+
+	return &Service{
+		Providers:  byName,
+		Repository: repository,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+		StateTTL:   DefaultStateTTL,
+		BindingKey: randomKey(),
+		states:     make(map[string]*pendingLogin),
+		metadata:   make(map[string]*metadata),
+		jwks:       make(map[string]map[string]*rsa.PublicKey),
+		now:        time.Now,
+	}
+}
+
+// ProviderNames returns the names of the configured providers in alphabetical order.
+func (s *Service) ProviderNames() []string {
+	names := make([]string, 0, len(s.Providers))
+	for name := range s.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AuthCodeURL starts a login with the given provider and returns the URL to redirect the browser to,
+// along with the binding the browser must present on the callback.
+// The request carries a one-time state, a nonce and a PKCE S256 code challenge.
+func (s *Service) AuthCodeURL(providerName string) (string, string, error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return "", "", oidc_model.ErrUnknownProvider
+	}
+
+	doc, err := s.discover(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := randomString()
+	if err != nil {
+		return "", "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	s.saveState(state, &pendingLogin{
+		provider:  provider.Name,
+		verifier:  verifier,
+		nonce:     nonce,
+		expiresAt: s.now().Add(s.StateTTL),
+	})
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {provider.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, provider.Scopes...), " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + query.Encode(), s.binding(state), nil
+}
+
+// Authenticate completes a login from the provider callback and returns the verified identity.
+// The binding must be the one returned with the authorization URL, proving the callback comes from the same browser.
+func (s *Service) Authenticate(providerName, code, state, binding string) (*oidc_model.Identity, error) {
+	provider, ok := s.Providers[providerName]
+	if !ok {
+		return nil, oidc_model.ErrUnknownProvider
+	}
+
+	// A stolen code and state cannot be redeemed, nor a victim logged into another account, without the binding
+	if !hmac.Equal([]byte(binding), []byte(s.binding(state))) {
+		return nil, oidc_model.ErrInvalidState
+	}
+
+	// The state is consumed even when the login fails so that it cannot be replayed
+	login := s.takeState(state)
+	if login == nil || login.provider != provider.Name {
+		return nil, oidc_model.ErrInvalidState
+	}
+
+	doc, err := s.discover(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken, err := s.exchange(provider, doc, code, login.verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := s.verifyIDToken(provider, doc, rawToken, login.nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oidc_model.Identity{
+		Provider:          provider.Name,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// Login completes a login from the provider callback and returns the linked auth.
+// Unknown identities are linked to the account with the same verified email address, or get a new account.
+func (s *Service) Login(providerName, code, state, binding string) (*user_model.User, error) {
+	identity, err := s.Authenticate(providerName, code, state, binding)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(identity)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled {
+		return nil, user_model.ErrAccountDisabled
+	}
+
+	return user, nil
+}
+
+// resolveUser returns the auth linked to the identity, linking or creating one on first login.
+func (s *Service) resolveUser(identity *oidc_model.Identity) (*user_model.User, error) {
+	user, err := s.Repository.GetByIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, user_model.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// Only addresses verified on both sides may link accounts, otherwise anyone could claim them
+	email, ok := utils.NormalizeEmail(identity.Email)
+	if !ok || !identity.EmailVerified {
+		email = ""
+	}
+	if email != "" {
+		existing, err := s.Repository.GetByEmail(email)
+		if err == nil && existing.EmailVerified {
+			if err := s.Repository.CreateIdentity(existing.ID, identity.Provider, identity.Subject); err != nil {
+				return nil, err
+			}
+			return existing, nil
+		}
+		if err == nil {
+			email = ""
+		} else if !errors.Is(err, user_model.ErrUserNotFound) {
+			return nil, err
+		}
+	}
+
+	user, err = s.createUser(identity, email)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repository.CreateIdentity(user.ID, identity.Provider, identity.Subject); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createUser creates an account for an identity with an unusable random password.
+func (s *Service) createUser(identity *oidc_model.Identity, email string) (*user_model.User, error) {
+	username, err := s.availableUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomString()
+	if err != nil {
+		return nil, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.Repository.Create(&user_model.User{Username: username, Password: string(hashedPassword), Email: email})
+	if err != nil {
+		return nil, err
+	}
+
+	if email != "" {
+		if err := s.Repository.SetEmailVerified(user.ID, email); err != nil {
+			return nil, err
+		}
+		user.EmailVerified = true
+	}
+
+	return user, nil
+}
+
+// availableUsername derives an unused username from the identity claims.
+func (s *Service) availableUsername(identity *oidc_model.Identity) (string, error) {
+	base := identity.PreferredUsername
+	if base == "" {
+		base = identity.Email
+	}
+	if base == "" {
+		base = identity.Provider + "-" + identity.Subject
+	}
+	// Claims are UTF-8, so they are cut between runes
+	if runes := []rune(base); len(runes) > 40 {
+		base = string(runes[:40])
+	}
+
+	username := base
+	for i := 0; i < 5; i++ {
+		_, err := s.Repository.GetByUsername(username)
+		if errors.Is(err, user_model.ErrUserNotFound) {
+			return username, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		username = base + "-" + hex.EncodeToString(suffix)
+	}
+
+	return "", user_model.ErrUserAlreadyExists
+}
+
+func (s *Service) saveState(state string, login *pendingLogin) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop abandoned logins so the map does not grow without bound
+	now := s.now()
+	for key, pending := range s.states {
+		if now.After(pending.expiresAt) {
+			delete(s.states, key)
+		}
+	}
+	s.states[state] = login
+}
+
+func (s *Service) takeState(state string) *pendingLogin {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	login, ok := s.states[state]
+	if !ok {
+		return nil
+	}
+	delete(s.states, state)
+	if s.now().After(login.expiresAt) {
+		return nil
+	}
+	return login
+}
+
+// randomString returns 32 random bytes encoded as base64url, suitable for states, nonces and PKCE verifiers.
+func randomString() (string, error) {
+	value := make([]byte, 32)
+	if _, err := rand.Read(value); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
+// binding returns the signature of the state that the browser starting the login keeps.
+func (s *Service) binding(state string) string {
+	mac := hmac.New(sha256.New, s.BindingKey)
+	mac.Write([]byte(state))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomKey generates a key for signing state bindings.
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("failed to generate signing key: " + err.Error())
+	}
+	return key
+}
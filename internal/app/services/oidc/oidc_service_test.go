@@ -0,0 +1,263 @@
+package oidc_service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/oidc"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// login runs the browser part of the flow against the fake provider.
+func login(t *testing.T, service *Service, provider *mocks.MockOIDCProvider) (string, string, string) {
+	authURL, binding, err := service.AuthCodeURL("example")
+	assert.NoError(t, err)
+
+	code, state, err := provider.Authorize(authURL)
+	assert.NoError(t, err)
+	return code, state, binding
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	provider := mocks.NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	service := NewOIDCService([]Provider{{
+		Name:         "example",
+		Issuer:       provider.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/example/callback/",
+		Scopes:       []string{"email", "profile"},
+	}}, mocks.NewMockUserRepository())
+
+	t.Run("Should build authorization request with PKCE", func(t *testing.T) {
+		authURL, binding, err := service.AuthCodeURL("example")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, binding)
+
+		parsed, err := url.Parse(authURL)
+		assert.NoError(t, err)
+		query := parsed.Query()
+		assert.Equal(t, "/authorize", parsed.Path)
+		assert.Equal(t, "code", query.Get("response_type"))
+		assert.Equal(t, "client", query.Get("client_id"))
+		assert.Equal(t, "openid email profile", query.Get("scope"))
+		assert.Equal(t, "S256", query.Get("code_challenge_method"))
+		assert.NotEmpty(t, query.Get("code_challenge"))
+		assert.NotEmpty(t, query.Get("nonce"))
+		assert.NotEmpty(t, query.Get("state"))
+	})
+
+	t.Run("Should return error for unknown provider", func(t *testing.T) {
+		_, _, err := service.AuthCodeURL("missing")
+		assert.ErrorIs(t, err, oidc_model.ErrUnknownProvider)
+	})
+
+	t.Run("Should return error when discovery fails", func(t *testing.T) {
+		broken := NewOIDCService([]Provider{{Name: "broken", Issuer: "http://127.0.0.1:1"}}, mocks.NewMockUserRepository())
+
+		_, _, err := broken.AuthCodeURL("broken")
+		assert.ErrorIs(t, err, oidc_model.ErrProviderUnavailable)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	provider := mocks.NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	service := NewOIDCService([]Provider{{
+		Name:         "example",
+		Issuer:       provider.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/example/callback/",
+		Scopes:       []string{"email", "profile"},
+	}}, mocks.NewMockUserRepository())
+
+	t.Run("Should create user on first login", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		code, state, binding := login(t, service, provider)
+
+		user, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "ssouser", user.Username)
+		assert.Equal(t, "sso@example.com", user.Email)
+		assert.True(t, user.EmailVerified)
+		assert.NotEmpty(t, user.Password)
+		assert.Equal(t, user.ID, repository.Identities["example:mock-subject"])
+	})
+
+	t.Run("Should shorten long usernames between runes", func(t *testing.T) {
+		service.Repository = mocks.NewMockUserRepository()
+		provider.Claims["preferred_username"] = strings.Repeat("a", 39) + "éé"
+		defer func() { provider.Claims["preferred_username"] = "ssouser" }()
+		code, state, binding := login(t, service, provider)
+
+		user, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", 39)+"é", user.Username)
+	})
+
+	t.Run("Should return linked user on later logins", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		code, state, binding := login(t, service, provider)
+		first, _ := service.Login("example", code, state, binding)
+
+		code, state, binding = login(t, service, provider)
+		second, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Len(t, repository.Users, 1)
+	})
+
+	t.Run("Should link account with same verified email", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		existing, _ := repository.Create(&user_model.User{Username: "local", Email: "sso@example.com", EmailVerified: true})
+		code, state, binding := login(t, service, provider)
+
+		user, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.Equal(t, existing.ID, user.ID)
+		assert.Equal(t, existing.ID, repository.Identities["example:mock-subject"])
+	})
+
+	t.Run("Should not link account with unverified email", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		existing, _ := repository.Create(&user_model.User{Username: "ssouser", Email: "sso@example.com"})
+		code, state, binding := login(t, service, provider)
+
+		user, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, existing.ID, user.ID)
+		assert.Contains(t, user.Username, "ssouser-")
+		assert.Empty(t, user.Email)
+	})
+
+	t.Run("Should not trust unverified provider email", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		provider.Claims["email_verified"] = false
+		defer func() { provider.Claims["email_verified"] = true }()
+		existing, _ := repository.Create(&user_model.User{Username: "local", Email: "sso@example.com", EmailVerified: true})
+		code, state, binding := login(t, service, provider)
+
+		user, err := service.Login("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.NotEqual(t, existing.ID, user.ID)
+		assert.Empty(t, user.Email)
+	})
+
+	t.Run("Should reject disabled user", func(t *testing.T) {
+		repository := mocks.NewMockUserRepository()
+		service.Repository = repository
+		existing, _ := repository.Create(&user_model.User{Username: "local", Email: "sso@example.com", EmailVerified: true, Disabled: true})
+		code, state, binding := login(t, service, provider)
+
+		_, err := service.Login("example", code, state, binding)
+
+		assert.ErrorIs(t, err, user_model.ErrAccountDisabled)
+		assert.Equal(t, existing.ID, repository.Identities["example:mock-subject"])
+	})
+
+	t.Run("Should return error when linking fails", func(t *testing.T) {
+		service.Providers["error"] = Provider{Name: "error", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}
+		authURL, binding, _ := service.AuthCodeURL("error")
+		code, state, _ := provider.Authorize(authURL)
+
+		_, err := service.Login("error", code, state, binding)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestAuthenticate(t *testing.T) {
+	provider := mocks.NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	service := NewOIDCService([]Provider{{
+		Name:         "example",
+		Issuer:       provider.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oidc/example/callback/",
+		Scopes:       []string{"email", "profile"},
+	}}, mocks.NewMockUserRepository())
+
+	t.Run("Should reject unknown state", func(t *testing.T) {
+		code, _, binding := login(t, service, provider)
+
+		_, err := service.Authenticate("example", code, "forged", binding)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should reject state without its binding", func(t *testing.T) {
+		code, state, _ := login(t, service, provider)
+		_, _, other := login(t, service, provider)
+
+		_, err := service.Authenticate("example", code, state, "")
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+
+		_, err = service.Authenticate("example", code, state, other)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should reject replayed state", func(t *testing.T) {
+		code, state, binding := login(t, service, provider)
+
+		_, err := service.Authenticate("example", code, state, binding)
+		assert.NoError(t, err)
+
+		_, err = service.Authenticate("example", code, state, binding)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should reject expired state", func(t *testing.T) {
+		code, state, binding := login(t, service, provider)
+		service.now = func() time.Time { return time.Now().Add(DefaultStateTTL + time.Minute) }
+		defer func() { service.now = time.Now }()
+
+		_, err := service.Authenticate("example", code, state, binding)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should reject state of another provider", func(t *testing.T) {
+		service.Providers["other"] = Provider{Name: "other", Issuer: provider.Issuer(), ClientID: "client"}
+		code, state, binding := login(t, service, provider)
+
+		_, err := service.Authenticate("other", code, state, binding)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should reject invalid code", func(t *testing.T) {
+		_, state, binding := login(t, service, provider)
+
+		_, err := service.Authenticate("example", "invalid", state, binding)
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidState)
+	})
+
+	t.Run("Should return error for unknown provider", func(t *testing.T) {
+		_, err := service.Authenticate("missing", "code", "state", "binding")
+		assert.ErrorIs(t, err, oidc_model.ErrUnknownProvider)
+	})
+}
+
+func TestProviderNames(t *testing.T) {
+	service := NewOIDCService([]Provider{{Name: "okta"}, {Name: "google"}}, mocks.NewMockUserRepository())
+
+	assert.Equal(t, []string{"google", "okta"}, service.ProviderNames())
+}
@@ -0,0 +1,292 @@
+package oidc_service
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"url-shortener/internal/app/models/oidc"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Provider holds the client registration for a single OpenID Connect identity provider.
+type Provider struct {
+	// Name identifies the provider in URLs and in the user_identities table.
+	Name string
+	// Issuer is the issuer URL; the discovery document is fetched from below it.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback URL registered with the provider.
+	RedirectURL string
+	// Scopes requested in addition to "openid".
+	Scopes []string
+}
+
+// metadata is the subset of the discovery document used by the login flow.
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// jsonWebKey is a single key of a JWKS document.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// audience accepts the aud claim both as a single string and as an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// idTokenClaims represents the claims of an ID token used by the login flow.
+type idTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          audience `json:"aud"`
+	ExpiresAt         int64    `json:"exp"`
+	IssuedAt          int64    `json:"iat"`
+	Nonce             string   `json:"nonce"`
+	AuthorizedParty   string   `json:"azp"`
+	Email             string   `json:"email"`
+	EmailVerified     bool     `json:"email_verified"`
+	PreferredUsername string   `json:"preferred_username"`
+}
+
+// Valid is a no-op; the claims are checked by verifyIDToken against the service clock.
+func (c *idTokenClaims) Valid() error {
+	return nil
+}
+
+// discover fetches and caches the discovery document of the provider.
+func (s *Service) discover(provider Provider) (*metadata, error) {
+	s.mu.Lock()
+	cached, ok := s.metadata[provider.Name]
+	s.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	var doc metadata
+	issuer := strings.TrimSuffix(provider.Issuer, "/")
+	if err := s.getJSON(issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, err
+	}
+
+	// The document must belong to the configured issuer, otherwise its tokens cannot be trusted
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("%w: discovery issuer %q does not match %q", oidc_model.ErrProviderUnavailable, doc.Issuer, provider.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: incomplete discovery document", oidc_model.ErrProviderUnavailable)
+	}
+
+	s.mu.Lock()
+	s.metadata[provider.Name] = &doc
+	s.mu.Unlock()
+	return &doc, nil
+}
+
+// keys returns the signing keys of the provider, refetching the JWKS when refresh is set.
+func (s *Service) keys(provider Provider, doc *metadata, refresh bool) (map[string]*rsa.PublicKey, error) {
+	s.mu.Lock()
+	cached, ok := s.jwks[provider.Name]
+	s.mu.Unlock()
+	if ok && !refresh {
+		return cached, nil
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := s.getJSON(doc.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, key := range set.Keys {
+		if key.KeyType != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		publicKey, err := parseRSAKey(key)
+		if err != nil {
+			continue
+		}
+		keys[key.KeyID] = publicKey
+	}
+
+	s.mu.Lock()
+	s.jwks[provider.Name] = keys
+	s.mu.Unlock()
+	return keys, nil
+}
+
+// exchange redeems the authorization code at the token endpoint and returns the raw ID token.
+func (s *Service) exchange(provider Provider, doc *metadata, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {provider.RedirectURL},
+		"client_id":     {provider.ClientID},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if provider.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+	}
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", oidc_model.ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: invalid token response", oidc_model.ErrProviderUnavailable)
+	}
+
+	// A rejected code is the client's fault, anything else is the provider's
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return "", fmt.Errorf("%w: %s %s", oidc_model.ErrInvalidState, body.Error, body.ErrorDescription)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint returned %d", oidc_model.ErrProviderUnavailable, resp.StatusCode)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", oidc_model.ErrInvalidIDToken)
+	}
+
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks the signature and claims of an ID token issued for the given login.
+func (s *Service) verifyIDToken(provider Provider, doc *metadata, rawToken, nonce string) (*idTokenClaims, error) {
+	keys, err := s.keys(provider, doc, false)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &idTokenClaims{}
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+
+		// The provider may have rotated its keys since they were cached
+		keys, err = s.keys(provider, doc, true)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if _, err := jwt.ParseWithClaims(rawToken, claims, keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", oidc_model.ErrInvalidIDToken, err)
+	}
+
+	now := s.now().Unix()
+	switch {
+	case strings.TrimSuffix(claims.Issuer, "/") != strings.TrimSuffix(doc.Issuer, "/"):
+		return nil, fmt.Errorf("%w: unexpected issuer", oidc_model.ErrInvalidIDToken)
+	case !claims.Audience.contains(provider.ClientID):
+		return nil, fmt.Errorf("%w: unexpected audience", oidc_model.ErrInvalidIDToken)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != provider.ClientID:
+		return nil, fmt.Errorf("%w: unexpected authorized party", oidc_model.ErrInvalidIDToken)
+	case claims.ExpiresAt == 0 || now > claims.ExpiresAt+clockSkew:
+		return nil, fmt.Errorf("%w: token is expired", oidc_model.ErrInvalidIDToken)
+	case claims.IssuedAt > now+clockSkew:
+		return nil, fmt.Errorf("%w: token is issued in the future", oidc_model.ErrInvalidIDToken)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce mismatch", oidc_model.ErrInvalidIDToken)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: missing subject", oidc_model.ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// getJSON fetches a JSON document from the provider.
+func (s *Service) getJSON(target string, value interface{}) error {
+	resp, err := s.HTTPClient.Get(target)
+	if err != nil {
+		return fmt.Errorf("%w: %v", oidc_model.ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", oidc_model.ErrProviderUnavailable, target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(value); err != nil {
+		return fmt.Errorf("%w: %v", oidc_model.ErrProviderUnavailable, err)
+	}
+	return nil
+}
+
+// parseRSAKey builds an RSA public key from the base64url encoded modulus and exponent of a JWK.
+func parseRSAKey(key jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, err
+	}
+	if len(n) == 0 || len(e) == 0 || len(e) > 4 {
+		return nil, fmt.Errorf("invalid RSA key %q", key.KeyID)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
@@ -0,0 +1,162 @@
+package oidc_service
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/oidc"
+	"url-shortener/internal/mocks"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyIDToken(t *testing.T) {
+	t.Run("Should accept valid token", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		code, state, binding := login(t, service, provider)
+
+		identity, err := service.Authenticate("example", code, state, binding)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "example", identity.Provider)
+		assert.Equal(t, "mock-subject", identity.Subject)
+		assert.Equal(t, "sso@example.com", identity.Email)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("Should accept audience array with authorized party", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		provider.Claims["aud"] = []string{"client", "other"}
+		provider.Claims["azp"] = "client"
+		code, state, binding := login(t, service, provider)
+
+		_, err := service.Authenticate("example", code, state, binding)
+		assert.NoError(t, err)
+	})
+
+	forgedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		claims map[string]interface{}
+		key    *rsa.PrivateKey
+	}{
+		{name: "Should reject token signed with unknown key", key: forgedKey},
+		{name: "Should reject wrong issuer", claims: map[string]interface{}{"iss": "https://evil.example.com"}},
+		{name: "Should reject wrong audience", claims: map[string]interface{}{"aud": "other"}},
+		{name: "Should reject audience array without authorized party", claims: map[string]interface{}{"aud": []string{"client", "other"}}},
+		{name: "Should reject expired token", claims: map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{name: "Should reject token issued in the future", claims: map[string]interface{}{"iat": time.Now().Add(time.Hour).Unix()}},
+		{name: "Should reject nonce mismatch", claims: map[string]interface{}{"nonce": "replayed"}},
+		{name: "Should reject missing subject", claims: map[string]interface{}{"sub": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := mocks.NewMockOIDCProvider()
+			defer provider.Server.Close()
+			service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+			for key, value := range tt.claims {
+				provider.Claims[key] = value
+			}
+			if tt.key != nil {
+				provider.SigningKey = tt.key
+			}
+			code, state, binding := login(t, service, provider)
+
+			_, err := service.Authenticate("example", code, state, binding)
+			assert.ErrorIs(t, err, oidc_model.ErrInvalidIDToken)
+		})
+	}
+
+	t.Run("Should refetch keys after rotation", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		code, state, binding := login(t, service, provider)
+		_, err := service.Authenticate("example", code, state, binding)
+		assert.NoError(t, err)
+
+		// Rotate the published key after the old one has been cached
+		provider.Key = forgedKey
+		provider.SigningKey = forgedKey
+		provider.KeyID = "rotated"
+		code, state, binding = login(t, service, provider)
+
+		_, err = service.Authenticate("example", code, state, binding)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject symmetric signatures", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		doc, err := service.discover(service.Providers["example"])
+		assert.NoError(t, err)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iss": provider.Issuer(), "aud": "client", "sub": "mock-subject", "exp": time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = provider.KeyID
+		raw, _ := token.SignedString([]byte("secret"))
+
+		_, err = service.verifyIDToken(service.Providers["example"], doc, raw, "")
+		assert.ErrorIs(t, err, oidc_model.ErrInvalidIDToken)
+	})
+}
+
+func TestDiscover(t *testing.T) {
+	t.Run("Should reject discovery document of another issuer", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		service.Providers["example"] = Provider{Name: "example", Issuer: provider.Issuer() + "/tenant", ClientID: "client"}
+
+		_, _, err := service.AuthCodeURL("example")
+		assert.ErrorIs(t, err, oidc_model.ErrProviderUnavailable)
+	})
+
+	t.Run("Should cache discovery document", func(t *testing.T) {
+		provider := mocks.NewMockOIDCProvider()
+		defer provider.Server.Close()
+		service := NewOIDCService([]Provider{{Name: "example", Issuer: provider.Issuer(), ClientID: "client", RedirectURL: "http://localhost/"}}, mocks.NewMockUserRepository())
+		first, err := service.discover(service.Providers["example"])
+		assert.NoError(t, err)
+
+		provider.Server.Close()
+		second, err := service.discover(service.Providers["example"])
+		assert.NoError(t, err)
+		assert.Same(t, first, second)
+	})
+}
+
+func TestAudience(t *testing.T) {
+	var single, multiple audience
+	assert.NoError(t, json.Unmarshal([]byte(`"client"`), &single))
+	assert.NoError(t, json.Unmarshal([]byte(`["client","other"]`), &multiple))
+	assert.Error(t, json.Unmarshal([]byte(`1`), &single))
+
+	assert.True(t, single.contains("client"))
+	assert.True(t, multiple.contains("other"))
+	assert.False(t, multiple.contains("missing"))
+}
+
+func TestParseRSAKey(t *testing.T) {
+	_, err := parseRSAKey(jsonWebKey{KeyID: "invalid", N: "!", E: "AQAB"})
+	assert.Error(t, err)
+
+	_, err = parseRSAKey(jsonWebKey{KeyID: "empty", N: "", E: "AQAB"})
+	assert.Error(t, err)
+
+	key, err := parseRSAKey(jsonWebKey{KeyID: "valid", N: "AQAB", E: "AQAB"})
+	assert.NoError(t, err)
+	assert.Equal(t, 65537, key.E)
+}
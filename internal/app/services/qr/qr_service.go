@@ -0,0 +1,336 @@
+package qr_service
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+	"sync"
+	"url-shortener/internal/app/models/qr"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Defaults and bounds of the QR code parameters.
+const (
+	DefaultSize      = 256
+	MinSize          = 64
+	MaxSize          = 2048
+	DefaultMargin    = 4
+	MaxMargin        = 16
+	DefaultCacheSize = 256
+	// logoRatio is the share of the code width covered by the logo, small enough for level H to recover.
+	logoRatio = 0.2
+)
+
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// options are the validated parameters of a QR code.
+type options struct {
+	format     string
+	size       int
+	margin     int
+	level      string
+	foreground color.RGBA
+	background color.RGBA
+	logo       bool
+}
+
+// Service generates QR codes and caches the generated images.
+type Service struct {
+	// Logo is drawn at the center of codes requesting it, nil when none is configured.
+	Logo image.Image
+
+	logoPNG  []byte
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+// cacheEntry is an image kept in the cache.
+type cacheEntry struct {
+	key  string
+	data []byte
+}
+
+// NewQRService creates a new instance of QRService caching up to cacheSize images.
+// A nil logo disables logos; a cacheSize of zero or less disables caching.
+func NewQRService(logo image.Image, cacheSize int) *Service {
+	s := &Service{
+		Logo:     logo,
+		capacity: cacheSize,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+	if logo != nil {
+		// The SVG embeds the logo as a PNG data URI
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, logo); err == nil {
+			s.logoPNG = buf.Bytes()
+		}
+	}
+	return s
+}
+
+// Generate returns the QR code encoding the content, as PNG or SVG as requested,
+// along with its content type.
+func (s *Service) Generate(content string, req qr_model.Request) ([]byte, string, error) {
+	opts, err := s.options(req)
+	if err != nil {
+		return nil, "", err
+	}
+	contentType := "image/png"
+	if opts.format == qr_model.FormatSVG {
+		contentType = "image/svg+xml"
+	}
+
+	key := fmt.Sprintf("%s|%+v", content, opts)
+	if data, ok := s.cached(key); ok {
+		return data, contentType, nil
+	}
+
+	code, err := qrcode.New(content, levels[opts.level])
+	if err != nil {
+		return nil, "", err
+	}
+	code.DisableBorder = true
+	modules := code.Bitmap()
+
+	var data []byte
+	if opts.format == qr_model.FormatSVG {
+		data, err = s.svg(modules, opts)
+	} else {
+		data, err = s.png(modules, opts)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.store(key, data)
+	return data, contentType, nil
+}
+
+// options validates the request and fills in the defaults.
+func (s *Service) options(req qr_model.Request) (options, error) {
+	opts := options{
+		format:     strings.ToLower(req.Format),
+		size:       req.Size,
+		margin:     DefaultMargin,
+		level:      strings.ToUpper(req.Level),
+		foreground: color.RGBA{A: 0xff},
+		background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		logo:       req.Logo,
+	}
+
+	if opts.format == "" {
+		opts.format = qr_model.FormatPNG
+	}
+	if opts.format != qr_model.FormatPNG && opts.format != qr_model.FormatSVG {
+		return opts, qr_model.ErrInvalidFormat
+	}
+
+	if opts.size == 0 {
+		opts.size = DefaultSize
+	}
+	if opts.size < MinSize || opts.size > MaxSize {
+		return opts, qr_model.ErrInvalidSize
+	}
+
+	if req.Margin != nil {
+		opts.margin = *req.Margin
+	}
+	if opts.margin < 0 || opts.margin > MaxMargin {
+		return opts, qr_model.ErrInvalidMargin
+	}
+
+	if opts.level == "" {
+		opts.level = "M"
+	}
+	if _, ok := levels[opts.level]; !ok {
+		return opts, qr_model.ErrInvalidLevel
+	}
+
+	var err error
+	if req.Foreground != "" {
+		if opts.foreground, err = parseColor(req.Foreground); err != nil {
+			return opts, err
+		}
+	}
+	if req.Background != "" {
+		if opts.background, err = parseColor(req.Background); err != nil {
+			return opts, err
+		}
+	}
+	if opts.foreground == opts.background {
+		return opts, qr_model.ErrInvalidColor
+	}
+
+	if opts.logo {
+		if s.Logo == nil {
+			return opts, qr_model.ErrLogoUnavailable
+		}
+		// The logo hides modules, so the code needs the highest error correction
+		opts.level = "H"
+	}
+
+	return opts, nil
+}
+
+// png renders the modules as a PNG image of the requested size.
+func (s *Service) png(modules [][]bool, opts options) ([]byte, error) {
+	total := len(modules) + 2*opts.margin
+	scale := opts.size / total
+	if scale < 1 {
+		return nil, qr_model.ErrInvalidSize
+	}
+	// Center the code, spreading the pixels left over by the integer scale around it
+	offset := (opts.size-scale*total)/2 + opts.margin*scale
+
+	img := image.NewRGBA(image.Rect(0, 0, opts.size, opts.size))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: opts.background}, image.Point{}, draw.Src)
+	foreground := &image.Uniform{C: opts.foreground}
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				rect := image.Rect(offset+x*scale, offset+y*scale, offset+(x+1)*scale, offset+(y+1)*scale)
+				draw.Draw(img, rect, foreground, image.Point{}, draw.Src)
+			}
+		}
+	}
+
+	if opts.logo {
+		width := int(float64(len(modules)*scale) * logoRatio)
+		center := opts.size / 2
+		// Pad the logo with the background so it does not touch the modules
+		pad := scale
+		backdrop := image.Rect(center-width/2-pad, center-width/2-pad, center+width/2+pad, center+width/2+pad)
+		draw.Draw(img, backdrop, &image.Uniform{C: opts.background}, image.Point{}, draw.Src)
+		drawScaled(img, image.Rect(center-width/2, center-width/2, center+width/2, center+width/2), s.Logo)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// svg renders the modules as an SVG image of the requested size, one path for all dark modules.
+func (s *Service) svg(modules [][]bool, opts options) ([]byte, error) {
+	total := len(modules) + 2*opts.margin
+
+	var path strings.Builder
+	for y, row := range modules {
+		// Merge horizontal runs of dark modules to keep the path short
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x+1 < len(row) && row[x+1] {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start+opts.margin, y+opts.margin, x-start+1, x-start+1)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.size, opts.size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" %s/>`, total, total, svgFill(opts.background))
+	fmt.Fprintf(&buf, `<path d="%s" %s/>`, path.String(), svgFill(opts.foreground))
+
+	if opts.logo {
+		width := float64(len(modules)) * logoRatio
+		origin := float64(total)/2 - width/2
+		fmt.Fprintf(&buf, `<rect x="%g" y="%g" width="%g" height="%g" %s/>`, origin-1, origin-1, width+2, width+2, svgFill(opts.background))
+		fmt.Fprintf(&buf, `<image x="%g" y="%g" width="%g" height="%g" href="data:image/png;base64,%s"/>`,
+			origin, origin, width, width, base64.StdEncoding.EncodeToString(s.logoPNG))
+	}
+
+	buf.WriteString("</svg>")
+	return buf.Bytes(), nil
+}
+
+func (s *Service) cached(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).data, true
+}
+
+// store adds the image to the cache, evicting the least recently used ones beyond the capacity.
+func (s *Service) store(key string, data []byte) {
+	if s.capacity <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.entries[key]; ok {
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(&cacheEntry{key: key, data: data})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// parseColor parses a RRGGBB or RRGGBBAA hex color, with an optional leading "#".
+func parseColor(value string) (color.RGBA, error) {
+	value = strings.TrimPrefix(value, "#")
+	if len(value) != 6 && len(value) != 8 {
+		return color.RGBA{}, qr_model.ErrInvalidColor
+	}
+	b, err := hex.DecodeString(value)
+	if err != nil {
+		return color.RGBA{}, qr_model.ErrInvalidColor
+	}
+	c := color.RGBA{R: b[0], G: b[1], B: b[2], A: 0xff}
+	if len(b) == 4 {
+		c.A = b[3]
+	}
+	return c, nil
+}
+
+// svgFill returns the fill attributes of the color.
+func svgFill(c color.RGBA) string {
+	fill := fmt.Sprintf(`fill="#%02x%02x%02x"`, c.R, c.G, c.B)
+	if c.A != 0xff {
+		fill += fmt.Sprintf(` fill-opacity="%.3g"`, float64(c.A)/0xff)
+	}
+	return fill
+}
+
+// drawScaled draws the source image over the rectangle with nearest-neighbour scaling.
+func drawScaled(dst draw.Image, rect image.Rectangle, src image.Image) {
+	bounds := src.Bounds()
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			sx := bounds.Min.X + (x-rect.Min.X)*bounds.Dx()/rect.Dx()
+			sy := bounds.Min.Y + (y-rect.Min.Y)*bounds.Dy()/rect.Dy()
+			// Blend so transparent logos keep the backdrop
+			draw.Draw(dst, image.Rect(x, y, x+1, y+1), &image.Uniform{C: src.At(sx, sy)}, image.Point{}, draw.Over)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package qr_service
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/qr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(value int) *int {
+	return &value
+}
+
+func TestGenerate(t *testing.T) {
+	logo := image.NewRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < len(logo.Pix); i += 4 {
+		copy(logo.Pix[i:], []byte{0x10, 0x20, 0x30, 0xff})
+	}
+	qrService := NewQRService(logo, 2)
+	content := "https://sho.rt/clicks/abc123"
+
+	t.Run("Should generate PNG with defaults", func(t *testing.T) {
+		data, contentType, err := qrService.Generate(content, qr_model.Request{})
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+
+		img, err := png.Decode(bytes.NewReader(data))
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, DefaultSize, DefaultSize), img.Bounds())
+		// 29 modules and a margin of 4 scale by 6, leaving the finder pattern 41 pixels in
+		assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, color.RGBAModel.Convert(img.At(40, 40)))
+		assert.Equal(t, color.RGBA{A: 0xff}, color.RGBAModel.Convert(img.At(41, 41)))
+	})
+
+	t.Run("Should apply size, margin and colors", func(t *testing.T) {
+		data, _, err := qrService.Generate(content, qr_model.Request{Size: 300, Margin: intPtr(0), Foreground: "#ff0000", Background: "00ff00"})
+		assert.NoError(t, err)
+
+		img, _ := png.Decode(bytes.NewReader(data))
+		assert.Equal(t, 300, img.Bounds().Dx())
+		// Without margin the finder pattern reaches the edge of the scaled code
+		red := color.RGBA{R: 0xff, A: 0xff}
+		assert.Equal(t, red, color.RGBAModel.Convert(img.At(8, 8)))
+		assert.Equal(t, color.RGBA{G: 0xff, A: 0xff}, color.RGBAModel.Convert(img.At(0, 0)))
+	})
+
+	t.Run("Should generate SVG", func(t *testing.T) {
+		data, contentType, err := qrService.Generate(content, qr_model.Request{Format: "SVG", Size: 128, Background: "ffffff00"})
+		assert.NoError(t, err)
+		assert.Equal(t, "image/svg+xml", contentType)
+
+		svg := string(data)
+		assert.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="128" height="128"`))
+		assert.Contains(t, svg, `fill="#ffffff" fill-opacity="0"`)
+		assert.Contains(t, svg, `<path d="M4 4h7v1h-7z`)
+		assert.NotContains(t, svg, "<image")
+	})
+
+	t.Run("Should draw the logo", func(t *testing.T) {
+		data, _, err := qrService.Generate(content, qr_model.Request{Format: "svg", Logo: true})
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `href="data:image/png;base64,`)
+
+		data, _, err = qrService.Generate(content, qr_model.Request{Logo: true})
+		assert.NoError(t, err)
+		img, _ := png.Decode(bytes.NewReader(data))
+		assert.Equal(t, color.RGBA{R: 0x10, G: 0x20, B: 0x30, A: 0xff}, color.RGBAModel.Convert(img.At(DefaultSize/2, DefaultSize/2)))
+	})
+
+	t.Run("Should cache images by parameters", func(t *testing.T) {
+		cache := NewQRService(nil, 2)
+		first, _, _ := cache.Generate(content, qr_model.Request{Size: 100})
+		second, _, _ := cache.Generate(content, qr_model.Request{Size: 100})
+		assert.Same(t, &first[0], &second[0])
+
+		_, _, _ = cache.Generate(content, qr_model.Request{Size: 120})
+		_, _, _ = cache.Generate(content, qr_model.Request{Size: 140})
+		assert.Equal(t, 2, cache.order.Len())
+		third, _, _ := cache.Generate(content, qr_model.Request{Size: 100})
+		assert.NotSame(t, &first[0], &third[0])
+	})
+
+	t.Run("Should not cache when disabled", func(t *testing.T) {
+		uncached := NewQRService(nil, 0)
+		_, _, err := uncached.Generate(content, qr_model.Request{})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, uncached.order.Len())
+	})
+
+	t.Run("Should reject invalid parameters", func(t *testing.T) {
+		cases := []struct {
+			req qr_model.Request
+			err error
+		}{
+			{qr_model.Request{Format: "gif"}, qr_model.ErrInvalidFormat},
+			{qr_model.Request{Size: 32}, qr_model.ErrInvalidSize},
+			{qr_model.Request{Size: 4096}, qr_model.ErrInvalidSize},
+			{qr_model.Request{Margin: intPtr(-1)}, qr_model.ErrInvalidMargin},
+			{qr_model.Request{Margin: intPtr(17)}, qr_model.ErrInvalidMargin},
+			{qr_model.Request{Level: "X"}, qr_model.ErrInvalidLevel},
+			{qr_model.Request{Foreground: "red"}, qr_model.ErrInvalidColor},
+			{qr_model.Request{Background: "zzzzzz"}, qr_model.ErrInvalidColor},
+			{qr_model.Request{Foreground: "ffffff"}, qr_model.ErrInvalidColor},
+		}
+		for _, c := range cases {
+			_, _, err := qrService.Generate(content, c.req)
+			assert.ErrorIs(t, err, c.err, "%+v", c.req)
+		}
+
+		_, _, err := NewQRService(nil, 0).Generate(content, qr_model.Request{Logo: true})
+		assert.ErrorIs(t, err, qr_model.ErrLogoUnavailable)
+
+		// Long content needs more modules than a small image has pixels
+		_, _, err = qrService.Generate(strings.Repeat("a", 1000), qr_model.Request{Size: 64})
+		assert.ErrorIs(t, err, qr_model.ErrInvalidSize)
+	})
+}
@@ -0,0 +1,143 @@
+package tag_service
+
+import (
+	"errors"
+	"url-shortener/internal/app/models/tag"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/repositories/tag"
+	"url-shortener/internal/app/services/url"
+)
+
+// MaxAssignURLs is the largest number of links a single tag assignment may change.
+const MaxAssignURLs = 1000
+
+// Service provides tag functionalities for the personal links of a user.
+type Service struct {
+	Repository tag_repository.Repository
+	// URLService checks the ownership of the links being tagged.
+	URLService *url_service.Service
+}
+
+// NewTagService creates a new instance of TagService with the given tag repository and URL service.
+func NewTagService(repository tag_repository.Repository, urlService *url_service.Service) *Service {
+	return &Service{Repository: repository, URLService: urlService}
+}
+
+// ListTags returns the tags of the user by name, with the number of links having each.
+func (s *Service) ListTags(userID uint) ([]tag_model.Tag, error) {
+	return s.Repository.List(userID)
+}
+
+// CreateTag creates a tag for the user.
+func (s *Service) CreateTag(userID uint, name string) (*tag_model.Tag, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the name is free before inserting
+	_, err = s.Repository.GetByName(userID, name)
+	if err == nil {
+		return nil, tag_model.ErrTagAlreadyExists
+	}
+	if !errors.Is(err, tag_model.ErrTagNotFound) {
+		return nil, err
+	}
+
+	return s.Repository.Create(userID, name)
+}
+
+// RenameTag renames a tag of the user; links keep the tag under its new name.
+func (s *Service) RenameTag(userID, tagID uint, name string) (*tag_model.Tag, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, err := s.Repository.GetByID(userID, tagID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.Name == name {
+		return tag, nil
+	}
+
+	_, err = s.Repository.GetByName(userID, name)
+	if err == nil {
+		return nil, tag_model.ErrTagAlreadyExists
+	}
+	if !errors.Is(err, tag_model.ErrTagNotFound) {
+		return nil, err
+	}
+
+	if err := s.Repository.Rename(tagID, name); err != nil {
+		return nil, err
+	}
+
+	tag.Name = name
+	return tag, nil
+}
+
+// DeleteTag removes a tag of the user from its links and deletes it.
+func (s *Service) DeleteTag(userID, tagID uint) error {
+	if _, err := s.Repository.GetByID(userID, tagID); err != nil {
+		return err
+	}
+
+	return s.Repository.Delete(tagID)
+}
+
+// AssignTags adds and removes tags of many personal links of the user at once. Added tags are created
+// when missing, and nothing changes unless the user owns every link.
+func (s *Service) AssignTags(userID uint, req tag_model.AssignRequest) error {
+	if len(req.URLs) == 0 {
+		return tag_model.ErrNoURLs
+	}
+	if len(req.URLs) > MaxAssignURLs {
+		return url_model.ErrTooManyURLs
+	}
+
+	add, err := url_service.NormalizeTags(req.Add)
+	if err != nil {
+		return err
+	}
+	remove, err := url_service.NormalizeTags(req.Remove)
+	if err != nil {
+		return err
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return tag_model.ErrNoTagChanges
+	}
+
+	shortCodes := make([]string, 0, len(req.URLs))
+	seen := make(map[string]bool)
+	for _, shortCode := range req.URLs {
+		if seen[shortCode] {
+			continue
+		}
+		seen[shortCode] = true
+		if err := s.URLService.RequireOwner(userID, shortCode); err != nil {
+			return err
+		}
+		shortCodes = append(shortCodes, shortCode)
+	}
+
+	return s.Repository.Assign(userID, shortCodes, add, remove)
+}
+
+// GetStats returns the aggregate clicks of the links of each tag of the user.
+func (s *Service) GetStats(userID uint) ([]tag_model.Stats, error) {
+	return s.Repository.Stats(userID)
+}
+
+// normalizeName trims a tag name and validates it like the tags of bulk created links.
+func normalizeName(name string) (string, error) {
+	names, err := url_service.NormalizeTags([]string{name})
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", url_model.ErrInvalidTag
+	}
+	return names[0], nil
+}
@@ -0,0 +1,167 @@
+package tag_service
+
+import (
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/tag"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateTag(t *testing.T) {
+	// User 1 owns the links "first" and "second", with a workspace link "shared" and a link "other" of user 2
+	urlRepository := mocks.NewMockUrlRepository()
+	tagRepository := mocks.NewMockTagRepository()
+	tagRepository.URLTags = urlRepository.Tags
+
+	owner, other := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.org", "second", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &other)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewTagService(tagRepository, urlService)
+
+	t.Run("Should create a trimmed tag", func(t *testing.T) {
+		tag, err := service.CreateTag(1, "  news ")
+		assert.NoError(t, err)
+		assert.Equal(t, "news", tag.Name)
+
+		tags, err := service.ListTags(1)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 1)
+	})
+
+	t.Run("Should reject duplicate names", func(t *testing.T) {
+		_, err := service.CreateTag(1, "news")
+		assert.ErrorIs(t, err, tag_model.ErrTagAlreadyExists)
+
+		// Tags are per user
+		_, err = service.CreateTag(2, "news")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should reject invalid names", func(t *testing.T) {
+		for _, name := range []string{" ", "a,b", strings.Repeat("a", 51)} {
+			_, err := service.CreateTag(1, name)
+			assert.ErrorIs(t, err, url_model.ErrInvalidTag)
+		}
+	})
+}
+
+func TestRenameAndDeleteTag(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	tagRepository := mocks.NewMockTagRepository()
+	tagRepository.URLTags = urlRepository.Tags
+
+	owner, other := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.org", "second", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &other)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewTagService(tagRepository, urlService)
+	news, _ := service.CreateTag(1, "news")
+	_, _ = service.CreateTag(1, "sport")
+	assert.NoError(t, service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"first"}, Add: []string{"news"}}))
+
+	t.Run("Should rename the tag of its links", func(t *testing.T) {
+		tag, err := service.RenameTag(1, news.ID, "press")
+		assert.NoError(t, err)
+		assert.Equal(t, "press", tag.Name)
+
+		urls, err := service.URLService.FindUserURLs(1, url_model.Filter{Tag: "press"})
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+	})
+
+	t.Run("Should keep an unchanged name", func(t *testing.T) {
+		tag, err := service.RenameTag(1, news.ID, "press")
+		assert.NoError(t, err)
+		assert.Equal(t, "press", tag.Name)
+	})
+
+	t.Run("Should reject taken names and tags of other users", func(t *testing.T) {
+		_, err := service.RenameTag(1, news.ID, "sport")
+		assert.ErrorIs(t, err, tag_model.ErrTagAlreadyExists)
+
+		_, err = service.RenameTag(2, news.ID, "mine")
+		assert.ErrorIs(t, err, tag_model.ErrTagNotFound)
+
+		assert.ErrorIs(t, service.DeleteTag(2, news.ID), tag_model.ErrTagNotFound)
+	})
+
+	t.Run("Should delete the tag from its links", func(t *testing.T) {
+		assert.NoError(t, service.DeleteTag(1, news.ID))
+
+		urls, err := service.URLService.FindUserURLs(1, url_model.Filter{Tag: "press"})
+		assert.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+}
+
+func TestAssignTags(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	tagRepository := mocks.NewMockTagRepository()
+	tagRepository.URLTags = urlRepository.Tags
+
+	owner, other := uint(1), uint(2)
+	_, _ = urlRepository.CreateURL("https://www.example.com", "first", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.org", "second", &owner)
+	_, _ = urlRepository.CreateURL("https://www.example.net", "other", &other)
+	_, _ = urlRepository.CreateWorkspaceURL("https://www.example.com", "shared", owner, 1)
+
+	urlService := url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository())
+	service := NewTagService(tagRepository, urlService)
+
+	t.Run("Should add tags to many links, creating missing ones", func(t *testing.T) {
+		err := service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"first", "second", "first"}, Add: []string{"news", " promo"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"news", "promo"}, tagRepository.URLTags["second"])
+
+		tags, err := service.ListTags(1)
+		assert.NoError(t, err)
+		assert.Len(t, tags, 2)
+		assert.Equal(t, 2, tags[0].URLCount)
+	})
+
+	t.Run("Should remove tags", func(t *testing.T) {
+		err := service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"second"}, Remove: []string{"promo"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"news"}, tagRepository.URLTags["second"])
+
+		stats, err := service.GetStats(1)
+		assert.NoError(t, err)
+		assert.Equal(t, tag_model.Stats{TagID: stats[1].TagID, Name: "promo", URLs: 1}, stats[1])
+	})
+
+	t.Run("Should only tag personal links of the user", func(t *testing.T) {
+		for _, code := range []string{"other", "shared"} {
+			err := service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"first", code}, Add: []string{"private"}})
+			assert.ErrorIs(t, err, url_model.ErrForbidden)
+		}
+		assert.NotContains(t, tagRepository.URLTags["first"], "private")
+
+		err := service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"missing"}, Add: []string{"news"}})
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should validate the request", func(t *testing.T) {
+		err := service.AssignTags(1, tag_model.AssignRequest{Add: []string{"news"}})
+		assert.ErrorIs(t, err, tag_model.ErrNoURLs)
+
+		err = service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"first"}, Add: []string{" "}})
+		assert.ErrorIs(t, err, tag_model.ErrNoTagChanges)
+
+		err = service.AssignTags(1, tag_model.AssignRequest{URLs: []string{"first"}, Remove: []string{"a;b"}})
+		assert.ErrorIs(t, err, url_model.ErrInvalidTag)
+
+		err = service.AssignTags(1, tag_model.AssignRequest{URLs: make([]string, MaxAssignURLs+1), Add: []string{"news"}})
+		assert.ErrorIs(t, err, url_model.ErrTooManyURLs)
+	})
+}
@@ -11,11 +11,20 @@ import (
 // Service handles JWT token generation and validation.
 type Service struct {
 	secretKey string
+	// Users is checked when validating tokens if set, so that the tokens of disabled or deleted
+	// accounts are rejected before they expire.
+	Users UserRepository
+}
+
+// UserRepository looks up the accounts tokens are issued for.
+type UserRepository interface {
+	GetByID(id uint) (*user_model.User, error)
 }
 
 type TokenRepository interface {
 	GenerateToken(user *user_model.User) (string, error)
 	ValidateToken(tokenString string) (uint, error)
+	ValidateClaims(tokenString string) (*Claims, error)
 }
 
 // NewTokenService creates a new instance of Service.
@@ -26,6 +35,8 @@ func NewTokenService(secretKey string) *Service {
 // Claims represents the JWT claims.
 type Claims struct {
 	UserID uint `json:"user_id"`
+	// Role is the role of the user when the token was issued.
+	Role string `json:"role"`
 	jwt.StandardClaims
 }
 
@@ -34,9 +45,14 @@ func (ts *Service) GenerateToken(user *user_model.User) (string, error) {
 	// Define the expiration time for the token
 	expirationTime := time.Now().Add(24 * time.Hour)
 
-	// Create the JWT claims, which include the user ID and expiration time
+	// Create the JWT claims, which include the user ID, role and expiration time
+	role := user.Role
+	if role == "" {
+		role = user_model.RoleUser
+	}
 	claims := &Claims{
 		UserID: user.ID,
+		Role:   role,
 		StandardClaims: jwt.StandardClaims{
 			ExpiresAt: expirationTime.Unix(),
 			IssuedAt:  time.Now().Unix(),
@@ -55,6 +71,16 @@ func (ts *Service) GenerateToken(user *user_model.User) (string, error) {
 
 // ValidateToken validates the provided JWT token and extracts the user ID.
 func (ts *Service) ValidateToken(tokenString string) (uint, error) {
+	claims, err := ts.ValidateClaims(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	return claims.UserID, nil
+}
+
+// ValidateClaims validates the provided JWT token and returns its claims.
+func (ts *Service) ValidateClaims(tokenString string) (*Claims, error) {
 	// Parse the token
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(ts.secretKey), nil
@@ -62,21 +88,36 @@ func (ts *Service) ValidateToken(tokenString string) (uint, error) {
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrSignatureInvalid) {
-			return 0, errors.New("invalid token signature")
+			return nil, errors.New("invalid token signature")
 		}
-		return 0, err
+		return nil, err
 	}
 
 	// Check if token is valid
 	if !token.Valid {
-		return 0, errors.New("invalid token")
+		return nil, errors.New("invalid token")
 	}
 
-	// Extract user ID from claims
+	// Extract the claims
 	claims, ok := token.Claims.(*Claims)
 	if !ok {
-		return 0, errors.New("invalid token claims")
+		return nil, errors.New("invalid token claims")
 	}
 
-	return claims.UserID, nil
+	// Tokens issued before roles existed carry none
+	if claims.Role == "" {
+		claims.Role = user_model.RoleUser
+	}
+
+	if ts.Users != nil {
+		user, err := ts.Users.GetByID(claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if user.Disabled {
+			return nil, user_model.ErrAccountDisabled
+		}
+	}
+
+	return claims, nil
 }
@@ -35,8 +35,42 @@ func TestTokenService_GenerateToken(t *testing.T) {
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	assert.True(t, ok)
 
-	// Validate the user ID in the claims
+	// Validate the user ID and default role in the claims
 	assert.Equal(t, float64(user.ID), claims["user_id"])
+	assert.Equal(t, user_model.RoleUser, claims["role"])
+}
+
+func TestTokenService_ValidateClaims(t *testing.T) {
+	tokenService := NewTokenService(MockSecretKey)
+
+	t.Run("Should carry role", func(t *testing.T) {
+		token, err := tokenService.GenerateToken(&user_model.User{ID: 1, Role: user_model.RoleAdmin})
+		assert.NoError(t, err)
+
+		claims, err := tokenService.ValidateClaims(token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), claims.UserID)
+		assert.Equal(t, user_model.RoleAdmin, claims.Role)
+	})
+
+	t.Run("Should default role of tokens without one", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			UserID:         1,
+			StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+		})
+		signedToken, _ := token.SignedString([]byte(MockSecretKey))
+
+		claims, err := tokenService.ValidateClaims(signedToken)
+		assert.NoError(t, err)
+		assert.Equal(t, user_model.RoleUser, claims.Role)
+	})
+
+	t.Run("Should reject token signed with another key", func(t *testing.T) {
+		token, _ := NewTokenService("other").GenerateToken(&user_model.User{ID: 1, Role: user_model.RoleAdmin})
+
+		_, err := tokenService.ValidateClaims(token)
+		assert.Error(t, err)
+	})
 }
 
 func TestTokenService_ValidateToken(t *testing.T) {
@@ -81,3 +115,34 @@ func TestTokenService_ValidateToken(t *testing.T) {
 	})
 
 }
+
+// userRepository is a UserRepository holding the given users.
+type userRepository map[uint]*user_model.User
+
+func (r userRepository) GetByID(id uint) (*user_model.User, error) {
+	user, ok := r[id]
+	if !ok {
+		return nil, user_model.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func TestTokenService_ValidateAccount(t *testing.T) {
+	tokenService := NewTokenService(MockSecretKey)
+	users := userRepository{1: {ID: 1}, 2: {ID: 2, Disabled: true}}
+	tokenService.Users = users
+
+	active, _ := tokenService.GenerateToken(users[1])
+	disabled, _ := tokenService.GenerateToken(users[2])
+	deleted, _ := tokenService.GenerateToken(&user_model.User{ID: 3})
+
+	userID, err := tokenService.ValidateToken(active)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), userID)
+
+	_, err = tokenService.ValidateToken(disabled)
+	assert.ErrorIs(t, err, user_model.ErrAccountDisabled)
+
+	_, err = tokenService.ValidateClaims(deleted)
+	assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+}
@@ -0,0 +1,118 @@
+package url_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// maxNotActiveMessageLength is the longest message of the page shown before a link is active, in characters.
+const maxNotActiveMessageLength = 2000
+
+// GetActivation returns the window in which the URL resolves and what visitors get before it opens.
+// The user needs view access to the URL.
+func (s *Service) GetActivation(userID uint, shortURL string) (*url_model.Activation, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.NotActive(shortURL)
+}
+
+// SetActivation validates and replaces the window in which the URL resolves and what visitors get before
+// it opens, returning it normalized. Invalid windows fail with an error wrapping url_model.ErrInvalidActivation.
+// The user needs edit access to the URL.
+func (s *Service) SetActivation(userID uint, shortURL string, activation url_model.Activation) (*url_model.Activation, error) {
+	activation, err := s.validateActivation(activation)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.SetActivation(shortURL, activation.ActiveFrom, activation.ActiveUntil); err != nil {
+		return nil, err
+	}
+	// Links answering as missing have no row
+	response := &activation.NotActive
+	if *response == (url_model.NotActiveResponse{Mode: url_model.NotActiveNotFound}) {
+		response = nil
+	}
+	if err := s.Repository.SetNotActiveResponse(shortURL, response); err != nil {
+		return nil, err
+	}
+	return &activation, nil
+}
+
+// NotActive returns the activation window of the URL and what visitors get before it opens, answering as
+// if the URL did not exist by default.
+func (s *Service) NotActive(shortURL string) (*url_model.Activation, error) {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.Repository.GetNotActiveResponse(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if response == nil {
+		response = &url_model.NotActiveResponse{Mode: url_model.NotActiveNotFound}
+	}
+
+	return &url_model.Activation{ActiveFrom: u.ActiveFrom, ActiveUntil: u.ExpiresAt, NotActive: *response}, nil
+}
+
+// withStatus sets the status of the listed links.
+func withStatus(urls []url_model.URL) []url_model.URL {
+	now := time.Now()
+	for i := range urls {
+		urls[i].Status = urls[i].StatusAt(now)
+	}
+	return urls
+}
+
+// validateActivation checks the window and the response before it opens, returning them with the default mode.
+func (s *Service) validateActivation(activation url_model.Activation) (url_model.Activation, error) {
+	if activation.ActiveFrom != nil && activation.ActiveUntil != nil && !activation.ActiveUntil.After(*activation.ActiveFrom) {
+		return activation, fmt.Errorf("%w: active_until must be after active_from", url_model.ErrInvalidActivation)
+	}
+
+	response := &activation.NotActive
+	response.URL = strings.TrimSpace(response.URL)
+	response.Message = strings.TrimSpace(response.Message)
+	switch response.Mode {
+	case "":
+		response.Mode = url_model.NotActiveNotFound
+	case url_model.NotActiveNotFound, url_model.NotActiveRedirect, url_model.NotActivePage:
+	default:
+		return activation, fmt.Errorf("%w: mode must be %q, %q or %q", url_model.ErrInvalidActivation,
+			url_model.NotActiveNotFound, url_model.NotActiveRedirect, url_model.NotActivePage)
+	}
+
+	if response.Mode == url_model.NotActiveRedirect {
+		if response.URL == "" {
+			return activation, fmt.Errorf("%w: redirect needs a url", url_model.ErrInvalidActivation)
+		}
+		if err := s.Safety.Check(response.URL); err != nil {
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				return activation, fmt.Errorf("%w: url: %s", url_model.ErrInvalidActivation, rejection.Detail)
+			}
+			return activation, err
+		}
+	} else {
+		response.URL = ""
+	}
+	if response.Mode != url_model.NotActivePage {
+		response.Message = ""
+	}
+	if utf8.RuneCountInString(response.Message) > maxNotActiveMessageLength {
+		return activation, fmt.Errorf("%w: message must be at most %d characters", url_model.ErrInvalidActivation, maxNotActiveMessageLength)
+	}
+	return activation, nil
+}
@@ -0,0 +1,117 @@
+package url_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivation(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com/launch", "launch", &owner)
+	_, _ = repository.CreateURL("https://www.example.com/sale", "sale", &owner)
+	_, _ = repository.CreateURL("https://www.example.com/home", "home", &owner)
+	activeFrom := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	ended := time.Now().Add(-time.Hour)
+
+	t.Run("Should save a window answering as missing by default", func(t *testing.T) {
+		saved, err := urlService.SetActivation(owner, "launch", url_model.Activation{ActiveFrom: &activeFrom})
+
+		assert.NoError(t, err)
+		assert.Equal(t, url_model.NotActiveNotFound, saved.NotActive.Mode)
+
+		found, err := urlService.GetActivation(owner, "launch")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+
+		_, err = urlService.GetOriginalURL("launch")
+		assert.ErrorIs(t, err, url_model.ErrURLNotActive)
+	})
+
+	t.Run("Should save the page shown before the link opens", func(t *testing.T) {
+		activation := url_model.Activation{
+			ActiveFrom: &activeFrom,
+			NotActive:  url_model.NotActiveResponse{Mode: url_model.NotActivePage, URL: "https://www.example.com", Message: " Opens soon "},
+		}
+		saved, err := urlService.SetActivation(owner, "launch", activation)
+
+		assert.NoError(t, err)
+		assert.Equal(t, url_model.NotActiveResponse{Mode: url_model.NotActivePage, Message: "Opens soon"}, saved.NotActive)
+
+		found, err := urlService.NotActive("launch")
+		assert.NoError(t, err)
+		assert.Equal(t, saved.NotActive, found.NotActive)
+	})
+
+	t.Run("Should list links with their status", func(t *testing.T) {
+		_, err := urlService.SetActivation(owner, "sale", url_model.Activation{ActiveUntil: &ended})
+		assert.NoError(t, err)
+
+		urls, err := urlService.GetUserURLs(owner)
+		assert.NoError(t, err)
+
+		status := map[string]string{}
+		for _, u := range urls {
+			status[u.ShortenedURL] = u.Status
+		}
+		assert.Equal(t, map[string]string{
+			"launch": url_model.StatusScheduled,
+			"sale":   url_model.StatusExpired,
+			"home":   url_model.StatusActive,
+		}, status)
+	})
+
+	t.Run("Should count the alias of a scheduled link as taken", func(t *testing.T) {
+		_, err := urlService.ShortenURLWithAlias("https://www.example.com/other", "launch", &owner)
+		assert.ErrorIs(t, err, url_model.ErrShortCodeAlreadyExists)
+	})
+
+	t.Run("Should only let editors set the window", func(t *testing.T) {
+		_, err := urlService.SetActivation(2, "launch", url_model.Activation{})
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetActivation(2, "launch")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove the window", func(t *testing.T) {
+		_, err := urlService.SetActivation(owner, "launch", url_model.Activation{})
+		assert.NoError(t, err)
+
+		found, err := urlService.GetActivation(owner, "launch")
+		assert.NoError(t, err)
+		assert.Equal(t, &url_model.Activation{NotActive: url_model.NotActiveResponse{Mode: url_model.NotActiveNotFound}}, found)
+
+		_, err = urlService.GetOriginalURL("launch")
+		assert.NoError(t, err)
+	})
+}
+
+func TestActivation_Validation(t *testing.T) {
+	urlService := NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+
+	from := time.Now().Add(time.Hour)
+	until := from.Add(-time.Minute)
+	tests := []struct {
+		name       string
+		activation url_model.Activation
+	}{
+		{"Until Before From", url_model.Activation{ActiveFrom: &from, ActiveUntil: &until}},
+		{"Unknown Mode", url_model.Activation{NotActive: url_model.NotActiveResponse{Mode: "teapot"}}},
+		{"Redirect Without URL", url_model.Activation{NotActive: url_model.NotActiveResponse{Mode: url_model.NotActiveRedirect}}},
+		{"Unsafe URL", url_model.Activation{NotActive: url_model.NotActiveResponse{Mode: url_model.NotActiveRedirect, URL: "javascript:alert(1)"}}},
+		{"Long Message", url_model.Activation{NotActive: url_model.NotActiveResponse{Mode: url_model.NotActivePage, Message: strings.Repeat("a", maxNotActiveMessageLength+1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetActivation(1, "launch", tt.activation)
+			assert.ErrorIs(t, err, url_model.ErrInvalidActivation)
+		})
+	}
+}
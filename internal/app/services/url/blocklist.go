@@ -0,0 +1,179 @@
+package url_service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blocklist holds the blocked domains and URL patterns, loaded from local files.
+// It is safe for concurrent use and can be reloaded while serving requests.
+type Blocklist struct {
+	// DomainsPath is a file of blocked domains, one per line; subdomains are blocked too.
+	DomainsPath string
+	// PatternsPath is a file of regular expressions matched against the whole URL, one per line.
+	PatternsPath string
+
+	mu          sync.RWMutex
+	domains     map[string]struct{}
+	patterns    []*regexp.Regexp
+	domainsMod  time.Time
+	patternsMod time.Time
+}
+
+// NewBlocklist loads the blocklist files. An empty path leaves that list empty.
+func NewBlocklist(domainsPath, patternsPath string) (*Blocklist, error) {
+	b := &Blocklist{DomainsPath: domainsPath, PatternsPath: patternsPath}
+	if _, err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reload reloads the files modified since the last load and reports whether anything changed.
+// On error the lists loaded before are kept.
+func (b *Blocklist) Reload() (bool, error) {
+	domainsMod, domainsChanged, err := modified(b.DomainsPath, b.loadedAt(&b.domainsMod))
+	if err != nil {
+		return false, err
+	}
+	patternsMod, patternsChanged, err := modified(b.PatternsPath, b.loadedAt(&b.patternsMod))
+	if err != nil {
+		return false, err
+	}
+
+	var domains map[string]struct{}
+	if domainsChanged {
+		lines, err := readLines(b.DomainsPath)
+		if err != nil {
+			return false, err
+		}
+		domains = make(map[string]struct{}, len(lines))
+		for _, line := range lines {
+			domains[normalizeHost(line)] = struct{}{}
+		}
+	}
+
+	var patterns []*regexp.Regexp
+	if patternsChanged {
+		lines, err := readLines(b.PatternsPath)
+		if err != nil {
+			return false, err
+		}
+		for _, line := range lines {
+			pattern, err := regexp.Compile(line)
+			if err != nil {
+				return false, fmt.Errorf("invalid blocklist pattern %q: %w", line, err)
+			}
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if domainsChanged {
+		b.domains, b.domainsMod = domains, domainsMod
+	}
+	if patternsChanged {
+		b.patterns, b.patternsMod = patterns, patternsMod
+	}
+
+	return domainsChanged || patternsChanged, nil
+}
+
+// Watch reloads the files every interval until stop is closed.
+func (b *Blocklist) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			changed, err := b.Reload()
+			if err != nil {
+				fmt.Println("[BLOCKLIST] Error reloading blocklist:", err)
+			} else if changed {
+				fmt.Println("[BLOCKLIST] Blocklist reloaded")
+			}
+		}
+	}
+}
+
+// BlockedDomain returns the blocklist entry matching the host or one of its parent domains.
+func (b *Blocklist) BlockedDomain(host string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	host = normalizeHost(host)
+	for {
+		if _, ok := b.domains[host]; ok {
+			return host, true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return "", false
+		}
+		host = parent
+	}
+}
+
+// BlockedPattern returns the first pattern matching the URL.
+func (b *Blocklist) BlockedPattern(rawURL string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, pattern := range b.patterns {
+		if pattern.MatchString(rawURL) {
+			return pattern.String(), true
+		}
+	}
+	return "", false
+}
+
+func (b *Blocklist) loadedAt(mod *time.Time) time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return *mod
+}
+
+// modified returns the modification time of the file and whether it differs from the last load.
+func modified(path string, last time.Time) (time.Time, bool, error) {
+	if path == "" {
+		return time.Time{}, false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to open blocklist: %w", err)
+	}
+	return info.ModTime(), !info.ModTime().Equal(last), nil
+}
+
+// readLines reads the non-empty lines of the file, skipping "#" comments.
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path) // #nosec G304 -- path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blocklist: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read blocklist: %w", err)
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,83 @@
+package url_service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlocklist(t *testing.T) {
+	dir := t.TempDir()
+	domains := filepath.Join(dir, "domains.txt")
+	assert.NoError(t, os.WriteFile(domains, []byte("evil.com\n"), 0o600))
+
+	blocklist, err := NewBlocklist(domains, "")
+	assert.NoError(t, err)
+
+	// rewrite replaces the file and moves its modification time forward
+	rewrite := func(path, content string, mod time.Time) {
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		assert.NoError(t, os.Chtimes(path, mod, mod))
+	}
+
+	t.Run("Should match domains and subdomains", func(t *testing.T) {
+		entry, ok := blocklist.BlockedDomain("www.evil.com")
+		assert.True(t, ok)
+		assert.Equal(t, "evil.com", entry)
+
+		_, ok = blocklist.BlockedDomain("evil.com.example.org")
+		assert.False(t, ok)
+	})
+
+	t.Run("Should not reload unchanged files", func(t *testing.T) {
+		changed, err := blocklist.Reload()
+		assert.NoError(t, err)
+		assert.False(t, changed)
+	})
+
+	t.Run("Should reload modified files", func(t *testing.T) {
+		rewrite(domains, "bad.org\n", time.Now().Add(time.Minute))
+
+		changed, err := blocklist.Reload()
+		assert.NoError(t, err)
+		assert.True(t, changed)
+
+		_, ok := blocklist.BlockedDomain("evil.com")
+		assert.False(t, ok)
+		_, ok = blocklist.BlockedDomain("bad.org")
+		assert.True(t, ok)
+	})
+
+	t.Run("Should keep the loaded lists when a reload fails", func(t *testing.T) {
+		patterns := filepath.Join(dir, "patterns.txt")
+		rewrite(patterns, "[\n", time.Now())
+		blocklist.PatternsPath = patterns
+
+		_, err := blocklist.Reload()
+		assert.Error(t, err)
+		_, ok := blocklist.BlockedDomain("bad.org")
+		assert.True(t, ok)
+	})
+
+	t.Run("Should reload while watching", func(t *testing.T) {
+		blocklist.PatternsPath = ""
+		stop := make(chan struct{})
+		defer close(stop)
+		go blocklist.Watch(10*time.Millisecond, stop)
+
+		rewrite(domains, "worse.net\n", time.Now().Add(2*time.Minute))
+
+		assert.Eventually(t, func() bool {
+			_, ok := blocklist.BlockedDomain("worse.net")
+			return ok
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Should return error for missing files", func(t *testing.T) {
+		_, err := NewBlocklist(filepath.Join(dir, "missing.txt"), "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,230 @@
+package url_service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// maxTagLength is the longest tag name, matching the tags table.
+const maxTagLength = 50
+
+// BulkLimits bounds the number of URLs of a bulk creation.
+type BulkLimits struct {
+	// MaxURLs is the largest request processed while the client waits.
+	MaxURLs int
+	// MaxAsyncURLs is the largest request processed as a background job.
+	MaxAsyncURLs int
+	// MaxImportURLs is the largest import.
+	MaxImportURLs int
+}
+
+// DefaultBulkLimits returns the limits used when none are configured.
+func DefaultBulkLimits() BulkLimits {
+	return BulkLimits{
+		MaxURLs:       1000,
+		MaxAsyncURLs:  50000,
+		MaxImportURLs: 50000,
+	}
+}
+
+// ShortenBulk validates each item and inserts the valid ones in a single transaction,
+// reporting the outcome of every row. Workspace links need the editor role in the workspace.
+// The error is only set when nothing could be attempted.
+func (s *Service) ShortenBulk(userID uint, workspaceID *uint, items []url_model.BulkItem) (*url_model.BulkReport, error) {
+	if workspaceID != nil {
+		if err := s.requireMember(*workspaceID, userID, workspace_model.RoleEditor); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &url_model.BulkReport{Total: len(items), Results: make([]url_model.BulkResult, len(items))}
+	var valid []url_model.NewURL
+	var validRows []int
+	aliases := make(map[string]bool)
+
+	for i, item := range items {
+		result := &report.Results[i]
+		result.Row = i + 1
+		result.URL = item.URL
+
+		newURL, err := s.bulkURL(item, aliases)
+		if err != nil {
+			result.Error = err.Error()
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				result.Reason = rejection.Reason
+			}
+			continue
+		}
+		newURL.UserID = userID
+		newURL.WorkspaceID = workspaceID
+		valid = append(valid, *newURL)
+		validRows = append(validRows, i)
+	}
+
+	if len(valid) > 0 {
+		if err := s.Repository.CreateURLs(valid); err != nil {
+			// The transaction was rolled back, so none of the valid rows were created
+			for _, i := range validRows {
+				report.Results[i].Error = err.Error()
+			}
+		} else {
+			for j, i := range validRows {
+				report.Results[i].ShortenedURL = valid[j].ShortCode
+			}
+		}
+	}
+
+	for _, result := range report.Results {
+		if result.Error == "" {
+			report.Created++
+		} else {
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// bulkURL validates one item of a bulk creation. Aliases taken by earlier rows are rejected.
+func (s *Service) bulkURL(item url_model.BulkItem, aliases map[string]bool) (*url_model.NewURL, error) {
+	originalURL := strings.TrimSpace(item.URL)
+	if originalURL == "" {
+		return nil, &url_model.Rejection{Reason: url_model.ReasonInvalidURL, Detail: "URL is required"}
+	}
+	if err := s.Safety.Check(originalURL); err != nil {
+		return nil, err
+	}
+
+	alias := strings.TrimSpace(item.Alias)
+	if alias != "" && aliases[alias] {
+		return nil, url_model.ErrDuplicateAlias
+	}
+	shortCode, err := s.shortCode(alias)
+	if err != nil {
+		return nil, err
+	}
+	aliases[shortCode] = true
+
+	expiresAt, err := s.parseExpiry(item.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := NormalizeTags(item.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return &url_model.NewURL{
+		OriginalURL: originalURL,
+		ShortCode:   shortCode,
+		ExpiresAt:   expiresAt,
+		Tags:        tags,
+	}, nil
+}
+
+// parseExpiry parses an RFC 3339 time or a YYYY-MM-DD date, read as midnight UTC.
+// An empty expiry returns nil; past expiries are rejected.
+func (s *Service) parseExpiry(expiry string) (*time.Time, error) {
+	expiry = strings.TrimSpace(expiry)
+	if expiry == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, expiry)
+	if err != nil {
+		if t, err = time.Parse(time.DateOnly, expiry); err != nil {
+			return nil, url_model.ErrInvalidExpiry
+		}
+	}
+	if !t.After(s.now()) {
+		return nil, url_model.ErrInvalidExpiry
+	}
+
+	t = t.UTC()
+	return &t, nil
+}
+
+// NormalizeTags trims the tags and drops empty and duplicate ones, keeping their order.
+func NormalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if len(tag) > maxTagLength || strings.ContainsAny(tag, ",;") {
+			return nil, url_model.ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// ParseBulkCSV reads bulk items from a CSV file whose header names the url, alias, tags and expiry
+// columns; only url is required. Tags are separated by semicolons. Reading stops with
+// url_model.ErrTooManyURLs once more than max rows are found.
+func ParseBulkCSV(r io.Reader, max int) ([]url_model.BulkItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV file is empty")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		// Spreadsheet exports may start with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["url"]; !ok {
+		return nil, errors.New("CSV header must have a url column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var items []url_model.BulkItem
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(items) == max {
+			return nil, url_model.ErrTooManyURLs
+		}
+
+		item := url_model.BulkItem{
+			URL:    field(record, "url"),
+			Alias:  field(record, "alias"),
+			Expiry: field(record, "expiry"),
+		}
+		if tags := field(record, "tags"); tags != "" {
+			item.Tags = strings.Split(tags, ";")
+		}
+		items = append(items, item)
+	}
+}
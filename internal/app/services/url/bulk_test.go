@@ -0,0 +1,113 @@
+package url_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortenBulk(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	workspaceRepo := mocks.NewMockWorkspaceRepository()
+	urlService := NewURLService(repository, workspaceRepo)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	urlService.now = func() time.Time { return now }
+
+	owner := uint(1)
+	_, _ = urlService.ShortenURLWithAlias("https://www.example.com", "taken", &owner)
+
+	t.Run("Should report every row", func(t *testing.T) {
+		report, err := urlService.ShortenBulk(owner, nil, []url_model.BulkItem{
+			{URL: "https://docs.example.com", Alias: "docs", Tags: []string{"docs", " docs ", "team"}, Expiry: "2026-06-01"},
+			{URL: "https://www.example.org"},
+			{URL: ""},
+			{URL: "ftp://files.example.com"},
+			{URL: "https://www.example.net", Alias: "taken"},
+			{URL: "https://www.example.net", Alias: "docs"},
+			{URL: "https://www.example.net", Expiry: "2025-12-31"},
+			{URL: "https://www.example.net", Tags: []string{"a,b"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 8, report.Total)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 6, report.Failed)
+
+		assert.Equal(t, "docs", report.Results[0].ShortenedURL)
+		assert.Len(t, report.Results[1].ShortenedURL, 8)
+		assert.Equal(t, url_model.ReasonInvalidURL, report.Results[2].Reason)
+		assert.Equal(t, url_model.ReasonScheme, report.Results[3].Reason)
+		assert.Equal(t, url_model.ErrShortCodeAlreadyExists.Error(), report.Results[4].Error)
+		assert.Equal(t, url_model.ErrDuplicateAlias.Error(), report.Results[5].Error)
+		assert.Equal(t, url_model.ErrInvalidExpiry.Error(), report.Results[6].Error)
+		assert.Equal(t, url_model.ErrInvalidTag.Error(), report.Results[7].Error)
+		for i, result := range report.Results {
+			assert.Equal(t, i+1, result.Row)
+		}
+
+		assert.Equal(t, []string{"docs", "team"}, repository.Tags["docs"])
+		created := repository.Urls[2]
+		assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), *created.ExpiresAt)
+		assert.Equal(t, owner, created.UserID)
+	})
+
+	t.Run("Should fail all valid rows when the transaction fails", func(t *testing.T) {
+		count := len(repository.Urls)
+		report, err := urlService.ShortenBulk(owner, nil, []url_model.BulkItem{
+			{URL: "https://www.example.org"},
+			{URL: "http://error.com"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 2, report.Failed)
+		assert.Empty(t, report.Results[0].ShortenedURL)
+		assert.Len(t, repository.Urls, count)
+	})
+
+	t.Run("Should require the editor role in the workspace", func(t *testing.T) {
+		workspace, _ := workspaceRepo.Create(&workspace_model.Workspace{Name: "Team", CreatedBy: 1})
+		_ = workspaceRepo.AddMember(workspace.ID, 3, workspace_model.RoleViewer)
+
+		_, err := urlService.ShortenBulk(3, &workspace.ID, []url_model.BulkItem{{URL: "https://www.example.org"}})
+		assert.ErrorIs(t, err, workspace_model.ErrInsufficientRole)
+
+		report, err := urlService.ShortenBulk(owner, &workspace.ID, []url_model.BulkItem{{URL: "https://www.example.org", Alias: "team-bulk"}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, workspace.ID, *repository.Urls[uint(len(repository.Urls))].WorkspaceID)
+	})
+}
+
+func TestParseBulkCSV(t *testing.T) {
+	t.Run("Should read the columns named by the header", func(t *testing.T) {
+		items, err := ParseBulkCSV(strings.NewReader("\ufeffTags,URL,expiry\n"+
+			"docs;team,https://docs.example.com,2026-06-01\n"+
+			",https://www.example.org\n"), 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []url_model.BulkItem{
+			{URL: "https://docs.example.com", Tags: []string{"docs", "team"}, Expiry: "2026-06-01"},
+			{URL: "https://www.example.org"},
+		}, items)
+	})
+
+	t.Run("Should reject invalid files", func(t *testing.T) {
+		_, err := ParseBulkCSV(strings.NewReader(""), 10)
+		assert.Error(t, err)
+		_, err = ParseBulkCSV(strings.NewReader("alias\ndocs\n"), 10)
+		assert.ErrorContains(t, err, "url column")
+		_, err = ParseBulkCSV(strings.NewReader("url\n\"unterminated\n"), 10)
+		assert.ErrorContains(t, err, "invalid CSV")
+	})
+
+	t.Run("Should stop after the maximum number of rows", func(t *testing.T) {
+		_, err := ParseBulkCSV(strings.NewReader("url\na\nb\nc\n"), 2)
+		assert.ErrorIs(t, err, url_model.ErrTooManyURLs)
+	})
+}
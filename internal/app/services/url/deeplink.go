@@ -0,0 +1,114 @@
+package url_service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// maxAppURILength is the longest app URI of a deep link.
+const maxAppURILength = 2048
+
+// unsafeAppSchemes are the schemes app URIs may not use, as browsers run or read them in the page.
+var unsafeAppSchemes = []string{"javascript", "data", "vbscript", "file", "blob"}
+
+// GetDeepLink returns the apps the URL opens. The user needs view access to the URL.
+func (s *Service) GetDeepLink(userID uint, shortURL string) (*url_model.DeepLink, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	deepLink, err := s.Repository.GetDeepLink(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if deepLink == nil {
+		deepLink = &url_model.DeepLink{}
+	}
+	return deepLink, nil
+}
+
+// SetDeepLink validates and replaces the apps the URL opens, returning them trimmed. Invalid deep links fail
+// with an error wrapping url_model.ErrInvalidDeepLink. The user needs edit access to the URL.
+func (s *Service) SetDeepLink(userID uint, shortURL string, deepLink url_model.DeepLink) (*url_model.DeepLink, error) {
+	var err error
+	if deepLink.IOS, err = s.validateAppTarget("ios", deepLink.IOS); err != nil {
+		return nil, err
+	}
+	if deepLink.Android, err = s.validateAppTarget("android", deepLink.Android); err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	// Links opening no app have no row
+	stored := &deepLink
+	if deepLink == (url_model.DeepLink{}) {
+		stored = nil
+	}
+	if err := s.Repository.SetDeepLink(shortURL, stored); err != nil {
+		return nil, err
+	}
+	return &deepLink, nil
+}
+
+// AppTarget returns the app the URL opens on the device, with the destination as its fallback when it
+// has none; nil when the URL has no app URI for the device.
+func (s *Service) AppTarget(shortURL, device, destination string) (*url_model.AppTarget, error) {
+	if device != url_model.DeviceIOS && device != url_model.DeviceAndroid {
+		return nil, nil
+	}
+	deepLink, err := s.Repository.GetDeepLink(shortURL)
+	if err != nil || deepLink == nil {
+		return nil, err
+	}
+
+	target := deepLink.IOS
+	if device == url_model.DeviceAndroid {
+		target = deepLink.Android
+	}
+	if target.URI == "" {
+		return nil, nil
+	}
+	if target.Fallback == "" {
+		target.Fallback = destination
+	}
+	return &target, nil
+}
+
+// validateAppTarget checks the app URI and fallback of a platform, returning them trimmed.
+func (s *Service) validateAppTarget(platform string, target url_model.AppTarget) (url_model.AppTarget, error) {
+	target.URI = strings.TrimSpace(target.URI)
+	target.Fallback = strings.TrimSpace(target.Fallback)
+	if target.URI == "" {
+		if target.Fallback != "" {
+			return target, fmt.Errorf("%w: %s: a fallback needs an app URI", url_model.ErrInvalidDeepLink, platform)
+		}
+		return target, nil
+	}
+
+	u, err := url.Parse(target.URI)
+	if err != nil || u.Scheme == "" || len(target.URI) > maxAppURILength {
+		return target, fmt.Errorf("%w: %s: app URI must be a URI with a scheme of at most %d characters", url_model.ErrInvalidDeepLink, platform, maxAppURILength)
+	}
+	for _, scheme := range unsafeAppSchemes {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return target, fmt.Errorf("%w: %s: scheme %q is not allowed", url_model.ErrInvalidDeepLink, platform, u.Scheme)
+		}
+	}
+
+	if target.Fallback != "" {
+		if err := s.Safety.Check(target.Fallback); err != nil {
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				return target, fmt.Errorf("%w: %s: fallback: %s", url_model.ErrInvalidDeepLink, platform, rejection.Detail)
+			}
+			return target, err
+		}
+	}
+	return target, nil
+}
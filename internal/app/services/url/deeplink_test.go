@@ -0,0 +1,93 @@
+package url_service
+
+import (
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeepLink(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com/product/1", "product", &owner)
+
+	deepLink := url_model.DeepLink{
+		IOS:     url_model.AppTarget{URI: " shop://product/1 ", Fallback: "https://apps.apple.com/app/id1"},
+		Android: url_model.AppTarget{URI: "intent://product/1#Intent;scheme=shop;package=com.example.shop;end"},
+	}
+
+	t.Run("Should save trimmed app URIs", func(t *testing.T) {
+		saved, err := urlService.SetDeepLink(owner, "product", deepLink)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "shop://product/1", saved.IOS.URI)
+
+		found, err := urlService.GetDeepLink(owner, "product")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+	})
+
+	t.Run("Should open the app of the device", func(t *testing.T) {
+		app, err := urlService.AppTarget("product", url_model.DeviceIOS, "https://www.example.com/product/1")
+		assert.NoError(t, err)
+		assert.Equal(t, &url_model.AppTarget{URI: "shop://product/1", Fallback: "https://apps.apple.com/app/id1"}, app)
+
+		// Without a store page the destination is the fallback
+		app, err = urlService.AppTarget("product", url_model.DeviceAndroid, "https://www.example.com/product/1")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/product/1", app.Fallback)
+
+		app, err = urlService.AppTarget("product", url_model.DeviceWindows, "https://www.example.com/product/1")
+		assert.NoError(t, err)
+		assert.Nil(t, app)
+	})
+
+	t.Run("Should dry run the app", func(t *testing.T) {
+		match, err := urlService.DryRun(owner, "product", NewVisitor(iPhoneAgent, "", "", time.Now()), "", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "shop://product/1", match.App.URI)
+	})
+
+	t.Run("Should only let editors set deep links", func(t *testing.T) {
+		_, err := urlService.SetDeepLink(2, "product", deepLink)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetDeepLink(2, "product")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove the deep link", func(t *testing.T) {
+		_, err := urlService.SetDeepLink(owner, "product", url_model.DeepLink{})
+		assert.NoError(t, err)
+		assert.NotContains(t, repository.DeepLinks, "product")
+
+		app, err := urlService.AppTarget("product", url_model.DeviceIOS, "https://www.example.com/product/1")
+		assert.NoError(t, err)
+		assert.Nil(t, app)
+	})
+}
+
+func TestDeepLink_Validation(t *testing.T) {
+	urlService := NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+
+	tests := []struct {
+		name   string
+		target url_model.AppTarget
+	}{
+		{"Missing Scheme", url_model.AppTarget{URI: "product/1"}},
+		{"Script Scheme", url_model.AppTarget{URI: "JavaScript:alert(1)"}},
+		{"Fallback Without App", url_model.AppTarget{Fallback: "https://apps.apple.com/app/id1"}},
+		{"Unsafe Fallback", url_model.AppTarget{URI: "shop://product/1", Fallback: "ftp://www.example.com"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetDeepLink(1, "product", url_model.DeepLink{Android: tt.target})
+			assert.ErrorIs(t, err, url_model.ErrInvalidDeepLink)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package url_service
+
+import (
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// Longest title and notes of a URL, in characters.
+const (
+	maxTitleLength = 255
+	maxNotesLength = 2000
+)
+
+// SetDetails sets the title and notes of the URL; empty values clear them. The user needs edit access to the URL.
+func (s *Service) SetDetails(userID uint, shortURL string, details url_model.DetailsRequest) error {
+	if utf8.RuneCountInString(details.Title) > maxTitleLength {
+		return url_model.ErrInvalidTitle
+	}
+	if utf8.RuneCountInString(details.Notes) > maxNotesLength {
+		return url_model.ErrInvalidNotes
+	}
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return err
+	}
+
+	return s.Repository.SetDetails(shortURL, details.Title, details.Notes)
+}
+
+// GetMetadata returns the page metadata fetched from the destination of the URL.
+// The user needs view access to the URL.
+func (s *Service) GetMetadata(userID uint, shortURL string) (*url_model.Metadata, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetMetadata(shortURL)
+}
@@ -0,0 +1,53 @@
+package url_service
+
+import (
+	"strings"
+	"testing"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkDetails(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://docs.example.com", "docs", &owner)
+
+	t.Run("Should set title and notes", func(t *testing.T) {
+		details := url_model.DetailsRequest{Title: "Docs", Notes: "Linked from the newsletter"}
+		assert.NoError(t, urlService.SetDetails(owner, "docs", details))
+
+		u, _ := repository.GetURL("docs")
+		assert.Equal(t, "Docs", u.Title)
+		assert.Equal(t, "Linked from the newsletter", u.Notes)
+	})
+
+	t.Run("Should only let editors set details", func(t *testing.T) {
+		assert.ErrorIs(t, urlService.SetDetails(2, "docs", url_model.DetailsRequest{}), url_model.ErrForbidden)
+		assert.ErrorIs(t, urlService.SetDetails(owner, "missing", url_model.DetailsRequest{}), url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should reject long details", func(t *testing.T) {
+		err := urlService.SetDetails(owner, "docs", url_model.DetailsRequest{Title: strings.Repeat("a", 256)})
+		assert.ErrorIs(t, err, url_model.ErrInvalidTitle)
+
+		err = urlService.SetDetails(owner, "docs", url_model.DetailsRequest{Notes: strings.Repeat("a", 2001)})
+		assert.ErrorIs(t, err, url_model.ErrInvalidNotes)
+	})
+
+	t.Run("Should return fetched metadata to viewers", func(t *testing.T) {
+		_, err := urlService.GetMetadata(owner, "docs")
+		assert.ErrorIs(t, err, url_model.ErrMetadataNotFound)
+
+		repository.Metadata["docs"] = &url_model.Metadata{Title: "Documentation"}
+		metadata, err := urlService.GetMetadata(owner, "docs")
+		assert.NoError(t, err)
+		assert.Equal(t, "Documentation", metadata.Title)
+
+		_, err = urlService.GetMetadata(2, "docs")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+}
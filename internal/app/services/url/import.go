@@ -0,0 +1,188 @@
+package url_service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/utils"
+)
+
+// importTimeLayouts are the creation time formats found in exports, tried in order.
+var importTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// ImportURLs imports links exported from another shortener as personal links of the user.
+// Source short codes are kept as aliases when free, otherwise the link gets a new code and the
+// conflict is reported. Links imported before, or earlier in the same file, are skipped so
+// imports can be re-run. A dry run validates and reports without creating anything.
+func (s *Service) ImportURLs(userID uint, records []url_model.ImportRecord, dryRun bool) (*url_model.ImportReport, error) {
+	sources, err := s.Repository.GetImportSources(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &url_model.ImportReport{DryRun: dryRun, Total: len(records), Results: make([]url_model.ImportResult, len(records))}
+	var valid []url_model.NewURL
+	var validRows []int
+	aliases := make(map[string]bool)
+
+	for i, record := range records {
+		result := &report.Results[i]
+		result.Row = i + 1
+		result.SourceCode = record.ShortCode
+		result.URL = record.OriginalURL
+
+		source := importSource(record)
+		if shortCode, ok := sources[source]; ok {
+			result.Status = url_model.ImportSkipped
+			result.ShortenedURL = shortCode
+			continue
+		}
+
+		newURL, conflict, err := s.importURL(record, aliases)
+		if err != nil {
+			result.Status = url_model.ImportFailed
+			result.Error = err.Error()
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				result.Reason = rejection.Reason
+			}
+			continue
+		}
+		newURL.UserID = userID
+		newURL.ImportSource = source
+		sources[source] = newURL.ShortCode
+
+		result.Status = url_model.ImportCreated
+		result.ShortenedURL = newURL.ShortCode
+		result.Conflict = conflict
+		valid = append(valid, *newURL)
+		validRows = append(validRows, i)
+	}
+
+	if !dryRun && len(valid) > 0 {
+		if err := s.Repository.CreateURLs(valid); err != nil {
+			// The transaction was rolled back, so none of the valid rows were created
+			for _, i := range validRows {
+				report.Results[i].Status = url_model.ImportFailed
+				report.Results[i].ShortenedURL = ""
+				report.Results[i].Error = err.Error()
+			}
+		}
+	}
+
+	for _, result := range report.Results {
+		switch result.Status {
+		case url_model.ImportCreated:
+			report.Created++
+		case url_model.ImportSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+		if result.Conflict != "" {
+			report.Conflicts++
+		}
+	}
+	return report, nil
+}
+
+// importURL validates one imported record. It returns why the source code could not be kept, if so.
+func (s *Service) importURL(record url_model.ImportRecord, aliases map[string]bool) (*url_model.NewURL, string, error) {
+	originalURL := strings.TrimSpace(record.OriginalURL)
+	if originalURL == "" {
+		return nil, "", &url_model.Rejection{Reason: url_model.ReasonInvalidURL, Detail: "URL is required"}
+	}
+	if err := s.Safety.Check(originalURL); err != nil {
+		return nil, "", err
+	}
+
+	createdAt, err := parseImportTime(record.CreatedAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	clicks := 0
+	if value := strings.TrimSpace(record.Clicks); value != "" {
+		if clicks, err = strconv.Atoi(value); err != nil || clicks < 0 {
+			return nil, "", url_model.ErrInvalidClicks
+		}
+	}
+
+	tags, err := NormalizeTags(record.Tags)
+	if err != nil {
+		return nil, "", err
+	}
+
+	shortCode, conflict, err := s.importShortCode(strings.TrimSpace(record.ShortCode), aliases)
+	if err != nil {
+		return nil, "", err
+	}
+	aliases[shortCode] = true
+
+	return &url_model.NewURL{
+		OriginalURL:    originalURL,
+		ShortCode:      shortCode,
+		Tags:           tags,
+		CreatedAt:      createdAt,
+		ImportedClicks: clicks,
+	}, conflict, nil
+}
+
+// importShortCode keeps the source code when it is a valid, free alias and generates a new code otherwise.
+func (s *Service) importShortCode(code string, aliases map[string]bool) (string, string, error) {
+	if code == "" {
+		return utils.GenerateShortCode(8), "", nil
+	}
+	if aliases[code] {
+		return utils.GenerateShortCode(8), url_model.ErrDuplicateAlias.Error(), nil
+	}
+
+	shortCode, err := s.shortCode(code)
+	if errors.Is(err, url_model.ErrInvalidAlias) || errors.Is(err, url_model.ErrShortCodeAlreadyExists) {
+		return utils.GenerateShortCode(8), err.Error(), nil
+	}
+	return shortCode, "", err
+}
+
+// importSource identifies a record in its source by its short code and URL, so that a row reusing the code
+// of a link imported before for another destination is still imported.
+func importSource(record url_model.ImportRecord) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(record.ShortCode) + "|" + strings.TrimSpace(record.OriginalURL)))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// parseImportTime parses a creation time of an export, read as UTC when it has no zone.
+// Numbers are Unix timestamps in seconds, or milliseconds when too large for seconds.
+func parseImportTime(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		t := time.Unix(unix, 0)
+		if unix > 1e11 {
+			t = time.UnixMilli(unix)
+		}
+		t = t.UTC()
+		return &t, nil
+	}
+
+	for _, layout := range importTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, url_model.ErrInvalidCreatedAt
+}
@@ -0,0 +1,263 @@
+package url_service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	url_model "url-shortener/internal/app/models/url"
+)
+
+// Import formats.
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+)
+
+// importFields maps the column names of common exports, lower-cased without punctuation,
+// to the fields of an import record.
+var importFields = map[string]string{
+	// Destination URL
+	"url":            "url",
+	"longurl":        "url",
+	"originalurl":    "url",
+	"destination":    "url",
+	"destinationurl": "url",
+	"target":         "url",
+	// Short code
+	"shortcode": "code",
+	"code":      "code",
+	"keyword":   "code",
+	"slashtag":  "code",
+	"address":   "code",
+	"alias":     "code",
+	"backhalf":  "code",
+	// Short link, read when there is no short code column
+	"shorturl":     "link",
+	"shortlink":    "link",
+	"shortenedurl": "link",
+	"link":         "link",
+	"bitlylink":    "link",
+	// Creation time
+	"created":      "created",
+	"createdat":    "created",
+	"datecreated":  "created",
+	"creationdate": "created",
+	"timestamp":    "created",
+	// Click total
+	"clicks":      "clicks",
+	"totalclicks": "clicks",
+	"clickcount":  "clicks",
+	"visits":      "clicks",
+	"visitcount":  "clicks",
+	"visitscount": "clicks",
+	"tags":        "tags",
+}
+
+// ParseImport reads the links of a CSV or JSON export, detecting the format from the content when
+// format is empty. Columns are recognised by the names used by common shorteners, such as
+// long_url, keyword or visitsCount. Reading stops with url_model.ErrTooManyURLs beyond max links.
+func ParseImport(r io.Reader, format string, max int) ([]url_model.ImportRecord, error) {
+	reader := bufio.NewReader(r)
+	if format == "" {
+		format = detectImportFormat(reader)
+	}
+
+	switch strings.ToLower(format) {
+	case ImportFormatCSV:
+		return parseImportCSV(reader, max)
+	case ImportFormatJSON:
+		return parseImportJSON(reader, max)
+	default:
+		return nil, url_model.ErrInvalidImportFormat
+	}
+}
+
+// detectImportFormat reads JSON when the content starts with an array or object, CSV otherwise.
+func detectImportFormat(reader *bufio.Reader) string {
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return ImportFormatCSV
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = reader.ReadByte()
+		case '[', '{':
+			return ImportFormatJSON
+		default:
+			return ImportFormatCSV
+		}
+	}
+}
+
+func parseImportCSV(r io.Reader, max int) ([]url_model.ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV file is empty")
+		}
+		return nil, err
+	}
+	var records []url_model.ImportRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		if len(records) == max {
+			return nil, url_model.ErrTooManyURLs
+		}
+
+		fields := make(map[string]string)
+		for i, name := range header {
+			if i < len(row) {
+				fields[name] = row[i]
+			}
+		}
+		records = append(records, importRecord(fields))
+	}
+
+	return checkImportRecords(records)
+}
+
+func parseImportJSON(r io.Reader, max int) ([]url_model.ImportRecord, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	items, ok := importItems(document)
+	if !ok {
+		return nil, errors.New("JSON must be an array of links or an object holding one")
+	}
+	if len(items) > max {
+		return nil, url_model.ErrTooManyURLs
+	}
+
+	records := make([]url_model.ImportRecord, 0, len(items))
+	for _, item := range items {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("JSON links must be objects")
+		}
+		fields := make(map[string]string)
+		for key, value := range object {
+			fields[key] = jsonString(value)
+		}
+		records = append(records, importRecord(fields))
+	}
+
+	return checkImportRecords(records)
+}
+
+// importItems returns the array of the document: the document itself, or the first array
+// property of an object such as {"links": [...]}, by key order.
+func importItems(document interface{}) ([]interface{}, bool) {
+	switch value := document.(type) {
+	case []interface{}:
+		return value, true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if items, ok := value[key].([]interface{}); ok {
+				return items, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// jsonString formats a JSON value as an export field. Arrays, such as tags, are joined with
+// semicolons, taking the name of object elements.
+func jsonString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		var parts []string
+		for _, element := range v {
+			if object, ok := element.(map[string]interface{}); ok {
+				element = object["name"]
+			}
+			if s, ok := element.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, ";")
+	}
+	return ""
+}
+
+// importRecord maps the named fields of an exported link to an import record.
+func importRecord(fields map[string]string) url_model.ImportRecord {
+	values := make(map[string]string)
+	for name, value := range fields {
+		if field, ok := importFields[normalizeColumn(name)]; ok && values[field] == "" {
+			values[field] = strings.TrimSpace(value)
+		}
+	}
+
+	record := url_model.ImportRecord{
+		ShortCode:   values["code"],
+		OriginalURL: values["url"],
+		CreatedAt:   values["created"],
+		Clicks:      values["clicks"],
+	}
+	if record.ShortCode == "" && values["link"] != "" {
+		// Short links such as "bit.ly/abc" end with their code
+		link := strings.TrimRight(values["link"], "/")
+		record.ShortCode = link[strings.LastIndex(link, "/")+1:]
+	}
+	if values["tags"] != "" {
+		record.Tags = strings.FieldsFunc(values["tags"], func(r rune) bool { return r == ';' || r == ',' || r == '|' })
+	}
+	return record
+}
+
+// checkImportRecords rejects exports without any recognised URL, usually a format mismatch.
+func checkImportRecords(records []url_model.ImportRecord) ([]url_model.ImportRecord, error) {
+	for _, record := range records {
+		if record.OriginalURL != "" {
+			return records, nil
+		}
+	}
+	if len(records) == 0 {
+		return records, nil
+	}
+	return nil, errors.New("no URL column found; expected a column such as url, long_url or destination")
+}
+
+// normalizeColumn lower-cases the column name and drops everything but letters and digits,
+// including the byte order mark spreadsheet exports may start with.
+func normalizeColumn(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package url_service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportURLs(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = urlService.ShortenURLWithAlias("https://www.example.com", "taken", &owner)
+
+	records := []url_model.ImportRecord{
+		{ShortCode: "docs", OriginalURL: "https://docs.example.com", CreatedAt: "2020-05-01 10:00:00", Clicks: "42", Tags: []string{"docs"}},
+		{ShortCode: "taken", OriginalURL: "https://www.example.org"},
+		{ShortCode: "no", OriginalURL: "https://www.example.net"},
+		{OriginalURL: "https://blog.example.com", CreatedAt: "1588327200"},
+		{ShortCode: "docs", OriginalURL: "https://docs.example.com"},
+		{ShortCode: "unsafe", OriginalURL: "ftp://files.example.com"},
+		{ShortCode: "when", OriginalURL: "https://www.example.com", CreatedAt: "yesterday"},
+		{ShortCode: "count", OriginalURL: "https://www.example.com", Clicks: "-1"},
+	}
+
+	t.Run("Should report without creating in dry run", func(t *testing.T) {
+		report, err := urlService.ImportURLs(owner, records, true)
+
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 4, report.Created)
+		assert.Equal(t, 1, report.Skipped)
+		assert.Equal(t, 3, report.Failed)
+		assert.Equal(t, 2, report.Conflicts)
+		assert.Len(t, repository.Urls, 1)
+
+		assert.Equal(t, "docs", report.Results[0].ShortenedURL)
+		assert.Equal(t, url_model.ErrShortCodeAlreadyExists.Error(), report.Results[1].Conflict)
+		assert.NotEqual(t, "taken", report.Results[1].ShortenedURL)
+		assert.Equal(t, url_model.ErrInvalidAlias.Error(), report.Results[2].Conflict)
+		assert.Equal(t, url_model.ImportSkipped, report.Results[4].Status)
+		assert.Equal(t, "docs", report.Results[4].ShortenedURL)
+		assert.Equal(t, url_model.ReasonScheme, report.Results[5].Reason)
+		assert.Equal(t, url_model.ErrInvalidCreatedAt.Error(), report.Results[6].Error)
+		assert.Equal(t, url_model.ErrInvalidClicks.Error(), report.Results[7].Error)
+	})
+
+	t.Run("Should keep codes, timestamps and clicks", func(t *testing.T) {
+		report, err := urlService.ImportURLs(owner, records, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 4, report.Created)
+		assert.Len(t, repository.Urls, 5)
+
+		imported := repository.Urls[2]
+		assert.Equal(t, "docs", imported.ShortenedURL)
+		assert.Equal(t, time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), imported.CreatedAt)
+		assert.Equal(t, 42, imported.ImportedClicks)
+		assert.Equal(t, []string{"docs"}, repository.Tags["docs"])
+		assert.Equal(t, time.Unix(1588327200, 0).UTC(), repository.Urls[5].CreatedAt)
+	})
+
+	t.Run("Should skip links imported before", func(t *testing.T) {
+		report, err := urlService.ImportURLs(owner, records, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Created)
+		assert.Equal(t, 5, report.Skipped)
+		assert.Equal(t, 0, report.Conflicts)
+		assert.Len(t, repository.Urls, 5)
+	})
+
+	t.Run("Should import rows reusing a code for another destination", func(t *testing.T) {
+		report, err := urlService.ImportURLs(owner, []url_model.ImportRecord{
+			{ShortCode: "docs", OriginalURL: "https://docs.example.org"},
+			{ShortCode: "blog", OriginalURL: "https://blog.example.org"},
+			{ShortCode: "blog", OriginalURL: "https://blog.example.net"},
+		}, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Created)
+		assert.Equal(t, 0, report.Skipped)
+		assert.Equal(t, url_model.ErrShortCodeAlreadyExists.Error(), report.Results[0].Conflict)
+		assert.Equal(t, "blog", report.Results[1].ShortenedURL)
+		assert.Equal(t, url_model.ErrDuplicateAlias.Error(), report.Results[2].Conflict)
+		assert.Len(t, repository.Urls, 8)
+	})
+
+	t.Run("Should fail all rows when the transaction fails", func(t *testing.T) {
+		report, err := urlService.ImportURLs(2, []url_model.ImportRecord{
+			{OriginalURL: "https://www.example.org"},
+			{OriginalURL: "http://error.com"},
+		}, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Failed)
+		assert.Empty(t, report.Results[0].ShortenedURL)
+		assert.Empty(t, repository.ImportSources[2])
+	})
+}
+
+func TestParseImport(t *testing.T) {
+	t.Run("Should read CSV exports", func(t *testing.T) {
+		records, err := ParseImport(strings.NewReader("\ufeffKeyword,URL,Title,Timestamp,Clicks\n"+
+			"docs,https://docs.example.com,Docs,2020-05-01 10:00:00,42\n"), "", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []url_model.ImportRecord{
+			{ShortCode: "docs", OriginalURL: "https://docs.example.com", CreatedAt: "2020-05-01 10:00:00", Clicks: "42"},
+		}, records)
+	})
+
+	t.Run("Should take the code from short links", func(t *testing.T) {
+		records, err := ParseImport(strings.NewReader("Bitly Link,Long URL,Tags\n"+
+			"https://bit.ly/abc123,https://www.example.com,docs|team\n"), ImportFormatCSV, 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", records[0].ShortCode)
+		assert.Equal(t, []string{"docs", "team"}, records[0].Tags)
+	})
+
+	t.Run("Should read JSON exports", func(t *testing.T) {
+		records, err := ParseImport(strings.NewReader(` {"count": 1, "links": [
+			{"slashtag": "docs", "destination": "https://docs.example.com", "createdAt": "2020-05-01T10:00:00Z",
+			 "clicks": 42, "tags": [{"name": "docs"}, "team"]}
+		]}`), "", 10)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []url_model.ImportRecord{
+			{ShortCode: "docs", OriginalURL: "https://docs.example.com", CreatedAt: "2020-05-01T10:00:00Z", Clicks: "42", Tags: []string{"docs", "team"}},
+		}, records)
+
+		records, err = ParseImport(strings.NewReader(`[{"shortCode": "a1", "longUrl": "https://www.example.com", "visitsCount": 3}]`), ImportFormatJSON, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, "3", records[0].Clicks)
+	})
+
+	t.Run("Should reject invalid exports", func(t *testing.T) {
+		_, err := ParseImport(strings.NewReader("url\n"), "xml", 10)
+		assert.ErrorIs(t, err, url_model.ErrInvalidImportFormat)
+		_, err = ParseImport(strings.NewReader("name,value\na,b\n"), "", 10)
+		assert.ErrorContains(t, err, "no URL column")
+		_, err = ParseImport(strings.NewReader(`{"count": 1}`), "", 10)
+		assert.Error(t, err)
+		_, err = ParseImport(strings.NewReader(`[1, 2]`), "", 10)
+		assert.Error(t, err)
+		_, err = ParseImport(strings.NewReader(`[{"url": "a"}, {"url": "b"}]`), "", 1)
+		assert.ErrorIs(t, err, url_model.ErrTooManyURLs)
+		_, err = ParseImport(strings.NewReader("url\na\nb\n"), "", 1)
+		assert.ErrorIs(t, err, url_model.ErrTooManyURLs)
+	})
+}
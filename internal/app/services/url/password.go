@@ -0,0 +1,111 @@
+package url_service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultAccessTTL is how long an unlocked password-protected link stays accessible.
+const DefaultAccessTTL = 15 * time.Minute
+
+// maxLinkPasswordLength is the number of bytes bcrypt takes into account.
+const maxLinkPasswordLength = 72
+
+// randomKey returns a random signing key, used until the service is given a shared one.
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic("failed to generate signing key: " + err.Error())
+	}
+	return key
+}
+
+// SetPassword protects the URL with a password, or removes the protection when it is empty.
+// The user needs edit access to the URL.
+func (s *Service) SetPassword(userID uint, shortURL, password string) error {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return err
+	}
+
+	if password == "" {
+		return s.Repository.SetPasswordHash(shortURL, nil)
+	}
+	if len(password) > maxLinkPasswordLength {
+		return url_model.ErrInvalidLinkPassword
+	}
+
+	// Hash the password like user passwords
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	hash := string(hashed)
+	return s.Repository.SetPasswordHash(shortURL, &hash)
+}
+
+// IsProtected reports whether the URL requires a password.
+func (s *Service) IsProtected(shortURL string) (bool, error) {
+	hash, err := s.Repository.GetPasswordHash(shortURL)
+	if err != nil {
+		return false, err
+	}
+	return hash != "", nil
+}
+
+// Unlock checks the password of the URL and returns an access token valid for AccessTTL.
+func (s *Service) Unlock(shortURL, password string) (string, error) {
+	hash, err := s.Repository.GetPasswordHash(shortURL)
+	if err != nil {
+		return "", err
+	}
+	if hash == "" || bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return "", url_model.ErrIncorrectLinkPassword
+	}
+
+	expiresAt := strconv.FormatInt(s.now().Add(s.AccessTTL).Unix(), 10)
+	return expiresAt + "." + base64.RawURLEncoding.EncodeToString(s.accessMAC(shortURL, expiresAt, hash)), nil
+}
+
+// CheckAccess reports whether the URL may be followed with the given access token.
+// URLs without a password are always accessible.
+func (s *Service) CheckAccess(shortURL, token string) (bool, error) {
+	hash, err := s.Repository.GetPasswordHash(shortURL)
+	if err != nil {
+		return false, err
+	}
+	if hash == "" {
+		return true, nil
+	}
+
+	expiresAt, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false, nil
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, s.accessMAC(shortURL, expiresAt, hash)) {
+		return false, nil
+	}
+	unix, err := strconv.ParseInt(expiresAt, 10, 64)
+	if err != nil || s.now().Unix() > unix {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// accessMAC signs the access token of a URL. The password hash is part of the signature so that
+// changing the password revokes the tokens issued before.
+func (s *Service) accessMAC(shortURL, expiresAt, hash string) []byte {
+	mac := hmac.New(sha256.New, s.AccessKey)
+	mac.Write([]byte(shortURL + "|" + expiresAt + "|" + hash))
+	return mac.Sum(nil)
+}
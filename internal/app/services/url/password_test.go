@@ -0,0 +1,86 @@
+package url_service
+
+import (
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinkPassword(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+	now := time.Now()
+	urlService.now = func() time.Time { return now }
+
+	owner := uint(1)
+	_, _ = urlService.ShortenURL("https://docs.example.com", &owner)
+	shortURL := repository.Urls[1].ShortenedURL
+
+	t.Run("Should not protect URLs by default", func(t *testing.T) {
+		protected, err := urlService.IsProtected(shortURL)
+		assert.NoError(t, err)
+		assert.False(t, protected)
+
+		allowed, err := urlService.CheckAccess(shortURL, "")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("Should only let editors set a password", func(t *testing.T) {
+		assert.ErrorIs(t, urlService.SetPassword(2, shortURL, "secret"), url_model.ErrForbidden)
+		assert.ErrorIs(t, urlService.SetPassword(owner, "missing", "secret"), url_model.ErrURLNotFound)
+		assert.ErrorIs(t, urlService.SetPassword(owner, shortURL, string(make([]byte, 73))), url_model.ErrInvalidLinkPassword)
+	})
+
+	t.Run("Should store a bcrypt hash", func(t *testing.T) {
+		assert.NoError(t, urlService.SetPassword(owner, shortURL, "secret"))
+
+		assert.Contains(t, repository.PasswordHashes[shortURL], "$2a$")
+		protected, _ := urlService.IsProtected(shortURL)
+		assert.True(t, protected)
+		allowed, _ := urlService.CheckAccess(shortURL, "")
+		assert.False(t, allowed)
+	})
+
+	t.Run("Should reject incorrect password", func(t *testing.T) {
+		_, err := urlService.Unlock(shortURL, "guess")
+		assert.ErrorIs(t, err, url_model.ErrIncorrectLinkPassword)
+	})
+
+	t.Run("Should grant access until the token expires", func(t *testing.T) {
+		token, err := urlService.Unlock(shortURL, "secret")
+		assert.NoError(t, err)
+
+		allowed, _ := urlService.CheckAccess(shortURL, token)
+		assert.True(t, allowed)
+		allowed, _ = urlService.CheckAccess(shortURL, token+"x")
+		assert.False(t, allowed)
+		// Extending the expiry breaks the signature
+		allowed, _ = urlService.CheckAccess(shortURL, "9"+token)
+		assert.False(t, allowed)
+
+		now = now.Add(DefaultAccessTTL + time.Second)
+		allowed, _ = urlService.CheckAccess(shortURL, token)
+		assert.False(t, allowed)
+	})
+
+	t.Run("Should revoke tokens when the password changes", func(t *testing.T) {
+		token, _ := urlService.Unlock(shortURL, "secret")
+		assert.NoError(t, urlService.SetPassword(owner, shortURL, "other"))
+
+		allowed, _ := urlService.CheckAccess(shortURL, token)
+		assert.False(t, allowed)
+	})
+
+	t.Run("Should remove the password", func(t *testing.T) {
+		assert.NoError(t, urlService.SetPassword(owner, shortURL, ""))
+
+		protected, _ := urlService.IsProtected(shortURL)
+		assert.False(t, protected)
+		_, err := urlService.Unlock(shortURL, "")
+		assert.ErrorIs(t, err, url_model.ErrIncorrectLinkPassword)
+	})
+}
@@ -0,0 +1,137 @@
+package url_service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// Bounds of the preview of a URL.
+const (
+	maxPreviewDescriptionLength = 1000
+	maxInterstitialDelay        = 30
+)
+
+// crawlerAgents are parts of the User-Agent headers of the crawlers unfurling links in chat tools and
+// social networks, in lower case.
+var crawlerAgents = []string{
+	"facebookexternalhit",
+	"facebot",
+	"twitterbot",
+	"slackbot",
+	"discordbot",
+	"telegrambot",
+	"whatsapp",
+	"linkedinbot",
+	"pinterest",
+	"skypeuripreview",
+	"redditbot",
+	"mastodon",
+	"embedly",
+	"vkshare",
+}
+
+// GetPreview returns the preview of the URL. The user needs view access to the URL.
+func (s *Service) GetPreview(userID uint, shortURL string) (*url_model.Preview, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	preview, err := s.Repository.GetPreview(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if preview == nil {
+		preview = &url_model.Preview{}
+	}
+	return preview, nil
+}
+
+// SetPreview validates and replaces the preview of the URL, returning it trimmed; an empty preview removes it.
+// Invalid previews fail with an error wrapping url_model.ErrInvalidPreview. The user needs edit access to the URL.
+func (s *Service) SetPreview(userID uint, shortURL string, preview url_model.Preview) (*url_model.Preview, error) {
+	preview, err := s.validatePreview(preview)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	stored := &preview
+	if preview == (url_model.Preview{}) {
+		stored = nil
+	}
+	if err := s.Repository.SetPreview(shortURL, stored); err != nil {
+		return nil, err
+	}
+	return &preview, nil
+}
+
+// LinkPreview returns the preview served for the URL, with the title of the URL and the description of its
+// destination page filling empty fields; nil when the URL has no preview.
+func (s *Service) LinkPreview(shortURL string) (*url_model.Preview, error) {
+	preview, err := s.Repository.GetPreview(shortURL)
+	if err != nil || preview == nil {
+		return nil, err
+	}
+
+	if preview.Title == "" {
+		u, err := s.Repository.GetURL(shortURL)
+		if err != nil {
+			return nil, err
+		}
+		preview.Title = u.Title
+	}
+	if preview.Description == "" {
+		metadata, err := s.Repository.GetMetadata(shortURL)
+		if err != nil && !errors.Is(err, url_model.ErrMetadataNotFound) {
+			return nil, err
+		}
+		if metadata != nil {
+			preview.Description = metadata.Description
+		}
+	}
+	return preview, nil
+}
+
+// IsCrawler reports whether the User-Agent header is one of a crawler unfurling links.
+func IsCrawler(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, crawler := range crawlerAgents {
+		if strings.Contains(ua, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePreview checks the preview and returns it with trimmed texts.
+func (s *Service) validatePreview(preview url_model.Preview) (url_model.Preview, error) {
+	preview.Title = strings.TrimSpace(preview.Title)
+	preview.Description = strings.TrimSpace(preview.Description)
+	preview.Image = strings.TrimSpace(preview.Image)
+
+	if utf8.RuneCountInString(preview.Title) > maxTitleLength {
+		return preview, fmt.Errorf("%w: title must be at most %d characters", url_model.ErrInvalidPreview, maxTitleLength)
+	}
+	if utf8.RuneCountInString(preview.Description) > maxPreviewDescriptionLength {
+		return preview, fmt.Errorf("%w: description must be at most %d characters", url_model.ErrInvalidPreview, maxPreviewDescriptionLength)
+	}
+	if preview.Delay < 0 || preview.Delay > maxInterstitialDelay {
+		return preview, fmt.Errorf("%w: delay must be 0 to %d seconds", url_model.ErrInvalidPreview, maxInterstitialDelay)
+	}
+	if preview.Image != "" {
+		if err := s.Safety.Check(preview.Image); err != nil {
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				return preview, fmt.Errorf("%w: image: %s", url_model.ErrInvalidPreview, rejection.Detail)
+			}
+			return preview, err
+		}
+	}
+	return preview, nil
+}
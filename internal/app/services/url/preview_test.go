@@ -0,0 +1,84 @@
+package url_service
+
+import (
+	"strings"
+	"testing"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreview(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com/launch", "launch", &owner)
+	_ = repository.SetDetails("launch", "Launch", "")
+	repository.Metadata["launch"] = &url_model.Metadata{Description: "Our new product"}
+
+	t.Run("Should save a trimmed preview", func(t *testing.T) {
+		saved, err := urlService.SetPreview(owner, "launch", url_model.Preview{Image: " https://www.example.com/card.png ", Interstitial: true, Delay: 5})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/card.png", saved.Image)
+
+		found, err := urlService.GetPreview(owner, "launch")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+	})
+
+	t.Run("Should fill empty fields from the URL", func(t *testing.T) {
+		preview, err := urlService.LinkPreview("launch")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Launch", preview.Title)
+		assert.Equal(t, "Our new product", preview.Description)
+	})
+
+	t.Run("Should only let editors set previews", func(t *testing.T) {
+		_, err := urlService.SetPreview(2, "launch", url_model.Preview{})
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetPreview(2, "launch")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove the preview", func(t *testing.T) {
+		_, err := urlService.SetPreview(owner, "launch", url_model.Preview{Title: " "})
+		assert.NoError(t, err)
+
+		preview, err := urlService.LinkPreview("launch")
+		assert.NoError(t, err)
+		assert.Nil(t, preview)
+	})
+}
+
+func TestPreview_Validation(t *testing.T) {
+	urlService := NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+
+	tests := []struct {
+		name    string
+		preview url_model.Preview
+	}{
+		{"Long Title", url_model.Preview{Title: strings.Repeat("a", maxTitleLength+1)}},
+		{"Long Description", url_model.Preview{Description: strings.Repeat("a", maxPreviewDescriptionLength+1)}},
+		{"Negative Delay", url_model.Preview{Interstitial: true, Delay: -1}},
+		{"Long Delay", url_model.Preview{Interstitial: true, Delay: maxInterstitialDelay + 1}},
+		{"Unsafe Image", url_model.Preview{Image: "file:///etc/passwd"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetPreview(1, "launch", tt.preview)
+			assert.ErrorIs(t, err, url_model.ErrInvalidPreview)
+		})
+	}
+}
+
+func TestIsCrawler(t *testing.T) {
+	assert.True(t, IsCrawler("Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"))
+	assert.True(t, IsCrawler("facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"))
+	assert.True(t, IsCrawler("Mozilla/5.0 (compatible; Discordbot/2.0; +https://discordapp.com)"))
+	assert.False(t, IsCrawler(iPhoneAgent))
+	assert.False(t, IsCrawler(""))
+}
@@ -0,0 +1,136 @@
+package url_service
+
+import (
+	"strings"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// redirectTypes are the HTTP statuses links may redirect with.
+var redirectTypes = map[int]bool{301: true, 302: true, 307: true, 308: true}
+
+// ChangeDestination validates and changes the destination and the redirect type of the URL, recording the
+// change in its history; a zero redirect type keeps the current one. Unsafe destinations fail with a
+// *url_model.Rejection. The user needs edit access to the URL.
+func (s *Service) ChangeDestination(userID uint, shortURL, destination string, redirectType int) (*url_model.Revision, error) {
+	destination = strings.TrimSpace(destination)
+	if redirectType != 0 && !redirectTypes[redirectType] {
+		return nil, url_model.ErrInvalidRedirectType
+	}
+	if err := s.Safety.Check(destination); err != nil {
+		return nil, err
+	}
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+	if redirectType == 0 {
+		redirectType = u.RedirectType
+	}
+
+	rules, err := s.Repository.GetRules(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.revise(u, rules, url_model.Revision{Destination: destination, RedirectType: redirectType, Rules: rules, ChangedBy: &userID}, false)
+}
+
+// GetHistory returns the revisions of the URL, newest first; URLs that were never changed have none.
+// The user needs view access to the URL.
+func (s *Service) GetHistory(userID uint, shortURL string) ([]url_model.Revision, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetRevisions(shortURL)
+}
+
+// Rollback restores the destination, redirect type and redirect rules of a revision of the URL, recording
+// the rollback as a new revision. Revisions of other URLs fail with url_model.ErrRevisionNotFound, and
+// destinations or rule destinations that are no longer safe with a *url_model.Rejection. The user needs edit
+// access to the URL.
+func (s *Service) Rollback(userID uint, shortURL string, revisionID uint) (*url_model.Revision, error) {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	history, err := s.Repository.GetRevisions(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	var target *url_model.Revision
+	for i := range history {
+		if history[i].ID == revisionID {
+			target = &history[i]
+			break
+		}
+	}
+	if target == nil {
+		return nil, url_model.ErrRevisionNotFound
+	}
+	// Blocklists may have changed since the revision was made
+	if err := s.Safety.Check(target.Destination); err != nil {
+		return nil, err
+	}
+	for _, rule := range target.Rules {
+		if err := s.Safety.Check(rule.Destination); err != nil {
+			return nil, err
+		}
+	}
+
+	rules, err := s.Repository.GetRules(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	return s.revise(u, rules, url_model.Revision{
+		Destination:  target.Destination,
+		RedirectType: target.RedirectType,
+		Rules:        target.Rules,
+		ChangedBy:    &userID,
+		RestoredFrom: &target.ID,
+	}, true)
+}
+
+// revise changes the URL, given as it was before the change with its rules, to the revision and records it,
+// replacing the rules of the URL with those of the revision when withRules is set. The first change of a URL
+// also records how it was before, so that it can be rolled back to.
+func (s *Service) revise(u *url_model.URL, rules []url_model.Rule, revision url_model.Revision, withRules bool) (*url_model.Revision, error) {
+	history, err := s.Repository.GetRevisions(u.ShortenedURL)
+	if err != nil {
+		return nil, err
+	}
+	var revisions []*url_model.Revision
+	if len(history) == 0 {
+		first := url_model.Revision{Destination: u.OriginalURL, RedirectType: u.RedirectType, Rules: nonNilRules(rules), CreatedAt: u.CreatedAt}
+		// Anonymous links have no creator
+		if u.UserID != 0 {
+			creator := u.UserID
+			first.ChangedBy = &creator
+		}
+		revisions = append(revisions, &first)
+	}
+
+	revision.PreviousDestination = u.OriginalURL
+	revision.Rules = nonNilRules(revision.Rules)
+	revision.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	revisions = append(revisions, &revision)
+	if err := s.Repository.Revise(u.ShortenedURL, revisions, withRules); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func nonNilRules(rules []url_model.Rule) []url_model.Rule {
+	if rules == nil {
+		return []url_model.Rule{}
+	}
+	return rules
+}
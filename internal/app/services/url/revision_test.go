@@ -0,0 +1,129 @@
+package url_service
+
+import (
+	"testing"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevisions(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com/v1", "docs", &owner)
+	iosRule := url_model.Rule{Conditions: url_model.RuleConditions{Devices: []string{"ios"}}, Destination: "https://apps.apple.com/app/id1"}
+
+	t.Run("Should record how the link was before its first change", func(t *testing.T) {
+		revision, err := urlService.ChangeDestination(owner, "docs", " https://www.example.com/v2 ", 302)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/v2", revision.Destination)
+		assert.Equal(t, "https://www.example.com/v1", revision.PreviousDestination)
+		assert.Equal(t, 302, revision.RedirectType)
+		assert.Equal(t, owner, *revision.ChangedBy)
+
+		history, err := urlService.GetHistory(owner, "docs")
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+		assert.Equal(t, revision.ID, history[0].ID)
+		assert.Equal(t, "https://www.example.com/v1", history[1].Destination)
+		assert.Equal(t, url_model.DefaultRedirectType, history[1].RedirectType)
+		assert.Equal(t, []url_model.Rule{}, history[1].Rules)
+
+		u, _ := repository.GetURL("docs")
+		assert.Equal(t, "https://www.example.com/v2", u.OriginalURL)
+	})
+
+	t.Run("Should record rule changes with the current destination", func(t *testing.T) {
+		_, err := urlService.SetRules(owner, "docs", []url_model.Rule{iosRule})
+		assert.NoError(t, err)
+
+		history, _ := urlService.GetHistory(owner, "docs")
+		assert.Len(t, history, 3)
+		assert.Equal(t, "https://www.example.com/v2", history[0].Destination)
+		assert.Equal(t, 302, history[0].RedirectType)
+		assert.Len(t, history[0].Rules, 1)
+	})
+
+	t.Run("Should keep the redirect type when none is given", func(t *testing.T) {
+		revision, err := urlService.ChangeDestination(owner, "docs", "https://www.example.com/v3", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 302, revision.RedirectType)
+		assert.Len(t, revision.Rules, 1)
+	})
+
+	t.Run("Should roll back to a revision", func(t *testing.T) {
+		history, _ := urlService.GetHistory(owner, "docs")
+		first := history[len(history)-1]
+
+		revision, err := urlService.Rollback(owner, "docs", first.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, *revision.RestoredFrom)
+		assert.Equal(t, "https://www.example.com/v3", revision.PreviousDestination)
+
+		u, _ := repository.GetURL("docs")
+		assert.Equal(t, "https://www.example.com/v1", u.OriginalURL)
+		assert.Equal(t, url_model.DefaultRedirectType, u.RedirectType)
+		rules, _ := repository.GetRules("docs")
+		assert.Empty(t, rules)
+
+		match, err := urlService.Resolve("docs", u.OriginalURL, NewVisitor(iPhoneAgent, "", "", first.CreatedAt), "")
+		assert.NoError(t, err)
+		assert.Equal(t, revision.ID, match.Revision)
+		assert.Equal(t, url_model.DefaultRedirectType, match.RedirectType)
+	})
+
+	t.Run("Should not roll back to revisions of other links", func(t *testing.T) {
+		_, _ = repository.CreateURL("https://www.example.org", "other", &owner)
+		_, _ = urlService.ChangeDestination(owner, "other", "https://www.example.org/v2", 0)
+		history, _ := urlService.GetHistory(owner, "other")
+
+		_, err := urlService.Rollback(owner, "docs", history[0].ID)
+		assert.ErrorIs(t, err, url_model.ErrRevisionNotFound)
+	})
+
+	t.Run("Should not roll back to rules that are no longer safe", func(t *testing.T) {
+		history, _ := urlService.GetHistory(owner, "docs")
+		var withRule url_model.Revision
+		for _, revision := range history {
+			if len(revision.Rules) == 1 {
+				withRule = revision
+				break
+			}
+		}
+		urlService.Safety.OwnHosts = []string{"apps.apple.com"}
+		defer func() { urlService.Safety.OwnHosts = nil }()
+
+		_, err := urlService.Rollback(owner, "docs", withRule.ID)
+
+		var rejection *url_model.Rejection
+		assert.ErrorAs(t, err, &rejection)
+		rules, _ := repository.GetRules("docs")
+		assert.Empty(t, rules)
+		after, _ := urlService.GetHistory(owner, "docs")
+		assert.Len(t, after, len(history))
+	})
+
+	t.Run("Should reject invalid changes", func(t *testing.T) {
+		_, err := urlService.ChangeDestination(owner, "docs", "https://www.example.com", 303)
+		assert.ErrorIs(t, err, url_model.ErrInvalidRedirectType)
+
+		_, err = urlService.ChangeDestination(owner, "docs", "javascript:alert(1)", 0)
+		var rejection *url_model.Rejection
+		assert.ErrorAs(t, err, &rejection)
+	})
+
+	t.Run("Should only let editors change links", func(t *testing.T) {
+		_, err := urlService.ChangeDestination(2, "docs", "https://www.example.com", 0)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.Rollback(2, "docs", 1)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetHistory(2, "docs")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+}
@@ -0,0 +1,405 @@
+package url_service
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// maxRules is the largest number of redirect rules of a URL.
+const maxRules = 20
+
+// maxRuleNameLength is the longest name of a redirect rule, in characters.
+const maxRuleNameLength = 100
+
+// DefaultCountryHeader is the request header holding the country of the visitor, as set by Cloudflare.
+const DefaultCountryHeader = "CF-IPCountry"
+
+// scheduleLayout is the layout of the times of a schedule.
+const scheduleLayout = "15:04"
+
+var (
+	countryPattern  = regexp.MustCompile(`^[A-Z]{2}$`)
+	languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+)
+
+var devices = map[string]bool{
+	url_model.DeviceIOS:     true,
+	url_model.DeviceAndroid: true,
+	url_model.DeviceWindows: true,
+	url_model.DeviceMacOS:   true,
+	url_model.DeviceLinux:   true,
+	url_model.DeviceOther:   true,
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// GetRules returns the redirect rules of the URL. The user needs view access to the URL.
+func (s *Service) GetRules(userID uint, shortURL string) ([]url_model.Rule, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.Repository.GetRules(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []url_model.Rule{}
+	}
+	return rules, nil
+}
+
+// SetRules validates and replaces the redirect rules of the URL, returning them normalized; no rules
+// removes them. The change is recorded in the history of the URL. Invalid rules fail with a
+// *url_model.RuleError. The user needs edit access to the URL.
+func (s *Service) SetRules(userID uint, shortURL string, rules []url_model.Rule) ([]url_model.Rule, error) {
+	normalized, err := s.validateRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	previous, err := s.Repository.GetRules(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	revision := url_model.Revision{Destination: u.OriginalURL, RedirectType: u.RedirectType, Rules: normalized, ChangedBy: &userID}
+	if _, err := s.revise(u, previous, revision, true); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// Resolve returns where the visitor of the URL is redirected to: the destination of the first matching
+// rule or, when none matches, the variant of the split of the URL chosen for the visitor, keeping the
+// assigned variant, or the original URL when the URL has no split. The match carries the redirect type and
+// the latest revision of the URL.
+func (s *Service) Resolve(shortURL, originalURL string, visitor url_model.Visitor, assigned string) (*url_model.RuleMatch, error) {
+	rules, err := s.Repository.GetRules(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	redirectType, revision, err := s.Repository.GetRedirectVersion(shortURL)
+	if err != nil {
+		return nil, err
+	}
+
+	match := matchRules(rules, originalURL, visitor)
+	match.RedirectType, match.Revision = redirectType, revision
+	if match.Rule != nil {
+		return match, nil
+	}
+
+	variant, cookie, err := s.ChooseVariant(shortURL, visitor.IPAddress, assigned)
+	if err != nil {
+		return nil, err
+	}
+	if variant != nil {
+		match.Destination, match.Variant, match.Cookie = variant.Destination, variant.Name, cookie
+	}
+	return match, nil
+}
+
+// DryRun returns where the visitor would be redirected to by the rules, split and tracking parameters of
+// the URL when visiting it with the query, and the app opened first, without recording a click. The user
+// needs view access to the URL.
+func (s *Service) DryRun(userID uint, shortURL string, visitor url_model.Visitor, assigned string, query url.Values) (*url_model.RuleMatch, error) {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	match, err := s.Resolve(shortURL, u.OriginalURL, visitor, assigned)
+	if err != nil {
+		return nil, err
+	}
+	if match.Destination, err = s.Track(shortURL, match.Destination, query); err != nil {
+		return nil, err
+	}
+	if match.App, err = s.AppTarget(shortURL, visitor.Device, match.Destination); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// NewVisitor describes a visit from its User-Agent and Accept-Language headers and the country of the visitor.
+func NewVisitor(userAgent, acceptLanguage, country string, at time.Time) url_model.Visitor {
+	return url_model.Visitor{
+		Device:   DetectDevice(userAgent),
+		Country:  strings.ToUpper(strings.TrimSpace(country)),
+		Language: PreferredLanguage(acceptLanguage),
+		Time:     at,
+	}
+}
+
+// DetectDevice returns the device of a User-Agent header. iPads presenting themselves as Macs are detected as macOS.
+func DetectDevice(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	// iOS and Android user agents mention Mac OS X and Linux too, so they are checked first
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return url_model.DeviceIOS
+	case strings.Contains(ua, "android"):
+		return url_model.DeviceAndroid
+	case strings.Contains(ua, "windows"):
+		return url_model.DeviceWindows
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		return url_model.DeviceMacOS
+	case strings.Contains(ua, "linux"), strings.Contains(ua, "x11"):
+		return url_model.DeviceLinux
+	default:
+		return url_model.DeviceOther
+	}
+}
+
+// PreferredLanguage returns the language of an Accept-Language header with the highest quality,
+// the first one on ties, in lower case; empty when there is none.
+func PreferredLanguage(acceptLanguage string) string {
+	type language struct {
+		tag     string
+		quality float64
+	}
+	var languages []language
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			q, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = q
+		}
+		if quality > 0 {
+			languages = append(languages, language{tag: tag, quality: quality})
+		}
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool { return languages[i].quality > languages[j].quality })
+	return languages[0].tag
+}
+
+// matchRules returns the destination of the first rule matching the visitor, falling back to the original URL.
+func matchRules(rules []url_model.Rule, originalURL string, visitor url_model.Visitor) *url_model.RuleMatch {
+	for i, rule := range rules {
+		if ruleMatches(rule.Conditions, visitor) {
+			number := i + 1
+			return &url_model.RuleMatch{Rule: &number, Name: rule.Name, Destination: rule.Destination, Visitor: visitor}
+		}
+	}
+	return &url_model.RuleMatch{Destination: originalURL, Visitor: visitor}
+}
+
+func ruleMatches(conditions url_model.RuleConditions, visitor url_model.Visitor) bool {
+	if len(conditions.Devices) > 0 && !listed(conditions.Devices, visitor.Device) {
+		return false
+	}
+	if len(conditions.Countries) > 0 && !listed(conditions.Countries, visitor.Country) {
+		return false
+	}
+	if len(conditions.Languages) > 0 && !languageListed(conditions.Languages, visitor.Language) {
+		return false
+	}
+	if conditions.Schedule != nil && !scheduleMatches(conditions.Schedule, visitor.Time) {
+		return false
+	}
+	return true
+}
+
+func listed(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// languageListed matches a language against tags and their sublanguages, so "de" matches "de-at".
+func languageListed(tags []string, language string) bool {
+	for _, tag := range tags {
+		if language == tag || strings.HasPrefix(language, tag+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleMatches reports whether the time falls within a validated schedule, or outside it for Outside schedules.
+func scheduleMatches(schedule *url_model.Schedule, t time.Time) bool {
+	location, err := loadLocation(schedule.Timezone)
+	if err != nil {
+		return false
+	}
+	local := t.In(location)
+	from, _ := time.Parse(scheduleLayout, schedule.From)
+	until, _ := time.Parse(scheduleLayout, schedule.Until)
+
+	minute := local.Hour()*60 + local.Minute()
+	start, end := from.Hour()*60+from.Minute(), until.Hour()*60+until.Minute()
+	weekday := local.Weekday()
+	var within bool
+	if start < end {
+		within = minute >= start && minute < end
+	} else {
+		within = minute >= start || minute < end
+		// The early hours of an overnight window belong to the day it started on
+		if minute < end {
+			weekday = local.AddDate(0, 0, -1).Weekday()
+		}
+	}
+	if within && len(schedule.Days) > 0 {
+		within = false
+		for _, day := range schedule.Days {
+			if weekdays[day] == weekday {
+				within = true
+			}
+		}
+	}
+
+	return within != schedule.Outside
+}
+
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+// validateRules checks the rules and returns a copy with their values normalized to the case they are matched in.
+func (s *Service) validateRules(rules []url_model.Rule) ([]url_model.Rule, error) {
+	if len(rules) > maxRules {
+		return nil, url_model.ErrTooManyRules
+	}
+
+	normalized := make([]url_model.Rule, len(rules))
+	for i, rule := range rules {
+		number := i + 1
+		invalid := func(field, detail string) error {
+			return &url_model.RuleError{Rule: number, Field: field, Detail: detail}
+		}
+
+		rule.Name = strings.TrimSpace(rule.Name)
+		if utf8.RuneCountInString(rule.Name) > maxRuleNameLength {
+			return nil, invalid("name", "must be at most 100 characters")
+		}
+
+		if rule.Destination == "" {
+			return nil, invalid("destination", "is required")
+		}
+		if err := s.Safety.Check(rule.Destination); err != nil {
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				return nil, invalid("destination", rejection.Detail)
+			}
+			return nil, err
+		}
+
+		conditions := rule.Conditions
+		if len(conditions.Devices) == 0 && len(conditions.Countries) == 0 && len(conditions.Languages) == 0 && conditions.Schedule == nil {
+			return nil, invalid("if", "at least one condition is required")
+		}
+
+		var err error
+		if conditions.Devices, err = normalizeValues(conditions.Devices, strings.ToLower, func(v string) bool { return devices[v] }); err != nil {
+			return nil, invalid("devices", err.Error())
+		}
+		if conditions.Countries, err = normalizeValues(conditions.Countries, strings.ToUpper, countryPattern.MatchString); err != nil {
+			return nil, invalid("countries", err.Error())
+		}
+		if conditions.Languages, err = normalizeValues(conditions.Languages, strings.ToLower, languagePattern.MatchString); err != nil {
+			return nil, invalid("languages", err.Error())
+		}
+		if conditions.Schedule != nil {
+			schedule, detail := validateSchedule(*conditions.Schedule)
+			if detail != "" {
+				return nil, invalid("schedule", detail)
+			}
+			conditions.Schedule = &schedule
+		}
+
+		rule.Conditions = conditions
+		normalized[i] = rule
+	}
+
+	return normalized, nil
+}
+
+// normalizeValues trims and converts the case of the values of a condition, rejecting invalid ones.
+func normalizeValues(values []string, convert func(string) string, valid func(string) bool) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i] = convert(strings.TrimSpace(value))
+		if !valid(normalized[i]) {
+			return nil, errors.New(strconv.Quote(value) + " is not valid")
+		}
+	}
+	return normalized, nil
+}
+
+// validateSchedule returns the schedule with its days in lower case, or the reason it is invalid.
+func validateSchedule(schedule url_model.Schedule) (url_model.Schedule, string) {
+	from, err := time.Parse(scheduleLayout, schedule.From)
+	if err != nil {
+		return schedule, "from must be an HH:MM time"
+	}
+	until, err := time.Parse(scheduleLayout, schedule.Until)
+	if err != nil {
+		return schedule, "until must be an HH:MM time"
+	}
+	if from.Equal(until) {
+		return schedule, "from and until must differ"
+	}
+	if _, err := loadLocation(schedule.Timezone); err != nil {
+		return schedule, strconv.Quote(schedule.Timezone) + " is not a known time zone"
+	}
+
+	days := make([]string, len(schedule.Days))
+	for i, day := range schedule.Days {
+		days[i] = strings.ToLower(strings.TrimSpace(day))
+		if _, ok := weekdays[days[i]]; !ok {
+			return schedule, strconv.Quote(day) + " is not a day from mon to sun"
+		}
+	}
+	if len(days) == 0 {
+		days = nil
+	}
+	schedule.Days = days
+	return schedule, ""
+}
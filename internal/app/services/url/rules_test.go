@@ -0,0 +1,180 @@
+package url_service
+
+import (
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	iPhoneAgent  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	androidAgent = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+)
+
+func TestRedirectRules(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com", "app", &owner)
+	_, _ = repository.CreateURL("https://www.example.org", "plain", &owner)
+
+	rules := []url_model.Rule{
+		{Name: "iOS", Conditions: url_model.RuleConditions{Devices: []string{"iOS"}}, Destination: "https://apps.apple.com/app/id1"},
+		{Conditions: url_model.RuleConditions{Devices: []string{"android"}}, Destination: "https://play.google.com/store/apps/details?id=app"},
+		{Conditions: url_model.RuleConditions{Countries: []string{"tr"}}, Destination: "https://www.example.com/tr"},
+		{Conditions: url_model.RuleConditions{Languages: []string{"DE"}}, Destination: "https://www.example.com/de"},
+		{Name: "Closed", Conditions: url_model.RuleConditions{Schedule: &url_model.Schedule{
+			Days: []string{"Mon", "tue", "wed", "thu", "fri"}, From: "09:00", Until: "17:00", Timezone: "Europe/Berlin", Outside: true,
+		}}, Destination: "https://status.example.com"},
+	}
+	// Wednesday 21 October 2026, 12:00 in Berlin
+	businessHours := time.Date(2026, 10, 21, 10, 0, 0, 0, time.UTC)
+
+	t.Run("Should save normalized rules", func(t *testing.T) {
+		saved, err := urlService.SetRules(owner, "app", rules)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ios"}, saved[0].Conditions.Devices)
+		assert.Equal(t, []string{"TR"}, saved[2].Conditions.Countries)
+		assert.Equal(t, []string{"de"}, saved[3].Conditions.Languages)
+		assert.Equal(t, "mon", saved[4].Conditions.Schedule.Days[0])
+
+		found, err := urlService.GetRules(owner, "app")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+	})
+
+	t.Run("Should resolve the first matching rule", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			visitor     url_model.Visitor
+			rule        int
+			destination string
+		}{
+			{"iOS", NewVisitor(iPhoneAgent, "tr-TR", "TR", businessHours), 1, "https://apps.apple.com/app/id1"},
+			{"Android", NewVisitor(androidAgent, "", "", businessHours), 2, "https://play.google.com/store/apps/details?id=app"},
+			{"Country", NewVisitor("", "de", "tr", businessHours), 3, "https://www.example.com/tr"},
+			{"Language", NewVisitor("", "en;q=0.5, de-AT", "US", businessHours), 4, "https://www.example.com/de"},
+			{"Evening", NewVisitor("", "en", "US", businessHours.Add(8*time.Hour)), 5, "https://status.example.com"},
+			{"Weekend", NewVisitor("", "en", "US", businessHours.Add(72*time.Hour)), 5, "https://status.example.com"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				match, err := urlService.Resolve("app", "https://www.example.com", tt.visitor, "")
+
+				assert.NoError(t, err)
+				assert.Equal(t, tt.rule, *match.Rule)
+				assert.Equal(t, tt.destination, match.Destination)
+			})
+		}
+	})
+
+	t.Run("Should fall back to the original URL", func(t *testing.T) {
+		match, err := urlService.Resolve("app", "https://www.example.com", NewVisitor("", "en", "US", businessHours), "")
+
+		assert.NoError(t, err)
+		assert.Nil(t, match.Rule)
+		assert.Equal(t, "https://www.example.com", match.Destination)
+
+		match, _ = urlService.Resolve("plain", "https://www.example.org", NewVisitor(iPhoneAgent, "", "", businessHours), "")
+		assert.Equal(t, "https://www.example.org", match.Destination)
+	})
+
+	t.Run("Should dry run for viewers", func(t *testing.T) {
+		match, err := urlService.DryRun(owner, "app", NewVisitor(androidAgent, "", "", businessHours), "", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, *match.Rule)
+		assert.Equal(t, url_model.DeviceAndroid, match.Visitor.Device)
+
+		_, err = urlService.DryRun(2, "app", url_model.Visitor{}, "", nil)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.DryRun(owner, "missing", url_model.Visitor{}, "", nil)
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should only let editors set rules", func(t *testing.T) {
+		_, err := urlService.SetRules(2, "app", nil)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetRules(2, "app")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove rules", func(t *testing.T) {
+		_, err := urlService.SetRules(owner, "app", []url_model.Rule{})
+		assert.NoError(t, err)
+
+		found, _ := urlService.GetRules(owner, "app")
+		assert.Empty(t, found)
+	})
+}
+
+func TestRedirectRules_Validation(t *testing.T) {
+	urlService := NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+	iOS := url_model.RuleConditions{Devices: []string{"ios"}}
+
+	tests := []struct {
+		name  string
+		rule  url_model.Rule
+		field string
+	}{
+		{"Missing Destination", url_model.Rule{Conditions: iOS}, "destination"},
+		{"Unsafe Destination", url_model.Rule{Conditions: iOS, Destination: "javascript:alert(1)"}, "destination"},
+		{"No Conditions", url_model.Rule{Destination: "https://www.example.com"}, "if"},
+		{"Unknown Device", url_model.Rule{Conditions: url_model.RuleConditions{Devices: []string{"blackberry"}}, Destination: "https://www.example.com"}, "devices"},
+		{"Invalid Country", url_model.Rule{Conditions: url_model.RuleConditions{Countries: []string{"TUR"}}, Destination: "https://www.example.com"}, "countries"},
+		{"Invalid Language", url_model.Rule{Conditions: url_model.RuleConditions{Languages: []string{"de_DE"}}, Destination: "https://www.example.com"}, "languages"},
+		{"Invalid Time", url_model.Rule{Conditions: url_model.RuleConditions{Schedule: &url_model.Schedule{From: "9am", Until: "17:00"}}, Destination: "https://www.example.com"}, "schedule"},
+		{"Empty Window", url_model.Rule{Conditions: url_model.RuleConditions{Schedule: &url_model.Schedule{From: "09:00", Until: "09:00"}}, Destination: "https://www.example.com"}, "schedule"},
+		{"Unknown Time Zone", url_model.Rule{Conditions: url_model.RuleConditions{Schedule: &url_model.Schedule{From: "09:00", Until: "17:00", Timezone: "Mars/Olympus"}}, Destination: "https://www.example.com"}, "schedule"},
+		{"Unknown Day", url_model.Rule{Conditions: url_model.RuleConditions{Schedule: &url_model.Schedule{Days: []string{"monday"}, From: "09:00", Until: "17:00"}}, Destination: "https://www.example.com"}, "schedule"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetRules(1, "app", []url_model.Rule{{Conditions: iOS, Destination: "https://www.example.com"}, tt.rule})
+
+			var ruleErr *url_model.RuleError
+			assert.ErrorAs(t, err, &ruleErr)
+			assert.ErrorIs(t, err, url_model.ErrInvalidRule)
+			assert.Equal(t, 2, ruleErr.Rule)
+			assert.Equal(t, tt.field, ruleErr.Field)
+		})
+	}
+
+	t.Run("Should limit the number of rules", func(t *testing.T) {
+		_, err := urlService.SetRules(1, "app", make([]url_model.Rule, maxRules+1))
+		assert.ErrorIs(t, err, url_model.ErrTooManyRules)
+	})
+}
+
+func TestScheduleMatches(t *testing.T) {
+	// Friday nights from 22:00 to 02:00 in Berlin
+	fridayNight := &url_model.Schedule{Days: []string{"fri"}, From: "22:00", Until: "02:00", Timezone: "Europe/Berlin"}
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+
+	assert.True(t, scheduleMatches(fridayNight, time.Date(2026, 10, 23, 23, 0, 0, 0, berlin)))
+	assert.True(t, scheduleMatches(fridayNight, time.Date(2026, 10, 24, 1, 0, 0, 0, berlin)))
+	assert.False(t, scheduleMatches(fridayNight, time.Date(2026, 10, 24, 2, 0, 0, 0, berlin)))
+	assert.False(t, scheduleMatches(fridayNight, time.Date(2026, 10, 24, 23, 0, 0, 0, berlin)))
+	assert.False(t, scheduleMatches(fridayNight, time.Date(2026, 10, 23, 1, 0, 0, 0, berlin)))
+}
+
+func TestDetectDevice(t *testing.T) {
+	assert.Equal(t, url_model.DeviceIOS, DetectDevice(iPhoneAgent))
+	assert.Equal(t, url_model.DeviceAndroid, DetectDevice(androidAgent))
+	assert.Equal(t, url_model.DeviceWindows, DetectDevice("Mozilla/5.0 (Windows NT 10.0; Win64; x64)"))
+	assert.Equal(t, url_model.DeviceMacOS, DetectDevice("Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0)"))
+	assert.Equal(t, url_model.DeviceLinux, DetectDevice("Mozilla/5.0 (X11; Linux x86_64)"))
+	assert.Equal(t, url_model.DeviceOther, DetectDevice("curl/8.4.0"))
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	assert.Equal(t, "de-at", PreferredLanguage("de-AT"))
+	assert.Equal(t, "fr", PreferredLanguage("en;q=0.8, fr, de;q=0.9"))
+	assert.Equal(t, "en", PreferredLanguage("en, fr"))
+	assert.Equal(t, "", PreferredLanguage("*, de;q=0"))
+	assert.Equal(t, "", PreferredLanguage(""))
+}
@@ -0,0 +1,173 @@
+package url_service
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	url_model "url-shortener/internal/app/models/url"
+)
+
+// sharedAddressSpace is the carrier-grade NAT range, which net.IP.IsPrivate does not cover.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// SafetyPolicy decides which destination URLs may be shortened.
+type SafetyPolicy struct {
+	// AllowedSchemes lists the accepted URL schemes, in lower case.
+	AllowedSchemes []string
+	// OwnHosts are the hosts serving short links; linking to them would create redirect loops.
+	OwnHosts []string
+	// ServesHost reports whether another host serves short links, such as a verified custom domain.
+	// When nil only OwnHosts are checked for loops.
+	ServesHost func(host string) bool
+	// LookupIP resolves host names so names pointing at private addresses are rejected too.
+	// When nil only IP literals are checked.
+	LookupIP func(host string) ([]net.IP, error)
+	// Blocklist holds the blocked domains and patterns, nil when none are configured.
+	Blocklist *Blocklist
+}
+
+// DefaultSafetyPolicy returns the policy used when none is configured.
+func DefaultSafetyPolicy() SafetyPolicy {
+	return SafetyPolicy{
+		AllowedSchemes: []string{"http", "https"},
+	}
+}
+
+// Check runs the destination URL through the policy checks, returning a *url_model.Rejection
+// explaining the first one it fails.
+func (p SafetyPolicy) Check(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &url_model.Rejection{Reason: url_model.ReasonInvalidURL, Detail: "URL cannot be parsed"}
+	}
+
+	checks := []func(*url.URL) *url_model.Rejection{
+		p.checkScheme,
+		p.checkHost,
+		p.checkLoop,
+		p.checkBlocklist,
+		p.checkAddress,
+	}
+	for _, check := range checks {
+		if rejection := check(u); rejection != nil {
+			return rejection
+		}
+	}
+
+	return nil
+}
+
+func (p SafetyPolicy) checkScheme(u *url.URL) *url_model.Rejection {
+	scheme := strings.ToLower(u.Scheme)
+	for _, allowed := range p.AllowedSchemes {
+		if scheme == allowed {
+			return nil
+		}
+	}
+	return &url_model.Rejection{Reason: url_model.ReasonScheme, Detail: fmt.Sprintf("scheme %q is not allowed", u.Scheme)}
+}
+
+// checkHost requires a host for hierarchical URLs and rejects numeric forms such as
+// "2130706433" or "0x7f.1" that browsers resolve to addresses but net.ParseIP does not.
+func (p SafetyPolicy) checkHost(u *url.URL) *url_model.Rejection {
+	if u.Opaque != "" {
+		return nil
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return &url_model.Rejection{Reason: url_model.ReasonInvalidURL, Detail: "URL has no host"}
+	}
+	if net.ParseIP(host) == nil && numericLabel(host[strings.LastIndex(host, ".")+1:]) {
+		return &url_model.Rejection{Reason: url_model.ReasonInvalidURL, Detail: fmt.Sprintf("host %q is not a valid name or address", host)}
+	}
+	return nil
+}
+
+func (p SafetyPolicy) checkLoop(u *url.URL) *url_model.Rejection {
+	host := normalizeHost(u.Hostname())
+	for _, own := range p.OwnHosts {
+		if host != "" && host == normalizeHost(own) {
+			return &url_model.Rejection{Reason: url_model.ReasonRedirectLoop, Detail: "URL points to this shortener"}
+		}
+	}
+	if host != "" && p.ServesHost != nil && p.ServesHost(host) {
+		return &url_model.Rejection{Reason: url_model.ReasonRedirectLoop, Detail: "URL points to a domain of this shortener"}
+	}
+	return nil
+}
+
+func (p SafetyPolicy) checkBlocklist(u *url.URL) *url_model.Rejection {
+	if p.Blocklist == nil {
+		return nil
+	}
+	if entry, ok := p.Blocklist.BlockedDomain(u.Hostname()); ok {
+		return &url_model.Rejection{Reason: url_model.ReasonBlockedDomain, Detail: fmt.Sprintf("domain %q is blocked", entry)}
+	}
+	if _, ok := p.Blocklist.BlockedPattern(u.String()); ok {
+		// The pattern itself is not disclosed so it cannot be worked around
+		return &url_model.Rejection{Reason: url_model.ReasonBlockedPattern, Detail: "URL matches a blocked pattern"}
+	}
+	return nil
+}
+
+func (p SafetyPolicy) checkAddress(u *url.URL) *url_model.Rejection {
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return &url_model.Rejection{Reason: url_model.ReasonPrivateAddress, Detail: fmt.Sprintf("host %q is local", host)}
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		if p.LookupIP == nil {
+			return nil
+		}
+		// Names that do not resolve are accepted; they cannot reach anything internal either
+		resolved, err := p.LookupIP(host)
+		if err != nil {
+			return nil
+		}
+		ips = resolved
+	}
+
+	for _, ip := range ips {
+		if InternalIP(ip) {
+			return &url_model.Rejection{Reason: url_model.ReasonPrivateAddress, Detail: fmt.Sprintf("host %q is a private address", host)}
+		}
+	}
+	return nil
+}
+
+// InternalIP reports whether the address is not publicly routable.
+func InternalIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	return ok && sharedAddressSpace.Contains(addr.Unmap())
+}
+
+// numericLabel reports whether the label is a decimal, octal or hexadecimal number.
+// Top-level domains are never numeric, so such hosts are meant to be read as addresses.
+func numericLabel(label string) bool {
+	digits := strings.TrimPrefix(strings.TrimPrefix(label, "0x"), "0X")
+	if digits == "" {
+		return label != ""
+	}
+	for _, r := range strings.ToLower(digits) {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f' || len(digits) == len(label)) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeHost lower-cases the host and removes the trailing dot of fully qualified names.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
@@ -0,0 +1,89 @@
+package url_service
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	url_model "url-shortener/internal/app/models/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafetyPolicy_Check(t *testing.T) {
+	dir := t.TempDir()
+	domains := filepath.Join(dir, "domains.txt")
+	patterns := filepath.Join(dir, "patterns.txt")
+	assert.NoError(t, os.WriteFile(domains, []byte("# phishing\nevil.com\n"), 0o600))
+	assert.NoError(t, os.WriteFile(patterns, []byte(`/wp-login\.php`+"\n"), 0o600))
+	blocklist, err := NewBlocklist(domains, patterns)
+	assert.NoError(t, err)
+
+	policy := DefaultSafetyPolicy()
+	policy.OwnHosts = []string{"Sho.rt"}
+	policy.ServesHost = func(host string) bool {
+		return host == "go.example.com"
+	}
+	policy.Blocklist = blocklist
+	policy.LookupIP = func(host string) ([]net.IP, error) {
+		if host == "internal.example.com" {
+			return []net.IP{net.ParseIP("10.0.0.5")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	// reason returns the rejection reason of the URL, empty when it is accepted
+	reason := func(rawURL string) string {
+		err := policy.Check(rawURL)
+		if err == nil {
+			return ""
+		}
+		assert.ErrorIs(t, err, url_model.ErrUnsafeURL)
+		var rejection *url_model.Rejection
+		assert.True(t, errors.As(err, &rejection))
+		return rejection.Reason
+	}
+
+	t.Run("Should accept public URLs", func(t *testing.T) {
+		assert.Empty(t, reason("https://www.example.com/path?q=1"))
+		assert.Empty(t, reason("http://93.184.216.34/"))
+		assert.Empty(t, reason("https://notevil.com/"))
+	})
+
+	t.Run("Should reject schemes outside the allowlist", func(t *testing.T) {
+		assert.Equal(t, url_model.ReasonScheme, reason("javascript:alert(1)"))
+		assert.Equal(t, url_model.ReasonScheme, reason("data:text/html,hi"))
+		assert.Equal(t, url_model.ReasonScheme, reason("ftp://example.com/"))
+	})
+
+	t.Run("Should reject URLs without a valid host", func(t *testing.T) {
+		assert.Equal(t, url_model.ReasonInvalidURL, reason("https:///path"))
+		assert.Equal(t, url_model.ReasonInvalidURL, reason("http://2130706433/"))
+		assert.Equal(t, url_model.ReasonInvalidURL, reason("http://0x7f.1/"))
+		assert.Equal(t, url_model.ReasonInvalidURL, reason("http://%zz/"))
+	})
+
+	t.Run("Should reject links to the shortener", func(t *testing.T) {
+		assert.Equal(t, url_model.ReasonRedirectLoop, reason("https://sho.rt/abc"))
+		assert.Equal(t, url_model.ReasonRedirectLoop, reason("http://SHO.RT.:8080/abc"))
+		assert.Equal(t, url_model.ReasonRedirectLoop, reason("https://Go.Example.com/launch"))
+	})
+
+	t.Run("Should reject private addresses", func(t *testing.T) {
+		for _, rawURL := range []string{
+			"http://localhost/", "http://app.localhost/", "http://127.0.0.1/", "http://10.1.2.3/",
+			"http://169.254.169.254/latest/meta-data/", "http://[::1]/", "http://[fd00::1]/",
+			"http://[::ffff:192.168.0.1]/", "http://100.64.0.1/", "http://0.0.0.0/",
+			"https://internal.example.com/",
+		} {
+			assert.Equal(t, url_model.ReasonPrivateAddress, reason(rawURL), rawURL)
+		}
+	})
+
+	t.Run("Should reject blocked domains and patterns", func(t *testing.T) {
+		assert.Equal(t, url_model.ReasonBlockedDomain, reason("https://evil.com/"))
+		assert.Equal(t, url_model.ReasonBlockedDomain, reason("https://login.EVIL.com/"))
+		assert.Equal(t, url_model.ReasonBlockedPattern, reason("https://example.com/wp-login.php"))
+	})
+}
@@ -0,0 +1,137 @@
+package url_service
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// Bounds of the variants of a split.
+const (
+	minVariants          = 2
+	maxVariants          = 10
+	maxVariantNameLength = 50
+	maxVariantWeight     = 1000
+)
+
+// GetSplit returns the split of the URL, nil when it has none. The user needs view access to the URL.
+func (s *Service) GetSplit(userID uint, shortURL string) (*url_model.Split, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetSplit(shortURL)
+}
+
+// SetSplit validates and replaces the split of the URL, returning it normalized; a split without variants
+// removes it. Invalid splits fail with an error wrapping url_model.ErrInvalidSplit. The user needs edit
+// access to the URL.
+func (s *Service) SetSplit(userID uint, shortURL string, split url_model.Split) (*url_model.Split, error) {
+	normalized, err := s.validateSplit(split)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.SetSplit(shortURL, normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// ChooseVariant returns the variant of the split of the URL served to the visitor, nil when the URL has
+// no split. Visitors keep the assigned variant, the one named by their cookie, while it is in the split.
+// The boolean reports whether the split remembers variants in a cookie.
+func (s *Service) ChooseVariant(shortURL, ipAddress, assigned string) (*url_model.Variant, bool, error) {
+	split, err := s.Repository.GetSplit(shortURL)
+	if err != nil || split == nil {
+		return nil, false, err
+	}
+	if len(split.Variants) == 0 {
+		return nil, false, nil
+	}
+
+	cookie := split.Sticky != url_model.StickyIP
+	if cookie && assigned != "" {
+		for i := range split.Variants {
+			if split.Variants[i].Name == assigned {
+				return &split.Variants[i], cookie, nil
+			}
+		}
+	}
+
+	total := 0
+	for _, variant := range split.Variants {
+		total += variant.Weight
+	}
+	var point int
+	if cookie {
+		point = s.intn(total)
+	} else {
+		// The same address gets the same variant of a link, without favoring a variant across links
+		hash := fnv.New64a()
+		_, _ = hash.Write([]byte(shortURL + "\x00" + ipAddress))
+		point = int(hash.Sum64() % uint64(total))
+	}
+
+	for i := range split.Variants {
+		point -= split.Variants[i].Weight
+		if point < 0 {
+			return &split.Variants[i], cookie, nil
+		}
+	}
+	return &split.Variants[len(split.Variants)-1], cookie, nil
+}
+
+// validateSplit checks the split and returns it with trimmed names and the default sticky mode,
+// or nil when it has no variants.
+func (s *Service) validateSplit(split url_model.Split) (*url_model.Split, error) {
+	if len(split.Variants) == 0 {
+		return nil, nil
+	}
+
+	switch split.Sticky {
+	case "":
+		split.Sticky = url_model.StickyCookie
+	case url_model.StickyCookie, url_model.StickyIP:
+	default:
+		return nil, fmt.Errorf("%w: sticky must be %q or %q", url_model.ErrInvalidSplit, url_model.StickyCookie, url_model.StickyIP)
+	}
+	if len(split.Variants) < minVariants || len(split.Variants) > maxVariants {
+		return nil, fmt.Errorf("%w: a split needs %d to %d variants", url_model.ErrInvalidSplit, minVariants, maxVariants)
+	}
+
+	variants := make([]url_model.Variant, len(split.Variants))
+	names := make(map[string]bool, len(split.Variants))
+	for i, variant := range split.Variants {
+		variant.Name = strings.TrimSpace(variant.Name)
+		if variant.Name == "" || utf8.RuneCountInString(variant.Name) > maxVariantNameLength {
+			return nil, fmt.Errorf("%w: variant %d: name must be 1 to %d characters", url_model.ErrInvalidSplit, i+1, maxVariantNameLength)
+		}
+		if names[variant.Name] {
+			return nil, fmt.Errorf("%w: variant %d: name %q is used twice", url_model.ErrInvalidSplit, i+1, variant.Name)
+		}
+		names[variant.Name] = true
+
+		if variant.Weight < 1 || variant.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("%w: variant %d: weight must be 1 to %d", url_model.ErrInvalidSplit, i+1, maxVariantWeight)
+		}
+		if err := s.Safety.Check(variant.Destination); err != nil {
+			var rejection *url_model.Rejection
+			if errors.As(err, &rejection) {
+				return nil, fmt.Errorf("%w: variant %d: %s", url_model.ErrInvalidSplit, i+1, rejection.Detail)
+			}
+			return nil, err
+		}
+		variants[i] = variant
+	}
+
+	split.Variants = variants
+	return &split, nil
+}
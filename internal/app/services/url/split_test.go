@@ -0,0 +1,131 @@
+package url_service
+
+import (
+	"testing"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com", "landing", &owner)
+
+	split := url_model.Split{Variants: []url_model.Variant{
+		{Name: " a ", Destination: "https://www.example.com/a", Weight: 3},
+		{Name: "b", Destination: "https://www.example.com/b", Weight: 1},
+	}}
+
+	t.Run("Should save a split sticking to cookies by default", func(t *testing.T) {
+		saved, err := urlService.SetSplit(owner, "landing", split)
+
+		assert.NoError(t, err)
+		assert.Equal(t, url_model.StickyCookie, saved.Sticky)
+		assert.Equal(t, "a", saved.Variants[0].Name)
+
+		found, err := urlService.GetSplit(owner, "landing")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+	})
+
+	t.Run("Should choose new visitors by weight", func(t *testing.T) {
+		served := make(map[string]int)
+		for point := 0; point < 4; point++ {
+			point := point
+			urlService.intn = func(n int) int {
+				assert.Equal(t, 4, n)
+				return point
+			}
+			variant, cookie, err := urlService.ChooseVariant("landing", "1.1.1.1", "")
+			assert.NoError(t, err)
+			assert.True(t, cookie)
+			served[variant.Name]++
+		}
+
+		assert.Equal(t, map[string]int{"a": 3, "b": 1}, served)
+	})
+
+	t.Run("Should keep the variant of the cookie", func(t *testing.T) {
+		urlService.intn = func(int) int { return 0 }
+
+		variant, _, _ := urlService.ChooseVariant("landing", "1.1.1.1", "b")
+		assert.Equal(t, "b", variant.Name)
+
+		// Removed variants are replaced
+		variant, _, _ = urlService.ChooseVariant("landing", "1.1.1.1", "c")
+		assert.Equal(t, "a", variant.Name)
+	})
+
+	t.Run("Should choose by IP address", func(t *testing.T) {
+		_, err := urlService.SetSplit(owner, "landing", url_model.Split{Sticky: url_model.StickyIP, Variants: split.Variants})
+		assert.NoError(t, err)
+
+		first, cookie, _ := urlService.ChooseVariant("landing", "203.0.113.7", "")
+		assert.False(t, cookie)
+		for i := 0; i < 5; i++ {
+			again, _, _ := urlService.ChooseVariant("landing", "203.0.113.7", "b")
+			assert.Equal(t, first, again)
+		}
+	})
+
+	t.Run("Should split when no rule matches", func(t *testing.T) {
+		repository.Rules["landing"] = []url_model.Rule{{Conditions: url_model.RuleConditions{Countries: []string{"TR"}}, Destination: "https://www.example.com/tr"}}
+
+		match, err := urlService.Resolve("landing", "https://www.example.com", NewVisitor("", "", "TR", time.Now()), "")
+		assert.NoError(t, err)
+		assert.Empty(t, match.Variant)
+		assert.Equal(t, "https://www.example.com/tr", match.Destination)
+
+		visitor := NewVisitor("", "", "US", time.Now())
+		visitor.IPAddress = "203.0.113.7"
+		match, err = urlService.Resolve("landing", "https://www.example.com", visitor, "")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, match.Variant)
+		assert.Equal(t, "https://www.example.com/"+match.Variant, match.Destination)
+	})
+
+	t.Run("Should only let editors set splits", func(t *testing.T) {
+		_, err := urlService.SetSplit(2, "landing", split)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetSplit(2, "landing")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove the split", func(t *testing.T) {
+		saved, err := urlService.SetSplit(owner, "landing", url_model.Split{})
+		assert.NoError(t, err)
+		assert.Nil(t, saved)
+
+		variant, _, err := urlService.ChooseVariant("landing", "1.1.1.1", "")
+		assert.NoError(t, err)
+		assert.Nil(t, variant)
+	})
+}
+
+func TestSplit_Validation(t *testing.T) {
+	urlService := NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
+	a := url_model.Variant{Name: "a", Destination: "https://www.example.com/a", Weight: 1}
+
+	tests := []struct {
+		name  string
+		split url_model.Split
+	}{
+		{"Unknown Sticky Mode", url_model.Split{Sticky: "session", Variants: []url_model.Variant{a, {Name: "b", Destination: "https://www.example.com/b", Weight: 1}}}},
+		{"Single Variant", url_model.Split{Variants: []url_model.Variant{a}}},
+		{"Missing Name", url_model.Split{Variants: []url_model.Variant{a, {Destination: "https://www.example.com/b", Weight: 1}}}},
+		{"Duplicate Name", url_model.Split{Variants: []url_model.Variant{a, a}}},
+		{"Zero Weight", url_model.Split{Variants: []url_model.Variant{a, {Name: "b", Destination: "https://www.example.com/b"}}}},
+		{"Unsafe Destination", url_model.Split{Variants: []url_model.Variant{a, {Name: "b", Destination: "ftp://www.example.com", Weight: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetSplit(1, "landing", tt.split)
+			assert.ErrorIs(t, err, url_model.ErrInvalidSplit)
+		})
+	}
+}
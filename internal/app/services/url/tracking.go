@@ -0,0 +1,210 @@
+package url_service
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// maxUTMLength is the longest UTM value, in characters.
+const maxUTMLength = 100
+
+// maxFinalURLLength is the longest destination redirected to once query parameters are added; longer
+// URLs are refused by many servers.
+const maxFinalURLLength = 8192
+
+// GetTracking returns the query parameters the URL adds to its destination. The user needs view access to the URL.
+func (s *Service) GetTracking(userID uint, shortURL string) (*url_model.Tracking, error) {
+	if err := s.Authorize(userID, shortURL, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	tracking, err := s.Repository.GetTracking(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if tracking == nil {
+		tracking = &url_model.Tracking{QueryConflict: url_model.QueryKeep}
+	}
+	return tracking, nil
+}
+
+// SetTracking validates and replaces the query parameters the URL adds to its destination, returning them
+// with trimmed values and the default conflict rule. The user needs edit access to the URL.
+func (s *Service) SetTracking(userID uint, shortURL string, tracking url_model.Tracking) (*url_model.Tracking, error) {
+	tracking, err := validateTracking(tracking)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+	if _, err := applyTracking(u.OriginalURL, &tracking, nil); err != nil {
+		return nil, err
+	}
+
+	// Links adding nothing have no row
+	stored := &tracking
+	if tracking.UTM == (url_model.UTM{}) && !tracking.PassQuery {
+		stored = nil
+	}
+	if err := s.Repository.SetTracking(shortURL, stored); err != nil {
+		return nil, err
+	}
+	return &tracking, nil
+}
+
+// Track returns the destination with the UTM parameters of the URL and, when it passes its query, the
+// query parameters of the visit. When the result is not a valid URL it fails with url_model.ErrInvalidFinalURL,
+// returning the destination unchanged.
+func (s *Service) Track(shortURL, destination string, query url.Values) (string, error) {
+	tracking, err := s.Repository.GetTracking(shortURL)
+	if err != nil {
+		return "", err
+	}
+	if tracking == nil {
+		return destination, nil
+	}
+
+	final, err := applyTracking(destination, tracking, query)
+	if err != nil {
+		return destination, err
+	}
+	return final, nil
+}
+
+// applyTracking adds the UTM parameters and the passed query to the destination. Parameters of the
+// destination that are not replaced keep their position and encoding.
+func applyTracking(destination string, tracking *url_model.Tracking, query url.Values) (string, error) {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", url_model.ErrInvalidFinalURL, "destination cannot be parsed")
+	}
+
+	params := splitQuery(u.RawQuery)
+	utm := []struct{ key, value string }{
+		{"utm_source", tracking.UTM.Source},
+		{"utm_medium", tracking.UTM.Medium},
+		{"utm_campaign", tracking.UTM.Campaign},
+		{"utm_term", tracking.UTM.Term},
+		{"utm_content", tracking.UTM.Content},
+	}
+	for _, p := range utm {
+		if p.value != "" {
+			params = setParam(params, p.key, p.value)
+		}
+	}
+
+	if tracking.PassQuery {
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			switch tracking.QueryConflict {
+			case url_model.QueryReplace:
+				params = removeParam(params, key)
+			case url_model.QueryAppend:
+			default:
+				if hasParam(params, key) {
+					continue
+				}
+			}
+			for _, value := range query[key] {
+				params = append(params, url.QueryEscape(key)+"="+url.QueryEscape(value))
+			}
+		}
+	}
+
+	u.RawQuery = strings.Join(params, "&")
+	u.ForceQuery = false
+	final := u.String()
+	if len(final) > maxFinalURLLength {
+		return "", fmt.Errorf("%w: longer than %d characters", url_model.ErrInvalidFinalURL, maxFinalURLLength)
+	}
+	if parsed, err := url.Parse(final); err != nil || parsed.Scheme != u.Scheme || parsed.Host != u.Host {
+		return "", fmt.Errorf("%w: %s", url_model.ErrInvalidFinalURL, "result cannot be parsed")
+	}
+	return final, nil
+}
+
+// splitQuery splits a raw query into its key=value parts, dropping empty ones.
+func splitQuery(rawQuery string) []string {
+	var params []string
+	for _, param := range strings.Split(rawQuery, "&") {
+		if param != "" {
+			params = append(params, param)
+		}
+	}
+	return params
+}
+
+// paramKey returns the decoded key of a key=value part of a query.
+func paramKey(param string) string {
+	key, _, _ := strings.Cut(param, "=")
+	if decoded, err := url.QueryUnescape(key); err == nil {
+		return decoded
+	}
+	return key
+}
+
+func hasParam(params []string, key string) bool {
+	for _, param := range params {
+		if paramKey(param) == key {
+			return true
+		}
+	}
+	return false
+}
+
+func removeParam(params []string, key string) []string {
+	kept := params[:0]
+	for _, param := range params {
+		if paramKey(param) != key {
+			kept = append(kept, param)
+		}
+	}
+	return kept
+}
+
+// setParam replaces every value of the key by the value, in place of the first one.
+func setParam(params []string, key, value string) []string {
+	encoded := url.QueryEscape(key) + "=" + url.QueryEscape(value)
+	for i, param := range params {
+		if paramKey(param) == key {
+			return append(append(params[:i:i], encoded), removeParam(params[i+1:], key)...)
+		}
+	}
+	return append(params, encoded)
+}
+
+// validateTracking checks the UTM values and the conflict rule, returning them trimmed and defaulted.
+func validateTracking(tracking url_model.Tracking) (url_model.Tracking, error) {
+	values := []*string{&tracking.UTM.Source, &tracking.UTM.Medium, &tracking.UTM.Campaign, &tracking.UTM.Term, &tracking.UTM.Content}
+	for _, value := range values {
+		*value = strings.TrimSpace(*value)
+		if utf8.RuneCountInString(*value) > maxUTMLength || !utf8.ValidString(*value) || strings.IndexFunc(*value, unicode.IsControl) >= 0 {
+			return tracking, url_model.ErrInvalidUTM
+		}
+	}
+
+	switch tracking.QueryConflict {
+	case "":
+		tracking.QueryConflict = url_model.QueryKeep
+	case url_model.QueryKeep, url_model.QueryReplace, url_model.QueryAppend:
+	default:
+		return tracking, url_model.ErrInvalidQueryConflict
+	}
+	return tracking, nil
+}
@@ -0,0 +1,121 @@
+package url_service
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracking(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com/sale?utm_source=old&ref=a%20b", "sale", &owner)
+
+	t.Run("Should default to keeping parameters", func(t *testing.T) {
+		tracking, err := urlService.GetTracking(owner, "sale")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &url_model.Tracking{QueryConflict: url_model.QueryKeep}, tracking)
+	})
+
+	t.Run("Should save trimmed UTM values", func(t *testing.T) {
+		saved, err := urlService.SetTracking(owner, "sale", url_model.Tracking{UTM: url_model.UTM{Source: " newsletter ", Campaign: "black friday"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "newsletter", saved.UTM.Source)
+		assert.Equal(t, url_model.QueryKeep, saved.QueryConflict)
+
+		found, err := urlService.GetTracking(owner, "sale")
+		assert.NoError(t, err)
+		assert.Equal(t, saved, found)
+	})
+
+	t.Run("Should replace UTM parameters of the destination", func(t *testing.T) {
+		final, err := urlService.Track("sale", "https://www.example.com/sale?utm_source=old&ref=a%20b#top", url.Values{"ref": {"x"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/sale?utm_source=newsletter&ref=a%20b&utm_campaign=black+friday#top", final)
+	})
+
+	t.Run("Should pass the query with conflict rules", func(t *testing.T) {
+		query := url.Values{"ref": {"x"}, "utm_source": {"ads"}, "gclid": {"1"}}
+		tests := []struct {
+			conflict string
+			final    string
+		}{
+			{url_model.QueryKeep, "https://www.example.com/sale?utm_source=newsletter&ref=a%20b&gclid=1"},
+			{url_model.QueryReplace, "https://www.example.com/sale?gclid=1&ref=x&utm_source=ads"},
+			{url_model.QueryAppend, "https://www.example.com/sale?utm_source=newsletter&ref=a%20b&gclid=1&ref=x&utm_source=ads"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.conflict, func(t *testing.T) {
+				_, err := urlService.SetTracking(owner, "sale", url_model.Tracking{UTM: url_model.UTM{Source: "newsletter"}, PassQuery: true, QueryConflict: tt.conflict})
+				assert.NoError(t, err)
+
+				final, err := urlService.Track("sale", "https://www.example.com/sale?utm_source=old&ref=a%20b", query)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.final, final)
+			})
+		}
+	})
+
+	t.Run("Should keep the destination when the result is too long", func(t *testing.T) {
+		final, err := urlService.Track("sale", "https://www.example.com", url.Values{"q": {strings.Repeat("a", maxFinalURLLength)}})
+
+		assert.ErrorIs(t, err, url_model.ErrInvalidFinalURL)
+		assert.Equal(t, "https://www.example.com", final)
+	})
+
+	t.Run("Should dry run with the query", func(t *testing.T) {
+		match, err := urlService.DryRun(owner, "sale", url_model.Visitor{}, "", url.Values{"gclid": {"1"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/sale?utm_source=newsletter&ref=a%20b&gclid=1", match.Destination)
+	})
+
+	t.Run("Should only let editors set tracking", func(t *testing.T) {
+		_, err := urlService.SetTracking(2, "sale", url_model.Tracking{})
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+		_, err = urlService.GetTracking(2, "sale")
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+	})
+
+	t.Run("Should remove tracking adding nothing", func(t *testing.T) {
+		_, err := urlService.SetTracking(owner, "sale", url_model.Tracking{QueryConflict: url_model.QueryReplace})
+		assert.NoError(t, err)
+		assert.NotContains(t, repository.Tracking, "sale")
+
+		final, err := urlService.Track("sale", "https://www.example.com/sale", url.Values{"gclid": {"1"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "https://www.example.com/sale", final)
+	})
+}
+
+func TestTracking_Validation(t *testing.T) {
+	repository := mocks.NewMockUrlRepository()
+	urlService := NewURLService(repository, mocks.NewMockWorkspaceRepository())
+	owner := uint(1)
+	_, _ = repository.CreateURL("https://www.example.com", "sale", &owner)
+
+	tests := []struct {
+		name     string
+		tracking url_model.Tracking
+		err      error
+	}{
+		{"Long Value", url_model.Tracking{UTM: url_model.UTM{Campaign: strings.Repeat("a", maxUTMLength+1)}}, url_model.ErrInvalidUTM},
+		{"Control Character", url_model.Tracking{UTM: url_model.UTM{Term: "a\nb"}}, url_model.ErrInvalidUTM},
+		{"Unknown Conflict Rule", url_model.Tracking{PassQuery: true, QueryConflict: "merge"}, url_model.ErrInvalidQueryConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := urlService.SetTracking(owner, "sale", tt.tracking)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
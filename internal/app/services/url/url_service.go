@@ -1,25 +1,66 @@
 package url_service
 
 import (
+	"errors"
+	"math/rand"
+	"regexp"
+	"time"
 	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
 	"url-shortener/internal/app/repositories/url"
+	"url-shortener/internal/app/repositories/workspace"
 	"url-shortener/internal/utils"
 )
 
+// aliasPattern restricts custom aliases to characters that are safe in a URL path.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,32}$`)
+
 // Service provides URL-related functionalities.
 type Service struct {
 	Repository url_repository.Repository
+	// WorkspaceRepository resolves the membership checks of workspace links.
+	WorkspaceRepository workspace_repository.Repository
+	// Safety decides which destination URLs may be shortened.
+	Safety SafetyPolicy
+	// AccessKey signs the access tokens of password-protected URLs; instances sharing links must share it.
+	AccessKey []byte
+	// AccessTTL is how long an access token stays valid.
+	AccessTTL time.Duration
+	now       func() time.Time
+	// intn chooses the variants of splits for new visitors.
+	intn func(n int) int
 }
 
-// NewURLService creates a new instance of URLService with the given URL repository.
-func NewURLService(repository url_repository.Repository) *Service {
-	return &Service{Repository: repository}
+// NewURLService creates a new instance of URLService with the given URL and workspace repositories.
+// It uses DefaultSafetyPolicy and a random access key until others are set.
+func NewURLService(repository url_repository.Repository, workspaceRepository workspace_repository.Repository) *Service {
+	return &Service{
+		Repository:          repository,
+		WorkspaceRepository: workspaceRepository,
+		Safety:              DefaultSafetyPolicy(),
+		AccessKey:           randomKey(),
+		AccessTTL:           DefaultAccessTTL,
+		now:                 time.Now,
+		intn:                rand.Intn,
+	}
 }
 
 // ShortenURL generates a shortened URL for the given original URL.
 func (s *Service) ShortenURL(originalURL string, userID *uint) (string, error) {
-	// Generate a unique short code for the URL
-	shortCode := utils.GenerateShortCode(8)
+	return s.ShortenURLWithAlias(originalURL, "", userID)
+}
+
+// ShortenURLWithAlias shortens the given original URL using the alias as short code.
+// An empty alias generates a random short code.
+func (s *Service) ShortenURLWithAlias(originalURL, alias string, userID *uint) (string, error) {
+	if err := s.Safety.Check(originalURL); err != nil {
+		return "", err
+	}
+
+	shortCode, err := s.shortCode(alias)
+	if err != nil {
+		return "", err
+	}
 
 	// Save the URL in the repository
 	shortenedURL, err := s.Repository.CreateURL(originalURL, shortCode, userID)
@@ -30,6 +71,53 @@ func (s *Service) ShortenURL(originalURL string, userID *uint) (string, error) {
 	return shortenedURL, nil
 }
 
+// ShortenURLInWorkspace shortens the given original URL inside a workspace the user can edit.
+// An empty alias generates a random short code.
+func (s *Service) ShortenURLInWorkspace(originalURL, alias string, userID, workspaceID uint) (string, error) {
+	if err := s.requireMember(workspaceID, userID, workspace_model.RoleEditor); err != nil {
+		return "", err
+	}
+
+	if err := s.Safety.Check(originalURL); err != nil {
+		return "", err
+	}
+
+	shortCode, err := s.shortCode(alias)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Repository.CreateWorkspaceURL(originalURL, shortCode, userID, workspaceID)
+}
+
+// shortCode returns the alias if it is valid and free, or a random short code when it is empty.
+func (s *Service) shortCode(alias string) (string, error) {
+	if alias == "" {
+		// Generate a unique short code for the URL
+		return utils.GenerateShortCode(8), nil
+	}
+
+	if !ValidAlias(alias) {
+		return "", url_model.ErrInvalidAlias
+	}
+
+	// Check the alias is free before inserting
+	_, err := s.Repository.GetOriginalURL(alias)
+	if err == nil || errors.Is(err, url_model.ErrURLDisabled) || errors.Is(err, url_model.ErrURLExpired) || errors.Is(err, url_model.ErrURLNotActive) {
+		return "", url_model.ErrShortCodeAlreadyExists
+	}
+	if !errors.Is(err, url_model.ErrURLNotFound) {
+		return "", err
+	}
+
+	return alias, nil
+}
+
+// ValidAlias reports whether the alias may be used as a custom short code.
+func ValidAlias(alias string) bool {
+	return aliasPattern.MatchString(alias)
+}
+
 // GetOriginalURL retrieves the original URL corresponding to the given shortened URL.
 func (s *Service) GetOriginalURL(shortURL string) (string, error) {
 	// Retrieve the original URL from the repository
@@ -49,16 +137,132 @@ func (s *Service) GetUserURLs(userID uint) ([]url_model.URL, error) {
 		return nil, err
 	}
 
-	return urls, nil
+	return withStatus(urls), nil
 }
 
-// GetUserWithShortURL retrieves the user who created the given shortened URL.
-func (s *Service) GetUserWithShortURL(userId uint, shortURL string) error {
-	// Check if the user is the owner of the short URL
-	err := s.Repository.GetUserWithShortURL(userId, shortURL)
+// FindUserURLs retrieves the personal URLs of the given user matching the filter, with their tags.
+func (s *Service) FindUserURLs(userID uint, filter url_model.Filter) ([]url_model.URL, error) {
+	urls, err := s.Repository.FindUserURLs(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return withStatus(urls), nil
+}
+
+// RequireOwner checks that the URL is a personal URL of the user; tags and folders only apply to those.
+func (s *Service) RequireOwner(userID uint, shortURL string) error {
+	u, err := s.Repository.GetURL(shortURL)
 	if err != nil {
 		return err
 	}
+	if u.WorkspaceID != nil || u.UserID == 0 || u.UserID != userID {
+		return url_model.ErrForbidden
+	}
 	return nil
+}
 
+// GetWorkspaceURLs retrieves the URLs of a workspace the user is a member of.
+func (s *Service) GetWorkspaceURLs(userID, workspaceID uint) ([]url_model.URL, error) {
+	if err := s.requireMember(workspaceID, userID, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	urls, err := s.Repository.GetWorkspaceURLs(workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return withStatus(urls), nil
+}
+
+// GetUserWithShortURL checks that the user may see the given shortened URL and its analytics.
+func (s *Service) GetUserWithShortURL(userId uint, shortURL string) error {
+	return s.Authorize(userId, shortURL, workspace_model.RoleViewer)
+}
+
+// Authorize checks that the user has access to the given shortened URL.
+// Personal URLs are only accessible to their creator; workspace URLs to members with at least the given role.
+func (s *Service) Authorize(userID uint, shortURL, role string) error {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return err
+	}
+
+	return s.authorize(userID, u, role)
+}
+
+// TransferURL moves a URL between personal and workspace scope.
+// The user needs edit access to the URL and, for a target workspace, the editor role in it.
+// Taking a URL out of its workspace is left to the owners of the workspace and the user who created
+// the URL. A nil workspace makes the URL a personal URL of the user.
+func (s *Service) TransferURL(userID uint, shortURL string, workspaceID *uint) error {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return err
+	}
+	role := workspace_model.RoleEditor
+	if u.WorkspaceID != nil && u.UserID != userID && (workspaceID == nil || *workspaceID != *u.WorkspaceID) {
+		role = workspace_model.RoleOwner
+	}
+	if err := s.authorize(userID, u, role); err != nil {
+		return err
+	}
+	// Moving a URL into its own workspace changes nothing, and must not make the user its creator
+	if workspaceID != nil && u.WorkspaceID != nil && *workspaceID == *u.WorkspaceID {
+		return nil
+	}
+
+	if workspaceID != nil {
+		if err := s.requireMember(*workspaceID, userID, workspace_model.RoleEditor); err != nil {
+			return err
+		}
+	}
+
+	return s.Repository.SetOwner(shortURL, userID, workspaceID)
+}
+
+// DeleteURL deletes a URL with its clicks, settings and history, returning it as it was.
+// The user needs edit access to the URL.
+func (s *Service) DeleteURL(userID uint, shortURL string) (*url_model.URL, error) {
+	u, err := s.Repository.GetURL(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, u, workspace_model.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	if err := s.Repository.DeleteURL(shortURL); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *Service) authorize(userID uint, u *url_model.URL, role string) error {
+	if u.WorkspaceID == nil {
+		// Anonymous URLs have no owner and nobody may manage them
+		if u.UserID == 0 || u.UserID != userID {
+			return url_model.ErrForbidden
+		}
+		return nil
+	}
+
+	err := s.requireMember(*u.WorkspaceID, userID, role)
+	if errors.Is(err, workspace_model.ErrNotMember) || errors.Is(err, workspace_model.ErrInsufficientRole) {
+		return url_model.ErrForbidden
+	}
+	return err
+}
+
+// requireMember checks that the user is a member of the workspace with at least the given role.
+func (s *Service) requireMember(workspaceID, userID uint, role string) error {
+	member, err := s.WorkspaceRepository.GetMember(workspaceID, userID)
+	if err != nil {
+		return err
+	}
+	if !workspace_model.HasRole(member.Role, role) {
+		return workspace_model.ErrInsufficientRole
+	}
+	return nil
 }
@@ -3,13 +3,15 @@ package url_service
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/workspace"
 	"url-shortener/internal/mocks"
 )
 
 func TestShortenURL(t *testing.T) {
 	mockRepo := mocks.NewMockUrlRepository()
 
-	urlService := NewURLService(mockRepo)
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
 
 	t.Run("Shorten URL Successfully", func(t *testing.T) {
 		url, err := urlService.ShortenURL("https://www.example.com", nil)
@@ -24,12 +26,16 @@ func TestShortenURL(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("Should reject unsafe URL", func(t *testing.T) {
+		_, err := urlService.ShortenURL("javascript:alert(1)", nil)
+		assert.ErrorIs(t, err, url_model.ErrUnsafeURL)
+	})
 }
 
 func TestGetOriginalURL(t *testing.T) {
 	mockRepo := mocks.NewMockUrlRepository()
 
-	urlService := NewURLService(mockRepo)
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
 
 	t.Run("Get Original URL Successfully", func(t *testing.T) {
 		url, err := urlService.GetOriginalURL("success")
@@ -53,7 +59,7 @@ func TestGetOriginalURL(t *testing.T) {
 func TestGetUserUrls(t *testing.T) {
 	mockRepo := mocks.NewMockUrlRepository()
 
-	urlService := NewURLService(mockRepo)
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
 
 	t.Run("Get User URLs Successfully", func(t *testing.T) {
 		user := uint(1)
@@ -75,10 +81,91 @@ func TestGetUserUrls(t *testing.T) {
 
 }
 
+func TestFindUserURLs(t *testing.T) {
+	mockRepo := mocks.NewMockUrlRepository()
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
+
+	user, folder := uint(1), uint(4)
+	_, _ = mockRepo.CreateURL("https://www.example.com", "tagged", &user)
+	_, _ = mockRepo.CreateURL("https://www.example.org", "filed", &user)
+	mockRepo.Tags["tagged"] = []string{"news"}
+	_ = mockRepo.SetFolder("filed", &folder)
+
+	t.Run("Should filter by tag", func(t *testing.T) {
+		urls, err := urlService.FindUserURLs(1, url_model.Filter{Tag: "news"})
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+		assert.Equal(t, "tagged", urls[0].ShortenedURL)
+		assert.Equal(t, []string{"news"}, urls[0].Tags)
+	})
+
+	t.Run("Should filter by folder", func(t *testing.T) {
+		urls, err := urlService.FindUserURLs(1, url_model.Filter{FolderID: &folder})
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+		assert.Equal(t, "filed", urls[0].ShortenedURL)
+	})
+
+	t.Run("Should return an empty list when nothing matches", func(t *testing.T) {
+		urls, err := urlService.FindUserURLs(1, url_model.Filter{Tag: "missing"})
+		assert.NoError(t, err)
+		assert.Empty(t, urls)
+	})
+}
+
+func TestRequireOwner(t *testing.T) {
+	mockRepo := mocks.NewMockUrlRepository()
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
+
+	user, workspace := uint(1), uint(1)
+	_, _ = mockRepo.CreateURL("https://www.example.com", "mine", &user)
+	_, _ = mockRepo.CreateURL("https://www.example.com", "anonymous", nil)
+	_, _ = mockRepo.CreateWorkspaceURL("https://www.example.com", "shared", user, workspace)
+
+	assert.NoError(t, urlService.RequireOwner(1, "mine"))
+	assert.ErrorIs(t, urlService.RequireOwner(2, "mine"), url_model.ErrForbidden)
+	assert.ErrorIs(t, urlService.RequireOwner(0, "anonymous"), url_model.ErrForbidden)
+	assert.ErrorIs(t, urlService.RequireOwner(1, "shared"), url_model.ErrForbidden)
+	assert.ErrorIs(t, urlService.RequireOwner(1, "missing"), url_model.ErrURLNotFound)
+}
+
+func TestDeleteURL(t *testing.T) {
+	mockRepo := mocks.NewMockUrlRepository()
+	workspaceRepo := mocks.NewMockWorkspaceRepository()
+	urlService := NewURLService(mockRepo, workspaceRepo)
+
+	// User 1 owns the workspace and user 3 views
+	workspace, _ := workspaceRepo.Create(&workspace_model.Workspace{Name: "Team", CreatedBy: 1})
+	_ = workspaceRepo.AddMember(workspace.ID, 3, workspace_model.RoleViewer)
+	user := uint(1)
+	_, _ = mockRepo.CreateURL("https://www.example.com", "mine", &user)
+	_, _ = mockRepo.CreateURL("https://www.example.com", "anonymous", nil)
+	_, _ = mockRepo.CreateWorkspaceURL("https://www.example.org", "shared", user, workspace.ID)
+
+	_, err := urlService.DeleteURL(2, "mine")
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+	_, err = urlService.DeleteURL(0, "anonymous")
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+	_, err = urlService.DeleteURL(3, "shared")
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+
+	deleted, err := urlService.DeleteURL(1, "shared")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.example.org", deleted.OriginalURL)
+	assert.Equal(t, workspace.ID, *deleted.WorkspaceID)
+	_, err = urlService.DeleteURL(1, "shared")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+
+	_, err = urlService.DeleteURL(1, "mine")
+	assert.NoError(t, err)
+	_, err = urlService.GetOriginalURL("mine")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+}
+
 func TestGetUserWithShortURL(t *testing.T) {
 	mockRepo := mocks.NewMockUrlRepository()
 
-	urlService := NewURLService(mockRepo)
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
 
 	t.Run("Get User with Short URL Successfully", func(t *testing.T) {
 		user := uint(1)
@@ -97,3 +184,134 @@ func TestGetUserWithShortURL(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestShortenURLWithAlias(t *testing.T) {
+	mockRepo := mocks.NewMockUrlRepository()
+
+	urlService := NewURLService(mockRepo, mocks.NewMockWorkspaceRepository())
+
+	t.Run("Shorten URL with Alias Successfully", func(t *testing.T) {
+		shortCode, err := urlService.ShortenURLWithAlias("https://www.example.com", "my-alias", nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "my-alias", shortCode)
+	})
+
+	t.Run("Should return error for taken alias", func(t *testing.T) {
+		_, err := urlService.ShortenURLWithAlias("https://www.example.com", "success", nil)
+
+		assert.ErrorIs(t, err, url_model.ErrShortCodeAlreadyExists)
+	})
+
+	t.Run("Should return error for disabled alias", func(t *testing.T) {
+		_, _ = mockRepo.CreateURL("https://www.example.com", "disabled-alias", nil)
+		_ = mockRepo.SetDisabled("disabled-alias", true)
+
+		_, err := urlService.ShortenURLWithAlias("https://www.example.com", "disabled-alias", nil)
+
+		assert.ErrorIs(t, err, url_model.ErrShortCodeAlreadyExists)
+	})
+
+	t.Run("Should return error for invalid alias", func(t *testing.T) {
+		for _, alias := range []string{"ab", "has space", "slash/alias", "a-very-long-alias-that-is-over-32-chars"} {
+			_, err := urlService.ShortenURLWithAlias("https://www.example.com", alias, nil)
+			assert.ErrorIs(t, err, url_model.ErrInvalidAlias, alias)
+		}
+	})
+}
+
+func TestWorkspaceURLs(t *testing.T) {
+	mockRepo := mocks.NewMockUrlRepository()
+	workspaceRepo := mocks.NewMockWorkspaceRepository()
+	urlService := NewURLService(mockRepo, workspaceRepo)
+
+	// User 1 owns the workspace, user 2 edits and user 3 views
+	workspace, _ := workspaceRepo.Create(&workspace_model.Workspace{Name: "Team", CreatedBy: 1})
+	_ = workspaceRepo.AddMember(workspace.ID, 2, workspace_model.RoleEditor)
+	_ = workspaceRepo.AddMember(workspace.ID, 3, workspace_model.RoleViewer)
+
+	t.Run("Should shorten URL in workspace", func(t *testing.T) {
+		shortCode, err := urlService.ShortenURLInWorkspace("https://www.example.com", "team-link", 2, workspace.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "team-link", shortCode)
+
+		urls, err := urlService.GetWorkspaceURLs(3, workspace.ID)
+		assert.NoError(t, err)
+		assert.Len(t, urls, 1)
+	})
+
+	t.Run("Should reject viewers and non-members", func(t *testing.T) {
+		_, err := urlService.ShortenURLInWorkspace("https://www.example.com", "", 3, workspace.ID)
+		assert.ErrorIs(t, err, workspace_model.ErrInsufficientRole)
+
+		_, err = urlService.ShortenURLInWorkspace("https://www.example.com", "", 4, workspace.ID)
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+
+		_, err = urlService.GetWorkspaceURLs(4, workspace.ID)
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+	})
+
+	t.Run("Should authorize members by role", func(t *testing.T) {
+		assert.NoError(t, urlService.GetUserWithShortURL(3, "team-link"))
+		assert.NoError(t, urlService.Authorize(2, "team-link", workspace_model.RoleEditor))
+		assert.ErrorIs(t, urlService.Authorize(3, "team-link", workspace_model.RoleEditor), url_model.ErrForbidden)
+		assert.ErrorIs(t, urlService.GetUserWithShortURL(4, "team-link"), url_model.ErrForbidden)
+	})
+
+	t.Run("Should not authorize anyone for anonymous URLs", func(t *testing.T) {
+		_, _ = mockRepo.CreateURL("https://www.example.com", "anonymous", nil)
+
+		assert.ErrorIs(t, urlService.GetUserWithShortURL(0, "anonymous"), url_model.ErrForbidden)
+	})
+
+	t.Run("Should transfer personal URL into workspace and back", func(t *testing.T) {
+		userID := uint(2)
+		_, _ = mockRepo.CreateURL("https://www.example.com", "mine", &userID)
+
+		err := urlService.TransferURL(2, "mine", &workspace.ID)
+		assert.NoError(t, err)
+		assert.NoError(t, urlService.GetUserWithShortURL(3, "mine"))
+
+		err = urlService.TransferURL(1, "mine", nil)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, urlService.GetUserWithShortURL(2, "mine"), url_model.ErrForbidden)
+		assert.NoError(t, urlService.GetUserWithShortURL(1, "mine"))
+	})
+
+	t.Run("Should reject transfers without edit access", func(t *testing.T) {
+		userID := uint(3)
+		_, _ = mockRepo.CreateURL("https://www.example.com", "viewers", &userID)
+
+		// Viewers cannot move links into the workspace
+		err := urlService.TransferURL(3, "viewers", &workspace.ID)
+		assert.ErrorIs(t, err, workspace_model.ErrInsufficientRole)
+
+		// Nor take workspace links out of it
+		err = urlService.TransferURL(3, "team-link", nil)
+		assert.ErrorIs(t, err, url_model.ErrForbidden)
+
+		err = urlService.TransferURL(3, "missing", nil)
+		assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	})
+
+	t.Run("Should leave taking links out of a workspace to owners and creators", func(t *testing.T) {
+		_, err := urlService.ShortenURLInWorkspace("https://www.example.com", "owners-link", 1, workspace.ID)
+		assert.NoError(t, err)
+		other, _ := workspaceRepo.Create(&workspace_model.Workspace{Name: "Other", CreatedBy: 2})
+
+		// Editors cannot take the links of others into their own scope
+		assert.ErrorIs(t, urlService.TransferURL(2, "owners-link", nil), url_model.ErrForbidden)
+		assert.ErrorIs(t, urlService.TransferURL(2, "owners-link", &other.ID), url_model.ErrForbidden)
+		assert.NoError(t, urlService.TransferURL(2, "owners-link", &workspace.ID))
+		assert.ErrorIs(t, urlService.TransferURL(2, "owners-link", nil), url_model.ErrForbidden)
+		assert.NoError(t, urlService.TransferURL(1, "owners-link", nil))
+
+		// Creators can take their links back
+		_, err = urlService.ShortenURLInWorkspace("https://www.example.com", "editors-link", 2, workspace.ID)
+		assert.NoError(t, err)
+		assert.NoError(t, urlService.TransferURL(2, "editors-link", nil))
+		assert.NoError(t, urlService.GetUserWithShortURL(2, "editors-link"))
+		assert.ErrorIs(t, urlService.GetUserWithShortURL(3, "editors-link"), url_model.ErrForbidden)
+	})
+}
@@ -0,0 +1,586 @@
+package webhook_service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	url_model "url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/repositories/webhook"
+	"url-shortener/internal/app/services/metadata"
+	"url-shortener/internal/app/services/url"
+)
+
+// Defaults of the delivery queue, used until others are set.
+const (
+	DefaultMaxAttempts  = 8
+	DefaultBaseDelay    = 30 * time.Second
+	DefaultMaxDelay     = 6 * time.Hour
+	DefaultTimeout      = 10 * time.Second
+	DefaultPollInterval = 5 * time.Second
+	// DefaultClickQueueSize is the number of clicks waiting to be published before new ones are dropped.
+	DefaultClickQueueSize = 1000
+)
+
+// batchSize is the number of due deliveries claimed at once.
+const batchSize = 20
+
+// deliveryLimit is the number of deliveries listed for a webhook.
+const deliveryLimit = 100
+
+// maxErrorBody is the part of a failed response body kept as the error of the attempt, in bytes.
+const maxErrorBody = 256
+
+// Service manages the webhooks of users and workspaces and delivers their events.
+// A nil Service is disabled: publishing does nothing.
+type Service struct {
+	Repository webhook_repository.Repository
+	// URLService looks up the links of events and checks webhook URLs and workspace roles.
+	URLService *url_service.Service
+	// Client sends the deliveries; it must not follow redirects, which would resend the signed body elsewhere.
+	Client *http.Client
+	// MaxAttempts is the number of attempts of a delivery before it is dead.
+	MaxAttempts int
+	// BaseDelay is the wait after the first failed attempt, doubled after each further one up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// PollInterval is how often Run looks for due deliveries.
+	PollInterval time.Duration
+	wake         chan struct{}
+	clicks       chan webhook_model.Click
+	now          func() time.Time
+}
+
+// NewWebhookService creates a new instance of WebhookService with the given webhook repository and URL service.
+// It delivers with NewHTTPClient(DefaultTimeout) and the default retry schedule until others are set, with room
+// for DefaultClickQueueSize clicks waiting to be published.
+func NewWebhookService(repository webhook_repository.Repository, urlService *url_service.Service) *Service {
+	return &Service{
+		Repository:   repository,
+		URLService:   urlService,
+		Client:       NewHTTPClient(DefaultTimeout),
+		MaxAttempts:  DefaultMaxAttempts,
+		BaseDelay:    DefaultBaseDelay,
+		MaxDelay:     DefaultMaxDelay,
+		PollInterval: DefaultPollInterval,
+		wake:         make(chan struct{}, 1),
+		clicks:       make(chan webhook_model.Click, DefaultClickQueueSize),
+		now:          time.Now,
+	}
+}
+
+// NewHTTPClient returns a client for delivering events that gives up after the timeout, does not follow
+// redirects and refuses to connect to private addresses.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	client := metadata_service.NewHTTPClient(timeout)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return client
+}
+
+// Sign returns the value of the signature header of a delivery sent at the Unix timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ListWebhooks returns the personal webhooks of the user and the webhooks of the workspaces they own, by ID.
+func (s *Service) ListWebhooks(userID uint) ([]webhook_model.Webhook, error) {
+	webhooks, err := s.Repository.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// GetWebhook returns a webhook the user manages, without its secret.
+func (s *Service) GetWebhook(userID, webhookID uint) (*webhook_model.Webhook, error) {
+	webhook, err := s.webhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// CreateWebhook registers a webhook for the user, or for the workspace when one is given, returning it
+// with the secret signing its deliveries. Workspace webhooks are registered by workspace owners.
+func (s *Service) CreateWebhook(userID uint, req webhook_model.Request) (*webhook_model.Webhook, error) {
+	events, err := validEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.URLService.Safety.Check(req.URL); err != nil {
+		return nil, err
+	}
+	webhook := &webhook_model.Webhook{URL: req.URL, UserID: userID, WorkspaceID: req.WorkspaceID, Events: events, Active: true}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+	if err := s.authorize(userID, webhook); err != nil {
+		return nil, err
+	}
+
+	if webhook.Secret, err = randomID("whsec_"); err != nil {
+		return nil, err
+	}
+	return s.Repository.Create(webhook)
+}
+
+// UpdateWebhook changes the URL, events and state of a webhook the user manages. An empty URL and
+// omitted events or state are left unchanged.
+func (s *Service) UpdateWebhook(userID, webhookID uint, req webhook_model.Request) (*webhook_model.Webhook, error) {
+	webhook, err := s.webhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := s.URLService.Safety.Check(req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = req.URL
+	}
+	if req.Events != nil {
+		if webhook.Events, err = validEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := s.Repository.Update(webhook); err != nil {
+		return nil, err
+	}
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// DeleteWebhook deletes a webhook the user manages with its deliveries.
+func (s *Service) DeleteWebhook(userID, webhookID uint) error {
+	webhook, err := s.webhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+	return s.Repository.Delete(webhook.ID)
+}
+
+// ListDeliveries returns the latest deliveries of a webhook the user manages, newest first, only those
+// with the status when one is given; the dead ones make up its dead-letter list.
+func (s *Service) ListDeliveries(userID, webhookID uint, status string) ([]webhook_model.Delivery, error) {
+	if status != "" && !contains(webhook_model.Statuses, status) {
+		return nil, webhook_model.ErrInvalidStatus
+	}
+	webhook, err := s.webhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repository.ListDeliveries(webhook.ID, status, deliveryLimit)
+}
+
+// GetDelivery returns a delivery of a webhook the user manages, with the log of its attempts.
+func (s *Service) GetDelivery(userID, deliveryID uint) (*webhook_model.Delivery, error) {
+	delivery, err := s.delivery(userID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Log, err = s.Repository.ListAttempts(delivery.ID); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// RetryDelivery queues a dead or failed delivery of a webhook the user manages again, with a fresh
+// set of attempts.
+func (s *Service) RetryDelivery(userID, deliveryID uint) (*webhook_model.Delivery, error) {
+	delivery, err := s.delivery(userID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Repository.Requeue(delivery.ID, s.now().UTC()); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return s.Repository.GetDelivery(delivery.ID)
+}
+
+// SendTest sends a webhook.test event to a webhook the user manages right away, once, returning the
+// delivery as delivered or failed. Test events are sent to inactive webhooks too.
+func (s *Service) SendTest(ctx context.Context, userID, webhookID uint) (*webhook_model.Delivery, error) {
+	webhook, err := s.webhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := s.event(webhook_model.EventTest, map[string]uint{"webhook_id": webhook.ID})
+	if err != nil {
+		return nil, err
+	}
+	// The lease keeps the delivery worker away from it while it is sent here
+	delivery, err := s.queue(webhook, event, s.now().UTC().Add(s.lease()))
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := s.send(ctx, webhook, delivery)
+	delivery.Attempts = 1
+	delivery.ResponseStatus, delivery.Error = attempt.ResponseStatus, attempt.Error
+	delivery.Status = webhook_model.StatusDelivered
+	if attempt.Error != "" {
+		delivery.Status = webhook_model.StatusFailed
+	}
+	if err := s.Repository.RecordAttempt(delivery, attempt); err != nil {
+		return nil, err
+	}
+	delivery.Log = []webhook_model.Attempt{attempt}
+	return delivery, nil
+}
+
+// PublishLink queues an event about the link with the short code for the webhooks of its owner, with
+// the link as data. Errors are logged; they never fail the change that raised the event.
+func (s *Service) PublishLink(eventType, shortCode string) {
+	if s == nil {
+		return
+	}
+	u, err := s.URLService.Repository.GetURL(shortCode)
+	if err != nil {
+		fmt.Printf("[WEBHOOKS] Error looking up %s for %s: %v\n", shortCode, eventType, err)
+		return
+	}
+	s.Publish(eventType, u, u)
+}
+
+// PublishClick queues the click for RunClicks, which publishes it as a link.clicked event for the webhooks of the
+// owner of the clicked link, so redirects do not wait on the lookups. It reports false when the service is
+// disabled or the queue is full, in which case the click is not published.
+func (s *Service) PublishClick(click webhook_model.Click) bool {
+	if s == nil {
+		return false
+	}
+
+	select {
+	case s.clicks <- click:
+		return true
+	default:
+		return false
+	}
+}
+
+// RunClicks publishes the queued clicks one at a time until stop is closed.
+func (s *Service) RunClicks(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case click := <-s.clicks:
+			s.publishClick(click)
+		}
+	}
+}
+
+// publishClick queues a link.clicked event for the webhooks of the owner of the clicked link.
+func (s *Service) publishClick(click webhook_model.Click) {
+	u, err := s.URLService.Repository.GetURL(click.ShortenedURL)
+	if err != nil {
+		fmt.Printf("[WEBHOOKS] Error looking up %s for %s: %v\n", click.ShortenedURL, webhook_model.EventLinkClicked, err)
+		return
+	}
+	s.Publish(webhook_model.EventLinkClicked, u, click)
+}
+
+// Publish queues an event about the link for the webhooks subscribed to it: those of its workspace for
+// workspace links, those of its creator for personal links. Anonymous links have no webhooks.
+func (s *Service) Publish(eventType string, u *url_model.URL, data interface{}) {
+	if s == nil || (u.WorkspaceID == nil && u.UserID == 0) {
+		return
+	}
+
+	webhooks, err := s.Repository.Subscribers(u.UserID, u.WorkspaceID)
+	if err != nil {
+		fmt.Printf("[WEBHOOKS] Error listing the webhooks of %s: %v\n", u.ShortenedURL, err)
+		return
+	}
+	var event *webhook_model.Event
+	queued := false
+	for i := range webhooks {
+		if !webhooks[i].Subscribes(eventType) {
+			continue
+		}
+		// Every webhook receives the same event, so receivers subscribed twice can tell
+		if event == nil {
+			if event, err = s.event(eventType, data); err != nil {
+				fmt.Printf("[WEBHOOKS] Error creating %s event of %s: %v\n", eventType, u.ShortenedURL, err)
+				return
+			}
+		}
+		if _, err := s.queue(&webhooks[i], event, event.CreatedAt); err != nil {
+			fmt.Printf("[WEBHOOKS] Error queueing %s for webhook %d: %v\n", eventType, webhooks[i].ID, err)
+			continue
+		}
+		queued = true
+	}
+	if queued {
+		s.notify()
+	}
+}
+
+// Run delivers the due deliveries every PollInterval, and as soon as events are published, until
+// stop is closed.
+func (s *Service) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		if _, err := s.DeliverDue(context.Background()); err != nil {
+			fmt.Printf("[WEBHOOKS] Error delivering events: %v\n", err)
+		}
+	}
+}
+
+// DeliverDue sends the pending deliveries whose next attempt is due, batch after batch, returning how
+// many were attempted. Failed attempts are retried with exponential backoff until MaxAttempts, after
+// which the delivery is dead. Deliveries queued before a webhook was deactivated are still sent.
+func (s *Service) DeliverDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		deliveries, err := s.Repository.ClaimDue(s.now().UTC(), s.lease(), batchSize)
+		if err != nil {
+			return attempted, err
+		}
+		for i := range deliveries {
+			if err := s.deliver(ctx, &deliveries[i]); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+		if len(deliveries) < batchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// deliver attempts a claimed delivery and schedules its next attempt when it fails.
+func (s *Service) deliver(ctx context.Context, delivery *webhook_model.Delivery) error {
+	webhook, err := s.Repository.GetByID(delivery.WebhookID)
+	if errors.Is(err, webhook_model.ErrWebhookNotFound) {
+		// The webhook was deleted with its deliveries after they were claimed
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	attempt := s.send(ctx, webhook, delivery)
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.Error = attempt.ResponseStatus, attempt.Error
+	switch {
+	case attempt.Error == "":
+		delivery.Status = webhook_model.StatusDelivered
+	case delivery.Attempts >= s.MaxAttempts:
+		delivery.Status = webhook_model.StatusDead
+	default:
+		delivery.NextAttemptAt = attempt.AttemptedAt.Add(s.backoff(delivery.Attempts))
+	}
+	return s.Repository.RecordAttempt(delivery, attempt)
+}
+
+// send makes one signed request of a delivery to its webhook; attempts answered with a status other
+// than 2xx, or not answered, have an error.
+func (s *Service) send(ctx context.Context, webhook *webhook_model.Webhook, delivery *webhook_model.Delivery) webhook_model.Attempt {
+	start := s.now().UTC()
+	status, err := s.post(ctx, webhook, delivery, start)
+	attempt := webhook_model.Attempt{
+		ResponseStatus: status,
+		Duration:       s.now().Sub(start).Milliseconds(),
+		AttemptedAt:    start.Truncate(time.Second),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt
+}
+
+// post sends the payload of a delivery signed for the time, returning the response status.
+func (s *Service) post(ctx context.Context, webhook *webhook_model.Webhook, delivery *webhook_model.Delivery, at time.Time) (int, error) {
+	// The URL was checked when it was saved, but the policy may have changed since
+	if err := s.URLService.Safety.Check(webhook.URL); err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, strings.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "url-shortener-webhooks/1.0")
+	req.Header.Set(webhook_model.HeaderEvent, delivery.EventType)
+	req.Header.Set(webhook_model.HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(webhook_model.HeaderTimestamp, timestamp)
+	req.Header.Set(webhook_model.HeaderSignature, Sign(webhook.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, errors.New(strings.TrimSpace(fmt.Sprintf("status %d %s", resp.StatusCode, excerpt)))
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the wait after the failed attempt with the number: BaseDelay doubled for each
+// attempt after the first, capped at MaxDelay.
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.BaseDelay
+	for i := 1; i < attempts && delay < s.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > s.MaxDelay {
+		return s.MaxDelay
+	}
+	return delay
+}
+
+// lease is how long claimed deliveries are left alone, enough for a request to time out.
+func (s *Service) lease() time.Duration {
+	if s.Client.Timeout > 0 {
+		return 2 * s.Client.Timeout
+	}
+	return time.Minute
+}
+
+// notify wakes Run up without waiting for the poll interval.
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// event returns a new event of the type with its JSON encoding as payload.
+func (s *Service) event(eventType string, data interface{}) (*webhook_model.Event, error) {
+	id, err := randomID("evt_")
+	if err != nil {
+		return nil, err
+	}
+	return &webhook_model.Event{ID: id, Type: eventType, CreatedAt: s.now().UTC().Truncate(time.Second), Data: data}, nil
+}
+
+// queue stores a pending delivery of the event to the webhook, first attempted at the time.
+func (s *Service) queue(webhook *webhook_model.Webhook, event *webhook_model.Event, at time.Time) (*webhook_model.Delivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	return s.Repository.CreateDelivery(&webhook_model.Delivery{
+		WebhookID:     webhook.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       string(payload),
+		Status:        webhook_model.StatusPending,
+		NextAttemptAt: at,
+	})
+}
+
+// webhook returns a webhook the user manages.
+func (s *Service) webhook(userID, webhookID uint) (*webhook_model.Webhook, error) {
+	webhook, err := s.Repository.GetByID(webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(userID, webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// delivery returns a delivery of a webhook the user manages.
+func (s *Service) delivery(userID, deliveryID uint) (*webhook_model.Delivery, error) {
+	delivery, err := s.Repository.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.webhook(userID, delivery.WebhookID); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// authorize checks the user manages the webhook: personal webhooks only the user who registered them,
+// workspace webhooks the owners of the workspace.
+func (s *Service) authorize(userID uint, webhook *webhook_model.Webhook) error {
+	if webhook.WorkspaceID == nil {
+		if webhook.UserID != userID {
+			return url_model.ErrForbidden
+		}
+		return nil
+	}
+
+	member, err := s.URLService.WorkspaceRepository.GetMember(*webhook.WorkspaceID, userID)
+	if errors.Is(err, workspace_model.ErrNotMember) {
+		return url_model.ErrForbidden
+	}
+	if err != nil {
+		return err
+	}
+	if !workspace_model.HasRole(member.Role, workspace_model.RoleOwner) {
+		return url_model.ErrForbidden
+	}
+	return nil
+}
+
+// validEvents checks the event types can be subscribed to, dropping duplicates.
+func validEvents(events []string) ([]string, error) {
+	valid := make([]string, 0, len(events))
+	for _, event := range events {
+		if !contains(webhook_model.Events, event) {
+			return nil, fmt.Errorf("%w: %q", webhook_model.ErrInvalidEvent, event)
+		}
+		if !contains(valid, event) {
+			valid = append(valid, event)
+		}
+	}
+	return valid, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// randomID returns the prefix followed by 16 random bytes in hex.
+func randomID(prefix string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,327 @@
+package webhook_service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/url"
+	"url-shortener/internal/app/models/webhook"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver records the requests of a webhook endpoint, answering with its status.
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+	_, _ = w.Write([]byte("receiver says hi"))
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+// deliverTo points the HTTP client of the service at the receiver, answering for every hostname.
+func deliverTo(t *testing.T, service *Service, r *receiver) {
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	service.Client.Transport = &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		},
+	}
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// User 1 owns workspace 1, in which user 2 is an editor
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	workspaceRepository.Workspaces[1] = &workspace_model.Workspace{ID: 1, Name: "Team"}
+	workspaceRepository.Members[1] = map[uint]string{1: workspace_model.RoleOwner, 2: workspace_model.RoleEditor}
+	repository := mocks.NewMockWebhookRepository()
+	repository.Members = workspaceRepository.Members
+
+	service := NewWebhookService(repository, url_service.NewURLService(mocks.NewMockUrlRepository(), workspaceRepository))
+	workspaceID := uint(1)
+
+	_, err := service.CreateWebhook(1, webhook_model.Request{URL: "https://hooks.example.com", Events: []string{"link.exploded"}})
+	assert.ErrorIs(t, err, webhook_model.ErrInvalidEvent)
+	_, err = service.CreateWebhook(1, webhook_model.Request{URL: "http://127.0.0.1/hook"})
+	var rejection *url_model.Rejection
+	assert.ErrorAs(t, err, &rejection)
+	_, err = service.CreateWebhook(2, webhook_model.Request{URL: "https://hooks.example.com", WorkspaceID: &workspaceID})
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+
+	webhook, err := service.CreateWebhook(1, webhook_model.Request{
+		URL:    "https://hooks.example.com",
+		Events: []string{webhook_model.EventLinkCreated, webhook_model.EventLinkCreated},
+	})
+	assert.NoError(t, err)
+	assert.True(t, webhook.Active)
+	assert.True(t, strings.HasPrefix(webhook.Secret, "whsec_"))
+	assert.Equal(t, []string{webhook_model.EventLinkCreated}, webhook.Events)
+
+	webhooks, err := service.ListWebhooks(1)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 1)
+	assert.Empty(t, webhooks[0].Secret)
+	_, err = service.GetWebhook(2, webhook.ID)
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+
+	inactive := false
+	updated, err := service.UpdateWebhook(1, webhook.ID, webhook_model.Request{Events: []string{}, Active: &inactive})
+	assert.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Empty(t, updated.Events)
+	assert.Equal(t, "https://hooks.example.com", updated.URL)
+
+	assert.ErrorIs(t, service.DeleteWebhook(2, webhook.ID), url_model.ErrForbidden)
+	assert.NoError(t, service.DeleteWebhook(1, webhook.ID))
+	_, err = service.GetWebhook(1, webhook.ID)
+	assert.ErrorIs(t, err, webhook_model.ErrWebhookNotFound)
+}
+
+func TestPublishAndDeliver(t *testing.T) {
+	// User 1 has the personal link "mine" and the workspace link "team"
+	workspaceID := uint(1)
+	urlRepository := mocks.NewMockUrlRepository()
+	urlRepository.Urls[1] = &url_model.URL{OriginalURL: "https://example.com", ShortenedURL: "mine", UserID: 1}
+	urlRepository.Urls[2] = &url_model.URL{OriginalURL: "https://example.org", ShortenedURL: "team", UserID: 2, WorkspaceID: &workspaceID}
+	urlRepository.Urls[3] = &url_model.URL{OriginalURL: "https://example.net", ShortenedURL: "anon"}
+	workspaceRepository := mocks.NewMockWorkspaceRepository()
+	workspaceRepository.Workspaces[1] = &workspace_model.Workspace{ID: 1, Name: "Team"}
+	workspaceRepository.Members[1] = map[uint]string{1: workspace_model.RoleOwner, 2: workspace_model.RoleEditor}
+	repository := mocks.NewMockWebhookRepository()
+	repository.Members = workspaceRepository.Members
+
+	service := NewWebhookService(repository, url_service.NewURLService(urlRepository, workspaceRepository))
+	r := &receiver{status: http.StatusOK}
+	deliverTo(t, service, r)
+	personal, _ := service.CreateWebhook(1, webhook_model.Request{URL: "http://hooks.example.com/personal", Events: []string{webhook_model.EventLinkCreated}})
+	shared, _ := service.CreateWebhook(1, webhook_model.Request{URL: "http://hooks.example.com/team", WorkspaceID: &workspaceID})
+
+	service.PublishLink(webhook_model.EventLinkCreated, "mine")
+	assert.True(t, service.PublishClick(webhook_model.Click{ShortenedURL: "mine", Destination: "https://example.com"}))
+	assert.True(t, service.PublishClick(webhook_model.Click{ShortenedURL: "team", Destination: "https://example.org", Country: "NL"}))
+	service.PublishLink(webhook_model.EventLinkUpdated, "anon")
+	service.PublishLink(webhook_model.EventLinkUpdated, "missing")
+	var disabled *Service
+	disabled.PublishLink(webhook_model.EventLinkCreated, "mine")
+	assert.False(t, disabled.PublishClick(webhook_model.Click{ShortenedURL: "mine"}))
+	// The click worker publishes the queued clicks
+	for len(service.clicks) > 0 {
+		service.publishClick(<-service.clicks)
+	}
+
+	attempted, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempted)
+	assert.Len(t, r.requests, 2)
+
+	for i, req := range r.requests {
+		secret := personal.Secret
+		if req.URL.Path == "/team" {
+			secret = shared.Secret
+		}
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, Sign(secret, req.Header.Get(webhook_model.HeaderTimestamp), r.bodies[i]), req.Header.Get(webhook_model.HeaderSignature))
+		assert.NotEmpty(t, req.Header.Get(webhook_model.HeaderDelivery))
+
+		var event struct {
+			ID   string          `json:"id"`
+			Type string          `json:"type"`
+			Data json.RawMessage `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(r.bodies[i], &event))
+		assert.Equal(t, req.Header.Get(webhook_model.HeaderEvent), event.Type)
+		assert.True(t, strings.HasPrefix(event.ID, "evt_"))
+		if req.URL.Path == "/team" {
+			assert.Equal(t, webhook_model.EventLinkClicked, event.Type)
+			assert.Contains(t, string(event.Data), `"country":"NL"`)
+		} else {
+			assert.Equal(t, webhook_model.EventLinkCreated, event.Type)
+			assert.Contains(t, string(event.Data), `"shortened_url":"mine"`)
+		}
+	}
+
+	delivered, _ := repository.ListDeliveries(personal.ID, webhook_model.StatusDelivered, 10)
+	assert.Len(t, delivered, 1)
+	assert.Equal(t, 1, delivered[0].Attempts)
+	assert.Equal(t, http.StatusOK, delivered[0].ResponseStatus)
+
+	// Nothing is left to deliver
+	attempted, err = service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, attempted)
+}
+
+func TestRunClicks(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	urlRepository.Urls[1] = &url_model.URL{OriginalURL: "https://example.com", ShortenedURL: "mine", UserID: 1}
+	repository := mocks.NewMockWebhookRepository()
+
+	service := NewWebhookService(repository, url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository()))
+	webhook, _ := service.CreateWebhook(1, webhook_model.Request{URL: "https://hooks.example.com", Events: []string{webhook_model.EventLinkClicked}})
+	service.clicks = make(chan webhook_model.Click, 1)
+
+	assert.True(t, service.PublishClick(webhook_model.Click{ShortenedURL: "mine", Destination: "https://example.com"}))
+	// The queue holds a single click
+	assert.False(t, service.PublishClick(webhook_model.Click{ShortenedURL: "mine", Destination: "https://example.com"}))
+	// Nothing is looked up or queued before the worker runs
+	deliveries, _ := repository.ListDeliveries(webhook.ID, "", 10)
+	assert.Empty(t, deliveries)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		service.RunClicks(stop)
+		close(done)
+	}()
+	// RunClicks finishes the click it took from the queue before stopping
+	assert.Eventually(t, func() bool { return len(service.clicks) == 0 }, time.Second, 10*time.Millisecond)
+	close(stop)
+	<-done
+
+	deliveries, _ = repository.ListDeliveries(webhook.ID, "", 10)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, webhook_model.EventLinkClicked, deliveries[0].EventType)
+	}
+}
+
+func TestDeliverDue_RetriesAndDeadLetters(t *testing.T) {
+	urlRepository := mocks.NewMockUrlRepository()
+	urlRepository.Urls[1] = &url_model.URL{OriginalURL: "https://example.com", ShortenedURL: "mine", UserID: 1}
+	repository := mocks.NewMockWebhookRepository()
+
+	service := NewWebhookService(repository, url_service.NewURLService(urlRepository, mocks.NewMockWorkspaceRepository()))
+	r := &receiver{status: http.StatusOK}
+	deliverTo(t, service, r)
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	service.MaxAttempts = 3
+	service.BaseDelay = time.Minute
+	r.setStatus(http.StatusInternalServerError)
+	webhook, _ := service.CreateWebhook(1, webhook_model.Request{URL: "http://hooks.example.com"})
+
+	service.PublishLink(webhook_model.EventLinkDeleted, "mine")
+	for i, wait := range []time.Duration{time.Minute, 2 * time.Minute} {
+		attempted, err := service.DeliverDue(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+		deliveries, _ := service.ListDeliveries(1, webhook.ID, webhook_model.StatusPending)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, i+1, deliveries[0].Attempts)
+		assert.Equal(t, now.Add(wait), deliveries[0].NextAttemptAt)
+		assert.Contains(t, deliveries[0].Error, "status 500 receiver says hi")
+
+		// The next attempt waits for the backoff
+		attempted, _ = service.DeliverDue(context.Background())
+		assert.Zero(t, attempted)
+		now = now.Add(wait)
+	}
+
+	attempted, err := service.DeliverDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, attempted)
+	dead, err := service.ListDeliveries(1, webhook.ID, webhook_model.StatusDead)
+	assert.NoError(t, err)
+	assert.Len(t, dead, 1)
+	_, err = service.ListDeliveries(1, webhook.ID, "lost")
+	assert.ErrorIs(t, err, webhook_model.ErrInvalidStatus)
+
+	delivery, err := service.GetDelivery(1, dead[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, delivery.Log, 3)
+	assert.Equal(t, http.StatusInternalServerError, delivery.Log[2].ResponseStatus)
+	_, err = service.GetDelivery(2, dead[0].ID)
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+
+	// Retried dead letters get a fresh set of attempts
+	r.setStatus(http.StatusNoContent)
+	retried, err := service.RetryDelivery(1, delivery.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook_model.StatusPending, retried.Status)
+	assert.Zero(t, retried.Attempts)
+	_, err = service.RetryDelivery(1, delivery.ID)
+	assert.ErrorIs(t, err, webhook_model.ErrDeliveryNotFailed)
+
+	attempted, _ = service.DeliverDue(context.Background())
+	assert.Equal(t, 1, attempted)
+	delivery, _ = service.GetDelivery(1, delivery.ID)
+	assert.Equal(t, webhook_model.StatusDelivered, delivery.Status)
+	assert.Len(t, delivery.Log, 4)
+	assert.Len(t, r.requests, 4)
+}
+
+func TestSendTest(t *testing.T) {
+	repository := mocks.NewMockWebhookRepository()
+
+	service := NewWebhookService(repository, url_service.NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository()))
+	r := &receiver{status: http.StatusOK}
+	deliverTo(t, service, r)
+	inactive := false
+	webhook, _ := service.CreateWebhook(1, webhook_model.Request{URL: "http://hooks.example.com", Active: &inactive})
+
+	delivery, err := service.SendTest(context.Background(), 1, webhook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook_model.StatusDelivered, delivery.Status)
+	assert.Equal(t, webhook_model.EventTest, delivery.EventType)
+	assert.Len(t, delivery.Log, 1)
+	assert.Equal(t, webhook_model.EventTest, r.requests[0].Header.Get(webhook_model.HeaderEvent))
+
+	r.setStatus(http.StatusGone)
+	delivery, err = service.SendTest(context.Background(), 1, webhook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, webhook_model.StatusFailed, delivery.Status)
+	assert.Equal(t, http.StatusGone, delivery.ResponseStatus)
+
+	// Test deliveries are not retried by the worker
+	attempted, _ := service.DeliverDue(context.Background())
+	assert.Zero(t, attempted)
+
+	_, err = service.SendTest(context.Background(), 2, webhook.ID)
+	assert.ErrorIs(t, err, url_model.ErrForbidden)
+}
+
+func TestBackoff(t *testing.T) {
+	service := &Service{BaseDelay: 30 * time.Second, MaxDelay: 10 * time.Minute}
+	tests := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		4:  4 * time.Minute,
+		5:  8 * time.Minute,
+		6:  10 * time.Minute,
+		60: 10 * time.Minute,
+	}
+	for attempts, expected := range tests {
+		assert.Equal(t, expected, service.backoff(attempts), "attempt %d", attempts)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1700000000.{}' | openssl dgst -sha256 -hmac whsec_test
+	assert.Equal(t, "sha256=35495024f4ef3f94e5a93e22221544c4b75e9a42300cd965ab81cb85cd994e91", Sign("whsec_test", "1700000000", []byte("{}")))
+}
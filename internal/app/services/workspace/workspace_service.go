@@ -0,0 +1,153 @@
+package workspace_service
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/app/repositories/auth"
+	"url-shortener/internal/app/repositories/workspace"
+)
+
+// Service provides workspace and membership functionalities.
+type Service struct {
+	Repository     workspace_repository.Repository
+	UserRepository auth_repository.Repository
+}
+
+// NewWorkspaceService creates a new instance of WorkspaceService with the given workspace and user repositories.
+func NewWorkspaceService(repository workspace_repository.Repository, userRepository auth_repository.Repository) *Service {
+	return &Service{Repository: repository, UserRepository: userRepository}
+}
+
+// CreateWorkspace creates a workspace owned by the given user.
+func (s *Service) CreateWorkspace(userID uint, name string) (*workspace_model.Workspace, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, workspace_model.ErrInvalidName
+	}
+
+	return s.Repository.Create(&workspace_model.Workspace{Name: name, CreatedBy: userID})
+}
+
+// ListWorkspaces returns the workspaces the user is a member of, with their role in each.
+func (s *Service) ListWorkspaces(userID uint) ([]workspace_model.Workspace, error) {
+	return s.Repository.ListByUser(userID)
+}
+
+// GetMembers returns the members of a workspace the user is a member of.
+func (s *Service) GetMembers(userID, workspaceID uint) ([]workspace_model.Member, error) {
+	if _, err := s.requireRole(workspaceID, userID, workspace_model.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.ListMembers(workspaceID)
+}
+
+// AddMember adds the user with the given username to a workspace. Only owners may add members.
+func (s *Service) AddMember(userID, workspaceID uint, username, role string) (*workspace_model.Member, error) {
+	if !workspace_model.ValidRole(role) {
+		return nil, workspace_model.ErrInvalidRole
+	}
+	if _, err := s.requireRole(workspaceID, userID, workspace_model.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	user, err := s.UserRepository.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the user is not a member yet before inserting
+	_, err = s.Repository.GetMember(workspaceID, user.ID)
+	if err == nil {
+		return nil, workspace_model.ErrMemberAlreadyExists
+	}
+	if !errors.Is(err, workspace_model.ErrNotMember) {
+		return nil, err
+	}
+
+	if err := s.Repository.AddMember(workspaceID, user.ID, role); err != nil {
+		return nil, err
+	}
+
+	return s.Repository.GetMember(workspaceID, user.ID)
+}
+
+// UpdateMemberRole changes the role of a member. Only owners may change roles, and the last owner cannot be demoted.
+func (s *Service) UpdateMemberRole(userID, workspaceID, memberID uint, role string) (*workspace_model.Member, error) {
+	if !workspace_model.ValidRole(role) {
+		return nil, workspace_model.ErrInvalidRole
+	}
+	if _, err := s.requireRole(workspaceID, userID, workspace_model.RoleOwner); err != nil {
+		return nil, err
+	}
+
+	member, err := s.Repository.GetMember(workspaceID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == role {
+		return member, nil
+	}
+	if member.Role == workspace_model.RoleOwner {
+		if err := s.requireAnotherOwner(workspaceID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.Repository.UpdateMemberRole(workspaceID, memberID, role); err != nil {
+		return nil, err
+	}
+
+	member.Role = role
+	return member, nil
+}
+
+// RemoveMember removes a member from a workspace. Owners may remove anyone and members may leave;
+// the last owner cannot leave. Links of the workspace stay in it.
+func (s *Service) RemoveMember(userID, workspaceID, memberID uint) error {
+	required := workspace_model.RoleOwner
+	if userID == memberID {
+		required = workspace_model.RoleViewer
+	}
+	if _, err := s.requireRole(workspaceID, userID, required); err != nil {
+		return err
+	}
+
+	member, err := s.Repository.GetMember(workspaceID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == workspace_model.RoleOwner {
+		if err := s.requireAnotherOwner(workspaceID); err != nil {
+			return err
+		}
+	}
+
+	return s.Repository.RemoveMember(workspaceID, memberID)
+}
+
+// requireRole returns the membership of the user if it grants at least the given role.
+func (s *Service) requireRole(workspaceID, userID uint, role string) (*workspace_model.Member, error) {
+	member, err := s.Repository.GetMember(workspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !workspace_model.HasRole(member.Role, role) {
+		return nil, workspace_model.ErrInsufficientRole
+	}
+	return member, nil
+}
+
+// requireAnotherOwner checks that the workspace keeps an owner after one is demoted or removed.
+func (s *Service) requireAnotherOwner(workspaceID uint) error {
+	owners, err := s.Repository.CountOwners(workspaceID)
+	if err != nil {
+		return err
+	}
+	if owners < 2 {
+		return workspace_model.ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package workspace_service
+
+import (
+	"strings"
+	"testing"
+	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/models/workspace"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateWorkspace(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	for _, username := range []string{"owner", "alice", "bob"} {
+		user, _ := userRepository.Create(&user_model.User{Username: username})
+		repository.Usernames[user.ID] = username
+	}
+
+	service := NewWorkspaceService(repository, userRepository)
+	workspace, _ := service.CreateWorkspace(1, "Team")
+	workspaceID := workspace.ID
+
+	t.Run("Should make creator the owner", func(t *testing.T) {
+		assert.Equal(t, workspace_model.RoleOwner, repository.Members[workspaceID][1])
+
+		workspaces, err := service.ListWorkspaces(1)
+		assert.NoError(t, err)
+		assert.Len(t, workspaces, 1)
+		assert.Equal(t, "Team", workspaces[0].Name)
+	})
+
+	t.Run("Should reject invalid names", func(t *testing.T) {
+		_, err := service.CreateWorkspace(1, "  ")
+		assert.ErrorIs(t, err, workspace_model.ErrInvalidName)
+
+		_, err = service.CreateWorkspace(1, strings.Repeat("a", 101))
+		assert.ErrorIs(t, err, workspace_model.ErrInvalidName)
+	})
+}
+
+func TestAddMember(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	for _, username := range []string{"owner", "alice", "bob"} {
+		user, _ := userRepository.Create(&user_model.User{Username: username})
+		repository.Usernames[user.ID] = username
+	}
+
+	service := NewWorkspaceService(repository, userRepository)
+	workspace, _ := service.CreateWorkspace(1, "Team")
+	workspaceID := workspace.ID
+
+	t.Run("Should add member by username", func(t *testing.T) {
+		member, err := service.AddMember(1, workspaceID, "alice", workspace_model.RoleEditor)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(2), member.UserID)
+		assert.Equal(t, "alice", member.Username)
+		assert.Equal(t, workspace_model.RoleEditor, member.Role)
+
+		members, err := service.GetMembers(2, workspaceID)
+		assert.NoError(t, err)
+		assert.Len(t, members, 2)
+	})
+
+	t.Run("Should reject duplicate member", func(t *testing.T) {
+		_, err := service.AddMember(1, workspaceID, "alice", workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrMemberAlreadyExists)
+	})
+
+	t.Run("Should only let owners add members", func(t *testing.T) {
+		_, err := service.AddMember(2, workspaceID, "bob", workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrInsufficientRole)
+
+		_, err = service.AddMember(3, workspaceID, "bob", workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+
+		_, err = service.GetMembers(3, workspaceID)
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+	})
+
+	t.Run("Should reject invalid role and unknown user", func(t *testing.T) {
+		_, err := service.AddMember(1, workspaceID, "bob", "admin")
+		assert.ErrorIs(t, err, workspace_model.ErrInvalidRole)
+
+		_, err = service.AddMember(1, workspaceID, "missing", workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestUpdateMemberRole(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	for _, username := range []string{"owner", "alice", "bob"} {
+		user, _ := userRepository.Create(&user_model.User{Username: username})
+		repository.Usernames[user.ID] = username
+	}
+
+	service := NewWorkspaceService(repository, userRepository)
+	workspace, _ := service.CreateWorkspace(1, "Team")
+	workspaceID := workspace.ID
+	_, _ = service.AddMember(1, workspaceID, "alice", workspace_model.RoleViewer)
+
+	t.Run("Should change role", func(t *testing.T) {
+		member, err := service.UpdateMemberRole(1, workspaceID, 2, workspace_model.RoleOwner)
+
+		assert.NoError(t, err)
+		assert.Equal(t, workspace_model.RoleOwner, member.Role)
+		assert.Equal(t, workspace_model.RoleOwner, repository.Members[workspaceID][2])
+	})
+
+	t.Run("Should demote owner while another remains", func(t *testing.T) {
+		_, err := service.UpdateMemberRole(2, workspaceID, 1, workspace_model.RoleEditor)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Should keep last owner", func(t *testing.T) {
+		_, err := service.UpdateMemberRole(2, workspaceID, 2, workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrLastOwner)
+	})
+
+	t.Run("Should reject non-owners and unknown members", func(t *testing.T) {
+		_, err := service.UpdateMemberRole(1, workspaceID, 2, workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrInsufficientRole)
+
+		_, err = service.UpdateMemberRole(2, workspaceID, 3, workspace_model.RoleViewer)
+		assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+
+		_, err = service.UpdateMemberRole(2, workspaceID, 1, "root")
+		assert.ErrorIs(t, err, workspace_model.ErrInvalidRole)
+	})
+}
+
+func TestRemoveMember(t *testing.T) {
+	repository := mocks.NewMockWorkspaceRepository()
+	userRepository := mocks.NewMockUserRepository()
+	for _, username := range []string{"owner", "alice", "bob"} {
+		user, _ := userRepository.Create(&user_model.User{Username: username})
+		repository.Usernames[user.ID] = username
+	}
+
+	service := NewWorkspaceService(repository, userRepository)
+	workspace, _ := service.CreateWorkspace(1, "Team")
+	workspaceID := workspace.ID
+	_, _ = service.AddMember(1, workspaceID, "alice", workspace_model.RoleEditor)
+	_, _ = service.AddMember(1, workspaceID, "bob", workspace_model.RoleViewer)
+
+	t.Run("Should let members leave", func(t *testing.T) {
+		assert.NoError(t, service.RemoveMember(3, workspaceID, 3))
+		assert.NotContains(t, repository.Members[workspaceID], uint(3))
+	})
+
+	t.Run("Should only let owners remove others", func(t *testing.T) {
+		assert.ErrorIs(t, service.RemoveMember(2, workspaceID, 1), workspace_model.ErrInsufficientRole)
+		assert.NoError(t, service.RemoveMember(1, workspaceID, 2))
+	})
+
+	t.Run("Should keep last owner", func(t *testing.T) {
+		assert.ErrorIs(t, service.RemoveMember(1, workspaceID, 1), workspace_model.ErrLastOwner)
+	})
+}
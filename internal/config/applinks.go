@@ -0,0 +1,36 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// NewAppleAppSiteAssociation returns the apple-app-site-association file letting iOS open short links in
+// apps, read from the JSON file APPLE_APP_SITE_ASSOCIATION_PATH points to; nil when unset.
+func NewAppleAppSiteAssociation() ([]byte, error) {
+	return readJSONFile("APPLE_APP_SITE_ASSOCIATION_PATH")
+}
+
+// NewAssetLinks returns the assetlinks.json file letting Android open short links in apps, read from
+// the JSON file ANDROID_ASSETLINKS_PATH points to; nil when unset.
+func NewAssetLinks() ([]byte, error) {
+	return readJSONFile("ANDROID_ASSETLINKS_PATH")
+}
+
+// readJSONFile reads the file the environment variable points to, checking that it holds JSON.
+func readJSONFile(key string) ([]byte, error) {
+	path := os.Getenv(key)
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%s is not a JSON file", key)
+	}
+	return data, nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAppLinkFiles(t *testing.T) {
+	t.Run("Should return nothing when unset", func(t *testing.T) {
+		aasa, err := NewAppleAppSiteAssociation()
+		assert.NoError(t, err)
+		assert.Nil(t, aasa)
+
+		assetLinks, err := NewAssetLinks()
+		assert.NoError(t, err)
+		assert.Nil(t, assetLinks)
+	})
+
+	t.Run("Should read the files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "assetlinks.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`[{"relation":["delegate_permission/common.handle_all_urls"]}]`), 0600))
+		t.Setenv("ANDROID_ASSETLINKS_PATH", path)
+
+		assetLinks, err := NewAssetLinks()
+
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"relation":["delegate_permission/common.handle_all_urls"]}]`, string(assetLinks))
+	})
+
+	t.Run("Should reject missing and invalid files", func(t *testing.T) {
+		t.Setenv("APPLE_APP_SITE_ASSOCIATION_PATH", filepath.Join(t.TempDir(), "missing"))
+		_, err := NewAppleAppSiteAssociation()
+		assert.Error(t, err)
+
+		path := filepath.Join(t.TempDir(), "apple-app-site-association")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"applinks":`), 0600))
+		t.Setenv("APPLE_APP_SITE_ASSOCIATION_PATH", path)
+		_, err = NewAppleAppSiteAssociation()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"url-shortener/internal/app/services/auth"
+)
+
+// NewPasswordPolicy creates the password policy from environment variables.
+// PASSWORD_BREACHED_LIST points to a file of SHA-1 hashes of breached passwords.
+func NewPasswordPolicy() (auth_service.PasswordPolicy, error) {
+	policy := auth_service.DefaultPasswordPolicy()
+	policy.MinLength = getEnvInt("PASSWORD_MIN_LENGTH", policy.MinLength)
+
+	if path := os.Getenv("PASSWORD_BREACHED_LIST"); path != "" {
+		hashes, err := auth_service.LoadBreachedHashes(path)
+		if err != nil {
+			return policy, fmt.Errorf("failed to load password policy: %w", err)
+		}
+		policy.BreachedHashes = hashes
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"url-shortener/internal/app/services/auth"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPasswordPolicy(t *testing.T) {
+	t.Run("Should use defaults when unset", func(t *testing.T) {
+		policy, err := NewPasswordPolicy()
+
+		assert.NoError(t, err)
+		assert.Equal(t, auth_service.DefaultPasswordPolicy(), policy)
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "breached.txt")
+		assert.NoError(t, os.WriteFile(path, []byte("CBFDAC6008F9CAB4083784CBD1874F76618D2A97\n"), 0600))
+		t.Setenv("PASSWORD_MIN_LENGTH", "12")
+		t.Setenv("PASSWORD_BREACHED_LIST", path)
+
+		policy, err := NewPasswordPolicy()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 12, policy.MinLength)
+		assert.Len(t, policy.BreachedHashes, 1)
+	})
+
+	t.Run("Should return error for missing breached list", func(t *testing.T) {
+		t.Setenv("PASSWORD_BREACHED_LIST", filepath.Join(t.TempDir(), "missing.txt"))
+
+		_, err := NewPasswordPolicy()
+
+		assert.Error(t, err)
+	})
+}
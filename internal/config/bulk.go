@@ -0,0 +1,16 @@
+package config
+
+import (
+	"url-shortener/internal/app/services/url"
+)
+
+// NewBulkLimits creates the bulk creation limits from environment variables.
+// Unset variables fall back to url_service.DefaultBulkLimits.
+func NewBulkLimits() url_service.BulkLimits {
+	defaults := url_service.DefaultBulkLimits()
+	return url_service.BulkLimits{
+		MaxURLs:       getEnvInt("BULK_MAX_URLS", defaults.MaxURLs),
+		MaxAsyncURLs:  getEnvInt("BULK_MAX_ASYNC_URLS", defaults.MaxAsyncURLs),
+		MaxImportURLs: getEnvInt("IMPORT_MAX_URLS", defaults.MaxImportURLs),
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"url-shortener/internal/app/services/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBulkLimits(t *testing.T) {
+	t.Run("Should use defaults when unset", func(t *testing.T) {
+		assert.Equal(t, url_service.DefaultBulkLimits(), NewBulkLimits())
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		t.Setenv("BULK_MAX_URLS", "100")
+		t.Setenv("BULK_MAX_ASYNC_URLS", "invalid")
+		t.Setenv("IMPORT_MAX_URLS", "200")
+
+		assert.Equal(t, url_service.BulkLimits{
+			MaxURLs:       100,
+			MaxAsyncURLs:  url_service.DefaultBulkLimits().MaxAsyncURLs,
+			MaxImportURLs: 200,
+		}, NewBulkLimits())
+	})
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+	"url-shortener/internal/app/services/export"
+)
+
+// NewExportDir returns the directory storing background exports, EXPORT_DIR or
+// a "url-shortener-exports" directory of the system temporary directory when unset.
+func NewExportDir() string {
+	if dir := os.Getenv("EXPORT_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "url-shortener-exports")
+}
+
+// NewExportMaxSyncRange returns the longest click time range exported while the client waits,
+// from EXPORT_MAX_SYNC_RANGE, falling back to export_service.DefaultMaxSyncRange.
+func NewExportMaxSyncRange() time.Duration {
+	return getEnvDuration("EXPORT_MAX_SYNC_RANGE", export_service.DefaultMaxSyncRange)
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"url-shortener/internal/app/services/export"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExportDir(t *testing.T) {
+	t.Run("Should use temporary directory when unset", func(t *testing.T) {
+		t.Setenv("EXPORT_DIR", "")
+		assert.Equal(t, filepath.Join(os.TempDir(), "url-shortener-exports"), NewExportDir())
+	})
+
+	t.Run("Should read environment variable", func(t *testing.T) {
+		t.Setenv("EXPORT_DIR", "/var/lib/exports")
+		assert.Equal(t, "/var/lib/exports", NewExportDir())
+	})
+}
+
+func TestNewExportMaxSyncRange(t *testing.T) {
+	assert.Equal(t, export_service.DefaultMaxSyncRange, NewExportMaxSyncRange())
+
+	t.Setenv("EXPORT_MAX_SYNC_RANGE", "168h")
+	assert.Equal(t, 168*time.Hour, NewExportMaxSyncRange())
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"url-shortener/internal/app/services/lockout"
+)
+
+// NewLockoutConfig creates the login lockout thresholds from environment variables.
+// Unset variables fall back to lockout_service.DefaultConfig.
+func NewLockoutConfig() lockout_service.Config {
+	defaults := lockout_service.DefaultConfig()
+	return lockout_service.Config{
+		MaxUserAttempts: getEnvInt("LOGIN_MAX_USER_ATTEMPTS", defaults.MaxUserAttempts),
+		MaxIPAttempts:   getEnvInt("LOGIN_MAX_IP_ATTEMPTS", defaults.MaxIPAttempts),
+		BaseDelay:       getEnvDuration("LOGIN_BASE_DELAY", defaults.BaseDelay),
+		MaxDelay:        getEnvDuration("LOGIN_MAX_DELAY", defaults.MaxDelay),
+		LockoutDuration: getEnvDuration("LOGIN_LOCKOUT_DURATION", defaults.LockoutDuration),
+		Window:          getEnvDuration("LOGIN_ATTEMPT_WINDOW", defaults.Window),
+	}
+}
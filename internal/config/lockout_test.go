@@ -0,0 +1,42 @@
+package config
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/services/lockout"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLockoutConfig(t *testing.T) {
+	t.Run("Should use defaults when unset", func(t *testing.T) {
+		assert.Equal(t, lockout_service.DefaultConfig(), NewLockoutConfig())
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		t.Setenv("LOGIN_MAX_USER_ATTEMPTS", "3")
+		t.Setenv("LOGIN_MAX_IP_ATTEMPTS", "10")
+		t.Setenv("LOGIN_BASE_DELAY", "2s")
+		t.Setenv("LOGIN_MAX_DELAY", "30s")
+		t.Setenv("LOGIN_LOCKOUT_DURATION", "5m")
+		t.Setenv("LOGIN_ATTEMPT_WINDOW", "30m")
+
+		assert.Equal(t, lockout_service.Config{
+			MaxUserAttempts: 3,
+			MaxIPAttempts:   10,
+			BaseDelay:       2 * time.Second,
+			MaxDelay:        30 * time.Second,
+			LockoutDuration: 5 * time.Minute,
+			Window:          30 * time.Minute,
+		}, NewLockoutConfig())
+	})
+
+	t.Run("Should ignore invalid values", func(t *testing.T) {
+		t.Setenv("LOGIN_MAX_USER_ATTEMPTS", "many")
+		t.Setenv("LOGIN_BASE_DELAY", "soon")
+
+		config := NewLockoutConfig()
+		assert.Equal(t, lockout_service.DefaultConfig().MaxUserAttempts, config.MaxUserAttempts)
+		assert.Equal(t, lockout_service.DefaultConfig().BaseDelay, config.BaseDelay)
+	})
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"os"
+	"url-shortener/internal/infrastructure/mail"
+)
+
+// NewMailer creates the mailer selected by MAIL_DRIVER.
+// "smtp" delivers through SMTP_*; anything else writes messages to MAIL_LOG_PATH, or stdout when unset.
+func NewMailer() mail.Mailer {
+	if os.Getenv("MAIL_DRIVER") == "smtp" {
+		return mail.NewSMTPMailer(
+			os.Getenv("SMTP_HOST"),
+			os.Getenv("SMTP_PORT"),
+			os.Getenv("SMTP_USERNAME"),
+			os.Getenv("SMTP_PASSWORD"),
+			os.Getenv("MAIL_FROM"),
+		)
+	}
+	return mail.NewLogMailer(os.Getenv("MAIL_LOG_PATH"))
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"testing"
+	"url-shortener/internal/infrastructure/mail"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMailer(t *testing.T) {
+	t.Run("Should default to log mailer", func(t *testing.T) {
+		t.Setenv("MAIL_LOG_PATH", "mail.log")
+
+		mailer := NewMailer()
+
+		logMailer, ok := mailer.(*mail.LogMailer)
+		assert.True(t, ok)
+		assert.Equal(t, "mail.log", logMailer.Path)
+	})
+
+	t.Run("Should create SMTP mailer", func(t *testing.T) {
+		t.Setenv("MAIL_DRIVER", "smtp")
+		t.Setenv("SMTP_HOST", "smtp.example.com")
+		t.Setenv("SMTP_PORT", "587")
+		t.Setenv("MAIL_FROM", "noreply@example.com")
+
+		mailer := NewMailer()
+
+		smtpMailer, ok := mailer.(*mail.SMTPMailer)
+		assert.True(t, ok)
+		assert.Equal(t, "smtp.example.com", smtpMailer.Host)
+		assert.Equal(t, "587", smtpMailer.Port)
+		assert.Equal(t, "noreply@example.com", smtpMailer.From)
+	})
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"url-shortener/internal/app/repositories/url"
+	"url-shortener/internal/app/services/metadata"
+)
+
+// NewMetadataService creates the background fetcher of the page metadata of new links and starts it,
+// or returns nil when METADATA_FETCH_ENABLED is false, as offline deployments need.
+// Pages are fetched within METADATA_FETCH_TIMEOUT, reading at most METADATA_MAX_BYTES of each,
+// and up to METADATA_QUEUE_SIZE links wait for their fetch.
+func NewMetadataService(repository url_repository.Repository) *metadata_service.Service {
+	if !getEnvBool("METADATA_FETCH_ENABLED", true) {
+		return nil
+	}
+
+	timeout := getEnvDuration("METADATA_FETCH_TIMEOUT", metadata_service.DefaultTimeout)
+	fetcher := metadata_service.NewFetcher(metadata_service.NewHTTPClient(timeout))
+	fetcher.Timeout = timeout
+	fetcher.MaxBytes = int64(getEnvInt("METADATA_MAX_BYTES", metadata_service.DefaultMaxBytes))
+
+	service := metadata_service.NewMetadataService(repository, fetcher, getEnvInt("METADATA_QUEUE_SIZE", metadata_service.DefaultQueueSize))
+	go service.Run(nil)
+	return service
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/services/metadata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMetadataService(t *testing.T) {
+	t.Run("Should use defaults", func(t *testing.T) {
+		service := NewMetadataService(nil)
+
+		assert.NotNil(t, service)
+		assert.Equal(t, metadata_service.DefaultTimeout, service.Fetcher.Timeout)
+		assert.Equal(t, int64(metadata_service.DefaultMaxBytes), service.Fetcher.MaxBytes)
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		t.Setenv("METADATA_FETCH_TIMEOUT", "2s")
+		t.Setenv("METADATA_MAX_BYTES", "65536")
+		service := NewMetadataService(nil)
+
+		assert.Equal(t, 2*time.Second, service.Fetcher.Timeout)
+		assert.Equal(t, int64(65536), service.Fetcher.MaxBytes)
+	})
+
+	t.Run("Should be disabled for offline deployments", func(t *testing.T) {
+		t.Setenv("METADATA_FETCH_ENABLED", "false")
+		assert.Nil(t, NewMetadataService(nil))
+	})
+}
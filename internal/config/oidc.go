@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"url-shortener/internal/app/services/oidc"
+)
+
+// NewOIDCProviders creates the single sign-on providers listed in OIDC_PROVIDERS.
+// Each provider NAME is configured through OIDC_<NAME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET,
+// _REDIRECT_URL and _SCOPES; providers without an issuer or client ID are skipped.
+func NewOIDCProviders() []oidc_service.Provider {
+	var providers []oidc_service.Provider
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := oidc_service.Provider{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			fmt.Println("[CONFIG] Skipping OIDC provider without issuer or client ID:", name)
+			continue
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = strings.TrimSuffix(os.Getenv("APP_BASE_URL"), "/") + "/auth/oidc/" + name + "/callback/"
+		}
+		if len(provider.Scopes) == 0 {
+			provider.Scopes = []string{"email", "profile"}
+		}
+
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOIDCProviders(t *testing.T) {
+	t.Run("Should return no providers when unset", func(t *testing.T) {
+		t.Setenv("OIDC_PROVIDERS", "")
+
+		assert.Empty(t, NewOIDCProviders())
+	})
+
+	t.Run("Should read providers", func(t *testing.T) {
+		t.Setenv("APP_BASE_URL", "https://sho.rt/")
+		t.Setenv("OIDC_PROVIDERS", "Google, my-okta, incomplete")
+		t.Setenv("OIDC_GOOGLE_ISSUER", "https://accounts.google.com")
+		t.Setenv("OIDC_GOOGLE_CLIENT_ID", "google-client")
+		t.Setenv("OIDC_GOOGLE_CLIENT_SECRET", "google-secret")
+		t.Setenv("OIDC_MY_OKTA_ISSUER", "https://example.okta.com")
+		t.Setenv("OIDC_MY_OKTA_CLIENT_ID", "okta-client")
+		t.Setenv("OIDC_MY_OKTA_REDIRECT_URL", "https://sho.rt/sso/callback")
+		t.Setenv("OIDC_MY_OKTA_SCOPES", "email groups")
+		t.Setenv("OIDC_INCOMPLETE_ISSUER", "https://idp.example.com")
+
+		providers := NewOIDCProviders()
+
+		assert.Len(t, providers, 2)
+		assert.Equal(t, "google", providers[0].Name)
+		assert.Equal(t, "google-secret", providers[0].ClientSecret)
+		assert.Equal(t, "https://sho.rt/auth/oidc/google/callback/", providers[0].RedirectURL)
+		assert.Equal(t, []string{"email", "profile"}, providers[0].Scopes)
+		assert.Equal(t, "my-okta", providers[1].Name)
+		assert.Equal(t, "https://sho.rt/sso/callback", providers[1].RedirectURL)
+		assert.Equal(t, []string{"email", "groups"}, providers[1].Scopes)
+	})
+}
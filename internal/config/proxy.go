@@ -0,0 +1,36 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// NewTrustedProxies creates the list of proxies from environment variables whose X-Forwarded-For header
+// is trusted for the IP address of clients. TRUSTED_PROXIES is a comma separated list of IP addresses
+// and CIDR ranges; when unset, clients are known by the address of their connection.
+func NewTrustedProxies() ([]*net.IPNet, error) {
+	var proxies []*net.IPNet
+	for _, item := range splitList(os.Getenv("TRUSTED_PROXIES")) {
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, proxy, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", item, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTrustedProxies(t *testing.T) {
+	t.Run("Should trust no proxies when unset", func(t *testing.T) {
+		proxies, err := NewTrustedProxies()
+
+		assert.NoError(t, err)
+		assert.Empty(t, proxies)
+	})
+
+	t.Run("Should read addresses and ranges", func(t *testing.T) {
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1, 2001:db8::1")
+
+		proxies, err := NewTrustedProxies()
+
+		assert.NoError(t, err)
+		assert.Len(t, proxies, 3)
+		assert.Equal(t, "10.0.0.0/8", proxies[0].String())
+		assert.Equal(t, "192.0.2.1/32", proxies[1].String())
+		assert.Equal(t, "2001:db8::1/128", proxies[2].String())
+		assert.True(t, proxies[0].Contains(net.ParseIP("10.1.2.3")))
+		assert.False(t, proxies[1].Contains(net.ParseIP("192.0.2.2")))
+	})
+
+	t.Run("Should return error for invalid entries", func(t *testing.T) {
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, proxy.local")
+		_, err := NewTrustedProxies()
+		assert.Error(t, err)
+
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/40")
+		_, err = NewTrustedProxies()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"os"
+	"url-shortener/internal/app/services/qr"
+)
+
+// NewQRService creates the QR code generator from environment variables.
+// QR_LOGO_PATH points to a PNG or JPEG logo and QR_CACHE_SIZE bounds the number of cached images.
+func NewQRService() (*qr_service.Service, error) {
+	cacheSize := getEnvInt("QR_CACHE_SIZE", qr_service.DefaultCacheSize)
+
+	path := os.Getenv("QR_LOGO_PATH")
+	if path == "" {
+		return qr_service.NewQRService(nil, cacheSize), nil
+	}
+
+	file, err := os.Open(path) // #nosec G304 -- path comes from configuration
+	if err != nil {
+		return qr_service.NewQRService(nil, cacheSize), fmt.Errorf("failed to open QR logo: %w", err)
+	}
+	defer file.Close()
+
+	logo, _, err := image.Decode(file)
+	if err != nil {
+		return qr_service.NewQRService(nil, cacheSize), fmt.Errorf("failed to decode QR logo: %w", err)
+	}
+
+	return qr_service.NewQRService(logo, cacheSize), nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewQRService(t *testing.T) {
+	t.Run("Should create service without logo", func(t *testing.T) {
+		qrService, err := NewQRService()
+
+		assert.NoError(t, err)
+		assert.Nil(t, qrService.Logo)
+	})
+
+	t.Run("Should load the logo", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logo.png")
+		file, err := os.Create(path)
+		assert.NoError(t, err)
+		assert.NoError(t, png.Encode(file, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+		assert.NoError(t, file.Close())
+		t.Setenv("QR_LOGO_PATH", path)
+
+		qrService, err := NewQRService()
+
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 4, 4), qrService.Logo.Bounds())
+	})
+
+	t.Run("Should return error for invalid logo", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logo.png")
+		assert.NoError(t, os.WriteFile(path, []byte("not an image"), 0o600))
+		t.Setenv("QR_LOGO_PATH", path)
+
+		qrService, err := NewQRService()
+		assert.Error(t, err)
+		assert.NotNil(t, qrService)
+
+		t.Setenv("QR_LOGO_PATH", filepath.Join(t.TempDir(), "missing.png"))
+		_, err = NewQRService()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"url-shortener/internal/app/middleware/ratelimit"
+)
+
+// rateLimitDefaults are the default quotas of the rate limited routes for anonymous and authenticated clients,
+// as "requests/period". Bulk shortening and imports create many links per request, so they have quotas of their own.
+var rateLimitDefaults = map[string][2]string{
+	ratelimit_middleware.RouteShorten:  {"10/1m", "60/1m"},
+	ratelimit_middleware.RouteBulk:     {"10/1h", "10/1h"},
+	ratelimit_middleware.RouteImport:   {"3/1h", "3/1h"},
+	ratelimit_middleware.RouteRedirect: {"120/1m", "120/1m"},
+	ratelimit_middleware.RouteUnlock:   {"10/1m", "10/1m"},
+	ratelimit_middleware.RouteQR:       {"30/1m", "30/1m"},
+}
+
+// NewRateLimitPolicies creates the per-route rate limits from environment variables.
+// Each route ROUTE is limited by RATE_LIMIT_<ROUTE>, or by RATE_LIMIT_<ROUTE>_ANONYMOUS and
+// RATE_LIMIT_<ROUTE>_AUTHENTICATED for each kind of client. Each variable is "requests/period" (e.g. "10/1m"),
+// optionally followed by ",burst"; "off" disables the limit. Unset or invalid variables fall back to the defaults.
+func NewRateLimitPolicies() map[string]ratelimit_middleware.RoutePolicy {
+	policies := make(map[string]ratelimit_middleware.RoutePolicy, len(rateLimitDefaults))
+	for route, defaults := range rateLimitDefaults {
+		policies[route] = ratelimit_middleware.RoutePolicy{
+			Anonymous:     getRoutePolicy(route, "ANONYMOUS", defaults[0]),
+			Authenticated: getRoutePolicy(route, "AUTHENTICATED", defaults[1]),
+		}
+	}
+
+	// Redirects are counted per client and per link
+	redirect := policies[ratelimit_middleware.RouteRedirect]
+	redirect.Param = "id"
+	policies[ratelimit_middleware.RouteRedirect] = redirect
+
+	// Password guesses are counted per link across all clients
+	unlock := policies[ratelimit_middleware.RouteUnlock]
+	unlock.Param = "id"
+	unlock.Shared = true
+	policies[ratelimit_middleware.RouteUnlock] = unlock
+
+	return policies
+}
+
+// getRoutePolicy returns the policy of the route for the kind of client, falling back to the policy of the whole route.
+func getRoutePolicy(route, client, fallback string) *ratelimit_middleware.Policy {
+	key := "RATE_LIMIT_" + strings.ToUpper(route)
+	if os.Getenv(key+"_"+client) != "" {
+		key += "_" + client
+	}
+	return getEnvPolicy(key, fallback)
+}
+
+// getEnvPolicy returns the policy of the environment variable or the fallback when unset or invalid.
+func getEnvPolicy(key, fallback string) *ratelimit_middleware.Policy {
+	value := os.Getenv(key)
+	if value == "" {
+		value = fallback
+	}
+	if strings.EqualFold(value, "off") {
+		return nil
+	}
+
+	policy, err := parsePolicy(value)
+	if err != nil {
+		fmt.Printf("[CONFIG] Invalid %s: %v\n", key, err)
+		policy, _ = parsePolicy(fallback)
+	}
+	return policy
+}
+
+// parsePolicy parses a "requests/period[,burst]" rate limit.
+func parsePolicy(value string) (*ratelimit_middleware.Policy, error) {
+	rate, burst, hasBurst := strings.Cut(value, ",")
+	limit, period, ok := strings.Cut(rate, "/")
+	if !ok {
+		return nil, fmt.Errorf("expected requests/period, got %q", value)
+	}
+
+	policy := &ratelimit_middleware.Policy{}
+	var err error
+	if policy.Limit, err = strconv.Atoi(strings.TrimSpace(limit)); err != nil || policy.Limit <= 0 {
+		return nil, fmt.Errorf("invalid request count %q", limit)
+	}
+	if policy.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || policy.Period <= 0 {
+		return nil, fmt.Errorf("invalid period %q", period)
+	}
+	if hasBurst {
+		if policy.Burst, err = strconv.Atoi(strings.TrimSpace(burst)); err != nil || policy.Burst <= 0 {
+			return nil, fmt.Errorf("invalid burst %q", burst)
+		}
+	}
+	return policy, nil
+}
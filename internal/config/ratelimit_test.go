@@ -0,0 +1,70 @@
+package config
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/middleware/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimitPolicies(t *testing.T) {
+	t.Run("Should use defaults when unset", func(t *testing.T) {
+		policies := NewRateLimitPolicies()
+
+		shorten := policies[ratelimit_middleware.RouteShorten]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 10, Period: time.Minute}, shorten.Anonymous)
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 60, Period: time.Minute}, shorten.Authenticated)
+
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 10, Period: time.Hour}, policies[ratelimit_middleware.RouteBulk].Authenticated)
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 3, Period: time.Hour}, policies[ratelimit_middleware.RouteImport].Authenticated)
+
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 30, Period: time.Minute}, policies[ratelimit_middleware.RouteQR].Anonymous)
+
+		redirect := policies[ratelimit_middleware.RouteRedirect]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 120, Period: time.Minute}, redirect.Anonymous)
+		assert.Equal(t, "id", redirect.Param)
+
+		unlock := policies[ratelimit_middleware.RouteUnlock]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 10, Period: time.Minute}, unlock.Anonymous)
+		assert.True(t, unlock.Shared)
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_SHORTEN_ANONYMOUS", "5/1h")
+		t.Setenv("RATE_LIMIT_SHORTEN_AUTHENTICATED", "100/1m,20")
+		t.Setenv("RATE_LIMIT_REDIRECT", "off")
+
+		policies := NewRateLimitPolicies()
+
+		shorten := policies[ratelimit_middleware.RouteShorten]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 5, Period: time.Hour}, shorten.Anonymous)
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 100, Period: time.Minute, Burst: 20}, shorten.Authenticated)
+		assert.Nil(t, policies[ratelimit_middleware.RouteRedirect].Anonymous)
+		assert.Equal(t, "id", policies[ratelimit_middleware.RouteRedirect].Param)
+	})
+
+	t.Run("Should configure each route and kind of client", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_BULK", "2/1m")
+		t.Setenv("RATE_LIMIT_IMPORT_AUTHENTICATED", "1/24h")
+
+		policies := NewRateLimitPolicies()
+
+		bulk := policies[ratelimit_middleware.RouteBulk]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 2, Period: time.Minute}, bulk.Anonymous)
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 2, Period: time.Minute}, bulk.Authenticated)
+
+		imports := policies[ratelimit_middleware.RouteImport]
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 3, Period: time.Hour}, imports.Anonymous)
+		assert.Equal(t, &ratelimit_middleware.Policy{Limit: 1, Period: 24 * time.Hour}, imports.Authenticated)
+	})
+
+	t.Run("Should ignore invalid values", func(t *testing.T) {
+		for _, value := range []string{"many", "0/1m", "10/soon", "10/1m,none"} {
+			t.Setenv("RATE_LIMIT_SHORTEN_ANONYMOUS", value)
+
+			shorten := NewRateLimitPolicies()[ratelimit_middleware.RouteShorten]
+			assert.Equal(t, &ratelimit_middleware.Policy{Limit: 10, Period: time.Minute}, shorten.Anonymous, value)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"os"
+	"url-shortener/internal/app/services/url"
+)
+
+// NewCountryHeader returns the request header holding the country of visitors for redirect rules,
+// read from COUNTRY_HEADER. It falls back to url_service.DefaultCountryHeader when unset.
+func NewCountryHeader() string {
+	if header := os.Getenv("COUNTRY_HEADER"); header != "" {
+		return header
+	}
+	return url_service.DefaultCountryHeader
+}
@@ -0,0 +1,20 @@
+package config
+
+import (
+	"testing"
+	"url-shortener/internal/app/services/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCountryHeader(t *testing.T) {
+	t.Run("Should use default when unset", func(t *testing.T) {
+		assert.Equal(t, url_service.DefaultCountryHeader, NewCountryHeader())
+	})
+
+	t.Run("Should read environment variable", func(t *testing.T) {
+		t.Setenv("COUNTRY_HEADER", "X-Country-Code")
+
+		assert.Equal(t, "X-Country-Code", NewCountryHeader())
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"url-shortener/internal/app/services/url"
+)
+
+// lookupTimeout bounds the DNS lookups of destination hosts.
+const lookupTimeout = 2 * time.Second
+
+// NewSafetyPolicy creates the destination URL policy from environment variables.
+// The host of APP_BASE_URL and URL_SAFETY_OWN_HOSTS are rejected as redirect loops, and
+// URL_BLOCKLIST_DOMAINS and URL_BLOCKLIST_PATTERNS are reloaded every URL_BLOCKLIST_RELOAD_INTERVAL.
+func NewSafetyPolicy() (url_service.SafetyPolicy, error) {
+	policy := url_service.DefaultSafetyPolicy()
+
+	if schemes := splitList(os.Getenv("URL_SAFETY_SCHEMES")); len(schemes) > 0 {
+		policy.AllowedSchemes = schemes
+	}
+
+	if base, err := url.Parse(os.Getenv("APP_BASE_URL")); err == nil && base.Hostname() != "" {
+		policy.OwnHosts = append(policy.OwnHosts, base.Hostname())
+	}
+	policy.OwnHosts = append(policy.OwnHosts, splitList(os.Getenv("URL_SAFETY_OWN_HOSTS"))...)
+
+	if getEnvBool("URL_SAFETY_RESOLVE_HOSTS", true) {
+		policy.LookupIP = lookupIP
+	}
+
+	domains, patterns := os.Getenv("URL_BLOCKLIST_DOMAINS"), os.Getenv("URL_BLOCKLIST_PATTERNS")
+	if domains != "" || patterns != "" {
+		blocklist, err := url_service.NewBlocklist(domains, patterns)
+		if err != nil {
+			return policy, fmt.Errorf("failed to load URL blocklist: %w", err)
+		}
+		policy.Blocklist = blocklist
+
+		if interval := getEnvDuration("URL_BLOCKLIST_RELOAD_INTERVAL", 30*time.Second); interval > 0 {
+			go blocklist.Watch(interval, nil)
+		}
+	}
+
+	return policy, nil
+}
+
+// lookupIP resolves the host with the default resolver.
+func lookupIP(host string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	return net.DefaultResolver.LookupIP(ctx, "ip", host)
+}
+
+// splitList splits a comma separated list, lower-casing and dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
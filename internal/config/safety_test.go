@@ -0,0 +1,47 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSafetyPolicy(t *testing.T) {
+	t.Run("Should use defaults when unset", func(t *testing.T) {
+		policy, err := NewSafetyPolicy()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"http", "https"}, policy.AllowedSchemes)
+		assert.Empty(t, policy.OwnHosts)
+		assert.NotNil(t, policy.LookupIP)
+		assert.Nil(t, policy.Blocklist)
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		domains := filepath.Join(t.TempDir(), "domains.txt")
+		assert.NoError(t, os.WriteFile(domains, []byte("evil.com\n"), 0o600))
+		t.Setenv("APP_BASE_URL", "https://sho.rt/")
+		t.Setenv("URL_SAFETY_OWN_HOSTS", "www.sho.rt, Links.example.com")
+		t.Setenv("URL_SAFETY_SCHEMES", "https")
+		t.Setenv("URL_SAFETY_RESOLVE_HOSTS", "false")
+		t.Setenv("URL_BLOCKLIST_DOMAINS", domains)
+		t.Setenv("URL_BLOCKLIST_RELOAD_INTERVAL", "0")
+
+		policy, err := NewSafetyPolicy()
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"https"}, policy.AllowedSchemes)
+		assert.Equal(t, []string{"sho.rt", "www.sho.rt", "links.example.com"}, policy.OwnHosts)
+		assert.Nil(t, policy.LookupIP)
+		assert.Error(t, policy.Check("https://evil.com/"))
+	})
+
+	t.Run("Should return error for missing blocklist", func(t *testing.T) {
+		t.Setenv("URL_BLOCKLIST_PATTERNS", filepath.Join(t.TempDir(), "missing.txt"))
+
+		_, err := NewSafetyPolicy()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"net/url"
+	"os"
+	"url-shortener/internal/infrastructure/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// NewTLSConfig creates the HTTPS configuration from environment variables. TLS_CERT_FILE and
+// TLS_KEY_FILE are served and checked for changes every TLS_RELOAD_INTERVAL. ACME_ENABLED issues
+// certificates for ACME_HOSTS, the host of APP_BASE_URL by default, from ACME_DIRECTORY_URL, caching
+// them in ACME_CACHE_DIR. HTTP_REDIRECT_ADDR redirects plain HTTP to HTTPS on TLS_REDIRECT_PORT.
+func NewTLSConfig() http.TLSConfig {
+	config := http.TLSConfig{
+		CertFile:       os.Getenv("TLS_CERT_FILE"),
+		KeyFile:        os.Getenv("TLS_KEY_FILE"),
+		ReloadInterval: getEnvDuration("TLS_RELOAD_INTERVAL", http.DefaultReloadInterval),
+		RedirectAddr:   os.Getenv("HTTP_REDIRECT_ADDR"),
+		RedirectPort:   os.Getenv("TLS_REDIRECT_PORT"),
+	}
+
+	if getEnvBool("ACME_ENABLED", false) {
+		hosts := splitList(os.Getenv("ACME_HOSTS"))
+		if base, err := url.Parse(os.Getenv("APP_BASE_URL")); len(hosts) == 0 && err == nil && base.Hostname() != "" {
+			hosts = []string{base.Hostname()}
+		}
+
+		cacheDir := os.Getenv("ACME_CACHE_DIR")
+		if cacheDir == "" {
+			cacheDir = "acme-cache"
+		}
+
+		config.ACME = &http.ACMEConfig{
+			Hosts:        hosts,
+			Email:        os.Getenv("ACME_EMAIL"),
+			DirectoryURL: os.Getenv("ACME_DIRECTORY_URL"),
+			Cache:        autocert.DirCache(cacheDir),
+		}
+	}
+
+	return config
+}
+
+// NewHSTSConfig creates the Strict-Transport-Security configuration from environment variables.
+// HSTS_MAX_AGE is a duration, 0 sending no header.
+func NewHSTSConfig() http.HSTSConfig {
+	return http.HSTSConfig{
+		MaxAge:            getEnvDuration("HSTS_MAX_AGE", 0),
+		IncludeSubdomains: getEnvBool("HSTS_INCLUDE_SUBDOMAINS", false),
+		Preload:           getEnvBool("HSTS_PRELOAD", false),
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("Should serve plain HTTP when unset", func(t *testing.T) {
+		config := NewTLSConfig()
+
+		assert.False(t, config.Enabled())
+		assert.Equal(t, time.Minute, config.ReloadInterval)
+		assert.Nil(t, config.ACME)
+	})
+
+	t.Run("Should read certificate files and the redirect listener", func(t *testing.T) {
+		t.Setenv("TLS_CERT_FILE", "/etc/tls/cert.pem")
+		t.Setenv("TLS_KEY_FILE", "/etc/tls/key.pem")
+		t.Setenv("TLS_RELOAD_INTERVAL", "10s")
+		t.Setenv("HTTP_REDIRECT_ADDR", ":80")
+		t.Setenv("TLS_REDIRECT_PORT", "443")
+
+		config := NewTLSConfig()
+
+		assert.True(t, config.Enabled())
+		assert.Equal(t, "/etc/tls/cert.pem", config.CertFile)
+		assert.Equal(t, "/etc/tls/key.pem", config.KeyFile)
+		assert.Equal(t, 10*time.Second, config.ReloadInterval)
+		assert.Equal(t, ":80", config.RedirectAddr)
+		assert.Equal(t, "443", config.RedirectPort)
+	})
+
+	t.Run("Should issue certificates for the base URL host by default", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "certs")
+		t.Setenv("ACME_ENABLED", "true")
+		t.Setenv("APP_BASE_URL", "https://sho.rt/")
+		t.Setenv("ACME_EMAIL", "ops@sho.rt")
+		t.Setenv("ACME_CACHE_DIR", dir)
+
+		config := NewTLSConfig()
+
+		assert.True(t, config.Enabled())
+		assert.Equal(t, []string{"sho.rt"}, config.ACME.Hosts)
+		assert.Equal(t, "ops@sho.rt", config.ACME.Email)
+		assert.Empty(t, config.ACME.DirectoryURL)
+		assert.Equal(t, autocert.DirCache(dir), config.ACME.Cache)
+
+		t.Setenv("ACME_HOSTS", "sho.rt, WWW.sho.rt")
+		t.Setenv("ACME_DIRECTORY_URL", "https://acme-staging-v02.api.letsencrypt.org/directory")
+		config = NewTLSConfig()
+		assert.Equal(t, []string{"sho.rt", "www.sho.rt"}, config.ACME.Hosts)
+		assert.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/directory", config.ACME.DirectoryURL)
+	})
+}
+
+func TestNewHSTSConfig(t *testing.T) {
+	assert.Zero(t, NewHSTSConfig().MaxAge)
+
+	t.Setenv("HSTS_MAX_AGE", "8760h")
+	t.Setenv("HSTS_INCLUDE_SUBDOMAINS", "true")
+	t.Setenv("HSTS_PRELOAD", "true")
+	config := NewHSTSConfig()
+
+	assert.Equal(t, 365*24*time.Hour, config.MaxAge)
+	assert.True(t, config.IncludeSubdomains)
+	assert.True(t, config.Preload)
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"url-shortener/internal/app/repositories/webhook"
+	"url-shortener/internal/app/services/url"
+	"url-shortener/internal/app/services/webhook"
+)
+
+// NewWebhookService creates the service delivering the events of links to webhooks and starts its delivery and
+// click workers.
+// Deliveries time out after WEBHOOK_TIMEOUT and are attempted up to WEBHOOK_MAX_ATTEMPTS times, waiting
+// WEBHOOK_RETRY_BASE_DELAY after the first failure, doubled after each further one up to WEBHOOK_RETRY_MAX_DELAY.
+// The queue is polled every WEBHOOK_POLL_INTERVAL for deliveries queued by other instances and due retries.
+func NewWebhookService(repository webhook_repository.Repository, urlService *url_service.Service) *webhook_service.Service {
+	service := webhook_service.NewWebhookService(repository, urlService)
+	service.Client = webhook_service.NewHTTPClient(getEnvDuration("WEBHOOK_TIMEOUT", webhook_service.DefaultTimeout))
+	service.MaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", webhook_service.DefaultMaxAttempts)
+	service.BaseDelay = getEnvDuration("WEBHOOK_RETRY_BASE_DELAY", webhook_service.DefaultBaseDelay)
+	service.MaxDelay = getEnvDuration("WEBHOOK_RETRY_MAX_DELAY", webhook_service.DefaultMaxDelay)
+	service.PollInterval = getEnvDuration("WEBHOOK_POLL_INTERVAL", webhook_service.DefaultPollInterval)
+	go service.Run(nil)
+	go service.RunClicks(nil)
+	return service
+}
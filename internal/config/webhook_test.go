@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/services/webhook"
+	"url-shortener/internal/mocks"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWebhookService(t *testing.T) {
+	t.Run("Should use defaults", func(t *testing.T) {
+		service := NewWebhookService(mocks.NewMockWebhookRepository(), nil)
+
+		assert.Equal(t, webhook_service.DefaultTimeout, service.Client.Timeout)
+		assert.Equal(t, webhook_service.DefaultMaxAttempts, service.MaxAttempts)
+		assert.Equal(t, webhook_service.DefaultBaseDelay, service.BaseDelay)
+		assert.Equal(t, webhook_service.DefaultMaxDelay, service.MaxDelay)
+		assert.Equal(t, webhook_service.DefaultPollInterval, service.PollInterval)
+	})
+
+	t.Run("Should read environment variables", func(t *testing.T) {
+		t.Setenv("WEBHOOK_TIMEOUT", "3s")
+		t.Setenv("WEBHOOK_MAX_ATTEMPTS", "5")
+		t.Setenv("WEBHOOK_RETRY_BASE_DELAY", "1m")
+		t.Setenv("WEBHOOK_RETRY_MAX_DELAY", "1h")
+		t.Setenv("WEBHOOK_POLL_INTERVAL", "30s")
+		service := NewWebhookService(mocks.NewMockWebhookRepository(), nil)
+
+		assert.Equal(t, 3*time.Second, service.Client.Timeout)
+		assert.Equal(t, 5, service.MaxAttempts)
+		assert.Equal(t, time.Minute, service.BaseDelay)
+		assert.Equal(t, time.Hour, service.MaxDelay)
+		assert.Equal(t, 30*time.Second, service.PollInterval)
+	})
+}
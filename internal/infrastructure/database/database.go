@@ -1,11 +1,50 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	_ "github.com/go-sql-driver/mysql"
+	"github.com/go-sql-driver/mysql"
 )
 
+// shortCodeLength is the length of short code columns, 8 in databases created before custom aliases.
+const shortCodeLength = 64
+
+// errDuplicateColumn is the MySQL error number of adding a column a table already has.
+const errDuplicateColumn = 1060
+
+// column is a column added to a table after the table was first created.
+type column struct {
+	table      string
+	name       string
+	definition string
+	// references is the key the column refers to, if any.
+	references string
+}
+
+// addedColumns lists the columns added to existing tables, which databases created by earlier versions
+// get with ALTER TABLE.
+var addedColumns = []column{
+	{table: "users", name: "email", definition: "VARCHAR(255) NULL UNIQUE"},
+	{table: "users", name: "email_verified", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+	{table: "users", name: "role", definition: "VARCHAR(50) NOT NULL DEFAULT 'user'"},
+	{table: "users", name: "disabled", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+	{table: "urls", name: "disabled", definition: "BOOLEAN NOT NULL DEFAULT FALSE"},
+	{table: "urls", name: "workspace_id", definition: "INT NULL", references: "workspaces(id)"},
+	{table: "urls", name: "password_hash", definition: "VARCHAR(255) NULL"},
+	{table: "urls", name: "expires_at", definition: "TIMESTAMP NULL"},
+	{table: "urls", name: "imported_clicks", definition: "INT NOT NULL DEFAULT 0"},
+	{table: "urls", name: "folder_id", definition: "INT NULL", references: "folders(id)"},
+	{table: "urls", name: "title", definition: "VARCHAR(255) NULL"},
+	{table: "urls", name: "notes", definition: "TEXT NULL"},
+	{table: "clicks", name: "variant", definition: "VARCHAR(50) NULL"},
+	{table: "urls", name: "active_from", definition: "TIMESTAMP NULL"},
+	{table: "urls", name: "redirect_type", definition: "SMALLINT NOT NULL DEFAULT 301"},
+	{table: "clicks", name: "revision_id", definition: "INT NULL"},
+	{table: "domains", name: "verified_hostname", definition: "VARCHAR(253) NULL UNIQUE"},
+}
+
 // Connector defines an interface for connecting to a database.
 type Connector interface {
 	Connect(driverName string) (*sql.DB, error)
@@ -78,22 +117,239 @@ func migrations(db *sql.DB) error {
 			id INT AUTO_INCREMENT PRIMARY KEY,
 			username VARCHAR(50) NOT NULL UNIQUE,
 			password VARCHAR(100) NOT NULL,
+			email VARCHAR(255) NULL UNIQUE,
+			email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+			role VARCHAR(50) NOT NULL DEFAULT 'user',
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 			);`,
+		`CREATE TABLE IF NOT EXISTS workspaces (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			created_by INT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS workspace_members (
+			workspace_id INT NOT NULL,
+			user_id INT NOT NULL,
+			role VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (workspace_id, user_id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS folders (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			name VARCHAR(50) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+			);`,
 		`CREATE TABLE IF NOT EXISTS urls (
 			original_url TEXT NOT NULL,
-			shortened_url VARCHAR(8) PRIMARY KEY,
+			shortened_url VARCHAR(64) PRIMARY KEY,
 			user_id INT,
+			workspace_id INT NULL,
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			redirect_type SMALLINT NOT NULL DEFAULT 301,
+			password_hash VARCHAR(255) NULL,
+			expires_at TIMESTAMP NULL,
+			active_from TIMESTAMP NULL,
+			imported_clicks INT NOT NULL DEFAULT 0,
+			folder_id INT NULL,
+			title VARCHAR(255) NULL,
+			notes TEXT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id)
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id),
+			FOREIGN KEY (folder_id) REFERENCES folders(id)
 			);`,
 		`CREATE TABLE IF NOT EXISTS clicks (
 			id INT AUTO_INCREMENT PRIMARY KEY,
-			url_id VARCHAR(8) NOT NULL,
+			url_id VARCHAR(64) NOT NULL,
 			ip_address VARCHAR(50) NOT NULL,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			variant VARCHAR(50) NULL,
+			revision_id INT NULL,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS audit_logs (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			action VARCHAR(50) NOT NULL,
+			actor_id INT NULL,
+			target VARCHAR(255) NOT NULL,
+			ip_address VARCHAR(50) NOT NULL,
+			details TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+			);`,
+		`CREATE TABLE IF NOT EXISTS password_reset_tokens (
+			token_hash CHAR(64) PRIMARY KEY,
+			user_id INT NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			used_at TIMESTAMP NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS user_identities (
+			provider VARCHAR(50) NOT NULL,
+			subject VARCHAR(255) NOT NULL,
+			user_id INT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (provider, subject),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS tags (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			user_id INT NOT NULL,
+			name VARCHAR(50) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (user_id, name),
+			FOREIGN KEY (user_id) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_tags (
+			url_id VARCHAR(64) NOT NULL,
+			tag_id INT NOT NULL,
+			PRIMARY KEY (url_id, tag_id),
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url),
+			FOREIGN KEY (tag_id) REFERENCES tags(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_imports (
+			user_id INT NOT NULL,
+			source VARCHAR(255) NOT NULL,
+			url_id VARCHAR(64) NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (user_id, source),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_metadata (
+			url_id VARCHAR(64) PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL,
+			favicon_url TEXT NOT NULL,
+			fetched_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_rules (
+			url_id VARCHAR(64) PRIMARY KEY,
+			rules JSON NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
 			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
 			);`,
+		`CREATE TABLE IF NOT EXISTS url_splits (
+			url_id VARCHAR(64) PRIMARY KEY,
+			sticky VARCHAR(10) NOT NULL,
+			variants JSON NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_tracking (
+			url_id VARCHAR(64) PRIMARY KEY,
+			utm_source VARCHAR(100) NOT NULL,
+			utm_medium VARCHAR(100) NOT NULL,
+			utm_campaign VARCHAR(100) NOT NULL,
+			utm_term VARCHAR(100) NOT NULL,
+			utm_content VARCHAR(100) NOT NULL,
+			pass_query BOOLEAN NOT NULL DEFAULT FALSE,
+			query_conflict VARCHAR(10) NOT NULL,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_deep_links (
+			url_id VARCHAR(64) PRIMARY KEY,
+			ios_uri VARCHAR(2048) NOT NULL,
+			ios_fallback VARCHAR(2048) NOT NULL,
+			android_uri VARCHAR(2048) NOT NULL,
+			android_fallback VARCHAR(2048) NOT NULL,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_previews (
+			url_id VARCHAR(64) PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL,
+			image_url TEXT NOT NULL,
+			interstitial BOOLEAN NOT NULL DEFAULT FALSE,
+			delay_seconds INT NOT NULL DEFAULT 0,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS url_not_active_responses (
+			url_id VARCHAR(64) PRIMARY KEY,
+			mode VARCHAR(10) NOT NULL,
+			fallback_url TEXT NOT NULL,
+			message TEXT NOT NULL,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS link_revisions (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			url_id VARCHAR(64) NOT NULL,
+			destination TEXT NOT NULL,
+			previous_destination TEXT NOT NULL,
+			redirect_type SMALLINT NOT NULL,
+			rules JSON NOT NULL,
+			changed_by INT NULL,
+			restored_from INT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url),
+			FOREIGN KEY (changed_by) REFERENCES users(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS domains (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			hostname VARCHAR(253) NOT NULL,
+			user_id INT NOT NULL,
+			workspace_id INT NULL,
+			verification_token VARCHAR(64) NOT NULL,
+			verified_at TIMESTAMP NULL,
+			verified_hostname VARCHAR(253) NULL UNIQUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX (hostname),
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS domain_links (
+			domain_id INT NOT NULL,
+			code VARCHAR(64) NOT NULL,
+			url_id VARCHAR(64) NOT NULL UNIQUE,
+			PRIMARY KEY (domain_id, code),
+			FOREIGN KEY (domain_id) REFERENCES domains(id),
+			FOREIGN KEY (url_id) REFERENCES urls(shortened_url)
+			);`,
+		`CREATE TABLE IF NOT EXISTS webhooks (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			url TEXT NOT NULL,
+			user_id INT NOT NULL,
+			workspace_id INT NULL,
+			events VARCHAR(255) NOT NULL,
+			secret VARCHAR(64) NOT NULL,
+			active BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (user_id) REFERENCES users(id),
+			FOREIGN KEY (workspace_id) REFERENCES workspaces(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			webhook_id INT NOT NULL,
+			event_id VARCHAR(64) NOT NULL,
+			event_type VARCHAR(32) NOT NULL,
+			payload MEDIUMTEXT NOT NULL,
+			status VARCHAR(10) NOT NULL,
+			attempts INT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMP NOT NULL,
+			response_status INT NOT NULL DEFAULT 0,
+			error TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			INDEX (status, next_attempt_at),
+			FOREIGN KEY (webhook_id) REFERENCES webhooks(id)
+			);`,
+		`CREATE TABLE IF NOT EXISTS webhook_attempts (
+			id INT AUTO_INCREMENT PRIMARY KEY,
+			delivery_id INT NOT NULL,
+			response_status INT NOT NULL DEFAULT 0,
+			error TEXT NOT NULL,
+			duration_ms INT NOT NULL,
+			attempted_at TIMESTAMP NOT NULL,
+			FOREIGN KEY (delivery_id) REFERENCES webhook_deliveries(id)
+			);`,
 	}
 
 	// Execute queries
@@ -104,5 +360,96 @@ func migrations(db *sql.DB) error {
 		}
 	}
 
+	// Bring tables created by earlier versions up to date
+	if err := widenShortCodes(db); err != nil {
+		return err
+	}
+	if err := addColumns(db); err != nil {
+		return err
+	}
+	return shareHostnames(db)
+}
+
+// widenShortCodes widens the short code columns of databases created before custom aliases. Foreign key
+// checks are turned off on the connection meanwhile, as MySQL refuses to change the length of key columns
+// otherwise.
+func widenShortCodes(db *sql.DB) error {
+	// The clicks column is widened last, so an interrupted migration is run again
+	var length int
+	err := db.QueryRow(`SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'clicks' AND COLUMN_NAME = 'url_id'`).Scan(&length)
+	if err != nil {
+		return fmt.Errorf("failed to read the short code length: %v", err)
+	}
+	if length >= shortCodeLength {
+		return nil
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to widen short codes: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		return fmt.Errorf("failed to widen short codes: %v", err)
+	}
+	// Checks are turned on again before the connection goes back to the pool
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+
+	queries := []string{
+		fmt.Sprintf("ALTER TABLE urls MODIFY shortened_url VARCHAR(%d) NOT NULL", shortCodeLength),
+		fmt.Sprintf("ALTER TABLE clicks MODIFY url_id VARCHAR(%d) NOT NULL", shortCodeLength),
+	}
+	for _, query := range queries {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to widen short codes: %v", err)
+		}
+	}
+	return nil
+}
+
+// shareHostnames drops the unique key of domains.hostname from databases created before pending claims could
+// share a hostname. The verified claims reserve their hostnames through verified_hostname instead, which is
+// filled in before the key is dropped so an interrupted migration is run again.
+func shareHostnames(db *sql.DB) error {
+	var index string
+	err := db.QueryRow(`SELECT INDEX_NAME FROM information_schema.STATISTICS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'domains' AND COLUMN_NAME = 'hostname' AND NON_UNIQUE = 0`).Scan(&index)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the hostname key: %v", err)
+	}
+
+	queries := []string{
+		"UPDATE domains SET verified_hostname = hostname WHERE verified_at IS NOT NULL",
+		fmt.Sprintf("ALTER TABLE domains DROP INDEX `%s`, ADD INDEX (hostname)", index),
+	}
+	for _, query := range queries {
+		if _, err := db.Exec(query); err != nil {
+			return fmt.Errorf("failed to share hostnames: %v", err)
+		}
+	}
+	return nil
+}
+
+// addColumns adds the columns missing from tables created by earlier versions. Columns a table already has
+// are left as they are, so the migration runs on every start.
+func addColumns(db *sql.DB) error {
+	for _, c := range addedColumns {
+		query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)
+		if c.references != "" {
+			query += fmt.Sprintf(", ADD FOREIGN KEY (%s) REFERENCES %s", c.name, c.references)
+		}
+
+		_, err := db.Exec(query)
+		var mysqlErr *mysql.MySQLError
+		if err != nil && !(errors.As(err, &mysqlErr) && mysqlErr.Number == errDuplicateColumn) {
+			return fmt.Errorf("failed to add column %s.%s: %v", c.table, c.name, err)
+		}
+	}
 	return nil
 }
@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/joho/godotenv"
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/stretchr/testify/assert"
@@ -93,10 +94,58 @@ func TestMigrations(t *testing.T) {
 		// Set up expectations for the mock database query to ensure that the migration is successful
 		mock.ExpectExec("CREATE TABLE IF NOT EXISTS users").WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS workspaces").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS workspace_members").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS folders").WillReturnResult(sqlmock.NewResult(1, 1))
+
 		mock.ExpectExec("CREATE TABLE IF NOT EXISTS urls").WillReturnResult(sqlmock.NewResult(1, 1))
 
 		mock.ExpectExec("CREATE TABLE IF NOT EXISTS clicks").WillReturnResult(sqlmock.NewResult(1, 1))
 
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS audit_logs").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS password_reset_tokens").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS user_identities").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS tags").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_tags").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_imports").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_metadata").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_rules").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_splits").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_tracking").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_deep_links").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_previews").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS url_not_active_responses").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS link_revisions").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS domains").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS domain_links").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS webhooks").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS webhook_deliveries").WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec("CREATE TABLE IF NOT EXISTS webhook_attempts").WillReturnResult(sqlmock.NewResult(1, 1))
+
+		// The tables are up to date already
+		mock.ExpectQuery("SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS").WillReturnRows(sqlmock.NewRows([]string{"length"}).AddRow(64))
+		for _, c := range addedColumns {
+			mock.ExpectExec("ALTER TABLE " + c.table + " ADD COLUMN " + c.name).WillReturnError(&mysql.MySQLError{Number: 1060, Message: "Duplicate column name"})
+		}
+		mock.ExpectQuery("SELECT INDEX_NAME FROM information_schema.STATISTICS").WillReturnRows(sqlmock.NewRows([]string{"index"}))
+
 		// Call the migrations function
 		err = migrations(db)
 		if err != nil {
@@ -116,6 +165,111 @@ func TestMigrations(t *testing.T) {
 	})
 }
 
+func TestWidenShortCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("Widen short codes of a baseline schema", func(t *testing.T) {
+		mock.ExpectQuery("SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS").WillReturnRows(sqlmock.NewRows([]string{"length"}).AddRow(8))
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ALTER TABLE urls MODIFY shortened_url VARCHAR\\(64\\) NOT NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ALTER TABLE clicks MODIFY url_id VARCHAR\\(64\\) NOT NULL").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, widenShortCodes(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Leave widened short codes", func(t *testing.T) {
+		mock.ExpectQuery("SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS").WillReturnRows(sqlmock.NewRows([]string{"length"}).AddRow(64))
+
+		assert.NoError(t, widenShortCodes(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Turn foreign key checks on again when widening fails", func(t *testing.T) {
+		mock.ExpectQuery("SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS").WillReturnRows(sqlmock.NewRows([]string{"length"}).AddRow(8))
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 0").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("ALTER TABLE urls MODIFY shortened_url").WillReturnError(fmt.Errorf("error"))
+		mock.ExpectExec("SET FOREIGN_KEY_CHECKS = 1").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.Error(t, widenShortCodes(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed to read the short code length", func(t *testing.T) {
+		mock.ExpectQuery("SELECT CHARACTER_MAXIMUM_LENGTH FROM information_schema.COLUMNS").WillReturnError(fmt.Errorf("error"))
+
+		assert.Error(t, widenShortCodes(db))
+	})
+}
+
+func TestAddColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("Add the columns missing from a baseline schema", func(t *testing.T) {
+		for _, c := range addedColumns {
+			mock.ExpectExec("ALTER TABLE " + c.table + " ADD COLUMN " + c.name + " ").WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+
+		assert.NoError(t, addColumns(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Skip columns added before", func(t *testing.T) {
+		for _, c := range addedColumns {
+			mock.ExpectExec("ALTER TABLE " + c.table + " ADD COLUMN " + c.name + " ").WillReturnError(&mysql.MySQLError{Number: 1060, Message: "Duplicate column name"})
+		}
+
+		assert.NoError(t, addColumns(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed to add a column", func(t *testing.T) {
+		mock.ExpectExec("ALTER TABLE users ADD COLUMN email ").WillReturnError(fmt.Errorf("error"))
+
+		assert.Error(t, addColumns(db))
+	})
+}
+
+func TestShareHostnames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error creating mock database connection: %v", err)
+	}
+	defer db.Close()
+
+	t.Run("Move the hostname key of a baseline schema to the verified hostnames", func(t *testing.T) {
+		mock.ExpectQuery("SELECT INDEX_NAME FROM information_schema.STATISTICS").WillReturnRows(sqlmock.NewRows([]string{"index"}).AddRow("hostname"))
+		mock.ExpectExec("UPDATE domains SET verified_hostname = hostname WHERE verified_at IS NOT NULL").WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectExec("ALTER TABLE domains DROP INDEX `hostname`, ADD INDEX \\(hostname\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.NoError(t, shareHostnames(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Leave shared hostnames", func(t *testing.T) {
+		mock.ExpectQuery("SELECT INDEX_NAME FROM information_schema.STATISTICS").WillReturnRows(sqlmock.NewRows([]string{"index"}))
+
+		assert.NoError(t, shareHostnames(db))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed to share hostnames", func(t *testing.T) {
+		mock.ExpectQuery("SELECT INDEX_NAME FROM information_schema.STATISTICS").WillReturnRows(sqlmock.NewRows([]string{"index"}).AddRow("hostname"))
+		mock.ExpectExec("UPDATE domains SET verified_hostname").WillReturnError(fmt.Errorf("error"))
+
+		assert.Error(t, shareHostnames(db))
+	})
+}
+
 func TestCreateDatabase(t *testing.T) {
 	// Create a mock database connection
 	db, mock, err := sqlmock.New()
@@ -0,0 +1,358 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// acmeServer stands in for an ACME certificate authority. It validates HTTP challenges against the
+// redirect listener of the server under test and issues certificates from a test CA.
+type acmeServer struct {
+	*httptest.Server
+	t  *testing.T
+	ca *testCA
+	// challengeAddr is where HTTP challenges are fetched, the redirect listener of the server under test.
+	challengeAddr string
+
+	mu         sync.Mutex
+	thumbprint string
+	orders     map[string]*acmeOrder
+}
+
+// acmeOrder is an order for one domain, with its single authorization and challenge.
+type acmeOrder struct {
+	domain string
+	token  string
+	status string
+	cert   []byte
+}
+
+func newACMEServer(t *testing.T, ca *testCA) *acmeServer {
+	s := &acmeServer{t: t, ca: ca, orders: map[string]*acmeOrder{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *acmeServer) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", len(r.URL.Path)))
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch parts[0] {
+	case "directory":
+		s.json(w, http.StatusOK, map[string]string{
+			"newNonce":   s.URL + "/new-nonce",
+			"newAccount": s.URL + "/new-account",
+			"newOrder":   s.URL + "/new-order",
+			"revokeCert": s.URL + "/revoke-cert",
+			"keyChange":  s.URL + "/key-change",
+		})
+	case "new-nonce":
+		w.WriteHeader(http.StatusOK)
+	case "new-account":
+		s.newAccount(w, r)
+	case "new-order":
+		s.newOrder(w, r)
+	case "authz", "challenge", "order", "finalize", "cert":
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		order, ok := s.orders[parts[len(parts)-1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		s.serveOrder(w, r, parts[0], parts[1], order)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newAccount registers the account key, whose thumbprint authorizes challenges.
+func (s *acmeServer) newAccount(w http.ResponseWriter, r *http.Request) {
+	var header struct {
+		JWK struct {
+			X string `json:"x"`
+			Y string `json:"y"`
+		} `json:"jwk"`
+	}
+	if _, err := readJWS(r, &header); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	x, _ := base64.RawURLEncoding.DecodeString(header.JWK.X)
+	y, _ := base64.RawURLEncoding.DecodeString(header.JWK.Y)
+	thumbprint, err := acme.JWKThumbprint(&ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.thumbprint = thumbprint
+	s.mu.Unlock()
+	w.Header().Set("Location", s.URL+"/account/1")
+	s.json(w, http.StatusCreated, map[string]string{"status": "valid"})
+}
+
+// newOrder creates a pending order for the requested domain.
+func (s *acmeServer) newOrder(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Identifiers []struct {
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	body, err := readJWS(r, nil)
+	if err == nil {
+		err = json.Unmarshal(body, &payload)
+	}
+	if err != nil || len(payload.Identifiers) != 1 {
+		http.Error(w, "invalid order", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := fmt.Sprint(len(s.orders) + 1)
+	order := &acmeOrder{domain: payload.Identifiers[0].Value, token: "token-" + id, status: acme.StatusPending}
+	s.orders[id] = order
+	w.Header().Set("Location", s.URL+"/order/"+id)
+	s.json(w, http.StatusCreated, s.orderJSON(id, order))
+}
+
+// serveOrder answers the requests on the authorization, challenge, finalization and certificate of an order.
+func (s *acmeServer) serveOrder(w http.ResponseWriter, r *http.Request, resource, id string, order *acmeOrder) {
+	body, err := readJWS(r, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch resource {
+	case "authz":
+		s.json(w, http.StatusOK, s.authzJSON(id, order))
+	case "challenge":
+		if order.status == acme.StatusPending {
+			order.status = acme.StatusInvalid
+			if s.validate(order) == nil {
+				order.status = acme.StatusReady
+			}
+		}
+		s.json(w, http.StatusOK, s.challengeJSON(id, order))
+	case "order":
+		s.json(w, http.StatusOK, s.orderJSON(id, order))
+	case "finalize":
+		var payload struct {
+			CSR string `json:"csr"`
+		}
+		_ = json.Unmarshal(body, &payload)
+		der, _ := base64.RawURLEncoding.DecodeString(payload.CSR)
+		csr, err := x509.ParseCertificateRequest(der)
+		if order.status != acme.StatusReady || err != nil || len(csr.DNSNames) != 1 || csr.DNSNames[0] != order.domain {
+			http.Error(w, "invalid finalization", http.StatusForbidden)
+			return
+		}
+		order.cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.issue(s.t, csr.PublicKey, order.domain)})
+		order.cert = append(order.cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.ca.cert.Raw})...)
+		order.status = acme.StatusValid
+		s.json(w, http.StatusOK, s.orderJSON(id, order))
+	case "cert":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		_, _ = w.Write(order.cert)
+	}
+}
+
+// validate fetches the HTTP challenge of the order from the redirect listener, as the domain.
+func (s *acmeServer) validate(order *acmeOrder) error {
+	req, _ := http.NewRequest(http.MethodGet, "http://"+s.challengeAddr+"/.well-known/acme-challenge/"+order.token, nil)
+	req.Host = order.domain
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != order.token+"."+s.thumbprint {
+		return errors.New("invalid key authorization")
+	}
+	return nil
+}
+
+func (s *acmeServer) orderJSON(id string, order *acmeOrder) map[string]interface{} {
+	result := map[string]interface{}{
+		"status":         order.status,
+		"identifiers":    []map[string]string{{"type": "dns", "value": order.domain}},
+		"authorizations": []string{s.URL + "/authz/" + id},
+		"finalize":       s.URL + "/finalize/" + id,
+	}
+	if order.status == acme.StatusValid {
+		result["certificate"] = s.URL + "/cert/" + id
+	}
+	return result
+}
+
+func (s *acmeServer) authzJSON(id string, order *acmeOrder) map[string]interface{} {
+	status := acme.StatusValid
+	switch order.status {
+	case acme.StatusPending, acme.StatusInvalid:
+		status = order.status
+	}
+	return map[string]interface{}{
+		"status":     status,
+		"identifier": map[string]string{"type": "dns", "value": order.domain},
+		"challenges": []interface{}{s.challengeJSON(id, order)},
+	}
+}
+
+func (s *acmeServer) challengeJSON(id string, order *acmeOrder) map[string]interface{} {
+	status := acme.StatusValid
+	switch order.status {
+	case acme.StatusPending, acme.StatusInvalid:
+		status = order.status
+	}
+	return map[string]interface{}{"type": "http-01", "url": s.URL + "/challenge/" + id, "token": order.token, "status": status}
+}
+
+// orderCount returns the number of orders placed.
+func (s *acmeServer) orderCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.orders)
+}
+
+func (s *acmeServer) json(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// readJWS returns the payload of a JWS request, decoding its protected header into header when given.
+// Signatures are not checked.
+func readJWS(r *http.Request, header interface{}) ([]byte, error) {
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		return nil, err
+	}
+	if header != nil {
+		protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(protected, header); err != nil {
+			return nil, err
+		}
+	}
+	return base64.RawURLEncoding.DecodeString(jws.Payload)
+}
+
+// memoryCache is an autocert.Cache keeping certificates in memory.
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string][]byte
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.entries[key]
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, nil
+}
+
+func (c *memoryCache) Put(_ context.Context, key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = data
+	return nil
+}
+
+func (c *memoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func TestServer_ACME(t *testing.T) {
+	ca := newTestCA(t)
+	acmeCA := newACMEServer(t, ca)
+	cache := &memoryCache{entries: map[string][]byte{}}
+
+	server := newTestServer()
+	require.NoError(t, server.EnableTLS(TLSConfig{
+		ACME: &ACMEConfig{
+			Hosts: []string{"sho.rt"},
+			AllowHost: func(_ context.Context, host string) error {
+				if host != "go.example.com" {
+					return errors.New("not a verified domain")
+				}
+				return nil
+			},
+			DirectoryURL: acmeCA.URL + "/directory",
+			Cache:        cache,
+		},
+		RedirectAddr: "127.0.0.1:0",
+	}))
+	addr, redirectAddr := startTLSServer(t, server)
+	acmeCA.challengeAddr = redirectAddr
+
+	get := func(serverName string) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool, ServerName: serverName}}}
+		return client.Get("https://" + addr + "/")
+	}
+
+	t.Run("Should issue certificates for the configured and allowed hosts", func(t *testing.T) {
+		for _, host := range []string{"sho.rt", "go.example.com"} {
+			resp, err := get(host)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, []string{host}, resp.TLS.PeerCertificates[0].DNSNames)
+			_, err = cache.Get(context.Background(), host)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("Should serve cached certificates", func(t *testing.T) {
+		orders := acmeCA.orderCount()
+
+		resp, err := get("go.example.com")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, orders, acmeCA.orderCount())
+	})
+
+	t.Run("Should refuse other hosts", func(t *testing.T) {
+		_, err := get("evil.example.com")
+		assert.Error(t, err)
+
+		_, err = cache.Get(context.Background(), "evil.example.com")
+		assert.ErrorIs(t, err, autocert.ErrCacheMiss)
+	})
+}
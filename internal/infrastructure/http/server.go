@@ -2,24 +2,60 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	_ "net/http"
+	"net"
+	"net/http"
+	admin_handler "url-shortener/internal/app/handlers/admin"
 	"url-shortener/internal/app/handlers/auth"
 	clicks_handler "url-shortener/internal/app/handlers/clicks"
+	domain_handler "url-shortener/internal/app/handlers/domain"
+	export_handler "url-shortener/internal/app/handlers/export"
+	folder_handler "url-shortener/internal/app/handlers/folder"
+	oidc_handler "url-shortener/internal/app/handlers/oidc"
+	qr_handler "url-shortener/internal/app/handlers/qr"
+	tag_handler "url-shortener/internal/app/handlers/tag"
 	"url-shortener/internal/app/handlers/url"
+	webhook_handler "url-shortener/internal/app/handlers/webhook"
+	workspace_handler "url-shortener/internal/app/handlers/workspace"
+	ratelimit_middleware "url-shortener/internal/app/middleware/ratelimit"
 )
 
+// Handlers groups the handlers whose routes are served by the HTTP server.
+type Handlers struct {
+	User      *auth_handler.Handler
+	URL       *url_handler.Handler
+	Clicks    *clicks_handler.Handler
+	OIDC      *oidc_handler.Handler
+	Admin     *admin_handler.Handler
+	Workspace *workspace_handler.Handler
+	QR        *qr_handler.Handler
+	Export    *export_handler.Handler
+	Tag       *tag_handler.Handler
+	Folder    *folder_handler.Handler
+	Domain    *domain_handler.Handler
+	Webhook   *webhook_handler.Handler
+	// RateLimiter throttles shortening, redirects and QR codes, nil disables rate limiting.
+	RateLimiter *ratelimit_middleware.Limiter
+}
+
 // Server represents the HTTP server.
 type Server struct {
 	echo *echo.Echo
 	host string
 	port string
+	// tls serves HTTPS when set by EnableTLS, with the optional plain HTTP redirect listener.
+	tls              *tls.Config
+	redirectAddr     string
+	redirectHandler  http.Handler
+	redirect         *http.Server
+	redirectListener net.Listener
 }
 
 // NewServer creates a new instance of the HTTP server.
-func NewServer(host, port string, userHandler *auth_handler.Handler, urlHandler *url_handler.Handler, clickHandler *clicks_handler.Handler) *Server {
+func NewServer(host, port string, handlers Handlers) *Server {
 	e := echo.New()
 
 	// Middleware
@@ -36,27 +72,90 @@ func NewServer(host, port string, userHandler *auth_handler.Handler, urlHandler
 
 	clicksGroup := e.Group("/clicks")
 
-	authRouter(authGroup, userHandler)
+	adminGroup := e.Group("/admin", handlers.Admin.RequireAdmin())
+
+	workspaceGroup := e.Group("/workspaces")
+
+	exportGroup := e.Group("/export")
+
+	tagGroup := e.Group("/tags")
+
+	folderGroup := e.Group("/folders")
+
+	domainGroup := e.Group("/domains")
+
+	webhookGroup := e.Group("/webhooks")
+
+	authRouter(authGroup, handlers.User)
+
+	oidcRoute(authGroup.Group("/oidc"), handlers.OIDC)
+
+	urlRoute(urlGroup, handlers.URL, handlers.RateLimiter)
+
+	qrRoute(urlGroup, handlers.QR, handlers.RateLimiter)
+
+	clicksRoute(clicksGroup, handlers.Clicks, handlers.RateLimiter)
 
-	urlRoute(urlGroup, urlHandler)
+	wellKnownRoute(e.Group("/.well-known"), handlers.Clicks)
 
-	clicksRoute(clicksGroup, clickHandler)
+	adminRoute(adminGroup, handlers.Admin)
 
-	return &Server{
+	workspaceRoute(workspaceGroup, handlers.Workspace)
+
+	exportRoute(exportGroup, handlers.Export)
+
+	tagRoute(tagGroup, handlers.Tag)
+
+	folderRoute(folderGroup, urlGroup, handlers.Folder)
+
+	domainRoute(domainGroup, handlers.Domain)
+
+	webhookRoute(webhookGroup, handlers.Webhook)
+
+	// Custom domains serve their short links at the root
+	shortLinkRoute(e, handlers.Clicks, handlers.RateLimiter)
+
+	server := &Server{
 		echo: e,
 		host: host,
 		port: port,
 	}
+	server.TrustProxies(nil)
+	return server
+}
+
+// TrustProxies takes the IP address of clients from the X-Forwarded-For header of requests coming
+// through the given proxies. Other requests, and all of them without proxies, are known by the address
+// of their connection, so clients cannot pick the IP address the lockout and rate limits key on.
+func (s *Server) TrustProxies(proxies []*net.IPNet) {
+	if len(proxies) == 0 {
+		s.echo.IPExtractor = echo.ExtractIPDirect()
+		return
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		options = append(options, echo.TrustIPRange(proxy))
+	}
+	s.echo.IPExtractor = echo.ExtractIPFromXFFHeader(options...)
 }
 
-// Start starts the HTTP server.
+// Start starts the HTTP server, serving HTTPS once EnableTLS is called.
 func (s *Server) Start() error {
 	addr := fmt.Sprintf("%s:%s", s.host, s.port)
+	if s.tls != nil {
+		return s.startTLS(addr)
+	}
 	return s.echo.Start(addr)
 }
 
-// Shutdown shuts down the HTTP server.
+// Shutdown shuts down the HTTP server and its redirect listener.
 func (s *Server) Shutdown(ctx context.Context) error {
+	if s.redirect != nil {
+		if err := s.redirect.Shutdown(ctx); err != nil {
+			return err
+		}
+	}
 	return s.echo.Shutdown(ctx)
 }
 
@@ -64,14 +163,135 @@ func authRouter(group *echo.Group, userHandler *auth_handler.Handler) {
 	group.POST("/register/", userHandler.CreateUserHandler)
 	group.POST("/login/", userHandler.LoginUserHandler)
 	group.GET("/refresh-token/", userHandler.RefreshTokenHandler)
+	group.POST("/password/change/", userHandler.ChangePasswordHandler)
+	group.POST("/password/forgot/", userHandler.ForgotPasswordHandler)
+	group.POST("/password/reset/", userHandler.ResetPasswordHandler)
+	group.POST("/email/", userHandler.ChangeEmailHandler)
+	group.POST("/email/resend/", userHandler.ResendVerificationHandler)
+	group.GET("/email/verify/", userHandler.VerifyEmailHandler)
+}
+
+func oidcRoute(group *echo.Group, oidcHandler *oidc_handler.Handler) {
+	group.GET("/", oidcHandler.ProvidersHandler)
+	group.GET("/:provider/login/", oidcHandler.LoginHandler)
+	group.GET("/:provider/callback/", oidcHandler.CallbackHandler)
 }
 
-func urlRoute(group *echo.Group, urlHandler *url_handler.Handler) {
-	group.POST("/shorten/", urlHandler.ShortenURLHandler)
+func urlRoute(group *echo.Group, urlHandler *url_handler.Handler, limiter *ratelimit_middleware.Limiter) {
+	group.POST("/shorten/", urlHandler.ShortenURLHandler, limiter.Route(ratelimit_middleware.RouteShorten))
+	group.POST("/bulk/", urlHandler.BulkShortenHandler, limiter.Route(ratelimit_middleware.RouteBulk))
+	group.GET("/bulk/:job/", urlHandler.GetBulkJobHandler)
+	group.POST("/import/", urlHandler.ImportHandler, limiter.Route(ratelimit_middleware.RouteImport))
 	group.GET("/", urlHandler.GetUserUrlsHandler)
+	group.DELETE("/:code/", urlHandler.DeleteURLHandler)
+	group.POST("/:code/transfer/", urlHandler.TransferURLHandler)
+	group.PUT("/:code/password/", urlHandler.SetPasswordHandler)
+	group.PUT("/:code/details/", urlHandler.SetDetailsHandler)
+	group.GET("/:code/metadata/", urlHandler.GetMetadataHandler)
+	group.POST("/:code/metadata/", urlHandler.RefreshMetadataHandler)
+	group.GET("/:code/rules/", urlHandler.GetRulesHandler)
+	group.PUT("/:code/rules/", urlHandler.SetRulesHandler)
+	group.POST("/:code/rules/test/", urlHandler.DryRunRulesHandler)
+	group.GET("/:code/split/", urlHandler.GetSplitHandler)
+	group.PUT("/:code/split/", urlHandler.SetSplitHandler)
+	group.GET("/:code/tracking/", urlHandler.GetTrackingHandler)
+	group.PUT("/:code/tracking/", urlHandler.SetTrackingHandler)
+	group.GET("/:code/deeplink/", urlHandler.GetDeepLinkHandler)
+	group.PUT("/:code/deeplink/", urlHandler.SetDeepLinkHandler)
+	group.GET("/:code/preview/", urlHandler.GetPreviewHandler)
+	group.PUT("/:code/preview/", urlHandler.SetPreviewHandler)
+	group.GET("/:code/activation/", urlHandler.GetActivationHandler)
+	group.PUT("/:code/activation/", urlHandler.SetActivationHandler)
+	group.PUT("/:code/destination/", urlHandler.SetDestinationHandler)
+	group.GET("/:code/history/", urlHandler.GetHistoryHandler)
+	group.POST("/:code/rollback/:rev/", urlHandler.RollbackHandler)
+}
+
+func qrRoute(group *echo.Group, qrHandler *qr_handler.Handler, limiter *ratelimit_middleware.Limiter) {
+	group.GET("/:code/qr/", qrHandler.GetQRCodeHandler, limiter.Route(ratelimit_middleware.RouteQR))
 }
 
-func clicksRoute(group *echo.Group, clickHandler *clicks_handler.Handler) {
-	group.GET("/:id", clickHandler.CreateClickHandler)
+func clicksRoute(group *echo.Group, clickHandler *clicks_handler.Handler, limiter *ratelimit_middleware.Limiter) {
+	group.GET("/:id", clickHandler.CreateClickHandler, limiter.Route(ratelimit_middleware.RouteRedirect))
+	group.POST("/:id", clickHandler.UnlockHandler, limiter.Route(ratelimit_middleware.RouteUnlock))
 	group.GET("/:id/details/", clickHandler.GetUserClickDetailsHandler)
+	group.GET("/:id/variants/", clickHandler.GetVariantStatsHandler)
+	group.GET("/:id/revisions/", clickHandler.GetRevisionStatsHandler)
+}
+
+func shortLinkRoute(e *echo.Echo, clickHandler *clicks_handler.Handler, limiter *ratelimit_middleware.Limiter) {
+	e.GET("/:id", clickHandler.CreateClickHandler, limiter.Route(ratelimit_middleware.RouteRedirect))
+	e.POST("/:id", clickHandler.UnlockHandler, limiter.Route(ratelimit_middleware.RouteUnlock))
+}
+
+func wellKnownRoute(group *echo.Group, clickHandler *clicks_handler.Handler) {
+	group.GET("/apple-app-site-association", clickHandler.AppleAppSiteAssociationHandler)
+	group.GET("/assetlinks.json", clickHandler.AssetLinksHandler)
+}
+
+func adminRoute(group *echo.Group, adminHandler *admin_handler.Handler) {
+	group.GET("/users/", adminHandler.ListUsersHandler)
+	group.GET("/users/:id/", adminHandler.GetUserHandler)
+	group.GET("/users/:id/urls/", adminHandler.GetUserURLsHandler)
+	group.PUT("/users/:id/role/", adminHandler.SetRoleHandler)
+	group.POST("/users/:id/disable/", adminHandler.DisableUserHandler)
+	group.POST("/users/:id/enable/", adminHandler.EnableUserHandler)
+	group.GET("/urls/:code/", adminHandler.GetURLHandler)
+	group.POST("/urls/:code/disable/", adminHandler.DisableURLHandler)
+	group.POST("/urls/:code/enable/", adminHandler.EnableURLHandler)
+}
+
+func workspaceRoute(group *echo.Group, workspaceHandler *workspace_handler.Handler) {
+	group.POST("/", workspaceHandler.CreateWorkspaceHandler)
+	group.GET("/", workspaceHandler.ListWorkspacesHandler)
+	group.GET("/:id/members/", workspaceHandler.ListMembersHandler)
+	group.POST("/:id/members/", workspaceHandler.AddMemberHandler)
+	group.PUT("/:id/members/:user/", workspaceHandler.UpdateMemberHandler)
+	group.DELETE("/:id/members/:user/", workspaceHandler.RemoveMemberHandler)
+	group.GET("/:id/urls/", workspaceHandler.GetWorkspaceURLsHandler)
+}
+
+func exportRoute(group *echo.Group, exportHandler *export_handler.Handler) {
+	group.GET("/urls/", exportHandler.ExportURLsHandler)
+	group.GET("/clicks/", exportHandler.ExportClicksHandler)
+	group.GET("/jobs/:job/", exportHandler.GetExportJobHandler)
+	group.GET("/jobs/:job/download/", exportHandler.DownloadExportHandler)
+}
+
+func tagRoute(group *echo.Group, tagHandler *tag_handler.Handler) {
+	group.GET("/", tagHandler.ListTagsHandler)
+	group.POST("/", tagHandler.CreateTagHandler)
+	group.GET("/stats/", tagHandler.GetStatsHandler)
+	group.POST("/assign/", tagHandler.AssignTagsHandler)
+	group.PUT("/:id/", tagHandler.RenameTagHandler)
+	group.DELETE("/:id/", tagHandler.DeleteTagHandler)
+}
+
+func folderRoute(group, urlGroup *echo.Group, folderHandler *folder_handler.Handler) {
+	group.GET("/", folderHandler.ListFoldersHandler)
+	group.POST("/", folderHandler.CreateFolderHandler)
+	group.PUT("/:id/", folderHandler.RenameFolderHandler)
+	group.DELETE("/:id/", folderHandler.DeleteFolderHandler)
+	urlGroup.PUT("/:code/folder/", folderHandler.MoveURLHandler)
+}
+
+func domainRoute(group *echo.Group, domainHandler *domain_handler.Handler) {
+	group.GET("/", domainHandler.ListDomainsHandler)
+	group.POST("/", domainHandler.CreateDomainHandler)
+	group.GET("/:id/", domainHandler.GetDomainHandler)
+	group.DELETE("/:id/", domainHandler.DeleteDomainHandler)
+	group.POST("/:id/verify/", domainHandler.VerifyDomainHandler)
+	group.GET("/:id/links/", domainHandler.ListLinksHandler)
+}
+
+func webhookRoute(group *echo.Group, webhookHandler *webhook_handler.Handler) {
+	group.GET("/", webhookHandler.ListWebhooksHandler)
+	group.POST("/", webhookHandler.CreateWebhookHandler)
+	group.GET("/:id/", webhookHandler.GetWebhookHandler)
+	group.PUT("/:id/", webhookHandler.UpdateWebhookHandler)
+	group.DELETE("/:id/", webhookHandler.DeleteWebhookHandler)
+	group.POST("/:id/test/", webhookHandler.TestWebhookHandler)
+	group.GET("/:id/deliveries/", webhookHandler.ListDeliveriesHandler)
+	group.GET("/deliveries/:id/", webhookHandler.GetDeliveryHandler)
+	group.POST("/deliveries/:id/retry/", webhookHandler.RetryDeliveryHandler)
 }
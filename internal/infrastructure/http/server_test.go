@@ -5,30 +5,68 @@ import (
 	"errors"
 	"github.com/stretchr/testify/assert"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	admin_handler "url-shortener/internal/app/handlers/admin"
 	"url-shortener/internal/app/handlers/auth"
 	clicks_handler "url-shortener/internal/app/handlers/clicks"
+	domain_handler "url-shortener/internal/app/handlers/domain"
+	export_handler "url-shortener/internal/app/handlers/export"
+	folder_handler "url-shortener/internal/app/handlers/folder"
+	oidc_handler "url-shortener/internal/app/handlers/oidc"
+	qr_handler "url-shortener/internal/app/handlers/qr"
+	tag_handler "url-shortener/internal/app/handlers/tag"
 	url_handler "url-shortener/internal/app/handlers/url"
+	webhook_handler "url-shortener/internal/app/handlers/webhook"
+	workspace_handler "url-shortener/internal/app/handlers/workspace"
+	ratelimit_middleware "url-shortener/internal/app/middleware/ratelimit"
+	admin_service "url-shortener/internal/app/services/admin"
 	"url-shortener/internal/app/services/auth"
 	clicks_service "url-shortener/internal/app/services/clicks"
+	domain_service "url-shortener/internal/app/services/domain"
+	email_service "url-shortener/internal/app/services/email"
+	export_service "url-shortener/internal/app/services/export"
+	folder_service "url-shortener/internal/app/services/folder"
+	lockout_service "url-shortener/internal/app/services/lockout"
+	oidc_service "url-shortener/internal/app/services/oidc"
+	qr_service "url-shortener/internal/app/services/qr"
+	tag_service "url-shortener/internal/app/services/tag"
 	"url-shortener/internal/app/services/token"
 	url_service "url-shortener/internal/app/services/url"
+	webhook_service "url-shortener/internal/app/services/webhook"
+	workspace_service "url-shortener/internal/app/services/workspace"
 	"url-shortener/internal/mocks"
 )
 
 // TestServer_StartAndShutdown tests the functionality of starting and shutting down the HTTP server.
 func TestServer_StartAndShutdown(t *testing.T) {
 	// Setup
-	authService := auth_service.NewAuthService(mocks.NewMockUserRepository())
-	urlService := url_service.NewURLService(mocks.NewMockUrlRepository())
+	authService := auth_service.NewAuthService(mocks.NewMockUserRepository(), auth_service.DefaultPasswordPolicy(), mocks.NewMockMailer())
+	urlService := url_service.NewURLService(mocks.NewMockUrlRepository(), mocks.NewMockWorkspaceRepository())
 	tokenService := token_service.NewTokenService(os.Getenv("JWT_SECRET_KEY"))
 	clicksService := clicks_service.NewClicksService(mocks.NewMockClicksRepository())
-	userHandler := auth_handler.NewAuthHandler(authService, tokenService)                    // assuming NewHandler() creates a new instance
-	urlHandler := url_handler.NewURLHandler(urlService, tokenService)                        // assuming NewHandler() creates a new instance
-	clicksHandler := clicks_handler.NewClickHandler(clicksService, urlService, tokenService) // assuming NewHandler() creates a new instance
-	server := NewServer("localhost", "8080", userHandler, urlHandler, clicksHandler)
+	lockoutService := lockout_service.NewLockoutService(lockout_service.DefaultConfig(), mocks.NewMockAuditRepository())
+	emailService := email_service.NewEmailService(mocks.NewMockUserRepository(), mocks.NewMockMailer(), "secret", "")
+	userHandler := auth_handler.NewAuthHandler(authService, tokenService, lockoutService, emailService) // assuming NewHandler() creates a new instance
+	urlHandler := url_handler.NewURLHandler(urlService, tokenService, emailService)                     // assuming NewHandler() creates a new instance
+	clicksHandler := clicks_handler.NewClickHandler(clicksService, urlService, tokenService)            // assuming NewHandler() creates a new instance
+	oidcHandler := oidc_handler.NewOIDCHandler(oidc_service.NewOIDCService(nil, mocks.NewMockUserRepository()), tokenService)
+	adminService := admin_service.NewAdminService(mocks.NewMockUserRepository(), mocks.NewMockUrlRepository(), mocks.NewMockAuditRepository())
+	adminHandler := admin_handler.NewAdminHandler(adminService, tokenService, mocks.NewMockUserRepository())
+	workspaceService := workspace_service.NewWorkspaceService(mocks.NewMockWorkspaceRepository(), mocks.NewMockUserRepository())
+	workspaceHandler := workspace_handler.NewWorkspaceHandler(workspaceService, urlService, tokenService)
+	qrHandler := qr_handler.NewQRHandler(qr_service.NewQRService(nil, 0), urlService, "")
+	exportService := export_service.NewExportService(mocks.NewMockUrlRepository(), mocks.NewMockClicksRepository(), urlService, t.TempDir())
+	exportHandler := export_handler.NewExportHandler(exportService, tokenService)
+	tagHandler := tag_handler.NewTagHandler(tag_service.NewTagService(mocks.NewMockTagRepository(), urlService), tokenService)
+	folderHandler := folder_handler.NewFolderHandler(folder_service.NewFolderService(mocks.NewMockFolderRepository(), urlService), tokenService)
+	domainHandler := domain_handler.NewDomainHandler(domain_service.NewDomainService(mocks.NewMockDomainRepository(), urlService), tokenService)
+	webhookHandler := webhook_handler.NewWebhookHandler(webhook_service.NewWebhookService(mocks.NewMockWebhookRepository(), urlService), tokenService)
+	rateLimiter := ratelimit_middleware.NewLimiter(ratelimit_middleware.NewMemoryStore(), ratelimit_middleware.NewKeyFunc(tokenService), nil)
+	server := NewServer("localhost", "8080", Handlers{User: userHandler, URL: urlHandler, Clicks: clicksHandler, OIDC: oidcHandler, Admin: adminHandler, Workspace: workspaceHandler, QR: qrHandler, Export: exportHandler, Tag: tagHandler, Folder: folderHandler, Domain: domainHandler, Webhook: webhookHandler, RateLimiter: rateLimiter})
 
 	// Start server
 	go func() {
@@ -64,3 +102,31 @@ func TestServer_StartAndShutdown(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+func TestServer_TrustProxies(t *testing.T) {
+	clientIP := func(server *Server, remoteAddr, forwardedFor string) string {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		return server.echo.NewContext(req, httptest.NewRecorder()).RealIP()
+	}
+
+	t.Run("Should ignore X-Forwarded-For without trusted proxies", func(t *testing.T) {
+		server := newTestServer()
+		server.TrustProxies(nil)
+
+		assert.Equal(t, "203.0.113.7", clientIP(server, "203.0.113.7:1234", "198.51.100.1"))
+		assert.Equal(t, "127.0.0.1", clientIP(server, "127.0.0.1:1234", "198.51.100.1"))
+	})
+
+	t.Run("Should read X-Forwarded-For from trusted proxies only", func(t *testing.T) {
+		_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+		server := newTestServer()
+		server.TrustProxies([]*net.IPNet{proxies})
+
+		assert.Equal(t, "198.51.100.1", clientIP(server, "10.0.0.2:1234", "198.51.100.1"))
+		assert.Equal(t, "198.51.100.1", clientIP(server, "10.0.0.2:1234", "192.0.2.9, 198.51.100.1, 10.0.0.3"))
+		assert.Equal(t, "203.0.113.7", clientIP(server, "203.0.113.7:1234", "198.51.100.1"))
+		assert.Equal(t, "127.0.0.1", clientIP(server, "127.0.0.1:1234", "198.51.100.1"))
+	})
+}
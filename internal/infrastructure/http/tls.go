@@ -0,0 +1,300 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultReloadInterval is how often certificate files are checked for changes unless configured.
+const DefaultReloadInterval = time.Minute
+
+// TLSConfig configures HTTPS serving from certificate files, ACME or both. Certificate files are
+// served for the names they cover and ACME issues certificates for the others.
+type TLSConfig struct {
+	// CertFile and KeyFile hold a PEM certificate chain and its key, empty when not used.
+	CertFile string
+	KeyFile  string
+	// ReloadInterval is how often the certificate files are checked for changes; renewed files are
+	// served without a restart.
+	ReloadInterval time.Duration
+	// ACME issues certificates automatically, nil when disabled.
+	ACME *ACMEConfig
+	// RedirectAddr is the address of the plain HTTP listener redirecting to HTTPS and answering ACME
+	// HTTP challenges, empty when there is none.
+	RedirectAddr string
+	// RedirectPort is the port of the HTTPS URLs redirected to, the port of the server when empty.
+	// Port 443 is left out of the URLs.
+	RedirectPort string
+}
+
+// ACMEConfig configures the automatic issuance of certificates with ACME, such as Let's Encrypt.
+type ACMEConfig struct {
+	// Hosts are the hostnames of the shortener certificates are issued for.
+	Hosts []string
+	// AllowHost accepts other hostnames, such as verified custom domains; nil accepts none.
+	AllowHost func(ctx context.Context, host string) error
+	// Email is the contact address of the ACME account, optional.
+	Email string
+	// DirectoryURL is the directory of the ACME server, Let's Encrypt when empty.
+	DirectoryURL string
+	// Cache stores the account key and certificates; instances sharing it share certificates.
+	Cache autocert.Cache
+}
+
+// HSTSConfig configures the Strict-Transport-Security header of HTTPS responses.
+type HSTSConfig struct {
+	// MaxAge is how long browsers only use HTTPS for the host; zero sends no header.
+	MaxAge            time.Duration
+	IncludeSubdomains bool
+	Preload           bool
+}
+
+// Enabled reports whether the configuration serves HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.ACME != nil
+}
+
+// EnableTLS makes Start serve HTTPS with the configuration. Certificate files are loaded right away
+// so a broken configuration fails here rather than on the first connection.
+func (s *Server) EnableTLS(config TLSConfig) error {
+	if !config.Enabled() {
+		return errors.New("TLS needs certificate files or ACME")
+	}
+
+	var static *certReloader
+	if config.CertFile != "" {
+		if config.KeyFile == "" {
+			return errors.New("TLS certificate file needs a key file")
+		}
+		interval := config.ReloadInterval
+		if interval <= 0 {
+			interval = DefaultReloadInterval
+		}
+		var err error
+		if static, err = newCertReloader(config.CertFile, config.KeyFile, interval); err != nil {
+			return err
+		}
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: []string{"h2", "http/1.1"}}
+	var manager *autocert.Manager
+	if config.ACME != nil {
+		manager = newManager(*config.ACME)
+		// Keeps the protocol of TLS-ALPN challenges
+		tlsConfig = manager.TLSConfig()
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	tlsConfig.GetCertificate = getCertificate(static, manager)
+
+	var redirect http.Handler
+	if config.RedirectAddr != "" {
+		redirect = redirectHandler(config.RedirectPort, s.port)
+		if manager != nil {
+			redirect = manager.HTTPHandler(redirect)
+		}
+	}
+
+	s.tls = tlsConfig
+	s.redirectAddr = config.RedirectAddr
+	s.redirectHandler = redirect
+	return nil
+}
+
+// EnableHSTS sends the Strict-Transport-Security header on HTTPS responses, including those of a TLS
+// proxy setting X-Forwarded-Proto.
+func (s *Server) EnableHSTS(config HSTSConfig) {
+	maxAge := int(config.MaxAge / time.Second)
+	if maxAge <= 0 {
+		return
+	}
+	s.echo.Use(middleware.SecureWithConfig(middleware.SecureConfig{
+		HSTSMaxAge:            maxAge,
+		HSTSExcludeSubdomains: !config.IncludeSubdomains,
+		HSTSPreloadEnabled:    config.Preload,
+	}))
+}
+
+// startTLS serves HTTPS on the address, and the redirect listener when configured.
+func (s *Server) startTLS(addr string) error {
+	if s.redirectAddr != "" {
+		listener, err := net.Listen("tcp", s.redirectAddr)
+		if err != nil {
+			return err
+		}
+		s.redirect = &http.Server{Handler: s.redirectHandler, ReadHeaderTimeout: 10 * time.Second}
+		s.redirectListener = listener
+		go func() {
+			if err := s.redirect.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Println("[SERVER] HTTP redirect listener stopped:", err)
+			}
+		}()
+	}
+
+	s.echo.TLSServer.Addr = addr
+	s.echo.TLSServer.TLSConfig = s.tls
+	return s.echo.StartServer(s.echo.TLSServer)
+}
+
+// newManager creates the ACME certificate manager of the configuration.
+func newManager(config ACMEConfig) *autocert.Manager {
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      config.Cache,
+		HostPolicy: hostPolicy(config.Hosts, config.AllowHost),
+		Email:      config.Email,
+	}
+	if config.DirectoryURL != "" {
+		manager.Client = &acme.Client{DirectoryURL: config.DirectoryURL}
+	}
+	return manager
+}
+
+// hostPolicy accepts the hosts of the shortener and the hosts allowed by allowHost.
+func hostPolicy(hosts []string, allowHost func(ctx context.Context, host string) error) autocert.HostPolicy {
+	whitelist := autocert.HostWhitelist(hosts...)
+	return func(ctx context.Context, host string) error {
+		err := whitelist(ctx, host)
+		if err == nil || allowHost == nil {
+			return err
+		}
+		if err := allowHost(ctx, strings.ToLower(host)); err != nil {
+			return fmt.Errorf("acme: host %q is not configured: %w", host, err)
+		}
+		return nil
+	}
+}
+
+// getCertificate serves the certificate files for the names they cover and ACME certificates otherwise.
+func getCertificate(static *certReloader, manager *autocert.Manager) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if static != nil {
+			cert := static.certificate()
+			if manager == nil || hello.ServerName == "" || cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return cert, nil
+			}
+		}
+		return manager.GetCertificate(hello)
+	}
+}
+
+// redirectHandler redirects requests to the same URL over HTTPS, on the port or else the fallback port.
+// Other methods than GET and HEAD are redirected with 308 so they are repeated as they were sent.
+func redirectHandler(port, fallback string) http.Handler {
+	if port == "" {
+		port = fallback
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if host == "" {
+			http.Error(w, "Host header is required", http.StatusBadRequest)
+			return
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+
+		status := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+	})
+}
+
+// certReloader serves a certificate from files, loading them again when they change.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+// newCertReloader loads the certificate files, failing when they cannot be used.
+func newCertReloader(certFile, keyFile string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: interval, now: time.Now}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	r.checked = r.now()
+	return r, nil
+}
+
+// certificate returns the current certificate, loading the files again when they changed since the
+// last check. Files that cannot be loaded, such as a certificate written before its key, keep the
+// current certificate until the next check.
+func (r *certReloader) certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert
+	}
+	r.checked = now
+
+	modTime, err := r.lastModified()
+	if err == nil && !modTime.Equal(r.modTime) {
+		if err := r.load(); err != nil {
+			fmt.Printf("[TLS] Keeping the current certificate, %s could not be loaded: %v\n", r.certFile, err)
+		} else {
+			fmt.Printf("[TLS] Reloaded the certificate of %s\n", r.certFile)
+		}
+	}
+	return r.cert
+}
+
+func (r *certReloader) load() error {
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return err
+	}
+
+	r.cert, r.modTime = &cert, modTime
+	return nil
+}
+
+// lastModified returns the latest modification time of the certificate and key files.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
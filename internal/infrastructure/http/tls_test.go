@@ -0,0 +1,228 @@
+package http
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues certificates trusted by its pool.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pool *x509.CertPool
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &testCA{cert: cert, key: key, pool: pool}
+}
+
+// issue returns a DER certificate for the public key and names.
+func (ca *testCA) issue(t *testing.T, pub crypto.PublicKey, names ...string) []byte {
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: names[0]},
+		DNSNames:     names,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, pub, ca.key)
+	require.NoError(t, err)
+	return der
+}
+
+// writeCert writes a certificate for the names and its key to cert.pem and key.pem in the directory.
+func (ca *testCA) writeCert(t *testing.T, dir string, names ...string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.issue(t, &key.PublicKey, names...)}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// startTLSServer starts the server and returns the addresses of its HTTPS and redirect listeners.
+func startTLSServer(t *testing.T, server *Server) (string, string) {
+	go func() {
+		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("server error: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, server.Shutdown(context.Background()))
+	})
+
+	require.Eventually(t, func() bool { return server.echo.TLSListenerAddr() != nil }, 5*time.Second, 10*time.Millisecond)
+	redirectAddr := ""
+	if server.redirectListener != nil {
+		redirectAddr = server.redirectListener.Addr().String()
+	}
+	return server.echo.TLSListenerAddr().String(), redirectAddr
+}
+
+// newTestServer returns a server on a random local port answering "ok" on /.
+func newTestServer() *Server {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return &Server{echo: e, host: "127.0.0.1", port: "0"}
+}
+
+func TestCertReloader(t *testing.T) {
+	ca := newTestCA(t)
+	dir := t.TempDir()
+	certFile, keyFile := ca.writeCert(t, dir, "old.example.com")
+
+	reloader, err := newCertReloader(certFile, keyFile, time.Minute)
+	require.NoError(t, err)
+	now := time.Now()
+	reloader.now = func() time.Time { return now }
+	assert.Equal(t, []string{"old.example.com"}, reloader.certificate().Leaf.DNSNames)
+
+	t.Run("Should reload changed files after the interval", func(t *testing.T) {
+		ca.writeCert(t, dir, "new.example.com")
+		modTime := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+
+		assert.Equal(t, []string{"old.example.com"}, reloader.certificate().Leaf.DNSNames)
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, []string{"new.example.com"}, reloader.certificate().Leaf.DNSNames)
+	})
+
+	t.Run("Should keep the certificate when the files are broken", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0600))
+		modTime := time.Now().Add(2 * time.Hour)
+		require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+
+		now = now.Add(time.Minute)
+		assert.Equal(t, []string{"new.example.com"}, reloader.certificate().Leaf.DNSNames)
+	})
+
+	t.Run("Should fail on missing files", func(t *testing.T) {
+		_, err := newCertReloader(filepath.Join(dir, "missing.pem"), keyFile, time.Minute)
+		assert.Error(t, err)
+	})
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		name, port, method, target, host, location string
+		status                                     int
+	}{
+		{"default port", "443", http.MethodGet, "/abc?utm=1", "sho.rt:80", "https://sho.rt/abc?utm=1", http.StatusMovedPermanently},
+		{"other port", "8443", http.MethodHead, "/abc", "sho.rt:8080", "https://sho.rt:8443/abc", http.StatusMovedPermanently},
+		{"POST", "443", http.MethodPost, "/abc", "go.example.com", "https://go.example.com/abc", http.StatusPermanentRedirect},
+		{"IPv6", "443", http.MethodGet, "/", "[::1]:80", "https://[::1]/", http.StatusMovedPermanently},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+
+			redirectHandler(tt.port, "8080").ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.location, rec.Header().Get("Location"))
+		})
+	}
+
+	t.Run("Should fall back to the server port", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/abc", nil)
+		req.Host = "sho.rt"
+		rec := httptest.NewRecorder()
+
+		redirectHandler("", "8443").ServeHTTP(rec, req)
+
+		assert.Equal(t, "https://sho.rt:8443/abc", rec.Header().Get("Location"))
+	})
+}
+
+func TestServer_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.writeCert(t, t.TempDir(), "sho.rt")
+
+	server := newTestServer()
+	require.NoError(t, server.EnableTLS(TLSConfig{CertFile: certFile, KeyFile: keyFile, RedirectAddr: "127.0.0.1:0", RedirectPort: "443"}))
+	server.EnableHSTS(HSTSConfig{MaxAge: 365 * 24 * time.Hour, IncludeSubdomains: true, Preload: true})
+	addr, redirectAddr := startTLSServer(t, server)
+
+	t.Run("Should serve HTTPS with HSTS", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.pool, ServerName: "sho.rt"}}}
+
+		resp, err := client.Get("https://" + addr + "/")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "max-age=31536000; includeSubdomains; preload", resp.Header.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("Should redirect plain HTTP", func(t *testing.T) {
+		client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+		req, _ := http.NewRequest(http.MethodGet, "http://"+redirectAddr+"/abc", nil)
+		req.Host = "sho.rt"
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+		assert.Equal(t, "https://sho.rt/abc", resp.Header.Get("Location"))
+		assert.Empty(t, resp.Header.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("Should reject broken configurations", func(t *testing.T) {
+		assert.Error(t, newTestServer().EnableTLS(TLSConfig{}))
+		assert.Error(t, newTestServer().EnableTLS(TLSConfig{CertFile: certFile}))
+		assert.Error(t, newTestServer().EnableTLS(TLSConfig{CertFile: certFile, KeyFile: certFile}))
+	})
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer implements Mailer by writing messages to a file, or to stdout when no path is set.
+// It is intended for local development where no SMTP server is available.
+type LogMailer struct {
+	Path string
+
+	mu     sync.Mutex
+	stdout io.Writer
+}
+
+// NewLogMailer creates a new instance of LogMailer writing to the given path.
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{Path: path, stdout: os.Stdout}
+}
+
+// Send appends the message to the log.
+func (m *LogMailer) Send(message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := fmt.Sprintf("[MAIL] %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+
+	if m.Path == "" {
+		_, err := io.WriteString(m.stdout, entry)
+		return err
+	}
+
+	file, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(entry); err != nil {
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+	return nil
+}
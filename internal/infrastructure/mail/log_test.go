@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMailer_Send(t *testing.T) {
+	message := Message{To: "user@example.com", Subject: "Hello", Body: "Hello, World!"}
+
+	t.Run("Write to File", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "mail.log")
+		mailer := NewLogMailer(path)
+
+		assert.NoError(t, mailer.Send(message))
+		assert.NoError(t, mailer.Send(message))
+
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, bytes.Count(content, []byte("To: user@example.com")))
+		assert.Contains(t, string(content), "Hello, World!")
+	})
+
+	t.Run("Write to Stdout", func(t *testing.T) {
+		var out bytes.Buffer
+		mailer := NewLogMailer("")
+		mailer.stdout = &out
+
+		assert.NoError(t, mailer.Send(message))
+		assert.Contains(t, out.String(), "Subject: Hello")
+	})
+
+	t.Run("Failed to Open File", func(t *testing.T) {
+		mailer := NewLogMailer(filepath.Join(t.TempDir(), "missing", "mail.log"))
+
+		assert.Error(t, mailer.Send(message))
+	})
+}
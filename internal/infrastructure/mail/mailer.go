@@ -0,0 +1,13 @@
+package mail
+
+// Message represents an email sent by the application.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer defines an interface for delivering emails.
+type Mailer interface {
+	Send(message Message) error
+}
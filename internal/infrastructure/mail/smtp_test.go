@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"errors"
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPMailer_Send(t *testing.T) {
+	message := Message{To: "user@example.com", Subject: "Hello", Body: "Hello, World!"}
+
+	t.Run("Send Successfully", func(t *testing.T) {
+		mailer := NewSMTPMailer("smtp.example.com", "587", "user", "secret", "noreply@example.com")
+
+		var gotAddr, gotFrom string
+		var gotTo []string
+		var gotMsg []byte
+		var gotAuth smtp.Auth
+		mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAddr, gotAuth, gotFrom, gotTo, gotMsg = addr, a, from, to, msg
+			return nil
+		}
+
+		err := mailer.Send(message)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "smtp.example.com:587", gotAddr)
+		assert.NotNil(t, gotAuth)
+		assert.Equal(t, "noreply@example.com", gotFrom)
+		assert.Equal(t, []string{"user@example.com"}, gotTo)
+		assert.Contains(t, string(gotMsg), "Subject: Hello\r\n")
+		assert.Contains(t, string(gotMsg), "\r\n\r\nHello, World!")
+	})
+
+	t.Run("Send Without Auth", func(t *testing.T) {
+		mailer := NewSMTPMailer("localhost", "25", "", "", "noreply@example.com")
+
+		var gotAuth smtp.Auth
+		mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			gotAuth = a
+			return nil
+		}
+
+		assert.NoError(t, mailer.Send(message))
+		assert.Nil(t, gotAuth)
+	})
+
+	t.Run("Failed to Send", func(t *testing.T) {
+		mailer := NewSMTPMailer("localhost", "25", "", "", "noreply@example.com")
+		mailer.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			return errors.New("connection refused")
+		}
+
+		err := mailer.Send(message)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+	})
+}
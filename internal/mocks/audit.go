@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"errors"
+	"url-shortener/internal/app/models/audit"
+)
+
+// MockAuditRepository is a mock implementation of AuditRepository interface for testing purposes.
+type MockAuditRepository struct {
+	Entries []*audit_model.Entry
+}
+
+// NewMockAuditRepository creates a new instance of MockAuditRepository.
+func NewMockAuditRepository() *MockAuditRepository {
+	return &MockAuditRepository{
+		Entries: make([]*audit_model.Entry, 0),
+	}
+}
+
+// Create simulates inserting a new audit entry in the mock database.
+func (r *MockAuditRepository) Create(entry *audit_model.Entry) error {
+	// DB error can be simulated here
+	if entry.Action == "error" {
+		return errors.New("audit entry not created")
+	}
+
+	entry.ID = uint(len(r.Entries) + 1) // Simulate auto-incrementing ID
+	r.Entries = append(r.Entries, entry)
+	return nil
+}
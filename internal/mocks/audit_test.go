@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"testing"
+	"url-shortener/internal/app/models/audit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockAuditRepository_Create(t *testing.T) {
+	repo := NewMockAuditRepository()
+
+	t.Run("Create Entry Successfully", func(t *testing.T) {
+		entry := &audit_model.Entry{Action: audit_model.ActionLoginLockout, Target: "username:testuser"}
+
+		err := repo.Create(entry)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), entry.ID)
+		assert.Len(t, repo.Entries, 1)
+	})
+
+	t.Run("Failed to Create Entry", func(t *testing.T) {
+		err := repo.Create(&audit_model.Entry{Action: "error"})
+
+		assert.Error(t, err)
+		assert.Len(t, repo.Entries, 1)
+	})
+}
@@ -1,18 +1,33 @@
 package mocks
 
 import (
+	"errors"
+	"strings"
+	"time"
 	"url-shortener/internal/app/models/user"
 )
 
+// MockResetToken is a password reset token stored by MockUserRepository.
+type MockResetToken struct {
+	UserID    uint
+	ExpiresAt time.Time
+	Used      bool
+}
+
 // MockUserRepository is a mock implementation of UserRepository interface for testing purposes.
 type MockUserRepository struct {
-	Users map[uint]*user_model.User
+	Users       map[uint]*user_model.User
+	ResetTokens map[string]*MockResetToken
+	// Identities maps "provider:subject" to the linked auth ID.
+	Identities map[string]uint
 }
 
 // NewMockUserRepository creates a new instance of MockUserRepository.
 func NewMockUserRepository() *MockUserRepository {
 	return &MockUserRepository{
-		Users: make(map[uint]*user_model.User),
+		Users:       make(map[uint]*user_model.User),
+		ResetTokens: make(map[string]*MockResetToken),
+		Identities:  make(map[string]uint),
 	}
 }
 
@@ -23,8 +38,14 @@ func (r *MockUserRepository) Create(user *user_model.User) (*user_model.User, er
 		if u.Username == user.Username {
 			return nil, user_model.ErrUserAlreadyExists
 		}
+		if user.Email != "" && u.Email == user.Email {
+			return nil, user_model.ErrEmailAlreadyExists
+		}
 
 	}
+	if user.Role == "" {
+		user.Role = user_model.RoleUser
+	}
 	user.ID = uint(len(r.Users) + 1) // Simulate auto-incrementing ID
 	r.Users[user.ID] = user
 	return user, nil
@@ -48,3 +69,132 @@ func (r *MockUserRepository) GetByUsername(username string) (*user_model.User, e
 	// Return an error if auth not found
 	return nil, user_model.ErrUserNotFound
 }
+
+// GetByID simulates retrieving an auth by ID from the mock database.
+func (r *MockUserRepository) GetByID(id uint) (*user_model.User, error) {
+	user, ok := r.Users[id]
+	if !ok {
+		return nil, user_model.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdatePassword simulates replacing the hashed password of an auth in the mock database.
+func (r *MockUserRepository) UpdatePassword(id uint, password string) error {
+	user, ok := r.Users[id]
+	if !ok {
+		return user_model.ErrUserNotFound
+	}
+	user.Password = password
+	return nil
+}
+
+// CreateResetToken simulates storing a password reset token in the mock database.
+func (r *MockUserRepository) CreateResetToken(userID uint, tokenHash string, expiresAt time.Time) error {
+	r.ResetTokens[tokenHash] = &MockResetToken{UserID: userID, ExpiresAt: expiresAt}
+	return nil
+}
+
+// ConsumeResetToken simulates marking a password reset token as used in the mock database.
+func (r *MockUserRepository) ConsumeResetToken(tokenHash string) (uint, error) {
+	token, ok := r.ResetTokens[tokenHash]
+	if !ok || token.Used || time.Now().After(token.ExpiresAt) {
+		return 0, user_model.ErrInvalidResetToken
+	}
+	token.Used = true
+	return token.UserID, nil
+}
+
+// GetByEmail simulates retrieving an auth by email address from the mock database.
+func (r *MockUserRepository) GetByEmail(email string) (*user_model.User, error) {
+	for _, user := range r.Users {
+		if user.Email != "" && user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, user_model.ErrUserNotFound
+}
+
+// UpdateEmail simulates replacing the email address of an auth in the mock database.
+func (r *MockUserRepository) UpdateEmail(id uint, email string) error {
+	user, ok := r.Users[id]
+	if !ok {
+		return user_model.ErrUserNotFound
+	}
+	user.Email = email
+	user.EmailVerified = false
+	return nil
+}
+
+// SetEmailVerified simulates marking the email address of an auth as verified in the mock database.
+func (r *MockUserRepository) SetEmailVerified(id uint, email string) error {
+	user, ok := r.Users[id]
+	if !ok || user.Email != email {
+		return user_model.ErrInvalidVerificationToken
+	}
+	user.EmailVerified = true
+	return nil
+}
+
+// GetByIdentity simulates retrieving the auth linked to an identity provider subject from the mock database.
+func (r *MockUserRepository) GetByIdentity(provider, subject string) (*user_model.User, error) {
+	userID, ok := r.Identities[provider+":"+subject]
+	if !ok {
+		return nil, user_model.ErrUserNotFound
+	}
+	return r.GetByID(userID)
+}
+
+// CreateIdentity simulates linking an identity provider subject to an auth in the mock database.
+func (r *MockUserRepository) CreateIdentity(userID uint, provider, subject string) error {
+	if provider == "error" {
+		return errors.New("identity error")
+	}
+	r.Identities[provider+":"+subject] = userID
+	return nil
+}
+
+// ListUsers simulates searching users by username or email address in the mock database.
+func (r *MockUserRepository) ListUsers(search string, limit, offset int) ([]user_model.User, error) {
+	if search == "error" {
+		return nil, errors.New("list error")
+	}
+
+	users := make([]user_model.User, 0)
+	for id := uint(1); id <= uint(len(r.Users)); id++ {
+		user, ok := r.Users[id]
+		if !ok || !(strings.Contains(user.Username, search) || strings.Contains(user.Email, search)) {
+			continue
+		}
+		users = append(users, *user)
+	}
+
+	if offset >= len(users) {
+		return []user_model.User{}, nil
+	}
+	users = users[offset:]
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// UpdateRole simulates replacing the role of an auth in the mock database.
+func (r *MockUserRepository) UpdateRole(id uint, role string) error {
+	user, ok := r.Users[id]
+	if !ok {
+		return user_model.ErrUserNotFound
+	}
+	user.Role = role
+	return nil
+}
+
+// SetDisabled simulates disabling or re-enabling an auth in the mock database.
+func (r *MockUserRepository) SetDisabled(id uint, disabled bool) error {
+	user, ok := r.Users[id]
+	if !ok {
+		return user_model.ErrUserNotFound
+	}
+	user.Disabled = disabled
+	return nil
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"errors"
 	"testing"
+	"time"
 	"url-shortener/internal/app/models/user"
 
 	"github.com/stretchr/testify/assert"
@@ -78,3 +79,144 @@ func TestMockUserRepository_GetByUsername(t *testing.T) {
 	})
 
 }
+
+func TestMockUserRepository_GetByID(t *testing.T) {
+	repo := NewMockUserRepository()
+	user, _ := repo.Create(&user_model.User{Username: "testuser", Password: "password123"})
+
+	t.Run("Get User Successfully", func(t *testing.T) {
+		foundUser, err := repo.GetByID(user.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, user, foundUser)
+	})
+
+	t.Run("Failed to Get User with Non-existent ID", func(t *testing.T) {
+		_, err := repo.GetByID(99)
+
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+}
+
+func TestMockUserRepository_UpdatePassword(t *testing.T) {
+	repo := NewMockUserRepository()
+	user, _ := repo.Create(&user_model.User{Username: "testuser", Password: "password123"})
+
+	t.Run("Update Password Successfully", func(t *testing.T) {
+		assert.NoError(t, repo.UpdatePassword(user.ID, "newhash"))
+		assert.Equal(t, "newhash", repo.Users[user.ID].Password)
+	})
+
+	t.Run("Failed to Update Non-existent User", func(t *testing.T) {
+		assert.ErrorIs(t, repo.UpdatePassword(99, "newhash"), user_model.ErrUserNotFound)
+	})
+}
+
+func TestMockUserRepository_ResetTokens(t *testing.T) {
+	repo := NewMockUserRepository()
+
+	t.Run("Consume Reset Token Once", func(t *testing.T) {
+		assert.NoError(t, repo.CreateResetToken(1, "hash", time.Now().Add(time.Hour)))
+
+		userID, err := repo.ConsumeResetToken("hash")
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), userID)
+
+		_, err = repo.ConsumeResetToken("hash")
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+
+	t.Run("Failed to Consume Expired Token", func(t *testing.T) {
+		assert.NoError(t, repo.CreateResetToken(1, "expired", time.Now().Add(-time.Minute)))
+
+		_, err := repo.ConsumeResetToken("expired")
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+
+	t.Run("Failed to Consume Unknown Token", func(t *testing.T) {
+		_, err := repo.ConsumeResetToken("unknown")
+		assert.ErrorIs(t, err, user_model.ErrInvalidResetToken)
+	})
+}
+
+func TestMockUserRepository_Email(t *testing.T) {
+	repo := NewMockUserRepository()
+	user, _ := repo.Create(&user_model.User{Username: "testuser", Password: "password123", Email: "user@example.com"})
+
+	t.Run("Failed to Create User with Existing Email", func(t *testing.T) {
+		_, err := repo.Create(&user_model.User{Username: "other", Password: "password123", Email: "user@example.com"})
+
+		assert.ErrorIs(t, err, user_model.ErrEmailAlreadyExists)
+	})
+
+	t.Run("Get User by Email", func(t *testing.T) {
+		foundUser, err := repo.GetByEmail("user@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, user, foundUser)
+
+		_, err = repo.GetByEmail("missing@example.com")
+		assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+	})
+
+	t.Run("Verify and Update Email", func(t *testing.T) {
+		assert.ErrorIs(t, repo.SetEmailVerified(user.ID, "other@example.com"), user_model.ErrInvalidVerificationToken)
+		assert.NoError(t, repo.SetEmailVerified(user.ID, "user@example.com"))
+		assert.True(t, user.EmailVerified)
+
+		assert.NoError(t, repo.UpdateEmail(user.ID, "new@example.com"))
+		assert.Equal(t, "new@example.com", user.Email)
+		assert.False(t, user.EmailVerified)
+
+		assert.ErrorIs(t, repo.UpdateEmail(99, "new@example.com"), user_model.ErrUserNotFound)
+	})
+}
+
+func TestMockUserRepository_Identities(t *testing.T) {
+	repo := NewMockUserRepository()
+	user, _ := repo.Create(&user_model.User{Username: "testuser", Password: "password123"})
+
+	_, err := repo.GetByIdentity("example", "subject-1")
+	assert.ErrorIs(t, err, user_model.ErrUserNotFound)
+
+	assert.NoError(t, repo.CreateIdentity(user.ID, "example", "subject-1"))
+	foundUser, err := repo.GetByIdentity("example", "subject-1")
+	assert.NoError(t, err)
+	assert.Equal(t, user, foundUser)
+
+	assert.Error(t, repo.CreateIdentity(user.ID, "error", "subject-1"))
+}
+
+func TestMockUserRepository_Admin(t *testing.T) {
+	repo := NewMockUserRepository()
+	first, _ := repo.Create(&user_model.User{Username: "alice", Password: "password123"})
+	_, _ = repo.Create(&user_model.User{Username: "bob", Password: "password123", Email: "bob@example.com"})
+
+	t.Run("List Users", func(t *testing.T) {
+		users, err := repo.ListUsers("", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, user_model.RoleUser, users[0].Role)
+
+		users, _ = repo.ListUsers("example.com", 10, 0)
+		assert.Len(t, users, 1)
+
+		users, _ = repo.ListUsers("", 1, 1)
+		assert.Equal(t, "bob", users[0].Username)
+
+		users, _ = repo.ListUsers("", 10, 5)
+		assert.Empty(t, users)
+
+		_, err = repo.ListUsers("error", 10, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("Update Role and Disable", func(t *testing.T) {
+		assert.NoError(t, repo.UpdateRole(first.ID, user_model.RoleAdmin))
+		assert.Equal(t, user_model.RoleAdmin, first.Role)
+		assert.NoError(t, repo.SetDisabled(first.ID, true))
+		assert.True(t, first.Disabled)
+
+		assert.ErrorIs(t, repo.UpdateRole(99, user_model.RoleAdmin), user_model.ErrUserNotFound)
+		assert.ErrorIs(t, repo.SetDisabled(99, true), user_model.ErrUserNotFound)
+	})
+}
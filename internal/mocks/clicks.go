@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"errors"
 	clicks_model "url-shortener/internal/app/models/clicks"
 	"url-shortener/internal/app/models/url"
 )
@@ -8,9 +9,11 @@ import (
 // MockClicksRepository is a mock implementation of UrlRepository interface for testing purposes.
 type MockClicksRepository struct {
 	Urls map[uint]*url_model.URL
+	// Clicks holds the clicks read by IterateClicks, in insertion order.
+	Clicks []clicks_model.Clicks
 }
 
-func (m MockClicksRepository) CreateClick(shortURL, ipAddress string) error {
+func (m MockClicksRepository) CreateClick(shortURL, ipAddress, variant string, revision uint) error {
 	if shortURL == "invalid" {
 		return url_model.ErrClickNotCreated
 	}
@@ -32,3 +35,69 @@ func (m MockClicksRepository) GetClicks(shortURL string) ([]clicks_model.Clicks,
 	return []clicks_model.Clicks{}, nil
 
 }
+
+// IterateClicks simulates reading the clicks matching the filter from the mock database.
+// Clicks of users are those of the personal urls in Urls; the short url "error" fails.
+func (m MockClicksRepository) IterateClicks(filter clicks_model.Filter, fn func(*clicks_model.Clicks) error) error {
+	if filter.ShortURL == "error" {
+		return errors.New("query error")
+	}
+
+	for i := range m.Clicks {
+		click := m.Clicks[i]
+		if filter.ShortURL != "" && click.UrlID != filter.ShortURL {
+			continue
+		}
+		if filter.ShortURL == "" && !m.ownedBy(click.UrlID, filter.UserID) {
+			continue
+		}
+		if (filter.From != nil && click.CreatedAt.Before(*filter.From)) || (filter.To != nil && !click.CreatedAt.Before(*filter.To)) {
+			continue
+		}
+		if err := fn(&click); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m MockClicksRepository) ownedBy(shortURL string, userID uint) bool {
+	for _, u := range m.Urls {
+		if u.ShortenedURL == shortURL {
+			return u.UserID == userID && u.WorkspaceID == nil
+		}
+	}
+	return false
+}
+
+// CountVariantClicks simulates counting the clicks of an url by variant in the mock database.
+// The short url "error" fails.
+func (m MockClicksRepository) CountVariantClicks(shortURL string) (map[string]int, error) {
+	if shortURL == "error" {
+		return nil, errors.New("query error")
+	}
+
+	counts := make(map[string]int)
+	for _, click := range m.Clicks {
+		if click.UrlID == shortURL && click.Variant != "" {
+			counts[click.Variant]++
+		}
+	}
+	return counts, nil
+}
+
+// CountRevisionClicks simulates counting the clicks of an url by revision in the mock database.
+// The short url "error" fails.
+func (m MockClicksRepository) CountRevisionClicks(shortURL string) (map[uint]int, error) {
+	if shortURL == "error" {
+		return nil, errors.New("query error")
+	}
+
+	counts := make(map[uint]int)
+	for _, click := range m.Clicks {
+		if click.UrlID == shortURL {
+			counts[click.Revision]++
+		}
+	}
+	return counts, nil
+}
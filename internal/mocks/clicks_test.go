@@ -1,20 +1,25 @@
 package mocks
 
-import "testing"
+import (
+	"testing"
+	"time"
+	clicks_model "url-shortener/internal/app/models/clicks"
+	"url-shortener/internal/app/models/url"
+)
 
 func TestCreateClick(t *testing.T) {
 
 	mockRepository := NewMockClicksRepository()
 
 	t.Run("Create Click Successfully", func(t *testing.T) {
-		err := mockRepository.CreateClick("test-url", "127.0.0.1")
+		err := mockRepository.CreateClick("test-url", "127.0.0.1", "", 0)
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
 		}
 	})
 
 	t.Run("Failed to Create Click", func(t *testing.T) {
-		err := mockRepository.CreateClick("invalid", "127.0.0.1")
+		err := mockRepository.CreateClick("invalid", "127.0.0.1", "", 0)
 
 		if err == nil {
 
@@ -48,3 +53,82 @@ func TestGetClicks(t *testing.T) {
 		}
 	})
 }
+
+func TestIterateClicks(t *testing.T) {
+	mockRepository := NewMockClicksRepository()
+	mockRepository.Urls[1] = &url_model.URL{ShortenedURL: "mine", UserID: 1}
+	mockRepository.Urls[2] = &url_model.URL{ShortenedURL: "other", UserID: 2}
+	now := time.Now()
+	mockRepository.Clicks = []clicks_model.Clicks{
+		{ID: 1, UrlID: "mine", CreatedAt: now.Add(-time.Hour)},
+		{ID: 2, UrlID: "other", CreatedAt: now},
+		{ID: 3, UrlID: "mine", CreatedAt: now},
+	}
+
+	t.Run("Iterate Clicks of User Within Range", func(t *testing.T) {
+		var ids []uint
+		err := mockRepository.IterateClicks(clicks_model.Filter{UserID: 1, From: &now}, func(click *clicks_model.Clicks) error {
+			ids = append(ids, click.ID)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if len(ids) != 1 || ids[0] != 3 {
+			t.Errorf("Expected click 3, got %v", ids)
+		}
+	})
+
+	t.Run("Failed to Iterate Clicks", func(t *testing.T) {
+		err := mockRepository.IterateClicks(clicks_model.Filter{ShortURL: "error"}, func(click *clicks_model.Clicks) error { return nil })
+
+		if err == nil {
+			t.Errorf("Expected an error, got nil")
+		}
+	})
+}
+
+func TestCountVariantClicks(t *testing.T) {
+	mockRepository := NewMockClicksRepository()
+	mockRepository.Clicks = []clicks_model.Clicks{
+		{ID: 1, UrlID: "landing", Variant: "a"},
+		{ID: 2, UrlID: "landing", Variant: "a"},
+		{ID: 3, UrlID: "landing"},
+		{ID: 4, UrlID: "other", Variant: "b"},
+	}
+
+	counts, err := mockRepository.CountVariantClicks("landing")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(counts) != 1 || counts["a"] != 2 {
+		t.Errorf("Expected 2 clicks of variant a, got %v", counts)
+	}
+
+	if _, err := mockRepository.CountVariantClicks("error"); err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+}
+
+func TestCountRevisionClicks(t *testing.T) {
+	mockRepository := NewMockClicksRepository()
+	mockRepository.Clicks = []clicks_model.Clicks{
+		{ID: 1, UrlID: "docs"},
+		{ID: 2, UrlID: "docs", Revision: 2},
+		{ID: 3, UrlID: "docs", Revision: 2},
+		{ID: 4, UrlID: "other", Revision: 5},
+	}
+
+	counts, err := mockRepository.CountRevisionClicks("docs")
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if len(counts) != 2 || counts[0] != 1 || counts[2] != 2 {
+		t.Errorf("Expected 1 click before the first revision and 2 of revision 2, got %v", counts)
+	}
+
+	if _, err := mockRepository.CountRevisionClicks("error"); err == nil {
+		t.Errorf("Expected an error, got nil")
+	}
+}
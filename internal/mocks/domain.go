@@ -0,0 +1,163 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+)
+
+// MockDomainRepository is a mock implementation of DomainRepository interface for testing purposes.
+type MockDomainRepository struct {
+	Domains map[uint]*domain_model.Domain
+	// Links maps a domain ID to the short code of the link of each code.
+	Links map[uint]map[string]string
+	// Members are the workspace members listing workspace domains; share it with MockWorkspaceRepository.Members.
+	Members map[uint]map[uint]string
+}
+
+// NewMockDomainRepository creates a new instance of MockDomainRepository.
+func NewMockDomainRepository() *MockDomainRepository {
+	return &MockDomainRepository{
+		Domains: make(map[uint]*domain_model.Domain),
+		Links:   make(map[uint]map[string]string),
+		Members: make(map[uint]map[uint]string),
+	}
+}
+
+// List simulates retrieving the personal and workspace domains of a user by hostname from the mock database. User 0 fails.
+func (r *MockDomainRepository) List(userID uint) ([]domain_model.Domain, error) {
+	if userID == 0 {
+		return nil, errors.New("query error")
+	}
+	domains := make([]domain_model.Domain, 0)
+	for _, domain := range r.Domains {
+		if domain.WorkspaceID == nil && domain.UserID == userID {
+			domains = append(domains, *domain)
+		} else if domain.WorkspaceID != nil {
+			if _, ok := r.Members[*domain.WorkspaceID][userID]; ok {
+				domains = append(domains, *domain)
+			}
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].Hostname < domains[j].Hostname })
+	return domains, nil
+}
+
+// GetByID simulates retrieving a domain by ID from the mock database.
+func (r *MockDomainRepository) GetByID(id uint) (*domain_model.Domain, error) {
+	domain, ok := r.Domains[id]
+	if !ok {
+		return nil, domain_model.ErrDomainNotFound
+	}
+	found := *domain
+	return &found, nil
+}
+
+// GetByHostname simulates retrieving the verified claim of a hostname, or else its oldest pending claim, from the mock database.
+func (r *MockDomainRepository) GetByHostname(hostname string) (*domain_model.Domain, error) {
+	claims, _ := r.ListByHostname(hostname)
+	if len(claims) == 0 {
+		return nil, domain_model.ErrDomainNotFound
+	}
+	for _, claim := range claims {
+		if claim.Verified {
+			return r.GetByID(claim.ID)
+		}
+	}
+	return r.GetByID(claims[0].ID)
+}
+
+// ListByHostname simulates retrieving the claims of a hostname by ID from the mock database.
+func (r *MockDomainRepository) ListByHostname(hostname string) ([]domain_model.Domain, error) {
+	claims := make([]domain_model.Domain, 0)
+	for _, domain := range r.Domains {
+		if domain.Hostname == hostname {
+			claims = append(claims, *domain)
+		}
+	}
+	sort.Slice(claims, func(i, j int) bool { return claims[i].ID < claims[j].ID })
+	return claims, nil
+}
+
+// Create simulates inserting a new domain in the mock database. The hostname "error.example.com" fails.
+func (r *MockDomainRepository) Create(domain *domain_model.Domain) (*domain_model.Domain, error) {
+	if domain.Hostname == "error.example.com" {
+		return nil, errors.New("domain not created")
+	}
+	created := *domain
+	created.ID = uint(len(r.Domains) + 1) // Simulate auto-incrementing ID
+	created.CreatedAt = time.Now()
+	r.Domains[created.ID] = &created
+	return r.GetByID(created.ID)
+}
+
+// SetVerified simulates recording the verification of a domain in the mock database. Like the unique key of
+// the database, it refuses a hostname another claim was verified for.
+func (r *MockDomainRepository) SetVerified(id uint, verifiedAt time.Time) error {
+	domain, ok := r.Domains[id]
+	if !ok {
+		return domain_model.ErrDomainNotFound
+	}
+	for _, other := range r.Domains {
+		if other.ID != id && other.Hostname == domain.Hostname && other.Verified {
+			return domain_model.ErrDomainAlreadyExists
+		}
+	}
+	domain.Verified = true
+	domain.VerifiedAt = &verifiedAt
+	return nil
+}
+
+// Delete simulates deleting a domain from the mock database.
+func (r *MockDomainRepository) Delete(id uint) error {
+	if _, ok := r.Domains[id]; !ok {
+		return domain_model.ErrDomainNotFound
+	}
+	delete(r.Domains, id)
+	return nil
+}
+
+// CreateLink simulates mapping a code of a domain to a link in the mock database. The code "error" fails.
+func (r *MockDomainRepository) CreateLink(domainID uint, code, shortURL string) error {
+	if code == "error" {
+		return errors.New("link not created")
+	}
+	if r.Links[domainID] == nil {
+		r.Links[domainID] = make(map[string]string)
+	}
+	r.Links[domainID][code] = shortURL
+	return nil
+}
+
+// GetLink simulates retrieving the short code of the link with a code on a domain from the mock database.
+func (r *MockDomainRepository) GetLink(domainID uint, code string) (string, error) {
+	shortURL, ok := r.Links[domainID][code]
+	if !ok {
+		return "", url_model.ErrURLNotFound
+	}
+	return shortURL, nil
+}
+
+// GetLinkByShortURL simulates retrieving the domain link with a short code from the mock database.
+func (r *MockDomainRepository) GetLinkByShortURL(shortURL string) (*domain_model.Link, error) {
+	for domainID, links := range r.Links {
+		for code, linked := range links {
+			if linked == shortURL {
+				return &domain_model.Link{Domain: r.Domains[domainID].Hostname, Code: code, ShortenedURL: shortURL}, nil
+			}
+		}
+	}
+	return nil, url_model.ErrURLNotFound
+}
+
+// ListLinks simulates retrieving the links of a domain by code from the mock database.
+func (r *MockDomainRepository) ListLinks(domainID uint) ([]domain_model.Link, error) {
+	links := make([]domain_model.Link, 0)
+	for code, shortURL := range r.Links[domainID] {
+		links = append(links, domain_model.Link{Domain: r.Domains[domainID].Hostname, Code: code, ShortenedURL: shortURL})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Code < links[j].Code })
+	return links, nil
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/domain"
+	"url-shortener/internal/app/models/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockDomainRepository(t *testing.T) {
+	repo := NewMockDomainRepository()
+	workspaceID := uint(4)
+	repo.Members[workspaceID] = map[uint]string{2: "viewer"}
+
+	personal, err := repo.Create(&domain_model.Domain{Hostname: "go.example.com", UserID: 1})
+	assert.NoError(t, err)
+	shared, err := repo.Create(&domain_model.Domain{Hostname: "links.example.org", UserID: 1, WorkspaceID: &workspaceID})
+	assert.NoError(t, err)
+	_, err = repo.Create(&domain_model.Domain{Hostname: "error.example.com", UserID: 1})
+	assert.Error(t, err)
+
+	domains, err := repo.List(1)
+	assert.NoError(t, err)
+	assert.Len(t, domains, 1)
+	domains, err = repo.List(2)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.ID, domains[0].ID)
+	_, err = repo.List(0)
+	assert.Error(t, err)
+
+	squatted, err := repo.Create(&domain_model.Domain{Hostname: "go.example.com", UserID: 2})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.SetVerified(personal.ID, time.Now()))
+	found, err := repo.GetByHostname("go.example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, personal.ID, found.ID)
+	assert.True(t, found.Verified)
+	claims, err := repo.ListByHostname("go.example.com")
+	assert.NoError(t, err)
+	assert.Len(t, claims, 2)
+	assert.ErrorIs(t, repo.SetVerified(squatted.ID, time.Now()), domain_model.ErrDomainAlreadyExists)
+
+	assert.NoError(t, repo.CreateLink(personal.ID, "launch", "abc12345"))
+	assert.Error(t, repo.CreateLink(personal.ID, "error", "abc12345"))
+	shortURL, err := repo.GetLink(personal.ID, "launch")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc12345", shortURL)
+	_, err = repo.GetLink(shared.ID, "launch")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	link, err := repo.GetLinkByShortURL("abc12345")
+	assert.NoError(t, err)
+	assert.Equal(t, "launch", link.Code)
+	_, err = repo.GetLinkByShortURL("missing")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	links, err := repo.ListLinks(personal.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []domain_model.Link{{Domain: "go.example.com", Code: "launch", ShortenedURL: "abc12345"}}, links)
+
+	assert.NoError(t, repo.Delete(shared.ID))
+	assert.ErrorIs(t, repo.Delete(shared.ID), domain_model.ErrDomainNotFound)
+	assert.ErrorIs(t, repo.SetVerified(shared.ID, time.Now()), domain_model.ErrDomainNotFound)
+}
@@ -0,0 +1,110 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"url-shortener/internal/app/models/folder"
+	"url-shortener/internal/app/models/url"
+)
+
+// MockFolderRepository is a mock implementation of FolderRepository interface for testing purposes.
+type MockFolderRepository struct {
+	Folders map[uint]*folder_model.Folder
+	// Owners holds the user ID of each folder.
+	Owners map[uint]uint
+	// Urls are the urls counted in folders; share it with MockUrlRepository.Urls.
+	Urls map[uint]*url_model.URL
+}
+
+// NewMockFolderRepository creates a new instance of MockFolderRepository.
+func NewMockFolderRepository() *MockFolderRepository {
+	return &MockFolderRepository{
+		Folders: make(map[uint]*folder_model.Folder),
+		Owners:  make(map[uint]uint),
+		Urls:    make(map[uint]*url_model.URL),
+	}
+}
+
+// List simulates retrieving the folders of a user by name from the mock database. User 0 fails.
+func (r *MockFolderRepository) List(userID uint) ([]folder_model.Folder, error) {
+	if userID == 0 {
+		return nil, errors.New("query error")
+	}
+	folders := make([]folder_model.Folder, 0)
+	for id, folder := range r.Folders {
+		if r.Owners[id] == userID {
+			folders = append(folders, r.counted(folder))
+		}
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i].Name < folders[j].Name })
+	return folders, nil
+}
+
+// GetByID simulates retrieving a folder of a user by ID from the mock database.
+func (r *MockFolderRepository) GetByID(userID, id uint) (*folder_model.Folder, error) {
+	folder, ok := r.Folders[id]
+	if !ok || r.Owners[id] != userID {
+		return nil, folder_model.ErrFolderNotFound
+	}
+	counted := r.counted(folder)
+	return &counted, nil
+}
+
+// GetByName simulates retrieving a folder of a user by name from the mock database.
+func (r *MockFolderRepository) GetByName(userID uint, name string) (*folder_model.Folder, error) {
+	for id, folder := range r.Folders {
+		if folder.Name == name && r.Owners[id] == userID {
+			counted := r.counted(folder)
+			return &counted, nil
+		}
+	}
+	return nil, folder_model.ErrFolderNotFound
+}
+
+// Create simulates inserting a new folder in the mock database. The name "error" fails.
+func (r *MockFolderRepository) Create(userID uint, name string) (*folder_model.Folder, error) {
+	if name == "error" {
+		return nil, errors.New("folder not created")
+	}
+	id := uint(len(r.Owners) + 1) // Simulate auto-incrementing ID
+	r.Folders[id] = &folder_model.Folder{ID: id, Name: name, CreatedAt: time.Now()}
+	r.Owners[id] = userID
+	return r.GetByID(userID, id)
+}
+
+// Rename simulates renaming a folder in the mock database.
+func (r *MockFolderRepository) Rename(id uint, name string) error {
+	folder, ok := r.Folders[id]
+	if !ok {
+		return folder_model.ErrFolderNotFound
+	}
+	folder.Name = name
+	return nil
+}
+
+// Delete simulates moving the urls of a folder out of it and deleting it in the mock database.
+func (r *MockFolderRepository) Delete(id uint) error {
+	if _, ok := r.Folders[id]; !ok {
+		return folder_model.ErrFolderNotFound
+	}
+	for _, u := range r.Urls {
+		if u.FolderID != nil && *u.FolderID == id {
+			u.FolderID = nil
+		}
+	}
+	delete(r.Folders, id)
+	return nil
+}
+
+// counted returns a copy of the folder with the number of personal urls in it.
+func (r *MockFolderRepository) counted(folder *folder_model.Folder) folder_model.Folder {
+	counted := *folder
+	counted.URLCount = 0
+	for _, u := range r.Urls {
+		if u.FolderID != nil && *u.FolderID == folder.ID && u.WorkspaceID == nil {
+			counted.URLCount++
+		}
+	}
+	return counted
+}
@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"testing"
+	"url-shortener/internal/app/models/folder"
+	"url-shortener/internal/app/models/url"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockFolderRepository(t *testing.T) {
+	repo := NewMockFolderRepository()
+
+	folder, err := repo.Create(1, "Campaigns")
+	assert.NoError(t, err)
+	_, err = repo.Create(1, "error")
+	assert.Error(t, err)
+	repo.Urls[1] = &url_model.URL{ShortenedURL: "abc", UserID: 1, FolderID: &folder.ID}
+
+	folders, err := repo.List(1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, folders[0].URLCount)
+	_, err = repo.List(0)
+	assert.Error(t, err)
+
+	_, err = repo.GetByName(2, "Campaigns")
+	assert.ErrorIs(t, err, folder_model.ErrFolderNotFound)
+
+	assert.NoError(t, repo.Rename(folder.ID, "Archive"))
+	renamed, _ := repo.GetByID(1, folder.ID)
+	assert.Equal(t, "Archive", renamed.Name)
+
+	assert.NoError(t, repo.Delete(folder.ID))
+	assert.Nil(t, repo.Urls[1].FolderID)
+	assert.ErrorIs(t, repo.Delete(folder.ID), folder_model.ErrFolderNotFound)
+	assert.ErrorIs(t, repo.Rename(folder.ID, "Archive"), folder_model.ErrFolderNotFound)
+}
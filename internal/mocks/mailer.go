@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"errors"
+	"url-shortener/internal/infrastructure/mail"
+)
+
+// MockMailer is a mock implementation of Mailer interface that captures sent messages for testing purposes.
+type MockMailer struct {
+	Messages []mail.Message
+}
+
+// NewMockMailer creates a new instance of MockMailer.
+func NewMockMailer() *MockMailer {
+	return &MockMailer{
+		Messages: make([]mail.Message, 0),
+	}
+}
+
+// Send simulates delivering a message by capturing it.
+func (m *MockMailer) Send(message mail.Message) error {
+	// Delivery error can be simulated here
+	if message.To == "error" {
+		return errors.New("mail not sent")
+	}
+
+	m.Messages = append(m.Messages, message)
+	return nil
+}
+
+// Last returns the most recently captured message.
+func (m *MockMailer) Last() (mail.Message, bool) {
+	if len(m.Messages) == 0 {
+		return mail.Message{}, false
+	}
+	return m.Messages[len(m.Messages)-1], true
+}
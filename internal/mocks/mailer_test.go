@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"testing"
+	"url-shortener/internal/infrastructure/mail"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockMailer_Send(t *testing.T) {
+	mailer := NewMockMailer()
+
+	t.Run("Capture Message", func(t *testing.T) {
+		_, ok := mailer.Last()
+		assert.False(t, ok)
+
+		err := mailer.Send(mail.Message{To: "user@example.com", Subject: "Hello"})
+
+		assert.NoError(t, err)
+		last, ok := mailer.Last()
+		assert.True(t, ok)
+		assert.Equal(t, "Hello", last.Subject)
+	})
+
+	t.Run("Failed to Send", func(t *testing.T) {
+		err := mailer.Send(mail.Message{To: "error"})
+
+		assert.Error(t, err)
+		assert.Len(t, mailer.Messages, 1)
+	})
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockOIDCProvider(t *testing.T) {
+	provider := NewMockOIDCProvider()
+	defer provider.Server.Close()
+
+	verifier := "verifier"
+	challenge := sha256.Sum256([]byte(verifier))
+	authURL := provider.Issuer() + "/authorize?" + url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"client"},
+		"redirect_uri":          {"http://localhost/callback"},
+		"state":                 {"state"},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode()
+
+	code, state, err := provider.Authorize(authURL)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, code)
+	assert.Equal(t, "state", state)
+
+	t.Run("Should reject wrong code verifier", func(t *testing.T) {
+		code, _, _ := provider.Authorize(authURL)
+		resp, err := http.PostForm(provider.Issuer()+"/token", url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"client_id":     {"client"},
+			"redirect_uri":  {"http://localhost/callback"},
+			"code_verifier": {"wrong"},
+		})
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Should issue ID token once", func(t *testing.T) {
+		form := url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"client_id":     {"client"},
+			"redirect_uri":  {"http://localhost/callback"},
+			"code_verifier": {verifier},
+		}
+		resp, err := http.PostForm(provider.Issuer()+"/token", form)
+		assert.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.NotEmpty(t, body["id_token"])
+
+		replay, err := http.PostForm(provider.Issuer()+"/token", form)
+		assert.NoError(t, err)
+		defer replay.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, replay.StatusCode)
+	})
+
+	t.Run("Should reject authorization without PKCE", func(t *testing.T) {
+		_, _, err := provider.Authorize(provider.Issuer() + "/authorize?response_type=code")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,167 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"url-shortener/internal/app/models/tag"
+)
+
+// MockTagRepository is a mock implementation of TagRepository interface for testing purposes.
+type MockTagRepository struct {
+	Tags map[uint]*tag_model.Tag
+	// Owners holds the user ID of each tag.
+	Owners map[uint]uint
+	// URLTags holds the tag names of urls by short code; share it with MockUrlRepository.Tags
+	// for tag filters to see assignments.
+	URLTags map[string][]string
+}
+
+// NewMockTagRepository creates a new instance of MockTagRepository.
+func NewMockTagRepository() *MockTagRepository {
+	return &MockTagRepository{
+		Tags:    make(map[uint]*tag_model.Tag),
+		Owners:  make(map[uint]uint),
+		URLTags: make(map[string][]string),
+	}
+}
+
+// List simulates retrieving the tags of a user by name from the mock database. User 0 fails.
+func (r *MockTagRepository) List(userID uint) ([]tag_model.Tag, error) {
+	if userID == 0 {
+		return nil, errors.New("query error")
+	}
+	tags := make([]tag_model.Tag, 0)
+	for _, tag := range r.Tags {
+		if r.Owners[tag.ID] == userID {
+			tags = append(tags, r.counted(tag))
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// GetByID simulates retrieving a tag of a user by ID from the mock database.
+func (r *MockTagRepository) GetByID(userID, id uint) (*tag_model.Tag, error) {
+	tag, ok := r.Tags[id]
+	if !ok || r.Owners[id] != userID {
+		return nil, tag_model.ErrTagNotFound
+	}
+	counted := r.counted(tag)
+	return &counted, nil
+}
+
+// GetByName simulates retrieving a tag of a user by name from the mock database.
+func (r *MockTagRepository) GetByName(userID uint, name string) (*tag_model.Tag, error) {
+	for id, tag := range r.Tags {
+		if tag.Name == name && r.Owners[id] == userID {
+			counted := r.counted(tag)
+			return &counted, nil
+		}
+	}
+	return nil, tag_model.ErrTagNotFound
+}
+
+// Create simulates inserting a new tag in the mock database. The name "error" fails.
+func (r *MockTagRepository) Create(userID uint, name string) (*tag_model.Tag, error) {
+	if name == "error" {
+		return nil, errors.New("tag not created")
+	}
+	id := uint(len(r.Owners) + 1) // Simulate auto-incrementing ID
+	r.Tags[id] = &tag_model.Tag{ID: id, Name: name, CreatedAt: time.Now()}
+	r.Owners[id] = userID
+	return r.GetByID(userID, id)
+}
+
+// Rename simulates renaming a tag in the mock database.
+func (r *MockTagRepository) Rename(id uint, name string) error {
+	tag, ok := r.Tags[id]
+	if !ok {
+		return tag_model.ErrTagNotFound
+	}
+	for code, names := range r.URLTags {
+		for i, n := range names {
+			if n == tag.Name {
+				r.URLTags[code][i] = name
+			}
+		}
+	}
+	tag.Name = name
+	return nil
+}
+
+// Delete simulates removing a tag from its urls and deleting it in the mock database.
+func (r *MockTagRepository) Delete(id uint) error {
+	tag, ok := r.Tags[id]
+	if !ok {
+		return tag_model.ErrTagNotFound
+	}
+	for code := range r.URLTags {
+		r.URLTags[code] = without(r.URLTags[code], tag.Name)
+	}
+	delete(r.Tags, id)
+	return nil
+}
+
+// Assign simulates adding and removing tags of urls in the mock database, creating missing tags.
+// The short code "error" fails.
+func (r *MockTagRepository) Assign(userID uint, shortCodes, add, remove []string) error {
+	for _, code := range shortCodes {
+		if code == "error" {
+			return errors.New("assign error")
+		}
+	}
+	for _, name := range add {
+		if _, err := r.GetByName(userID, name); err != nil {
+			if _, err := r.Create(userID, name); err != nil {
+				return err
+			}
+		}
+		for _, code := range shortCodes {
+			if !contains(r.URLTags[code], name) {
+				r.URLTags[code] = append(r.URLTags[code], name)
+			}
+		}
+	}
+	for _, name := range remove {
+		for _, code := range shortCodes {
+			r.URLTags[code] = without(r.URLTags[code], name)
+		}
+	}
+	return nil
+}
+
+// Stats simulates aggregating the urls of each tag of a user; the mock records no clicks. User 0 fails.
+func (r *MockTagRepository) Stats(userID uint) ([]tag_model.Stats, error) {
+	tags, err := r.List(userID)
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]tag_model.Stats, 0, len(tags))
+	for _, tag := range tags {
+		stats = append(stats, tag_model.Stats{TagID: tag.ID, Name: tag.Name, URLs: tag.URLCount})
+	}
+	return stats, nil
+}
+
+// counted returns a copy of the tag with the number of urls having it.
+func (r *MockTagRepository) counted(tag *tag_model.Tag) tag_model.Tag {
+	counted := *tag
+	counted.URLCount = 0
+	for _, names := range r.URLTags {
+		if contains(names, tag.Name) {
+			counted.URLCount++
+		}
+	}
+	return counted
+}
+
+func without(values []string, value string) []string {
+	kept := values[:0]
+	for _, v := range values {
+		if v != value {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"testing"
+	"url-shortener/internal/app/models/tag"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockTagRepository(t *testing.T) {
+	repo := NewMockTagRepository()
+
+	tag, err := repo.Create(1, "docs")
+	assert.NoError(t, err)
+	_, err = repo.Create(1, "error")
+	assert.Error(t, err)
+
+	assert.NoError(t, repo.Assign(1, []string{"abc", "def"}, []string{"docs", "team"}, nil))
+	assert.NoError(t, repo.Assign(1, []string{"def"}, nil, []string{"docs"}))
+	assert.Error(t, repo.Assign(1, []string{"error"}, []string{"docs"}, nil))
+
+	tags, err := repo.List(1)
+	assert.NoError(t, err)
+	assert.Len(t, tags, 2)
+	assert.Equal(t, 1, tags[0].URLCount)
+
+	_, err = repo.GetByID(2, tag.ID)
+	assert.ErrorIs(t, err, tag_model.ErrTagNotFound)
+
+	assert.NoError(t, repo.Rename(tag.ID, "guides"))
+	assert.Equal(t, []string{"guides", "team"}, repo.URLTags["abc"])
+	assert.NoError(t, repo.Delete(tag.ID))
+	assert.Equal(t, []string{"team"}, repo.URLTags["abc"])
+	assert.ErrorIs(t, repo.Delete(tag.ID), tag_model.ErrTagNotFound)
+
+	stats, err := repo.Stats(1)
+	assert.NoError(t, err)
+	assert.Equal(t, []tag_model.Stats{{TagID: 2, Name: "team", URLs: 2}}, stats)
+	_, err = repo.Stats(0)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"url-shortener/internal/app/models/url"
 	"url-shortener/internal/app/models/user"
+	"url-shortener/internal/app/services/token"
 )
 
 // MockTokenService is a mock implementation of TokenService for testing purposes.
@@ -40,9 +41,26 @@ func (mts *MockTokenService) ValidateToken(tokenString string) (uint, error) {
 	if tokenString == "expired" {
 		return 0, nil
 	}
+	if tokenString == "disabled" {
+		return 0, user_model.ErrAccountDisabled
+	}
 	if tokenString == "mockToken" {
 		return 1, nil
 	}
 	// For simplicity in testing, return a fixed user ID
 	return 123, nil
 }
+
+// ValidateClaims mocks the ValidateClaims method of TokenService.
+// The token "admin" belongs to user 1 with the admin role.
+func (mts *MockTokenService) ValidateClaims(tokenString string) (*token_service.Claims, error) {
+	if tokenString == "admin" {
+		return &token_service.Claims{UserID: 1, Role: user_model.RoleAdmin}, nil
+	}
+
+	userID, err := mts.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	return &token_service.Claims{UserID: userID, Role: user_model.RoleUser}, nil
+}
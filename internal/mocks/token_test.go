@@ -71,6 +71,12 @@ func TestMockTokenService_ValidateToken(t *testing.T) {
 		assert.Zero(t, userID)
 	})
 
+	t.Run("Disabled Account Token", func(t *testing.T) {
+		_, err := mockTokenService.ValidateToken("disabled")
+
+		assert.ErrorIs(t, err, user_model.ErrAccountDisabled)
+	})
+
 	t.Run("Valid Token", func(t *testing.T) {
 		userID, err := mockTokenService.ValidateToken("mockToken")
 
@@ -78,3 +84,19 @@ func TestMockTokenService_ValidateToken(t *testing.T) {
 		assert.Equal(t, uint(1), userID)
 	})
 }
+
+func TestMockTokenService_ValidateClaims(t *testing.T) {
+	tokenService := NewMockTokenService()
+
+	claims, err := tokenService.ValidateClaims("admin")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), claims.UserID)
+	assert.Equal(t, user_model.RoleAdmin, claims.Role)
+
+	claims, err = tokenService.ValidateClaims("mockToken")
+	assert.NoError(t, err)
+	assert.Equal(t, user_model.RoleUser, claims.Role)
+
+	_, err = tokenService.ValidateClaims("invalid")
+	assert.Error(t, err)
+}
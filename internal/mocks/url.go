@@ -1,18 +1,55 @@
 package mocks
 
 import (
+	"errors"
+	"time"
 	"url-shortener/internal/app/models/url"
 )
 
 // MockUrlRepository is a mock implementation of UrlRepository interface for testing purposes.
 type MockUrlRepository struct {
 	Urls map[uint]*url_model.URL
+	// Tags holds the tags of urls created in bulk by short code.
+	Tags map[string][]string
+	// PasswordHashes holds the password hashes of protected urls by short code.
+	PasswordHashes map[string]string
+	// ImportSources holds the short codes of imported urls by user and import source.
+	ImportSources map[uint]map[string]string
+	// Metadata holds the fetched page metadata of urls by short code.
+	Metadata map[string]*url_model.Metadata
+	// Rules holds the redirect rules of urls by short code.
+	Rules map[string][]url_model.Rule
+	// Splits holds the splits of urls by short code.
+	Splits map[string]*url_model.Split
+	// Tracking holds the query parameters added to the destination of urls by short code.
+	Tracking map[string]*url_model.Tracking
+	// DeepLinks holds the apps opened by urls by short code.
+	DeepLinks map[string]*url_model.DeepLink
+	// Previews holds the previews of urls by short code.
+	Previews map[string]*url_model.Preview
+	// NotActive holds what visitors of urls that are not active yet get, by short code.
+	NotActive map[string]*url_model.NotActiveResponse
+	// Revisions holds the revisions of urls by short code, oldest first.
+	Revisions map[string][]url_model.Revision
+	// revisionID is the ID of the latest revision recorded.
+	revisionID uint
 }
 
 // NewMockUrlRepository creates a new instance of MockUrlRepository.
 func NewMockUrlRepository() *MockUrlRepository {
 	return &MockUrlRepository{
-		Urls: make(map[uint]*url_model.URL),
+		Urls:           make(map[uint]*url_model.URL),
+		Tags:           make(map[string][]string),
+		PasswordHashes: make(map[string]string),
+		ImportSources:  make(map[uint]map[string]string),
+		Metadata:       make(map[string]*url_model.Metadata),
+		Rules:          make(map[string][]url_model.Rule),
+		Splits:         make(map[string]*url_model.Split),
+		Tracking:       make(map[string]*url_model.Tracking),
+		DeepLinks:      make(map[string]*url_model.DeepLink),
+		Previews:       make(map[string]*url_model.Preview),
+		NotActive:      make(map[string]*url_model.NotActiveResponse),
+		Revisions:      make(map[string][]url_model.Revision),
 	}
 }
 
@@ -38,6 +75,7 @@ func (r *MockUrlRepository) CreateURL(originalUrl, shortCode string, userId *uin
 		OriginalURL:  originalUrl,
 		ShortenedURL: shortCode,
 		UserID:       userID,
+		RedirectType: url_model.DefaultRedirectType,
 	}
 
 	r.Urls[uint(len(r.Urls)+1)] = url
@@ -58,15 +96,28 @@ func (r *MockUrlRepository) GetOriginalURL(shortCode string) (string, error) {
 	if shortCode == "invalid" {
 		return "https://www.google.com", nil
 	}
+
+	if u := r.find(shortCode); u != nil {
+		if u.Disabled {
+			return "", url_model.ErrURLDisabled
+		}
+		if u.ExpiresAt != nil && !u.ExpiresAt.After(time.Now()) {
+			return "", url_model.ErrURLExpired
+		}
+		if u.ActiveFrom != nil && u.ActiveFrom.After(time.Now()) {
+			return "", url_model.ErrURLNotActive
+		}
+		return u.OriginalURL, nil
+	}
 	// Return an error if url not found
 	return "", url_model.ErrURLNotFound
 }
 
-// GetUserURLs simulates retrieving all urls created by a user from the mock database.
+// GetUserURLs simulates retrieving all personal urls created by a user from the mock database.
 func (r *MockUrlRepository) GetUserURLs(userId uint) ([]url_model.URL, error) {
 	urls := make([]url_model.URL, 0)
 	for _, u := range r.Urls {
-		if u.UserID == userId {
+		if u.UserID == userId && u.WorkspaceID == nil {
 			urls = append(urls, *u)
 		}
 	}
@@ -88,3 +139,515 @@ func (r *MockUrlRepository) GetUserWithShortURL(userId uint, shortURL string) er
 	}
 	return nil
 }
+
+// GetURL simulates retrieving an url by shortCode, including disabled ones, from the mock database.
+func (r *MockUrlRepository) GetURL(shortCode string) (*url_model.URL, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	if u := r.find(shortCode); u != nil {
+		found := *u
+		return &found, nil
+	}
+	return nil, url_model.ErrURLNotFound
+}
+
+// SetDisabled simulates disabling or re-enabling an url in the mock database.
+func (r *MockUrlRepository) SetDisabled(shortCode string, disabled bool) error {
+	u := r.find(shortCode)
+	if u == nil {
+		return url_model.ErrURLNotFound
+	}
+	u.Disabled = disabled
+	return nil
+}
+
+func (r *MockUrlRepository) find(shortCode string) *url_model.URL {
+	for _, u := range r.Urls {
+		if u.ShortenedURL == shortCode {
+			return u
+		}
+	}
+	return nil
+}
+
+// CreateWorkspaceURL simulates creating a new url owned by a workspace in the mock database.
+func (r *MockUrlRepository) CreateWorkspaceURL(originalUrl, shortCode string, userId, workspaceId uint) (string, error) {
+	if originalUrl == "http://error.com" {
+		return "", errors.New("create error")
+	}
+	if r.find(shortCode) != nil {
+		return "", url_model.ErrShortCodeAlreadyExists
+	}
+
+	r.Urls[uint(len(r.Urls)+1)] = &url_model.URL{
+		OriginalURL:  originalUrl,
+		ShortenedURL: shortCode,
+		UserID:       userId,
+		WorkspaceID:  &workspaceId,
+		RedirectType: url_model.DefaultRedirectType,
+	}
+	return shortCode, nil
+}
+
+// GetWorkspaceURLs simulates retrieving all urls of a workspace from the mock database.
+func (r *MockUrlRepository) GetWorkspaceURLs(workspaceId uint) ([]url_model.URL, error) {
+	urls := make([]url_model.URL, 0)
+	for _, u := range r.Urls {
+		if u.WorkspaceID != nil && *u.WorkspaceID == workspaceId {
+			urls = append(urls, *u)
+		}
+	}
+	return urls, nil
+}
+
+// SetOwner simulates moving an url to a user and workspace in the mock database.
+func (r *MockUrlRepository) SetOwner(shortCode string, userId uint, workspaceId *uint) error {
+	u := r.find(shortCode)
+	if u == nil {
+		return url_model.ErrURLNotFound
+	}
+	u.UserID = userId
+	u.WorkspaceID = workspaceId
+	u.FolderID = nil
+	return nil
+}
+
+// SetFolder simulates moving an url into a folder in the mock database.
+func (r *MockUrlRepository) SetFolder(shortCode string, folderID *uint) error {
+	u := r.find(shortCode)
+	if u == nil {
+		return url_model.ErrURLNotFound
+	}
+	u.FolderID = folderID
+	return nil
+}
+
+// GetPasswordHash simulates retrieving the password hash of an url from the mock database.
+func (r *MockUrlRepository) GetPasswordHash(shortCode string) (string, error) {
+	if shortCode == "error" {
+		return "", errors.New("get error")
+	}
+	if shortCode != "success" && shortCode != "invalid" && r.find(shortCode) == nil {
+		return "", url_model.ErrURLNotFound
+	}
+	return r.PasswordHashes[shortCode], nil
+}
+
+// SetPasswordHash simulates setting or removing the password hash of an url in the mock database.
+func (r *MockUrlRepository) SetPasswordHash(shortCode string, hash *string) error {
+	if r.find(shortCode) == nil {
+		return url_model.ErrURLNotFound
+	}
+	if hash == nil {
+		delete(r.PasswordHashes, shortCode)
+		return nil
+	}
+	r.PasswordHashes[shortCode] = *hash
+	return nil
+}
+
+// CreateURLs simulates inserting urls in a single transaction in the mock database.
+// Nothing is inserted when one of the urls is "http://error.com" or its short code is taken.
+func (r *MockUrlRepository) CreateURLs(urls []url_model.NewURL) error {
+	for _, u := range urls {
+		if u.OriginalURL == "http://error.com" {
+			return errors.New("create error")
+		}
+		if r.find(u.ShortCode) != nil {
+			return url_model.ErrShortCodeAlreadyExists
+		}
+	}
+
+	for _, u := range urls {
+		created := &url_model.URL{
+			OriginalURL:    u.OriginalURL,
+			ShortenedURL:   u.ShortCode,
+			UserID:         u.UserID,
+			WorkspaceID:    u.WorkspaceID,
+			ExpiresAt:      u.ExpiresAt,
+			ImportedClicks: u.ImportedClicks,
+			RedirectType:   url_model.DefaultRedirectType,
+		}
+		if u.CreatedAt != nil {
+			created.CreatedAt = *u.CreatedAt
+		}
+		r.Urls[uint(len(r.Urls)+1)] = created
+		if len(u.Tags) > 0 {
+			r.Tags[u.ShortCode] = u.Tags
+		}
+		if u.ImportSource != "" {
+			if r.ImportSources[u.UserID] == nil {
+				r.ImportSources[u.UserID] = make(map[string]string)
+			}
+			r.ImportSources[u.UserID][u.ImportSource] = u.ShortCode
+		}
+	}
+	return nil
+}
+
+// GetImportSources simulates retrieving the import sources of a user from the mock database.
+func (r *MockUrlRepository) GetImportSources(userID uint) (map[string]string, error) {
+	sources := make(map[string]string)
+	for source, shortCode := range r.ImportSources[userID] {
+		sources[source] = shortCode
+	}
+	return sources, nil
+}
+
+// IterateUserURLs simulates reading the personal urls of a user one at a time from the mock database.
+// User 0 fails, like the "error" short code.
+func (r *MockUrlRepository) IterateUserURLs(userID uint, fn func(*url_model.URL) error) error {
+	if userID == 0 {
+		return errors.New("query error")
+	}
+	for id := uint(1); id <= uint(len(r.Urls)); id++ {
+		u, ok := r.Urls[id]
+		if !ok || u.UserID != userID || u.WorkspaceID != nil {
+			continue
+		}
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindUserURLs simulates retrieving the personal urls of a user matching the filter, with their tags
+// from Tags, from the mock database. User 0 fails, like the "error" short code.
+func (r *MockUrlRepository) FindUserURLs(userID uint, filter url_model.Filter) ([]url_model.URL, error) {
+	if userID == 0 {
+		return nil, errors.New("query error")
+	}
+	urls := make([]url_model.URL, 0)
+	for id := uint(1); id <= uint(len(r.Urls)); id++ {
+		u, ok := r.Urls[id]
+		if !ok || u.UserID != userID || u.WorkspaceID != nil {
+			continue
+		}
+		if filter.FolderID != nil && (u.FolderID == nil || *u.FolderID != *filter.FolderID) {
+			continue
+		}
+		tags := r.Tags[u.ShortenedURL]
+		if filter.Tag != "" && !contains(tags, filter.Tag) {
+			continue
+		}
+		found := *u
+		found.Tags = append([]string(nil), tags...)
+		urls = append(urls, found)
+	}
+	return urls, nil
+}
+
+// SetDetails simulates setting the title and notes of an url in the mock database. The short code "error" fails.
+func (r *MockUrlRepository) SetDetails(shortCode, title, notes string) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if u := r.find(shortCode); u != nil {
+		u.Title, u.Notes = title, notes
+	}
+	return nil
+}
+
+// GetMetadata simulates retrieving the page metadata of an url from the mock database.
+func (r *MockUrlRepository) GetMetadata(shortCode string) (*url_model.Metadata, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	metadata, ok := r.Metadata[shortCode]
+	if !ok {
+		return nil, url_model.ErrMetadataNotFound
+	}
+	copied := *metadata
+	return &copied, nil
+}
+
+// SaveMetadata simulates storing the page metadata of an url in the mock database, giving urls
+// without a title the page title. The short code "error" fails.
+func (r *MockUrlRepository) SaveMetadata(shortCode string, metadata *url_model.Metadata) error {
+	if shortCode == "error" {
+		return errors.New("save error")
+	}
+	copied := *metadata
+	r.Metadata[shortCode] = &copied
+	if u := r.find(shortCode); u != nil && u.Title == "" {
+		u.Title = metadata.Title
+	}
+	return nil
+}
+
+// GetRules simulates retrieving the redirect rules of an url from the mock database. The short code "error" fails.
+func (r *MockUrlRepository) GetRules(shortCode string) ([]url_model.Rule, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	return r.Rules[shortCode], nil
+}
+
+// SetRules simulates replacing the redirect rules of an url in the mock database. The short code "error" fails.
+func (r *MockUrlRepository) SetRules(shortCode string, rules []url_model.Rule) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if len(rules) == 0 {
+		delete(r.Rules, shortCode)
+		return nil
+	}
+	r.Rules[shortCode] = append([]url_model.Rule(nil), rules...)
+	return nil
+}
+
+// GetSplit simulates retrieving the split of an url from the mock database. The short code "error" fails.
+func (r *MockUrlRepository) GetSplit(shortCode string) (*url_model.Split, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	split, ok := r.Splits[shortCode]
+	if !ok {
+		return nil, nil
+	}
+	copied := *split
+	return &copied, nil
+}
+
+// SetSplit simulates replacing the split of an url in the mock database. The short code "error" fails.
+func (r *MockUrlRepository) SetSplit(shortCode string, split *url_model.Split) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if split == nil {
+		delete(r.Splits, shortCode)
+		return nil
+	}
+	copied := *split
+	r.Splits[shortCode] = &copied
+	return nil
+}
+
+// GetTracking simulates retrieving the query parameters added to the destination of an url from the mock
+// database. The short code "error" fails.
+func (r *MockUrlRepository) GetTracking(shortCode string) (*url_model.Tracking, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	tracking, ok := r.Tracking[shortCode]
+	if !ok {
+		return nil, nil
+	}
+	copied := *tracking
+	return &copied, nil
+}
+
+// SetTracking simulates replacing the query parameters added to the destination of an url in the mock
+// database. The short code "error" fails.
+func (r *MockUrlRepository) SetTracking(shortCode string, tracking *url_model.Tracking) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if tracking == nil {
+		delete(r.Tracking, shortCode)
+		return nil
+	}
+	copied := *tracking
+	r.Tracking[shortCode] = &copied
+	return nil
+}
+
+// GetDeepLink simulates retrieving the apps opened by an url from the mock database.
+// The short code "error" fails.
+func (r *MockUrlRepository) GetDeepLink(shortCode string) (*url_model.DeepLink, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	deepLink, ok := r.DeepLinks[shortCode]
+	if !ok {
+		return nil, nil
+	}
+	copied := *deepLink
+	return &copied, nil
+}
+
+// SetDeepLink simulates replacing the apps opened by an url in the mock database.
+// The short code "error" fails.
+func (r *MockUrlRepository) SetDeepLink(shortCode string, deepLink *url_model.DeepLink) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if deepLink == nil {
+		delete(r.DeepLinks, shortCode)
+		return nil
+	}
+	copied := *deepLink
+	r.DeepLinks[shortCode] = &copied
+	return nil
+}
+
+// GetPreview simulates retrieving the preview of an url from the mock database.
+// The short code "error" fails.
+func (r *MockUrlRepository) GetPreview(shortCode string) (*url_model.Preview, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	preview, ok := r.Previews[shortCode]
+	if !ok {
+		return nil, nil
+	}
+	copied := *preview
+	return &copied, nil
+}
+
+// SetPreview simulates replacing the preview of an url in the mock database.
+// The short code "error" fails.
+func (r *MockUrlRepository) SetPreview(shortCode string, preview *url_model.Preview) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if preview == nil {
+		delete(r.Previews, shortCode)
+		return nil
+	}
+	copied := *preview
+	r.Previews[shortCode] = &copied
+	return nil
+}
+
+// SetActivation simulates setting when an url starts and stops resolving in the mock database.
+// The short code "error" fails.
+func (r *MockUrlRepository) SetActivation(shortCode string, activeFrom, activeUntil *time.Time) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if u := r.find(shortCode); u != nil {
+		u.ActiveFrom, u.ExpiresAt = activeFrom, activeUntil
+	}
+	return nil
+}
+
+// GetNotActiveResponse simulates retrieving what visitors of an url get before it is active from the mock
+// database. The short code "error" fails.
+func (r *MockUrlRepository) GetNotActiveResponse(shortCode string) (*url_model.NotActiveResponse, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	response, ok := r.NotActive[shortCode]
+	if !ok {
+		return nil, nil
+	}
+	copied := *response
+	return &copied, nil
+}
+
+// SetNotActiveResponse simulates replacing what visitors of an url get before it is active in the mock
+// database. The short code "error" fails.
+func (r *MockUrlRepository) SetNotActiveResponse(shortCode string, response *url_model.NotActiveResponse) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if response == nil {
+		delete(r.NotActive, shortCode)
+		return nil
+	}
+	copied := *response
+	r.NotActive[shortCode] = &copied
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRedirectVersion simulates retrieving the redirect type and the latest revision of an url from the mock
+// database. The short code "error" fails.
+func (r *MockUrlRepository) GetRedirectVersion(shortCode string) (int, uint, error) {
+	if shortCode == "error" {
+		return 0, 0, errors.New("get error")
+	}
+	if shortCode == "success" || shortCode == "invalid" {
+		return url_model.DefaultRedirectType, 0, nil
+	}
+	u := r.find(shortCode)
+	if u == nil {
+		return 0, 0, url_model.ErrURLNotFound
+	}
+	var latest uint
+	if revisions := r.Revisions[shortCode]; len(revisions) > 0 {
+		latest = revisions[len(revisions)-1].ID
+	}
+	return u.RedirectType, latest, nil
+}
+
+// GetRevisions simulates retrieving the revisions of an url from the mock database, newest first.
+// The short code "error" fails.
+func (r *MockUrlRepository) GetRevisions(shortCode string) ([]url_model.Revision, error) {
+	if shortCode == "error" {
+		return nil, errors.New("get error")
+	}
+	stored := r.Revisions[shortCode]
+	revisions := make([]url_model.Revision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, stored[i])
+	}
+	return revisions, nil
+}
+
+// Revise simulates changing an url to the last of the revisions and recording them in the mock database,
+// setting their IDs. The short code "error" fails.
+func (r *MockUrlRepository) Revise(shortCode string, revisions []*url_model.Revision, withRules bool) error {
+	if shortCode == "error" {
+		return errors.New("update error")
+	}
+	if len(revisions) == 0 {
+		return nil
+	}
+	latest := revisions[len(revisions)-1]
+	if u := r.find(shortCode); u != nil {
+		u.OriginalURL, u.RedirectType = latest.Destination, latest.RedirectType
+	}
+	if withRules {
+		_ = r.SetRules(shortCode, latest.Rules)
+	}
+	for _, revision := range revisions {
+		r.revisionID++
+		revision.ID = r.revisionID
+		stored := *revision
+		stored.Rules = append([]url_model.Rule{}, revision.Rules...)
+		r.Revisions[shortCode] = append(r.Revisions[shortCode], stored)
+	}
+	return nil
+}
+
+// DeleteURL simulates deleting an url with its settings from the mock database. The urls after it move
+// down one ID, as the mock numbers urls by their count. The short code "error" fails.
+func (r *MockUrlRepository) DeleteURL(shortCode string) error {
+	if shortCode == "error" {
+		return errors.New("delete error")
+	}
+	for id, u := range r.Urls {
+		if u.ShortenedURL != shortCode {
+			continue
+		}
+		delete(r.Urls, id)
+		for next := id + 1; r.Urls[next] != nil; next++ {
+			r.Urls[next-1] = r.Urls[next]
+			delete(r.Urls, next)
+		}
+		delete(r.Tags, shortCode)
+		delete(r.PasswordHashes, shortCode)
+		delete(r.Metadata, shortCode)
+		delete(r.Rules, shortCode)
+		delete(r.Splits, shortCode)
+		delete(r.Tracking, shortCode)
+		delete(r.DeepLinks, shortCode)
+		delete(r.Previews, shortCode)
+		delete(r.NotActive, shortCode)
+		delete(r.Revisions, shortCode)
+		return nil
+	}
+	return url_model.ErrURLNotFound
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"testing"
+	"time"
 	"url-shortener/internal/app/models/url"
 
 	"github.com/stretchr/testify/assert"
@@ -103,3 +104,363 @@ func TestMockUrlRepository_GetUserWithShortURL(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestMockUrlRepository_Disable(t *testing.T) {
+	repo := NewMockUrlRepository()
+	_, _ = repo.CreateURL("https://www.example.com", "abc123", nil)
+
+	url, err := repo.GetURL("abc123")
+	assert.NoError(t, err)
+	assert.False(t, url.Disabled)
+
+	original, err := repo.GetOriginalURL("abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.example.com", original)
+
+	assert.NoError(t, repo.SetDisabled("abc123", true))
+	_, err = repo.GetOriginalURL("abc123")
+	assert.ErrorIs(t, err, url_model.ErrURLDisabled)
+
+	assert.ErrorIs(t, repo.SetDisabled("missing", true), url_model.ErrURLNotFound)
+	_, err = repo.GetURL("missing")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	_, err = repo.GetURL("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Workspace(t *testing.T) {
+	repo := NewMockUrlRepository()
+	userID := uint(1)
+	_, _ = repo.CreateURL("https://www.example.com", "personal", &userID)
+
+	shortCode, err := repo.CreateWorkspaceURL("https://www.example.com", "team", 1, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "team", shortCode)
+
+	_, err = repo.CreateWorkspaceURL("https://www.example.com", "team", 1, 7)
+	assert.ErrorIs(t, err, url_model.ErrShortCodeAlreadyExists)
+	_, err = repo.CreateWorkspaceURL("http://error.com", "other", 1, 7)
+	assert.Error(t, err)
+
+	urls, _ := repo.GetUserURLs(1)
+	assert.Len(t, urls, 1)
+	urls, _ = repo.GetWorkspaceURLs(7)
+	assert.Len(t, urls, 1)
+
+	assert.NoError(t, repo.SetOwner("team", 2, nil))
+	urls, _ = repo.GetWorkspaceURLs(7)
+	assert.Empty(t, urls)
+	urls, _ = repo.GetUserURLs(2)
+	assert.Len(t, urls, 1)
+
+	assert.ErrorIs(t, repo.SetOwner("missing", 2, nil), url_model.ErrURLNotFound)
+}
+
+func TestMockUrlRepository_PasswordHash(t *testing.T) {
+	repo := NewMockUrlRepository()
+	_, _ = repo.CreateURL("https://www.example.com", "abc123", nil)
+
+	hash, err := repo.GetPasswordHash("abc123")
+	assert.NoError(t, err)
+	assert.Empty(t, hash)
+
+	protected := "hash"
+	assert.NoError(t, repo.SetPasswordHash("abc123", &protected))
+	hash, _ = repo.GetPasswordHash("abc123")
+	assert.Equal(t, "hash", hash)
+
+	assert.NoError(t, repo.SetPasswordHash("abc123", nil))
+	hash, _ = repo.GetPasswordHash("abc123")
+	assert.Empty(t, hash)
+
+	assert.ErrorIs(t, repo.SetPasswordHash("missing", nil), url_model.ErrURLNotFound)
+	_, err = repo.GetPasswordHash("missing")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	_, err = repo.GetPasswordHash("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_CreateURLs(t *testing.T) {
+	repo := NewMockUrlRepository()
+	_, _ = repo.CreateURL("https://www.example.com", "taken", nil)
+	expiredAt := time.Now().Add(-time.Minute)
+
+	assert.NoError(t, repo.CreateURLs([]url_model.NewURL{
+		{OriginalURL: "https://www.example.com", ShortCode: "tagged", UserID: 1, Tags: []string{"docs"}},
+		{OriginalURL: "https://www.example.com", ShortCode: "expired", UserID: 1, ExpiresAt: &expiredAt, ImportSource: "old", ImportedClicks: 3},
+	}))
+	assert.Equal(t, []string{"docs"}, repo.Tags["tagged"])
+	sources, _ := repo.GetImportSources(1)
+	assert.Equal(t, map[string]string{"old": "expired"}, sources)
+	_, err := repo.GetOriginalURL("expired")
+	assert.ErrorIs(t, err, url_model.ErrURLExpired)
+
+	// Failed batches insert nothing
+	assert.ErrorIs(t, repo.CreateURLs([]url_model.NewURL{
+		{OriginalURL: "https://www.example.com", ShortCode: "fresh"},
+		{OriginalURL: "https://www.example.com", ShortCode: "taken"},
+	}), url_model.ErrShortCodeAlreadyExists)
+	assert.Error(t, repo.CreateURLs([]url_model.NewURL{{OriginalURL: "http://error.com", ShortCode: "other"}}))
+	assert.Len(t, repo.Urls, 3)
+}
+
+func TestMockUrlRepository_IterateUserURLs(t *testing.T) {
+	repo := NewMockUrlRepository()
+	_, _ = repo.CreateURL("https://www.example.com", "first", nil)
+	userID := uint(1)
+	_, _ = repo.CreateURL("https://www.example.com", "mine", &userID)
+	_, _ = repo.CreateURL("https://www.example.org", "also-mine", &userID)
+
+	var codes []string
+	assert.NoError(t, repo.IterateUserURLs(1, func(u *url_model.URL) error {
+		codes = append(codes, u.ShortenedURL)
+		return nil
+	}))
+	assert.Equal(t, []string{"mine", "also-mine"}, codes)
+	assert.Error(t, repo.IterateUserURLs(0, func(u *url_model.URL) error { return nil }))
+}
+
+func TestMockUrlRepository_FindUserURLs(t *testing.T) {
+	repo := NewMockUrlRepository()
+	userID := uint(1)
+	folderID := uint(3)
+	_, _ = repo.CreateURL("https://www.example.com", "docs", &userID)
+	_, _ = repo.CreateURL("https://www.example.org", "filed", &userID)
+	repo.Tags["docs"] = []string{"docs"}
+	assert.NoError(t, repo.SetFolder("filed", &folderID))
+	assert.ErrorIs(t, repo.SetFolder("missing", nil), url_model.ErrURLNotFound)
+
+	urls, err := repo.FindUserURLs(1, url_model.Filter{})
+	assert.NoError(t, err)
+	assert.Len(t, urls, 2)
+	assert.Equal(t, []string{"docs"}, urls[0].Tags)
+
+	urls, _ = repo.FindUserURLs(1, url_model.Filter{Tag: "docs"})
+	assert.Len(t, urls, 1)
+	urls, _ = repo.FindUserURLs(1, url_model.Filter{FolderID: &folderID})
+	assert.Equal(t, "filed", urls[0].ShortenedURL)
+
+	_, err = repo.FindUserURLs(0, url_model.Filter{})
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_DetailsAndMetadata(t *testing.T) {
+	repo := NewMockUrlRepository()
+	userID := uint(1)
+	_, _ = repo.CreateURL("https://www.example.com", "untitled", &userID)
+	_, _ = repo.CreateURL("https://www.example.org", "titled", &userID)
+	assert.NoError(t, repo.SetDetails("titled", "Mine", "Some notes"))
+	assert.Error(t, repo.SetDetails("error", "", ""))
+
+	_, err := repo.GetMetadata("untitled")
+	assert.ErrorIs(t, err, url_model.ErrMetadataNotFound)
+
+	metadata := &url_model.Metadata{Title: "Example", Description: "An example page"}
+	assert.NoError(t, repo.SaveMetadata("untitled", metadata))
+	assert.NoError(t, repo.SaveMetadata("titled", metadata))
+	assert.Error(t, repo.SaveMetadata("error", metadata))
+
+	found, err := repo.GetMetadata("untitled")
+	assert.NoError(t, err)
+	assert.Equal(t, metadata, found)
+
+	u, _ := repo.GetURL("untitled")
+	assert.Equal(t, "Example", u.Title)
+	u, _ = repo.GetURL("titled")
+	assert.Equal(t, "Mine", u.Title)
+	assert.Equal(t, "Some notes", u.Notes)
+}
+
+func TestMockUrlRepository_Rules(t *testing.T) {
+	repo := NewMockUrlRepository()
+	rules := []url_model.Rule{{Conditions: url_model.RuleConditions{Devices: []string{url_model.DeviceIOS}}, Destination: "https://apps.apple.com"}}
+
+	found, err := repo.GetRules("abc123")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetRules("abc123", rules))
+	found, _ = repo.GetRules("abc123")
+	assert.Equal(t, rules, found)
+
+	assert.NoError(t, repo.SetRules("abc123", nil))
+	found, _ = repo.GetRules("abc123")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetRules("error", rules))
+	_, err = repo.GetRules("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Split(t *testing.T) {
+	repo := NewMockUrlRepository()
+	split := &url_model.Split{Sticky: url_model.StickyCookie, Variants: []url_model.Variant{{Name: "a", Destination: "https://www.example.com/a", Weight: 1}}}
+
+	found, err := repo.GetSplit("abc123")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetSplit("abc123", split))
+	found, _ = repo.GetSplit("abc123")
+	assert.Equal(t, split, found)
+
+	assert.NoError(t, repo.SetSplit("abc123", nil))
+	found, _ = repo.GetSplit("abc123")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetSplit("error", split))
+	_, err = repo.GetSplit("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Tracking(t *testing.T) {
+	repo := NewMockUrlRepository()
+	tracking := &url_model.Tracking{UTM: url_model.UTM{Source: "newsletter"}, QueryConflict: url_model.QueryKeep}
+
+	found, err := repo.GetTracking("abc123")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetTracking("abc123", tracking))
+	found, _ = repo.GetTracking("abc123")
+	assert.Equal(t, tracking, found)
+
+	assert.NoError(t, repo.SetTracking("abc123", nil))
+	found, _ = repo.GetTracking("abc123")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetTracking("error", tracking))
+	_, err = repo.GetTracking("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_DeepLink(t *testing.T) {
+	repo := NewMockUrlRepository()
+	deepLink := &url_model.DeepLink{IOS: url_model.AppTarget{URI: "shop://product/1"}}
+
+	found, err := repo.GetDeepLink("abc123")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetDeepLink("abc123", deepLink))
+	found, _ = repo.GetDeepLink("abc123")
+	assert.Equal(t, deepLink, found)
+
+	assert.NoError(t, repo.SetDeepLink("abc123", nil))
+	found, _ = repo.GetDeepLink("abc123")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetDeepLink("error", deepLink))
+	_, err = repo.GetDeepLink("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Preview(t *testing.T) {
+	repo := NewMockUrlRepository()
+	preview := &url_model.Preview{Title: "Launch", Interstitial: true}
+
+	found, err := repo.GetPreview("abc123")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetPreview("abc123", preview))
+	found, _ = repo.GetPreview("abc123")
+	assert.Equal(t, preview, found)
+
+	assert.NoError(t, repo.SetPreview("abc123", nil))
+	found, _ = repo.GetPreview("abc123")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetPreview("error", preview))
+	_, err = repo.GetPreview("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Activation(t *testing.T) {
+	repo := NewMockUrlRepository()
+	owner := uint(1)
+	_, _ = repo.CreateURL("https://www.example.com", "launch", &owner)
+	activeFrom := time.Now().Add(time.Hour)
+
+	assert.NoError(t, repo.SetActivation("launch", &activeFrom, nil))
+	_, err := repo.GetOriginalURL("launch")
+	assert.ErrorIs(t, err, url_model.ErrURLNotActive)
+
+	response := &url_model.NotActiveResponse{Mode: url_model.NotActiveRedirect, URL: "https://www.example.com/soon"}
+	found, err := repo.GetNotActiveResponse("launch")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	assert.NoError(t, repo.SetNotActiveResponse("launch", response))
+	found, _ = repo.GetNotActiveResponse("launch")
+	assert.Equal(t, response, found)
+
+	assert.NoError(t, repo.SetNotActiveResponse("launch", nil))
+	found, _ = repo.GetNotActiveResponse("launch")
+	assert.Nil(t, found)
+
+	assert.Error(t, repo.SetActivation("error", nil, nil))
+	assert.Error(t, repo.SetNotActiveResponse("error", response))
+	_, err = repo.GetNotActiveResponse("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_Revisions(t *testing.T) {
+	repo := NewMockUrlRepository()
+	owner := uint(1)
+	_, _ = repo.CreateURL("https://www.example.com/v1", "docs", &owner)
+
+	redirectType, latest, err := repo.GetRedirectVersion("docs")
+	assert.NoError(t, err)
+	assert.Equal(t, url_model.DefaultRedirectType, redirectType)
+	assert.Equal(t, uint(0), latest)
+
+	rules := []url_model.Rule{{Conditions: url_model.RuleConditions{Devices: []string{"ios"}}, Destination: "https://www.example.com/ios"}}
+	revision := &url_model.Revision{Destination: "https://www.example.com/v2", PreviousDestination: "https://www.example.com/v1", RedirectType: 302, Rules: rules}
+	first := &url_model.Revision{Destination: "https://www.example.com/v1", RedirectType: 301}
+	assert.NoError(t, repo.Revise("docs", []*url_model.Revision{first, revision}, false))
+	assert.Equal(t, uint(2), revision.ID)
+
+	u, _ := repo.GetURL("docs")
+	assert.Equal(t, "https://www.example.com/v2", u.OriginalURL)
+	stored, _ := repo.GetRules("docs")
+	assert.Empty(t, stored)
+	redirectType, latest, _ = repo.GetRedirectVersion("docs")
+	assert.Equal(t, 302, redirectType)
+	assert.Equal(t, uint(2), latest)
+
+	revisions, err := repo.GetRevisions("docs")
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, uint(2), revisions[0].ID)
+
+	_, _, err = repo.GetRedirectVersion("missing")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	assert.NoError(t, repo.Revise("docs", []*url_model.Revision{revision}, true))
+	stored, _ = repo.GetRules("docs")
+	assert.Equal(t, rules, stored)
+
+	assert.Error(t, repo.Revise("error", []*url_model.Revision{revision}, true))
+	_, err = repo.GetRevisions("error")
+	assert.Error(t, err)
+}
+
+func TestMockUrlRepository_DeleteURL(t *testing.T) {
+	repo := NewMockUrlRepository()
+	_, _ = repo.CreateURL("https://www.example.com/a", "first", nil)
+	_, _ = repo.CreateURL("https://www.example.com/b", "second", nil)
+	_ = repo.SetRules("first", []url_model.Rule{{Destination: "https://www.example.com/ios"}})
+
+	assert.NoError(t, repo.DeleteURL("first"))
+	_, err := repo.GetURL("first")
+	assert.ErrorIs(t, err, url_model.ErrURLNotFound)
+	assert.Empty(t, repo.Rules["first"])
+	assert.ErrorIs(t, repo.DeleteURL("first"), url_model.ErrURLNotFound)
+	assert.Error(t, repo.DeleteURL("error"))
+
+	// New urls do not overwrite the remaining ones
+	_, _ = repo.CreateURL("https://www.example.com/c", "third", nil)
+	original, err := repo.GetOriginalURL("second")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.example.com/b", original)
+}
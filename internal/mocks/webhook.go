@@ -0,0 +1,230 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+	"url-shortener/internal/app/models/webhook"
+)
+
+// MockWebhookRepository is a mock implementation of WebhookRepository interface for testing purposes.
+// It is safe for concurrent use, as the delivery worker runs in the background.
+type MockWebhookRepository struct {
+	Webhooks   map[uint]*webhook_model.Webhook
+	Deliveries map[uint]*webhook_model.Delivery
+	// Attempts holds the attempts of deliveries by delivery ID, oldest first.
+	Attempts map[uint][]webhook_model.Attempt
+	// Members are the workspace members listing workspace webhooks; share it with MockWorkspaceRepository.Members.
+	Members map[uint]map[uint]string
+
+	mu         sync.Mutex
+	webhookID  uint
+	deliveryID uint
+}
+
+// NewMockWebhookRepository creates a new instance of MockWebhookRepository.
+func NewMockWebhookRepository() *MockWebhookRepository {
+	return &MockWebhookRepository{
+		Webhooks:   make(map[uint]*webhook_model.Webhook),
+		Deliveries: make(map[uint]*webhook_model.Delivery),
+		Attempts:   make(map[uint][]webhook_model.Attempt),
+		Members:    make(map[uint]map[uint]string),
+	}
+}
+
+// List simulates retrieving the personal webhooks of a user and those of the workspaces they own by ID
+// from the mock database. User 0 fails.
+func (r *MockWebhookRepository) List(userID uint) ([]webhook_model.Webhook, error) {
+	if userID == 0 {
+		return nil, errors.New("query error")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter(func(webhook *webhook_model.Webhook) bool {
+		if webhook.WorkspaceID == nil {
+			return webhook.UserID == userID
+		}
+		return r.Members[*webhook.WorkspaceID][userID] == "owner"
+	}), nil
+}
+
+// GetByID simulates retrieving a webhook by ID from the mock database.
+func (r *MockWebhookRepository) GetByID(id uint) (*webhook_model.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	webhook, ok := r.Webhooks[id]
+	if !ok {
+		return nil, webhook_model.ErrWebhookNotFound
+	}
+	found := *webhook
+	return &found, nil
+}
+
+// Subscribers simulates retrieving the active webhooks of the links of a workspace, or of the personal
+// links of a user, from the mock database.
+func (r *MockWebhookRepository) Subscribers(userID uint, workspaceID *uint) ([]webhook_model.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.filter(func(webhook *webhook_model.Webhook) bool {
+		if !webhook.Active {
+			return false
+		}
+		if workspaceID != nil {
+			return webhook.WorkspaceID != nil && *webhook.WorkspaceID == *workspaceID
+		}
+		return webhook.WorkspaceID == nil && webhook.UserID == userID
+	}), nil
+}
+
+func (r *MockWebhookRepository) filter(keep func(*webhook_model.Webhook) bool) []webhook_model.Webhook {
+	webhooks := make([]webhook_model.Webhook, 0)
+	for _, webhook := range r.Webhooks {
+		if keep(webhook) {
+			webhooks = append(webhooks, *webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks
+}
+
+// Create simulates inserting a new webhook in the mock database. The URL "https://error.example.com" fails.
+func (r *MockWebhookRepository) Create(webhook *webhook_model.Webhook) (*webhook_model.Webhook, error) {
+	if webhook.URL == "https://error.example.com" {
+		return nil, errors.New("webhook not created")
+	}
+	r.mu.Lock()
+	r.webhookID++ // Simulate auto-incrementing ID
+	created := *webhook
+	created.ID = r.webhookID
+	created.CreatedAt = time.Now()
+	r.Webhooks[created.ID] = &created
+	r.mu.Unlock()
+	return r.GetByID(created.ID)
+}
+
+// Update simulates saving the URL, events and state of a webhook in the mock database.
+func (r *MockWebhookRepository) Update(webhook *webhook_model.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found, ok := r.Webhooks[webhook.ID]
+	if !ok {
+		return webhook_model.ErrWebhookNotFound
+	}
+	found.URL, found.Events, found.Active = webhook.URL, webhook.Events, webhook.Active
+	return nil
+}
+
+// Delete simulates deleting a webhook with its deliveries from the mock database.
+func (r *MockWebhookRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.Webhooks[id]; !ok {
+		return webhook_model.ErrWebhookNotFound
+	}
+	for deliveryID, delivery := range r.Deliveries {
+		if delivery.WebhookID == id {
+			delete(r.Deliveries, deliveryID)
+			delete(r.Attempts, deliveryID)
+		}
+	}
+	delete(r.Webhooks, id)
+	return nil
+}
+
+// CreateDelivery simulates queueing a delivery in the mock database. The event type "error" fails.
+func (r *MockWebhookRepository) CreateDelivery(delivery *webhook_model.Delivery) (*webhook_model.Delivery, error) {
+	if delivery.EventType == "error" {
+		return nil, errors.New("delivery not created")
+	}
+	r.mu.Lock()
+	r.deliveryID++
+	created := *delivery
+	created.ID = r.deliveryID
+	created.CreatedAt = time.Now()
+	r.Deliveries[created.ID] = &created
+	r.mu.Unlock()
+	return r.GetDelivery(created.ID)
+}
+
+// GetDelivery simulates retrieving a delivery by ID from the mock database.
+func (r *MockWebhookRepository) GetDelivery(id uint) (*webhook_model.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.Deliveries[id]
+	if !ok {
+		return nil, webhook_model.ErrDeliveryNotFound
+	}
+	found := *delivery
+	return &found, nil
+}
+
+// ListDeliveries simulates retrieving the latest deliveries of a webhook, newest first, from the mock database.
+func (r *MockWebhookRepository) ListDeliveries(webhookID uint, status string, limit int) ([]webhook_model.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliveries := make([]webhook_model.Delivery, 0)
+	for _, delivery := range r.Deliveries {
+		if delivery.WebhookID == webhookID && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+// ClaimDue simulates claiming the due pending deliveries, oldest first, in the mock database.
+func (r *MockWebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]webhook_model.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	deliveries := make([]webhook_model.Delivery, 0)
+	for _, delivery := range r.Deliveries {
+		if delivery.Status == webhook_model.StatusPending && !delivery.NextAttemptAt.After(now) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	for _, delivery := range deliveries {
+		r.Deliveries[delivery.ID].NextAttemptAt = now.Add(lease)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt simulates saving the outcome of an attempt of a delivery in the mock database.
+func (r *MockWebhookRepository) RecordAttempt(delivery *webhook_model.Delivery, attempt webhook_model.Attempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	found, ok := r.Deliveries[delivery.ID]
+	if !ok {
+		return webhook_model.ErrDeliveryNotFound
+	}
+	found.Status, found.Attempts, found.NextAttemptAt = delivery.Status, delivery.Attempts, delivery.NextAttemptAt
+	found.ResponseStatus, found.Error = delivery.ResponseStatus, delivery.Error
+	r.Attempts[delivery.ID] = append(r.Attempts[delivery.ID], attempt)
+	return nil
+}
+
+// ListAttempts simulates retrieving the attempts of a delivery from the mock database.
+func (r *MockWebhookRepository) ListAttempts(deliveryID uint) ([]webhook_model.Attempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append(make([]webhook_model.Attempt, 0), r.Attempts[deliveryID]...), nil
+}
+
+// Requeue simulates making a dead or failed delivery pending again in the mock database.
+func (r *MockWebhookRepository) Requeue(id uint, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery, ok := r.Deliveries[id]
+	if !ok || (delivery.Status != webhook_model.StatusDead && delivery.Status != webhook_model.StatusFailed) {
+		return webhook_model.ErrDeliveryNotFailed
+	}
+	delivery.Status, delivery.Attempts, delivery.NextAttemptAt = webhook_model.StatusPending, 0, now
+	return nil
+}
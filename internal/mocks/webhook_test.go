@@ -0,0 +1,63 @@
+package mocks
+
+import (
+	"testing"
+	"time"
+	"url-shortener/internal/app/models/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockWebhookRepository(t *testing.T) {
+	repo := NewMockWebhookRepository()
+	workspaceID := uint(4)
+	repo.Members[workspaceID] = map[uint]string{1: "owner", 2: "viewer"}
+
+	personal, err := repo.Create(&webhook_model.Webhook{URL: "https://hooks.example.com/a", UserID: 1, Active: true})
+	assert.NoError(t, err)
+	shared, err := repo.Create(&webhook_model.Webhook{URL: "https://hooks.example.com/b", UserID: 1, WorkspaceID: &workspaceID, Active: true})
+	assert.NoError(t, err)
+	_, err = repo.Create(&webhook_model.Webhook{URL: "https://error.example.com", UserID: 1})
+	assert.Error(t, err)
+
+	webhooks, err := repo.List(1)
+	assert.NoError(t, err)
+	assert.Len(t, webhooks, 2)
+	webhooks, err = repo.List(2)
+	assert.NoError(t, err)
+	assert.Empty(t, webhooks)
+	_, err = repo.List(0)
+	assert.Error(t, err)
+
+	subscribers, _ := repo.Subscribers(1, &workspaceID)
+	assert.Equal(t, shared.ID, subscribers[0].ID)
+	personal.Active = false
+	assert.NoError(t, repo.Update(personal))
+	subscribers, _ = repo.Subscribers(1, nil)
+	assert.Empty(t, subscribers)
+
+	now := time.Now()
+	delivery, err := repo.CreateDelivery(&webhook_model.Delivery{WebhookID: shared.ID, EventType: webhook_model.EventLinkCreated, Status: webhook_model.StatusPending, NextAttemptAt: now})
+	assert.NoError(t, err)
+	_, err = repo.CreateDelivery(&webhook_model.Delivery{WebhookID: shared.ID, EventType: "error"})
+	assert.Error(t, err)
+
+	due, _ := repo.ClaimDue(now, time.Minute, 10)
+	assert.Len(t, due, 1)
+	due, _ = repo.ClaimDue(now, time.Minute, 10)
+	assert.Empty(t, due)
+
+	assert.ErrorIs(t, repo.Requeue(delivery.ID, now), webhook_model.ErrDeliveryNotFailed)
+	delivery.Status, delivery.Attempts = webhook_model.StatusDead, 3
+	assert.NoError(t, repo.RecordAttempt(delivery, webhook_model.Attempt{ResponseStatus: 500, AttemptedAt: now}))
+	attempts, _ := repo.ListAttempts(delivery.ID)
+	assert.Len(t, attempts, 1)
+	dead, _ := repo.ListDeliveries(shared.ID, webhook_model.StatusDead, 10)
+	assert.Len(t, dead, 1)
+	assert.NoError(t, repo.Requeue(delivery.ID, now))
+
+	assert.NoError(t, repo.Delete(shared.ID))
+	assert.ErrorIs(t, repo.Delete(shared.ID), webhook_model.ErrWebhookNotFound)
+	_, err = repo.GetDelivery(delivery.ID)
+	assert.ErrorIs(t, err, webhook_model.ErrDeliveryNotFound)
+}
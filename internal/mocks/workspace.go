@@ -0,0 +1,127 @@
+package mocks
+
+import (
+	"errors"
+	"sort"
+	"time"
+	"url-shortener/internal/app/models/workspace"
+)
+
+// MockWorkspaceRepository is a mock implementation of WorkspaceRepository interface for testing purposes.
+type MockWorkspaceRepository struct {
+	Workspaces map[uint]*workspace_model.Workspace
+	// Members maps a workspace ID to the role of each member.
+	Members map[uint]map[uint]string
+	// Usernames resolves member usernames; members without one are listed with an empty username.
+	Usernames map[uint]string
+}
+
+// NewMockWorkspaceRepository creates a new instance of MockWorkspaceRepository.
+func NewMockWorkspaceRepository() *MockWorkspaceRepository {
+	return &MockWorkspaceRepository{
+		Workspaces: make(map[uint]*workspace_model.Workspace),
+		Members:    make(map[uint]map[uint]string),
+		Usernames:  make(map[uint]string),
+	}
+}
+
+// Create simulates inserting a new workspace with its creator as owner in the mock database.
+func (r *MockWorkspaceRepository) Create(workspace *workspace_model.Workspace) (*workspace_model.Workspace, error) {
+	// DB error can be simulated here
+	if workspace.Name == "error" {
+		return nil, errors.New("workspace not created")
+	}
+
+	workspace.ID = uint(len(r.Workspaces) + 1) // Simulate auto-incrementing ID
+	workspace.CreatedAt = time.Now()
+	r.Workspaces[workspace.ID] = workspace
+	r.Members[workspace.ID] = map[uint]string{workspace.CreatedBy: workspace_model.RoleOwner}
+
+	workspace.Role = workspace_model.RoleOwner
+	return workspace, nil
+}
+
+// GetByID simulates retrieving a workspace by ID from the mock database.
+func (r *MockWorkspaceRepository) GetByID(id uint) (*workspace_model.Workspace, error) {
+	workspace, ok := r.Workspaces[id]
+	if !ok {
+		return nil, workspace_model.ErrWorkspaceNotFound
+	}
+	return workspace, nil
+}
+
+// ListByUser simulates retrieving the workspaces of a user from the mock database.
+func (r *MockWorkspaceRepository) ListByUser(userID uint) ([]workspace_model.Workspace, error) {
+	workspaces := make([]workspace_model.Workspace, 0)
+	for id, members := range r.Members {
+		if role, ok := members[userID]; ok {
+			workspace := *r.Workspaces[id]
+			workspace.Role = role
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].ID < workspaces[j].ID })
+	return workspaces, nil
+}
+
+// GetMember simulates retrieving the membership of a user in a workspace from the mock database.
+func (r *MockWorkspaceRepository) GetMember(workspaceID, userID uint) (*workspace_model.Member, error) {
+	role, ok := r.Members[workspaceID][userID]
+	if !ok {
+		return nil, workspace_model.ErrNotMember
+	}
+	return &workspace_model.Member{WorkspaceID: workspaceID, UserID: userID, Username: r.Usernames[userID], Role: role}, nil
+}
+
+// ListMembers simulates retrieving the members of a workspace from the mock database.
+func (r *MockWorkspaceRepository) ListMembers(workspaceID uint) ([]workspace_model.Member, error) {
+	members := make([]workspace_model.Member, 0)
+	for userID := range r.Members[workspaceID] {
+		member, _ := r.GetMember(workspaceID, userID)
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+// AddMember simulates inserting a membership in the mock database.
+func (r *MockWorkspaceRepository) AddMember(workspaceID, userID uint, role string) error {
+	members, ok := r.Members[workspaceID]
+	if !ok {
+		return workspace_model.ErrWorkspaceNotFound
+	}
+	if _, ok := members[userID]; ok {
+		return workspace_model.ErrMemberAlreadyExists
+	}
+	members[userID] = role
+	return nil
+}
+
+// UpdateMemberRole simulates replacing the role of a member in the mock database.
+func (r *MockWorkspaceRepository) UpdateMemberRole(workspaceID, userID uint, role string) error {
+	if _, ok := r.Members[workspaceID][userID]; !ok {
+		return workspace_model.ErrNotMember
+	}
+	r.Members[workspaceID][userID] = role
+	return nil
+}
+
+// RemoveMember simulates deleting a membership from the mock database.
+func (r *MockWorkspaceRepository) RemoveMember(workspaceID, userID uint) error {
+	if _, ok := r.Members[workspaceID][userID]; !ok {
+		return workspace_model.ErrNotMember
+	}
+	delete(r.Members[workspaceID], userID)
+	return nil
+}
+
+// CountOwners simulates counting the owners of a workspace in the mock database.
+func (r *MockWorkspaceRepository) CountOwners(workspaceID uint) (int, error) {
+	count := 0
+	for _, role := range r.Members[workspaceID] {
+		if role == workspace_model.RoleOwner {
+			count++
+		}
+	}
+	return count, nil
+}
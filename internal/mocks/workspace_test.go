@@ -0,0 +1,44 @@
+package mocks
+
+import (
+	"testing"
+	"url-shortener/internal/app/models/workspace"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMockWorkspaceRepository(t *testing.T) {
+	repo := NewMockWorkspaceRepository()
+
+	workspace, err := repo.Create(&workspace_model.Workspace{Name: "Marketing", CreatedBy: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), workspace.ID)
+	_, err = repo.Create(&workspace_model.Workspace{Name: "error"})
+	assert.Error(t, err)
+
+	_, err = repo.GetByID(1)
+	assert.NoError(t, err)
+	_, err = repo.GetByID(2)
+	assert.ErrorIs(t, err, workspace_model.ErrWorkspaceNotFound)
+
+	assert.NoError(t, repo.AddMember(1, 2, workspace_model.RoleViewer))
+	assert.ErrorIs(t, repo.AddMember(1, 2, workspace_model.RoleViewer), workspace_model.ErrMemberAlreadyExists)
+	assert.ErrorIs(t, repo.AddMember(9, 2, workspace_model.RoleViewer), workspace_model.ErrWorkspaceNotFound)
+
+	workspaces, _ := repo.ListByUser(2)
+	assert.Len(t, workspaces, 1)
+	assert.Equal(t, workspace_model.RoleViewer, workspaces[0].Role)
+
+	assert.NoError(t, repo.UpdateMemberRole(1, 2, workspace_model.RoleOwner))
+	count, _ := repo.CountOwners(1)
+	assert.Equal(t, 2, count)
+
+	members, _ := repo.ListMembers(1)
+	assert.Len(t, members, 2)
+
+	assert.NoError(t, repo.RemoveMember(1, 2))
+	_, err = repo.GetMember(1, 2)
+	assert.ErrorIs(t, err, workspace_model.ErrNotMember)
+	assert.ErrorIs(t, repo.RemoveMember(1, 2), workspace_model.ErrNotMember)
+	assert.ErrorIs(t, repo.UpdateMemberRole(1, 2, workspace_model.RoleEditor), workspace_model.ErrNotMember)
+}
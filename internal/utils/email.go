@@ -0,0 +1,17 @@
+package utils
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail validates a bare email address and returns it trimmed and lower-cased.
+// Display names such as "Name <user@example.com>" are rejected.
+func NormalizeEmail(email string) (string, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > 255 {
+		return "", false
+	}
+	return email, true
+}
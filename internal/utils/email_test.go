@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	t.Run("Normalize Valid Email", func(t *testing.T) {
+		email, ok := NormalizeEmail("  User@Example.COM ")
+
+		assert.True(t, ok)
+		assert.Equal(t, "user@example.com", email)
+	})
+
+	t.Run("Reject Invalid Emails", func(t *testing.T) {
+		for _, email := range []string{"", "user", "user@", "Name <user@example.com>", "a@b@c"} {
+			_, ok := NormalizeEmail(email)
+			assert.False(t, ok, email)
+		}
+	})
+}
@@ -12,6 +12,11 @@ import (
 )
 
 func main() {
+	// The import subcommand imports an export file and exits, see importCommand
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
 	// Start a goroutine to periodically display memory usage
 	go func() {
 		for {
@@ -36,10 +41,37 @@ func main() {
 		}
 	}(db)
 
-	// Create auth handler
-	userHandler, urlHandler, clicksHandler := initializeHandlers(db)
+	// Create handlers. Serving without the configured blocklist would let blocked destinations
+	// through, so a safety policy that fails to load stops the server.
+	handlers, err := initializeHandlers(db)
+	if err != nil {
+		fmt.Println("[MAIN] Error loading URL safety policy:", err)
+		return
+	}
+
+	server := http.NewServer(os.Getenv("HOST"), os.Getenv("PORT"), handlers)
 
-	server := http.NewServer(os.Getenv("HOST"), os.Getenv("PORT"), userHandler, urlHandler, clicksHandler)
+	// Client IP addresses come from X-Forwarded-For behind trusted proxies only
+	proxies, err := config.NewTrustedProxies()
+	if err != nil {
+		fmt.Println("[MAIN] Error configuring trusted proxies:", err)
+		return
+	}
+	server.TrustProxies(proxies)
+
+	// Serve HTTPS when certificate files or ACME are configured
+	tlsConfig := config.NewTLSConfig()
+	if tlsConfig.Enabled() {
+		if tlsConfig.ACME != nil {
+			// Verified custom domains get certificates too
+			tlsConfig.ACME.AllowHost = handlers.Domain.Service.AllowHost
+		}
+		if err := server.EnableTLS(tlsConfig); err != nil {
+			fmt.Println("[MAIN] Error configuring TLS:", err)
+			return
+		}
+	}
+	server.EnableHSTS(config.NewHSTSConfig())
 
 	if err := server.Start(); err != nil {
 		fmt.Println("[MAIN] Error starting server:", err)